@@ -16,6 +16,7 @@ import (
 	"github.com/filipexyz/notif/internal/interceptor"
 	intNats "github.com/filipexyz/notif/internal/nats"
 	"github.com/filipexyz/notif/internal/server"
+	"github.com/filipexyz/notif/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -34,6 +35,20 @@ func main() {
 	// Setup logging
 	setupLogging(cfg)
 
+	// Export traces when an OTLP endpoint is configured
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		slog.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	// Start embedded NATS server (optional)
 	if cfg.NatsEmbedded {
 		embeddedCfg := intNats.EmbeddedConfig{
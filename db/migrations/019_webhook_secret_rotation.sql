@@ -0,0 +1,8 @@
+-- +goose Up
+-- Keep the previous signing secret valid during a rotation grace period
+ALTER TABLE webhooks ADD COLUMN previous_secret VARCHAR(64);
+ALTER TABLE webhooks ADD COLUMN previous_secret_expires_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS previous_secret_expires_at;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS previous_secret;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Retry failed schedule fires with backoff before marking them failed
+ALTER TABLE scheduled_events ADD COLUMN max_attempts INTEGER NOT NULL DEFAULT 3;
+ALTER TABLE scheduled_events ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE scheduled_events DROP COLUMN IF EXISTS attempts;
+ALTER TABLE scheduled_events DROP COLUMN IF EXISTS max_attempts;
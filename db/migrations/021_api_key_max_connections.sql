@@ -0,0 +1,6 @@
+-- +goose Up
+-- Per-key cap on concurrent WebSocket connections (NULL = server default)
+ALTER TABLE api_keys ADD COLUMN max_connections INTEGER;
+
+-- +goose Down
+ALTER TABLE api_keys DROP COLUMN IF EXISTS max_connections;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Total time a failing delivery may keep retrying before it goes to the DLQ
+ALTER TABLE webhooks ADD COLUMN retry_budget_seconds INTEGER NOT NULL DEFAULT 21600;
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS retry_budget_seconds;
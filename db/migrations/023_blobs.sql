@@ -0,0 +1,19 @@
+-- +goose Up
+-- Blob attachments: metadata for objects uploaded out-of-band to the blob store
+CREATE TABLE blobs (
+    id VARCHAR(32) PRIMARY KEY,
+    org_id VARCHAR(255) NOT NULL,
+    project_id VARCHAR(32) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    api_key_id UUID REFERENCES api_keys(id),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    uploaded_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_blobs_project ON blobs(org_id, project_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS blobs;
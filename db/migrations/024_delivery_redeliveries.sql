@@ -0,0 +1,8 @@
+-- +goose Up
+-- When the receiver first saw the event, and how many times it was redelivered since
+ALTER TABLE event_deliveries ADD COLUMN first_attempt_at TIMESTAMPTZ;
+ALTER TABLE event_deliveries ADD COLUMN redeliveries INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE event_deliveries DROP COLUMN IF EXISTS redeliveries;
+ALTER TABLE event_deliveries DROP COLUMN IF EXISTS first_attempt_at;
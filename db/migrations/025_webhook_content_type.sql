@@ -0,0 +1,8 @@
+-- +goose Up
+-- How the webhook payload is encoded (json or form) and the Content-Type sent with it
+ALTER TABLE webhooks ADD COLUMN content_type TEXT NOT NULL DEFAULT 'application/json';
+ALTER TABLE webhooks ADD COLUMN body_encoding TEXT NOT NULL DEFAULT 'json';
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS body_encoding;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS content_type;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Pinned versions are exempt from version retention pruning
+ALTER TABLE schema_versions ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE schema_versions DROP COLUMN IF EXISTS pinned;
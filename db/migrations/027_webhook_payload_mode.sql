@@ -0,0 +1,6 @@
+-- +goose Up
+-- envelope wraps data with id/topic/timestamp; raw sends data alone with metadata in headers
+ALTER TABLE webhooks ADD COLUMN payload_mode TEXT NOT NULL DEFAULT 'envelope';
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS payload_mode;
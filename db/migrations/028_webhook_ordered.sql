@@ -0,0 +1,6 @@
+-- +goose Up
+-- ordered webhooks get their events one at a time, in emit order
+ALTER TABLE webhooks ADD COLUMN ordered BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS ordered;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Recurring schedules re-arm from a cron expression after each run
+ALTER TABLE scheduled_events ADD COLUMN cron TEXT;
+ALTER TABLE scheduled_events ADD COLUMN max_occurrences INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE scheduled_events ADD COLUMN occurrences INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE scheduled_events ADD COLUMN last_run_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE scheduled_events DROP COLUMN IF EXISTS last_run_at;
+ALTER TABLE scheduled_events DROP COLUMN IF EXISTS occurrences;
+ALTER TABLE scheduled_events DROP COLUMN IF EXISTS max_occurrences;
+ALTER TABLE scheduled_events DROP COLUMN IF EXISTS cron;
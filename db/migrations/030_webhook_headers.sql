@@ -0,0 +1,6 @@
+-- +goose Up
+-- static headers added to every delivery; secret-looking values are encrypted
+ALTER TABLE webhooks ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS headers;
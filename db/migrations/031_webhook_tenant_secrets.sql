@@ -0,0 +1,8 @@
+-- +goose Up
+-- per-tenant signing secrets, selected by a field of the event data
+ALTER TABLE webhooks ADD COLUMN tenant_field TEXT NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN tenant_secrets JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS tenant_secrets;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS tenant_field;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- admin keys may subscribe across the projects they're authorized for
+ALTER TABLE api_keys ADD COLUMN scopes TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN authorized_projects TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE api_keys DROP COLUMN IF EXISTS authorized_projects;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Emit outbox: events persisted before they're published to JetStream
+CREATE TABLE event_outbox (
+    id VARCHAR(32) PRIMARY KEY,
+    org_id VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_event_outbox_due ON event_outbox(next_attempt_at, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_outbox;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Per-webhook retry schedule ({max_retries, backoff}); empty uses the defaults
+ALTER TABLE webhooks ADD COLUMN retry_policy JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS retry_policy;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- URLs tried in order, within the same attempt, when the primary url fails
+ALTER TABLE webhooks ADD COLUMN fallback_urls TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE webhooks DROP COLUMN IF EXISTS fallback_urls;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Named transformation pipelines, applied in position order to the events
+-- emitted in a project before they're stored and delivered
+CREATE TABLE pipelines (
+    id VARCHAR(32) PRIMARY KEY,
+    org_id VARCHAR(255) NOT NULL,
+    project_id VARCHAR(32) NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    topic_pattern VARCHAR(255) NOT NULL DEFAULT '>',
+    redact TEXT[] NOT NULL DEFAULT '{}',
+    jq TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(project_id, name)
+);
+
+CREATE INDEX idx_pipelines_project ON pipelines(project_id, position);
+
+-- +goose Down
+DROP TABLE IF EXISTS pipelines;
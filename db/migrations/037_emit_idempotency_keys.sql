@@ -0,0 +1,17 @@
+-- +goose Up
+-- Idempotency keys of recent emits: a retried emit with the same key
+-- returns the original event instead of publishing it again
+CREATE TABLE emit_idempotency_keys (
+    project_id VARCHAR(32) NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    event_id VARCHAR(32) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (project_id, key)
+);
+
+CREATE INDEX idx_emit_idempotency_keys_created ON emit_idempotency_keys(project_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS emit_idempotency_keys;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Keys with expires_at are refused once it passes; rotation sets it on the
+-- old key to end its grace period
+ALTER TABLE api_keys ADD COLUMN expires_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE api_keys DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Audit queries are scoped to an org and page newest first by
+-- (timestamp, id); this index serves both, and its org_id prefix replaces
+-- the single-column index
+CREATE INDEX idx_audit_log_org_timestamp ON audit_log (org_id, timestamp DESC, id DESC);
+DROP INDEX IF EXISTS idx_audit_log_org_id;
+
+-- +goose Down
+CREATE INDEX IF NOT EXISTS idx_audit_log_org_id ON audit_log (org_id);
+DROP INDEX IF EXISTS idx_audit_log_org_timestamp;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Emitted events keep their data (up to EVENT_DATA_MAX_BYTES) so
+-- GET /events?filter= can match fields with containment (@>), which the
+-- jsonb_path_ops GIN index serves
+ALTER TABLE events ADD COLUMN data JSONB;
+CREATE INDEX idx_events_data ON events USING GIN (data jsonb_path_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_events_data;
+ALTER TABLE events DROP COLUMN IF EXISTS data;
@@ -1,5 +1,5 @@
 -- name: GetAPIKeyByHash :one
-SELECT id, key_prefix, name, rate_limit_per_second, revoked_at, created_at, org_id, project_id
+SELECT id, key_prefix, name, rate_limit_per_second, revoked_at, created_at, org_id, project_id, max_connections, scopes, authorized_projects, expires_at
 FROM api_keys
 WHERE key_hash = $1 AND revoked_at IS NULL;
 
@@ -12,9 +12,9 @@ WHERE id = $1;
 UPDATE api_keys SET last_used_at = NOW() WHERE id = $1;
 
 -- name: CreateAPIKey :one
-INSERT INTO api_keys (key_hash, key_prefix, name, rate_limit_per_second, org_id, project_id)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, key_prefix, name, rate_limit_per_second, created_at, org_id, project_id;
+INSERT INTO api_keys (key_hash, key_prefix, name, rate_limit_per_second, org_id, project_id, max_connections, scopes, authorized_projects, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, key_prefix, name, rate_limit_per_second, created_at, org_id, project_id, max_connections, scopes, authorized_projects, expires_at;
 
 -- name: RevokeAPIKey :exec
 UPDATE api_keys SET revoked_at = NOW() WHERE id = $1;
@@ -33,7 +33,7 @@ WHERE org_id = $1
 ORDER BY created_at DESC;
 
 -- name: ListAPIKeysByProject :many
-SELECT id, key_prefix, name, rate_limit_per_second, created_at, last_used_at, revoked_at, project_id
+SELECT id, key_prefix, name, rate_limit_per_second, created_at, last_used_at, revoked_at, project_id, max_connections, scopes, authorized_projects, expires_at
 FROM api_keys
 WHERE org_id = $1 AND project_id = $2
 ORDER BY created_at DESC;
@@ -50,3 +50,21 @@ WHERE id = $1 AND org_id = $2 AND revoked_at IS NULL;
 -- name: RevokeAPIKeyByProject :exec
 UPDATE api_keys SET revoked_at = NOW()
 WHERE id = $1 AND org_id = $2 AND project_id = $3 AND revoked_at IS NULL;
+
+-- name: GetAPIKeyByProject :one
+-- An active key of a project, with what rotation copies to its successor
+SELECT id, key_prefix, name, rate_limit_per_second, created_at, max_connections, scopes, authorized_projects, expires_at
+FROM api_keys
+WHERE id = $1 AND org_id = $2 AND project_id = $3 AND revoked_at IS NULL;
+
+-- name: ExpireAPIKeyByProject :exec
+-- Brings a key's expiry forward to $4; a key already expiring sooner keeps its expiry
+UPDATE api_keys SET expires_at = $4
+WHERE id = $1 AND org_id = $2 AND project_id = $3 AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > $4);
+
+-- name: CountAPIKeyEventsByProject :many
+SELECT api_key_id, COUNT(*) AS events
+FROM events
+WHERE org_id = $1 AND project_id = $2 AND created_at >= $3 AND api_key_id IS NOT NULL
+GROUP BY api_key_id;
@@ -3,11 +3,17 @@ INSERT INTO audit_log (actor, action, org_id, target, detail, ip_address)
 VALUES ($1, $2, $3, $4, $5, $6);
 
 -- name: ListAuditLogs :many
+-- Newest first. before is the id of the last entry on the previous page.
 SELECT id, timestamp, actor, action, org_id, target, detail, ip_address
 FROM audit_log
-WHERE
-    (sqlc.narg('org_id')::VARCHAR IS NULL OR org_id = sqlc.narg('org_id'))
+WHERE org_id = sqlc.arg('org_id')
     AND (sqlc.narg('action')::TEXT IS NULL OR action = sqlc.narg('action'))
+    AND (sqlc.narg('actor')::TEXT IS NULL OR actor = sqlc.narg('actor'))
     AND (sqlc.narg('since')::TIMESTAMPTZ IS NULL OR timestamp >= sqlc.narg('since'))
-ORDER BY id DESC
+    AND (sqlc.narg('until')::TIMESTAMPTZ IS NULL OR timestamp < sqlc.narg('until'))
+    AND (sqlc.narg('before')::BIGINT IS NULL OR (timestamp, id) < (
+        SELECT b.timestamp, b.id FROM audit_log b
+        WHERE b.id = sqlc.narg('before') AND b.org_id = sqlc.arg('org_id')
+    ))
+ORDER BY timestamp DESC, id DESC
 LIMIT sqlc.arg('limit');
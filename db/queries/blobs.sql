@@ -0,0 +1,13 @@
+-- name: CreateBlob :one
+INSERT INTO blobs (id, org_id, project_id, content_type, size, storage_key, api_key_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetBlobByProject :one
+SELECT * FROM blobs WHERE id = $1 AND org_id = $2 AND project_id = $3;
+
+-- name: MarkBlobUploaded :one
+UPDATE blobs
+SET size = $2, uploaded_at = COALESCE(uploaded_at, NOW())
+WHERE id = $1
+RETURNING *;
@@ -1,6 +1,16 @@
 -- name: CreateEventDelivery :one
-INSERT INTO event_deliveries (event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, delivered_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO event_deliveries (event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, delivered_at, first_attempt_at, redeliveries)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+    -- Carry the first attempt over from earlier rows for the same receiver
+    COALESCE(sqlc.narg(first_attempt_at), (
+        SELECT MIN(COALESCE(prev.first_attempt_at, prev.delivered_at, prev.created_at))
+        FROM event_deliveries prev
+        WHERE prev.event_id = $1
+          AND prev.receiver_type = $2
+          AND prev.receiver_id IS NOT DISTINCT FROM $3
+          AND prev.consumer_name IS NOT DISTINCT FROM $4
+    ), $8, NOW()),
+    GREATEST($7 - 1, 0))
 RETURNING *;
 
 -- name: UpdateEventDeliveryStatus :exec
@@ -1,6 +1,6 @@
 -- name: CreateEvent :exec
-INSERT INTO events (id, topic, api_key_id, org_id, project_id, payload_size, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7);
+INSERT INTO events (id, topic, api_key_id, org_id, project_id, payload_size, created_at, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
 
 -- name: GetEvent :one
 SELECT id, topic, api_key_id, org_id, project_id, payload_size, created_at
@@ -52,12 +52,37 @@ WHERE topic LIKE $1 AND org_id = $2
 ORDER BY created_at DESC
 LIMIT $3;
 
+-- name: SearchEvents :many
+-- Newest first. Matches events whose data contains the data document;
+-- before is the id of the last event on the previous page.
+SELECT id, topic, org_id, project_id, created_at, data
+FROM events
+WHERE org_id = $1 AND project_id = $2
+  AND data @> sqlc.arg('data')::JSONB
+  AND (sqlc.narg('topic')::TEXT IS NULL OR topic ~ sqlc.narg('topic'))
+  AND (sqlc.narg('since')::TIMESTAMPTZ IS NULL OR created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::TIMESTAMPTZ IS NULL OR created_at < sqlc.narg('until'))
+  AND (sqlc.narg('before')::VARCHAR IS NULL OR (created_at, id) < (
+    SELECT b.created_at, b.id FROM events b
+    WHERE b.id = sqlc.narg('before') AND b.org_id = $1 AND b.project_id = $2
+  ))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
 -- name: CountEventsByOrg :one
 SELECT COUNT(*) FROM events WHERE org_id = $1;
 
 -- name: CountEventsByProject :one
 SELECT COUNT(*) FROM events WHERE org_id = $1 AND project_id = $2;
 
+-- name: CountEventsByTopic :many
+-- Event counts per topic for a project
+SELECT topic, COUNT(*) as count
+FROM events
+WHERE org_id = $1 AND project_id = $2
+GROUP BY topic
+ORDER BY topic;
+
 -- name: CountEventsByAPIKey :one
 SELECT COUNT(*) FROM events WHERE api_key_id = $1;
 
@@ -76,3 +101,14 @@ SELECT
     COUNT(CASE WHEN created_at > NOW() - INTERVAL '1 hour' THEN 1 END) as last_hour
 FROM events
 WHERE org_id = $1 AND project_id = $2;
+
+-- name: AggregateEvents :one
+-- Totals for an org, or one project when project_id is set
+SELECT
+    COUNT(*) as total,
+    COUNT(CASE WHEN created_at > NOW() - INTERVAL '24 hours' THEN 1 END) as last_24h,
+    COUNT(CASE WHEN created_at > NOW() - INTERVAL '1 hour' THEN 1 END) as last_hour,
+    COALESCE(SUM(payload_size), 0)::bigint as payload_bytes
+FROM events
+WHERE org_id = $1
+  AND (sqlc.narg(project_id)::text IS NULL OR project_id = sqlc.narg(project_id));
@@ -0,0 +1,16 @@
+-- name: ClaimIdempotencyKey :one
+INSERT INTO emit_idempotency_keys (project_id, key, event_id, topic, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (project_id, key) DO NOTHING
+RETURNING project_id, key, event_id, topic, created_at;
+
+-- name: GetIdempotencyKey :one
+SELECT project_id, key, event_id, topic, created_at
+FROM emit_idempotency_keys
+WHERE project_id = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM emit_idempotency_keys WHERE project_id = $1 AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM emit_idempotency_keys WHERE project_id = $1 AND created_at < $2;
@@ -0,0 +1,24 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO event_outbox (id, org_id, payload)
+VALUES ($1, $2, $3);
+
+-- name: GetDueOutboxEvents :many
+SELECT id, org_id, payload, attempts, last_error, created_at, next_attempt_at
+FROM event_outbox
+WHERE next_attempt_at <= $1
+ORDER BY created_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: LeaseOutboxEvents :exec
+UPDATE event_outbox
+SET next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = ANY(sqlc.arg(ids)::text[]);
+
+-- name: DeleteOutboxEvent :exec
+DELETE FROM event_outbox WHERE id = $1;
+
+-- name: RecordOutboxFailure :exec
+UPDATE event_outbox
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1;
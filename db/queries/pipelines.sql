@@ -0,0 +1,21 @@
+-- name: CreatePipeline :one
+INSERT INTO pipelines (id, org_id, project_id, name, topic_pattern, redact, jq, position, enabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetPipelineByName :one
+SELECT * FROM pipelines WHERE project_id = $1 AND name = $2;
+
+-- name: ListPipelines :many
+SELECT * FROM pipelines
+WHERE project_id = $1
+ORDER BY position, name;
+
+-- name: UpdatePipeline :one
+UPDATE pipelines
+SET topic_pattern = $2, redact = $3, jq = $4, position = $5, enabled = $6, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeletePipeline :exec
+DELETE FROM pipelines WHERE id = $1;
@@ -1,6 +1,6 @@
 -- name: CreateScheduledEvent :one
-INSERT INTO scheduled_events (id, org_id, project_id, topic, data, scheduled_for, api_key_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO scheduled_events (id, org_id, project_id, topic, data, scheduled_for, api_key_id, max_attempts, cron, max_occurrences)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
 -- name: GetScheduledEvent :one
@@ -52,6 +52,17 @@ SET status = sqlc.arg(status)::text,
     error = sqlc.arg(error)
 WHERE id = sqlc.arg(id);
 
+-- name: RecordScheduledEventAttempt :exec
+UPDATE scheduled_events
+SET status = sqlc.arg(status)::text,
+    attempts = sqlc.arg(attempts),
+    scheduled_for = sqlc.arg(scheduled_for),
+    executed_at = CASE WHEN sqlc.arg(status)::text = 'completed' THEN NOW() ELSE executed_at END,
+    error = sqlc.arg(error),
+    occurrences = sqlc.arg(occurrences),
+    last_run_at = sqlc.arg(last_run_at)
+WHERE id = sqlc.arg(id) AND status = 'pending';
+
 -- name: CancelScheduledEvent :execrows
 UPDATE scheduled_events
 SET status = 'cancelled'
@@ -62,6 +62,11 @@ UPDATE schema_versions
 SET is_latest = (id = $2)
 WHERE schema_id = $1;
 
+-- name: SetSchemaVersionPinned :exec
+UPDATE schema_versions
+SET pinned = $3
+WHERE schema_id = $1 AND version = $2;
+
 -- name: DeleteSchemaVersion :exec
 DELETE FROM schema_versions WHERE id = $1;
 
@@ -102,3 +107,17 @@ SELECT
 FROM schema_validations
 WHERE schema_id = $1
   AND validated_at > NOW() - INTERVAL '24 hours';
+
+-- name: GetValidationStatsBySchemaVersion :many
+-- Emit-time validation outcomes in the last 24h, per schema version
+SELECT
+    COALESCE(sv.version, '')::text as version,
+    COUNT(*) as total,
+    COUNT(*) FILTER (WHERE v.valid = true) as valid_count,
+    COUNT(*) FILTER (WHERE v.valid = false) as invalid_count
+FROM schema_validations v
+LEFT JOIN schema_versions sv ON sv.id = v.schema_version_id
+WHERE v.schema_id = $1
+  AND v.validated_at > NOW() - INTERVAL '24 hours'
+GROUP BY sv.version
+ORDER BY MAX(v.validated_at) DESC;
@@ -39,3 +39,16 @@ JOIN api_keys ak ON ak.id = w.api_key_id
 LEFT JOIN webhook_deliveries wd ON w.id = wd.webhook_id AND wd.created_at > NOW() - INTERVAL '24 hours'
 WHERE ak.org_id = $1 AND ak.revoked_at IS NULL
 GROUP BY w.id, w.url;
+
+-- name: GetRedeliveryStats :one
+-- Final outcomes in the last 24h and how many needed more than one attempt
+SELECT
+    COUNT(*) as completed,
+    COUNT(*) FILTER (WHERE ed.redeliveries > 0) as redelivered,
+    COALESCE(SUM(ed.redeliveries), 0)::bigint as redeliveries
+FROM event_deliveries ed
+JOIN events e ON e.id = ed.event_id
+WHERE e.org_id = $1
+  AND ed.receiver_type = $2
+  AND ed.status IN ('acked', 'dlq', 'dropped')
+  AND ed.created_at > NOW() - INTERVAL '24 hours';
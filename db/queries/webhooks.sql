@@ -1,6 +1,6 @@
 -- name: CreateWebhook :one
-INSERT INTO webhooks (org_id, project_id, url, topics, secret)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO webhooks (org_id, project_id, url, topics, secret, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 RETURNING *;
 
 -- name: GetWebhook :one
@@ -41,7 +41,13 @@ ORDER BY created_at;
 
 -- name: UpdateWebhook :one
 UPDATE webhooks
-SET url = $2, topics = $3, enabled = $4, updated_at = NOW()
+SET url = $2, topics = $3, enabled = $4, retry_budget_seconds = $5, content_type = $6, body_encoding = $7, payload_mode = $8, ordered = $9, headers = $10, tenant_field = $11, tenant_secrets = $12, retry_policy = $13, fallback_urls = $14, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RotateWebhookSecret :one
+UPDATE webhooks
+SET previous_secret = secret, previous_secret_expires_at = $3, secret = $2, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
@@ -73,11 +79,21 @@ ORDER BY wd.created_at
 LIMIT $1;
 
 -- name: GetWebhookDeliveries :many
+-- Newest first. before is the id of the last delivery on the previous page.
 SELECT * FROM webhook_deliveries
 WHERE webhook_id = $1
-ORDER BY created_at DESC
+  AND (sqlc.narg('status')::TEXT IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('before')::UUID IS NULL OR (created_at, id) < (
+    SELECT b.created_at, b.id FROM webhook_deliveries b
+    WHERE b.id = sqlc.narg('before') AND b.webhook_id = $1
+  ))
+ORDER BY created_at DESC, id DESC
 LIMIT $2;
 
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2;
+
 -- name: GetDeliveriesByEventID :many
 SELECT wd.*, w.url as webhook_url
 FROM webhook_deliveries wd
@@ -0,0 +1,188 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalPathPrefix is where notifd serves LocalStore uploads and downloads.
+const LocalPathPrefix = "/blobs/"
+
+// LocalStore keeps blobs on the local filesystem. notifd itself plays the
+// part of the object store: presigned URLs point at LocalPathPrefix and carry
+// an HMAC signature and expiry, checked by ServeHTTP.
+type LocalStore struct {
+	dir        string
+	baseURL    string
+	signingKey []byte
+	maxSize    int64
+	now        func() time.Time
+}
+
+// NewLocalStore creates a LocalStore rooted at dir. baseURL is the public
+// address of notifd (e.g. https://notif.example.com); when empty, presigned
+// URLs are relative paths. Uploads larger than maxSize are rejected.
+func NewLocalStore(dir, baseURL string, signingKey []byte, maxSize int64) (*LocalStore, error) {
+	if len(signingKey) == 0 {
+		return nil, errors.New("local blob store requires a signing key")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create blob dir: %w", err)
+	}
+	return &LocalStore{
+		dir:        dir,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		signingKey: signingKey,
+		maxSize:    maxSize,
+		now:        time.Now,
+	}, nil
+}
+
+// PresignPut implements Store.
+func (l *LocalStore) PresignPut(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return l.presign(http.MethodPut, key, expires)
+}
+
+// PresignGet implements Store.
+func (l *LocalStore) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return l.presign(http.MethodGet, key, expires)
+}
+
+// Size implements Store.
+func (l *LocalStore) Size(ctx context.Context, key string) (int64, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (l *LocalStore) presign(method, key string, expires time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	exp := strconv.FormatInt(l.now().Add(expires).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", exp)
+	q.Set("sig", l.signature(method, key, exp))
+	return l.baseURL + LocalPathPrefix + key + "?" + q.Encode(), nil
+}
+
+func (l *LocalStore) signature(method, key, expires string) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	mac.Write([]byte(method + "\n" + key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *LocalStore) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// ServeHTTP handles presigned PUT and GET requests under LocalPathPrefix.
+func (l *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, LocalPathPrefix)
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	if method != http.MethodGet && method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	exp := r.URL.Query().Get("expires")
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	sig := r.URL.Query().Get("sig")
+	if err != nil || validKey(key) != nil ||
+		!hmac.Equal([]byte(sig), []byte(l.signature(method, key, exp))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	if l.now().Unix() > expUnix {
+		http.Error(w, "url expired", http.StatusForbidden)
+		return
+	}
+
+	path, _ := l.path(key)
+	if method == http.MethodPut {
+		l.put(w, r, path)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "read failed", http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), f)
+}
+
+// put writes the body to a temp file and renames it into place, so readers
+// never see a partial upload.
+func (l *LocalStore) put(w http.ResponseWriter, r *http.Request, path string) {
+	if l.maxSize > 0 && r.ContentLength > l.maxSize {
+		http.Error(w, "blob too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		http.Error(w, "write failed", http.StatusInternalServerError)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		http.Error(w, "write failed", http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	body := io.Reader(r.Body)
+	if l.maxSize > 0 {
+		body = io.LimitReader(r.Body, l.maxSize+1)
+	}
+	n, err := io.Copy(tmp, body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		http.Error(w, "write failed", http.StatusInternalServerError)
+		return
+	}
+	if l.maxSize > 0 && n > l.maxSize {
+		http.Error(w, "blob too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		http.Error(w, "write failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
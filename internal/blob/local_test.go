@@ -0,0 +1,144 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) (*LocalStore, *httptest.Server) {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	store, err := NewLocalStore(t.TempDir(), srv.URL, []byte("test-signing-key"), 1024)
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	mux.Handle(LocalPathPrefix, store)
+	return store, srv
+}
+
+func doRequest(t *testing.T, method, url string, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func TestLocalStore_UploadAndDownload(t *testing.T) {
+	store, _ := newTestLocalStore(t)
+	ctx := context.Background()
+	key := ObjectKey("org_1", "prj_1", "blob_1")
+
+	if _, err := store.Size(ctx, key); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound before upload, got %v", err)
+	}
+
+	putURL, err := store.PresignPut(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := doRequest(t, http.MethodPut, putURL, "hello blob"); status != http.StatusOK {
+		t.Fatalf("PUT status = %d", status)
+	}
+
+	size, err := store.Size(ctx, key)
+	if err != nil || size != int64(len("hello blob")) {
+		t.Fatalf("Size = %d, %v", size, err)
+	}
+
+	getURL, err := store.PresignGet(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, body := doRequest(t, http.MethodGet, getURL, "")
+	if status != http.StatusOK || body != "hello blob" {
+		t.Errorf("GET = %d %q", status, body)
+	}
+
+	// A download URL can't be used to overwrite the blob
+	if status, _ := doRequest(t, http.MethodPut, getURL, "overwrite"); status != http.StatusForbidden {
+		t.Errorf("PUT with GET signature = %d, want 403", status)
+	}
+}
+
+func TestLocalStore_RejectsBadURLs(t *testing.T) {
+	store, _ := newTestLocalStore(t)
+	ctx := context.Background()
+	key := ObjectKey("org_1", "prj_1", "blob_1")
+
+	putURL, _ := store.PresignPut(ctx, key, time.Minute)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"forged signature", strings.Replace(putURL, "sig=", "sig=00", 1)},
+		{"other key", strings.Replace(putURL, "blob_1", "blob_2", 1)},
+		{"missing signature", strings.Split(putURL, "?")[0]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := doRequest(t, http.MethodPut, tt.url, "x"); status != http.StatusForbidden {
+				t.Errorf("status = %d, want 403", status)
+			}
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		store.now = func() time.Time { return time.Now().Add(-time.Hour) }
+		defer func() { store.now = time.Now }()
+		expired, _ := store.PresignPut(ctx, key, time.Minute)
+		store.now = time.Now
+		status, body := doRequest(t, http.MethodPut, expired, "x")
+		if status != http.StatusForbidden || !strings.Contains(body, "expired") {
+			t.Errorf("status = %d %q, want 403 url expired", status, body)
+		}
+	})
+}
+
+func TestLocalStore_MaxSize(t *testing.T) {
+	store, _ := newTestLocalStore(t)
+	ctx := context.Background()
+	key := ObjectKey("org_1", "prj_1", "blob_big")
+
+	putURL, _ := store.PresignPut(ctx, key, time.Minute)
+	if status, _ := doRequest(t, http.MethodPut, putURL, strings.Repeat("x", 2048)); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", status)
+	}
+	if _, err := store.Size(ctx, key); err != ErrNotFound {
+		t.Errorf("oversized upload must not be stored, got %v", err)
+	}
+}
+
+func TestLocalStore_InvalidKeys(t *testing.T) {
+	store, _ := newTestLocalStore(t)
+	for _, key := range []string{"", "../etc/passwd", "org/../../x", "/abs", "org//x"} {
+		if _, err := store.PresignPut(context.Background(), key, time.Minute); err == nil {
+			t.Errorf("PresignPut(%q) should fail", key)
+		}
+		if _, err := store.Size(context.Background(), key); err == nil || err == ErrNotFound {
+			t.Errorf("Size(%q) should reject the key, got %v", key, err)
+		}
+	}
+}
+
+func TestNewLocalStore_RequiresSigningKey(t *testing.T) {
+	if _, err := NewLocalStore(t.TempDir(), "", nil, 0); err == nil {
+		t.Error("expected error without signing key")
+	}
+}
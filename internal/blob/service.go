@@ -0,0 +1,182 @@
+package blob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultUploadTTL is how long a presigned upload URL stays valid.
+	DefaultUploadTTL = 15 * time.Minute
+	// DefaultDownloadTTL is how long download URLs in deliveries stay valid.
+	DefaultDownloadTTL = 7 * 24 * time.Hour
+	// MaxAttachments caps the blobs referenced by a single event.
+	MaxAttachments = 10
+)
+
+var (
+	// ErrNotUploaded means the blob's metadata exists but nothing was uploaded.
+	ErrNotUploaded = errors.New("blob has not been uploaded")
+	// ErrSizeMismatch means the uploaded object is larger than declared.
+	ErrSizeMismatch = errors.New("uploaded blob exceeds its declared size")
+	// ErrTooManyAttachments means an event referenced more than MaxAttachments blobs.
+	ErrTooManyAttachments = fmt.Errorf("at most %d attachments per event", MaxAttachments)
+)
+
+// Upload is a presigned upload target for a new blob.
+type Upload struct {
+	URL       string
+	Method    string
+	ExpiresAt time.Time
+}
+
+// Service manages blob metadata on top of a Store.
+type Service struct {
+	queries     *db.Queries
+	store       Store
+	maxSize     int64
+	uploadTTL   time.Duration
+	downloadTTL time.Duration
+}
+
+// NewService creates a Service. Blobs larger than maxSize are refused.
+func NewService(queries *db.Queries, store Store, maxSize int64) *Service {
+	return &Service{
+		queries:     queries,
+		store:       store,
+		maxSize:     maxSize,
+		uploadTTL:   DefaultUploadTTL,
+		downloadTTL: DefaultDownloadTTL,
+	}
+}
+
+// SetURLTTLs sets how long presigned upload and download URLs stay valid.
+// Non-positive values keep the current setting.
+func (s *Service) SetURLTTLs(upload, download time.Duration) {
+	if upload > 0 {
+		s.uploadTTL = upload
+	}
+	if download > 0 {
+		s.downloadTTL = download
+	}
+}
+
+// MaxSize returns the largest blob the service accepts.
+func (s *Service) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Create records a pending blob and returns where to upload it.
+func (s *Service) Create(ctx context.Context, orgID, projectID string, apiKeyID pgtype.UUID, contentType string, size int64) (db.Blob, *Upload, error) {
+	if size <= 0 || (s.maxSize > 0 && size > s.maxSize) {
+		return db.Blob{}, nil, fmt.Errorf("size must be between 1 and %d bytes", s.maxSize)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	id := generateBlobID()
+	key := ObjectKey(orgID, projectID, id)
+	uploadURL, err := s.store.PresignPut(ctx, key, s.uploadTTL)
+	if err != nil {
+		return db.Blob{}, nil, fmt.Errorf("presign upload: %w", err)
+	}
+
+	b, err := s.queries.CreateBlob(ctx, db.CreateBlobParams{
+		ID:          id,
+		OrgID:       orgID,
+		ProjectID:   projectID,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+		ApiKeyID:    apiKeyID,
+	})
+	if err != nil {
+		return db.Blob{}, nil, fmt.Errorf("create blob: %w", err)
+	}
+	return b, &Upload{URL: uploadURL, Method: "PUT", ExpiresAt: time.Now().UTC().Add(s.uploadTTL)}, nil
+}
+
+// Get returns a blob's metadata, confirming the upload with the store the
+// first time it is seen. ErrNotFound covers blobs of other projects.
+func (s *Service) Get(ctx context.Context, orgID, projectID, id string) (db.Blob, error) {
+	b, err := s.queries.GetBlobByProject(ctx, db.GetBlobByProjectParams{
+		ID:        id,
+		OrgID:     orgID,
+		ProjectID: projectID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.Blob{}, ErrNotFound
+	}
+	if err != nil || b.UploadedAt.Valid {
+		return b, err
+	}
+
+	size, err := s.store.Size(ctx, b.StorageKey)
+	if errors.Is(err, ErrNotFound) {
+		return b, nil
+	}
+	if err != nil {
+		return b, fmt.Errorf("stat blob: %w", err)
+	}
+	if size > b.Size {
+		return b, ErrSizeMismatch
+	}
+	return s.queries.MarkBlobUploaded(ctx, db.MarkBlobUploadedParams{ID: b.ID, Size: size})
+}
+
+// Attachment returns an uploaded blob as an event attachment with a fresh
+// download URL.
+func (s *Service) Attachment(ctx context.Context, b db.Blob) (domain.Attachment, error) {
+	if !b.UploadedAt.Valid {
+		return domain.Attachment{}, ErrNotUploaded
+	}
+	u, err := s.store.PresignGet(ctx, b.StorageKey, s.downloadTTL)
+	if err != nil {
+		return domain.Attachment{}, fmt.Errorf("presign download: %w", err)
+	}
+	return domain.Attachment{
+		ID:          b.ID,
+		ContentType: b.ContentType,
+		Size:        b.Size,
+		URL:         u,
+		ExpiresAt:   time.Now().UTC().Add(s.downloadTTL),
+	}, nil
+}
+
+// Attach resolves blob IDs referenced by an emitted event. Every blob must
+// belong to the project and be uploaded.
+func (s *Service) Attach(ctx context.Context, orgID, projectID string, ids []string) ([]domain.Attachment, error) {
+	if len(ids) > MaxAttachments {
+		return nil, ErrTooManyAttachments
+	}
+	attachments := make([]domain.Attachment, 0, len(ids))
+	for _, id := range ids {
+		b, err := s.Get(ctx, orgID, projectID, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		a, err := s.Attachment(ctx, b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+// generateBlobID creates a unique blob ID with "blob_" prefix.
+func generateBlobID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "blob_" + hex.EncodeToString(b)
+}
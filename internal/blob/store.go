@@ -0,0 +1,43 @@
+// Package blob stores event attachments outside the event stream. Clients
+// upload and download directly against the store using short-lived presigned
+// URLs; notifd only keeps metadata and hands out the URLs.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist in the store.
+var ErrNotFound = errors.New("blob not found")
+
+// Store is a pluggable object store that can presign uploads and downloads.
+type Store interface {
+	// PresignPut returns a URL that accepts a PUT of the object body.
+	PresignPut(ctx context.Context, key string, expires time.Duration) (string, error)
+	// PresignGet returns a URL that serves the object body.
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+	// Size returns the stored object's size in bytes, or ErrNotFound.
+	Size(ctx context.Context, key string) (int64, error)
+}
+
+// ObjectKey returns the storage key for a blob, namespaced by org and project.
+func ObjectKey(orgID, projectID, blobID string) string {
+	return orgID + "/" + projectID + "/" + blobID
+}
+
+// validKey rejects keys that could escape the store's namespace.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
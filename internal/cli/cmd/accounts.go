@@ -135,6 +135,7 @@ var orgListCmd = &cobra.Command{
 				NatsPublicKey string `json:"nats_public_key"`
 				BillingTier   string `json:"billing_tier"`
 				CreatedAt     string `json:"created_at"`
+				Drained       bool   `json:"drained"`
 			} `json:"orgs"`
 			Count int `json:"count"`
 		}
@@ -151,12 +152,138 @@ var orgListCmd = &cobra.Command{
 			if len(keyPreview) > 16 {
 				keyPreview = keyPreview[:16] + "..."
 			}
-			out.Info(fmt.Sprintf("  %s  %s  tier=%s  key=%s",
-				org.ID, org.Name, org.BillingTier, keyPreview))
+			line := fmt.Sprintf("  %s  %s  tier=%s  key=%s",
+				org.ID, org.Name, org.BillingTier, keyPreview)
+			if org.Drained {
+				line += "  (drained)"
+			}
+			out.Info(line)
 		}
 	},
 }
 
+var orgDrainCmd = &cobra.Command{
+	Use:   "drain <id>",
+	Short: "Drain an org for maintenance",
+	Long: `Put an org into maintenance: emits are refused with 503 and subscribers
+stop receiving new events (WebSocket clients get a "draining" message).
+Deliveries in flight finish; held events are delivered after 'resume'.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := getClient()
+		resp, err := c.Post("/api/v1/orgs/"+args[0]+"/drain", nil)
+		if err != nil {
+			out.Error("Failed to drain org: " + err.Error())
+			return
+		}
+		if jsonOutput {
+			fmt.Println(string(resp))
+			return
+		}
+		out.Success("Org drained: " + args[0])
+	},
+}
+
+var orgResumeCmd = &cobra.Command{
+	Use:   "resume <id>",
+	Short: "Resume a drained org",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := getClient()
+		resp, err := c.Post("/api/v1/orgs/"+args[0]+"/resume", nil)
+		if err != nil {
+			out.Error("Failed to resume org: " + err.Error())
+			return
+		}
+		if jsonOutput {
+			fmt.Println(string(resp))
+			return
+		}
+		out.Success("Org resumed: " + args[0])
+	},
+}
+
+var (
+	orgMigrateMaxAge        string
+	orgMigrateMaxBytes      int64
+	orgMigrateReplicas      int
+	orgMigrateStorage       string
+	orgMigrateDryRun        bool
+	orgMigrateAllowDataLoss bool
+)
+
+var orgMigrateStreamCmd = &cobra.Command{
+	Use:   "migrate-stream <id>",
+	Short: "Migrate an org's events stream to new settings",
+	Long: `Apply new retention, replica or storage settings to an org's events stream.
+Unset flags keep the current value. --dry-run prints the planned changes.
+
+A migration that would discard stored messages is refused unless
+--allow-data-loss is given. Changing --storage copies the stream into a new
+one, which requires draining the org first.
+
+Examples:
+  notif accounts migrate-stream acme --max-age 72h --dry-run
+  notif accounts migrate-stream acme --max-bytes 5368709120 --replicas 3
+  notif accounts drain acme && notif accounts migrate-stream acme --storage memory`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		body := map[string]any{
+			"max_age":         orgMigrateMaxAge,
+			"max_bytes":       orgMigrateMaxBytes,
+			"replicas":        orgMigrateReplicas,
+			"storage":         orgMigrateStorage,
+			"dry_run":         orgMigrateDryRun,
+			"allow_data_loss": orgMigrateAllowDataLoss,
+		}
+		data, _ := json.Marshal(body)
+
+		c := getClient()
+		resp, err := c.Post("/api/v1/orgs/"+args[0]+"/stream/migrate", data)
+		if err != nil {
+			out.Error("Failed to migrate stream: " + err.Error())
+			return
+		}
+		if jsonOutput {
+			fmt.Println(string(resp))
+			return
+		}
+
+		var result struct {
+			Stream  string `json:"stream"`
+			Changes []struct {
+				Setting string `json:"setting"`
+				From    string `json:"from"`
+				To      string `json:"to"`
+			} `json:"changes"`
+			Copy          bool     `json:"copy"`
+			DataLoss      []string `json:"data_loss"`
+			Messages      uint64   `json:"messages"`
+			MessagesAfter uint64   `json:"messages_after"`
+			Applied       bool     `json:"applied"`
+		}
+		json.Unmarshal(resp, &result)
+		if len(result.Changes) == 0 {
+			out.Info("Stream " + result.Stream + " already has these settings")
+			return
+		}
+		for _, change := range result.Changes {
+			out.Info(fmt.Sprintf("  %s: %s -> %s", change.Setting, change.From, change.To))
+		}
+		if result.Copy {
+			out.Info("  messages are copied into a new stream")
+		}
+		for _, loss := range result.DataLoss {
+			out.Warn(loss)
+		}
+		if !result.Applied {
+			out.Info(fmt.Sprintf("Dry run: %s (%d messages) not changed", result.Stream, result.Messages))
+			return
+		}
+		out.Success(fmt.Sprintf("Stream %s migrated (%d messages, %d after)", result.Stream, result.Messages, result.MessagesAfter))
+	},
+}
+
 var orgLimitsSetTier string
 
 var orgLimitsCmd = &cobra.Command{
@@ -214,12 +341,22 @@ func init() {
 
 	orgLimitsCmd.Flags().StringVar(&orgLimitsSetTier, "set", "", "set billing tier (free, pro, enterprise)")
 
+	orgMigrateStreamCmd.Flags().StringVar(&orgMigrateMaxAge, "max-age", "", "message retention, e.g. 72h")
+	orgMigrateStreamCmd.Flags().Int64Var(&orgMigrateMaxBytes, "max-bytes", 0, "stream size limit in bytes")
+	orgMigrateStreamCmd.Flags().IntVar(&orgMigrateReplicas, "replicas", 0, "stream replicas (1-5)")
+	orgMigrateStreamCmd.Flags().StringVar(&orgMigrateStorage, "storage", "", "storage type: file or memory")
+	orgMigrateStreamCmd.Flags().BoolVar(&orgMigrateDryRun, "dry-run", false, "only print the planned changes")
+	orgMigrateStreamCmd.Flags().BoolVar(&orgMigrateAllowDataLoss, "allow-data-loss", false, "apply even if stored messages would be discarded")
+
 	accountsCmd.AddCommand(accountsRebuildAllCmd)
 	accountsCmd.AddCommand(accountsVerifyAllCmd)
 	accountsCmd.AddCommand(orgCreateCmd)
 	accountsCmd.AddCommand(orgDeleteCmd)
 	accountsCmd.AddCommand(orgListCmd)
 	accountsCmd.AddCommand(orgLimitsCmd)
+	accountsCmd.AddCommand(orgDrainCmd)
+	accountsCmd.AddCommand(orgResumeCmd)
+	accountsCmd.AddCommand(orgMigrateStreamCmd)
 
 	rootCmd.AddCommand(accountsCmd)
 }
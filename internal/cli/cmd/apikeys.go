@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/filipexyz/notif/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	apiKeysAll bool
+
+	apiKeysCreateName           string
+	apiKeysCreateScopes         []string
+	apiKeysCreateMaxConnections int
+	apiKeysCreateExpiresIn      string
+
+	apiKeysRotateGracePeriod string
+)
+
+var apiKeysCmd = &cobra.Command{
+	Use:     "api-keys",
+	Aliases: []string{"apikeys"},
+	Short:   "Manage API keys",
+}
+
+var apiKeysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys of the current project",
+	Long: `List API keys with their metadata and recent usage. Secrets are never shown.
+
+Listing keys with an API key requires a self-hosted server (AUTH_MODE=local).
+
+Examples:
+  notif api-keys list
+  notif api-keys list --all
+  notif api-keys list --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		c := getClient()
+		result, err := c.APIKeyList(apiKeysAll)
+		if err != nil {
+			out.Error("Failed to list API keys: %v", err)
+			return
+		}
+
+		if jsonOutput {
+			out.JSON(result)
+			return
+		}
+
+		if result.Count == 0 {
+			out.Info("No API keys found")
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "PREFIX\tNAME\tCREATED\tLAST USED\tEVENTS (24H)\tSTATUS")
+		for _, k := range result.APIKeys {
+			lastUsed := "never"
+			if k.LastUsedAt != nil {
+				lastUsed = *k.LastUsedAt
+			}
+			var events int64
+			if k.Usage != nil {
+				events = k.Usage.Events24h
+			}
+			status := "active"
+			if k.RevokedAt != nil {
+				status = "revoked"
+			} else if k.ExpiresAt != nil {
+				if t, err := time.Parse(time.RFC3339, *k.ExpiresAt); err == nil && !t.After(time.Now()) {
+					status = "expired"
+				} else {
+					status = "expires " + *k.ExpiresAt
+				}
+			}
+			name := k.Name
+			if name == "" {
+				name = "-"
+			}
+			fmt.Fprintf(tw, "%s…\t%s\t%s\t%s\t%d\t%s\n", k.KeyPrefix, name, k.CreatedAt, lastUsed, events, status)
+		}
+		tw.Flush()
+	},
+}
+
+var apiKeysCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key in the current project",
+	Long: `Create an API key. The secret is shown once; save it.
+
+--scopes limits what the key may do: emit, subscribe, schemas:write,
+webhooks:write or admin. Without scopes the key has full access to the project.
+--expires-in makes the key stop working after a duration, in days (90d) or
+Go duration syntax (12h).
+
+Creating keys with an API key requires a self-hosted server (AUTH_MODE=local).
+
+Examples:
+  notif api-keys create --name edge-publisher --scopes emit
+  notif api-keys create --name dashboard --scopes subscribe,schemas:write
+  notif api-keys create --name ci --expires-in 90d`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		var scopes []string
+		for _, scope := range apiKeysCreateScopes {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+		req := client.CreateAPIKeyRequest{Name: apiKeysCreateName, Scopes: scopes}
+		if apiKeysCreateMaxConnections > 0 {
+			req.MaxConnections = &apiKeysCreateMaxConnections
+		}
+		if apiKeysCreateExpiresIn != "" {
+			d, err := parseExpiresIn(apiKeysCreateExpiresIn)
+			if err != nil {
+				out.Error("Invalid --expires-in: %v", err)
+				return
+			}
+			expiresAt := time.Now().Add(d).UTC()
+			req.ExpiresAt = &expiresAt
+		}
+
+		c := getClient()
+		key, err := c.APIKeyCreate(req)
+		if err != nil {
+			out.Error("Failed to create API key: %v", err)
+			return
+		}
+
+		if jsonOutput {
+			out.JSON(key)
+			return
+		}
+
+		out.Success("API key created")
+		out.KeyValue("ID", key.ID)
+		if key.Name != "" {
+			out.KeyValue("Name", key.Name)
+		}
+		scopesLabel := "full access"
+		if len(key.Scopes) > 0 {
+			scopesLabel = strings.Join(key.Scopes, ", ")
+		}
+		out.KeyValue("Scopes", scopesLabel)
+		if key.ExpiresAt != nil {
+			out.KeyValue("Expires", *key.ExpiresAt)
+		}
+		out.KeyValue("Key", key.FullKey)
+		out.Warn("Save the key - it won't be shown again!")
+	},
+}
+
+var apiKeysRotateCmd = &cobra.Command{
+	Use:   "rotate <id>",
+	Short: "Replace an API key with a new one",
+	Long: `Rotate an API key. The new key has the same name, scopes and limits and is
+shown once; save it. The old key keeps working for the grace period (default
+24h, at most 168h), so clients can switch over, then expires.
+
+Rotating keys with an API key requires a self-hosted server (AUTH_MODE=local).
+
+Examples:
+  notif api-keys rotate 6f1c...
+  notif api-keys rotate 6f1c... --grace-period 1h
+  notif api-keys rotate 6f1c... --grace-period 0s`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		c := getClient()
+		key, err := c.APIKeyRotate(args[0], apiKeysRotateGracePeriod)
+		if err != nil {
+			out.Error("Failed to rotate API key: %v", err)
+			return
+		}
+
+		if jsonOutput {
+			out.JSON(key)
+			return
+		}
+
+		out.Success("API key rotated")
+		out.KeyValue("ID", key.ID)
+		if key.ExpiresAt != nil {
+			out.KeyValue("Expires", *key.ExpiresAt)
+		}
+		out.KeyValue("Key", key.FullKey)
+		out.KeyValue("Old key valid until", key.PreviousKeyExpiresAt)
+		out.Warn("Save the key - it won't be shown again!")
+	},
+}
+
+// parseExpiresIn parses a key lifetime in days ("90d") or Go duration
+// syntax ("12h").
+func parseExpiresIn(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%q: expected a number of days like 90d", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("%q: expected days (90d) or a duration (12h)", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%q: must be positive", s)
+	}
+	return d, nil
+}
+
+func init() {
+	apiKeysListCmd.Flags().BoolVar(&apiKeysAll, "all", false, "include revoked keys")
+
+	apiKeysCreateCmd.Flags().StringVar(&apiKeysCreateName, "name", "", "key name")
+	apiKeysCreateCmd.Flags().StringSliceVar(&apiKeysCreateScopes, "scopes", nil, "comma-separated scopes (emit, subscribe, schemas:write, webhooks:write, admin); default full access")
+	apiKeysCreateCmd.Flags().IntVar(&apiKeysCreateMaxConnections, "max-connections", 0, "max concurrent WebSocket connections (default: server limit)")
+	apiKeysCreateCmd.Flags().StringVar(&apiKeysCreateExpiresIn, "expires-in", "", "key lifetime, e.g. 90d or 12h (default: never expires)")
+
+	apiKeysRotateCmd.Flags().StringVar(&apiKeysRotateGracePeriod, "grace-period", "", "how long the old key keeps working, e.g. 1h (default 24h)")
+
+	apiKeysCmd.AddCommand(apiKeysListCmd)
+	apiKeysCmd.AddCommand(apiKeysCreateCmd)
+	apiKeysCmd.AddCommand(apiKeysRotateCmd)
+	rootCmd.AddCommand(apiKeysCmd)
+}
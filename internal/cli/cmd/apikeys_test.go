@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpiresIn(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"1d":  24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"30m": 30 * time.Minute,
+	} {
+		if got, err := parseExpiresIn(in); err != nil || got != want {
+			t.Errorf("parseExpiresIn(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "xd", "0d", "-1d", "-1h", "90"} {
+		if _, err := parseExpiresIn(in); err == nil {
+			t.Errorf("parseExpiresIn(%q) = nil error, want error", in)
+		}
+	}
+}
@@ -0,0 +1,543 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/filipexyz/notif/pkg/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// applyConfig is the desired state read from a config directory. A nil
+// list means the directory doesn't manage that kind of resource, so
+// nothing of it is deleted.
+type applyConfig struct {
+	Schemas  *[]schemaSpec  `yaml:"schemas"`
+	Webhooks *[]webhookSpec `yaml:"webhooks"`
+}
+
+// schemaSpec declares a schema and the JSON Schema of its latest version.
+type schemaSpec struct {
+	Name         string   `yaml:"name"`
+	TopicPattern string   `yaml:"topic_pattern"`
+	Description  string   `yaml:"description"`
+	Tags         []string `yaml:"tags"`
+
+	// Version names a new version; unset bumps the patch version.
+	Version        string `yaml:"version"`
+	ValidationMode string `yaml:"validation_mode"`
+	OnInvalid      string `yaml:"on_invalid"`
+	Compatibility  string `yaml:"compatibility"`
+
+	// Schema is inline; SchemaFile is a JSON file relative to the config
+	// file. One is required.
+	Schema     any    `yaml:"schema"`
+	SchemaFile string `yaml:"schema_file"`
+
+	schemaJSON json.RawMessage
+	source     string
+}
+
+// webhookSpec declares a webhook, identified by its URL. Unset fields keep
+// the server's value.
+type webhookSpec struct {
+	URL          string            `yaml:"url"`
+	Topics       []string          `yaml:"topics"`
+	Enabled      *bool             `yaml:"enabled"`
+	RetryBudget  string            `yaml:"retry_budget"`
+	ContentType  string            `yaml:"content_type"`
+	BodyEncoding string            `yaml:"body_encoding"`
+	PayloadMode  string            `yaml:"payload_mode"`
+	Ordered      *bool             `yaml:"ordered"`
+	Headers      map[string]string `yaml:"headers"`
+	FallbackURLs []string          `yaml:"fallback_urls"`
+}
+
+// applyChange is one step of an apply plan.
+type applyChange struct {
+	op     string // "create", "update" or "delete"
+	kind   string // "schema" or "webhook"
+	name   string
+	detail string
+	run    func(c *client.Client) error
+}
+
+func (ch applyChange) String() string {
+	sign := map[string]string{"create": "+", "update": "~", "delete": "-"}[ch.op]
+	s := fmt.Sprintf("%s %s %s", sign, ch.kind, ch.name)
+	if ch.detail != "" {
+		s += " (" + ch.detail + ")"
+	}
+	return s
+}
+
+var (
+	applyDir    string
+	applyDryRun bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <dir>",
+	Short: "Converge schemas and webhooks to a config directory",
+	Long: `Apply schemas and webhooks declared in YAML files (*.yaml, *.yml) under a
+directory, creating, updating and deleting resources until the server matches.
+Running it again with no changes is a no-op.
+
+Each file may hold a "schemas:" and a "webhooks:" list. Schemas are matched by
+name and webhooks by URL. Resources of a kind that no file declares are left
+alone; once any file has a "schemas:" (or "webhooks:") key, schemas (webhooks)
+missing from the directory are deleted.
+
+  schemas:
+    - name: order-placed
+      topic_pattern: orders.placed
+      schema_file: order-placed.json   # or an inline "schema:"
+  webhooks:
+    - url: https://example.com/hooks/orders
+      topics: ["orders.*"]
+
+A changed JSON Schema or validation setting creates a new schema version
+(the patch version is bumped unless "version:" is set). Webhook header values
+are redacted by the server, so headers are only updated when their names change.
+
+Examples:
+  notif apply -f ./notif-config/ --dry-run
+  notif apply -f ./notif-config/`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+		if applyDir == "" {
+			out.Error("-f is required")
+			return
+		}
+
+		desired, err := loadApplyConfig(applyDir)
+		if err != nil {
+			out.Error("%v", err)
+			return
+		}
+
+		c := getClient()
+		plan, err := planApply(c, desired)
+		if err != nil {
+			out.Error("Failed to plan: %v", err)
+			return
+		}
+		if len(plan) == 0 {
+			out.Success("No changes")
+			return
+		}
+
+		for _, ch := range plan {
+			if applyDryRun {
+				fmt.Println(ch)
+				continue
+			}
+			if err := ch.run(c); err != nil {
+				out.Error("%s: %v", ch, err)
+				return
+			}
+			fmt.Println(ch)
+		}
+		if applyDryRun {
+			out.Info("Dry run: %d change(s) not applied", len(plan))
+			return
+		}
+		if slices.ContainsFunc(plan, func(ch applyChange) bool { return ch.kind == "schema" }) {
+			clearSchemaCache()
+		}
+		out.Success("Applied %d change(s)", len(plan))
+	},
+}
+
+// loadApplyConfig reads and merges every YAML file under dir.
+func loadApplyConfig(dir string) (*applyConfig, error) {
+	merged := &applyConfig{}
+	schemaNames := make(map[string]string)
+	webhookURLs := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var cfg applyConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if cfg.Schemas != nil {
+			if merged.Schemas == nil {
+				merged.Schemas = &[]schemaSpec{}
+			}
+			for _, s := range *cfg.Schemas {
+				if err := s.resolve(path); err != nil {
+					return err
+				}
+				if prev, ok := schemaNames[s.Name]; ok {
+					return fmt.Errorf("%s: schema %q is also declared in %s", path, s.Name, prev)
+				}
+				schemaNames[s.Name] = path
+				*merged.Schemas = append(*merged.Schemas, s)
+			}
+		}
+		if cfg.Webhooks != nil {
+			if merged.Webhooks == nil {
+				merged.Webhooks = &[]webhookSpec{}
+			}
+			for _, w := range *cfg.Webhooks {
+				if w.URL == "" || len(w.Topics) == 0 {
+					return fmt.Errorf("%s: webhooks need a url and topics", path)
+				}
+				if prev, ok := webhookURLs[w.URL]; ok {
+					return fmt.Errorf("%s: webhook %s is also declared in %s", path, w.URL, prev)
+				}
+				webhookURLs[w.URL] = path
+				*merged.Webhooks = append(*merged.Webhooks, w)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if merged.Schemas == nil && merged.Webhooks == nil {
+		return nil, fmt.Errorf("no schemas or webhooks declared under %s", dir)
+	}
+	return merged, nil
+}
+
+// resolve checks the spec and loads its JSON Schema.
+func (s *schemaSpec) resolve(path string) error {
+	s.source = path
+	if s.Name == "" || s.TopicPattern == "" {
+		return fmt.Errorf("%s: schemas need a name and topic_pattern", path)
+	}
+	switch {
+	case s.Schema != nil && s.SchemaFile != "":
+		return fmt.Errorf("%s: schema %q sets both schema and schema_file", path, s.Name)
+	case s.SchemaFile != "":
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(path), s.SchemaFile))
+		if err != nil {
+			return fmt.Errorf("%s: schema %q: %w", path, s.Name, err)
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("%s: schema %q: %s is not valid JSON", path, s.Name, s.SchemaFile)
+		}
+		s.schemaJSON = data
+	case s.Schema != nil:
+		data, err := json.Marshal(s.Schema)
+		if err != nil {
+			return fmt.Errorf("%s: schema %q: %w", path, s.Name, err)
+		}
+		s.schemaJSON = data
+	default:
+		return fmt.Errorf("%s: schema %q needs a schema or schema_file", path, s.Name)
+	}
+	return nil
+}
+
+// planApply diffs desired against the server and returns the changes that
+// converge them: schemas first, then webhooks, each in declaration order
+// with deletions last.
+func planApply(c *client.Client, desired *applyConfig) ([]applyChange, error) {
+	var plan []applyChange
+	if desired.Schemas != nil {
+		actual, err := c.SchemaList()
+		if err != nil {
+			return nil, fmt.Errorf("list schemas: %w", err)
+		}
+		changes, err := planSchemas(*desired.Schemas, actual.Schemas)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, changes...)
+	}
+	if desired.Webhooks != nil {
+		actual, err := c.WebhookList()
+		if err != nil {
+			return nil, fmt.Errorf("list webhooks: %w", err)
+		}
+		plan = append(plan, planWebhooks(*desired.Webhooks, actual.Webhooks)...)
+	}
+	return plan, nil
+}
+
+func planSchemas(desired []schemaSpec, actual []*client.Schema) ([]applyChange, error) {
+	byName := make(map[string]*client.Schema, len(actual))
+	for _, s := range actual {
+		byName[s.Name] = s
+	}
+
+	var plan []applyChange
+	for _, spec := range desired {
+		existing, ok := byName[spec.Name]
+		if !ok {
+			version := spec.Version
+			if version == "" {
+				version = "1.0.0"
+			}
+			plan = append(plan, applyChange{
+				op: "create", kind: "schema", name: spec.Name, detail: "version " + version,
+				run: func(c *client.Client) error {
+					if _, err := c.SchemaCreate(client.CreateSchemaRequest{
+						Name:         spec.Name,
+						TopicPattern: spec.TopicPattern,
+						Description:  spec.Description,
+						Tags:         spec.Tags,
+					}); err != nil {
+						return err
+					}
+					_, err := c.SchemaVersionCreate(spec.Name, spec.versionRequest(version, nil))
+					return err
+				},
+			})
+			continue
+		}
+		delete(byName, spec.Name)
+
+		if update, fields := spec.schemaUpdate(existing); len(fields) > 0 {
+			plan = append(plan, applyChange{
+				op: "update", kind: "schema", name: spec.Name, detail: strings.Join(fields, ", "),
+				run: func(c *client.Client) error {
+					_, err := c.SchemaUpdate(spec.Name, update)
+					return err
+				},
+			})
+		}
+
+		latest := existing.LatestVersion
+		if latest != nil && !spec.versionChanged(latest) {
+			continue
+		}
+		version := spec.Version
+		switch {
+		case latest == nil && version == "":
+			version = "1.0.0"
+		case latest != nil && version == "":
+			version = bumpPatchVersion(latest.Version)
+		case latest != nil && version == latest.Version:
+			return nil, fmt.Errorf("%s: schema %q version %s already exists with a different schema", spec.source, spec.Name, version)
+		}
+		plan = append(plan, applyChange{
+			op: "update", kind: "schema", name: spec.Name, detail: "new version " + version,
+			run: func(c *client.Client) error {
+				_, err := c.SchemaVersionCreate(spec.Name, spec.versionRequest(version, latest))
+				return err
+			},
+		})
+	}
+
+	for _, s := range actual {
+		if _, extra := byName[s.Name]; !extra {
+			continue
+		}
+		plan = append(plan, applyChange{
+			op: "delete", kind: "schema", name: s.Name,
+			run: func(c *client.Client) error { return c.SchemaDelete(s.Name) },
+		})
+	}
+	return plan, nil
+}
+
+// schemaUpdate returns the metadata update for existing and the names of
+// the fields it changes.
+func (s *schemaSpec) schemaUpdate(existing *client.Schema) (client.UpdateSchemaRequest, []string) {
+	var req client.UpdateSchemaRequest
+	var fields []string
+	if s.TopicPattern != existing.TopicPattern {
+		req.TopicPattern = s.TopicPattern
+		fields = append(fields, "topic_pattern")
+	}
+	if s.Description != "" && s.Description != existing.Description {
+		req.Description = s.Description
+		fields = append(fields, "description")
+	}
+	if s.Tags != nil && !slices.Equal(s.Tags, existing.Tags) {
+		req.Tags = s.Tags
+		fields = append(fields, "tags")
+	}
+	return req, fields
+}
+
+// versionChanged reports whether the spec differs from the latest version.
+func (s *schemaSpec) versionChanged(latest *client.SchemaVersion) bool {
+	if !jsonEqual(s.schemaJSON, latest.Schema) {
+		return true
+	}
+	return (s.ValidationMode != "" && s.ValidationMode != latest.ValidationMode) ||
+		(s.OnInvalid != "" && s.OnInvalid != latest.OnInvalid) ||
+		(s.Compatibility != "" && s.Compatibility != latest.Compatibility)
+}
+
+// versionRequest builds the new version; settings the spec leaves unset
+// carry over from the latest version, if any.
+func (s *schemaSpec) versionRequest(version string, latest *client.SchemaVersion) client.CreateSchemaVersionRequest {
+	req := client.CreateSchemaVersionRequest{
+		Version:        version,
+		Schema:         s.schemaJSON,
+		ValidationMode: s.ValidationMode,
+		OnInvalid:      s.OnInvalid,
+		Compatibility:  s.Compatibility,
+	}
+	if latest != nil {
+		req.ValidationMode = cmp.Or(req.ValidationMode, latest.ValidationMode)
+		req.OnInvalid = cmp.Or(req.OnInvalid, latest.OnInvalid)
+		req.Compatibility = cmp.Or(req.Compatibility, latest.Compatibility)
+	}
+	return req
+}
+
+func planWebhooks(desired []webhookSpec, actual []client.Webhook) []applyChange {
+	byURL := make(map[string]client.Webhook, len(actual))
+	for _, w := range actual {
+		byURL[w.URL] = w
+	}
+
+	var plan []applyChange
+	for _, spec := range desired {
+		existing, ok := byURL[spec.URL]
+		if !ok {
+			plan = append(plan, applyChange{
+				op: "create", kind: "webhook", name: spec.URL,
+				run: func(c *client.Client) error {
+					w, err := c.WebhookCreateWithOptions(client.CreateWebhookRequest{
+						URL:          spec.URL,
+						Topics:       spec.Topics,
+						RetryBudget:  spec.RetryBudget,
+						ContentType:  spec.ContentType,
+						BodyEncoding: spec.BodyEncoding,
+						PayloadMode:  spec.PayloadMode,
+						Ordered:      spec.Ordered != nil && *spec.Ordered,
+						Headers:      spec.Headers,
+						FallbackURLs: spec.FallbackURLs,
+					})
+					if err != nil || spec.Enabled == nil || *spec.Enabled {
+						return err
+					}
+					_, err = c.WebhookUpdate(w.ID, client.UpdateWebhookRequest{Enabled: spec.Enabled})
+					return err
+				},
+			})
+			continue
+		}
+		delete(byURL, spec.URL)
+
+		if update, fields := spec.webhookUpdate(existing); len(fields) > 0 {
+			plan = append(plan, applyChange{
+				op: "update", kind: "webhook", name: spec.URL, detail: strings.Join(fields, ", "),
+				run: func(c *client.Client) error {
+					_, err := c.WebhookUpdate(existing.ID, update)
+					return err
+				},
+			})
+		}
+	}
+
+	for _, w := range actual {
+		if _, extra := byURL[w.URL]; !extra {
+			continue
+		}
+		plan = append(plan, applyChange{
+			op: "delete", kind: "webhook", name: w.URL,
+			run: func(c *client.Client) error { return c.WebhookDelete(w.ID) },
+		})
+	}
+	return plan
+}
+
+// webhookUpdate returns the update for existing and the names of the
+// fields it changes.
+func (w *webhookSpec) webhookUpdate(existing client.Webhook) (client.UpdateWebhookRequest, []string) {
+	var req client.UpdateWebhookRequest
+	var fields []string
+	if !sameSet(w.Topics, existing.Topics) {
+		req.Topics = w.Topics
+		fields = append(fields, "topics")
+	}
+	if w.Enabled != nil && *w.Enabled != existing.Enabled {
+		req.Enabled = w.Enabled
+		fields = append(fields, "enabled")
+	}
+	if w.RetryBudget != "" && w.RetryBudget != existing.RetryBudget {
+		req.RetryBudget = w.RetryBudget
+		fields = append(fields, "retry_budget")
+	}
+	if w.ContentType != "" && w.ContentType != existing.ContentType {
+		req.ContentType = w.ContentType
+		fields = append(fields, "content_type")
+	}
+	if w.BodyEncoding != "" && w.BodyEncoding != existing.BodyEncoding {
+		req.BodyEncoding = w.BodyEncoding
+		fields = append(fields, "body_encoding")
+	}
+	if w.PayloadMode != "" && w.PayloadMode != existing.PayloadMode {
+		req.PayloadMode = w.PayloadMode
+		fields = append(fields, "payload_mode")
+	}
+	if w.Ordered != nil && *w.Ordered != existing.Ordered {
+		req.Ordered = w.Ordered
+		fields = append(fields, "ordered")
+	}
+	// Values come back redacted, so only a change of names is visible
+	if w.Headers != nil && !sameSet(mapKeys(w.Headers), mapKeys(existing.Headers)) {
+		req.Headers = w.Headers
+		fields = append(fields, "headers")
+	}
+	// Fallbacks are tried in order, so order is a change too
+	if w.FallbackURLs != nil && !slices.Equal(w.FallbackURLs, existing.FallbackURLs) {
+		req.FallbackURLs = &w.FallbackURLs
+		fields = append(fields, "fallback_urls")
+	}
+	return req, fields
+}
+
+// jsonEqual reports whether a and b hold the same JSON value.
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return bytes.Equal(ca, cb)
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, strings.ToLower(k))
+	}
+	return keys
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringVarP(&applyDir, "file", "f", "", "config directory (required)")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "show the plan without applying it")
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+// mockConfigServer keeps schemas and webhooks in memory behind the same
+// endpoints notif apply uses.
+type mockConfigServer struct {
+	mu       sync.Mutex
+	schemas  map[string]*client.Schema
+	webhooks map[string]*client.Webhook
+	nextID   int
+	writes   int
+}
+
+func newMockConfigServer(t *testing.T) (*mockConfigServer, *client.Client) {
+	t.Helper()
+	m := &mockConfigServer{schemas: map[string]*client.Schema{}, webhooks: map[string]*client.Webhook{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/schemas", func(w http.ResponseWriter, r *http.Request) {
+		list := []*client.Schema{}
+		for _, s := range m.schemas {
+			list = append(list, s)
+		}
+		json.NewEncoder(w).Encode(client.SchemaListResponse{Schemas: list, Count: len(list)})
+	})
+	mux.HandleFunc("POST /api/v1/schemas", func(w http.ResponseWriter, r *http.Request) {
+		var req client.CreateSchemaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s := &client.Schema{ID: m.id("sch"), Name: req.Name, TopicPattern: req.TopicPattern, Description: req.Description, Tags: req.Tags}
+		m.schemas[req.Name] = s
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(s)
+	})
+	mux.HandleFunc("PUT /api/v1/schemas/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var req client.UpdateSchemaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		s := m.schemas[r.PathValue("name")]
+		if req.TopicPattern != "" {
+			s.TopicPattern = req.TopicPattern
+		}
+		json.NewEncoder(w).Encode(s)
+	})
+	mux.HandleFunc("DELETE /api/v1/schemas/{name}", func(w http.ResponseWriter, r *http.Request) {
+		delete(m.schemas, r.PathValue("name"))
+		json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+	})
+	mux.HandleFunc("POST /api/v1/schemas/{name}/versions", func(w http.ResponseWriter, r *http.Request) {
+		var req client.CreateSchemaVersionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		v := &client.SchemaVersion{
+			ID:             m.id("ver"),
+			Version:        req.Version,
+			Schema:         req.Schema,
+			ValidationMode: defaultString(req.ValidationMode, "strict"),
+			OnInvalid:      defaultString(req.OnInvalid, "reject"),
+			Compatibility:  defaultString(req.Compatibility, "backward"),
+		}
+		m.schemas[r.PathValue("name")].LatestVersion = v
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(v)
+	})
+	mux.HandleFunc("GET /api/v1/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		list := []client.Webhook{}
+		for _, wh := range m.webhooks {
+			list = append(list, *wh)
+		}
+		json.NewEncoder(w).Encode(client.WebhookListResponse{Webhooks: list, Count: len(list)})
+	})
+	mux.HandleFunc("POST /api/v1/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		var req client.CreateWebhookRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		wh := &client.Webhook{ID: m.id("wh"), URL: req.URL, Topics: req.Topics, Enabled: true, Ordered: req.Ordered}
+		m.webhooks[wh.ID] = wh
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(wh)
+	})
+	mux.HandleFunc("PUT /api/v1/webhooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var req client.UpdateWebhookRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		wh := m.webhooks[r.PathValue("id")]
+		if req.Topics != nil {
+			wh.Topics = req.Topics
+		}
+		if req.Enabled != nil {
+			wh.Enabled = *req.Enabled
+		}
+		json.NewEncoder(w).Encode(wh)
+	})
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		delete(m.webhooks, r.PathValue("id"))
+		json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if r.Method != http.MethodGet {
+			m.writes++
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return m, client.New("nsh_test", client.WithServer(srv.URL))
+}
+
+func (m *mockConfigServer) id(prefix string) string {
+	m.nextID++
+	return fmt.Sprintf("%s_%d", prefix, m.nextID)
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func writeConfigFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runApply(t *testing.T, c *client.Client, dir string) []applyChange {
+	t.Helper()
+	desired, err := loadApplyConfig(dir)
+	if err != nil {
+		t.Fatalf("loadApplyConfig: %v", err)
+	}
+	plan, err := planApply(c, desired)
+	if err != nil {
+		t.Fatalf("planApply: %v", err)
+	}
+	for _, ch := range plan {
+		if err := ch.run(c); err != nil {
+			t.Fatalf("%s: %v", ch, err)
+		}
+	}
+	return plan
+}
+
+func TestApply_CreatesThenNoOp(t *testing.T) {
+	m, c := newMockConfigServer(t)
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "schemas.yaml", `
+schemas:
+  - name: order-placed
+    topic_pattern: orders.placed
+    validation_mode: warn
+    schema:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+  - name: user-signup
+    topic_pattern: users.signup
+    schema_file: user-signup.json
+`)
+	writeConfigFile(t, dir, "user-signup.json", `{"type": "object", "properties": {"email": {"type": "string"}}}`)
+	if err := os.Mkdir(filepath.Join(dir, "hooks"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfigFile(t, filepath.Join(dir, "hooks"), "webhooks.yml", `
+webhooks:
+  - url: https://example.com/orders
+    topics: ["orders.*"]
+  - url: https://example.com/paused
+    topics: ["users.*"]
+    enabled: false
+`)
+
+	plan := runApply(t, c, dir)
+	if len(plan) != 4 {
+		t.Fatalf("first apply made %d changes, want 4: %v", len(plan), plan)
+	}
+	if s := m.schemas["order-placed"]; s == nil || s.LatestVersion == nil || s.LatestVersion.Version != "1.0.0" || s.LatestVersion.ValidationMode != "warn" {
+		t.Errorf("order-placed = %+v", s)
+	}
+	if s := m.schemas["user-signup"]; s == nil || s.LatestVersion == nil || !jsonEqual(s.LatestVersion.Schema, json.RawMessage(`{"properties":{"email":{"type":"string"}},"type":"object"}`)) {
+		t.Errorf("user-signup = %+v", s)
+	}
+	enabled := map[string]bool{}
+	for _, wh := range m.webhooks {
+		enabled[wh.URL] = wh.Enabled
+	}
+	if len(enabled) != 2 || !enabled["https://example.com/orders"] || enabled["https://example.com/paused"] {
+		t.Errorf("webhooks enabled = %v", enabled)
+	}
+
+	writes := m.writes
+	if plan := runApply(t, c, dir); len(plan) != 0 {
+		t.Errorf("second apply planned %v, want no changes", plan)
+	}
+	if m.writes != writes {
+		t.Errorf("second apply made %d writes", m.writes-writes)
+	}
+}
+
+func TestApply_UpdatesAndDeletes(t *testing.T) {
+	m, c := newMockConfigServer(t)
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "notif.yaml", `
+schemas:
+  - name: order-placed
+    topic_pattern: orders.placed
+    schema: {type: object}
+  - name: legacy
+    topic_pattern: legacy.>
+    schema: {type: object}
+webhooks:
+  - url: https://example.com/orders
+    topics: ["orders.*"]
+  - url: https://example.com/legacy
+    topics: ["legacy.>"]
+`)
+	runApply(t, c, dir)
+
+	writeConfigFile(t, dir, "notif.yaml", `
+schemas:
+  - name: order-placed
+    topic_pattern: orders.placed
+    schema: {type: object, required: [id]}
+webhooks:
+  - url: https://example.com/orders
+    topics: ["orders.*", "refunds.*"]
+`)
+	plan := runApply(t, c, dir)
+
+	var got []string
+	for _, ch := range plan {
+		got = append(got, ch.String())
+	}
+	want := []string{
+		"~ schema order-placed (new version 1.0.1)",
+		"- schema legacy",
+		"~ webhook https://example.com/orders (topics)",
+		"- webhook https://example.com/legacy",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+	if len(m.schemas) != 1 || m.schemas["order-placed"].LatestVersion.Version != "1.0.1" {
+		t.Errorf("schemas = %v", m.schemas)
+	}
+	if len(m.webhooks) != 1 {
+		t.Errorf("webhooks = %v", m.webhooks)
+	}
+}
+
+func TestApply_LeavesUndeclaredKinds(t *testing.T) {
+	m, c := newMockConfigServer(t)
+	m.webhooks["wh_existing"] = &client.Webhook{ID: "wh_existing", URL: "https://example.com/x", Topics: []string{"x"}, Enabled: true}
+
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "schemas.yaml", "schemas: []\n")
+	if plan := runApply(t, c, dir); len(plan) != 0 {
+		t.Errorf("plan = %v, want no changes", plan)
+	}
+	if len(m.webhooks) != 1 {
+		t.Error("webhook deleted by a config that doesn't declare webhooks")
+	}
+}
+
+func TestLoadApplyConfig_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"duplicate schema":       "schemas:\n  - {name: a, topic_pattern: a, schema: {}}\n  - {name: a, topic_pattern: a, schema: {}}\n",
+		"schema without body":    "schemas:\n  - {name: a, topic_pattern: a}\n",
+		"webhook without topics": "webhooks:\n  - {url: https://example.com}\n",
+		"nothing declared":       "other: true\n",
+	} {
+		dir := t.TempDir()
+		writeConfigFile(t, dir, "notif.yaml", content)
+		if _, err := loadApplyConfig(dir); err == nil {
+			t.Errorf("%s: loadApplyConfig = nil error, want error", name)
+		}
+	}
+}
@@ -11,22 +11,29 @@ import (
 var (
 	auditOrg    string
 	auditAction string
+	auditActor  string
 	auditSince  string
+	auditFrom   string
+	auditTo     string
+	auditCursor int64
 	auditLimit  int
 )
 
 var auditCmd = &cobra.Command{
 	Use:   "audit",
 	Short: "Query the audit log",
-	Long: `View audit log entries for security-sensitive operations.
+	Long: `View audit log entries for security-sensitive operations, newest first.
+Requires an API key with the admin scope.
 
 Examples:
   notif audit
   notif audit --org org_1 --since 1h
   notif audit --action event.emit --limit 10
+  notif audit --actor api:key_abc --from 2026-01-01T00:00:00Z --to 2026-02-01T00:00:00Z
+  notif audit --cursor 1234    # next page, from the previous page's cursor
   notif audit --json`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -35,7 +42,11 @@ Examples:
 		result, err := c.AuditList(client.AuditQueryOptions{
 			Org:    auditOrg,
 			Action: auditAction,
+			Actor:  auditActor,
 			Since:  auditSince,
+			From:   auditFrom,
+			To:     auditTo,
+			Before: auditCursor,
 			Limit:  auditLimit,
 		})
 		if err != nil {
@@ -44,7 +55,7 @@ Examples:
 		}
 
 		if jsonOutput {
-			out.JSON(result.Entries)
+			out.JSON(result)
 			return
 		}
 
@@ -57,7 +68,7 @@ Examples:
 		out.Divider()
 
 		for _, entry := range result.Entries {
-			out.Info("[%d] %s  %s", entry.ID, entry.Timestamp, entry.Action)
+			out.Info("[%d] %s  %s", entry.ID, entry.CreatedAt, entry.Action)
 			out.KeyValue("Actor", entry.Actor)
 			if entry.OrgID != "" {
 				out.KeyValue("Org", entry.OrgID)
@@ -68,9 +79,9 @@ Examples:
 			if entry.IPAddress != "" {
 				out.KeyValue("IP", entry.IPAddress)
 			}
-			if entry.Detail != nil {
+			if entry.Metadata != nil {
 				var pretty map[string]any
-				if json.Unmarshal(entry.Detail, &pretty) == nil {
+				if json.Unmarshal(entry.Metadata, &pretty) == nil {
 					for k, v := range pretty {
 						out.KeyValue(fmt.Sprintf("  %s", k), fmt.Sprintf("%v", v))
 					}
@@ -78,13 +89,20 @@ Examples:
 			}
 			out.Divider()
 		}
+		if result.NextCursor != 0 {
+			out.Info("More entries: notif audit --cursor %d", result.NextCursor)
+		}
 	},
 }
 
 func init() {
 	auditCmd.Flags().StringVar(&auditOrg, "org", "", "filter by organization ID")
 	auditCmd.Flags().StringVar(&auditAction, "action", "", "filter by action (e.g. event.emit)")
+	auditCmd.Flags().StringVar(&auditActor, "actor", "", "filter by actor (e.g. api:key_abc)")
 	auditCmd.Flags().StringVar(&auditSince, "since", "", "filter events since duration (e.g. 1h, 30m)")
+	auditCmd.Flags().StringVar(&auditFrom, "from", "", "entries at or after this time (RFC 3339 or Unix seconds)")
+	auditCmd.Flags().StringVar(&auditTo, "to", "", "entries before this time (RFC 3339 or Unix seconds)")
+	auditCmd.Flags().Int64Var(&auditCursor, "cursor", 0, "resume after a previous page's cursor")
 	auditCmd.Flags().IntVar(&auditLimit, "limit", 50, "maximum number of entries to return")
 
 	rootCmd.AddCommand(auditCmd)
@@ -25,8 +25,8 @@ var authCmd = &cobra.Command{
 
 		// Save to config
 		cfg.APIKey = apiKey
-		if serverURL != "" {
-			cfg.Server = serverURL
+		if serverFlag != "" {
+			cfg.Server = serverFlag
 		}
 
 		if err := config.Save(cfg, cfgFile); err != nil {
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/filipexyz/notif/internal/cli/config"
+	"github.com/filipexyz/notif/internal/federation"
+	"github.com/filipexyz/notif/internal/interceptor"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +27,7 @@ var configShowCmd = &cobra.Command{
 		if jsonOutput {
 			out.JSON(map[string]any{
 				"path":    path,
-				"api_key": maskAPIKey(cfg.APIKey),
+				"api_key": maskAPIKey(apiKey),
 				"server":  serverURL,
 			})
 			return
@@ -30,11 +35,97 @@ var configShowCmd = &cobra.Command{
 
 		out.Header("Configuration")
 		out.KeyValue("Path", path)
-		out.KeyValue("API Key", maskAPIKey(cfg.APIKey))
+		out.KeyValue("API Key", maskAPIKey(apiKey))
 		out.KeyValue("Server", serverURL)
 	},
 }
 
+var (
+	configValidateInterceptors string
+	configValidateFederation   string
+)
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate interceptor and federation config files",
+	Long: `Load and check notifd config files without starting the server.
+
+Reports every problem found (bad subjects, jq that doesn't compile, unknown
+bridge directions, ...) and exits non-zero if any file is invalid.
+
+Examples:
+  notif config validate --interceptors interceptors.yaml
+  notif config validate --interceptors interceptors.yaml --federation federation.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if configValidateInterceptors == "" && configValidateFederation == "" {
+			out.Error("--interceptors or --federation is required")
+			os.Exit(1)
+		}
+
+		type fileResult struct {
+			Kind   string   `json:"kind"`
+			Path   string   `json:"path"`
+			Valid  bool     `json:"valid"`
+			Errors []string `json:"errors,omitempty"`
+		}
+		var results []fileResult
+		valid := true
+		for _, f := range []struct{ kind, path string }{
+			{"interceptors", configValidateInterceptors},
+			{"federation", configValidateFederation},
+		} {
+			if f.path == "" {
+				continue
+			}
+			errs := validateConfigFile(f.kind, f.path)
+			r := fileResult{Kind: f.kind, Path: f.path, Valid: len(errs) == 0}
+			for _, err := range errs {
+				r.Errors = append(r.Errors, err.Error())
+			}
+			results = append(results, r)
+			valid = valid && r.Valid
+		}
+
+		if jsonOutput {
+			out.JSON(map[string]any{"valid": valid, "files": results})
+		} else {
+			for _, r := range results {
+				if r.Valid {
+					out.Success("%s: valid %s config", r.Path, r.Kind)
+					continue
+				}
+				out.Error("%s: %d problem(s) in %s config", r.Path, len(r.Errors), r.Kind)
+				for _, e := range r.Errors {
+					fmt.Fprintf(os.Stderr, "  - %s\n", e)
+				}
+			}
+		}
+		if !valid {
+			os.Exit(1)
+		}
+	},
+}
+
+// validateConfigFile loads a notifd config file of the given kind and
+// returns every problem found.
+func validateConfigFile(kind, path string) []error {
+	switch kind {
+	case "interceptors":
+		c, err := interceptor.LoadConfig(path)
+		if err != nil {
+			return []error{err}
+		}
+		return c.Validate()
+	case "federation":
+		c, err := federation.LoadConfig(path)
+		if err != nil {
+			return []error{err}
+		}
+		return c.Validate()
+	}
+	return []error{fmt.Errorf("unknown config kind %q", kind)}
+}
+
 func maskAPIKey(key string) string {
 	if key == "" {
 		return "(not set)"
@@ -46,6 +137,10 @@ func maskAPIKey(key string) string {
 }
 
 func init() {
+	configValidateCmd.Flags().StringVar(&configValidateInterceptors, "interceptors", "", "interceptor config file to validate")
+	configValidateCmd.Flags().StringVar(&configValidateFederation, "federation", "", "federation config file to validate")
+
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
 	rootCmd.AddCommand(configCmd)
 }
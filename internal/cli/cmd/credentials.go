@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/filipexyz/notif/internal/cli/config"
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+// credentials are the server and API key a command talks to.
+type credentials struct {
+	Server string
+	APIKey string
+}
+
+// resolveCredentials picks the server and API key, each from the first
+// source that sets it:
+//
+//  1. the --server and --api-key flags
+//  2. NOTIF_SERVER, and NOTIF_JWT or NOTIF_API_KEY (a JWT wins when both
+//     are set, so the web terminal's session token is used)
+//  3. files, in order: a project file such as .notif.yaml before the
+//     user's config
+//
+// The server falls back to client.DefaultServer. Every command resolves
+// credentials here, so a flag always beats the environment and the
+// environment always beats a file.
+func resolveCredentials(flags credentials, getenv func(string) string, files ...credentials) credentials {
+	env := credentials{Server: getenv("NOTIF_SERVER"), APIKey: getenv("NOTIF_JWT")}
+	if env.APIKey == "" {
+		env.APIKey = getenv("NOTIF_API_KEY")
+	}
+
+	var resolved credentials
+	for _, src := range append([]credentials{flags, env}, files...) {
+		if resolved.Server == "" {
+			resolved.Server = src.Server
+		}
+		if resolved.APIKey == "" {
+			resolved.APIKey = src.APIKey
+		}
+	}
+	if resolved.Server == "" {
+		resolved.Server = client.DefaultServer
+	}
+	return resolved
+}
+
+// fileCredentials returns the credentials saved in the user's config.
+func fileCredentials(c *config.Config) credentials {
+	return credentials{Server: c.Server, APIKey: c.APIKey}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+func TestResolveCredentials(t *testing.T) {
+	flags := credentials{Server: "https://flag.example", APIKey: "nsh_flag"}
+	project := credentials{Server: "https://project.example"}
+	user := credentials{Server: "https://config.example", APIKey: "nsh_config"}
+	allEnv := map[string]string{
+		"NOTIF_SERVER":  "https://env.example",
+		"NOTIF_JWT":     "jwt_env",
+		"NOTIF_API_KEY": "nsh_env",
+	}
+
+	tests := []struct {
+		name  string
+		flags credentials
+		env   map[string]string
+		files []credentials
+		want  credentials
+	}{
+		{
+			name:  "flags beat env and config",
+			flags: flags,
+			env:   allEnv,
+			files: []credentials{project, user},
+			want:  flags,
+		},
+		{
+			name:  "env beats config",
+			env:   allEnv,
+			files: []credentials{project, user},
+			want:  credentials{Server: "https://env.example", APIKey: "jwt_env"},
+		},
+		{
+			name: "JWT beats API key in env",
+			env:  map[string]string{"NOTIF_JWT": "jwt_env", "NOTIF_API_KEY": "nsh_env"},
+			want: credentials{Server: client.DefaultServer, APIKey: "jwt_env"},
+		},
+		{
+			name:  "API key env without JWT",
+			env:   map[string]string{"NOTIF_API_KEY": "nsh_env"},
+			files: []credentials{user},
+			want:  credentials{Server: "https://config.example", APIKey: "nsh_env"},
+		},
+		{
+			name:  "config when nothing else is set",
+			files: []credentials{user},
+			want:  user,
+		},
+		{
+			name:  "project file before user config",
+			files: []credentials{project, user},
+			want:  credentials{Server: "https://project.example", APIKey: "nsh_config"},
+		},
+		{
+			name:  "each value resolved independently",
+			flags: credentials{APIKey: "nsh_flag"},
+			env:   map[string]string{"NOTIF_SERVER": "https://env.example"},
+			files: []credentials{user},
+			want:  credentials{Server: "https://env.example", APIKey: "nsh_flag"},
+		},
+		{
+			name: "default server",
+			want: credentials{Server: client.DefaultServer},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := resolveCredentials(tt.flags, getenv, tt.files...); got != tt.want {
+				t.Errorf("resolveCredentials() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRootResolvesCredentials(t *testing.T) {
+	t.Setenv("NOTIF_SERVER", "https://env.example")
+	t.Setenv("NOTIF_JWT", "")
+	t.Setenv("NOTIF_API_KEY", "nsh_env")
+	t.Cleanup(func() { serverFlag, apiKeyFlag = "", "" })
+
+	// The flag wins for the key; the server comes from the environment.
+	rootCmd.SetArgs([]string{"--config", t.TempDir() + "/missing.json", "--api-key", "nsh_flag", "version"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if apiKey != "nsh_flag" || serverURL != "https://env.example" {
+		t.Errorf("resolved key=%q server=%q", apiKey, serverURL)
+	}
+	if cfg.APIKey != "" {
+		t.Errorf("cfg.APIKey = %q, the config file must not pick up flag or env values", cfg.APIKey)
+	}
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/filipexyz/notif/pkg/client"
 	"github.com/spf13/cobra"
 )
 
@@ -14,18 +17,27 @@ var dlqCmd = &cobra.Command{
 
 var dlqListTopic string
 var dlqListLimit int
+var dlqListOlderThan time.Duration
+var dlqListMinAttempts int
+var dlqListConsumerGroup string
 
 var dlqListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List messages in the DLQ",
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
 
 		c := getClient()
-		result, err := c.DLQList(dlqListTopic, dlqListLimit)
+		result, err := c.DLQListWithOptions(client.DLQListOptions{
+			Topic:         dlqListTopic,
+			Limit:         dlqListLimit,
+			OlderThan:     dlqListOlderThan,
+			MinAttempts:   dlqListMinAttempts,
+			ConsumerGroup: dlqListConsumerGroup,
+		})
 		if err != nil {
 			out.Error("Failed to list DLQ: %v", err)
 			return
@@ -62,12 +74,59 @@ var dlqListCmd = &cobra.Command{
 	},
 }
 
+var dlqStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Count DLQ messages by topic and consumer group",
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		c := getClient()
+		stats, err := c.DLQStats()
+		if err != nil {
+			out.Error("Failed to get DLQ stats: %v", err)
+			return
+		}
+
+		if jsonOutput {
+			out.JSON(stats)
+			return
+		}
+
+		out.Header("Dead Letter Queue")
+		out.KeyValue("Total", strconv.FormatInt(stats.Total, 10))
+		if len(stats.ByTopic) > 0 {
+			out.Divider()
+			out.Info("By topic")
+			printDLQCounts(stats.ByTopic)
+		}
+		if len(stats.ByConsumerGroup) > 0 {
+			out.Divider()
+			out.Info("By consumer group")
+			printDLQCounts(stats.ByConsumerGroup)
+		}
+	},
+}
+
+func printDLQCounts(counts map[string]int64) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out.KeyValue(k, strconv.FormatInt(counts[k], 10))
+	}
+}
+
 var dlqReplayCmd = &cobra.Command{
 	Use:   "replay <seq>",
 	Short: "Replay a DLQ message to its original topic",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -98,7 +157,7 @@ var dlqDeleteCmd = &cobra.Command{
 	Short: "Delete a message from the DLQ",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -130,7 +189,7 @@ var dlqReplayAllCmd = &cobra.Command{
 	Use:   "replay-all",
 	Short: "Replay all messages from the DLQ",
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -152,18 +211,23 @@ var dlqReplayAllCmd = &cobra.Command{
 }
 
 var dlqPurgeTopic string
+var dlqPurgeOlderThan time.Duration
 
 var dlqPurgeCmd = &cobra.Command{
 	Use:   "purge",
 	Short: "Delete all messages from the DLQ",
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
 
 		c := getClient()
-		result, err := c.DLQPurge(dlqPurgeTopic)
+		opts := client.DLQPurgeOptions{Topic: dlqPurgeTopic}
+		if dlqPurgeOlderThan > 0 {
+			opts.Before = time.Now().Add(-dlqPurgeOlderThan)
+		}
+		result, err := c.DLQPurgeWithOptions(opts)
 		if err != nil {
 			out.Error("Failed to purge: %v", err)
 			return
@@ -181,11 +245,16 @@ var dlqPurgeCmd = &cobra.Command{
 func init() {
 	dlqListCmd.Flags().StringVar(&dlqListTopic, "topic", "", "filter by topic")
 	dlqListCmd.Flags().IntVar(&dlqListLimit, "limit", 100, "max messages to list")
+	dlqListCmd.Flags().DurationVar(&dlqListOlderThan, "older-than", 0, "only messages that failed at least this long ago (e.g. 1h)")
+	dlqListCmd.Flags().IntVar(&dlqListMinAttempts, "min-attempts", 0, "only messages with at least this many attempts")
+	dlqListCmd.Flags().StringVar(&dlqListConsumerGroup, "consumer-group", "", "filter by consumer group")
 
 	dlqReplayAllCmd.Flags().StringVar(&dlqReplayAllTopic, "topic", "", "filter by topic")
 	dlqPurgeCmd.Flags().StringVar(&dlqPurgeTopic, "topic", "", "filter by topic")
+	dlqPurgeCmd.Flags().DurationVar(&dlqPurgeOlderThan, "older-than", 0, "only messages that failed at least this long ago (e.g. 24h)")
 
 	dlqCmd.AddCommand(dlqListCmd)
+	dlqCmd.AddCommand(dlqStatsCmd)
 	dlqCmd.AddCommand(dlqReplayCmd)
 	dlqCmd.AddCommand(dlqDeleteCmd)
 	dlqCmd.AddCommand(dlqReplayAllCmd)
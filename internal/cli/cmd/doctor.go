@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/filipexyz/notif/internal/cli/doctor"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems",
+	Long:  `Run diagnostics and report problems with suggested fixes.`,
+}
+
+var doctorServerCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Report server-side health",
+	Long: `Query the server's health and stats endpoints and report NATS and
+database status, DLQ depth, webhook failure rate, schedule backlog and
+stream storage usage.
+
+Exits non-zero if any check fails.
+
+Examples:
+  notif doctor server
+  notif doctor server --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			os.Exit(1)
+		}
+
+		report := doctor.Server(getClient())
+
+		if jsonOutput {
+			out.JSON(report)
+		} else {
+			out.Header("Server diagnostics: " + report.Server)
+			for _, check := range report.Checks {
+				switch check.Status {
+				case doctor.StatusOK:
+					out.Success("%-24s %s", check.Name, check.Detail)
+				case doctor.StatusWarn:
+					out.Warn("%-24s %s", check.Name, check.Detail)
+				case doctor.StatusFail:
+					out.Error("%-24s %s", check.Name, check.Detail)
+				default:
+					out.Info("%-24s %s", check.Name, check.Detail)
+				}
+			}
+		}
+
+		if !report.Healthy() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.AddCommand(doctorServerCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -24,6 +24,8 @@ var (
 	rawOutput      bool
 	scheduleAt     string
 	scheduleIn     string
+	scheduleCron   string
+	scheduleMaxRun int
 	dataFlag       string
 )
 
@@ -45,6 +47,10 @@ Schedule for later:
   notif emit orders.reminder '{"id": 123}' --at "2024-01-15T10:00:00Z"
   notif emit orders.reminder '{"id": 123}' --in 30m
 
+Recurring schedule (cron, UTC):
+  notif emit reports.daily '{}' --cron "0 9 * * mon-fri"
+  notif emit reports.daily '{}' --cron @hourly --max-occurrences 24
+
 Request-response mode (wait for reply):
   notif emit orders.create '{"id": 123}' \
     --reply-to 'orders.created,orders.failed' \
@@ -57,7 +63,7 @@ Note: If your shell expands special characters like ! (bash history), use:
   printf '{"text":"Hello!"}' | notif emit topic`,
 	Args: cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -100,7 +106,7 @@ Note: If your shell expands special characters like ! (bash history), use:
 		c := getClient()
 
 		// Schedule mode
-		if scheduleAt != "" || scheduleIn != "" {
+		if scheduleAt != "" || scheduleIn != "" || scheduleCron != "" {
 			var scheduledFor *time.Time
 			if scheduleAt != "" {
 				t, err := time.Parse(time.RFC3339, scheduleAt)
@@ -111,7 +117,14 @@ Note: If your shell expands special characters like ! (bash history), use:
 				scheduledFor = &t
 			}
 
-			resp, err := c.Schedule(topic, json.RawMessage(data), scheduledFor, scheduleIn)
+			resp, err := c.CreateSchedule(client.ScheduleRequest{
+				Topic:          topic,
+				Data:           json.RawMessage(data),
+				ScheduledFor:   scheduledFor,
+				In:             scheduleIn,
+				Cron:           scheduleCron,
+				MaxOccurrences: scheduleMaxRun,
+			})
 			if err != nil {
 				if jsonOutput {
 					out.JSON(map[string]any{"error": err.Error()})
@@ -130,6 +143,9 @@ Note: If your shell expands special characters like ! (bash history), use:
 			out.KeyValue("ID", resp.ID)
 			out.KeyValue("Topic", resp.Topic)
 			out.KeyValue("Scheduled For", resp.ScheduledFor.Format("2006-01-02 15:04:05 MST"))
+			if resp.Cron != "" {
+				out.KeyValue("Cron", resp.Cron)
+			}
 			return
 		}
 
@@ -260,5 +276,7 @@ func init() {
 	emitCmd.Flags().BoolVar(&rawOutput, "raw", false, "output only the data field (for hooks/pipes)")
 	emitCmd.Flags().StringVar(&scheduleAt, "at", "", "schedule for specific time (RFC3339, e.g., 2024-01-15T10:00:00Z)")
 	emitCmd.Flags().StringVar(&scheduleIn, "in", "", "schedule after delay (e.g., 5m, 1h, 30s)")
+	emitCmd.Flags().StringVar(&scheduleCron, "cron", "", "schedule on a recurring cron expression, UTC (e.g., \"0 9 * * mon-fri\", @hourly)")
+	emitCmd.Flags().IntVar(&scheduleMaxRun, "max-occurrences", 0, "stop a --cron schedule after this many runs (0 = unlimited)")
 	rootCmd.AddCommand(emitCmd)
 }
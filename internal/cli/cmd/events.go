@@ -1,24 +1,37 @@
 package cmd
 
 import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/filipexyz/notif/internal/cli/display"
 	"github.com/filipexyz/notif/pkg/client"
 	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
 )
 
 var eventsCmd = &cobra.Command{
 	Use:   "events",
-	Short: "Query historical events",
-	Long:  `List and retrieve historical events from the stream.`,
+	Short: "Query and follow events",
+	Long:  `List and retrieve historical events from the stream, or follow live ones.`,
 }
 
 var (
-	eventsListTopic string
-	eventsListFrom  string
-	eventsListTo    string
-	eventsListLimit int
+	eventsListTopic  string
+	eventsListFrom   string
+	eventsListTo     string
+	eventsListLimit  int
+	eventsListFilter []string
 )
 
 var eventsListCmd = &cobra.Command{
@@ -30,9 +43,10 @@ Examples:
   notif events list
   notif events list --topic orders.created
   notif events list --topic "orders.*" --from 2024-01-01T00:00:00Z
-  notif events list --limit 50`,
+  notif events list --limit 50
+  notif events list --filter data.status=error --filter data.region=eu`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -57,10 +71,30 @@ Examples:
 		}
 
 		c := getClient()
-		result, err := c.EventsList(opts)
-		if err != nil {
-			out.Error("Failed to list events: %v", err)
-			return
+		var (
+			result any
+			events []client.StoredEvent
+		)
+		if len(eventsListFilter) > 0 {
+			found, err := c.EventsSearch(client.EventsSearchOptions{
+				Filters: eventsListFilter,
+				Topic:   opts.Topic,
+				From:    opts.From,
+				To:      opts.To,
+				Limit:   opts.Limit,
+			})
+			if err != nil {
+				out.Error("Failed to search events: %v", err)
+				return
+			}
+			result, events = found, found.Events
+		} else {
+			listed, err := c.EventsList(opts)
+			if err != nil {
+				out.Error("Failed to list events: %v", err)
+				return
+			}
+			result, events = listed, listed.Events
 		}
 
 		if jsonOutput {
@@ -68,27 +102,339 @@ Examples:
 			return
 		}
 
-		if result.Count == 0 {
+		if len(events) == 0 {
 			out.Info("No events found")
 			return
 		}
 
 		out.Header("Events")
-		out.KeyValue("Count", strconv.Itoa(result.Count))
+		out.KeyValue("Count", strconv.Itoa(len(events)))
 		out.Divider()
 
-		for _, e := range result.Events {
+		for _, e := range events {
 			out.Event(e.Event.ID, e.Event.Topic, e.Event.Data, e.Event.Timestamp)
 		}
 	},
 }
 
+var (
+	eventsExportTopic string
+	eventsExportFrom  string
+	eventsExportTo    string
+	eventsExportOut   string
+)
+
+// eventsExportPageSize is the page size used when paging through the
+// stream; it matches the server's maximum list limit.
+const eventsExportPageSize = 1000
+
+var eventsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export historical events to a file",
+	Long: `Export matching historical events as NDJSON, one event per line, oldest first.
+
+Output ending in .gz is gzip-compressed. Without --out, events are written to stdout.
+
+Examples:
+  notif events export --out events.ndjson
+  notif events export --topic "orders.*" --from 24h --out orders.ndjson.gz
+  notif events export --from 2024-01-01T00:00:00Z --to 2024-02-01T00:00:00Z > january.ndjson`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		opts := client.EventsQueryOptions{Topic: eventsExportTopic}
+		if eventsExportFrom != "" {
+			if t, err := time.Parse(time.RFC3339, eventsExportFrom); err == nil {
+				opts.From = t
+			} else if d, err := time.ParseDuration(eventsExportFrom); err == nil {
+				opts.From = time.Now().Add(-d)
+			} else {
+				out.Error("Invalid --from: %s", eventsExportFrom)
+				return
+			}
+		}
+		if eventsExportTo != "" {
+			t, err := time.Parse(time.RFC3339, eventsExportTo)
+			if err != nil {
+				out.Error("Invalid --to: %s", eventsExportTo)
+				return
+			}
+			opts.To = t
+		}
+
+		if eventsExportOut == "" || eventsExportOut == "-" {
+			if _, err := exportEvents(getClient(), opts, os.Stdout); err != nil {
+				out.Error("Failed to export events: %v", err)
+			}
+			return
+		}
+
+		n, err := exportEventsToFile(getClient(), opts, eventsExportOut)
+		if err != nil {
+			out.Error("Failed to export events: %v", err)
+			return
+		}
+		out.Success("Exported %d events to %s", n, eventsExportOut)
+	},
+}
+
+// exportEventsToFile writes matching events to path, gzip-compressed when
+// path ends in .gz. A failed export leaves no partial file behind.
+func exportEventsToFile(c *client.Client, opts client.EventsQueryOptions, path string) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var w io.Writer = f
+	var zw *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+
+	n, err := exportEvents(c, opts, w)
+	if err == nil && zw != nil {
+		err = zw.Close()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return n, nil
+}
+
+// exportEvents pages through matching events with the list cursor and
+// writes each one to w as a line of JSON. It returns the number written.
+func exportEvents(c *client.Client, opts client.EventsQueryOptions, w io.Writer) (int, error) {
+	opts.Limit = eventsExportPageSize
+	enc := json.NewEncoder(w)
+	n := 0
+	for {
+		page, err := c.EventsList(opts)
+		if err != nil {
+			return n, err
+		}
+		for _, e := range page.Events {
+			if err := enc.Encode(e.Event); err != nil {
+				return n, fmt.Errorf("write event %s: %w", e.Event.ID, err)
+			}
+			n++
+		}
+		if page.NextCursor == 0 {
+			return n, nil
+		}
+		opts.After = page.NextCursor
+	}
+}
+
+var (
+	eventsImportRemap  []string
+	eventsImportRate   float64
+	eventsImportDryRun bool
+)
+
+var eventsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Re-emit events from an export file",
+	Long: `Re-emit events from an NDJSON file written by 'notif events export'.
+
+Events are sent in batches, oldest first, under their original topics unless
+remapped with --remap old=new. A remap ending in ".>" on both sides moves a
+whole subtree. Files ending in .gz are decompressed.
+
+Examples:
+  notif events import events.ndjson
+  notif events import orders.ndjson.gz --remap "orders.>=replay.orders.>"
+  notif events import events.ndjson --rate 50
+  notif events import events.ndjson --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		remap, err := parseTopicRemap(eventsImportRemap)
+		if err != nil {
+			out.Error("%v", err)
+			return
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			out.Error("Failed to open %s: %v", args[0], err)
+			return
+		}
+		defer f.Close()
+
+		var r io.Reader = f
+		if strings.HasSuffix(args[0], ".gz") {
+			zr, err := gzip.NewReader(f)
+			if err != nil {
+				out.Error("Failed to read %s: %v", args[0], err)
+				return
+			}
+			r = zr
+		}
+
+		opts := eventsImportOptions{remap: remap, rate: eventsImportRate, dryRun: eventsImportDryRun}
+		if eventsImportDryRun {
+			summary, err := importEvents(context.Background(), nil, r, opts)
+			if err != nil {
+				out.Error("Failed to read %s: %v", args[0], err)
+				return
+			}
+			out.Info("Dry run: %d events would be emitted", summary.Emitted)
+			return
+		}
+
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		summary, err := importEvents(ctx, getClient(), r, opts)
+		for _, failure := range summary.Failures {
+			out.Warn("%s", failure)
+		}
+		if err != nil {
+			out.Error("Import stopped after %d events: %v", summary.Emitted, err)
+			return
+		}
+		if summary.Failed > 0 {
+			out.Warn("Imported %d events, %d rejected", summary.Emitted, summary.Failed)
+			return
+		}
+		out.Success("Imported %d events", summary.Emitted)
+	},
+}
+
+// topicRemap rewrites topics on import: exact topics, or subtrees when both
+// sides end in ".>".
+type topicRemap map[string]string
+
+func parseTopicRemap(specs []string) (topicRemap, error) {
+	remap := topicRemap{}
+	for _, spec := range specs {
+		from, to, ok := strings.Cut(spec, "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid --remap %q, expected old=new", spec)
+		}
+		if strings.HasSuffix(from, ".>") != strings.HasSuffix(to, ".>") {
+			return nil, fmt.Errorf("invalid --remap %q, both sides must end in .> to remap a subtree", spec)
+		}
+		remap[from] = to
+	}
+	return remap, nil
+}
+
+func (m topicRemap) apply(topic string) string {
+	if to, ok := m[topic]; ok {
+		return to
+	}
+	for from, to := range m {
+		prefix, ok := strings.CutSuffix(from, ">")
+		if ok && strings.HasPrefix(topic, prefix) {
+			return strings.TrimSuffix(to, ">") + strings.TrimPrefix(topic, prefix)
+		}
+	}
+	return topic
+}
+
+type eventsImportOptions struct {
+	remap topicRemap
+	// rate caps events per second; zero or less means unlimited.
+	rate   float64
+	dryRun bool
+}
+
+type eventsImportSummary struct {
+	Emitted  int
+	Failed   int
+	Failures []string
+}
+
+// importEvents reads exported events from r and re-emits them through c in
+// batches of client.MaxEmitBatchSize. In dry-run mode c is unused and
+// Emitted counts the events that would be sent.
+func importEvents(ctx context.Context, c *client.Client, r io.Reader, opts eventsImportOptions) (eventsImportSummary, error) {
+	var summary eventsImportSummary
+	var limiter *rate.Limiter
+	if opts.rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.rate), client.MaxEmitBatchSize)
+	}
+
+	batch := make([]client.EmitRequest, 0, client.MaxEmitBatchSize)
+	lines := make([]int, 0, client.MaxEmitBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		defer func() {
+			batch = batch[:0]
+			lines = lines[:0]
+		}()
+		if opts.dryRun {
+			summary.Emitted += len(batch)
+			return nil
+		}
+		if limiter != nil {
+			if err := limiter.WaitN(ctx, len(batch)); err != nil {
+				return err
+			}
+		}
+		resp, err := c.EmitBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		summary.Emitted += resp.Emitted
+		summary.Failed += resp.Failed
+		for i, result := range resp.Results {
+			if result.Error != "" && i < len(lines) {
+				summary.Failures = append(summary.Failures, fmt.Sprintf("line %d (%s): %s", lines[i], result.Topic, result.Error))
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var event client.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return summary, fmt.Errorf("line %d: %w", line, err)
+		}
+		if event.Topic == "" {
+			return summary, fmt.Errorf("line %d: missing topic", line)
+		}
+		batch = append(batch, client.EmitRequest{Topic: opts.remap.apply(event.Topic), Data: event.Data})
+		lines = append(lines, line)
+		if len(batch) == client.MaxEmitBatchSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, err
+	}
+	return summary, flush()
+}
+
 var eventsGetCmd = &cobra.Command{
 	Use:   "get <seq>",
 	Short: "Get a specific event by sequence number",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -125,7 +471,7 @@ var eventsStatsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show stream statistics",
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -157,6 +503,99 @@ var eventsStatsCmd = &cobra.Command{
 	},
 }
 
+var eventsTailGroup string
+
+var eventsTailCmd = &cobra.Command{
+	Use:   "tail [topic]",
+	Short: "Follow live events",
+	Long: `Print events as they are emitted, one per line, until interrupted.
+
+Without a topic every topic is followed. With --json each event is written
+as a raw JSON line; otherwise events use the schema display configs, like
+'notif subscribe'. Status messages go to stderr.
+
+Examples:
+  notif events tail
+  notif events tail "orders.*"
+  notif events tail "orders.*" --json | jq '.data.amount'
+  notif events tail "orders.*" --group ci-watcher`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		topic := "*"
+		if len(args) == 1 {
+			topic = args[0]
+		}
+		topics := []string{topic}
+		status := out.Stderr()
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		c := getClient()
+		sub, err := c.Subscribe(ctx, topics, client.SubscribeOptions{
+			AutoAck:       true,
+			Group:         eventsTailGroup,
+			From:          "latest",
+			DisplayConfig: usesSchemaDisplay(jsonOutput),
+		})
+		if err != nil {
+			out.Error("Failed to subscribe: %v", err)
+			os.Exit(1)
+		}
+		defer sub.Close()
+
+		var renderer *display.RendererManager
+		if !jsonOutput {
+			renderer = setupRenderer(ctx, c, topics)
+		}
+		status.Info("Tailing %s (Ctrl+C to exit)", topic)
+
+		for {
+			select {
+			case event, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				if jsonOutput {
+					if err := writeNDJSON(os.Stdout, event); err != nil {
+						status.Error("Failed to write event: %v", err)
+						return
+					}
+					continue
+				}
+				line, err := renderer.RenderEvent(event.ID, event.Topic, event.Data, event.Timestamp)
+				if err != nil {
+					out.Event(event.ID, event.Topic, event.Data, event.Timestamp)
+					continue
+				}
+				fmt.Println(line)
+
+			case configs := <-sub.DisplayConfigs():
+				applyDisplayConfigs(renderer, configs)
+
+			case err := <-sub.Errors():
+				// The SDK reconnects on its own
+				if _, ok := err.(*client.ReconnectedError); ok {
+					status.Success("Reconnected")
+				} else if closed, ok := err.(*client.ClosedError); ok && !closed.Reconnect {
+					out.Error("Disconnected: %v", closed)
+					return
+				} else {
+					status.Warn("Connection error: %v (reconnecting...)", err)
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	},
+}
+
 func formatBytes(b uint64) string {
 	const unit = 1024
 	if b < unit {
@@ -175,8 +614,23 @@ func init() {
 	eventsListCmd.Flags().StringVar(&eventsListFrom, "from", "", "start time (RFC3339 or duration like 1h, 24h)")
 	eventsListCmd.Flags().StringVar(&eventsListTo, "to", "", "end time (RFC3339)")
 	eventsListCmd.Flags().IntVar(&eventsListLimit, "limit", 100, "max events to return")
+	eventsListCmd.Flags().StringArrayVar(&eventsListFilter, "filter", nil, "match a data field, data.<field>=<value> (repeatable)")
+
+	eventsExportCmd.Flags().StringVar(&eventsExportTopic, "topic", "", "filter by topic (supports wildcards)")
+	eventsExportCmd.Flags().StringVar(&eventsExportFrom, "from", "", "start time (RFC3339 or duration like 1h, 24h)")
+	eventsExportCmd.Flags().StringVar(&eventsExportTo, "to", "", "end time (RFC3339)")
+	eventsExportCmd.Flags().StringVarP(&eventsExportOut, "out", "o", "", "output file (.gz to compress; default stdout)")
+
+	eventsImportCmd.Flags().StringArrayVar(&eventsImportRemap, "remap", nil, "rewrite a topic, old=new (repeatable; use .> on both sides for a subtree)")
+	eventsImportCmd.Flags().Float64Var(&eventsImportRate, "rate", 100, "max events per second (0 for unlimited)")
+	eventsImportCmd.Flags().BoolVar(&eventsImportDryRun, "dry-run", false, "count events without emitting them")
+
+	eventsTailCmd.Flags().StringVar(&eventsTailGroup, "group", "", "consumer group name (events are shared between tails in the group)")
 
 	eventsCmd.AddCommand(eventsListCmd)
+	eventsCmd.AddCommand(eventsTailCmd)
+	eventsCmd.AddCommand(eventsExportCmd)
+	eventsCmd.AddCommand(eventsImportCmd)
 	eventsCmd.AddCommand(eventsGetCmd)
 	eventsCmd.AddCommand(eventsStatsCmd)
 
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+// mockEventsServer serves n stored events from GET /api/v1/events, paging
+// with the same limit/after/next_cursor contract as the real server.
+func mockEventsServer(t *testing.T, n int) (*httptest.Server, *int) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := make([]map[string]any, n)
+	for i := range events {
+		events[i] = map[string]any{
+			"seq": i + 1,
+			"event": map[string]any{
+				"id":        fmt.Sprintf("evt_%d", i+1),
+				"topic":     "orders.created",
+				"data":      map[string]int{"n": i + 1},
+				"timestamp": base.Add(time.Duration(i) * time.Second),
+			},
+			"timestamp": base.Add(time.Duration(i) * time.Second),
+		}
+	}
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/events" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+
+		page := events[min(after, n):min(after+limit, n)]
+		resp := map[string]any{"events": page, "count": len(page)}
+		if len(page) == limit {
+			resp["next_cursor"] = after + len(page)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func readExport(t *testing.T, r io.Reader) []client.Event {
+	t.Helper()
+	var events []client.Event
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var e client.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", len(events)+1, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	return events
+}
+
+func TestExportEventsToFile(t *testing.T) {
+	// More than two pages so the cursor is followed more than once.
+	const total = 2*eventsExportPageSize + 7
+	srv, requests := mockEventsServer(t, total)
+	c := client.New("nsh_test", client.WithServer(srv.URL))
+
+	for _, name := range []string{"events.ndjson", "events.ndjson.gz"} {
+		t.Run(name, func(t *testing.T) {
+			*requests = 0
+			path := filepath.Join(t.TempDir(), name)
+
+			n, err := exportEventsToFile(c, client.EventsQueryOptions{}, path)
+			if err != nil {
+				t.Fatalf("exportEventsToFile: %v", err)
+			}
+			if n != total {
+				t.Errorf("exported %d events, want %d", n, total)
+			}
+			if *requests != 3 {
+				t.Errorf("made %d requests, want 3", *requests)
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var r io.Reader = f
+			if filepath.Ext(name) == ".gz" {
+				zr, err := gzip.NewReader(f)
+				if err != nil {
+					t.Fatalf("gzip.NewReader: %v", err)
+				}
+				r = zr
+			}
+
+			events := readExport(t, r)
+			if len(events) != total {
+				t.Fatalf("file has %d events, want %d", len(events), total)
+			}
+			for i, e := range events {
+				if want := fmt.Sprintf("evt_%d", i+1); e.ID != want {
+					t.Fatalf("line %d: id = %s, want %s", i+1, e.ID, want)
+				}
+				if string(e.Data) != fmt.Sprintf(`{"n":%d}`, i+1) {
+					t.Fatalf("line %d: data = %s", i+1, e.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestExportEventsToFile_RemovesPartialFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	c := client.New("nsh_test", client.WithServer(srv.URL))
+
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	if _, err := exportEventsToFile(c, client.EventsQueryOptions{}, path); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("partial export left behind: %v", err)
+	}
+}
+
+// mockBatchEmitServer accepts POST /api/v1/emit/batch and records every
+// batch it receives.
+func mockBatchEmitServer(t *testing.T) (*httptest.Server, *[][]client.EmitRequest) {
+	t.Helper()
+	var batches [][]client.EmitRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/emit/batch" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Events []client.EmitRequest `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		batches = append(batches, body.Events)
+
+		resp := client.EmitBatchResponse{}
+		for i, e := range body.Events {
+			result := client.EmitBatchResult{Topic: e.Topic}
+			if e.Topic == "rejected" {
+				result.Error = "rejected by test"
+				resp.Failed++
+			} else {
+				result.ID = fmt.Sprintf("evt_new_%d", i)
+				resp.Emitted++
+			}
+			resp.Results = append(resp.Results, result)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &batches
+}
+
+func exportFile(t *testing.T, n int, topic func(i int) string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := 0; i < n; i++ {
+		enc.Encode(client.Event{
+			ID:        fmt.Sprintf("evt_%d", i),
+			Topic:     topic(i),
+			Data:      json.RawMessage(fmt.Sprintf(`{"n":%d}`, i)),
+			Timestamp: time.Now(),
+		})
+	}
+	return &buf
+}
+
+func TestImportEvents(t *testing.T) {
+	srv, batches := mockBatchEmitServer(t)
+	c := client.New("nsh_test", client.WithServer(srv.URL))
+
+	const total = client.MaxEmitBatchSize + 20
+	topics := []string{"orders.created", "orders.eu.shipped", "users.signup"}
+	file := exportFile(t, total, func(i int) string { return topics[i%len(topics)] })
+
+	remap, err := parseTopicRemap([]string{"orders.>=replay.orders.>", "users.signup=replay.signup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := importEvents(context.Background(), c, file, eventsImportOptions{remap: remap})
+	if err != nil {
+		t.Fatalf("importEvents: %v", err)
+	}
+	if summary.Emitted != total || summary.Failed != 0 {
+		t.Errorf("summary = %+v, want %d emitted", summary, total)
+	}
+	if len(*batches) != 2 || len((*batches)[0]) != client.MaxEmitBatchSize {
+		t.Fatalf("got %d batches, want 2 with the first full", len(*batches))
+	}
+
+	wantTopics := []string{"replay.orders.created", "replay.orders.eu.shipped", "replay.signup"}
+	i := 0
+	for _, batch := range *batches {
+		for _, e := range batch {
+			if want := wantTopics[i%len(wantTopics)]; e.Topic != want {
+				t.Errorf("event %d: topic = %s, want %s", i, e.Topic, want)
+			}
+			if want := fmt.Sprintf(`{"n":%d}`, i); string(e.Data) != want {
+				t.Errorf("event %d: data = %s, want %s", i, e.Data, want)
+			}
+			i++
+		}
+	}
+	if i != total {
+		t.Errorf("server received %d events, want %d", i, total)
+	}
+}
+
+func TestImportEvents_ReportsRejected(t *testing.T) {
+	srv, _ := mockBatchEmitServer(t)
+	c := client.New("nsh_test", client.WithServer(srv.URL))
+
+	file := exportFile(t, 3, func(i int) string {
+		if i == 1 {
+			return "rejected"
+		}
+		return "orders.created"
+	})
+	summary, err := importEvents(context.Background(), c, file, eventsImportOptions{})
+	if err != nil {
+		t.Fatalf("importEvents: %v", err)
+	}
+	if summary.Emitted != 2 || summary.Failed != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if len(summary.Failures) != 1 || !strings.HasPrefix(summary.Failures[0], "line 2 ") {
+		t.Errorf("failures = %q", summary.Failures)
+	}
+}
+
+func TestImportEvents_DryRun(t *testing.T) {
+	file := exportFile(t, 250, func(int) string { return "orders.created" })
+	// A nil client would panic if the dry run tried to emit.
+	summary, err := importEvents(context.Background(), nil, file, eventsImportOptions{dryRun: true})
+	if err != nil {
+		t.Fatalf("importEvents: %v", err)
+	}
+	if summary.Emitted != 250 {
+		t.Errorf("dry run counted %d events, want 250", summary.Emitted)
+	}
+}
+
+func TestImportEvents_InvalidLine(t *testing.T) {
+	file := bytes.NewBufferString(`{"topic":"orders.created","data":{}}` + "\nnot json\n")
+	if _, err := importEvents(context.Background(), nil, file, eventsImportOptions{dryRun: true}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("err = %v, want line 2 error", err)
+	}
+}
+
+func TestParseTopicRemap(t *testing.T) {
+	remap, err := parseTopicRemap([]string{"a.b=c.d", "orders.>=archive.orders.>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for topic, want := range map[string]string{
+		"a.b":             "c.d",
+		"a.b.c":           "a.b.c",
+		"orders.created":  "archive.orders.created",
+		"orders.eu.x":     "archive.orders.eu.x",
+		"ordersx.created": "ordersx.created",
+	} {
+		if got := remap.apply(topic); got != want {
+			t.Errorf("apply(%q) = %q, want %q", topic, got, want)
+		}
+	}
+
+	for _, bad := range []string{"a.b", "=x", "a.>=b", "a=b.>"} {
+		if _, err := parseTopicRemap([]string{bad}); err == nil {
+			t.Errorf("parseTopicRemap(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/filipexyz/notif/pkg/client"
+	"github.com/itchyny/gojq"
+	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
+)
+
+var (
+	replayTopic         string
+	replayFrom          string
+	replayTo            string
+	replayTargetProject string
+	replayTargetKey     string
+	replayJq            string
+	replayRate          float64
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-emit historical events into another project",
+	Long: `Read matching historical events from the current project, oldest first,
+and emit them into a target project under the same topics.
+
+The target is a project slug or ID (--target-project, needs a session login)
+or the API key of the target project (--target-key). --jq rewrites each
+event's data; events for which it outputs nothing are skipped. --to defaults
+to now, so events emitted during the replay are not replayed.
+
+Examples:
+  notif replay --topic "orders.>" --from 24h --target-project staging
+  notif replay --from 2024-01-01T00:00:00Z --to 2024-01-02T00:00:00Z --target-key nsh_xxx
+  notif replay --topic "users.*" --from 1h --target-project staging --jq '.email = "redacted"'
+  notif replay --topic "orders.>" --from 1h --target-project staging --jq 'select(.total > 1000)' --rate 20`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+		if (replayTargetProject == "") == (replayTargetKey == "") {
+			out.Error("Specify exactly one of --target-project or --target-key")
+			return
+		}
+
+		opts := replayOptions{rate: replayRate}
+		opts.query.Topic = replayTopic
+		if replayFrom != "" {
+			if t, err := time.Parse(time.RFC3339, replayFrom); err == nil {
+				opts.query.From = t
+			} else if d, err := time.ParseDuration(replayFrom); err == nil {
+				opts.query.From = time.Now().Add(-d)
+			} else {
+				out.Error("Invalid --from: %s", replayFrom)
+				return
+			}
+		}
+		opts.query.To = time.Now()
+		if replayTo != "" {
+			t, err := time.Parse(time.RFC3339, replayTo)
+			if err != nil {
+				out.Error("Invalid --to: %s", replayTo)
+				return
+			}
+			opts.query.To = t
+		}
+		if replayJq != "" {
+			code, err := compileJqTransform(replayJq)
+			if err != nil {
+				out.Error("Invalid --jq: %v", err)
+				return
+			}
+			opts.jq = code
+		}
+
+		source := getClient()
+		target, err := replayTarget(source, replayTargetProject, replayTargetKey)
+		if err != nil {
+			out.Error("%v", err)
+			return
+		}
+
+		status := out.Stderr()
+		opts.progress = func(s replaySummary) {
+			status.Info("Read %d events, emitted %d", s.Read, s.Emitted)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		summary, err := replayEvents(ctx, source, target, opts)
+		for _, failure := range summary.Failures {
+			status.Warn("%s", failure)
+		}
+		if jsonOutput {
+			out.JSON(summary)
+		}
+		if err != nil {
+			out.Error("Replay stopped after %d events: %v", summary.Emitted, err)
+			return
+		}
+		if jsonOutput {
+			return
+		}
+		if summary.Failed > 0 || summary.Skipped > 0 {
+			out.Warn("Replayed %d of %d events (%d skipped, %d failed)", summary.Emitted, summary.Read, summary.Skipped, summary.Failed)
+			return
+		}
+		out.Success("Replayed %d events", summary.Emitted)
+	},
+}
+
+// replayTarget returns a client that emits into the target project: the
+// project's own API key, or the current session scoped to the project with
+// the given slug or ID.
+func replayTarget(source *client.Client, project, key string) (*client.Client, error) {
+	if key != "" {
+		return client.New(key, client.WithServer(serverURL)), nil
+	}
+
+	// An API key is bound to its project and ignores X-Project-ID, so
+	// emitting with it would replay into the source project
+	if strings.HasPrefix(apiKey, "nsh_") {
+		return nil, fmt.Errorf("--target-project needs a session login; with API keys use --target-key")
+	}
+
+	projects, err := source.ProjectList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	for _, p := range projects.Projects {
+		if p.Slug == project || p.ID == project {
+			return client.New(apiKey, client.WithServer(serverURL), client.WithProjectID(p.ID)), nil
+		}
+	}
+	return nil, fmt.Errorf("project %q not found", project)
+}
+
+// compileJqTransform parses and compiles a jq expression applied to event data.
+func compileJqTransform(expr string) (*gojq.Code, error) {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return gojq.Compile(query)
+}
+
+// transformData runs code over data and returns its first output. ok is
+// false when the expression outputs nothing, e.g. a select that rejects.
+func transformData(code *gojq.Code, data json.RawMessage) (json.RawMessage, bool, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, false, err
+	}
+	result, ok := code.Run(v).Next()
+	if !ok {
+		return nil, false, nil
+	}
+	if err, isErr := result.(error); isErr {
+		return nil, false, err
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+type replayOptions struct {
+	query client.EventsQueryOptions
+	// jq, when set, rewrites each event's data.
+	jq *gojq.Code
+	// rate caps events per second; zero or less means unlimited.
+	rate float64
+	// progress, when set, is called after each page of source events.
+	progress func(replaySummary)
+}
+
+type replaySummary struct {
+	Read     int      `json:"read"`
+	Emitted  int      `json:"emitted"`
+	Skipped  int      `json:"skipped"`
+	Failed   int      `json:"failed"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// replayEvents pages through the events in source matching opts.query and
+// emits them into target in batches of client.MaxEmitBatchSize, keeping
+// their topics.
+func replayEvents(ctx context.Context, source, target *client.Client, opts replayOptions) (replaySummary, error) {
+	var summary replaySummary
+	var limiter *rate.Limiter
+	if opts.rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.rate), client.MaxEmitBatchSize)
+	}
+
+	batch := make([]client.EmitRequest, 0, client.MaxEmitBatchSize)
+	ids := make([]string, 0, client.MaxEmitBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		defer func() {
+			batch = batch[:0]
+			ids = ids[:0]
+		}()
+		if limiter != nil {
+			if err := limiter.WaitN(ctx, len(batch)); err != nil {
+				return err
+			}
+		}
+		resp, err := target.EmitBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		summary.Emitted += resp.Emitted
+		summary.Failed += resp.Failed
+		for i, result := range resp.Results {
+			if result.Error != "" && i < len(ids) {
+				summary.Failures = append(summary.Failures, fmt.Sprintf("%s (%s): %s", ids[i], result.Topic, result.Error))
+			}
+		}
+		return nil
+	}
+
+	query := opts.query
+	query.Limit = eventsExportPageSize
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		page, err := source.EventsList(query)
+		if err != nil {
+			return summary, err
+		}
+		for _, e := range page.Events {
+			summary.Read++
+			data := e.Event.Data
+			if opts.jq != nil {
+				transformed, ok, err := transformData(opts.jq, data)
+				if err != nil {
+					summary.Failed++
+					summary.Failures = append(summary.Failures, fmt.Sprintf("%s (%s): jq: %v", e.Event.ID, e.Event.Topic, err))
+					continue
+				}
+				if !ok {
+					summary.Skipped++
+					continue
+				}
+				data = transformed
+			}
+			batch = append(batch, client.EmitRequest{Topic: e.Event.Topic, Data: data})
+			ids = append(ids, e.Event.ID)
+			if len(batch) == client.MaxEmitBatchSize {
+				if err := flush(); err != nil {
+					return summary, err
+				}
+			}
+		}
+		if page.NextCursor == 0 {
+			break
+		}
+		if opts.progress != nil {
+			opts.progress(summary)
+		}
+		query.After = page.NextCursor
+	}
+	return summary, flush()
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayTopic, "topic", "", "filter by topic (supports wildcards)")
+	replayCmd.Flags().StringVar(&replayFrom, "from", "", "start time (RFC3339 or duration like 1h, 24h)")
+	replayCmd.Flags().StringVar(&replayTo, "to", "", "end time (RFC3339, default now)")
+	replayCmd.Flags().StringVar(&replayTargetProject, "target-project", "", "slug or ID of the project to emit into")
+	replayCmd.Flags().StringVar(&replayTargetKey, "target-key", "", "API key of the project to emit into")
+	replayCmd.Flags().StringVar(&replayJq, "jq", "", "jq expression rewriting each event's data")
+	replayCmd.Flags().Float64Var(&replayRate, "rate", 100, "max events per second (0 for unlimited)")
+
+	rootCmd.AddCommand(replayCmd)
+}
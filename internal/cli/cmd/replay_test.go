@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+func TestReplayEvents(t *testing.T) {
+	const total = eventsExportPageSize + 10
+	sourceSrv, requests := mockEventsServer(t, total)
+	targetSrv, batches := mockBatchEmitServer(t)
+	source := client.New("nsh_source", client.WithServer(sourceSrv.URL))
+	target := client.New("nsh_target", client.WithServer(targetSrv.URL))
+
+	code, err := compileJqTransform(`select(.n % 2 == 0) | .replayed = true`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := 0
+	summary, err := replayEvents(context.Background(), source, target, replayOptions{
+		jq:       code,
+		progress: func(replaySummary) { pages++ },
+	})
+	if err != nil {
+		t.Fatalf("replayEvents: %v", err)
+	}
+
+	if summary.Read != total || summary.Emitted != total/2 || summary.Skipped != total/2 || summary.Failed != 0 {
+		t.Errorf("summary = %+v, want %d read, %d emitted and skipped", summary, total, total/2)
+	}
+	if *requests != 2 {
+		t.Errorf("made %d list requests, want 2", *requests)
+	}
+	if pages != 1 {
+		t.Errorf("reported progress %d times, want 1", pages)
+	}
+
+	n := 2
+	for _, batch := range *batches {
+		if len(batch) > client.MaxEmitBatchSize {
+			t.Errorf("batch of %d events, want at most %d", len(batch), client.MaxEmitBatchSize)
+		}
+		for _, e := range batch {
+			if e.Topic != "orders.created" {
+				t.Errorf("topic = %s, want orders.created", e.Topic)
+			}
+			var data map[string]any
+			if err := json.Unmarshal(e.Data, &data); err != nil {
+				t.Fatalf("data: %v", err)
+			}
+			if want := fmt.Sprint(n); fmt.Sprint(data["n"]) != want || data["replayed"] != true {
+				t.Errorf("data = %s, want n=%s and replayed", e.Data, want)
+			}
+			n += 2
+		}
+	}
+}
+
+func TestReplayEvents_JqError(t *testing.T) {
+	sourceSrv, _ := mockEventsServer(t, 3)
+	targetSrv, batches := mockBatchEmitServer(t)
+	source := client.New("nsh_source", client.WithServer(sourceSrv.URL))
+	target := client.New("nsh_target", client.WithServer(targetSrv.URL))
+
+	code, err := compileJqTransform(`if .n == 2 then error("bad event") else . end`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := replayEvents(context.Background(), source, target, replayOptions{jq: code})
+	if err != nil {
+		t.Fatalf("replayEvents: %v", err)
+	}
+	if summary.Emitted != 2 || summary.Failed != 1 || len(summary.Failures) != 1 {
+		t.Errorf("summary = %+v, want 2 emitted and 1 failure", summary)
+	}
+	if len(*batches) != 1 || len((*batches)[0]) != 2 {
+		t.Errorf("got batches %v, want one of 2 events", *batches)
+	}
+}
@@ -12,11 +12,17 @@ import (
 
 var (
 	cfgFile    string
-	serverURL  string
+	serverFlag string
+	apiKeyFlag string
 	projectID  string
 	jsonOutput bool
 	cfg        *config.Config
 	out        *output.Output
+
+	// serverURL and apiKey are resolved once per command by
+	// resolveCredentials; cfg keeps only what the config file says.
+	serverURL string
+	apiKey    string
 )
 
 // rootCmd represents the base command
@@ -34,23 +40,8 @@ var rootCmd = &cobra.Command{
 			cfg = &config.Config{}
 		}
 
-		// Auth token priority: NOTIF_JWT > NOTIF_API_KEY > config
-		if jwt := os.Getenv("NOTIF_JWT"); jwt != "" {
-			cfg.APIKey = jwt // JWT works as bearer token too
-		} else if apiKey := os.Getenv("NOTIF_API_KEY"); apiKey != "" {
-			cfg.APIKey = apiKey
-		}
-
-		// Server URL priority: flag > env > config > default
-		if serverURL == "" {
-			if envServer := os.Getenv("NOTIF_SERVER"); envServer != "" {
-				serverURL = envServer
-			} else if cfg.Server != "" {
-				serverURL = cfg.Server
-			} else {
-				serverURL = client.DefaultServer
-			}
-		}
+		creds := resolveCredentials(flagCredentials(), os.Getenv, fileCredentials(cfg))
+		serverURL, apiKey = creds.Server, creds.APIKey
 
 		// Project ID from env (for web terminal / JWT auth)
 		if projectID == "" {
@@ -69,13 +60,18 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default $HOME/.notif/config.json)")
-	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "server URL")
+	rootCmd.PersistentFlags().StringVar(&serverFlag, "server", "", "server URL (overrides NOTIF_SERVER and config)")
+	rootCmd.PersistentFlags().StringVar(&apiKeyFlag, "api-key", "", "API key (overrides NOTIF_JWT, NOTIF_API_KEY and config)")
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output as JSON")
 }
 
+// flagCredentials returns the credentials given on the command line.
+func flagCredentials() credentials {
+	return credentials{Server: serverFlag, APIKey: apiKeyFlag}
+}
+
 // getClient creates a client with current config.
 func getClient() *client.Client {
-	apiKey := cfg.APIKey
 	opts := []client.Option{client.WithServer(serverURL)}
 	if projectID != "" {
 		opts = append(opts, client.WithProjectID(projectID))
@@ -18,7 +18,7 @@ var schedulesListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List scheduled events",
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -76,7 +76,7 @@ var schedulesGetCmd = &cobra.Command{
 	Short: "Get scheduled event details",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -106,6 +106,20 @@ var schedulesGetCmd = &cobra.Command{
 		if s.ExecutedAt != nil {
 			out.KeyValue("Executed", s.ExecutedAt.Format("2006-01-02 15:04:05 MST"))
 		}
+		if s.Cron != "" {
+			out.KeyValue("Cron", s.Cron)
+			if s.NextRun != nil {
+				out.KeyValue("Next Run", s.NextRun.Format("2006-01-02 15:04:05 MST"))
+			}
+			if s.LastRun != nil {
+				out.KeyValue("Last Run", s.LastRun.Format("2006-01-02 15:04:05 MST"))
+			}
+			occurrences := fmt.Sprintf("%d", s.Occurrences)
+			if s.MaxOccurrences > 0 {
+				occurrences += fmt.Sprintf(" of %d", s.MaxOccurrences)
+			}
+			out.KeyValue("Occurrences", occurrences)
+		}
 		if s.Error != nil {
 			out.KeyValue("Error", *s.Error)
 		}
@@ -119,7 +133,7 @@ var schedulesCancelCmd = &cobra.Command{
 	Short: "Cancel a pending scheduled event",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -149,7 +163,7 @@ var schedulesRunCmd = &cobra.Command{
 	Short: "Execute a scheduled event immediately",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -119,7 +121,7 @@ Examples:
   notif schemas push ./schemas/*.yaml`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -219,10 +221,12 @@ func pushSchemaFile(c *client.Client, filename string) error {
 			if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "already exists") {
 				out.Info("Version %s already exists for %s", def.Version, def.Name)
 			} else {
+				printBreakingChanges(err)
 				return err
 			}
 		} else {
 			out.Success("Created version %s for schema %s", version.Version, def.Name)
+			printCompatibilityWarnings(version)
 		}
 	}
 
@@ -233,7 +237,7 @@ var schemasListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all schemas",
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -278,6 +282,7 @@ var schemasListCmd = &cobra.Command{
 
 var (
 	getSchemaOnly bool
+	getBundle     bool
 )
 
 var schemasGetCmd = &cobra.Command{
@@ -285,26 +290,34 @@ var schemasGetCmd = &cobra.Command{
 	Short: "Get schema details",
 	Long: `Get schema details. Use --schema to output only the JSON Schema (for piping).
 
+Use --bundle to output the JSON Schema with every notif://schemas/ $ref
+inlined, giving a single self-contained schema for other tools.
+
 Examples:
   notif schemas get order-placed
   notif schemas get order-placed --schema
+  notif schemas get order-placed --bundle > order-placed.json
   notif schemas get order-placed --schema | jq '.properties'`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
 
 		c := getClient()
-		schema, err := c.SchemaGet(args[0])
+		get := c.SchemaGet
+		if getBundle {
+			get = c.SchemaGetBundled
+		}
+		schema, err := get(args[0])
 		if err != nil {
 			out.Error("Failed to get schema: %v", err)
 			return
 		}
 
 		// Output only JSON Schema for piping
-		if getSchemaOnly {
+		if getSchemaOnly || getBundle {
 			if schema.LatestVersion == nil {
 				out.Error("Schema has no versions")
 				return
@@ -344,7 +357,7 @@ var schemasDeleteCmd = &cobra.Command{
 	Short: "Delete a schema",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -376,7 +389,7 @@ var schemasValidateCmd = &cobra.Command{
 - From a file: notif schemas validate order-placed @data.json`,
 	Args: cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -439,7 +452,7 @@ var schemasVersionsCmd = &cobra.Command{
 	Short: "List versions of a schema",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -478,12 +491,47 @@ var schemasVersionsCmd = &cobra.Command{
 	},
 }
 
+var schemasStatsCmd = &cobra.Command{
+	Use:   "stats <schema-name>",
+	Short: "Show how often emitted events pass validation",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		c := getClient()
+		stats, err := c.SchemaStats(args[0])
+		if err != nil {
+			out.Error("Failed to get stats: %v", err)
+			return
+		}
+
+		if jsonOutput {
+			out.JSON(stats)
+			return
+		}
+
+		out.Header("Validation stats for " + stats.Schema + " (last " + stats.Window + ")")
+		out.KeyValue("Total", strconv.FormatInt(stats.Total, 10))
+		out.KeyValue("Valid", strconv.FormatInt(stats.ValidCount, 10))
+		out.KeyValue("Invalid", strconv.FormatInt(stats.InvalidCount, 10))
+		for _, v := range stats.Versions {
+			out.Divider()
+			out.Info("%s", v.Version)
+			out.KeyValue("Valid", strconv.FormatInt(v.ValidCount, 10))
+			out.KeyValue("Invalid", strconv.FormatInt(v.InvalidCount, 10))
+		}
+	},
+}
+
 var schemasForTopicCmd = &cobra.Command{
 	Use:   "for-topic <topic>",
 	Short: "Find schema for a topic",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -521,13 +569,14 @@ var schemasForTopicCmd = &cobra.Command{
 var (
 	generateConfigFile string
 	generateDryRun     bool
+	generateValidate   bool
 	initAllSchemas     bool
 )
 
 var schemasGenerateCmd = &cobra.Command{
 	Use:   "generate [schema-name]",
 	Short: "Generate typed code from schemas",
-	Long: `Generate typed code (TypeScript/Go) from notif.sh JSON Schemas.
+	Long: `Generate typed code (TypeScript/Go/Python) from notif.sh JSON Schemas.
 
 Reads configuration from .notif.yaml and generates code for all configured schemas.
 Optionally specify a schema name to generate code for only that schema.
@@ -536,7 +585,8 @@ Examples:
   notif schemas generate                    # Generate all schemas
   notif schemas generate order-placed       # Generate specific schema
   notif schemas generate --dry-run          # Preview what would be generated
-  notif schemas generate -c custom.yaml     # Use custom config file`,
+  notif schemas generate -c custom.yaml     # Use custom config file
+  notif schemas generate --validate-examples  # Fail if examples drift from schemas (CI)`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Find or use specified config file
@@ -551,19 +601,20 @@ Examples:
 		}
 
 		// Load config
-		cfg, err := codegen.LoadConfig(configPath)
+		genCfg, err := codegen.LoadConfig(configPath)
 		if err != nil {
 			out.Error("Failed to load config: %v", err)
 			return
 		}
 
-		// Get API key for fetching schemas from server
-		apiKey := os.Getenv("NOTIF_API_KEY")
-		if apiKey == "" {
+		// .notif.yaml's server applies unless --server or NOTIF_SERVER says
+		// otherwise
+		creds := resolveCredentials(flagCredentials(), os.Getenv, credentials{Server: genCfg.Server}, fileCredentials(cfg))
+		if creds.APIKey == "" {
 			// Check if all schemas are local files (or if using "schemas: all")
-			needsServer := cfg.Schemas.All
+			needsServer := genCfg.Schemas.All
 			if !needsServer {
-				for _, s := range cfg.Schemas.Entries {
+				for _, s := range genCfg.Schemas.Entries {
 					if s.File == "" {
 						needsServer = true
 						break
@@ -571,30 +622,27 @@ Examples:
 				}
 			}
 			if needsServer {
-				out.Error("No API key configured. Set NOTIF_API_KEY or run 'notif auth <key>'.")
+				out.Error("No API key configured. Use --api-key, set NOTIF_API_KEY or run 'notif auth <key>'.")
 				return
 			}
 		}
 
 		// Create client (may be nil if all schemas are local)
 		var c *client.Client
-		if apiKey != "" {
-			server := cfg.Server
-			if server == "" {
-				server = serverURL
-			}
-			c = client.New(apiKey, client.WithServer(server))
+		if creds.APIKey != "" {
+			c = client.New(creds.APIKey, client.WithServer(creds.Server))
 		}
 
 		// Create generator
 		opts := []codegen.GeneratorOption{
 			codegen.WithDryRun(generateDryRun),
+			codegen.WithValidateExamples(generateValidate),
 			codegen.WithProgressCallback(func(msg string) {
 				out.Info(msg)
 			}),
 		}
 
-		gen := codegen.NewGenerator(cfg, c, configPath, opts...)
+		gen := codegen.NewGenerator(genCfg, c, configPath, opts...)
 
 		// Filter schema if specified
 		var filterSchema string
@@ -629,6 +677,9 @@ Examples:
 					out.Error("  %s: %v", r.Schema, r.Error)
 				}
 			}
+			if generateValidate {
+				os.Exit(1)
+			}
 		}
 	},
 }
@@ -654,9 +705,8 @@ Examples:
 
 		// If --all flag, fetch schemas from server
 		if initAllSchemas {
-			apiKey := os.Getenv("NOTIF_API_KEY")
 			if apiKey == "" {
-				out.Error("No API key configured. Set NOTIF_API_KEY to fetch schemas.")
+				out.Error("No API key configured. Use --api-key, set NOTIF_API_KEY or run 'notif auth <key>'.")
 				return
 			}
 
@@ -747,7 +797,7 @@ Examples:
   EOF`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -829,7 +879,7 @@ Examples:
   notif schemas edit order-placed --version 2.0.0 < schema.json`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -873,7 +923,7 @@ Examples:
 		}
 
 		// Create new version
-		_, err = c.SchemaVersionCreate(args[0], client.CreateSchemaVersionRequest{
+		created, err := c.SchemaVersionCreate(args[0], client.CreateSchemaVersionRequest{
 			Version:        version,
 			Schema:         schemaJSON,
 			ValidationMode: "strict",
@@ -881,6 +931,7 @@ Examples:
 		})
 		if err != nil {
 			out.Error("Failed to create version: %v", err)
+			printBreakingChanges(err)
 			return
 		}
 
@@ -893,6 +944,7 @@ Examples:
 		}
 
 		out.Success("Updated schema: %s (version %s)", args[0], version)
+		printCompatibilityWarnings(created)
 	},
 }
 
@@ -903,6 +955,29 @@ func clearSchemaCache() {
 	_ = loader.ClearCache() // Ignore errors, cache clearing is best-effort
 }
 
+// printBreakingChanges lists why a version was rejected as incompatible.
+func printBreakingChanges(err error) {
+	var compatErr *client.CompatibilityError
+	if !errors.As(err, &compatErr) {
+		return
+	}
+	for _, ch := range compatErr.Changes {
+		out.Warn("%s: %s (%s)", ch.Field, ch.Message, ch.ChangeType)
+	}
+}
+
+// printCompatibilityWarnings lists how a version created without a
+// declared compatibility breaks backward compatibility.
+func printCompatibilityWarnings(v *client.SchemaVersion) {
+	if len(v.CompatibilityWarnings) == 0 {
+		return
+	}
+	out.Warn("Version %s is not backward compatible; set compatibility to enforce it:", v.Version)
+	for _, ch := range v.CompatibilityWarnings {
+		out.Warn("%s: %s (%s)", ch.Field, ch.Message, ch.ChangeType)
+	}
+}
+
 // bumpPatchVersion increments the patch version (1.0.0 -> 1.0.1)
 func bumpPatchVersion(v string) string {
 	parts := strings.Split(v, ".")
@@ -943,7 +1018,7 @@ Examples:
 
 		// Handle --refresh flag
 		if cacheRefresh {
-			if cfg.APIKey == "" {
+			if apiKey == "" {
 				out.Error("No API key configured. Run 'notif auth <key>' first.")
 				return
 			}
@@ -1041,6 +1116,7 @@ func init() {
 	schemasCmd.AddCommand(schemasDeleteCmd)
 	schemasCmd.AddCommand(schemasValidateCmd)
 	schemasCmd.AddCommand(schemasVersionsCmd)
+	schemasCmd.AddCommand(schemasStatsCmd)
 	schemasCmd.AddCommand(schemasForTopicCmd)
 	schemasCmd.AddCommand(schemasGenerateCmd)
 	schemasCmd.AddCommand(schemasInitCmd)
@@ -1050,6 +1126,7 @@ func init() {
 
 	// Get command flags
 	schemasGetCmd.Flags().BoolVar(&getSchemaOnly, "schema", false, "output only the JSON Schema (for piping)")
+	schemasGetCmd.Flags().BoolVar(&getBundle, "bundle", false, "output only the JSON Schema, with notif://schemas/ refs inlined")
 
 	// Create command flags
 	schemasCreateCmd.Flags().StringVar(&createTopic, "topic", "", "topic pattern (required)")
@@ -1063,6 +1140,7 @@ func init() {
 	// Generate command flags
 	schemasGenerateCmd.Flags().StringVarP(&generateConfigFile, "config", "c", "", "config file (default .notif.yaml)")
 	schemasGenerateCmd.Flags().BoolVar(&generateDryRun, "dry-run", false, "show what would be generated without writing files")
+	schemasGenerateCmd.Flags().BoolVar(&generateValidate, "validate-examples", false, "validate each schema's examples against it and fail on mismatch")
 
 	// Init command flags
 	schemasInitCmd.Flags().BoolVar(&initAllSchemas, "all", false, "include all schemas from server")
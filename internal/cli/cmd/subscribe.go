@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
@@ -29,6 +31,12 @@ var (
 	subscribeNoCache bool
 	subscribeOffline bool
 	subscribeRaw     bool
+	subscribeOutput  string
+	subscribeSample  int
+	subscribeProject string
+	subscribeAcross  []string
+	subscribeFromSeq uint64
+	subscribeLatest  string
 )
 
 var subscribeCmd = &cobra.Command{
@@ -41,6 +49,12 @@ Examples:
   notif subscribe "orders.*"
   notif subscribe orders.created users.signup
   notif subscribe --group processor "orders.*"
+  notif subscribe "clicks.*" --sample 100    # server delivers 1 in 100
+  notif subscribe "orders.*" --project '{id, total: .amount}'
+  notif subscribe "orders.*" --projects prj_a,prj_b    # admin keys only
+  notif subscribe "prices.*" --latest-per-key sku     # latest stored price per sku, then live
+  notif subscribe "orders.*" --from 2024-01-01T00:00:00Z    # replay from a point in time
+  notif subscribe "orders.*" --from-seq 1042
 
 Filter and auto-exit:
   notif subscribe 'orders.*' --filter '.status == "completed"' --once
@@ -49,16 +63,35 @@ Filter and auto-exit:
 Custom display:
   notif subscribe 'orders.*' --format '{{.data.orderId}} - {{.data.status | color "green"}}'
   notif subscribe 'payments.*' --format '{{.topic}} {{.data.amount | printf "$%.2f"}}'
-  notif subscribe 'logs.*' --fields "timestamp,topic,data.level,data.message"`,
+  notif subscribe 'logs.*' --fields "timestamp,topic,data.level,data.message"
+
+Piping (one JSON event per line on stdout, status on stderr):
+  notif subscribe 'orders.*' --output ndjson | jq '.data.amount'`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
 
 		topics := args
 
+		ndjson := false
+		switch subscribeOutput {
+		case "", "pretty":
+		case "ndjson":
+			ndjson = true
+		default:
+			out.Error("Invalid --output %q (expected pretty or ndjson)", subscribeOutput)
+			os.Exit(1)
+		}
+
+		// In ndjson mode stdout carries only events
+		status := out
+		if ndjson {
+			status = out.Stderr()
+		}
+
 		// Parse jq filter if provided
 		var jqCode *gojq.Code
 		if subscribeFilter != "" {
@@ -86,10 +119,35 @@ Custom display:
 			defer cancel()
 		}
 
+		from := subscribeFrom
+		if subscribeLatest != "" && !cmd.Flags().Changed("from") {
+			// The snapshot is taken from the stored events
+			from = "beginning"
+		}
+
+		if subscribeFromSeq > 0 {
+			if cmd.Flags().Changed("from") {
+				out.Error("--from and --from-seq cannot be combined")
+				return
+			}
+			from = ""
+		}
+
 		opts := client.SubscribeOptions{
 			AutoAck: !subscribeNoAck,
 			Group:   subscribeGroup,
-			From:    subscribeFrom,
+			From:    from,
+			FromSeq: subscribeFromSeq,
+			Sample:  subscribeSample,
+
+			LatestPerKey: subscribeLatest,
+
+			// Reshaped on the server; --filter still sees the projection
+			ProjectJq: subscribeProject,
+			Projects:  subscribeAcross,
+
+			// Have the server push schema display configs as they change
+			DisplayConfig: usesSchemaDisplay(ndjson),
 		}
 
 		sub, err := c.Subscribe(ctx, topics, opts)
@@ -100,26 +158,40 @@ Custom display:
 		defer sub.Close()
 
 		// Set up display renderer
-		renderer := setupRenderer(ctx, c, topics)
+		var renderer *display.RendererManager
+		if !ndjson {
+			renderer = setupRenderer(ctx, c, topics)
+		}
 
 		if !jsonOutput {
-			out.Success("Subscribed to %v", topics)
+			status.Success("Subscribed to %v", topics)
 			if subscribeGroup != "" {
-				out.KeyValue("Group", subscribeGroup)
+				status.KeyValue("Group", subscribeGroup)
 			}
 			if subscribeFilter != "" {
-				out.KeyValue("Filter", subscribeFilter)
+				status.KeyValue("Filter", subscribeFilter)
+			}
+			if subscribeSample > 1 {
+				status.KeyValue("Sample", fmt.Sprintf("1 in %d", subscribeSample))
 			}
-			if subscribeFormat != "" {
-				out.KeyValue("Display", "custom template")
+			if subscribeProject != "" {
+				status.KeyValue("Project", subscribeProject)
+			}
+			if len(subscribeAcross) > 0 {
+				status.KeyValue("Projects", strings.Join(subscribeAcross, ", "))
+			}
+			if ndjson {
+				status.KeyValue("Output", "ndjson")
+			} else if subscribeFormat != "" {
+				status.KeyValue("Display", "custom template")
 			} else if subscribeFields != "" {
-				out.KeyValue("Display", "table mode")
+				status.KeyValue("Display", "table mode")
 			}
 			if subscribeCount > 0 {
-				out.KeyValue("Exit after", fmt.Sprintf("%d events", subscribeCount))
+				status.KeyValue("Exit after", fmt.Sprintf("%d events", subscribeCount))
 			}
-			out.Info("Waiting for events... (Ctrl+C to exit)")
-			out.Divider()
+			status.Info("Waiting for events... (Ctrl+C to exit)")
+			status.Divider()
 		}
 
 		// Handle signals
@@ -141,7 +213,12 @@ Custom display:
 				}
 
 				// Render event
-				if jsonOutput {
+				if ndjson {
+					if err := writeNDJSON(os.Stdout, event); err != nil {
+						out.Error("Failed to write event: %v", err)
+						return
+					}
+				} else if jsonOutput {
 					out.Event(event.ID, event.Topic, event.Data, event.Timestamp)
 				} else {
 					output, err := renderer.RenderEvent(event.ID, event.Topic, event.Data, event.Timestamp)
@@ -159,17 +236,23 @@ Custom display:
 					return
 				}
 
+			case configs := <-sub.DisplayConfigs():
+				applyDisplayConfigs(renderer, configs)
+
 			case err := <-sub.Errors():
 				// Log error but don't exit - SDK will auto-reconnect
 				if _, ok := err.(*client.ReconnectedError); ok {
-					out.Success("Reconnected")
+					status.Success("Reconnected")
+				} else if closed, ok := err.(*client.ClosedError); ok && !closed.Reconnect {
+					out.Error("Disconnected: %v", closed)
+					return
 				} else {
-					out.Warn("Connection error: %v (reconnecting...)", err)
+					status.Warn("Connection error: %v (reconnecting...)", err)
 				}
 
 			case <-sigCh:
 				if !jsonOutput {
-					out.Info("Disconnecting...")
+					status.Info("Disconnecting...")
 				}
 				return
 
@@ -184,6 +267,11 @@ Custom display:
 	},
 }
 
+// writeNDJSON writes the raw event as a single line of JSON.
+func writeNDJSON(w io.Writer, event *client.Event) error {
+	return json.NewEncoder(w).Encode(event)
+}
+
 // setupRenderer creates the appropriate renderer manager based on config.
 func setupRenderer(ctx context.Context, c *client.Client, topics []string) *display.RendererManager {
 	// Create colorizer
@@ -263,6 +351,30 @@ func setupRenderer(ctx context.Context, c *client.Client, topics []string) *disp
 	return renderer
 }
 
+// usesSchemaDisplay reports whether events are rendered with the schemas'
+// display configs, rather than raw, a --format/--fields override or ndjson.
+func usesSchemaDisplay(ndjson bool) bool {
+	return !ndjson && !subscribeRaw && !subscribeOffline && subscribeFormat == "" && subscribeFields == ""
+}
+
+// applyDisplayConfigs installs display configs pushed by the server,
+// replacing cached ones for the same topic patterns.
+func applyDisplayConfigs(renderer *display.RendererManager, configs []client.DisplayConfig) {
+	if renderer == nil {
+		return
+	}
+	for _, c := range configs {
+		var cfg display.DisplayConfig
+		if err := json.Unmarshal(c.Display, &cfg); err != nil {
+			out.Warn("Invalid display config for schema %s: %v", c.Schema, err)
+			continue
+		}
+		if err := renderer.AddTopicConfig(c.TopicPattern, &cfg); err != nil {
+			out.Warn("Failed to setup display for schema %s: %v", c.Schema, err)
+		}
+	}
+}
+
 // parseFieldsFlag parses the --fields flag into FieldConfig slice.
 func parseFieldsFlag(fields string) []display.FieldConfig {
 	parts := strings.Split(fields, ",")
@@ -283,9 +395,14 @@ func parseFieldsFlag(fields string) []display.FieldConfig {
 
 func init() {
 	subscribeCmd.Flags().StringVar(&subscribeGroup, "group", "", "consumer group name")
-	subscribeCmd.Flags().StringVar(&subscribeFrom, "from", "latest", "start position (latest, beginning)")
+	subscribeCmd.Flags().StringVar(&subscribeFrom, "from", "latest", "start position (latest, beginning, or an RFC3339 time)")
+	subscribeCmd.Flags().Uint64Var(&subscribeFromSeq, "from-seq", 0, "start at this stream sequence")
 	subscribeCmd.Flags().BoolVar(&subscribeNoAck, "no-auto-ack", false, "disable automatic acknowledgment")
 	subscribeCmd.Flags().StringVar(&subscribeFilter, "filter", "", "jq expression to filter events")
+	subscribeCmd.Flags().IntVar(&subscribeSample, "sample", 0, "server-side sampling: receive only 1 in N matching events")
+	subscribeCmd.Flags().StringVar(&subscribeProject, "project", "", "jq expression the server applies to each event's data")
+	subscribeCmd.Flags().StringSliceVar(&subscribeAcross, "projects", nil, "subscribe across these project IDs (admin keys only)")
+	subscribeCmd.Flags().StringVar(&subscribeLatest, "latest-per-key", "", "of the stored events, deliver only the latest per value of this data field (implies --from beginning)")
 	subscribeCmd.Flags().BoolVar(&subscribeOnce, "once", false, "exit after first matching event")
 	subscribeCmd.Flags().IntVar(&subscribeCount, "count", 0, "exit after N matching events")
 	subscribeCmd.Flags().DurationVar(&subscribeTimeout, "timeout", 0, "timeout waiting for events")
@@ -297,6 +414,7 @@ func init() {
 	subscribeCmd.Flags().BoolVar(&subscribeNoCache, "no-cache", false, "ignore schema cache, always fetch from server")
 	subscribeCmd.Flags().BoolVar(&subscribeOffline, "offline", false, "use only local cache (error if not available)")
 	subscribeCmd.Flags().BoolVar(&subscribeRaw, "raw", false, "disable custom display, show raw format (timestamp topic json)")
+	subscribeCmd.Flags().StringVar(&subscribeOutput, "output", "pretty", "output mode: pretty or ndjson (one JSON event per line, status on stderr)")
 
 	rootCmd.AddCommand(subscribeCmd)
 }
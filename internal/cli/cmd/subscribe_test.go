@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	events := []*client.Event{
+		{EnvelopeVersion: 1, ID: "evt_1", Topic: "orders.created", Data: json.RawMessage(`{"id":1}`), Timestamp: time.Now()},
+		// Pretty-printed data must still come out on a single line
+		{EnvelopeVersion: 1, ID: "evt_2", Topic: "orders.updated", Data: json.RawMessage("{\n  \"id\": 2,\n  \"items\": [1, 2]\n}"), Timestamp: time.Now()},
+		{EnvelopeVersion: 1, ID: "evt_3", Topic: "orders.deleted", Data: json.RawMessage(`"plain string"`), Timestamp: time.Now(), Attempt: 2},
+	}
+
+	var buf bytes.Buffer
+	for _, e := range events {
+		if err := writeNDJSON(&buf, e); err != nil {
+			t.Fatalf("writeNDJSON: %v", err)
+		}
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	var lines int
+	for scanner.Scan() {
+		var got client.Event
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v (%q)", lines+1, err, scanner.Text())
+		}
+		if got.ID != events[lines].ID || got.Topic != events[lines].Topic {
+			t.Errorf("line %d: got %s/%s, want %s/%s", lines+1, got.ID, got.Topic, events[lines].ID, events[lines].Topic)
+		}
+		lines++
+	}
+	if lines != len(events) {
+		t.Errorf("expected %d lines, got %d", len(events), lines)
+	}
+}
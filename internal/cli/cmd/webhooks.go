@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/filipexyz/notif/pkg/client"
@@ -14,7 +16,18 @@ var webhooksCmd = &cobra.Command{
 }
 
 var webhooksCreateURL string
+var webhooksCreateFallbackURLs []string
 var webhooksCreateTopics string
+var webhooksCreateRetryBudget string
+var webhooksCreateMaxRetries int
+var webhooksCreateBackoff string
+var webhooksCreateContentType string
+var webhooksCreateBodyEncoding string
+var webhooksCreatePayloadMode string
+var webhooksCreateOrdered bool
+var webhooksCreateHeaders []string
+var webhooksCreateTenantField string
+var webhooksCreateTenantSecrets []string
 
 var webhooksCreateCmd = &cobra.Command{
 	Use:   "create",
@@ -23,9 +36,15 @@ var webhooksCreateCmd = &cobra.Command{
 
 Examples:
   notif webhooks create --url https://example.com/webhook --topics "orders.*"
-  notif webhooks create --url https://api.example.com/events --topics "orders.created,users.signup"`,
+  notif webhooks create --url https://api.example.com/events --topics "orders.created,users.signup"
+  notif webhooks create --url https://example.com/webhook --topics "orders.*" --retry-budget 1h
+  notif webhooks create --url https://a.example.com/hook --fallback-url https://b.example.com/hook --topics "orders.*"
+  notif webhooks create --url https://example.com/webhook --topics "orders.*" --max-retries 8 --backoff 5s,1m,10m
+  notif webhooks create --url https://example.com/hook --topics "orders.*" --body-encoding form
+  notif webhooks create --url https://example.com/hook --topics "orders.*" -H "Authorization: Bearer xyz" -H "X-Tenant-ID: acme"
+  notif webhooks create --url https://example.com/hook --topics "orders.*" --tenant-field tenant --tenant-secret acme=s3cret`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -44,8 +63,52 @@ Examples:
 			topics[i] = strings.TrimSpace(topics[i])
 		}
 
+		headers, err := parseHeaderFlags(webhooksCreateHeaders)
+		if err != nil {
+			out.Error("%v", err)
+			return
+		}
+		var tenantSecrets map[string]string
+		for _, f := range webhooksCreateTenantSecrets {
+			tenant, secret, ok := strings.Cut(f, "=")
+			if !ok || tenant == "" || secret == "" {
+				out.Error("invalid --tenant-secret %q, want tenant=secret", f)
+				return
+			}
+			if tenantSecrets == nil {
+				tenantSecrets = make(map[string]string)
+			}
+			tenantSecrets[tenant] = secret
+		}
+
+		var retryPolicy *client.RetryPolicy
+		if webhooksCreateMaxRetries > 0 || webhooksCreateBackoff != "" {
+			retryPolicy = &client.RetryPolicy{MaxRetries: webhooksCreateMaxRetries}
+			if webhooksCreateBackoff != "" {
+				for _, d := range strings.Split(webhooksCreateBackoff, ",") {
+					retryPolicy.Backoff = append(retryPolicy.Backoff, strings.TrimSpace(d))
+				}
+			}
+		}
+
 		c := getClient()
-		webhook, err := c.WebhookCreate(webhooksCreateURL, topics)
+		webhook, err := c.WebhookCreateWithOptions(client.CreateWebhookRequest{
+			URL:          webhooksCreateURL,
+			FallbackURLs: webhooksCreateFallbackURLs,
+			Topics:       topics,
+			RetryBudget:  webhooksCreateRetryBudget,
+
+			ContentType:  webhooksCreateContentType,
+			BodyEncoding: webhooksCreateBodyEncoding,
+			PayloadMode:  webhooksCreatePayloadMode,
+			Ordered:      webhooksCreateOrdered,
+			Headers:      headers,
+
+			TenantField:   webhooksCreateTenantField,
+			TenantSecrets: tenantSecrets,
+
+			RetryPolicy: retryPolicy,
+		})
 		if err != nil {
 			out.Error("Failed to create webhook: %v", err)
 			return
@@ -60,6 +123,11 @@ Examples:
 		out.KeyValue("ID", webhook.ID)
 		out.KeyValue("URL", webhook.URL)
 		out.KeyValue("Topics", strings.Join(webhook.Topics, ", "))
+		out.KeyValue("Retry budget", webhook.RetryBudget)
+		out.KeyValue("Body", webhook.BodyEncoding+" ("+webhook.ContentType+")")
+		out.KeyValue("Payload", webhook.PayloadMode)
+		out.KeyValue("Ordered", boolToStr(webhook.Ordered))
+		printWebhookHeaders(webhook.Headers)
 		out.KeyValue("Secret", webhook.Secret)
 		out.Warn("Save the secret - it won't be shown again!")
 	},
@@ -69,7 +137,7 @@ var webhooksListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all webhooks",
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -113,7 +181,7 @@ var webhooksGetCmd = &cobra.Command{
 	Short: "Get webhook details",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -133,8 +201,33 @@ var webhooksGetCmd = &cobra.Command{
 		out.Header("Webhook")
 		out.KeyValue("ID", webhook.ID)
 		out.KeyValue("URL", webhook.URL)
+		if len(webhook.FallbackURLs) > 0 {
+			out.KeyValue("Fallback URLs", strings.Join(webhook.FallbackURLs, ", "))
+		}
 		out.KeyValue("Topics", strings.Join(webhook.Topics, ", "))
 		out.KeyValue("Enabled", boolToStr(webhook.Enabled))
+		out.KeyValue("Retry budget", webhook.RetryBudget)
+		if p := webhook.RetryPolicy; p != nil {
+			if p.MaxRetries > 0 {
+				out.KeyValue("Max retries", fmt.Sprintf("%d", p.MaxRetries))
+			}
+			if len(p.Backoff) > 0 {
+				out.KeyValue("Backoff", strings.Join(p.Backoff, ", "))
+			}
+		}
+		out.KeyValue("Body", webhook.BodyEncoding+" ("+webhook.ContentType+")")
+		out.KeyValue("Payload", webhook.PayloadMode)
+		out.KeyValue("Ordered", boolToStr(webhook.Ordered))
+		printWebhookHeaders(webhook.Headers)
+		if webhook.TenantField != "" {
+			tenants := make([]string, 0, len(webhook.TenantSecrets))
+			for tenant := range webhook.TenantSecrets {
+				tenants = append(tenants, tenant)
+			}
+			sort.Strings(tenants)
+			out.KeyValue("Tenant field", webhook.TenantField)
+			out.KeyValue("Tenants", strings.Join(tenants, ", "))
+		}
 		out.KeyValue("Created", webhook.CreatedAt)
 	},
 }
@@ -144,7 +237,7 @@ var webhooksDeleteCmd = &cobra.Command{
 	Short: "Delete a webhook",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -169,7 +262,7 @@ var webhooksEnableCmd = &cobra.Command{
 	Short: "Enable a webhook",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -191,7 +284,7 @@ var webhooksDisableCmd = &cobra.Command{
 	Short: "Disable a webhook",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
@@ -208,18 +301,28 @@ var webhooksDisableCmd = &cobra.Command{
 	},
 }
 
+var (
+	webhooksDeliveriesStatus string
+	webhooksDeliveriesLimit  int
+	webhooksDeliveriesBefore string
+)
+
 var webhooksDeliveriesCmd = &cobra.Command{
 	Use:   "deliveries <id>",
 	Short: "List recent deliveries for a webhook",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if cfg.APIKey == "" {
+		if apiKey == "" {
 			out.Error("No API key configured. Run 'notif auth <key>' first.")
 			return
 		}
 
 		c := getClient()
-		result, err := c.WebhookDeliveries(args[0])
+		result, err := c.WebhookDeliveriesWithOptions(args[0], client.WebhookDeliveriesOptions{
+			Limit:  webhooksDeliveriesLimit,
+			Before: webhooksDeliveriesBefore,
+			Status: webhooksDeliveriesStatus,
+		})
 		if err != nil {
 			out.Error("Failed to get deliveries: %v", err)
 			return
@@ -255,9 +358,100 @@ var webhooksDeliveriesCmd = &cobra.Command{
 			}
 			out.Divider()
 		}
+		if result.NextCursor != "" {
+			out.Info("More deliveries: --before %s", result.NextCursor)
+		}
+	},
+}
+
+var webhooksRetryCmd = &cobra.Command{
+	Use:   "retry <id> <delivery-id>",
+	Short: "Retry a failed delivery without re-emitting the event",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		c := getClient()
+		if err := c.RetryWebhookDelivery(args[0], args[1]); err != nil {
+			out.Error("Failed to retry delivery: %v", err)
+			return
+		}
+
+		out.Success("Delivery queued for retry")
 	},
 }
 
+var webhooksRotateGrace string
+
+var webhooksRotateSecretCmd = &cobra.Command{
+	Use:   "rotate-secret <id>",
+	Short: "Rotate a webhook's signing secret",
+	Long: `Generate a new signing secret for a webhook.
+
+Deliveries are signed with the new secret in X-Notif-Signature. Until the grace
+period ends they also carry X-Notif-Signature-Previous, signed with the old
+secret, so receivers can switch over without dropping events.
+
+Examples:
+  notif webhooks rotate-secret <id>
+  notif webhooks rotate-secret <id> --grace 1h`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			return
+		}
+
+		c := getClient()
+		webhook, err := c.WebhookRotateSecret(args[0], webhooksRotateGrace)
+		if err != nil {
+			out.Error("Failed to rotate secret: %v", err)
+			return
+		}
+
+		if jsonOutput {
+			out.JSON(webhook)
+			return
+		}
+
+		out.Success("Webhook secret rotated")
+		out.KeyValue("ID", webhook.ID)
+		out.KeyValue("Secret", webhook.Secret)
+		out.KeyValue("Old secret valid until", webhook.PreviousSecretExpiresAt)
+		out.Warn("Save the secret - it won't be shown again!")
+	},
+}
+
+// parseHeaderFlags turns repeated "Name: value" flags into a header map.
+func parseHeaderFlags(flags []string) (map[string]string, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(flags))
+	for _, f := range flags {
+		name, value, ok := strings.Cut(f, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid --header %q, want \"Name: value\"", f)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+func printWebhookHeaders(headers map[string]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.KeyValue("Header", name+": "+headers[name])
+	}
+}
+
 func boolToStr(b bool) string {
 	if b {
 		return "yes"
@@ -267,7 +461,22 @@ func boolToStr(b bool) string {
 
 func init() {
 	webhooksCreateCmd.Flags().StringVar(&webhooksCreateURL, "url", "", "webhook URL")
+	webhooksCreateCmd.Flags().StringArrayVar(&webhooksCreateFallbackURLs, "fallback-url", nil, "URL tried when the primary fails a delivery, in order (repeatable, up to 3)")
 	webhooksCreateCmd.Flags().StringVar(&webhooksCreateTopics, "topics", "", "comma-separated topic patterns")
+	webhooksCreateCmd.Flags().StringVar(&webhooksCreateRetryBudget, "retry-budget", "", "give up retrying failed deliveries after this long (default 6h)")
+	webhooksCreateCmd.Flags().IntVar(&webhooksCreateMaxRetries, "max-retries", 0, "delivery attempts before a failing event goes to the DLQ, 1-20 (default 5)")
+	webhooksCreateCmd.Flags().StringVar(&webhooksCreateBackoff, "backoff", "", "comma-separated delays before each retry, e.g. 5s,1m,10m; the last one repeats")
+	webhooksCreateCmd.Flags().StringVar(&webhooksCreateBodyEncoding, "body-encoding", "", "payload encoding: json or form (default json)")
+	webhooksCreateCmd.Flags().StringVar(&webhooksCreatePayloadMode, "payload-mode", "", "envelope, or raw to send only the event data with metadata in headers (default envelope)")
+	webhooksCreateCmd.Flags().BoolVar(&webhooksCreateOrdered, "ordered", false, "deliver events one at a time in emit order (lower throughput)")
+	webhooksCreateCmd.Flags().StringVar(&webhooksCreateTenantField, "tenant-field", "", "event data path selecting the signing secret, e.g. tenant")
+	webhooksCreateCmd.Flags().StringArrayVar(&webhooksCreateTenantSecrets, "tenant-secret", nil, "signing secret for one tenant, tenant=secret (repeatable)")
+	webhooksCreateCmd.Flags().StringArrayVarP(&webhooksCreateHeaders, "header", "H", nil, "static header sent with every delivery, \"Name: value\" (repeatable)")
+	webhooksCreateCmd.Flags().StringVar(&webhooksCreateContentType, "content-type", "", "Content-Type header sent with deliveries (default per encoding)")
+	webhooksDeliveriesCmd.Flags().StringVar(&webhooksDeliveriesStatus, "status", "", "only deliveries with this status: pending, success or failed")
+	webhooksDeliveriesCmd.Flags().IntVar(&webhooksDeliveriesLimit, "limit", 0, "max deliveries to list (default 100)")
+	webhooksDeliveriesCmd.Flags().StringVar(&webhooksDeliveriesBefore, "before", "", "list deliveries older than this delivery ID (a previous page's cursor)")
+	webhooksRotateSecretCmd.Flags().StringVar(&webhooksRotateGrace, "grace", "", "how long the old secret stays valid (default 24h)")
 
 	webhooksCmd.AddCommand(webhooksCreateCmd)
 	webhooksCmd.AddCommand(webhooksListCmd)
@@ -276,6 +485,8 @@ func init() {
 	webhooksCmd.AddCommand(webhooksEnableCmd)
 	webhooksCmd.AddCommand(webhooksDisableCmd)
 	webhooksCmd.AddCommand(webhooksDeliveriesCmd)
+	webhooksCmd.AddCommand(webhooksRetryCmd)
+	webhooksCmd.AddCommand(webhooksRotateSecretCmd)
 
 	rootCmd.AddCommand(webhooksCmd)
 }
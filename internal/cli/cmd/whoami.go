@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/filipexyz/notif/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the identity of the configured credentials",
+	Long: `Show which org, project and API key the CLI is authenticated as.
+
+Examples:
+  notif whoami
+  NOTIF_API_KEY=nsh_xxx notif whoami --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKey == "" {
+			out.Error("No API key configured. Run 'notif auth <key>' first.")
+			os.Exit(1)
+		}
+
+		c := getClient()
+		identity, err := c.Whoami()
+		if err != nil {
+			var authErr *client.AuthError
+			if errors.As(err, &authErr) {
+				out.Error("Not authenticated against %s: %s", serverURL, authErr.Message)
+			} else {
+				out.Error("Failed to get identity: %v", err)
+			}
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			out.JSON(identity)
+			return
+		}
+
+		project := identity.ProjectID
+		if identity.ProjectName != "" {
+			project += " (" + identity.ProjectName + ")"
+		}
+
+		out.KeyValue("Server", serverURL)
+		out.KeyValue("Org", identity.OrgID)
+		out.KeyValue("Project", project)
+		if identity.AuthType == "api_key" {
+			key := identity.KeyPrefix + "…"
+			if identity.KeyName != "" {
+				key += " (" + identity.KeyName + ")"
+			}
+			out.KeyValue("API key", key)
+		} else if identity.UserID != "" {
+			out.KeyValue("User", identity.UserID)
+		}
+		out.KeyValue("Scopes", strings.Join(identity.Scopes, ", "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
@@ -0,0 +1,204 @@
+// Package doctor diagnoses a notif server from the client side, using the
+// health and stats endpoints an API key can reach.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip" // not available on this server
+)
+
+// Thresholds for degrading a check to warn or fail.
+const (
+	webhookFailureWarn = 0.05
+	webhookFailureFail = 0.25
+	storageWarn        = 0.80
+	storageFail        = 0.95
+)
+
+// Check is one line of the report.
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the result of diagnosing a server.
+type Report struct {
+	Server string  `json:"server"`
+	Checks []Check `json:"checks"`
+}
+
+// Healthy reports whether no check failed.
+func (r *Report) Healthy() bool {
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns the check with the given name, or nil.
+func (r *Report) Check(name string) *Check {
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			return &r.Checks[i]
+		}
+	}
+	return nil
+}
+
+func (r *Report) add(name string, status Status, format string, args ...any) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// StatsClient is the subset of the notif client the diagnostics use.
+type StatsClient interface {
+	ServerURL() string
+	ReadyStatus() (*client.ReadyResponse, error)
+	DLQStats() (*client.DLQStats, error)
+	WebhooksStats() (*client.WebhooksStats, error)
+	SchedulesStats() (*client.SchedulesStats, error)
+	StreamsStats() ([]client.StreamStats, error)
+}
+
+// Server runs every check against the server behind c. An unreachable
+// server yields a single failed check.
+func Server(c StatsClient) *Report {
+	r := &Report{Server: c.ServerURL()}
+
+	ready, err := c.ReadyStatus()
+	if err != nil {
+		r.add("Server", StatusFail, "unreachable: %v", err)
+		return r
+	}
+	checkNATS(r, ready)
+	checkDatabase(r, ready)
+
+	if stats, err := c.DLQStats(); err != nil {
+		r.add("DLQ", statusForError(err), "%v", err)
+	} else if stats.Total > 0 {
+		r.add("DLQ", StatusWarn, "%d dead-lettered event(s) (notif dlq list)", stats.Total)
+	} else {
+		r.add("DLQ", StatusOK, "empty")
+	}
+
+	if stats, err := c.WebhooksStats(); err != nil {
+		r.add("Webhooks", statusForError(err), "%v", err)
+	} else {
+		checkWebhooks(r, stats)
+	}
+
+	if stats, err := c.SchedulesStats(); err != nil {
+		r.add("Schedules", statusForError(err), "%v", err)
+	} else if stats.Failed > 0 {
+		r.add("Schedules", StatusWarn, "%d pending, %d failed", stats.Pending, stats.Failed)
+	} else {
+		r.add("Schedules", StatusOK, "%d pending", stats.Pending)
+	}
+
+	if streams, err := c.StreamsStats(); err != nil {
+		r.add("Storage", statusForError(err), "%v", err)
+	} else {
+		for _, s := range streams {
+			checkStorage(r, s)
+		}
+	}
+
+	return r
+}
+
+func checkNATS(r *Report, ready *client.ReadyResponse) {
+	switch ready.NATS {
+	case "connected":
+		if ready.Accounts > 0 {
+			r.add("NATS", StatusOK, "connected (%d accounts)", ready.Accounts)
+		} else {
+			r.add("NATS", StatusOK, "connected")
+		}
+	case "partially_connected":
+		r.add("NATS", StatusWarn, "disconnected accounts: %s", strings.Join(ready.DisconnectedOrgs, ", "))
+	default:
+		r.add("NATS", StatusFail, "%s", ready.NATS)
+	}
+}
+
+func checkDatabase(r *Report, ready *client.ReadyResponse) {
+	if ready.Database == "connected" {
+		r.add("Database", StatusOK, "connected")
+	} else {
+		r.add("Database", StatusFail, "%s", ready.Database)
+	}
+}
+
+func checkWebhooks(r *Report, stats *client.WebhooksStats) {
+	d := stats.Deliveries
+	if d.Total == 0 {
+		r.add("Webhooks", StatusOK, "%d enabled, no deliveries in the last 24h", stats.Enabled)
+		return
+	}
+
+	rate := float64(d.Failed) / float64(d.Total)
+	status := StatusOK
+	switch {
+	case rate >= webhookFailureFail:
+		status = StatusFail
+	case rate >= webhookFailureWarn:
+		status = StatusWarn
+	}
+	r.add("Webhooks", status, "%.1f%% failed (%d of %d deliveries in the last 24h)", rate*100, d.Failed, d.Total)
+}
+
+func checkStorage(r *Report, s client.StreamStats) {
+	name := "Storage " + s.Name
+	if s.MaxBytes <= 0 {
+		r.add(name, StatusOK, "%s, %d messages (no limit)", formatBytes(s.Bytes), s.Messages)
+		return
+	}
+
+	used := float64(s.Bytes) / float64(s.MaxBytes)
+	status := StatusOK
+	switch {
+	case used >= storageFail:
+		status = StatusFail
+	case used >= storageWarn:
+		status = StatusWarn
+	}
+	r.add(name, status, "%s of %s (%.0f%%), %d messages", formatBytes(s.Bytes), formatBytes(uint64(s.MaxBytes)), used*100, s.Messages)
+}
+
+// statusForError skips checks the server doesn't support and fails the rest.
+func statusForError(err error) Status {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotImplemented {
+		return StatusSkip
+	}
+	return StatusFail
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
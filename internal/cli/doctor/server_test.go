@@ -0,0 +1,109 @@
+package doctor
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+type fakeClient struct {
+	ready     *client.ReadyResponse
+	readyErr  error
+	dlq       *client.DLQStats
+	webhooks  *client.WebhooksStats
+	schedules *client.SchedulesStats
+	schedErr  error
+	streams   []client.StreamStats
+}
+
+func (f *fakeClient) ServerURL() string { return "http://notif.test" }
+func (f *fakeClient) ReadyStatus() (*client.ReadyResponse, error) {
+	return f.ready, f.readyErr
+}
+func (f *fakeClient) DLQStats() (*client.DLQStats, error)           { return f.dlq, nil }
+func (f *fakeClient) WebhooksStats() (*client.WebhooksStats, error) { return f.webhooks, nil }
+func (f *fakeClient) SchedulesStats() (*client.SchedulesStats, error) {
+	return f.schedules, f.schedErr
+}
+func (f *fakeClient) StreamsStats() ([]client.StreamStats, error) { return f.streams, nil }
+
+func healthyClient() *fakeClient {
+	return &fakeClient{
+		ready:     &client.ReadyResponse{Status: "ready", NATS: "connected", Database: "connected"},
+		dlq:       &client.DLQStats{},
+		webhooks:  &client.WebhooksStats{},
+		schedules: &client.SchedulesStats{},
+		streams:   []client.StreamStats{{Name: "NOTIF_EVENTS", Bytes: 1 << 20, MaxBytes: 1 << 30}},
+	}
+}
+
+func TestServer_Healthy(t *testing.T) {
+	r := Server(healthyClient())
+	if !r.Healthy() {
+		t.Fatalf("expected healthy report, got %+v", r.Checks)
+	}
+	for _, name := range []string{"NATS", "Database", "DLQ", "Webhooks", "Schedules", "Storage NOTIF_EVENTS"} {
+		c := r.Check(name)
+		if c == nil {
+			t.Errorf("missing check %q", name)
+		} else if c.Status != StatusOK {
+			t.Errorf("%s: expected ok, got %s (%s)", name, c.Status, c.Detail)
+		}
+	}
+}
+
+func TestServer_Unreachable(t *testing.T) {
+	f := healthyClient()
+	f.readyErr = errors.New("connection refused")
+
+	r := Server(f)
+	if r.Healthy() || len(r.Checks) != 1 || r.Checks[0].Name != "Server" {
+		t.Errorf("expected a single failed Server check, got %+v", r.Checks)
+	}
+}
+
+func TestServer_Thresholds(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*fakeClient)
+		check  string
+		want   Status
+	}{
+		{"dlq depth", func(f *fakeClient) { f.dlq.Total = 3 }, "DLQ", StatusWarn},
+		{"nats down", func(f *fakeClient) { f.ready.NATS = "disconnected" }, "NATS", StatusFail},
+		{"nats partial", func(f *fakeClient) {
+			f.ready.NATS, f.ready.DisconnectedOrgs = "partially_connected", []string{"org_a"}
+		}, "NATS", StatusWarn},
+		{"database down", func(f *fakeClient) { f.ready.Database = "disconnected" }, "Database", StatusFail},
+		{"webhooks some failures", func(f *fakeClient) {
+			f.webhooks.Deliveries = client.WebhookDeliveryStats{Total: 100, Failed: 10}
+		}, "Webhooks", StatusWarn},
+		{"webhooks mostly failing", func(f *fakeClient) {
+			f.webhooks.Deliveries = client.WebhookDeliveryStats{Total: 100, Failed: 50}
+		}, "Webhooks", StatusFail},
+		{"failed schedules", func(f *fakeClient) { f.schedules.Failed = 1 }, "Schedules", StatusWarn},
+		{"schedules unsupported", func(f *fakeClient) {
+			f.schedErr = &client.APIError{StatusCode: http.StatusNotImplemented, Message: "not available"}
+		}, "Schedules", StatusSkip},
+		{"storage nearly full", func(f *fakeClient) { f.streams[0].Bytes = 900 << 20 }, "Storage NOTIF_EVENTS", StatusWarn},
+		{"storage full", func(f *fakeClient) { f.streams[0].Bytes = 1 << 30 }, "Storage NOTIF_EVENTS", StatusFail},
+		{"storage unlimited", func(f *fakeClient) { f.streams[0].MaxBytes = -1 }, "Storage NOTIF_EVENTS", StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := healthyClient()
+			tt.modify(f)
+
+			c := Server(f).Check(tt.check)
+			if c == nil {
+				t.Fatalf("missing check %q", tt.check)
+			}
+			if c.Status != tt.want {
+				t.Errorf("expected %s, got %s (%s)", tt.want, c.Status, c.Detail)
+			}
+		})
+	}
+}
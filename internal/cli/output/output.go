@@ -3,6 +3,7 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
@@ -24,12 +25,21 @@ const (
 type Output struct {
 	jsonMode bool
 	noColor  bool
+	w        io.Writer
 }
 
 // New creates a new Output instance.
 func New(jsonMode bool) *Output {
 	noColor := os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
-	return &Output{jsonMode: jsonMode, noColor: noColor}
+	return &Output{jsonMode: jsonMode, noColor: noColor, w: os.Stdout}
+}
+
+// Stderr returns a copy of the Output that writes status messages to stderr,
+// keeping stdout free for machine-readable data.
+func (o *Output) Stderr() *Output {
+	cp := *o
+	cp.w = os.Stderr
+	return &cp
 }
 
 func (o *Output) color(c, text string) string {
@@ -44,7 +54,7 @@ func (o *Output) Success(format string, args ...any) {
 	if o.jsonMode {
 		return
 	}
-	fmt.Printf(o.color(Green, "✓ ")+format+"\n", args...)
+	fmt.Fprintf(o.w, o.color(Green, "✓ ")+format+"\n", args...)
 }
 
 // Error prints an error message.
@@ -60,7 +70,7 @@ func (o *Output) Warn(format string, args ...any) {
 	if o.jsonMode {
 		return
 	}
-	fmt.Printf(o.color(Yellow, "! ")+format+"\n", args...)
+	fmt.Fprintf(o.w, o.color(Yellow, "! ")+format+"\n", args...)
 }
 
 // Info prints an info message.
@@ -68,7 +78,7 @@ func (o *Output) Info(format string, args ...any) {
 	if o.jsonMode {
 		return
 	}
-	fmt.Printf(o.color(Cyan, "→ ")+format+"\n", args...)
+	fmt.Fprintf(o.w, o.color(Cyan, "→ ")+format+"\n", args...)
 }
 
 // Header prints a header.
@@ -76,7 +86,7 @@ func (o *Output) Header(text string) {
 	if o.jsonMode {
 		return
 	}
-	fmt.Println(o.color(Bold, text))
+	fmt.Fprintln(o.w, o.color(Bold, text))
 }
 
 // KeyValue prints a key-value pair.
@@ -84,7 +94,7 @@ func (o *Output) KeyValue(key, value string) {
 	if o.jsonMode {
 		return
 	}
-	fmt.Printf("  %s: %s\n", o.color(Gray, key), value)
+	fmt.Fprintf(o.w, "  %s: %s\n", o.color(Gray, key), value)
 }
 
 // Divider prints a divider line.
@@ -92,7 +102,7 @@ func (o *Output) Divider() {
 	if o.jsonMode {
 		return
 	}
-	fmt.Println(o.color(Gray, "─────────────────────────────────────────"))
+	fmt.Fprintln(o.w, o.color(Gray, "─────────────────────────────────────────"))
 }
 
 // JSON prints data as JSON.
@@ -115,7 +125,7 @@ func (o *Output) Event(id, topic string, data json.RawMessage, ts time.Time) {
 		})
 		return
 	}
-	fmt.Printf("%s %s %s\n",
+	fmt.Fprintf(o.w, "%s %s %s\n",
 		o.color(Gray, ts.Format("15:04:05")),
 		o.color(Magenta, topic),
 		string(data),
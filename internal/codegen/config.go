@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -48,6 +49,7 @@ func (s SchemaList) MarshalYAML() (interface{}, error) {
 type OutputConfig struct {
 	TypeScript string `yaml:"typescript,omitempty"`
 	Go         string `yaml:"go,omitempty"`
+	Python     string `yaml:"python,omitempty"`
 }
 
 // OptionsConfig holds language-specific generation options.
@@ -58,7 +60,29 @@ type OptionsConfig struct {
 
 // TypeScriptOptions holds TypeScript generation options.
 type TypeScriptOptions struct {
-	Exports string `yaml:"exports,omitempty"` // named | default
+	Exports string       `yaml:"exports,omitempty"` // named | default
+	Unions  []TopicUnion `yaml:"unions,omitempty"`
+}
+
+// TopicUnion groups the schemas whose topics share a prefix into one
+// discriminated union keyed by topic, e.g. orders.created and orders.shipped
+// under "orders".
+type TopicUnion struct {
+	Name        string `yaml:"name,omitempty"` // Defaults to <Prefix>Event
+	TopicPrefix string `yaml:"topicPrefix"`
+}
+
+// TypeName returns the union's type name.
+func (u TopicUnion) TypeName() string {
+	if u.Name != "" {
+		return u.Name
+	}
+	return toPascalCase(strings.ReplaceAll(u.TopicPrefix, ".", "_")) + "Event"
+}
+
+// Matches reports whether topic falls under the union's prefix.
+func (u TopicUnion) Matches(topic string) bool {
+	return topic == u.TopicPrefix || strings.HasPrefix(topic, u.TopicPrefix+".")
 }
 
 // GoOptions holds Go generation options.
@@ -139,7 +163,7 @@ func (c *Config) validate() error {
 		return fmt.Errorf("unsupported config version: %d (expected 1)", c.Version)
 	}
 
-	if c.Output.TypeScript == "" && c.Output.Go == "" {
+	if c.Output.TypeScript == "" && c.Output.Go == "" && c.Output.Python == "" {
 		return fmt.Errorf("at least one output language must be configured")
 	}
 
@@ -153,6 +177,23 @@ func (c *Config) validate() error {
 		}
 	}
 
+	seen := make(map[string]bool)
+	for i, u := range c.Options.TypeScript.Unions {
+		if u.TopicPrefix == "" {
+			return fmt.Errorf("union at index %d has no topicPrefix", i)
+		}
+		if strings.ContainsAny(u.TopicPrefix, "*> ") || strings.HasPrefix(u.TopicPrefix, ".") || strings.HasSuffix(u.TopicPrefix, ".") {
+			return fmt.Errorf("union %q: topicPrefix must be literal dot-separated tokens", u.TopicPrefix)
+		}
+		if u.Name != "" && !isTSIdentifier(u.Name) {
+			return fmt.Errorf("union %q: name %q is not a valid TypeScript identifier", u.TopicPrefix, u.Name)
+		}
+		if seen[u.TypeName()] {
+			return fmt.Errorf("duplicate union %s", u.TypeName())
+		}
+		seen[u.TypeName()] = true
+	}
+
 	return nil
 }
 
@@ -185,6 +226,9 @@ func (c *Config) GetLanguagesForSchema(entry SchemaEntry) []string {
 	if c.Output.Go != "" {
 		langs = append(langs, "go")
 	}
+	if c.Output.Python != "" {
+		langs = append(langs, "python")
+	}
 	return langs
 }
 
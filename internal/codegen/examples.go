@@ -0,0 +1,48 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/filipexyz/notif/internal/schema"
+)
+
+// ExamplesError lists the examples of a schema that don't conform to it,
+// one line per violation.
+type ExamplesError struct {
+	Failures []string
+}
+
+func (e *ExamplesError) Error() string {
+	return "examples do not match schema:\n  " + strings.Join(e.Failures, "\n  ")
+}
+
+// ValidateExamples checks every declared example against the schema's own
+// JSON Schema. Examples are numbered from 1 in the order declared.
+func ValidateExamples(s *Schema) error {
+	if len(s.Examples) == 0 {
+		return nil
+	}
+
+	validator := schema.NewValidator()
+	var failures []string
+	for i, example := range s.Examples {
+		result, err := validator.Validate(s.Source, example)
+		if err != nil {
+			return fmt.Errorf("validate example #%d: %w", i+1, err)
+		}
+		// The validator reports an example's violations in no fixed order
+		var violations []string
+		for _, ve := range result.Errors {
+			violations = append(violations, fmt.Sprintf("example #%d: %s: %s", i+1, ve.Field, ve.Message))
+		}
+		sort.Strings(violations)
+		failures = append(failures, violations...)
+	}
+
+	if len(failures) > 0 {
+		return &ExamplesError{Failures: failures}
+	}
+	return nil
+}
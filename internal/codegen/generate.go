@@ -16,10 +16,13 @@ type Generator struct {
 	client     *client.Client
 	tsGen      *TypeScriptGenerator
 	goGen      *GoGenerator
+	pyGen      *PythonGenerator
 	configDir  string // Directory containing the config file
 	dryRun     bool
 	verbose    bool
 	onProgress func(msg string) // Callback for progress messages
+
+	validateExamples bool // Fail schemas whose examples don't conform
 }
 
 // GeneratorOption configures the generator.
@@ -32,6 +35,14 @@ func WithDryRun(dryRun bool) GeneratorOption {
 	}
 }
 
+// WithValidateExamples checks each schema's examples against the schema
+// before generating, failing the schema on any mismatch.
+func WithValidateExamples(validate bool) GeneratorOption {
+	return func(g *Generator) {
+		g.validateExamples = validate
+	}
+}
+
 // WithVerbose enables verbose output.
 func WithVerbose(verbose bool) GeneratorOption {
 	return func(g *Generator) {
@@ -54,6 +65,7 @@ func NewGenerator(config *Config, client *client.Client, configPath string, opts
 		configDir: filepath.Dir(configPath),
 		tsGen:     NewTypeScriptGenerator(config.Options.TypeScript),
 		goGen:     NewGoGenerator(config.Options.Go),
+		pyGen:     NewPythonGenerator(),
 	}
 
 	for _, opt := range opts {
@@ -75,6 +87,7 @@ type GenerateResult struct {
 // Generate generates code for all configured schemas.
 func (g *Generator) Generate(filterSchema string) ([]GenerateResult, error) {
 	var results []GenerateResult
+	var tsSchemas []*Schema
 
 	// Get schema entries to process
 	entries, err := g.getSchemaEntries()
@@ -98,11 +111,31 @@ func (g *Generator) Generate(filterSchema string) ([]GenerateResult, error) {
 			continue
 		}
 
+		if g.validateExamples {
+			if err := ValidateExamples(schema); err != nil {
+				results = append(results, GenerateResult{
+					Schema: entry.Name,
+					Error:  err,
+				})
+				continue
+			}
+		}
+
 		// Generate for each configured language
 		languages := g.config.GetLanguagesForSchema(entry)
 		for _, lang := range languages {
 			result := g.generateForLanguage(schema, lang)
 			results = append(results, result)
+			if lang == "typescript" && result.Generated {
+				tsSchemas = append(tsSchemas, schema)
+			}
+		}
+	}
+
+	// Topic unions span schemas, so a single-schema run leaves them alone
+	if filterSchema == "" && g.config.Output.TypeScript != "" {
+		for _, union := range g.config.Options.TypeScript.Unions {
+			results = append(results, g.generateTopicUnion(union, tsSchemas))
 		}
 	}
 
@@ -152,6 +185,7 @@ func (g *Generator) getSchemaEntries() ([]SchemaEntry, error) {
 func (g *Generator) fetchSchema(entry SchemaEntry) (*Schema, error) {
 	var schemaJSON []byte
 	var topic, version string
+	var examples []json.RawMessage
 
 	if entry.File != "" {
 		// Load from local file
@@ -167,10 +201,11 @@ func (g *Generator) fetchSchema(entry SchemaEntry) (*Schema, error) {
 
 		// Parse YAML file (like the push command does)
 		var def struct {
-			Name    string      `yaml:"name"`
-			Version string      `yaml:"version"`
-			Topic   string      `yaml:"topic"`
-			Schema  interface{} `yaml:"schema"`
+			Name     string        `yaml:"name"`
+			Version  string        `yaml:"version"`
+			Topic    string        `yaml:"topic"`
+			Schema   interface{}   `yaml:"schema"`
+			Examples []interface{} `yaml:"examples"`
 		}
 		if err := yaml.Unmarshal(data, &def); err != nil {
 			return nil, fmt.Errorf("failed to parse schema file: %w", err)
@@ -183,6 +218,13 @@ func (g *Generator) fetchSchema(entry SchemaEntry) (*Schema, error) {
 
 		topic = def.Topic
 		version = def.Version
+		for _, ex := range def.Examples {
+			data, err := json.Marshal(ex)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal example: %w", err)
+			}
+			examples = append(examples, data)
+		}
 	} else {
 		// Fetch from server
 		g.log("Fetching schema %s from server...", entry.Name)
@@ -199,6 +241,11 @@ func (g *Generator) fetchSchema(entry SchemaEntry) (*Schema, error) {
 		schemaJSON = s.LatestVersion.Schema
 		topic = s.TopicPattern
 		version = s.LatestVersion.Version
+		if len(s.LatestVersion.Examples) > 0 {
+			if err := json.Unmarshal(s.LatestVersion.Examples, &examples); err != nil {
+				return nil, fmt.Errorf("failed to parse examples: %w", err)
+			}
+		}
 	}
 
 	// Parse JSON Schema to IR
@@ -206,6 +253,8 @@ func (g *Generator) fetchSchema(entry SchemaEntry) (*Schema, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse schema: %w", err)
 	}
+	schema.Source = schemaJSON
+	schema.Examples = examples
 
 	return schema, nil
 }
@@ -230,6 +279,10 @@ func (g *Generator) generateForLanguage(schema *Schema, lang string) GenerateRes
 		code, err = g.goGen.GenerateWithImports(schema)
 		filename = toSnakeCase(schema.Name) + ".go"
 		outDir = g.config.Output.Go
+	case "python":
+		code, err = g.pyGen.Generate(schema)
+		filename = pythonModuleName(schema.Name) + ".py"
+		outDir = g.config.Output.Python
 	default:
 		result.Error = fmt.Errorf("unsupported language: %s", lang)
 		return result
@@ -240,6 +293,35 @@ func (g *Generator) generateForLanguage(schema *Schema, lang string) GenerateRes
 		return result
 	}
 
+	return g.writeResult(result, code, outDir, filename)
+}
+
+// generateTopicUnion generates a union's TypeScript file from the generated
+// schemas whose topics fall under its prefix.
+func (g *Generator) generateTopicUnion(union TopicUnion, schemas []*Schema) GenerateResult {
+	result := GenerateResult{
+		Schema:   union.TypeName(),
+		Language: "typescript",
+	}
+
+	var members []*Schema
+	for _, schema := range schemas {
+		if union.Matches(schema.Topic) {
+			members = append(members, schema)
+		}
+	}
+
+	code, err := g.tsGen.GenerateTopicUnion(union, members)
+	if err != nil {
+		result.Error = fmt.Errorf("union %s: %w", union.TypeName(), err)
+		return result
+	}
+
+	return g.writeResult(result, code, g.config.Output.TypeScript, toSnakeCase(union.TypeName())+".ts")
+}
+
+// writeResult writes code to filename under outDir, unless this is a dry run.
+func (g *Generator) writeResult(result GenerateResult, code, outDir, filename string) GenerateResult {
 	// Resolve output path
 	if !filepath.IsAbs(outDir) {
 		outDir = filepath.Join(g.configDir, outDir)
@@ -294,6 +376,30 @@ func (g *Generator) generateBarrelFiles(results []GenerateResult) error {
 		g.log("Generated: %s", barrelPath)
 	}
 
+	// Python packages export through __init__.py
+	var pySchemas []string
+	for _, r := range results {
+		if r.Language == "python" && r.Generated && r.Error == nil {
+			pySchemas = append(pySchemas, r.Schema)
+		}
+	}
+
+	if len(pySchemas) > 0 && g.config.Output.Python != "" {
+		outDir := g.config.Output.Python
+		if !filepath.IsAbs(outDir) {
+			outDir = filepath.Join(g.configDir, outDir)
+		}
+
+		initCode := g.pyGen.GenerateInitFile(pySchemas)
+		initPath := filepath.Join(outDir, "__init__.py")
+
+		if err := os.WriteFile(initPath, []byte(initCode), 0644); err != nil {
+			return fmt.Errorf("failed to write __init__.py: %w", err)
+		}
+
+		g.log("Generated: %s", initPath)
+	}
+
 	return nil
 }
 
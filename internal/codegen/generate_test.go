@@ -0,0 +1,243 @@
+package codegen
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// generateGo runs the generator on a local schema file with example
+// validation enabled, writing Go output to a temp dir.
+func generateGo(t *testing.T, file string) GenerateResult {
+	t.Helper()
+	outDir := t.TempDir()
+	cfg := &Config{
+		Version: 1,
+		Output:  OutputConfig{Go: outDir},
+		Options: OptionsConfig{Go: GoOptions{Package: "events"}},
+		Schemas: SchemaList{Entries: []SchemaEntry{{Name: strings.TrimSuffix(file, ".yaml"), File: file}}},
+	}
+	gen := NewGenerator(cfg, nil, filepath.Join("testdata", "examples", ".notif.yaml"), WithValidateExamples(true))
+
+	results, err := gen.Generate("")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	return results[0]
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "examples", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("update golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s mismatch (run with -update to accept)\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestGenerate_ValidateExamplesPasses(t *testing.T) {
+	result := generateGo(t, "order-placed.yaml")
+	if result.Error != nil {
+		t.Fatalf("expected conforming examples to pass, got %v", result.Error)
+	}
+	if !result.Generated {
+		t.Fatal("expected code to be generated")
+	}
+
+	code, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatalf("read generated code: %v", err)
+	}
+	assertGolden(t, "order-placed.go.golden", code)
+}
+
+func TestGenerate_ValidateExamplesFails(t *testing.T) {
+	result := generateGo(t, "order-drift.yaml")
+	if result.Error == nil {
+		t.Fatal("expected non-conforming examples to fail generation")
+	}
+	if result.Generated || result.FilePath != "" {
+		t.Error("expected no code to be generated")
+	}
+	assertGolden(t, "order-drift.err.golden", []byte(result.Error.Error()+"\n"))
+}
+
+func TestValidateExamples_NoExamples(t *testing.T) {
+	s := &Schema{Source: []byte(`{"type":"object"}`)}
+	if err := ValidateExamples(s); err != nil {
+		t.Errorf("expected schema without examples to pass, got %v", err)
+	}
+}
+
+func TestGenerate_Python(t *testing.T) {
+	outDir := t.TempDir()
+	cfg := &Config{
+		Version: 1,
+		Output:  OutputConfig{Python: outDir},
+		Schemas: SchemaList{Entries: []SchemaEntry{{Name: "order-shipped", File: "order-shipped.yaml"}}},
+	}
+	gen := NewGenerator(cfg, nil, filepath.Join("testdata", "examples", ".notif.yaml"), WithValidateExamples(true))
+
+	results, err := gen.Generate("")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(results) != 1 || results[0].Error != nil {
+		t.Fatalf("expected 1 generated result, got %+v", results)
+	}
+	if got := filepath.Base(results[0].FilePath); got != "order_shipped.py" {
+		t.Errorf("expected order_shipped.py, got %s", got)
+	}
+
+	code, err := os.ReadFile(results[0].FilePath)
+	if err != nil {
+		t.Fatalf("read generated code: %v", err)
+	}
+	assertGolden(t, "order-shipped.py.golden", code)
+
+	init, err := os.ReadFile(filepath.Join(outDir, "__init__.py"))
+	if err != nil {
+		t.Fatalf("read __init__.py: %v", err)
+	}
+	if want := "from .order_shipped import OrderShipped, emit_order_shipped, subscribe_order_shipped\n"; !strings.Contains(string(init), want) {
+		t.Errorf("expected __init__.py to export %q, got:\n%s", want, init)
+	}
+}
+
+func TestGenerate_TopicUnion(t *testing.T) {
+	outDir := t.TempDir()
+	cfg := &Config{
+		Version: 1,
+		Output:  OutputConfig{TypeScript: outDir},
+		Options: OptionsConfig{TypeScript: TypeScriptOptions{
+			Exports: "named",
+			Unions:  []TopicUnion{{TopicPrefix: "orders"}},
+		}},
+		Schemas: SchemaList{Entries: []SchemaEntry{
+			{Name: "order-placed", File: "order-placed.yaml"},
+			{Name: "order-shipped", File: "order-shipped.yaml"},
+			{Name: "order-cancelled", File: "order-cancelled.yaml"},
+		}},
+	}
+	gen := NewGenerator(cfg, nil, filepath.Join("testdata", "examples", ".notif.yaml"))
+
+	results, err := gen.Generate("")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 3 schemas and 1 union, got %d results", len(results))
+	}
+	union := results[3]
+	if union.Error != nil || union.Schema != "OrdersEvent" {
+		t.Fatalf("expected OrdersEvent union, got %+v", union)
+	}
+
+	code, err := os.ReadFile(union.FilePath)
+	if err != nil {
+		t.Fatalf("read generated union: %v", err)
+	}
+	assertGolden(t, "orders-event.ts.golden", code)
+
+	// A switch over every topic must type-check as exhaustive
+	check := `import { type OrdersEvent } from './orders_event';
+
+export function describe(event: OrdersEvent): string {
+  switch (event.topic) {
+    case 'orders.cancelled':
+      return event.data.reason;
+    case 'orders.placed':
+      return String(event.data.amount);
+    case 'orders.shipped':
+      return event.data.shipped_at;
+    default: {
+      const unreachable: never = event;
+      return unreachable;
+    }
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(outDir, "check.ts"), []byte(check), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assertTypeScriptCompiles(t, outDir)
+}
+
+func TestGenerate_TopicUnionSharedTopic(t *testing.T) {
+	cfg := &Config{
+		Version: 1,
+		Output:  OutputConfig{TypeScript: t.TempDir()},
+		Options: OptionsConfig{TypeScript: TypeScriptOptions{Unions: []TopicUnion{{Name: "OrderEvent", TopicPrefix: "orders"}}}},
+		Schemas: SchemaList{Entries: []SchemaEntry{
+			{Name: "order-placed", File: "order-placed.yaml"},
+			{Name: "order-drift", File: "order-drift.yaml"},
+		}},
+	}
+	results, err := NewGenerator(cfg, nil, filepath.Join("testdata", "examples", ".notif.yaml")).Generate("")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	union := results[len(results)-1]
+	if union.Error == nil || !strings.Contains(union.Error.Error(), `share topic "orders.placed"`) {
+		t.Errorf("expected shared topic error, got %v", union.Error)
+	}
+}
+
+// assertTypeScriptCompiles type-checks the .ts files in dir with tsc,
+// resolving zod from the global node_modules. When either is missing it
+// skips, except in CI.
+func assertTypeScriptCompiles(t *testing.T, dir string) {
+	t.Helper()
+	tsc, err := exec.LookPath("tsc")
+	if err != nil {
+		missingTool(t, "tsc")
+	}
+	root, err := exec.Command("npm", "root", "-g").Output()
+	if err != nil {
+		missingTool(t, "npm")
+	}
+	zod := filepath.Join(strings.TrimSpace(string(root)), "zod")
+	if _, err := os.Stat(zod); err != nil {
+		missingTool(t, "zod (global)")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(zod, filepath.Join(dir, "node_modules", "zod")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.ts"))
+	args := append([]string{"--noEmit", "--strict", "--target", "es2020", "--module", "esnext", "--moduleResolution", "bundler"}, files...)
+	cmd := exec.Command(tsc, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("tsc: %v\n%s", err, out)
+	}
+}
+
+// missingTool skips the test locally, where the TypeScript toolchain is
+// optional, and fails it in CI, which installs it.
+func missingTool(t *testing.T, tool string) {
+	t.Helper()
+	if os.Getenv("CI") != "" {
+		t.Fatalf("%s not installed; CI installs it in .github/workflows/test.yml", tool)
+	}
+	t.Skip(tool + " not installed")
+}
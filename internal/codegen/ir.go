@@ -1,5 +1,7 @@
 package codegen
 
+import "encoding/json"
+
 // TypeKind represents the kind of a type in the intermediate representation.
 type TypeKind int
 
@@ -49,6 +51,11 @@ type Schema struct {
 	Description string
 	Root        *Type
 	Definitions map[string]*Type // Named definitions/nested types
+
+	// Source is the JSON Schema the IR was parsed from, and Examples the
+	// example payloads declared alongside it.
+	Source   json.RawMessage
+	Examples []json.RawMessage
 }
 
 // Type represents a type in the intermediate representation.
@@ -0,0 +1,386 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PythonGenerator generates Python dataclasses with typed emit/subscribe
+// helpers for the notifsh SDK.
+type PythonGenerator struct {
+	// defs are the definitions of the schema being generated, by class name.
+	defs map[string]*Type
+}
+
+// NewPythonGenerator creates a new Python generator.
+func NewPythonGenerator() *PythonGenerator {
+	return &PythonGenerator{}
+}
+
+// pythonKeywords can't be used as field names; they get a trailing "_".
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true,
+	"def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true,
+	"for": true, "from": true, "global": true, "if": true, "import": true, "in": true,
+	"is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true,
+	"raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// Generate generates Python code for a schema.
+func (g *PythonGenerator) Generate(schema *Schema) (string, error) {
+	rootName := toPascalCase(schema.Name)
+	g.defs = make(map[string]*Type)
+	for name, def := range schema.Definitions {
+		if className := toPascalCase(name); className != rootName {
+			g.defs[className] = def
+		}
+	}
+	defNames := make([]string, 0, len(g.defs))
+	for name := range g.defs {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+
+	var b strings.Builder
+
+	// Schema metadata comment
+	b.WriteString(fmt.Sprintf("# Schema: %s\n", schema.Name))
+	if schema.Topic != "" {
+		b.WriteString(fmt.Sprintf("# Topic: %s\n", schema.Topic))
+	}
+	if schema.Version != "" {
+		b.WriteString(fmt.Sprintf("# Version: %s\n", schema.Version))
+	}
+	if schema.Description != "" {
+		b.WriteString(fmt.Sprintf("# %s\n", schema.Description))
+	}
+	b.WriteString("# Code generated by notif. DO NOT EDIT.\n\n")
+
+	b.WriteString("from __future__ import annotations\n\n")
+	b.WriteString("from dataclasses import dataclass\n")
+	b.WriteString("from datetime import datetime\n")
+	b.WriteString("from typing import Any, AsyncIterator, Literal, Optional\n\n")
+	b.WriteString("from notifsh import EmitResponse, Event, Notif\n\n")
+	if schema.Topic != "" {
+		b.WriteString(fmt.Sprintf("TOPIC = %q\n\n", schema.Topic))
+	}
+	b.WriteString("\n")
+	b.WriteString("def _parse_datetime(value: str) -> datetime:\n")
+	b.WriteString("    return datetime.fromisoformat(value.replace(\"Z\", \"+00:00\"))\n")
+
+	// Nested types first, so the root reads last before the helpers
+	for _, name := range defNames {
+		b.WriteString("\n\n")
+		b.WriteString(g.generateType(g.defs[name], name))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(g.generateType(schema.Root, rootName))
+
+	b.WriteString("\n\n")
+	b.WriteString(g.generateHelpers(schema, rootName))
+
+	return b.String(), nil
+}
+
+// generateType generates a dataclass for an object type, or a type alias
+// for anything else.
+func (g *PythonGenerator) generateType(t *Type, name string) string {
+	if !isPythonClass(t) {
+		return fmt.Sprintf("%s = %s\n", name, g.pyType(t))
+	}
+
+	props := pythonFieldOrder(t.Properties)
+
+	var b strings.Builder
+	b.WriteString("@dataclass\n")
+	b.WriteString(fmt.Sprintf("class %s:\n", name))
+	if t.Description != "" {
+		b.WriteString(fmt.Sprintf("    %s\n\n", pyDocstring(t.Description)))
+	}
+	for _, prop := range props {
+		if prop.Description != "" {
+			b.WriteString(fmt.Sprintf("    # %s\n", prop.Description))
+		}
+		field := fmt.Sprintf("    %s: %s", pyFieldName(prop.JSONName), g.fieldType(prop))
+		if prop.IsOptional() {
+			field += " = None"
+		}
+		b.WriteString(field + "\n")
+	}
+	if len(props) > 0 {
+		b.WriteString("\n")
+	}
+
+	// from_dict
+	b.WriteString("    @classmethod\n")
+	b.WriteString(fmt.Sprintf("    def from_dict(cls, data: dict[str, Any]) -> %s:\n", name))
+	if len(props) == 0 {
+		b.WriteString("        return cls()\n")
+	} else {
+		b.WriteString("        return cls(\n")
+		for _, prop := range props {
+			b.WriteString(fmt.Sprintf("            %s=%s,\n", pyFieldName(prop.JSONName), g.fromDictField(prop)))
+		}
+		b.WriteString("        )\n")
+	}
+	b.WriteString("\n")
+
+	// to_dict: optional fields that are None are left out
+	b.WriteString("    def to_dict(self) -> dict[str, Any]:\n")
+	b.WriteString("        out: dict[str, Any] = {}\n")
+	for _, prop := range props {
+		attr := "self." + pyFieldName(prop.JSONName)
+		value := g.toExpr(prop.Type, attr)
+		switch {
+		case !prop.Required:
+			b.WriteString(fmt.Sprintf("        if %s is not None:\n", attr))
+			b.WriteString(fmt.Sprintf("            out[%q] = %s\n", prop.JSONName, value))
+		case prop.Type.Nullable && value != attr:
+			b.WriteString(fmt.Sprintf("        out[%q] = None if %s is None else %s\n", prop.JSONName, attr, value))
+		default:
+			b.WriteString(fmt.Sprintf("        out[%q] = %s\n", prop.JSONName, value))
+		}
+	}
+	b.WriteString("        return out\n")
+	return b.String()
+}
+
+// generateHelpers generates typed emit and subscribe helpers for the root.
+func (g *PythonGenerator) generateHelpers(schema *Schema, rootName string) string {
+	fn := pythonModuleName(schema.Name)
+	topicParam := "topic: str"
+	topicsDefault := "topics"
+	if schema.Topic != "" {
+		topicParam = "topic: str = TOPIC"
+		topicsDefault = "topics or (TOPIC,)"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("async def emit_%s(\n", fn))
+	b.WriteString(fmt.Sprintf("    client: Notif, data: %s, %s\n", rootName, topicParam))
+	b.WriteString(") -> EmitResponse:\n")
+	b.WriteString(fmt.Sprintf("    \"\"\"Emit an event with %s data.\"\"\"\n", schema.Name))
+	b.WriteString(fmt.Sprintf("    return await client.emit(topic, %s)\n", g.toExpr(schema.Root, "data")))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("async def subscribe_%s(\n", fn))
+	b.WriteString("    client: Notif, *topics: str, **options: Any\n")
+	b.WriteString(fmt.Sprintf(") -> AsyncIterator[tuple[%s, Event]]:\n", rootName))
+	b.WriteString(fmt.Sprintf("    \"\"\"Yield %s events as (data, event) pairs.\"\"\"\n", schema.Name))
+	b.WriteString(fmt.Sprintf("    async for event in client.subscribe(*(%s), **options):\n", topicsDefault))
+	b.WriteString(fmt.Sprintf("        yield %s, event\n", g.fromExpr(schema.Root, "event.data")))
+	return b.String()
+}
+
+// pyType returns the type hint for t.
+func (g *PythonGenerator) pyType(t *Type) string {
+	if t == nil {
+		return "Any"
+	}
+
+	var base string
+	switch t.Kind {
+	case KindObject:
+		if isPythonClass(t) {
+			base = t.Name
+		} else {
+			base = "dict[str, Any]"
+		}
+	case KindArray:
+		base = fmt.Sprintf("list[%s]", g.pyType(t.Items))
+	case KindString:
+		if t.Format == "date-time" {
+			base = "datetime"
+		} else {
+			base = "str"
+		}
+	case KindNumber:
+		base = "float"
+	case KindInteger:
+		base = "int"
+	case KindBoolean:
+		base = "bool"
+	case KindEnum:
+		quoted := make([]string, len(t.Enum))
+		for i, v := range t.Enum {
+			quoted[i] = fmt.Sprintf("%q", v)
+		}
+		base = fmt.Sprintf("Literal[%s]", strings.Join(quoted, ", "))
+	case KindRef:
+		base = toPascalCase(t.Ref)
+	default:
+		return "Any"
+	}
+
+	if t.Nullable {
+		base = fmt.Sprintf("Optional[%s]", base)
+	}
+	return base
+}
+
+// fieldType is a property's type hint: optional properties may be None.
+func (g *PythonGenerator) fieldType(prop Property) string {
+	hint := g.pyType(prop.Type)
+	if !prop.Required && !prop.Type.Nullable && hint != "Any" {
+		hint = fmt.Sprintf("Optional[%s]", hint)
+	}
+	return hint
+}
+
+// fromDictField reads a property out of the data dict in from_dict.
+func (g *PythonGenerator) fromDictField(prop Property) string {
+	lookup := fmt.Sprintf("data[%q]", prop.JSONName)
+	if !prop.Required {
+		lookup = fmt.Sprintf("data.get(%q)", prop.JSONName)
+	}
+	value := g.fromExpr(prop.Type, fmt.Sprintf("data[%q]", prop.JSONName))
+	if value == fmt.Sprintf("data[%q]", prop.JSONName) {
+		return lookup
+	}
+	if !prop.Required || prop.Type.Nullable {
+		return fmt.Sprintf("None if %s is None else %s", lookup, value)
+	}
+	return value
+}
+
+// fromExpr converts the JSON value expr to t's Python value.
+func (g *PythonGenerator) fromExpr(t *Type, expr string) string {
+	if t == nil {
+		return expr
+	}
+	switch t.Kind {
+	case KindObject:
+		if isPythonClass(t) {
+			return fmt.Sprintf("%s.from_dict(%s)", t.Name, expr)
+		}
+	case KindArray:
+		if item := g.fromExpr(t.Items, "item"); item != "item" {
+			if t.Items.Nullable {
+				item = fmt.Sprintf("None if item is None else %s", item)
+			}
+			return fmt.Sprintf("[%s for item in %s]", item, expr)
+		}
+	case KindString:
+		if t.Format == "date-time" {
+			return fmt.Sprintf("_parse_datetime(%s)", expr)
+		}
+	case KindRef:
+		if def, ok := g.defs[toPascalCase(t.Ref)]; ok {
+			if isPythonClass(def) {
+				return fmt.Sprintf("%s.from_dict(%s)", toPascalCase(t.Ref), expr)
+			}
+			return g.fromExpr(def, expr)
+		}
+	}
+	return expr
+}
+
+// toExpr converts the Python value expr of type t to its JSON value.
+func (g *PythonGenerator) toExpr(t *Type, expr string) string {
+	if t == nil {
+		return expr
+	}
+	switch t.Kind {
+	case KindObject:
+		if isPythonClass(t) {
+			return expr + ".to_dict()"
+		}
+	case KindArray:
+		if item := g.toExpr(t.Items, "item"); item != "item" {
+			if t.Items.Nullable {
+				item = fmt.Sprintf("None if item is None else %s", item)
+			}
+			return fmt.Sprintf("[%s for item in %s]", item, expr)
+		}
+	case KindString:
+		if t.Format == "date-time" {
+			return expr + ".isoformat()"
+		}
+	case KindRef:
+		if def, ok := g.defs[toPascalCase(t.Ref)]; ok {
+			if isPythonClass(def) {
+				return expr + ".to_dict()"
+			}
+			return g.toExpr(def, expr)
+		}
+	}
+	return expr
+}
+
+// GenerateInitFile generates an __init__.py that exports each schema's
+// root class and helpers.
+func (g *PythonGenerator) GenerateInitFile(schemas []string) string {
+	var b strings.Builder
+
+	b.WriteString("# Auto-generated package file for notif.sh schemas\n")
+	b.WriteString("# Do not edit manually\n\n")
+
+	sort.Strings(schemas)
+
+	for _, name := range schemas {
+		module := pythonModuleName(name)
+		b.WriteString(fmt.Sprintf("from .%s import %s, emit_%s, subscribe_%s\n", module, toPascalCase(name), module, module))
+	}
+
+	return b.String()
+}
+
+// isPythonClass reports whether t is generated as a dataclass.
+func isPythonClass(t *Type) bool {
+	return t != nil && t.Kind == KindObject && t.Name != "" && len(t.Properties) > 0
+}
+
+// pythonFieldOrder sorts properties by JSON name, those without a default
+// first as dataclasses require.
+func pythonFieldOrder(properties []Property) []Property {
+	props := make([]Property, len(properties))
+	copy(props, properties)
+	sort.SliceStable(props, func(i, j int) bool {
+		if props[i].IsOptional() != props[j].IsOptional() {
+			return !props[i].IsOptional()
+		}
+		return props[i].JSONName < props[j].JSONName
+	})
+	return props
+}
+
+// pythonModuleName is the module a schema is generated into.
+func pythonModuleName(schema string) string {
+	return strings.ReplaceAll(toSnakeCase(schema), "-", "_")
+}
+
+// pyFieldName turns a JSON property name into a snake_case identifier.
+func pyFieldName(jsonName string) string {
+	var b strings.Builder
+	for i, r := range jsonName {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	name := strings.ReplaceAll(b.String(), "__", "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "field_" + name
+	}
+	if pythonKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// pyDocstring quotes a description as a one-line docstring.
+func pyDocstring(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), `\`, `\\`)
+	s = strings.ReplaceAll(s, `"""`, `\"\"\"`)
+	s = strings.ReplaceAll(s, "\n", " ")
+	return `"""` + s + `"""`
+}
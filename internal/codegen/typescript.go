@@ -47,13 +47,15 @@ func (g *TypeScriptGenerator) Generate(schema *Schema) (string, error) {
 
 		for _, name := range defNames {
 			def := schema.Definitions[name]
+			// $defs keys may be lowercase; refs to them resolve to PascalCase
+			typeName := toPascalCase(name)
 			// Skip if it's the same as root
-			if name == toPascalCase(schema.Name) {
+			if typeName == toPascalCase(schema.Name) {
 				continue
 			}
 			zodCode := g.generateZodType(def)
-			b.WriteString(fmt.Sprintf("export const %sSchema = %s;\n\n", name, zodCode))
-			b.WriteString(fmt.Sprintf("export type %s = z.infer<typeof %sSchema>;\n\n", name, name))
+			b.WriteString(fmt.Sprintf("export const %sSchema = %s;\n\n", typeName, zodCode))
+			b.WriteString(fmt.Sprintf("export type %s = z.infer<typeof %sSchema>;\n\n", typeName, typeName))
 		}
 	}
 
@@ -249,6 +251,82 @@ func (g *TypeScriptGenerator) GenerateBarrelFile(schemas []string) string {
 	return b.String()
 }
 
+// GenerateTopicUnion generates a discriminated union keyed by topic over
+// schemas, plus a type guard per variant. Each schema must have a distinct,
+// literal topic; its types are imported from the schema's own file.
+func (g *TypeScriptGenerator) GenerateTopicUnion(union TopicUnion, schemas []*Schema) (string, error) {
+	if len(schemas) == 0 {
+		return "", fmt.Errorf("no schemas with topics under %q", union.TopicPrefix)
+	}
+	variants := make([]*Schema, len(schemas))
+	copy(variants, schemas)
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Topic < variants[j].Topic })
+
+	for i, s := range variants {
+		if strings.ContainsAny(s.Topic, "*>") {
+			return "", fmt.Errorf("schema %s: topic %q has wildcards and can't discriminate a union", s.Name, s.Topic)
+		}
+		if i > 0 && variants[i-1].Topic == s.Topic {
+			return "", fmt.Errorf("schemas %s and %s share topic %q", variants[i-1].Name, s.Name, s.Topic)
+		}
+	}
+
+	name := union.TypeName()
+	var b strings.Builder
+
+	// Header
+	b.WriteString("import { z } from 'zod';\n")
+	for _, s := range variants {
+		rootName := toPascalCase(s.Name)
+		b.WriteString(fmt.Sprintf("import { %sSchema, type %s } from './%s';\n", rootName, rootName, toSnakeCase(s.Name)))
+	}
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("// Topic union: %s\n", union.TopicPrefix))
+	b.WriteString("// Switch on .topic to narrow .data; the switch is exhaustive over every variant.\n\n")
+
+	// Union schema
+	b.WriteString(fmt.Sprintf("export const %sSchema = z.discriminatedUnion('topic', [\n", name))
+	for _, s := range variants {
+		b.WriteString(fmt.Sprintf("  z.object({ topic: z.literal('%s'), data: %sSchema }),\n", s.Topic, toPascalCase(s.Name)))
+	}
+	b.WriteString("]);\n\n")
+
+	// Type inference
+	b.WriteString(fmt.Sprintf("export type %s = z.infer<typeof %sSchema>;\n\n", name, name))
+	b.WriteString(fmt.Sprintf("export type %sTopic = %s['topic'];\n\n", name, name))
+
+	// Validation helper
+	b.WriteString("// Validation helper\n")
+	b.WriteString(fmt.Sprintf("export function validate%s(event: unknown): %s {\n", name, name))
+	b.WriteString(fmt.Sprintf("  return %sSchema.parse(event);\n", name))
+	b.WriteString("}\n")
+
+	// Type guards
+	for _, s := range variants {
+		rootName := toPascalCase(s.Name)
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("// Type guard for %s\n", s.Topic))
+		b.WriteString(fmt.Sprintf("export function is%sEvent(\n", rootName))
+		b.WriteString("  event: { topic: string; data: unknown },\n")
+		b.WriteString(fmt.Sprintf("): event is { topic: '%s'; data: %s } {\n", s.Topic, rootName))
+		b.WriteString(fmt.Sprintf("  return event.topic === '%s' && %sSchema.safeParse(event.data).success;\n", s.Topic, rootName))
+		b.WriteString("}\n")
+	}
+
+	return b.String(), nil
+}
+
+// isTSIdentifier reports whether s is a valid TypeScript identifier.
+func isTSIdentifier(s string) bool {
+	for i, r := range s {
+		if r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return s != ""
+}
+
 func escapeRegex(pattern string) string {
 	// Escape forward slashes for JavaScript regex literals
 	return strings.ReplaceAll(pattern, "/", "\\/")
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/caarlos0/env/v10"
+	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/security"
 )
 
 // AuthMode determines the authentication mode for the server.
@@ -22,6 +25,48 @@ type Config struct {
 	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`
 	MaxPayloadSize  int64         `env:"MAX_PAYLOAD_SIZE" envDefault:"262144"` // 256KB
 
+	// EmitBatchMaxEvents caps the events in one POST /emit/batch. Each event
+	// is still held to MaxPayloadSize.
+	EmitBatchMaxEvents int `env:"EMIT_BATCH_MAX_EVENTS" envDefault:"500"`
+	// EmitBatchMaxBytes caps the whole body of one POST /emit/batch.
+	EmitBatchMaxBytes int64 `env:"EMIT_BATCH_MAX_BYTES" envDefault:"4194304"` // 4MB
+
+	// EmitOutbox persists emitted events to Postgres before publishing, so
+	// emits keep succeeding while NATS is briefly unavailable; a relay
+	// publishes them every OutboxRelayInterval until JetStream accepts them.
+	EmitOutbox          bool          `env:"EMIT_OUTBOX" envDefault:"false"`
+	OutboxRelayInterval time.Duration `env:"OUTBOX_RELAY_INTERVAL" envDefault:"1s"`
+
+	// EventDataMaxBytes is the largest event payload kept in Postgres for
+	// GET /events?filter=; larger events are recorded without their data
+	// and never match a filter. Stored data is never pruned, so this is
+	// opt-in: 0, the default, keeps no data.
+	EventDataMaxBytes int `env:"EVENT_DATA_MAX_BYTES" envDefault:"0"`
+
+	// EmitIdempotencyWindow is how long an emit's Idempotency-Key is
+	// remembered; a retry within it returns the original event.
+	EmitIdempotencyWindow time.Duration `env:"EMIT_IDEMPOTENCY_WINDOW" envDefault:"24h"`
+
+	// WSMaxConnectionsPerKey caps concurrent WebSocket connections per API key
+	// unless the key sets its own max_connections. 0 = unlimited.
+	WSMaxConnectionsPerKey int `env:"WS_MAX_CONNECTIONS_PER_KEY" envDefault:"100"`
+
+	// MaxSubscriptionsPerProject caps distinct active subscriptions (consumers)
+	// per project across all connections; consumer group members count once.
+	// 0 = unlimited.
+	MaxSubscriptionsPerProject int `env:"MAX_SUBSCRIPTIONS_PER_PROJECT" envDefault:"500"`
+
+	// ConsumerGroupMaxMembers caps the members of one consumer group; joins
+	// beyond it are rejected with GROUP_FULL. ConsumerGroupMemberMaxInflight
+	// caps the unacked events one member holds, so a single connection
+	// can't take a group's whole backlog. 0 = unlimited.
+	ConsumerGroupMaxMembers        int `env:"CONSUMER_GROUP_MAX_MEMBERS" envDefault:"100"`
+	ConsumerGroupMemberMaxInflight int `env:"CONSUMER_GROUP_MEMBER_MAX_INFLIGHT" envDefault:"100"`
+
+	// MetricsPort serves the Prometheus /metrics endpoint on its own port,
+	// off the public listener. Empty disables the endpoint.
+	MetricsPort string `env:"METRICS_PORT"`
+
 	// Database
 	DatabaseURL string `env:"DATABASE_URL,required"`
 
@@ -38,9 +83,60 @@ type Config struct {
 	// When false, uses legacy single-connection mode.
 	MultiAccount bool `env:"NATS_MULTI_ACCOUNT" envDefault:"false"`
 
+	// ConsumerGroupTTL is how long a consumer group with no connected members
+	// keeps its position before being deleted. 0 = never.
+	ConsumerGroupTTL time.Duration `env:"CONSUMER_GROUP_TTL" envDefault:"72h"`
+
+	// ResumeCursorTTL is how long a from "resume" cursor nobody is
+	// subscribed to keeps its position before being deleted. 0 = never.
+	ResumeCursorTTL time.Duration `env:"RESUME_CURSOR_TTL" envDefault:"1h"`
+
+	// ScheduleMaxLeadTime caps how far ahead an event can be scheduled.
+	// 0 = unlimited.
+	ScheduleMaxLeadTime time.Duration `env:"SCHEDULE_MAX_LEAD_TIME" envDefault:"8760h"`
+
+	// SchemaMaxVersions keeps only the newest N versions of each schema,
+	// pruning older ones when a version is created. Latest and pinned
+	// versions are always kept. 0 = unlimited.
+	SchemaMaxVersions int `env:"SCHEMA_MAX_VERSIONS" envDefault:"50"`
+
+	// SchemaVersionMaxAge prunes schema versions older than this, with the
+	// same exemptions. 0 = never.
+	SchemaVersionMaxAge time.Duration `env:"SCHEMA_VERSION_MAX_AGE" envDefault:"0"`
+
+	// DLQPolicies overrides, per topic pattern, what happens to events that
+	// run out of retries, e.g. "audit.>=drop,payments.*=dlq-after-1". The
+	// first matching pattern wins; other topics go to the DLQ.
+	DLQPolicies nats.DLQPolicies `env:"DLQ_POLICIES"`
+
+	// FanoutLimits caps, per topic pattern, how many receivers (webhooks
+	// plus WebSocket subscriptions) one event is delivered to, e.g.
+	// "orders.>=20,>=500". The first matching pattern wins; other topics
+	// are unlimited.
+	FanoutLimits nats.FanoutLimits `env:"FANOUT_LIMITS"`
+
+	// FanoutShed skips receivers past the fan-out limit. Unset, they are
+	// still delivered to and the overflow is only logged and audited.
+	FanoutShed bool `env:"FANOUT_SHED" envDefault:"false"`
+
+	// EmitRateLimits caps, per project and topic pattern, how fast events
+	// may be emitted: pattern=N[:B] for N events per second with bursts of
+	// B, e.g. "orders.>=50:100,>=1000". The first matching pattern wins;
+	// other topics are unlimited. Over the limit, emits get a 429.
+	EmitRateLimits nats.EmitRateLimits `env:"EMIT_RATE_LIMITS"`
+
+	// SecretsEncryptionKey (base64, 32 bytes) encrypts credentials stored
+	// at rest: secret-looking webhook header values and tenant signing
+	// secrets. Unset stores them in plaintext.
+	SecretsEncryptionKey string `env:"SECRETS_ENCRYPTION_KEY"`
+
 	// Logging
 	LogLevel  string `env:"LOG_LEVEL" envDefault:"info"`
 	LogFormat string `env:"LOG_FORMAT" envDefault:"json"`
+	// AccessLog is "off", "info" (one record per request) or "debug"
+	// (adds query, client address, user agent and redacted headers).
+	// Access records go to their own logger, tagged log=access.
+	AccessLog string `env:"ACCESS_LOG" envDefault:"info"`
 
 	// Authentication
 	// AUTH_MODE: "clerk" (default) or "local" (self-hosted, API keys only)
@@ -58,6 +154,21 @@ type Config struct {
 	InterceptorsConfigPath string `env:"INTERCEPTORS_CONFIG" envDefault:""`
 	FederationConfigPath   string `env:"FEDERATION_CONFIG" envDefault:""`
 
+	// Blob attachments (optional). BLOB_STORE: "" (disabled), "local" or "s3".
+	BlobStore       string        `env:"BLOB_STORE" envDefault:""`
+	BlobLocalDir    string        `env:"BLOB_LOCAL_DIR" envDefault:"/data/blobs"`
+	BlobPublicURL   string        `env:"BLOB_PUBLIC_URL" envDefault:""`        // base URL for local presigned links; empty = request host
+	BlobSigningKey  string        `env:"BLOB_SIGNING_KEY"`                     // HMAC key for local presigned links
+	BlobMaxSize     int64         `env:"BLOB_MAX_SIZE" envDefault:"104857600"` // 100MB
+	BlobUploadTTL   time.Duration `env:"BLOB_UPLOAD_TTL" envDefault:"15m"`
+	BlobDownloadTTL time.Duration `env:"BLOB_DOWNLOAD_TTL" envDefault:"168h"`
+
+	BlobS3Bucket          string `env:"BLOB_S3_BUCKET"`
+	BlobS3Region          string `env:"BLOB_S3_REGION" envDefault:"us-east-1"`
+	BlobS3Endpoint        string `env:"BLOB_S3_ENDPOINT"` // S3-compatible endpoint (MinIO, R2); empty = AWS
+	BlobS3AccessKeyID     string `env:"BLOB_S3_ACCESS_KEY_ID"`
+	BlobS3SecretAccessKey string `env:"BLOB_S3_SECRET_ACCESS_KEY"`
+
 	// Terminal
 	CLIBinaryPath string `env:"CLI_BINARY_PATH" envDefault:"/app/notif"`
 }
@@ -67,10 +178,31 @@ func (c *Config) IsSelfHosted() bool {
 	return c.AuthMode == AuthModeLocal
 }
 
+// SecretBox returns the encrypter for SECRETS_ENCRYPTION_KEY, or nil when
+// the key is unset.
+func (c *Config) SecretBox() (*security.SecretBox, error) {
+	if c.SecretsEncryptionKey == "" {
+		return nil, nil
+	}
+	key, err := security.ParseSecretKey(c.SecretsEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("SECRETS_ENCRYPTION_KEY: %w", err)
+	}
+	return security.NewSecretBox(key)
+}
+
 func Load() (*Config, error) {
 	cfg := &Config{}
 	if err := env.Parse(cfg); err != nil {
 		return nil, err
 	}
+	if _, err := cfg.SecretBox(); err != nil {
+		return nil, err
+	}
+	switch cfg.AccessLog {
+	case "off", "info", "debug":
+	default:
+		return nil, fmt.Errorf("ACCESS_LOG: must be off, info or debug, got %q", cfg.AccessLog)
+	}
 	return cfg, nil
 }
@@ -11,19 +11,61 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countAPIKeyEventsByProject = `-- name: CountAPIKeyEventsByProject :many
+SELECT api_key_id, COUNT(*) AS events
+FROM events
+WHERE org_id = $1 AND project_id = $2 AND created_at >= $3 AND api_key_id IS NOT NULL
+GROUP BY api_key_id
+`
+
+type CountAPIKeyEventsByProjectParams struct {
+	OrgID     string             `json:"org_id"`
+	ProjectID pgtype.Text        `json:"project_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type CountAPIKeyEventsByProjectRow struct {
+	ApiKeyID pgtype.UUID `json:"api_key_id"`
+	Events   int64       `json:"events"`
+}
+
+func (q *Queries) CountAPIKeyEventsByProject(ctx context.Context, arg CountAPIKeyEventsByProjectParams) ([]CountAPIKeyEventsByProjectRow, error) {
+	rows, err := q.db.Query(ctx, countAPIKeyEventsByProject, arg.OrgID, arg.ProjectID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountAPIKeyEventsByProjectRow{}
+	for rows.Next() {
+		var i CountAPIKeyEventsByProjectRow
+		if err := rows.Scan(&i.ApiKeyID, &i.Events); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createAPIKey = `-- name: CreateAPIKey :one
-INSERT INTO api_keys (key_hash, key_prefix, name, rate_limit_per_second, org_id, project_id)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, key_prefix, name, rate_limit_per_second, created_at, org_id, project_id
+INSERT INTO api_keys (key_hash, key_prefix, name, rate_limit_per_second, org_id, project_id, max_connections, scopes, authorized_projects, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, key_prefix, name, rate_limit_per_second, created_at, org_id, project_id, max_connections, scopes, authorized_projects, expires_at
 `
 
 type CreateAPIKeyParams struct {
-	KeyHash            string      `json:"key_hash"`
-	KeyPrefix          string      `json:"key_prefix"`
-	Name               pgtype.Text `json:"name"`
-	RateLimitPerSecond pgtype.Int4 `json:"rate_limit_per_second"`
-	OrgID              pgtype.Text `json:"org_id"`
-	ProjectID          string      `json:"project_id"`
+	KeyHash            string             `json:"key_hash"`
+	KeyPrefix          string             `json:"key_prefix"`
+	Name               pgtype.Text        `json:"name"`
+	RateLimitPerSecond pgtype.Int4        `json:"rate_limit_per_second"`
+	OrgID              pgtype.Text        `json:"org_id"`
+	ProjectID          string             `json:"project_id"`
+	MaxConnections     pgtype.Int4        `json:"max_connections"`
+	Scopes             []string           `json:"scopes"`
+	AuthorizedProjects []string           `json:"authorized_projects"`
+	ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
 }
 
 type CreateAPIKeyRow struct {
@@ -34,6 +76,10 @@ type CreateAPIKeyRow struct {
 	CreatedAt          pgtype.Timestamptz `json:"created_at"`
 	OrgID              pgtype.Text        `json:"org_id"`
 	ProjectID          string             `json:"project_id"`
+	MaxConnections     pgtype.Int4        `json:"max_connections"`
+	Scopes             []string           `json:"scopes"`
+	AuthorizedProjects []string           `json:"authorized_projects"`
+	ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
 }
 
 func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (CreateAPIKeyRow, error) {
@@ -44,6 +90,10 @@ func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (Cre
 		arg.RateLimitPerSecond,
 		arg.OrgID,
 		arg.ProjectID,
+		arg.MaxConnections,
+		arg.Scopes,
+		arg.AuthorizedProjects,
+		arg.ExpiresAt,
 	)
 	var i CreateAPIKeyRow
 	err := row.Scan(
@@ -54,12 +104,40 @@ func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (Cre
 		&i.CreatedAt,
 		&i.OrgID,
 		&i.ProjectID,
+		&i.MaxConnections,
+		&i.Scopes,
+		&i.AuthorizedProjects,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
+const expireAPIKeyByProject = `-- name: ExpireAPIKeyByProject :exec
+UPDATE api_keys SET expires_at = $4
+WHERE id = $1 AND org_id = $2 AND project_id = $3 AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > $4)
+`
+
+type ExpireAPIKeyByProjectParams struct {
+	ID        pgtype.UUID        `json:"id"`
+	OrgID     pgtype.Text        `json:"org_id"`
+	ProjectID string             `json:"project_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+// Brings a key's expiry forward to $4; a key already expiring sooner keeps its expiry
+func (q *Queries) ExpireAPIKeyByProject(ctx context.Context, arg ExpireAPIKeyByProjectParams) error {
+	_, err := q.db.Exec(ctx, expireAPIKeyByProject,
+		arg.ID,
+		arg.OrgID,
+		arg.ProjectID,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
 const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
-SELECT id, key_prefix, name, rate_limit_per_second, revoked_at, created_at, org_id, project_id
+SELECT id, key_prefix, name, rate_limit_per_second, revoked_at, created_at, org_id, project_id, max_connections, scopes, authorized_projects, expires_at
 FROM api_keys
 WHERE key_hash = $1 AND revoked_at IS NULL
 `
@@ -73,6 +151,10 @@ type GetAPIKeyByHashRow struct {
 	CreatedAt          pgtype.Timestamptz `json:"created_at"`
 	OrgID              pgtype.Text        `json:"org_id"`
 	ProjectID          string             `json:"project_id"`
+	MaxConnections     pgtype.Int4        `json:"max_connections"`
+	Scopes             []string           `json:"scopes"`
+	AuthorizedProjects []string           `json:"authorized_projects"`
+	ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
 }
 
 func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error) {
@@ -87,6 +169,10 @@ func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKe
 		&i.CreatedAt,
 		&i.OrgID,
 		&i.ProjectID,
+		&i.MaxConnections,
+		&i.Scopes,
+		&i.AuthorizedProjects,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
@@ -162,6 +248,48 @@ func (q *Queries) GetAPIKeyByIdAndOrg(ctx context.Context, arg GetAPIKeyByIdAndO
 	return i, err
 }
 
+const getAPIKeyByProject = `-- name: GetAPIKeyByProject :one
+SELECT id, key_prefix, name, rate_limit_per_second, created_at, max_connections, scopes, authorized_projects, expires_at
+FROM api_keys
+WHERE id = $1 AND org_id = $2 AND project_id = $3 AND revoked_at IS NULL
+`
+
+type GetAPIKeyByProjectParams struct {
+	ID        pgtype.UUID `json:"id"`
+	OrgID     pgtype.Text `json:"org_id"`
+	ProjectID string      `json:"project_id"`
+}
+
+type GetAPIKeyByProjectRow struct {
+	ID                 pgtype.UUID        `json:"id"`
+	KeyPrefix          string             `json:"key_prefix"`
+	Name               pgtype.Text        `json:"name"`
+	RateLimitPerSecond pgtype.Int4        `json:"rate_limit_per_second"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	MaxConnections     pgtype.Int4        `json:"max_connections"`
+	Scopes             []string           `json:"scopes"`
+	AuthorizedProjects []string           `json:"authorized_projects"`
+	ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
+}
+
+// An active key of a project, with what rotation copies to its successor
+func (q *Queries) GetAPIKeyByProject(ctx context.Context, arg GetAPIKeyByProjectParams) (GetAPIKeyByProjectRow, error) {
+	row := q.db.QueryRow(ctx, getAPIKeyByProject, arg.ID, arg.OrgID, arg.ProjectID)
+	var i GetAPIKeyByProjectRow
+	err := row.Scan(
+		&i.ID,
+		&i.KeyPrefix,
+		&i.Name,
+		&i.RateLimitPerSecond,
+		&i.CreatedAt,
+		&i.MaxConnections,
+		&i.Scopes,
+		&i.AuthorizedProjects,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
 const listAPIKeys = `-- name: ListAPIKeys :many
 SELECT id, key_prefix, name, rate_limit_per_second, created_at, last_used_at, revoked_at, org_id, project_id
 FROM api_keys
@@ -260,7 +388,7 @@ func (q *Queries) ListAPIKeysByOrg(ctx context.Context, orgID pgtype.Text) ([]Li
 }
 
 const listAPIKeysByProject = `-- name: ListAPIKeysByProject :many
-SELECT id, key_prefix, name, rate_limit_per_second, created_at, last_used_at, revoked_at, project_id
+SELECT id, key_prefix, name, rate_limit_per_second, created_at, last_used_at, revoked_at, project_id, max_connections, scopes, authorized_projects, expires_at
 FROM api_keys
 WHERE org_id = $1 AND project_id = $2
 ORDER BY created_at DESC
@@ -280,6 +408,10 @@ type ListAPIKeysByProjectRow struct {
 	LastUsedAt         pgtype.Timestamptz `json:"last_used_at"`
 	RevokedAt          pgtype.Timestamptz `json:"revoked_at"`
 	ProjectID          string             `json:"project_id"`
+	MaxConnections     pgtype.Int4        `json:"max_connections"`
+	Scopes             []string           `json:"scopes"`
+	AuthorizedProjects []string           `json:"authorized_projects"`
+	ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
 }
 
 func (q *Queries) ListAPIKeysByProject(ctx context.Context, arg ListAPIKeysByProjectParams) ([]ListAPIKeysByProjectRow, error) {
@@ -300,6 +432,10 @@ func (q *Queries) ListAPIKeysByProject(ctx context.Context, arg ListAPIKeysByPro
 			&i.LastUsedAt,
 			&i.RevokedAt,
 			&i.ProjectID,
+			&i.MaxConnections,
+			&i.Scopes,
+			&i.AuthorizedProjects,
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -41,26 +41,38 @@ func (q *Queries) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams)
 const listAuditLogs = `-- name: ListAuditLogs :many
 SELECT id, timestamp, actor, action, org_id, target, detail, ip_address
 FROM audit_log
-WHERE
-    ($1::VARCHAR IS NULL OR org_id = $1)
+WHERE org_id = $1
     AND ($2::TEXT IS NULL OR action = $2)
-    AND ($3::TIMESTAMPTZ IS NULL OR timestamp >= $3)
-ORDER BY id DESC
-LIMIT $4
+    AND ($3::TEXT IS NULL OR actor = $3)
+    AND ($4::TIMESTAMPTZ IS NULL OR timestamp >= $4)
+    AND ($5::TIMESTAMPTZ IS NULL OR timestamp < $5)
+    AND ($6::BIGINT IS NULL OR (timestamp, id) < (
+        SELECT b.timestamp, b.id FROM audit_log b
+        WHERE b.id = $6 AND b.org_id = $1
+    ))
+ORDER BY timestamp DESC, id DESC
+LIMIT $7
 `
 
 type ListAuditLogsParams struct {
 	OrgID  pgtype.Text        `json:"org_id"`
 	Action pgtype.Text        `json:"action"`
+	Actor  pgtype.Text        `json:"actor"`
 	Since  pgtype.Timestamptz `json:"since"`
+	Until  pgtype.Timestamptz `json:"until"`
+	Before pgtype.Int8        `json:"before"`
 	Limit  int32              `json:"limit"`
 }
 
+// Newest first. before is the id of the last entry on the previous page.
 func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
 	rows, err := q.db.Query(ctx, listAuditLogs,
 		arg.OrgID,
 		arg.Action,
+		arg.Actor,
 		arg.Since,
+		arg.Until,
+		arg.Before,
 		arg.Limit,
 	)
 	if err != nil {
@@ -44,20 +44,31 @@ func (q *Queries) CountDeliveriesByStatus(ctx context.Context) ([]CountDeliverie
 }
 
 const createEventDelivery = `-- name: CreateEventDelivery :one
-INSERT INTO event_deliveries (event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, delivered_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error
+INSERT INTO event_deliveries (event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, delivered_at, first_attempt_at, redeliveries)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+    -- Carry the first attempt over from earlier rows for the same receiver
+    COALESCE($9, (
+        SELECT MIN(COALESCE(prev.first_attempt_at, prev.delivered_at, prev.created_at))
+        FROM event_deliveries prev
+        WHERE prev.event_id = $1
+          AND prev.receiver_type = $2
+          AND prev.receiver_id IS NOT DISTINCT FROM $3
+          AND prev.consumer_name IS NOT DISTINCT FROM $4
+    ), $8, NOW()),
+    GREATEST($7 - 1, 0))
+RETURNING id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error, first_attempt_at, redeliveries
 `
 
 type CreateEventDeliveryParams struct {
-	EventID      string             `json:"event_id"`
-	ReceiverType string             `json:"receiver_type"`
-	ReceiverID   pgtype.UUID        `json:"receiver_id"`
-	ConsumerName pgtype.Text        `json:"consumer_name"`
-	ClientID     pgtype.Text        `json:"client_id"`
-	Status       string             `json:"status"`
-	Attempt      int32              `json:"attempt"`
-	DeliveredAt  pgtype.Timestamptz `json:"delivered_at"`
+	EventID        string             `json:"event_id"`
+	ReceiverType   string             `json:"receiver_type"`
+	ReceiverID     pgtype.UUID        `json:"receiver_id"`
+	ConsumerName   pgtype.Text        `json:"consumer_name"`
+	ClientID       pgtype.Text        `json:"client_id"`
+	Status         string             `json:"status"`
+	Attempt        int32              `json:"attempt"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	FirstAttemptAt pgtype.Timestamptz `json:"first_attempt_at"`
 }
 
 func (q *Queries) CreateEventDelivery(ctx context.Context, arg CreateEventDeliveryParams) (EventDelivery, error) {
@@ -70,6 +81,7 @@ func (q *Queries) CreateEventDelivery(ctx context.Context, arg CreateEventDelive
 		arg.Status,
 		arg.Attempt,
 		arg.DeliveredAt,
+		arg.FirstAttemptAt,
 	)
 	var i EventDelivery
 	err := row.Scan(
@@ -85,12 +97,14 @@ func (q *Queries) CreateEventDelivery(ctx context.Context, arg CreateEventDelive
 		&i.DeliveredAt,
 		&i.AckedAt,
 		&i.Error,
+		&i.FirstAttemptAt,
+		&i.Redeliveries,
 	)
 	return i, err
 }
 
 const getDeliveriesByConsumer = `-- name: GetDeliveriesByConsumer :many
-SELECT id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error FROM event_deliveries
+SELECT id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error, first_attempt_at, redeliveries FROM event_deliveries
 WHERE consumer_name = $1
 ORDER BY created_at DESC
 LIMIT $2
@@ -123,6 +137,8 @@ func (q *Queries) GetDeliveriesByConsumer(ctx context.Context, arg GetDeliveries
 			&i.DeliveredAt,
 			&i.AckedAt,
 			&i.Error,
+			&i.FirstAttemptAt,
+			&i.Redeliveries,
 		); err != nil {
 			return nil, err
 		}
@@ -135,7 +151,7 @@ func (q *Queries) GetDeliveriesByConsumer(ctx context.Context, arg GetDeliveries
 }
 
 const getDeliveriesByWebhook = `-- name: GetDeliveriesByWebhook :many
-SELECT id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error FROM event_deliveries
+SELECT id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error, first_attempt_at, redeliveries FROM event_deliveries
 WHERE receiver_type = 'webhook' AND receiver_id = $1
 ORDER BY created_at DESC
 LIMIT $2
@@ -168,6 +184,8 @@ func (q *Queries) GetDeliveriesByWebhook(ctx context.Context, arg GetDeliveriesB
 			&i.DeliveredAt,
 			&i.AckedAt,
 			&i.Error,
+			&i.FirstAttemptAt,
+			&i.Redeliveries,
 		); err != nil {
 			return nil, err
 		}
@@ -180,7 +198,7 @@ func (q *Queries) GetDeliveriesByWebhook(ctx context.Context, arg GetDeliveriesB
 }
 
 const getEventDeliveries = `-- name: GetEventDeliveries :many
-SELECT id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error FROM event_deliveries
+SELECT id, event_id, receiver_type, receiver_id, consumer_name, client_id, status, attempt, created_at, delivered_at, acked_at, error, first_attempt_at, redeliveries FROM event_deliveries
 WHERE event_id = $1
 ORDER BY created_at DESC
 `
@@ -207,6 +225,8 @@ func (q *Queries) GetEventDeliveries(ctx context.Context, eventID string) ([]Eve
 			&i.DeliveredAt,
 			&i.AckedAt,
 			&i.Error,
+			&i.FirstAttemptAt,
+			&i.Redeliveries,
 		); err != nil {
 			return nil, err
 		}
@@ -219,7 +239,7 @@ func (q *Queries) GetEventDeliveries(ctx context.Context, eventID string) ([]Eve
 }
 
 const getEventDeliveriesWithWebhookURL = `-- name: GetEventDeliveriesWithWebhookURL :many
-SELECT ed.id, ed.event_id, ed.receiver_type, ed.receiver_id, ed.consumer_name, ed.client_id, ed.status, ed.attempt, ed.created_at, ed.delivered_at, ed.acked_at, ed.error, w.url as webhook_url
+SELECT ed.id, ed.event_id, ed.receiver_type, ed.receiver_id, ed.consumer_name, ed.client_id, ed.status, ed.attempt, ed.created_at, ed.delivered_at, ed.acked_at, ed.error, ed.first_attempt_at, ed.redeliveries, w.url as webhook_url
 FROM event_deliveries ed
 LEFT JOIN webhooks w ON ed.receiver_type = 'webhook' AND ed.receiver_id = w.id
 WHERE ed.event_id = $1
@@ -233,19 +253,21 @@ type GetEventDeliveriesWithWebhookURLParams struct {
 }
 
 type GetEventDeliveriesWithWebhookURLRow struct {
-	ID           pgtype.UUID        `json:"id"`
-	EventID      string             `json:"event_id"`
-	ReceiverType string             `json:"receiver_type"`
-	ReceiverID   pgtype.UUID        `json:"receiver_id"`
-	ConsumerName pgtype.Text        `json:"consumer_name"`
-	ClientID     pgtype.Text        `json:"client_id"`
-	Status       string             `json:"status"`
-	Attempt      int32              `json:"attempt"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	DeliveredAt  pgtype.Timestamptz `json:"delivered_at"`
-	AckedAt      pgtype.Timestamptz `json:"acked_at"`
-	Error        pgtype.Text        `json:"error"`
-	WebhookUrl   pgtype.Text        `json:"webhook_url"`
+	ID             pgtype.UUID        `json:"id"`
+	EventID        string             `json:"event_id"`
+	ReceiverType   string             `json:"receiver_type"`
+	ReceiverID     pgtype.UUID        `json:"receiver_id"`
+	ConsumerName   pgtype.Text        `json:"consumer_name"`
+	ClientID       pgtype.Text        `json:"client_id"`
+	Status         string             `json:"status"`
+	Attempt        int32              `json:"attempt"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	AckedAt        pgtype.Timestamptz `json:"acked_at"`
+	Error          pgtype.Text        `json:"error"`
+	FirstAttemptAt pgtype.Timestamptz `json:"first_attempt_at"`
+	Redeliveries   int32              `json:"redeliveries"`
+	WebhookUrl     pgtype.Text        `json:"webhook_url"`
 }
 
 func (q *Queries) GetEventDeliveriesWithWebhookURL(ctx context.Context, arg GetEventDeliveriesWithWebhookURLParams) ([]GetEventDeliveriesWithWebhookURLRow, error) {
@@ -270,6 +292,8 @@ func (q *Queries) GetEventDeliveriesWithWebhookURL(ctx context.Context, arg GetE
 			&i.DeliveredAt,
 			&i.AckedAt,
 			&i.Error,
+			&i.FirstAttemptAt,
+			&i.Redeliveries,
 			&i.WebhookUrl,
 		); err != nil {
 			return nil, err
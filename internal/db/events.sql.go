@@ -11,6 +11,42 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const aggregateEvents = `-- name: AggregateEvents :one
+SELECT
+    COUNT(*) as total,
+    COUNT(CASE WHEN created_at > NOW() - INTERVAL '24 hours' THEN 1 END) as last_24h,
+    COUNT(CASE WHEN created_at > NOW() - INTERVAL '1 hour' THEN 1 END) as last_hour,
+    COALESCE(SUM(payload_size), 0)::bigint as payload_bytes
+FROM events
+WHERE org_id = $1
+  AND ($2::text IS NULL OR project_id = $2)
+`
+
+type AggregateEventsParams struct {
+	OrgID     string      `json:"org_id"`
+	ProjectID pgtype.Text `json:"project_id"`
+}
+
+type AggregateEventsRow struct {
+	Total        int64 `json:"total"`
+	Last24h      int64 `json:"last_24h"`
+	LastHour     int64 `json:"last_hour"`
+	PayloadBytes int64 `json:"payload_bytes"`
+}
+
+// Totals for an org, or one project when project_id is set
+func (q *Queries) AggregateEvents(ctx context.Context, arg AggregateEventsParams) (AggregateEventsRow, error) {
+	row := q.db.QueryRow(ctx, aggregateEvents, arg.OrgID, arg.ProjectID)
+	var i AggregateEventsRow
+	err := row.Scan(
+		&i.Total,
+		&i.Last24h,
+		&i.LastHour,
+		&i.PayloadBytes,
+	)
+	return i, err
+}
+
 const countEventsByAPIKey = `-- name: CountEventsByAPIKey :one
 SELECT COUNT(*) FROM events WHERE api_key_id = $1
 `
@@ -49,9 +85,48 @@ func (q *Queries) CountEventsByProject(ctx context.Context, arg CountEventsByPro
 	return count, err
 }
 
+const countEventsByTopic = `-- name: CountEventsByTopic :many
+SELECT topic, COUNT(*) as count
+FROM events
+WHERE org_id = $1 AND project_id = $2
+GROUP BY topic
+ORDER BY topic
+`
+
+type CountEventsByTopicParams struct {
+	OrgID     string      `json:"org_id"`
+	ProjectID pgtype.Text `json:"project_id"`
+}
+
+type CountEventsByTopicRow struct {
+	Topic string `json:"topic"`
+	Count int64  `json:"count"`
+}
+
+// Event counts per topic for a project
+func (q *Queries) CountEventsByTopic(ctx context.Context, arg CountEventsByTopicParams) ([]CountEventsByTopicRow, error) {
+	rows, err := q.db.Query(ctx, countEventsByTopic, arg.OrgID, arg.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountEventsByTopicRow{}
+	for rows.Next() {
+		var i CountEventsByTopicRow
+		if err := rows.Scan(&i.Topic, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createEvent = `-- name: CreateEvent :exec
-INSERT INTO events (id, topic, api_key_id, org_id, project_id, payload_size, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO events (id, topic, api_key_id, org_id, project_id, payload_size, created_at, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 `
 
 type CreateEventParams struct {
@@ -62,6 +137,7 @@ type CreateEventParams struct {
 	ProjectID   pgtype.Text        `json:"project_id"`
 	PayloadSize int32              `json:"payload_size"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	Data        []byte             `json:"data"`
 }
 
 func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) error {
@@ -73,6 +149,7 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) error
 		arg.ProjectID,
 		arg.PayloadSize,
 		arg.CreatedAt,
+		arg.Data,
 	)
 	return err
 }
@@ -494,3 +571,77 @@ func (q *Queries) ListEventsByTopicAndOrg(ctx context.Context, arg ListEventsByT
 	}
 	return items, nil
 }
+
+const searchEvents = `-- name: SearchEvents :many
+SELECT id, topic, org_id, project_id, created_at, data
+FROM events
+WHERE org_id = $1 AND project_id = $2
+  AND data @> $3::JSONB
+  AND ($4::TEXT IS NULL OR topic ~ $4)
+  AND ($5::TIMESTAMPTZ IS NULL OR created_at >= $5)
+  AND ($6::TIMESTAMPTZ IS NULL OR created_at < $6)
+  AND ($7::VARCHAR IS NULL OR (created_at, id) < (
+    SELECT b.created_at, b.id FROM events b
+    WHERE b.id = $7 AND b.org_id = $1 AND b.project_id = $2
+  ))
+ORDER BY created_at DESC, id DESC
+LIMIT $8
+`
+
+type SearchEventsParams struct {
+	OrgID     string             `json:"org_id"`
+	ProjectID pgtype.Text        `json:"project_id"`
+	Data      []byte             `json:"data"`
+	Topic     pgtype.Text        `json:"topic"`
+	Since     pgtype.Timestamptz `json:"since"`
+	Until     pgtype.Timestamptz `json:"until"`
+	Before    pgtype.Text        `json:"before"`
+	Limit     int32              `json:"limit"`
+}
+
+type SearchEventsRow struct {
+	ID        string             `json:"id"`
+	Topic     string             `json:"topic"`
+	OrgID     string             `json:"org_id"`
+	ProjectID pgtype.Text        `json:"project_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	Data      []byte             `json:"data"`
+}
+
+// Newest first. Matches events whose data contains the data document;
+// before is the id of the last event on the previous page.
+func (q *Queries) SearchEvents(ctx context.Context, arg SearchEventsParams) ([]SearchEventsRow, error) {
+	rows, err := q.db.Query(ctx, searchEvents,
+		arg.OrgID,
+		arg.ProjectID,
+		arg.Data,
+		arg.Topic,
+		arg.Since,
+		arg.Until,
+		arg.Before,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchEventsRow{}
+	for rows.Next() {
+		var i SearchEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Topic,
+			&i.OrgID,
+			&i.ProjectID,
+			&i.CreatedAt,
+			&i.Data,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
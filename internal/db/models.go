@@ -21,6 +21,10 @@ type ApiKey struct {
 	RevokedAt          pgtype.Timestamptz `json:"revoked_at"`
 	OrgID              pgtype.Text        `json:"org_id"`
 	ProjectID          string             `json:"project_id"`
+	MaxConnections     pgtype.Int4        `json:"max_connections"`
+	Scopes             []string           `json:"scopes"`
+	AuthorizedProjects []string           `json:"authorized_projects"`
+	ExpiresAt          pgtype.Timestamptz `json:"expires_at"`
 }
 
 type AuditLog struct {
@@ -34,6 +38,18 @@ type AuditLog struct {
 	IpAddress *netip.Addr        `json:"ip_address"`
 }
 
+type Blob struct {
+	ID          string             `json:"id"`
+	OrgID       string             `json:"org_id"`
+	ProjectID   string             `json:"project_id"`
+	ContentType string             `json:"content_type"`
+	Size        int64              `json:"size"`
+	StorageKey  string             `json:"storage_key"`
+	ApiKeyID    pgtype.UUID        `json:"api_key_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UploadedAt  pgtype.Timestamptz `json:"uploaded_at"`
+}
+
 type ConsumerGroup struct {
 	ID          pgtype.UUID        `json:"id"`
 	Name        string             `json:"name"`
@@ -42,6 +58,14 @@ type ConsumerGroup struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 }
 
+type EmitIdempotencyKey struct {
+	ProjectID string             `json:"project_id"`
+	Key       string             `json:"key"`
+	EventID   string             `json:"event_id"`
+	Topic     string             `json:"topic"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Event struct {
 	ID          string             `json:"id"`
 	Topic       string             `json:"topic"`
@@ -50,6 +74,17 @@ type Event struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	OrgID       string             `json:"org_id"`
 	ProjectID   pgtype.Text        `json:"project_id"`
+	Data        []byte             `json:"data"`
+}
+
+type EventOutbox struct {
+	ID            string             `json:"id"`
+	OrgID         string             `json:"org_id"`
+	Payload       []byte             `json:"payload"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"last_error"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
 }
 
 type Org struct {
@@ -64,18 +99,34 @@ type Org struct {
 }
 
 type EventDelivery struct {
-	ID           pgtype.UUID        `json:"id"`
-	EventID      string             `json:"event_id"`
-	ReceiverType string             `json:"receiver_type"`
-	ReceiverID   pgtype.UUID        `json:"receiver_id"`
-	ConsumerName pgtype.Text        `json:"consumer_name"`
-	ClientID     pgtype.Text        `json:"client_id"`
-	Status       string             `json:"status"`
-	Attempt      int32              `json:"attempt"`
+	ID             pgtype.UUID        `json:"id"`
+	EventID        string             `json:"event_id"`
+	ReceiverType   string             `json:"receiver_type"`
+	ReceiverID     pgtype.UUID        `json:"receiver_id"`
+	ConsumerName   pgtype.Text        `json:"consumer_name"`
+	ClientID       pgtype.Text        `json:"client_id"`
+	Status         string             `json:"status"`
+	Attempt        int32              `json:"attempt"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	AckedAt        pgtype.Timestamptz `json:"acked_at"`
+	Error          pgtype.Text        `json:"error"`
+	FirstAttemptAt pgtype.Timestamptz `json:"first_attempt_at"`
+	Redeliveries   int32              `json:"redeliveries"`
+}
+
+type Pipeline struct {
+	ID           string             `json:"id"`
+	OrgID        string             `json:"org_id"`
+	ProjectID    string             `json:"project_id"`
+	Name         string             `json:"name"`
+	TopicPattern string             `json:"topic_pattern"`
+	Redact       []string           `json:"redact"`
+	Jq           string             `json:"jq"`
+	Position     int32              `json:"position"`
+	Enabled      bool               `json:"enabled"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	DeliveredAt  pgtype.Timestamptz `json:"delivered_at"`
-	AckedAt      pgtype.Timestamptz `json:"acked_at"`
-	Error        pgtype.Text        `json:"error"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Project struct {
@@ -88,17 +139,23 @@ type Project struct {
 }
 
 type ScheduledEvent struct {
-	ID           string             `json:"id"`
-	OrgID        string             `json:"org_id"`
-	Topic        string             `json:"topic"`
-	Data         []byte             `json:"data"`
-	ScheduledFor pgtype.Timestamptz `json:"scheduled_for"`
-	Status       string             `json:"status"`
-	ApiKeyID     pgtype.UUID        `json:"api_key_id"`
-	Error        pgtype.Text        `json:"error"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	ExecutedAt   pgtype.Timestamptz `json:"executed_at"`
-	ProjectID    pgtype.Text        `json:"project_id"`
+	ID             string             `json:"id"`
+	OrgID          string             `json:"org_id"`
+	Topic          string             `json:"topic"`
+	Data           []byte             `json:"data"`
+	ScheduledFor   pgtype.Timestamptz `json:"scheduled_for"`
+	Status         string             `json:"status"`
+	ApiKeyID       pgtype.UUID        `json:"api_key_id"`
+	Error          pgtype.Text        `json:"error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ExecutedAt     pgtype.Timestamptz `json:"executed_at"`
+	ProjectID      pgtype.Text        `json:"project_id"`
+	MaxAttempts    int32              `json:"max_attempts"`
+	Attempts       int32              `json:"attempts"`
+	Cron           pgtype.Text        `json:"cron"`
+	MaxOccurrences int32              `json:"max_occurrences"`
+	Occurrences    int32              `json:"occurrences"`
+	LastRunAt      pgtype.Timestamptz `json:"last_run_at"`
 }
 
 type Schema struct {
@@ -139,19 +196,32 @@ type SchemaVersion struct {
 	IsLatest       pgtype.Bool        `json:"is_latest"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 	CreatedBy      pgtype.Text        `json:"created_by"`
+	Pinned         bool               `json:"pinned"`
 }
 
 type Webhook struct {
-	ID        pgtype.UUID        `json:"id"`
-	ApiKeyID  pgtype.UUID        `json:"api_key_id"`
-	Url       string             `json:"url"`
-	Topics    []string           `json:"topics"`
-	Secret    string             `json:"secret"`
-	Enabled   bool               `json:"enabled"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
-	OrgID     pgtype.Text        `json:"org_id"`
-	ProjectID pgtype.Text        `json:"project_id"`
+	ID                      pgtype.UUID        `json:"id"`
+	ApiKeyID                pgtype.UUID        `json:"api_key_id"`
+	Url                     string             `json:"url"`
+	Topics                  []string           `json:"topics"`
+	Secret                  string             `json:"secret"`
+	Enabled                 bool               `json:"enabled"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+	OrgID                   pgtype.Text        `json:"org_id"`
+	ProjectID               pgtype.Text        `json:"project_id"`
+	PreviousSecret          pgtype.Text        `json:"previous_secret"`
+	PreviousSecretExpiresAt pgtype.Timestamptz `json:"previous_secret_expires_at"`
+	RetryBudgetSeconds      int32              `json:"retry_budget_seconds"`
+	ContentType             string             `json:"content_type"`
+	BodyEncoding            string             `json:"body_encoding"`
+	PayloadMode             string             `json:"payload_mode"`
+	Ordered                 bool               `json:"ordered"`
+	Headers                 []byte             `json:"headers"`
+	TenantField             string             `json:"tenant_field"`
+	TenantSecrets           []byte             `json:"tenant_secrets"`
+	RetryPolicy             []byte             `json:"retry_policy"`
+	FallbackUrls            []string           `json:"fallback_urls"`
 }
 
 type WebhookDelivery struct {
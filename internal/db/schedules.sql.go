@@ -114,19 +114,22 @@ func (q *Queries) CountScheduledEventsByStatus(ctx context.Context, orgID string
 }
 
 const createScheduledEvent = `-- name: CreateScheduledEvent :one
-INSERT INTO scheduled_events (id, org_id, project_id, topic, data, scheduled_for, api_key_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id
+INSERT INTO scheduled_events (id, org_id, project_id, topic, data, scheduled_for, api_key_id, max_attempts, cron, max_occurrences)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at
 `
 
 type CreateScheduledEventParams struct {
-	ID           string             `json:"id"`
-	OrgID        string             `json:"org_id"`
-	ProjectID    pgtype.Text        `json:"project_id"`
-	Topic        string             `json:"topic"`
-	Data         []byte             `json:"data"`
-	ScheduledFor pgtype.Timestamptz `json:"scheduled_for"`
-	ApiKeyID     pgtype.UUID        `json:"api_key_id"`
+	ID             string             `json:"id"`
+	OrgID          string             `json:"org_id"`
+	ProjectID      pgtype.Text        `json:"project_id"`
+	Topic          string             `json:"topic"`
+	Data           []byte             `json:"data"`
+	ScheduledFor   pgtype.Timestamptz `json:"scheduled_for"`
+	ApiKeyID       pgtype.UUID        `json:"api_key_id"`
+	MaxAttempts    int32              `json:"max_attempts"`
+	Cron           pgtype.Text        `json:"cron"`
+	MaxOccurrences int32              `json:"max_occurrences"`
 }
 
 func (q *Queries) CreateScheduledEvent(ctx context.Context, arg CreateScheduledEventParams) (ScheduledEvent, error) {
@@ -138,6 +141,9 @@ func (q *Queries) CreateScheduledEvent(ctx context.Context, arg CreateScheduledE
 		arg.Data,
 		arg.ScheduledFor,
 		arg.ApiKeyID,
+		arg.MaxAttempts,
+		arg.Cron,
+		arg.MaxOccurrences,
 	)
 	var i ScheduledEvent
 	err := row.Scan(
@@ -152,12 +158,18 @@ func (q *Queries) CreateScheduledEvent(ctx context.Context, arg CreateScheduledE
 		&i.CreatedAt,
 		&i.ExecutedAt,
 		&i.ProjectID,
+		&i.MaxAttempts,
+		&i.Attempts,
+		&i.Cron,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.LastRunAt,
 	)
 	return i, err
 }
 
 const getPendingScheduledEvents = `-- name: GetPendingScheduledEvents :many
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events
 WHERE scheduled_for <= NOW() AND status = 'pending'
 ORDER BY scheduled_for ASC
 LIMIT $1
@@ -185,6 +197,12 @@ func (q *Queries) GetPendingScheduledEvents(ctx context.Context, limit int32) ([
 			&i.CreatedAt,
 			&i.ExecutedAt,
 			&i.ProjectID,
+			&i.MaxAttempts,
+			&i.Attempts,
+			&i.Cron,
+			&i.MaxOccurrences,
+			&i.Occurrences,
+			&i.LastRunAt,
 		); err != nil {
 			return nil, err
 		}
@@ -197,7 +215,7 @@ func (q *Queries) GetPendingScheduledEvents(ctx context.Context, limit int32) ([
 }
 
 const getScheduledEvent = `-- name: GetScheduledEvent :one
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events WHERE id = $1 AND org_id = $2
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events WHERE id = $1 AND org_id = $2
 `
 
 type GetScheduledEventParams struct {
@@ -220,12 +238,18 @@ func (q *Queries) GetScheduledEvent(ctx context.Context, arg GetScheduledEventPa
 		&i.CreatedAt,
 		&i.ExecutedAt,
 		&i.ProjectID,
+		&i.MaxAttempts,
+		&i.Attempts,
+		&i.Cron,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.LastRunAt,
 	)
 	return i, err
 }
 
 const getScheduledEventByProject = `-- name: GetScheduledEventByProject :one
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events WHERE id = $1 AND org_id = $2 AND project_id = $3
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events WHERE id = $1 AND org_id = $2 AND project_id = $3
 `
 
 type GetScheduledEventByProjectParams struct {
@@ -249,12 +273,18 @@ func (q *Queries) GetScheduledEventByProject(ctx context.Context, arg GetSchedul
 		&i.CreatedAt,
 		&i.ExecutedAt,
 		&i.ProjectID,
+		&i.MaxAttempts,
+		&i.Attempts,
+		&i.Cron,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.LastRunAt,
 	)
 	return i, err
 }
 
 const getScheduledEventForExecution = `-- name: GetScheduledEventForExecution :one
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events
 WHERE id = $1 AND org_id = $2 AND status = 'pending'
 FOR UPDATE SKIP LOCKED
 `
@@ -279,12 +309,18 @@ func (q *Queries) GetScheduledEventForExecution(ctx context.Context, arg GetSche
 		&i.CreatedAt,
 		&i.ExecutedAt,
 		&i.ProjectID,
+		&i.MaxAttempts,
+		&i.Attempts,
+		&i.Cron,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.LastRunAt,
 	)
 	return i, err
 }
 
 const listScheduledEvents = `-- name: ListScheduledEvents :many
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events
 WHERE org_id = $1
 ORDER BY scheduled_for DESC
 LIMIT $2 OFFSET $3
@@ -317,6 +353,12 @@ func (q *Queries) ListScheduledEvents(ctx context.Context, arg ListScheduledEven
 			&i.CreatedAt,
 			&i.ExecutedAt,
 			&i.ProjectID,
+			&i.MaxAttempts,
+			&i.Attempts,
+			&i.Cron,
+			&i.MaxOccurrences,
+			&i.Occurrences,
+			&i.LastRunAt,
 		); err != nil {
 			return nil, err
 		}
@@ -329,7 +371,7 @@ func (q *Queries) ListScheduledEvents(ctx context.Context, arg ListScheduledEven
 }
 
 const listScheduledEventsByProject = `-- name: ListScheduledEventsByProject :many
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events
 WHERE org_id = $1 AND project_id = $2
 ORDER BY scheduled_for DESC
 LIMIT $3 OFFSET $4
@@ -368,6 +410,12 @@ func (q *Queries) ListScheduledEventsByProject(ctx context.Context, arg ListSche
 			&i.CreatedAt,
 			&i.ExecutedAt,
 			&i.ProjectID,
+			&i.MaxAttempts,
+			&i.Attempts,
+			&i.Cron,
+			&i.MaxOccurrences,
+			&i.Occurrences,
+			&i.LastRunAt,
 		); err != nil {
 			return nil, err
 		}
@@ -380,7 +428,7 @@ func (q *Queries) ListScheduledEventsByProject(ctx context.Context, arg ListSche
 }
 
 const listScheduledEventsByProjectAndStatus = `-- name: ListScheduledEventsByProjectAndStatus :many
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events
 WHERE org_id = $1 AND project_id = $2 AND status = $3
 ORDER BY scheduled_for DESC
 LIMIT $4 OFFSET $5
@@ -421,6 +469,12 @@ func (q *Queries) ListScheduledEventsByProjectAndStatus(ctx context.Context, arg
 			&i.CreatedAt,
 			&i.ExecutedAt,
 			&i.ProjectID,
+			&i.MaxAttempts,
+			&i.Attempts,
+			&i.Cron,
+			&i.MaxOccurrences,
+			&i.Occurrences,
+			&i.LastRunAt,
 		); err != nil {
 			return nil, err
 		}
@@ -433,7 +487,7 @@ func (q *Queries) ListScheduledEventsByProjectAndStatus(ctx context.Context, arg
 }
 
 const listScheduledEventsByStatus = `-- name: ListScheduledEventsByStatus :many
-SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id FROM scheduled_events
+SELECT id, org_id, topic, data, scheduled_for, status, api_key_id, error, created_at, executed_at, project_id, max_attempts, attempts, cron, max_occurrences, occurrences, last_run_at FROM scheduled_events
 WHERE org_id = $1 AND status = $2
 ORDER BY scheduled_for DESC
 LIMIT $3 OFFSET $4
@@ -472,6 +526,12 @@ func (q *Queries) ListScheduledEventsByStatus(ctx context.Context, arg ListSched
 			&i.CreatedAt,
 			&i.ExecutedAt,
 			&i.ProjectID,
+			&i.MaxAttempts,
+			&i.Attempts,
+			&i.Cron,
+			&i.MaxOccurrences,
+			&i.Occurrences,
+			&i.LastRunAt,
 		); err != nil {
 			return nil, err
 		}
@@ -483,6 +543,41 @@ func (q *Queries) ListScheduledEventsByStatus(ctx context.Context, arg ListSched
 	return items, nil
 }
 
+const recordScheduledEventAttempt = `-- name: RecordScheduledEventAttempt :exec
+UPDATE scheduled_events
+SET status = $1::text,
+    attempts = $2,
+    scheduled_for = $3,
+    executed_at = CASE WHEN $1::text = 'completed' THEN NOW() ELSE executed_at END,
+    error = $4,
+    occurrences = $5,
+    last_run_at = $6
+WHERE id = $7 AND status = 'pending'
+`
+
+type RecordScheduledEventAttemptParams struct {
+	Status       string             `json:"status"`
+	Attempts     int32              `json:"attempts"`
+	ScheduledFor pgtype.Timestamptz `json:"scheduled_for"`
+	Error        pgtype.Text        `json:"error"`
+	Occurrences  int32              `json:"occurrences"`
+	LastRunAt    pgtype.Timestamptz `json:"last_run_at"`
+	ID           string             `json:"id"`
+}
+
+func (q *Queries) RecordScheduledEventAttempt(ctx context.Context, arg RecordScheduledEventAttemptParams) error {
+	_, err := q.db.Exec(ctx, recordScheduledEventAttempt,
+		arg.Status,
+		arg.Attempts,
+		arg.ScheduledFor,
+		arg.Error,
+		arg.Occurrences,
+		arg.LastRunAt,
+		arg.ID,
+	)
+	return err
+}
+
 const updateScheduledEventStatus = `-- name: UpdateScheduledEventStatus :exec
 UPDATE scheduled_events
 SET status = $1::text,
@@ -101,7 +101,7 @@ func (q *Queries) CreateSchemaValidation(ctx context.Context, arg CreateSchemaVa
 const createSchemaVersion = `-- name: CreateSchemaVersion :one
 INSERT INTO schema_versions (id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_by)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-RETURNING id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by
+RETURNING id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by, pinned
 `
 
 type CreateSchemaVersionParams struct {
@@ -146,6 +146,7 @@ func (q *Queries) CreateSchemaVersion(ctx context.Context, arg CreateSchemaVersi
 		&i.IsLatest,
 		&i.CreatedAt,
 		&i.CreatedBy,
+		&i.Pinned,
 	)
 	return i, err
 }
@@ -169,7 +170,7 @@ func (q *Queries) DeleteSchemaVersion(ctx context.Context, id string) error {
 }
 
 const getLatestSchemaVersion = `-- name: GetLatestSchemaVersion :one
-SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by FROM schema_versions
+SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by, pinned FROM schema_versions
 WHERE schema_id = $1 AND is_latest = true
 `
 
@@ -189,6 +190,7 @@ func (q *Queries) GetLatestSchemaVersion(ctx context.Context, schemaID string) (
 		&i.IsLatest,
 		&i.CreatedAt,
 		&i.CreatedBy,
+		&i.Pinned,
 	)
 	return i, err
 }
@@ -294,7 +296,7 @@ func (q *Queries) GetSchemaValidation(ctx context.Context, id string) (SchemaVal
 }
 
 const getSchemaVersion = `-- name: GetSchemaVersion :one
-SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by FROM schema_versions WHERE id = $1
+SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by, pinned FROM schema_versions WHERE id = $1
 `
 
 func (q *Queries) GetSchemaVersion(ctx context.Context, id string) (SchemaVersion, error) {
@@ -313,12 +315,13 @@ func (q *Queries) GetSchemaVersion(ctx context.Context, id string) (SchemaVersio
 		&i.IsLatest,
 		&i.CreatedAt,
 		&i.CreatedBy,
+		&i.Pinned,
 	)
 	return i, err
 }
 
 const getSchemaVersionByVersion = `-- name: GetSchemaVersionByVersion :one
-SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by FROM schema_versions
+SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by, pinned FROM schema_versions
 WHERE schema_id = $1 AND version = $2
 `
 
@@ -343,6 +346,7 @@ func (q *Queries) GetSchemaVersionByVersion(ctx context.Context, arg GetSchemaVe
 		&i.IsLatest,
 		&i.CreatedAt,
 		&i.CreatedBy,
+		&i.Pinned,
 	)
 	return i, err
 }
@@ -393,6 +397,53 @@ func (q *Queries) GetValidationStatsBySchema(ctx context.Context, schemaID pgtyp
 	return i, err
 }
 
+const getValidationStatsBySchemaVersion = `-- name: GetValidationStatsBySchemaVersion :many
+SELECT
+    COALESCE(sv.version, '')::text as version,
+    COUNT(*) as total,
+    COUNT(*) FILTER (WHERE v.valid = true) as valid_count,
+    COUNT(*) FILTER (WHERE v.valid = false) as invalid_count
+FROM schema_validations v
+LEFT JOIN schema_versions sv ON sv.id = v.schema_version_id
+WHERE v.schema_id = $1
+  AND v.validated_at > NOW() - INTERVAL '24 hours'
+GROUP BY sv.version
+ORDER BY MAX(v.validated_at) DESC
+`
+
+type GetValidationStatsBySchemaVersionRow struct {
+	Version      string `json:"version"`
+	Total        int64  `json:"total"`
+	ValidCount   int64  `json:"valid_count"`
+	InvalidCount int64  `json:"invalid_count"`
+}
+
+// Emit-time validation outcomes in the last 24h, per schema version
+func (q *Queries) GetValidationStatsBySchemaVersion(ctx context.Context, schemaID pgtype.Text) ([]GetValidationStatsBySchemaVersionRow, error) {
+	rows, err := q.db.Query(ctx, getValidationStatsBySchemaVersion, schemaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetValidationStatsBySchemaVersionRow
+	for rows.Next() {
+		var i GetValidationStatsBySchemaVersionRow
+		if err := rows.Scan(
+			&i.Version,
+			&i.Total,
+			&i.ValidCount,
+			&i.InvalidCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSchemaValidations = `-- name: ListSchemaValidations :many
 SELECT id, org_id, project_id, event_id, schema_id, schema_version_id, topic, valid, errors, validated_at FROM schema_validations
 WHERE project_id = $1
@@ -482,7 +533,7 @@ func (q *Queries) ListSchemaValidationsBySchema(ctx context.Context, arg ListSch
 }
 
 const listSchemaVersions = `-- name: ListSchemaVersions :many
-SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by FROM schema_versions
+SELECT id, schema_id, version, schema_json, validation_mode, on_invalid, compatibility, examples, fingerprint, is_latest, created_at, created_by, pinned FROM schema_versions
 WHERE schema_id = $1
 ORDER BY created_at DESC
 `
@@ -509,6 +560,7 @@ func (q *Queries) ListSchemaVersions(ctx context.Context, schemaID string) ([]Sc
 			&i.IsLatest,
 			&i.CreatedAt,
 			&i.CreatedBy,
+			&i.Pinned,
 		); err != nil {
 			return nil, err
 		}
@@ -613,6 +665,23 @@ func (q *Queries) SetSchemaVersionLatest(ctx context.Context, arg SetSchemaVersi
 	return err
 }
 
+const setSchemaVersionPinned = `-- name: SetSchemaVersionPinned :exec
+UPDATE schema_versions
+SET pinned = $3
+WHERE schema_id = $1 AND version = $2
+`
+
+type SetSchemaVersionPinnedParams struct {
+	SchemaID string `json:"schema_id"`
+	Version  string `json:"version"`
+	Pinned   bool   `json:"pinned"`
+}
+
+func (q *Queries) SetSchemaVersionPinned(ctx context.Context, arg SetSchemaVersionPinnedParams) error {
+	_, err := q.db.Exec(ctx, setSchemaVersionPinned, arg.SchemaID, arg.Version, arg.Pinned)
+	return err
+}
+
 const updateSchema = `-- name: UpdateSchema :one
 UPDATE schemas
 SET topic_pattern = $2, description = $3, tags = $4, updated_at = NOW()
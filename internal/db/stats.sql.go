@@ -33,6 +33,38 @@ func (q *Queries) GetAPIKeyStats(ctx context.Context, orgID pgtype.Text) (GetAPI
 	return i, err
 }
 
+const getRedeliveryStats = `-- name: GetRedeliveryStats :one
+SELECT
+    COUNT(*) as completed,
+    COUNT(*) FILTER (WHERE ed.redeliveries > 0) as redelivered,
+    COALESCE(SUM(ed.redeliveries), 0)::bigint as redeliveries
+FROM event_deliveries ed
+JOIN events e ON e.id = ed.event_id
+WHERE e.org_id = $1
+  AND ed.receiver_type = $2
+  AND ed.status IN ('acked', 'dlq', 'dropped')
+  AND ed.created_at > NOW() - INTERVAL '24 hours'
+`
+
+type GetRedeliveryStatsParams struct {
+	OrgID        string `json:"org_id"`
+	ReceiverType string `json:"receiver_type"`
+}
+
+type GetRedeliveryStatsRow struct {
+	Completed    int64 `json:"completed"`
+	Redelivered  int64 `json:"redelivered"`
+	Redeliveries int64 `json:"redeliveries"`
+}
+
+// Final outcomes in the last 24h and how many needed more than one attempt
+func (q *Queries) GetRedeliveryStats(ctx context.Context, arg GetRedeliveryStatsParams) (GetRedeliveryStatsRow, error) {
+	row := q.db.QueryRow(ctx, getRedeliveryStats, arg.OrgID, arg.ReceiverType)
+	var i GetRedeliveryStatsRow
+	err := row.Scan(&i.Completed, &i.Redelivered, &i.Redeliveries)
+	return i, err
+}
+
 const getWebhookDeliveryStats = `-- name: GetWebhookDeliveryStats :one
 SELECT
     COUNT(*) as total,
@@ -12,17 +12,27 @@ import (
 )
 
 const createWebhook = `-- name: CreateWebhook :one
-INSERT INTO webhooks (org_id, project_id, url, topics, secret)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id
+INSERT INTO webhooks (org_id, project_id, url, topics, secret, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls
 `
 
 type CreateWebhookParams struct {
-	OrgID     pgtype.Text `json:"org_id"`
-	ProjectID pgtype.Text `json:"project_id"`
-	Url       string      `json:"url"`
-	Topics    []string    `json:"topics"`
-	Secret    string      `json:"secret"`
+	OrgID              pgtype.Text `json:"org_id"`
+	ProjectID          pgtype.Text `json:"project_id"`
+	Url                string      `json:"url"`
+	Topics             []string    `json:"topics"`
+	Secret             string      `json:"secret"`
+	RetryBudgetSeconds int32       `json:"retry_budget_seconds"`
+	ContentType        string      `json:"content_type"`
+	BodyEncoding       string      `json:"body_encoding"`
+	PayloadMode        string      `json:"payload_mode"`
+	Ordered            bool        `json:"ordered"`
+	Headers            []byte      `json:"headers"`
+	TenantField        string      `json:"tenant_field"`
+	TenantSecrets      []byte      `json:"tenant_secrets"`
+	RetryPolicy        []byte      `json:"retry_policy"`
+	FallbackUrls       []string    `json:"fallback_urls"`
 }
 
 func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
@@ -32,6 +42,16 @@ func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (W
 		arg.Url,
 		arg.Topics,
 		arg.Secret,
+		arg.RetryBudgetSeconds,
+		arg.ContentType,
+		arg.BodyEncoding,
+		arg.PayloadMode,
+		arg.Ordered,
+		arg.Headers,
+		arg.TenantField,
+		arg.TenantSecrets,
+		arg.RetryPolicy,
+		arg.FallbackUrls,
 	)
 	var i Webhook
 	err := row.Scan(
@@ -45,6 +65,18 @@ func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (W
 		&i.UpdatedAt,
 		&i.OrgID,
 		&i.ProjectID,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.RetryBudgetSeconds,
+		&i.ContentType,
+		&i.BodyEncoding,
+		&i.PayloadMode,
+		&i.Ordered,
+		&i.Headers,
+		&i.TenantField,
+		&i.TenantSecrets,
+		&i.RetryPolicy,
+		&i.FallbackUrls,
 	)
 	return i, err
 }
@@ -175,7 +207,7 @@ func (q *Queries) GetDeliveriesByEventID(ctx context.Context, eventID string) ([
 }
 
 const getEnabledWebhooks = `-- name: GetEnabledWebhooks :many
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks
 WHERE enabled = true
 ORDER BY created_at
 `
@@ -200,6 +232,18 @@ func (q *Queries) GetEnabledWebhooks(ctx context.Context) ([]Webhook, error) {
 			&i.UpdatedAt,
 			&i.OrgID,
 			&i.ProjectID,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.RetryBudgetSeconds,
+			&i.ContentType,
+			&i.BodyEncoding,
+			&i.PayloadMode,
+			&i.Ordered,
+			&i.Headers,
+			&i.TenantField,
+			&i.TenantSecrets,
+			&i.RetryPolicy,
+			&i.FallbackUrls,
 		); err != nil {
 			return nil, err
 		}
@@ -212,7 +256,7 @@ func (q *Queries) GetEnabledWebhooks(ctx context.Context) ([]Webhook, error) {
 }
 
 const getEnabledWebhooksByOrg = `-- name: GetEnabledWebhooksByOrg :many
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks
 WHERE org_id = $1 AND enabled = true
 ORDER BY created_at DESC
 `
@@ -237,6 +281,18 @@ func (q *Queries) GetEnabledWebhooksByOrg(ctx context.Context, orgID pgtype.Text
 			&i.UpdatedAt,
 			&i.OrgID,
 			&i.ProjectID,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.RetryBudgetSeconds,
+			&i.ContentType,
+			&i.BodyEncoding,
+			&i.PayloadMode,
+			&i.Ordered,
+			&i.Headers,
+			&i.TenantField,
+			&i.TenantSecrets,
+			&i.RetryPolicy,
+			&i.FallbackUrls,
 		); err != nil {
 			return nil, err
 		}
@@ -249,7 +305,7 @@ func (q *Queries) GetEnabledWebhooksByOrg(ctx context.Context, orgID pgtype.Text
 }
 
 const getEnabledWebhooksByProject = `-- name: GetEnabledWebhooksByProject :many
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks
 WHERE org_id = $1 AND project_id = $2 AND enabled = true
 ORDER BY created_at DESC
 `
@@ -279,6 +335,18 @@ func (q *Queries) GetEnabledWebhooksByProject(ctx context.Context, arg GetEnable
 			&i.UpdatedAt,
 			&i.OrgID,
 			&i.ProjectID,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.RetryBudgetSeconds,
+			&i.ContentType,
+			&i.BodyEncoding,
+			&i.PayloadMode,
+			&i.Ordered,
+			&i.Headers,
+			&i.TenantField,
+			&i.TenantSecrets,
+			&i.RetryPolicy,
+			&i.FallbackUrls,
 		); err != nil {
 			return nil, err
 		}
@@ -350,7 +418,7 @@ func (q *Queries) GetPendingDeliveries(ctx context.Context, limit int32) ([]GetP
 }
 
 const getWebhook = `-- name: GetWebhook :one
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks WHERE id = $1
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks WHERE id = $1
 `
 
 func (q *Queries) GetWebhook(ctx context.Context, id pgtype.UUID) (Webhook, error) {
@@ -367,12 +435,24 @@ func (q *Queries) GetWebhook(ctx context.Context, id pgtype.UUID) (Webhook, erro
 		&i.UpdatedAt,
 		&i.OrgID,
 		&i.ProjectID,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.RetryBudgetSeconds,
+		&i.ContentType,
+		&i.BodyEncoding,
+		&i.PayloadMode,
+		&i.Ordered,
+		&i.Headers,
+		&i.TenantField,
+		&i.TenantSecrets,
+		&i.RetryPolicy,
+		&i.FallbackUrls,
 	)
 	return i, err
 }
 
 const getWebhookByIdAndOrg = `-- name: GetWebhookByIdAndOrg :one
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks WHERE id = $1 AND org_id = $2
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks WHERE id = $1 AND org_id = $2
 `
 
 type GetWebhookByIdAndOrgParams struct {
@@ -394,6 +474,18 @@ func (q *Queries) GetWebhookByIdAndOrg(ctx context.Context, arg GetWebhookByIdAn
 		&i.UpdatedAt,
 		&i.OrgID,
 		&i.ProjectID,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.RetryBudgetSeconds,
+		&i.ContentType,
+		&i.BodyEncoding,
+		&i.PayloadMode,
+		&i.Ordered,
+		&i.Headers,
+		&i.TenantField,
+		&i.TenantSecrets,
+		&i.RetryPolicy,
+		&i.FallbackUrls,
 	)
 	return i, err
 }
@@ -401,17 +493,30 @@ func (q *Queries) GetWebhookByIdAndOrg(ctx context.Context, arg GetWebhookByIdAn
 const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
 SELECT id, webhook_id, event_id, topic, status, attempt, response_status, response_body, error, created_at, delivered_at FROM webhook_deliveries
 WHERE webhook_id = $1
-ORDER BY created_at DESC
+  AND ($3::TEXT IS NULL OR status = $3)
+  AND ($4::UUID IS NULL OR (created_at, id) < (
+    SELECT b.created_at, b.id FROM webhook_deliveries b
+    WHERE b.id = $4 AND b.webhook_id = $1
+  ))
+ORDER BY created_at DESC, id DESC
 LIMIT $2
 `
 
 type GetWebhookDeliveriesParams struct {
 	WebhookID pgtype.UUID `json:"webhook_id"`
 	Limit     int32       `json:"limit"`
+	Status    pgtype.Text `json:"status"`
+	Before    pgtype.UUID `json:"before"`
 }
 
+// Newest first. before is the id of the last delivery on the previous page.
 func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
-	rows, err := q.db.Query(ctx, getWebhookDeliveries, arg.WebhookID, arg.Limit)
+	rows, err := q.db.Query(ctx, getWebhookDeliveries,
+		arg.WebhookID,
+		arg.Limit,
+		arg.Status,
+		arg.Before,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -442,8 +547,37 @@ func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDelive
 	return items, nil
 }
 
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, webhook_id, event_id, topic, status, attempt, response_status, response_body, error, created_at, delivered_at FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2
+`
+
+type GetWebhookDeliveryParams struct {
+	ID        pgtype.UUID `json:"id"`
+	WebhookID pgtype.UUID `json:"webhook_id"`
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, getWebhookDelivery, arg.ID, arg.WebhookID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.EventID,
+		&i.Topic,
+		&i.Status,
+		&i.Attempt,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.Error,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
 const getWebhooksByAPIKey = `-- name: GetWebhooksByAPIKey :many
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks
 WHERE api_key_id = $1
 ORDER BY created_at DESC
 `
@@ -468,6 +602,18 @@ func (q *Queries) GetWebhooksByAPIKey(ctx context.Context, apiKeyID pgtype.UUID)
 			&i.UpdatedAt,
 			&i.OrgID,
 			&i.ProjectID,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.RetryBudgetSeconds,
+			&i.ContentType,
+			&i.BodyEncoding,
+			&i.PayloadMode,
+			&i.Ordered,
+			&i.Headers,
+			&i.TenantField,
+			&i.TenantSecrets,
+			&i.RetryPolicy,
+			&i.FallbackUrls,
 		); err != nil {
 			return nil, err
 		}
@@ -480,7 +626,7 @@ func (q *Queries) GetWebhooksByAPIKey(ctx context.Context, apiKeyID pgtype.UUID)
 }
 
 const getWebhooksByOrg = `-- name: GetWebhooksByOrg :many
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks
 WHERE org_id = $1
 ORDER BY created_at DESC
 `
@@ -505,6 +651,18 @@ func (q *Queries) GetWebhooksByOrg(ctx context.Context, orgID pgtype.Text) ([]We
 			&i.UpdatedAt,
 			&i.OrgID,
 			&i.ProjectID,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.RetryBudgetSeconds,
+			&i.ContentType,
+			&i.BodyEncoding,
+			&i.PayloadMode,
+			&i.Ordered,
+			&i.Headers,
+			&i.TenantField,
+			&i.TenantSecrets,
+			&i.RetryPolicy,
+			&i.FallbackUrls,
 		); err != nil {
 			return nil, err
 		}
@@ -517,7 +675,7 @@ func (q *Queries) GetWebhooksByOrg(ctx context.Context, orgID pgtype.Text) ([]We
 }
 
 const getWebhooksByProject = `-- name: GetWebhooksByProject :many
-SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id FROM webhooks
+SELECT id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls FROM webhooks
 WHERE org_id = $1 AND project_id = $2
 ORDER BY created_at DESC
 `
@@ -547,6 +705,18 @@ func (q *Queries) GetWebhooksByProject(ctx context.Context, arg GetWebhooksByPro
 			&i.UpdatedAt,
 			&i.OrgID,
 			&i.ProjectID,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.RetryBudgetSeconds,
+			&i.ContentType,
+			&i.BodyEncoding,
+			&i.PayloadMode,
+			&i.Ordered,
+			&i.Headers,
+			&i.TenantField,
+			&i.TenantSecrets,
+			&i.RetryPolicy,
+			&i.FallbackUrls,
 		); err != nil {
 			return nil, err
 		}
@@ -558,18 +728,71 @@ func (q *Queries) GetWebhooksByProject(ctx context.Context, arg GetWebhooksByPro
 	return items, nil
 }
 
+const rotateWebhookSecret = `-- name: RotateWebhookSecret :one
+UPDATE webhooks
+SET previous_secret = secret, previous_secret_expires_at = $3, secret = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls
+`
+
+type RotateWebhookSecretParams struct {
+	ID                      pgtype.UUID        `json:"id"`
+	Secret                  string             `json:"secret"`
+	PreviousSecretExpiresAt pgtype.Timestamptz `json:"previous_secret_expires_at"`
+}
+
+func (q *Queries) RotateWebhookSecret(ctx context.Context, arg RotateWebhookSecretParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, rotateWebhookSecret, arg.ID, arg.Secret, arg.PreviousSecretExpiresAt)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.ApiKeyID,
+		&i.Url,
+		&i.Topics,
+		&i.Secret,
+		&i.Enabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrgID,
+		&i.ProjectID,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.RetryBudgetSeconds,
+		&i.ContentType,
+		&i.BodyEncoding,
+		&i.PayloadMode,
+		&i.Ordered,
+		&i.Headers,
+		&i.TenantField,
+		&i.TenantSecrets,
+		&i.RetryPolicy,
+		&i.FallbackUrls,
+	)
+	return i, err
+}
+
 const updateWebhook = `-- name: UpdateWebhook :one
 UPDATE webhooks
-SET url = $2, topics = $3, enabled = $4, updated_at = NOW()
+SET url = $2, topics = $3, enabled = $4, retry_budget_seconds = $5, content_type = $6, body_encoding = $7, payload_mode = $8, ordered = $9, headers = $10, tenant_field = $11, tenant_secrets = $12, retry_policy = $13, fallback_urls = $14, updated_at = NOW()
 WHERE id = $1
-RETURNING id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id
+RETURNING id, api_key_id, url, topics, secret, enabled, created_at, updated_at, org_id, project_id, previous_secret, previous_secret_expires_at, retry_budget_seconds, content_type, body_encoding, payload_mode, ordered, headers, tenant_field, tenant_secrets, retry_policy, fallback_urls
 `
 
 type UpdateWebhookParams struct {
-	ID      pgtype.UUID `json:"id"`
-	Url     string      `json:"url"`
-	Topics  []string    `json:"topics"`
-	Enabled bool        `json:"enabled"`
+	ID                 pgtype.UUID `json:"id"`
+	Url                string      `json:"url"`
+	Topics             []string    `json:"topics"`
+	Enabled            bool        `json:"enabled"`
+	RetryBudgetSeconds int32       `json:"retry_budget_seconds"`
+	ContentType        string      `json:"content_type"`
+	BodyEncoding       string      `json:"body_encoding"`
+	PayloadMode        string      `json:"payload_mode"`
+	Ordered            bool        `json:"ordered"`
+	Headers            []byte      `json:"headers"`
+	TenantField        string      `json:"tenant_field"`
+	TenantSecrets      []byte      `json:"tenant_secrets"`
+	RetryPolicy        []byte      `json:"retry_policy"`
+	FallbackUrls       []string    `json:"fallback_urls"`
 }
 
 func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error) {
@@ -578,6 +801,16 @@ func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (W
 		arg.Url,
 		arg.Topics,
 		arg.Enabled,
+		arg.RetryBudgetSeconds,
+		arg.ContentType,
+		arg.BodyEncoding,
+		arg.PayloadMode,
+		arg.Ordered,
+		arg.Headers,
+		arg.TenantField,
+		arg.TenantSecrets,
+		arg.RetryPolicy,
+		arg.FallbackUrls,
 	)
 	var i Webhook
 	err := row.Scan(
@@ -591,6 +824,18 @@ func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (W
 		&i.UpdatedAt,
 		&i.OrgID,
 		&i.ProjectID,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.RetryBudgetSeconds,
+		&i.ContentType,
+		&i.BodyEncoding,
+		&i.PayloadMode,
+		&i.Ordered,
+		&i.Headers,
+		&i.TenantField,
+		&i.TenantSecrets,
+		&i.RetryPolicy,
+		&i.FallbackUrls,
 	)
 	return i, err
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"regexp"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +22,32 @@ type APIKey struct {
 	OrgID              string
 }
 
+// API key scopes. A key with no scopes has full access to its project, as
+// keys did before scopes existed; a key with scopes may only do what they
+// grant.
+const (
+	// ScopeEmit allows publishing events (POST /emit, WebSocket emits).
+	ScopeEmit = "emit"
+	// ScopeSubscribe allows opening WebSocket subscriptions.
+	ScopeSubscribe = "subscribe"
+	// ScopeSchemasWrite allows creating, changing and deleting schemas.
+	ScopeSchemasWrite = "schemas:write"
+	// ScopeWebhooksWrite allows creating, changing and deleting webhooks.
+	ScopeWebhooksWrite = "webhooks:write"
+	// ScopeAdmin grants every other scope, and lets an API key subscribe
+	// across the projects listed in its authorized projects, not just its
+	// own.
+	ScopeAdmin = "admin"
+)
+
+// APIKeyScopes lists every valid API key scope.
+var APIKeyScopes = []string{ScopeEmit, ScopeSubscribe, ScopeSchemasWrite, ScopeWebhooksWrite, ScopeAdmin}
+
+// HasScope reports whether a key with scopes is granted scope.
+func HasScope(scopes []string, scope string) bool {
+	return len(scopes) == 0 || slices.Contains(scopes, ScopeAdmin) || slices.Contains(scopes, scope)
+}
+
 // keyRegex matches: nsh_[a-zA-Z0-9]{28} (32 chars total, like Stripe)
 var keyRegex = regexp.MustCompile(`^nsh_[a-zA-Z0-9]{28}$`)
 
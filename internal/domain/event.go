@@ -7,6 +7,19 @@ import (
 	"time"
 )
 
+// EnvelopeVersion is the current version of the event envelope delivered to
+// subscribers (WebSocket "event" frames and webhook payloads).
+//
+// v1: {envelope_version, id, topic, data, timestamp} plus delivery metadata
+// (attempt, max_attempts) on WebSocket frames.
+const EnvelopeVersion = 1
+
+// SupportedEnvelopeVersion reports whether the server can render events in
+// the given envelope version.
+func SupportedEnvelopeVersion(v int) bool {
+	return v == 1
+}
+
 type Event struct {
 	ID        string          `json:"id"`
 	Topic     string          `json:"topic"`
@@ -15,6 +28,31 @@ type Event struct {
 	OrgID     string          `json:"org_id,omitempty"`
 	ProjectID string          `json:"project_id,omitempty"`
 	Attempt   int             `json:"attempt,omitempty"`
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// Schema and SchemaVersion name the schema the event was validated
+	// against at emit time; empty when the topic has no schema.
+	Schema        string `json:"schema,omitempty"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+
+	// AckDeadline is how long subscribers have to ack the event, in place
+	// of their subscription's ack timeout; empty keeps that timeout.
+	AckDeadline string `json:"ack_deadline,omitempty"`
+
+	// Traceparent is the W3C trace context of the emit. It travels in the
+	// NATS message headers rather than the event body.
+	Traceparent string `json:"-"`
+}
+
+// Attachment references a blob uploaded out-of-band and attached to an
+// event. URL is a presigned download link valid until ExpiresAt.
+type Attachment struct {
+	ID          string    `json:"id"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	ExpiresAt   time.Time `json:"expires_at"`
 }
 
 // NewEvent creates a new event with a generated ID.
@@ -39,6 +77,37 @@ func generateEventID() string {
 type EmitRequest struct {
 	Topic string          `json:"topic"`
 	Data  json.RawMessage `json:"data"`
+	// Attachments lists blob IDs (from POST /blobs) to attach to the event.
+	Attachments []string `json:"attachments,omitempty"`
+	// IdempotencyKey deduplicates retries: an emit repeating a recent key
+	// returns the original event. POST /emit takes it from the
+	// Idempotency-Key header.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// AckDeadline, e.g. "15m", overrides the ack timeout of the
+	// subscriptions the event is delivered to.
+	AckDeadline string `json:"ack_deadline,omitempty"`
+}
+
+// EmitBatchRequest is the request body for POST /emit/batch.
+type EmitBatchRequest struct {
+	Events []EmitRequest `json:"events"`
+}
+
+// EmitBatchResult is the outcome of one event in a batch, in request
+// order: either the emitted event's ID or the reason it was rejected.
+type EmitBatchResult struct {
+	ID        string     `json:"id,omitempty"`
+	Topic     string     `json:"topic"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Code      string     `json:"code,omitempty"`
+}
+
+// EmitBatchResponse is the response body for POST /emit/batch.
+type EmitBatchResponse struct {
+	Results []EmitBatchResult `json:"results"`
+	Emitted int               `json:"emitted"`
+	Failed  int               `json:"failed"`
 }
 
 // EmitResponse is the response body for POST /emit.
@@ -46,4 +115,7 @@ type EmitResponse struct {
 	ID        string    `json:"id"`
 	Topic     string    `json:"topic"`
 	CreatedAt time.Time `json:"created_at"`
+	// Duplicate is set when the idempotency key was already used and the
+	// response describes the original event.
+	Duplicate bool `json:"duplicate,omitempty"`
 }
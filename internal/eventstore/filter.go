@@ -0,0 +1,140 @@
+package eventstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Filter matches events by their data: every predicate "data.<path>=<value>"
+// must hold. A value that parses as JSON (a number, true, false, null, a
+// "quoted string", an array or object) is compared as that; anything else
+// is a string, so data.status=error and data.status="error" are the same.
+// Arrays match when they contain the given elements, as with Postgres @>.
+type Filter struct {
+	doc map[string]any
+}
+
+// ParseFilter parses data field predicates, ANDed together.
+func ParseFilter(predicates []string) (*Filter, error) {
+	f := &Filter{doc: make(map[string]any)}
+	for _, pred := range predicates {
+		field, raw, ok := strings.Cut(pred, "=")
+		if !ok {
+			return nil, fmt.Errorf("filter %q: expected data.<field>=<value>", pred)
+		}
+		path, ok := strings.CutPrefix(strings.TrimSpace(field), "data.")
+		if !ok {
+			return nil, fmt.Errorf("filter %q: field must start with data.", pred)
+		}
+		segments := strings.Split(path, ".")
+		for _, seg := range segments {
+			if seg == "" {
+				return nil, fmt.Errorf("filter %q: empty field name", pred)
+			}
+		}
+		if err := f.set(segments, filterValue(raw)); err != nil {
+			return nil, fmt.Errorf("filter %q: %w", pred, err)
+		}
+	}
+	if len(f.doc) == 0 {
+		return nil, fmt.Errorf("filter is empty")
+	}
+	return f, nil
+}
+
+// set places value at path in the containment document. Predicates may
+// share a parent object but not a field.
+func (f *Filter) set(path []string, value any) error {
+	obj := f.doc
+	for _, seg := range path[:len(path)-1] {
+		switch next := obj[seg].(type) {
+		case nil:
+			child := make(map[string]any)
+			obj[seg] = child
+			obj = child
+		case map[string]any:
+			obj = next
+		default:
+			return fmt.Errorf("conflicts with another filter on data.%s", strings.Join(path, "."))
+		}
+	}
+	last := path[len(path)-1]
+	if _, exists := obj[last]; exists {
+		return fmt.Errorf("conflicts with another filter on data.%s", strings.Join(path, "."))
+	}
+	obj[last] = value
+	return nil
+}
+
+// filterValue decodes raw as JSON, falling back to the string itself.
+func filterValue(raw string) any {
+	raw = strings.TrimSpace(raw)
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return raw
+	}
+	return v
+}
+
+// Document returns the JSON document the event data must contain.
+func (f *Filter) Document() []byte {
+	doc, _ := json.Marshal(f.doc)
+	return doc
+}
+
+// Match reports whether data satisfies the filter.
+func (f *Filter) Match(data json.RawMessage) bool {
+	if len(data) == 0 {
+		return false
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return false
+	}
+	return contains(v, any(f.doc))
+}
+
+// contains reports whether a contains b with Postgres jsonb @> semantics:
+// objects match on a subset of keys, arrays when every element of b is
+// contained by some element of a, and scalars on equality.
+func contains(a, b any) bool {
+	switch bv := b.(type) {
+	case map[string]any:
+		av, ok := a.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, want := range bv {
+			got, ok := av[k]
+			if !ok || !contains(got, want) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		av, ok := a.([]any)
+		if !ok {
+			return false
+		}
+		for _, want := range bv {
+			found := false
+			for _, got := range av {
+				if contains(got, want) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		switch a.(type) {
+		case map[string]any, []any:
+			return false
+		}
+		return a == b
+	}
+}
@@ -0,0 +1,67 @@
+package eventstore
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseFilter_Document(t *testing.T) {
+	f, err := ParseFilter([]string{"data.status=error", "data.http.code=500", `data.http.method="GET"`, "data.retry=true"})
+	if err != nil {
+		t.Fatalf("ParseFilter: %v", err)
+	}
+	want := `{"http":{"code":500,"method":"GET"},"retry":true,"status":"error"}`
+	if got := string(f.Document()); got != want {
+		t.Errorf("Document() = %s, want %s", got, want)
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, preds := range [][]string{
+		nil,
+		{"status=error"},
+		{"data.status"},
+		{"data..status=error"},
+		{"data.=error"},
+		{"data.status=error", "data.status=ok"},
+		{"data.http=1", "data.http.code=500"},
+		{"data.http.code=500", "data.http=1"},
+	} {
+		if _, err := ParseFilter(preds); err == nil {
+			t.Errorf("ParseFilter(%q): expected an error", preds)
+		}
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	data := json.RawMessage(`{"status":"error","code":500,"tags":["db","eu"],"http":{"method":"GET","path":"/x"}}`)
+	for _, tc := range []struct {
+		preds []string
+		want  bool
+	}{
+		{[]string{"data.status=error"}, true},
+		{[]string{`data.status="error"`}, true},
+		{[]string{"data.status=ok"}, false},
+		{[]string{"data.code=500"}, true},
+		{[]string{`data.code="500"`}, false},
+		{[]string{"data.http.method=GET", "data.status=error"}, true},
+		{[]string{"data.http.method=GET", "data.status=ok"}, false},
+		{[]string{`data.tags=["eu"]`}, true},
+		{[]string{`data.tags=["us"]`}, false},
+		{[]string{"data.http=GET"}, false},
+		{[]string{"data.missing=1"}, false},
+	} {
+		f, err := ParseFilter(tc.preds)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", tc.preds, err)
+		}
+		if got := f.Match(data); got != tc.want {
+			t.Errorf("Match(%q) = %v, want %v", tc.preds, got, tc.want)
+		}
+	}
+
+	f, _ := ParseFilter([]string{"data.status=error"})
+	if f.Match(nil) {
+		t.Error("records stored without data must not match")
+	}
+}
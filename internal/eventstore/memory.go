@@ -0,0 +1,136 @@
+package eventstore
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory keeps records in process. It is meant for tests and single-node
+// development; nothing survives a restart.
+type Memory struct {
+	mu      sync.RWMutex
+	records []Record
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Append records an emitted event.
+func (m *Memory) Append(_ context.Context, rec Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, rec)
+	return nil
+}
+
+// Search returns records whose stored data matches opts.Filter, newest
+// first.
+func (m *Memory) Search(_ context.Context, opts SearchOptions) ([]Record, error) {
+	var re *regexp.Regexp
+	if opts.Topic != "" {
+		var err error
+		if re, err = regexp.Compile(topicPattern(opts.Topic)); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.RLock()
+	records := []Record{}
+	var before *Record
+	for _, rec := range m.records {
+		if rec.OrgID != opts.OrgID || rec.ProjectID != opts.ProjectID {
+			continue
+		}
+		if opts.Before != "" && rec.ID == opts.Before {
+			found := rec
+			before = &found
+		}
+		if re != nil && !re.MatchString(rec.Topic) {
+			continue
+		}
+		if !opts.From.IsZero() && rec.CreatedAt.Before(opts.From) {
+			continue
+		}
+		if !opts.To.IsZero() && !rec.CreatedAt.Before(opts.To) {
+			continue
+		}
+		if !opts.Filter.Match(rec.Data) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	m.mu.RUnlock()
+
+	newer := func(a, b Record) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	}
+	sort.Slice(records, func(i, j int) bool { return newer(records[i], records[j]) })
+	if opts.Before != "" {
+		if before == nil {
+			return []Record{}, nil
+		}
+		page := records[:0]
+		for _, rec := range records {
+			if newer(*before, rec) {
+				page = append(page, rec)
+			}
+		}
+		records = page
+	}
+	if limit := listLimit(opts.Limit); len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// Aggregate returns totals for a project, or the whole org when projectID
+// is empty.
+func (m *Memory) Aggregate(_ context.Context, orgID, projectID string) (Stats, error) {
+	now := time.Now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stats Stats
+	for _, rec := range m.records {
+		if rec.OrgID != orgID || (projectID != "" && rec.ProjectID != projectID) {
+			continue
+		}
+		stats.Total++
+		stats.PayloadBytes += int64(rec.PayloadSize)
+		if rec.CreatedAt.After(now.Add(-24 * time.Hour)) {
+			stats.Last24h++
+		}
+		if rec.CreatedAt.After(now.Add(-time.Hour)) {
+			stats.LastHour++
+		}
+	}
+	return stats, nil
+}
+
+// CountByTopic returns the project's event count per topic, sorted by
+// topic.
+func (m *Memory) CountByTopic(_ context.Context, orgID, projectID string) ([]TopicCount, error) {
+	m.mu.RLock()
+	byTopic := map[string]int64{}
+	for _, rec := range m.records {
+		if rec.OrgID == orgID && rec.ProjectID == projectID {
+			byTopic[rec.Topic]++
+		}
+	}
+	m.mu.RUnlock()
+
+	counts := make([]TopicCount, 0, len(byTopic))
+	for topic, n := range byTopic {
+		counts = append(counts, TopicCount{Topic: topic, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Topic < counts[j].Topic })
+	return counts, nil
+}
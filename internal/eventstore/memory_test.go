@@ -0,0 +1,130 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+func seedStore(t *testing.T, s Store) {
+	t.Helper()
+	now := time.Now()
+	for _, rec := range []Record{
+		{ID: "evt_1", Topic: "orders.created", OrgID: "org_a", ProjectID: "prj_a", PayloadSize: 10, CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "evt_2", Topic: "orders.eu.created", OrgID: "org_a", ProjectID: "prj_a", PayloadSize: 20, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "evt_3", Topic: "orders.shipped", OrgID: "org_a", ProjectID: "prj_a", PayloadSize: 30, CreatedAt: now.Add(-time.Minute)},
+		{ID: "evt_4", Topic: "orders.created", OrgID: "org_a", ProjectID: "prj_b", PayloadSize: 40, CreatedAt: now},
+		{ID: "evt_5", Topic: "orders.created", OrgID: "org_b", ProjectID: "prj_c", PayloadSize: 50, CreatedAt: now},
+	} {
+		if err := s.Append(context.Background(), rec); err != nil {
+			t.Fatalf("Append(%s): %v", rec.ID, err)
+		}
+	}
+}
+
+func ids(records []Record) []string {
+	out := make([]string, len(records))
+	for i, rec := range records {
+		out[i] = rec.ID
+	}
+	return out
+}
+
+func TestMemory_Aggregate(t *testing.T) {
+	s := NewMemory()
+	seedStore(t, s)
+
+	project, err := s.Aggregate(context.Background(), "org_a", "prj_a")
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if want := (Stats{Total: 3, Last24h: 2, LastHour: 1, PayloadBytes: 60}); project != want {
+		t.Errorf("project stats = %+v, want %+v", project, want)
+	}
+
+	org, err := s.Aggregate(context.Background(), "org_a", "")
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if want := (Stats{Total: 4, Last24h: 3, LastHour: 2, PayloadBytes: 100}); org != want {
+		t.Errorf("org stats = %+v, want %+v", org, want)
+	}
+}
+
+func TestTopicPattern(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		match          bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.createdx", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.eu.created", false},
+		{"orders.>", "orders.eu.created", true},
+		{"orders.>", "orders", false},
+		{"a+b.*", "a+b.c", true},
+		{"a+b.*", "aab.c", false},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(topicPattern(tt.pattern))
+		if got := re.MatchString(tt.topic); got != tt.match {
+			t.Errorf("topicPattern(%q) match %q = %v, want %v", tt.pattern, tt.topic, got, tt.match)
+		}
+	}
+}
+
+func TestMemory_Search(t *testing.T) {
+	s := NewMemory()
+	now := time.Now()
+	for i, rec := range []Record{
+		{ID: "evt_1", Topic: "jobs.run", Data: json.RawMessage(`{"status":"error"}`)},
+		{ID: "evt_2", Topic: "jobs.run", Data: json.RawMessage(`{"status":"ok"}`)},
+		{ID: "evt_3", Topic: "jobs.retry", Data: json.RawMessage(`{"status":"error"}`)},
+		{ID: "evt_4", Topic: "jobs.run"}, // stored without data
+		{ID: "evt_5", Topic: "jobs.run", Data: json.RawMessage(`{"status":"error"}`)},
+		{ID: "evt_6", Topic: "jobs.run", ProjectID: "prj_b", Data: json.RawMessage(`{"status":"error"}`)},
+	} {
+		rec.OrgID = "org_a"
+		if rec.ProjectID == "" {
+			rec.ProjectID = "prj_a"
+		}
+		rec.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := s.Append(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filter, err := ParseFilter([]string{"data.status=error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search := func(opts SearchOptions) []string {
+		t.Helper()
+		opts.OrgID, opts.ProjectID, opts.Filter = "org_a", "prj_a", filter
+		records, err := s.Search(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		return ids(records)
+	}
+
+	if got := search(SearchOptions{}); !slices.Equal(got, []string{"evt_5", "evt_3", "evt_1"}) {
+		t.Errorf("all: got %v", got)
+	}
+	if got := search(SearchOptions{Topic: "jobs.run"}); !slices.Equal(got, []string{"evt_5", "evt_1"}) {
+		t.Errorf("topic: got %v", got)
+	}
+	if got := search(SearchOptions{From: now.Add(time.Minute), To: now.Add(4 * time.Minute)}); !slices.Equal(got, []string{"evt_3"}) {
+		t.Errorf("range: got %v", got)
+	}
+
+	// Paging with the last id of each page
+	if got := search(SearchOptions{Limit: 2}); !slices.Equal(got, []string{"evt_5", "evt_3"}) {
+		t.Errorf("page 1: got %v", got)
+	}
+	if got := search(SearchOptions{Limit: 2, Before: "evt_3"}); !slices.Equal(got, []string{"evt_1"}) {
+		t.Errorf("page 2: got %v", got)
+	}
+}
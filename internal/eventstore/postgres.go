@@ -0,0 +1,132 @@
+package eventstore
+
+import (
+	"context"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Postgres stores event metadata in the events table.
+type Postgres struct {
+	queries *db.Queries
+}
+
+// NewPostgres returns the default store, backed by queries.
+func NewPostgres(queries *db.Queries) *Postgres {
+	return &Postgres{queries: queries}
+}
+
+// Append inserts the record.
+func (p *Postgres) Append(ctx context.Context, rec Record) error {
+	params := db.CreateEventParams{
+		ID:          rec.ID,
+		Topic:       rec.Topic,
+		OrgID:       rec.OrgID,
+		ProjectID:   projectText(rec.ProjectID),
+		PayloadSize: int32(rec.PayloadSize),
+		CreatedAt:   pgtype.Timestamptz{Time: rec.CreatedAt, Valid: true},
+		Data:        rec.Data,
+	}
+	if rec.APIKeyID != nil {
+		params.ApiKeyID = pgtype.UUID{Bytes: *rec.APIKeyID, Valid: true}
+	}
+	return p.queries.CreateEvent(ctx, params)
+}
+
+// Search returns records whose stored data matches opts.Filter, newest
+// first. The filter is a containment (@>) query served by the events data
+// GIN index.
+func (p *Postgres) Search(ctx context.Context, opts SearchOptions) ([]Record, error) {
+	params := db.SearchEventsParams{
+		OrgID:     opts.OrgID,
+		ProjectID: projectText(opts.ProjectID),
+		Data:      opts.Filter.Document(),
+		Limit:     int32(listLimit(opts.Limit)),
+	}
+	if opts.Topic != "" {
+		params.Topic = pgtype.Text{String: topicPattern(opts.Topic), Valid: true}
+	}
+	if !opts.From.IsZero() {
+		params.Since = pgtype.Timestamptz{Time: opts.From, Valid: true}
+	}
+	if !opts.To.IsZero() {
+		params.Until = pgtype.Timestamptz{Time: opts.To, Valid: true}
+	}
+	if opts.Before != "" {
+		params.Before = pgtype.Text{String: opts.Before, Valid: true}
+	}
+
+	rows, err := p.queries.SearchEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, len(rows))
+	for i, row := range rows {
+		records[i] = Record{
+			ID:        row.ID,
+			Topic:     row.Topic,
+			OrgID:     row.OrgID,
+			ProjectID: row.ProjectID.String,
+			CreatedAt: row.CreatedAt.Time,
+			Data:      row.Data,
+		}
+	}
+	return records, nil
+}
+
+// Aggregate returns totals for a project, or the whole org when projectID
+// is empty.
+func (p *Postgres) Aggregate(ctx context.Context, orgID, projectID string) (Stats, error) {
+	row, err := p.queries.AggregateEvents(ctx, db.AggregateEventsParams{
+		OrgID:     orgID,
+		ProjectID: projectText(projectID),
+	})
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		Total:        row.Total,
+		Last24h:      row.Last24h,
+		LastHour:     row.LastHour,
+		PayloadBytes: row.PayloadBytes,
+	}, nil
+}
+
+// CountByTopic returns the project's event count per topic, sorted by
+// topic.
+func (p *Postgres) CountByTopic(ctx context.Context, orgID, projectID string) ([]TopicCount, error) {
+	rows, err := p.queries.CountEventsByTopic(ctx, db.CountEventsByTopicParams{
+		OrgID:     orgID,
+		ProjectID: projectText(projectID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]TopicCount, len(rows))
+	for i, row := range rows {
+		counts[i] = TopicCount{Topic: row.Topic, Count: row.Count}
+	}
+	return counts, nil
+}
+
+func projectText(projectID string) pgtype.Text {
+	return pgtype.Text{String: projectID, Valid: projectID != ""}
+}
+
+func newRecord(id, topic string, apiKeyID pgtype.UUID, orgID string, projectID pgtype.Text, size int32, createdAt pgtype.Timestamptz) Record {
+	rec := Record{
+		ID:          id,
+		Topic:       topic,
+		OrgID:       orgID,
+		ProjectID:   projectID.String,
+		PayloadSize: int(size),
+		CreatedAt:   createdAt.Time,
+	}
+	if apiKeyID.Valid {
+		keyID := uuid.UUID(apiKeyID.Bytes)
+		rec.APIKeyID = &keyID
+	}
+	return rec
+}
@@ -0,0 +1,101 @@
+// Package eventstore persists event metadata (id, topic, owner, payload size)
+// alongside the stream. Postgres is the default backend; high-volume
+// deployments can plug in a columnar or object store for analytics.
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultListLimit caps Search when no limit is given.
+const DefaultListLimit = 100
+
+// Record is the persisted metadata of one emitted event.
+type Record struct {
+	ID          string
+	Topic       string
+	APIKeyID    *uuid.UUID
+	OrgID       string
+	ProjectID   string
+	PayloadSize int
+	CreatedAt   time.Time
+	// Data is the event's payload, kept for Search when it is small enough;
+	// nil otherwise.
+	Data json.RawMessage
+}
+
+// SearchOptions selects records for Search.
+type SearchOptions struct {
+	OrgID     string
+	ProjectID string
+	// Filter matches the event data; required.
+	Filter *Filter
+	// Topic filters by subject pattern (`*` one token, `>` the rest);
+	// empty searches every topic.
+	Topic string
+	// From (inclusive) and To (exclusive) bound the emit time when set.
+	From time.Time
+	To   time.Time
+	// Before resumes after the event with this id, the last of a page.
+	Before string
+	Limit  int
+}
+
+// Stats are event totals for an org or project.
+type Stats struct {
+	Total        int64
+	Last24h      int64
+	LastHour     int64
+	PayloadBytes int64
+}
+
+// TopicCount is the number of events recorded on one topic.
+type TopicCount struct {
+	Topic string
+	Count int64
+}
+
+// Store is a pluggable backend for event metadata.
+type Store interface {
+	// Append records an emitted event.
+	Append(ctx context.Context, rec Record) error
+	// Search returns records whose stored data matches opts.Filter, newest
+	// first. Records stored without data never match.
+	Search(ctx context.Context, opts SearchOptions) ([]Record, error)
+	// Aggregate returns totals for a project, or the whole org when
+	// projectID is empty.
+	Aggregate(ctx context.Context, orgID, projectID string) (Stats, error)
+	// CountByTopic returns the project's event count per topic, sorted by
+	// topic.
+	CountByTopic(ctx context.Context, orgID, projectID string) ([]TopicCount, error)
+}
+
+// topicPattern converts a subject pattern to an anchored regular
+// expression, usable by Go and by Postgres `~`.
+func topicPattern(pattern string) string {
+	tokens := strings.Split(pattern, ".")
+	for i, tok := range tokens {
+		switch tok {
+		case "*":
+			tokens[i] = `[^.]+`
+		case ">":
+			tokens[i] = `.+`
+		default:
+			tokens[i] = regexp.QuoteMeta(tok)
+		}
+	}
+	return "^" + strings.Join(tokens, `\.`) + "$"
+}
+
+func listLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultListLimit
+	}
+	return limit
+}
@@ -0,0 +1,62 @@
+package eventstore
+
+import (
+	"sort"
+	"strings"
+)
+
+// TopicNode is one segment of the topic hierarchy, e.g. "created" under
+// "orders" for orders.created.
+type TopicNode struct {
+	Segment string `json:"segment"`
+	// Topic is the full prefix ending in this segment.
+	Topic string `json:"topic"`
+	// Count is the number of events on Topic and every topic below it.
+	Count    int64        `json:"count"`
+	Children []*TopicNode `json:"children,omitempty"`
+}
+
+// TopicTree arranges per-topic counts into a tree of segments. Each node
+// counts the events on its own topic plus all its descendants; siblings are
+// sorted by segment.
+func TopicTree(counts []TopicCount) []*TopicNode {
+	root := &TopicNode{}
+	for _, tc := range counts {
+		node := root
+		for i, segment := range strings.Split(tc.Topic, ".") {
+			child := childNode(node, segment)
+			if child == nil {
+				child = &TopicNode{Segment: segment}
+				if i == 0 {
+					child.Topic = segment
+				} else {
+					child.Topic = node.Topic + "." + segment
+				}
+				node.Children = append(node.Children, child)
+			}
+			child.Count += tc.Count
+			node = child
+		}
+	}
+	sortTopicNodes(root.Children)
+	if root.Children == nil {
+		return []*TopicNode{}
+	}
+	return root.Children
+}
+
+func childNode(node *TopicNode, segment string) *TopicNode {
+	for _, child := range node.Children {
+		if child.Segment == segment {
+			return child
+		}
+	}
+	return nil
+}
+
+func sortTopicNodes(nodes []*TopicNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Segment < nodes[j].Segment })
+	for _, node := range nodes {
+		sortTopicNodes(node.Children)
+	}
+}
@@ -0,0 +1,31 @@
+package eventstore
+
+import "testing"
+
+func TestTopicTree(t *testing.T) {
+	tree := TopicTree([]TopicCount{
+		{Topic: "orders", Count: 1},
+		{Topic: "billing.invoice", Count: 4},
+		{Topic: "orders.eu.shipped", Count: 2},
+		{Topic: "orders.created", Count: 3},
+	})
+
+	if len(tree) != 2 || tree[0].Segment != "billing" || tree[1].Segment != "orders" {
+		t.Fatalf("roots = %+v, want billing and orders", tree)
+	}
+	orders := tree[1]
+	if orders.Count != 6 {
+		t.Errorf("orders count = %d, want 6 (own event plus children)", orders.Count)
+	}
+	if len(orders.Children) != 2 || orders.Children[0].Segment != "created" || orders.Children[1].Segment != "eu" {
+		t.Fatalf("orders children = %+v, want created and eu", orders.Children)
+	}
+	shipped := orders.Children[1].Children[0]
+	if shipped.Topic != "orders.eu.shipped" || shipped.Count != 2 || shipped.Children != nil {
+		t.Errorf("shipped = %+v, want leaf orders.eu.shipped with 2 events", shipped)
+	}
+
+	if tree := TopicTree(nil); tree == nil || len(tree) != 0 {
+		t.Errorf("empty tree = %v, want []", tree)
+	}
+}
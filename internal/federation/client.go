@@ -102,19 +102,27 @@ func (c *Client) readLoop(ctx context.Context, conn *websocket.Conn, topics []st
 
 // Emit sends an event via HTTP POST /api/v1/emit. Retries up to 3x.
 func (c *Client) Emit(ctx context.Context, topic string, data json.RawMessage) error {
+	_, err := c.EmitID(ctx, topic, data)
+	return err
+}
+
+// EmitID is Emit, returning the id the remote gave the event.
+func (c *Client) EmitID(ctx context.Context, topic string, data json.RawMessage) (string, error) {
 	body, _ := json.Marshal(map[string]any{"topic": topic, "data": data})
 	var lastErr error
 	for i := range 3 {
-		if i > 0 { select { case <-ctx.Done(): return ctx.Err(); case <-time.After(time.Duration(i) * time.Second): } }
+		if i > 0 { select { case <-ctx.Done(): return "", ctx.Err(); case <-time.After(time.Duration(i) * time.Second): } }
 		req, _ := http.NewRequestWithContext(ctx, "POST", c.emitURL, bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+c.apiKey)
 		resp, err := c.http.Do(req)
 		if err != nil { lastErr = err; continue }
+		var emitted struct{ ID string `json:"id"` }
+		json.NewDecoder(resp.Body).Decode(&emitted)
 		io.Copy(io.Discard, resp.Body); resp.Body.Close()
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 { return nil }
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 { return emitted.ID, nil }
 		lastErr = fmt.Errorf("emit: status %d", resp.StatusCode)
-		if resp.StatusCode >= 400 && resp.StatusCode < 500 { return lastErr } // permanent error, don't retry
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 { return "", lastErr } // permanent error, don't retry
 	}
-	return lastErr
+	return "", lastErr
 }
@@ -0,0 +1,169 @@
+package federation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDedupWindow is how long an inbound event id is remembered.
+	DefaultDedupWindow = 10 * time.Minute
+	// DefaultDedupMaxEntries bounds the memory the dedup window uses; the
+	// oldest ids are forgotten first.
+	DefaultDedupMaxEntries = 100_000
+)
+
+// dedup remembers recently ingested remote event ids, so the same event
+// arriving through overlapping bridges (or looping back) is published
+// locally once.
+type dedup struct {
+	mu         sync.Mutex
+	window     time.Duration
+	maxEntries int
+	seen       map[string]time.Time
+	order      []dedupEntry // oldest first
+}
+
+type dedupEntry struct {
+	id string
+	at time.Time
+}
+
+func newDedup(window time.Duration, maxEntries int) *dedup {
+	return &dedup{window: window, maxEntries: maxEntries, seen: make(map[string]time.Time)}
+}
+
+// claim records id and reports whether it was not seen within the window.
+// Events without an id are never treated as duplicates.
+func (d *dedup) claim(id string, now time.Time) bool {
+	if id == "" || d.window <= 0 {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.evict(now)
+	if _, ok := d.seen[id]; ok {
+		return false
+	}
+	d.seen[id] = now
+	d.order = append(d.order, dedupEntry{id, now})
+	return true
+}
+
+// release forgets id, e.g. after its local publish failed, so a later
+// delivery of the same event is not dropped.
+func (d *dedup) release(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, id)
+}
+
+func (d *dedup) evict(now time.Time) {
+	for len(d.order) > 0 {
+		e := d.order[0]
+		expired := now.Sub(e.at) > d.window
+		full := d.maxEntries > 0 && len(d.order) >= d.maxEntries
+		if !expired && !full {
+			return
+		}
+		// A released and re-claimed id has a newer entry; keep it
+		if at, ok := d.seen[e.id]; ok && at.Equal(e.at) {
+			delete(d.seen, e.id)
+		}
+		d.order = d.order[1:]
+	}
+}
+
+// echoTTL is how long a bidirectional bridge expects an event it sent out
+// to come back from the remote.
+const echoTTL = time.Minute
+
+// echoes tracks events a bidirectional bridge sent out that the remote will
+// deliver back to its subscription. They are keyed by topic and data,
+// which are known before the emit, so the inbound side can drop an echo
+// that arrives before the emit returns the remote id.
+type echoes struct {
+	mu        sync.Mutex
+	pending   map[string][]time.Time // expiry of each expected echo
+	lastSweep time.Time
+}
+
+func newEchoes() *echoes {
+	return &echoes{pending: make(map[string][]time.Time)}
+}
+
+// echoKey identifies an event by topic and data, ignoring insignificant
+// whitespace in the data.
+func echoKey(topic string, data json.RawMessage) string {
+	var buf bytes.Buffer
+	if json.Compact(&buf, data) != nil {
+		buf.Reset()
+		buf.Write(data)
+	}
+	h := sha256.New()
+	h.Write([]byte(topic))
+	h.Write([]byte{0})
+	h.Write(buf.Bytes())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// expect records one echo of key.
+func (e *echoes) expect(key string, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if now.Sub(e.lastSweep) >= echoTTL {
+		for k := range e.pending {
+			e.prune(k, now)
+		}
+		e.lastSweep = now
+	}
+	e.pending[key] = append(e.pending[key], now.Add(echoTTL))
+}
+
+// take consumes one expected echo of key and reports whether there was
+// one.
+func (e *echoes) take(key string, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.prune(key, now)
+	if len(e.pending[key]) == 0 {
+		return false
+	}
+	e.cancelLocked(key)
+	return true
+}
+
+// cancel forgets one expected echo of key, e.g. after the emit failed.
+func (e *echoes) cancel(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cancelLocked(key)
+}
+
+func (e *echoes) cancelLocked(key string) {
+	switch n := len(e.pending[key]); n {
+	case 0:
+	case 1:
+		delete(e.pending, key)
+	default:
+		e.pending[key] = e.pending[key][1:]
+	}
+}
+
+// prune drops key's expired echoes. Callers hold e.mu.
+func (e *echoes) prune(key string, now time.Time) {
+	live := e.pending[key]
+	for len(live) > 0 && !now.Before(live[0]) {
+		live = live[1:]
+	}
+	if len(live) == 0 {
+		delete(e.pending, key)
+		return
+	}
+	e.pending[key] = live
+}
@@ -5,17 +5,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
 
+	notifnats "github.com/filipexyz/notif/internal/nats"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"gopkg.in/yaml.v3"
 )
 
-type Config struct{ Bridges []BridgeConfig `yaml:"bridges"` }
+// originHeader marks events a bridge published locally with the bridge's
+// name, so the bridge doesn't send them back out.
+const originHeader = "X-Notif-Federation"
+
+// Bridge directions. A "both" bridge mirrors one topic both ways.
+const (
+	DirectionInbound  = "inbound"
+	DirectionOutbound = "outbound"
+	DirectionBoth     = "both"
+)
+
+type Config struct {
+	Bridges []BridgeConfig `yaml:"bridges"`
+
+	// DedupWindow is how long inbound event ids are remembered to drop
+	// duplicates across bridges. 0 = DefaultDedupWindow.
+	DedupWindow time.Duration `yaml:"dedup_window"`
+}
 
 type BridgeConfig struct {
 	Name         string `yaml:"name"`
@@ -25,6 +45,9 @@ type BridgeConfig struct {
 	RemoteTopic  string `yaml:"remote_topic"`
 	LocalSubject string `yaml:"local_subject"`
 	Enabled      *bool  `yaml:"enabled"` // defaults to true if nil
+	// TopicMap, if set, rewrites topics under a local prefix, keeping the
+	// structure remote_topic's wildcards match. See TopicMap.
+	TopicMap *TopicMap `yaml:"topic_map"`
 }
 
 // IsEnabled returns whether this bridge is enabled (defaults to true).
@@ -45,12 +68,110 @@ func LoadConfig(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Validate checks every bridge, enabled or not, without connecting anywhere:
+// names are present and unique, the URL is http(s), the direction is known
+// and both topics parse. Inbound bridges subscribe to remote_topic (wildcards
+// allowed) and publish to a concrete local_subject; outbound bridges consume
+// local_subject (wildcards allowed) and emit to a concrete remote_topic;
+// bridges in both directions need both concrete. A bridge with a topic_map
+// publishes to mapped topics, so its remote_topic may have wildcards either
+// way and its local_subject is a project root. It returns all problems
+// found rather than stopping at the first.
+func (c *Config) Validate() []error {
+	var errs []error
+	if c.DedupWindow < 0 {
+		errs = append(errs, fmt.Errorf("dedup_window must not be negative"))
+	}
+	seen := make(map[string]bool)
+	for i, bc := range c.Bridges {
+		label := fmt.Sprintf("bridge #%d", i+1)
+		if bc.Name != "" {
+			label = fmt.Sprintf("bridge %q", bc.Name)
+		}
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf(label+": "+format, args...))
+		}
+
+		switch {
+		case bc.Name == "":
+			fail("name is required")
+		case strings.Contains(bc.Name, ","):
+			fail("name must not contain commas")
+		case seen[bc.Name]:
+			fail("duplicate name")
+		}
+		seen[bc.Name] = true
+
+		if bc.URL == "" {
+			fail("url is required")
+		} else if u, err := url.Parse(bc.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("url %q must be an absolute http(s) URL", bc.URL)
+		}
+		if bc.APIKey == "" {
+			fail("api_key is required")
+		}
+
+		if !validDirection(bc.Direction) {
+			fail("invalid direction %q (want inbound, outbound or both)", bc.Direction)
+		}
+		if bc.TopicMap != nil {
+			if bc.RemoteTopic == "" {
+				fail("remote_topic is required")
+			}
+			if bc.LocalSubject == "" {
+				fail("local_subject is required")
+			}
+			if bc.RemoteTopic != "" && bc.LocalSubject != "" {
+				if _, err := newTopicMapper(bc.RemoteTopic, bc.LocalSubject, *bc.TopicMap); err != nil {
+					fail("%v", err)
+				}
+			}
+			continue
+		}
+		// Only the side being published to must be concrete; with an invalid
+		// direction, just check that both parse.
+		if bc.RemoteTopic == "" {
+			fail("remote_topic is required")
+		} else if err := notifnats.ValidateSubject(bc.RemoteTopic, !bc.sends()); err != nil {
+			fail("remote_topic: %v", err)
+		}
+		if bc.LocalSubject == "" {
+			fail("local_subject is required")
+		} else if err := notifnats.ValidateSubject(bc.LocalSubject, !bc.receives()); err != nil {
+			fail("local_subject: %v", err)
+		} else if !strings.HasPrefix(bc.LocalSubject, "events.") {
+			fail("local_subject %q is outside the events stream (events.>)", bc.LocalSubject)
+		}
+	}
+	return errs
+}
+
+func validDirection(direction string) bool {
+	return direction == DirectionInbound || direction == DirectionOutbound || direction == DirectionBoth
+}
+
+// receives reports whether the bridge publishes remote events locally.
+func (bc BridgeConfig) receives() bool {
+	return bc.Direction == DirectionInbound || bc.Direction == DirectionBoth
+}
+
+// sends reports whether the bridge emits local events to the remote.
+func (bc BridgeConfig) sends() bool {
+	return bc.Direction == DirectionOutbound || bc.Direction == DirectionBoth
+}
+
 type Bridge struct {
 	name, direction, remoteTopic, localSubject, streamName string
+	topics                                                 *topicMapper // nil: everything bridges to localSubject
 	client                                                 *Client
 	js                                                     jetstream.JetStream
+	dedup                                                  *dedup
 	cancel                                                 context.CancelFunc
 	wg                                                     sync.WaitGroup
+	// echoes is set on bidirectional bridges: the remote delivers every
+	// event sent out back to this bridge's subscription, possibly before
+	// the emit returns its id.
+	echoes *echoes
 }
 
 type Federation struct {
@@ -71,6 +192,12 @@ func NewFederation(cfg *Config, js jetstream.JetStream, streamName string, logge
 	if streamName == "" {
 		streamName = "NOTIF_EVENTS"
 	}
+	window := cfg.DedupWindow
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	// Shared by all inbound bridges, so overlapping ones ingest an event once
+	dd := newDedup(window, DefaultDedupMaxEntries)
 	seen := make(map[string]bool)
 	var bridges []*Bridge
 	for _, bc := range cfg.Bridges {
@@ -91,7 +218,7 @@ func NewFederation(cfg *Config, js jetstream.JetStream, streamName string, logge
 		if bc.URL == "" {
 			return nil, fmt.Errorf("bridge %q: url is required", bc.Name)
 		}
-		if bc.Direction != "inbound" && bc.Direction != "outbound" {
+		if !validDirection(bc.Direction) {
 			return nil, fmt.Errorf("bridge %q: invalid direction %q", bc.Name, bc.Direction)
 		}
 		if bc.RemoteTopic == "" {
@@ -100,11 +227,22 @@ func NewFederation(cfg *Config, js jetstream.JetStream, streamName string, logge
 		if bc.LocalSubject == "" {
 			return nil, fmt.Errorf("bridge %q: local_subject is required", bc.Name)
 		}
-		bridges = append(bridges, &Bridge{
+		b := &Bridge{
 			name: bc.Name, direction: bc.Direction,
 			remoteTopic: bc.RemoteTopic, localSubject: bc.LocalSubject, streamName: streamName,
-			client: NewClient(bc.URL, expandEnv(bc.APIKey), logger), js: js,
-		})
+			client: NewClient(bc.URL, expandEnv(bc.APIKey), logger), js: js, dedup: dd,
+		}
+		if bc.Direction == DirectionBoth {
+			b.echoes = newEchoes()
+		}
+		if bc.TopicMap != nil {
+			mapper, err := newTopicMapper(bc.RemoteTopic, bc.LocalSubject, *bc.TopicMap)
+			if err != nil {
+				return nil, fmt.Errorf("bridge %q: %w", bc.Name, err)
+			}
+			b.topics = mapper
+		}
+		bridges = append(bridges, b)
 	}
 	return &Federation{bridges: bridges, logger: logger}, nil
 }
@@ -115,13 +253,15 @@ func (f *Federation) Start(ctx context.Context) error {
 		b.cancel = cancel
 
 		var err error
-		if b.direction == "inbound" {
+		if b.direction != DirectionOutbound {
 			err = b.startInbound(bCtx, f.logger)
-		} else {
+		}
+		if err == nil && b.direction != DirectionInbound {
 			err = b.startOutbound(bCtx, f.logger)
 		}
 		if err != nil {
 			cancel()
+			b.wg.Wait()
 			// Rollback previously started bridges
 			for j := 0; j < i; j++ {
 				if f.bridges[j].cancel != nil {
@@ -154,13 +294,37 @@ func (b *Bridge) startInbound(ctx context.Context, logger *slog.Logger) error {
 	go func() {
 		defer b.wg.Done()
 		for evt := range events {
-			payload, err := json.Marshal(map[string]any{"id": evt.ID, "topic": evt.Topic, "data": evt.Data, "timestamp": evt.Timestamp})
+			// Subscribed with auto_ack, so a duplicate is already acked remotely
+			if b.echoes != nil && b.echoes.take(echoKey(evt.Topic, evt.Data), time.Now()) {
+				// Claimed too, in case the emit's claim hasn't happened yet
+				b.dedup.claim(evt.ID, time.Now())
+				logger.Debug("federation: dropping echo of outbound event", "bridge", b.name, "id", evt.ID)
+				continue
+			}
+			if !b.dedup.claim(evt.ID, time.Now()) {
+				logger.Debug("federation: dropping duplicate inbound event", "bridge", b.name, "id", evt.ID)
+				continue
+			}
+			subject, topic := b.localSubject, evt.Topic
+			if b.topics != nil {
+				var err error
+				if subject, topic, err = b.topics.toLocal(evt.Topic); err != nil {
+					logger.Warn("federation: inbound topic not mapped", "bridge", b.name, "error", err)
+					b.dedup.release(evt.ID)
+					continue
+				}
+			}
+			payload, err := json.Marshal(map[string]any{"id": evt.ID, "topic": topic, "data": evt.Data, "timestamp": evt.Timestamp})
 			if err != nil {
 				logger.Error("federation: marshal inbound event failed", "bridge", b.name, "error", err)
+				b.dedup.release(evt.ID)
 				continue
 			}
-			if _, err := b.js.Publish(ctx, b.localSubject, payload); err != nil {
-				logger.Error("federation: local publish failed", "bridge", b.name, "error", err, "subject", b.localSubject)
+			msg := &nats.Msg{Subject: subject, Data: payload, Header: nats.Header{}}
+			msg.Header.Set(originHeader, b.name)
+			if _, err := b.js.PublishMsg(ctx, msg); err != nil {
+				logger.Error("federation: local publish failed", "bridge", b.name, "error", err, "subject", subject)
+				b.dedup.release(evt.ID)
 			}
 		}
 	}()
@@ -168,9 +332,13 @@ func (b *Bridge) startInbound(ctx context.Context, logger *slog.Logger) error {
 }
 
 func (b *Bridge) startOutbound(ctx context.Context, logger *slog.Logger) error {
+	filter := b.localSubject
+	if b.topics != nil {
+		filter = b.topics.localFilter()
+	}
 	consumer, err := b.js.CreateOrUpdateConsumer(ctx, b.streamName, jetstream.ConsumerConfig{
 		Durable:       "federation-" + b.name,
-		FilterSubject: b.localSubject,
+		FilterSubject: filter,
 		DeliverPolicy: jetstream.DeliverAllPolicy,
 		AckPolicy:     jetstream.AckExplicitPolicy,
 		AckWait:       30 * time.Second,
@@ -182,15 +350,43 @@ func (b *Bridge) startOutbound(ctx context.Context, logger *slog.Logger) error {
 	go func() {
 		defer b.wg.Done()
 		cc, err := consumer.Consume(func(msg jetstream.Msg) {
+			if b.bridgedIn(msg) {
+				// This bridge brought it in from the remote; don't send it back
+				msg.Ack()
+				return
+			}
 			var evt struct{ Data json.RawMessage `json:"data"` }
 			if json.Unmarshal(msg.Data(), &evt) != nil || evt.Data == nil {
 				evt.Data = msg.Data()
 			}
-			if err := b.client.Emit(ctx, b.remoteTopic, evt.Data); err != nil {
+			remoteTopic := b.remoteTopic
+			if b.topics != nil {
+				var err error
+				if remoteTopic, err = b.topics.toRemote(msg.Subject()); err != nil {
+					logger.Error("federation: outbound subject not mapped", "bridge", b.name, "error", err)
+					msg.Term()
+					return
+				}
+			}
+			// Expected before the emit, as the echo may beat its response
+			var echo string
+			if b.echoes != nil {
+				echo = echoKey(remoteTopic, evt.Data)
+				b.echoes.expect(echo, time.Now())
+			}
+			id, err := b.client.EmitID(ctx, remoteTopic, evt.Data)
+			if err != nil {
 				logger.Error("federation: remote emit failed", "bridge", b.name, "error", err)
+				if b.echoes != nil {
+					b.echoes.cancel(echo)
+				}
 				msg.Nak()
 				return
 			}
+			if b.echoes != nil {
+				// Also drop the echo by id, should the remote rewrite its data
+				b.dedup.claim(id, time.Now())
+			}
 			msg.Ack()
 		})
 		if err != nil {
@@ -202,3 +398,9 @@ func (b *Bridge) startOutbound(ctx context.Context, logger *slog.Logger) error {
 	}()
 	return nil
 }
+
+// bridgedIn reports whether msg was published locally by this bridge.
+func (b *Bridge) bridgedIn(msg jetstream.Msg) bool {
+	h := msg.Headers()
+	return h != nil && h.Get(originHeader) == b.name
+}
@@ -3,6 +3,7 @@ package federation
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -94,6 +95,69 @@ func TestLoadConfig_FileNotFound(t *testing.T) {
 	}
 }
 
+func TestValidate_ExampleConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../federation.example.yaml")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if errs := cfg.Validate(); len(errs) != 0 {
+		t.Fatalf("expected example config to be valid, got %v", errs)
+	}
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	yaml := `bridges:
+  - name: bad
+    url: ftp://remote.notif.sh
+    api_key: nsh_abc
+    direction: sideways
+    remote_topic: "alerts.>"
+    local_subject: "events.org.default.alerts"
+  - name: bad
+    url: https://remote.notif.sh
+    api_key: nsh_abc
+    direction: inbound
+    remote_topic: "alerts..critical"
+    local_subject: "events.org.*.alerts"
+  - url: https://remote.notif.sh
+    direction: outbound
+    remote_topic: "metrics.>"
+    local_subject: "other.metrics.>"
+`
+	f, err := os.CreateTemp(t.TempDir(), "federation-*.yaml")
+	if err != nil {
+		t.Fatalf("create temp file: %v", err)
+	}
+	f.WriteString(yaml)
+	f.Close()
+
+	cfg, err := LoadConfig(f.Name())
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	got := cfg.Validate()
+	want := []string{
+		`bridge "bad": url "ftp://remote.notif.sh" must be an absolute http(s) URL`,
+		`bridge "bad": invalid direction "sideways"`,
+		`bridge "bad": duplicate name`,
+		`bridge "bad": remote_topic: subject "alerts..critical" has an empty token`,
+		`bridge "bad": local_subject: subject "events.org.*.alerts" must not contain wildcards`,
+		`bridge #3: name is required`,
+		`bridge #3: api_key is required`,
+		`bridge #3: remote_topic: subject "metrics.>" must not contain wildcards`,
+		`bridge #3: local_subject "other.metrics.>" is outside the events stream`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(got[i].Error(), w) {
+			t.Errorf("error %d = %q, want prefix %q", i, got[i], w)
+		}
+	}
+}
+
 func TestClientSubscribe(t *testing.T) {
 	eventData := json.RawMessage(`{"msg":"hello"}`)
 
@@ -464,6 +528,100 @@ func TestBridgeInbound(t *testing.T) {
 	}
 }
 
+func TestBridgeInbound_DropsDuplicates(t *testing.T) {
+	// Each connection delivers evt_dup twice, and two overlapping bridges
+	// subscribe to the same remote, so evt_dup arrives four times.
+	srv := startWSServer(t, func(conn *websocket.Conn) {
+		conn.ReadJSON(&json.RawMessage{})
+		conn.WriteJSON(map[string]any{"type": "subscribed", "topics": []string{"alerts.>"}})
+		time.Sleep(50 * time.Millisecond)
+		for _, id := range []string{"evt_dup", "evt_dup", "evt_other"} {
+			conn.WriteJSON(map[string]any{"type": "event", "id": id, "topic": "alerts.critical", "data": json.RawMessage(`{}`)})
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	_, js := startEmbeddedNATS(t)
+	const subject = "events.org_default.default.prod.alerts.critical"
+	consumer, err := js.CreateOrUpdateConsumer(context.Background(), "NOTIF_EVENTS", jetstream.ConsumerConfig{
+		FilterSubject: subject,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckPolicy:     jetstream.AckNonePolicy,
+	})
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+
+	bridge := func(name, remoteTopic string) BridgeConfig {
+		return BridgeConfig{Name: name, URL: srv.URL, APIKey: "nsh_test", Direction: "inbound", RemoteTopic: remoteTopic, LocalSubject: subject}
+	}
+	fed, err := NewFederation(&Config{Bridges: []BridgeConfig{
+		bridge("all-alerts", "alerts.>"),
+		bridge("critical-alerts", "alerts.critical"),
+	}}, js, "NOTIF_EVENTS", nil)
+	if err != nil {
+		t.Fatalf("new federation: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := fed.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer fed.Stop()
+
+	// Let every copy arrive before counting
+	time.Sleep(500 * time.Millisecond)
+	msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	counts := map[string]int{}
+	for msg := range msgs.Messages() {
+		var evt map[string]any
+		json.Unmarshal(msg.Data(), &evt)
+		counts[evt["id"].(string)]++
+	}
+	if counts["evt_dup"] != 1 || counts["evt_other"] != 1 || len(counts) != 2 {
+		t.Errorf("local publishes = %v, want each event once", counts)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	now := time.Now()
+	d := newDedup(time.Minute, 2)
+
+	if !d.claim("a", now) || d.claim("a", now.Add(time.Second)) {
+		t.Fatal("expected the first claim to win and the second to be a duplicate")
+	}
+	if !d.claim("", now) || !d.claim("", now) {
+		t.Error("events without an id must never be duplicates")
+	}
+
+	// A failed publish releases the id for the next delivery
+	d.release("a")
+	if !d.claim("a", now.Add(2*time.Second)) {
+		t.Error("released id should be claimable again")
+	}
+
+	// Outside the window an id is forgotten
+	if !d.claim("a", now.Add(2*time.Minute)) {
+		t.Error("id should be forgotten after the window")
+	}
+
+	// The oldest ids are dropped once maxEntries is reached
+	later := now.Add(3 * time.Minute)
+	d.claim("b", later)
+	d.claim("c", later)
+	if !d.claim("a", later) {
+		t.Error("oldest id should be evicted when full")
+	}
+}
+
 func TestBridgeOutbound(t *testing.T) {
 	var (
 		mu       sync.Mutex
@@ -556,3 +714,225 @@ func TestBridgeOutbound(t *testing.T) {
 		t.Errorf("expected remote topic metrics.from-staging, got %v", received[0]["topic"])
 	}
 }
+
+func TestValidate_BothDirections(t *testing.T) {
+	both := func(remoteTopic, localSubject string) *Config {
+		return &Config{Bridges: []BridgeConfig{{
+			Name: "mirror", URL: "https://remote.notif.sh", APIKey: "nsh_abc", Direction: DirectionBoth,
+			RemoteTopic: remoteTopic, LocalSubject: localSubject,
+		}}}
+	}
+	if errs := both("orders.created", "events.org_default.default.orders.created").Validate(); len(errs) != 0 {
+		t.Errorf("concrete topics: %v", errs)
+	}
+	// Each side is published to, so neither may be a wildcard
+	if errs := both("orders.>", "events.org_default.default.orders.created").Validate(); len(errs) != 1 {
+		t.Errorf("wildcard remote_topic: %v, want one error", errs)
+	}
+	if errs := both("orders.created", "events.org_default.default.orders.>").Validate(); len(errs) != 1 {
+		t.Errorf("wildcard local_subject: %v, want one error", errs)
+	}
+}
+
+// mirrorRemote mocks a remote notif that delivers every emitted event, and
+// any preset ones, to its WebSocket subscribers, as a real one would.
+type mirrorRemote struct {
+	mu     sync.Mutex
+	emits  []string // topics emitted to the remote
+	events chan map[string]any
+	// hold, when set, keeps emits from being answered until it is closed
+	hold chan struct{}
+}
+
+func startMirrorRemote(t *testing.T) (*httptest.Server, *mirrorRemote) {
+	t.Helper()
+	m := &mirrorRemote{events: make(chan map[string]any, 16)}
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/emit":
+			var req struct {
+				Topic string          `json:"topic"`
+				Data  json.RawMessage `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			m.mu.Lock()
+			m.emits = append(m.emits, req.Topic)
+			id := fmt.Sprintf("evt_remote_%d", len(m.emits))
+			m.mu.Unlock()
+			// Delivered before the emit is answered, the worst case for echoes
+			m.events <- map[string]any{"type": "event", "id": id, "topic": req.Topic, "data": req.Data}
+			if m.hold != nil {
+				<-m.hold
+			}
+			json.NewEncoder(w).Encode(map[string]string{"id": id, "topic": req.Topic})
+		case "/ws":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.ReadJSON(&json.RawMessage{})
+			conn.WriteJSON(map[string]any{"type": "subscribed"})
+			go func() {
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+			for evt := range m.events {
+				if conn.WriteJSON(evt) != nil {
+					return
+				}
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, m
+}
+
+func (m *mirrorRemote) emitted() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.emits...)
+}
+
+func TestBridgeBoth_NoEcho(t *testing.T) {
+	srv, remote := startMirrorRemote(t)
+	_, js := startEmbeddedNATS(t)
+	const subject = "events.org_default.default.orders.created"
+
+	fed, err := NewFederation(&Config{Bridges: []BridgeConfig{{
+		Name: "mirror", URL: srv.URL, APIKey: "nsh_test", Direction: DirectionBoth,
+		RemoteTopic: "orders.created", LocalSubject: subject,
+	}}}, js, "NOTIF_EVENTS", nil)
+	if err != nil {
+		t.Fatalf("new federation: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := fed.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer fed.Stop()
+
+	// An event emitted locally goes out once and isn't echoed back in
+	local, _ := json.Marshal(map[string]any{"id": "evt_local", "topic": "orders.created", "data": map[string]any{"n": 1}})
+	if _, err := js.Publish(ctx, subject, local); err != nil {
+		t.Fatalf("publish local: %v", err)
+	}
+	// An event from the remote comes in once and isn't sent back out
+	remote.events <- map[string]any{"type": "event", "id": "evt_from_remote", "topic": "orders.created", "data": json.RawMessage(`{"n":2}`)}
+
+	time.Sleep(500 * time.Millisecond)
+	if got := remote.emitted(); len(got) != 1 || got[0] != "orders.created" {
+		t.Errorf("remote emits = %v, want only the local event", got)
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, "NOTIF_EVENTS", jetstream.ConsumerConfig{
+		FilterSubject: subject,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckPolicy:     jetstream.AckNonePolicy,
+	})
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var ids []string
+	for msg := range msgs.Messages() {
+		var evt map[string]any
+		json.Unmarshal(msg.Data(), &evt)
+		ids = append(ids, evt["id"].(string))
+	}
+	if len(ids) != 2 || ids[0] != "evt_local" || ids[1] != "evt_from_remote" {
+		t.Errorf("local events = %v, want evt_local and evt_from_remote without the echo", ids)
+	}
+}
+
+func TestBridgeBoth_InboundDoesNotWaitForEmit(t *testing.T) {
+	srv, remote := startMirrorRemote(t)
+	remote.hold = make(chan struct{})
+	_, js := startEmbeddedNATS(t)
+	const subject = "events.org_default.default.orders.created"
+
+	fed, err := NewFederation(&Config{Bridges: []BridgeConfig{{
+		Name: "mirror", URL: srv.URL, APIKey: "nsh_test", Direction: DirectionBoth,
+		RemoteTopic: "orders.created", LocalSubject: subject,
+	}}}, js, "NOTIF_EVENTS", nil)
+	if err != nil {
+		t.Fatalf("new federation: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := fed.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer fed.Stop()
+	defer close(remote.hold)
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, "NOTIF_EVENTS", jetstream.ConsumerConfig{
+		FilterSubject: subject,
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		AckPolicy:     jetstream.AckNonePolicy,
+	})
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+
+	// The emit of a local event stays unanswered...
+	local, _ := json.Marshal(map[string]any{"id": "evt_local", "topic": "orders.created", "data": map[string]any{"n": 1}})
+	if _, err := js.Publish(ctx, subject, local); err != nil {
+		t.Fatalf("publish local: %v", err)
+	}
+	// ...while its echo and another remote event come in
+	remote.events <- map[string]any{"type": "event", "id": "evt_from_remote", "topic": "orders.created", "data": json.RawMessage(`{"n":2}`)}
+
+	msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(2*time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var ids []string
+	for msg := range msgs.Messages() {
+		var evt map[string]any
+		json.Unmarshal(msg.Data(), &evt)
+		ids = append(ids, evt["id"].(string))
+	}
+	if len(ids) != 2 || ids[0] != "evt_local" || ids[1] != "evt_from_remote" {
+		t.Errorf("local events = %v, want evt_local and evt_from_remote without the echo", ids)
+	}
+}
+
+func TestEchoes(t *testing.T) {
+	e := newEchoes()
+	now := time.Now()
+	key := echoKey("orders.created", json.RawMessage(`{"n": 1}`))
+	if key != echoKey("orders.created", json.RawMessage(`{"n":1}`)) {
+		t.Error("whitespace changed the key")
+	}
+	if key == echoKey("orders.updated", json.RawMessage(`{"n":1}`)) {
+		t.Error("topic not part of the key")
+	}
+
+	e.expect(key, now)
+	e.expect(key, now)
+	if !e.take(key, now) || !e.take(key, now) || e.take(key, now) {
+		t.Error("want exactly two echoes taken")
+	}
+
+	e.expect(key, now)
+	e.cancel(key)
+	if e.take(key, now) {
+		t.Error("cancelled echo taken")
+	}
+
+	e.expect(key, now)
+	if e.take(key, now.Add(echoTTL)) {
+		t.Error("expired echo taken")
+	}
+}
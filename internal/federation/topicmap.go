@@ -0,0 +1,102 @@
+package federation
+
+import (
+	"fmt"
+	"strings"
+
+	notifnats "github.com/filipexyz/notif/internal/nats"
+)
+
+// TopicMap rewrites topics between the remote and local namespaces instead
+// of bridging everything to one fixed local subject. The literal tokens of
+// remote_topic before its first wildcard are swapped for LocalPrefix, and
+// the rest of the topic is kept: with remote_topic "alerts.>" and
+// local_prefix "partner.alerts", "alerts.critical.db" lands on
+// "<local_subject>.partner.alerts.critical.db". Outbound bridges map the
+// other way. With a topic map, local_subject is the project's subject
+// root, "events.<org>.<project>".
+type TopicMap struct {
+	LocalPrefix string `yaml:"local_prefix"`
+}
+
+// topicMapper applies a bridge's TopicMap.
+type topicMapper struct {
+	remotePattern string
+	remotePrefix  []string // remote_topic's tokens before its first wildcard
+	rest          []string // remote_topic's tokens from its first wildcard
+	localRoot     string   // events.<org>.<project>
+	localPrefix   []string
+}
+
+func newTopicMapper(remoteTopic, localRoot string, tm TopicMap) (*topicMapper, error) {
+	if err := notifnats.ValidateSubject(remoteTopic, true); err != nil {
+		return nil, fmt.Errorf("remote_topic: %w", err)
+	}
+	if tm.LocalPrefix == "" {
+		return nil, fmt.Errorf("topic_map.local_prefix is required")
+	}
+	if err := notifnats.ValidateSubject(tm.LocalPrefix, false); err != nil {
+		return nil, fmt.Errorf("topic_map.local_prefix: %w", err)
+	}
+	if err := notifnats.ValidateSubject(localRoot, false); err != nil {
+		return nil, fmt.Errorf("local_subject: %w", err)
+	}
+	if root := strings.Split(localRoot, "."); len(root) != 3 || root[0] != "events" {
+		return nil, fmt.Errorf("local_subject %q must be a project's subject root (events.<org>.<project>) with a topic_map", localRoot)
+	}
+
+	tokens := strings.Split(remoteTopic, ".")
+	literal := len(tokens)
+	for i, tok := range tokens {
+		if tok == "*" || tok == ">" {
+			literal = i
+			break
+		}
+	}
+	return &topicMapper{
+		remotePattern: remoteTopic,
+		remotePrefix:  tokens[:literal],
+		rest:          tokens[literal:],
+		localRoot:     localRoot,
+		localPrefix:   strings.Split(tm.LocalPrefix, "."),
+	}, nil
+}
+
+// localFilter is the local subject pattern the remote topic maps to.
+func (m *topicMapper) localFilter() string {
+	return m.join(m.localRoot, m.localPrefix, m.rest)
+}
+
+// toLocal maps a remote topic to its local subject and topic. Only whole
+// leading tokens are replaced; later tokens equal to the prefix are kept.
+func (m *topicMapper) toLocal(remoteTopic string) (subject, topic string, err error) {
+	if !notifnats.MatchSubject(m.remotePattern, remoteTopic) {
+		return "", "", fmt.Errorf("topic %q does not match %q", remoteTopic, m.remotePattern)
+	}
+	rest := strings.Split(remoteTopic, ".")[len(m.remotePrefix):]
+	topic = m.join("", m.localPrefix, rest)
+	return m.localRoot + "." + topic, topic, nil
+}
+
+// toRemote maps a local subject back to the remote topic, the inverse of
+// toLocal.
+func (m *topicMapper) toRemote(subject string) (string, error) {
+	if !notifnats.MatchSubject(m.localFilter(), subject) {
+		return "", fmt.Errorf("subject %q does not match %q", subject, m.localFilter())
+	}
+	skip := len(strings.Split(m.localRoot, ".")) + len(m.localPrefix)
+	rest := strings.Split(subject, ".")[skip:]
+	return m.join("", m.remotePrefix, rest), nil
+}
+
+// join joins root (if any) and the token lists with dots.
+func (m *topicMapper) join(root string, parts ...[]string) string {
+	var tokens []string
+	if root != "" {
+		tokens = append(tokens, root)
+	}
+	for _, p := range parts {
+		tokens = append(tokens, p...)
+	}
+	return strings.Join(tokens, ".")
+}
@@ -0,0 +1,162 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestTopicMapper_PreservesSegments(t *testing.T) {
+	m, err := newTopicMapper("alerts.>", "events.o.p", TopicMap{LocalPrefix: "partner.alerts"})
+	if err != nil {
+		t.Fatalf("new mapper: %v", err)
+	}
+	if got := m.localFilter(); got != "events.o.p.partner.alerts.>" {
+		t.Errorf("local filter = %q", got)
+	}
+
+	tests := []struct{ remote, subject string }{
+		{"alerts.critical", "events.o.p.partner.alerts.critical"},
+		{"alerts.critical.db", "events.o.p.partner.alerts.critical.db"},
+		{"alerts.critical.db.replica.2", "events.o.p.partner.alerts.critical.db.replica.2"},
+	}
+	for _, tt := range tests {
+		subject, topic, err := m.toLocal(tt.remote)
+		if err != nil || subject != tt.subject || topic != subject[len("events.o.p."):] {
+			t.Errorf("toLocal(%q) = %q, %q, %v, want %q", tt.remote, subject, topic, err, tt.subject)
+			continue
+		}
+		if remote, err := m.toRemote(subject); err != nil || remote != tt.remote {
+			t.Errorf("toRemote(%q) = %q, %v, want %q", subject, remote, err, tt.remote)
+		}
+	}
+}
+
+func TestTopicMapper_Collisions(t *testing.T) {
+	m, err := newTopicMapper("alerts.*.>", "events.o.p", TopicMap{LocalPrefix: "alerts.partner"})
+	if err != nil {
+		t.Fatalf("new mapper: %v", err)
+	}
+
+	// Only the leading prefix is rewritten: later tokens that equal it, or
+	// equal the local prefix, are kept as they are.
+	tests := []struct{ remote, subject string }{
+		{"alerts.alerts.db", "events.o.p.alerts.partner.alerts.db"},
+		{"alerts.partner.alerts", "events.o.p.alerts.partner.partner.alerts"},
+		{"alerts.db.alerts.partner", "events.o.p.alerts.partner.db.alerts.partner"},
+	}
+	for _, tt := range tests {
+		subject, _, err := m.toLocal(tt.remote)
+		if err != nil || subject != tt.subject {
+			t.Errorf("toLocal(%q) = %q, %v, want %q", tt.remote, subject, err, tt.subject)
+			continue
+		}
+		if remote, err := m.toRemote(subject); err != nil || remote != tt.remote {
+			t.Errorf("toRemote(%q) = %q, %v, want %q", subject, remote, err, tt.remote)
+		}
+	}
+
+	// Prefixes match whole tokens, and topics must match the whole pattern
+	for _, remote := range []string{"alertsx.db.primary", "alerts.db", "metrics.alerts.db"} {
+		if subject, _, err := m.toLocal(remote); err == nil {
+			t.Errorf("toLocal(%q) = %q, want an error", remote, subject)
+		}
+	}
+	for _, subject := range []string{"events.o.p.alerts.db.primary", "events.o.p.alerts.partnerx.db.x", "events.o.q.alerts.partner.db.x"} {
+		if remote, err := m.toRemote(subject); err == nil {
+			t.Errorf("toRemote(%q) = %q, want an error", subject, remote)
+		}
+	}
+}
+
+func TestValidate_TopicMap(t *testing.T) {
+	mapped := func(direction, remoteTopic, localSubject, prefix string) *Config {
+		return &Config{Bridges: []BridgeConfig{{
+			Name: "partner", URL: "https://remote.notif.sh", APIKey: "nsh_abc", Direction: direction,
+			RemoteTopic: remoteTopic, LocalSubject: localSubject, TopicMap: &TopicMap{LocalPrefix: prefix},
+		}}}
+	}
+	// The published side is computed per event, so wildcards are fine either way
+	for _, direction := range []string{DirectionInbound, DirectionOutbound, DirectionBoth} {
+		if errs := mapped(direction, "alerts.>", "events.org_default.default", "partner.alerts").Validate(); len(errs) != 0 {
+			t.Errorf("%s: %v", direction, errs)
+		}
+	}
+	bad := []*Config{
+		mapped(DirectionInbound, "alerts.>", "events.org_default.default", ""),
+		mapped(DirectionInbound, "alerts.>", "events.org_default.default", "partner.*"),
+		mapped(DirectionInbound, "alerts.>", "events.org_default.default.partner", "alerts"),
+		mapped(DirectionInbound, "alerts..x", "events.org_default.default", "partner"),
+	}
+	for i, cfg := range bad {
+		if errs := cfg.Validate(); len(errs) != 1 {
+			t.Errorf("config %d: %v, want one error", i, errs)
+		}
+	}
+}
+
+func TestBridgeInbound_TopicMap(t *testing.T) {
+	srv := startWSServer(t, func(conn *websocket.Conn) {
+		conn.ReadJSON(&json.RawMessage{})
+		conn.WriteJSON(map[string]any{"type": "subscribed", "topics": []string{"alerts.>"}})
+		time.Sleep(50 * time.Millisecond)
+		conn.WriteJSON(map[string]any{
+			"type":  "event",
+			"id":    "evt_remote1",
+			"topic": "alerts.critical.db",
+			"data":  json.RawMessage(`{"alert":"fire"}`),
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+
+	_, js := startEmbeddedNATS(t)
+	consumer, err := js.CreateOrUpdateConsumer(context.Background(), "NOTIF_EVENTS", jetstream.ConsumerConfig{
+		FilterSubject: "events.org_default.default.>",
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		AckPolicy:     jetstream.AckNonePolicy,
+	})
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+
+	cfg := &Config{Bridges: []BridgeConfig{{
+		Name: "partner-alerts", URL: srv.URL, APIKey: "nsh_test", Direction: DirectionInbound,
+		RemoteTopic: "alerts.>", LocalSubject: "events.org_default.default",
+		TopicMap: &TopicMap{LocalPrefix: "partner.alerts"},
+	}}}
+	fed, err := NewFederation(cfg, js, "NOTIF_EVENTS", nil)
+	if err != nil {
+		t.Fatalf("new federation: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := fed.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer fed.Stop()
+
+	msgs, err := consumer.Fetch(1, jetstream.FetchMaxWait(5*time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var got int
+	for msg := range msgs.Messages() {
+		got++
+		var evt map[string]any
+		json.Unmarshal(msg.Data(), &evt)
+		if msg.Subject() != "events.org_default.default.partner.alerts.critical.db" || evt["topic"] != "partner.alerts.critical.db" {
+			t.Errorf("got %s with topic %v", msg.Subject(), evt["topic"])
+		}
+	}
+	if got != 1 {
+		t.Fatal("did not receive inbound event on local NATS")
+	}
+}
@@ -1,41 +1,86 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
+	"time"
 
+	"github.com/filipexyz/notif/internal/audit"
 	"github.com/filipexyz/notif/internal/db"
 	"github.com/filipexyz/notif/internal/domain"
 	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/websocket"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // APIKeyHandler handles API key management via Clerk-authenticated dashboard.
 type APIKeyHandler struct {
-	queries *db.Queries
+	pool     *pgxpool.Pool
+	queries  *db.Queries
+	hub      *websocket.Hub
+	auditLog *audit.Logger
 }
 
 // NewAPIKeyHandler creates a new APIKeyHandler.
-func NewAPIKeyHandler(queries *db.Queries) *APIKeyHandler {
-	return &APIKeyHandler{queries: queries}
+func NewAPIKeyHandler(pool *pgxpool.Pool) *APIKeyHandler {
+	return &APIKeyHandler{pool: pool, queries: db.New(pool)}
+}
+
+// SetHub lets Revoke disconnect the revoked key's WebSocket clients.
+func (h *APIKeyHandler) SetHub(hub *websocket.Hub) {
+	h.hub = hub
+}
+
+// SetAuditLog records key creation and rotation in the audit log.
+func (h *APIKeyHandler) SetAuditLog(auditLog *audit.Logger) {
+	h.auditLog = auditLog
 }
 
 // CreateAPIKeyRequest is the request body for creating an API key.
 type CreateAPIKeyRequest struct {
 	Name      string `json:"name"`
 	ProjectID string `json:"project_id,omitempty"` // Optional, defaults to current project
+	// MaxConnections caps concurrent WebSocket connections for this key.
+	// Omit to use the server default (WS_MAX_CONNECTIONS_PER_KEY).
+	MaxConnections *int `json:"max_connections,omitempty"`
+	// Scopes limits what the key may do (see domain.APIKeyScopes); omit
+	// them for full access to the project. AuthorizedProjects lists the
+	// projects an admin key may subscribe across, besides its own.
+	Scopes             []string `json:"scopes,omitempty"`
+	AuthorizedProjects []string `json:"authorized_projects,omitempty"`
+	// ExpiresAt makes the key stop working at that time; omit it for a key
+	// that never expires.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
-// APIKeyResponse is the response for an API key.
+// APIKeyResponse is the response for an API key. The key hash is never
+// exposed; the full key only once, on create.
 type APIKeyResponse struct {
-	ID         string  `json:"id"`
-	KeyPrefix  string  `json:"key_prefix"`
-	FullKey    string  `json:"full_key,omitempty"` // Only returned on create
-	Name       string  `json:"name,omitempty"`
-	CreatedAt  string  `json:"created_at"`
-	LastUsedAt *string `json:"last_used_at,omitempty"`
+	ID                 string       `json:"id"`
+	KeyPrefix          string       `json:"key_prefix"`
+	FullKey            string       `json:"full_key,omitempty"` // Only returned on create
+	Name               string       `json:"name,omitempty"`
+	RateLimitPerSecond int32        `json:"rate_limit_per_second,omitempty"`
+	MaxConnections     *int32       `json:"max_connections,omitempty"`
+	Scopes             []string     `json:"scopes,omitempty"`
+	AuthorizedProjects []string     `json:"authorized_projects,omitempty"`
+	CreatedAt          string       `json:"created_at"`
+	ExpiresAt          *string      `json:"expires_at,omitempty"`
+	LastUsedAt         *string      `json:"last_used_at,omitempty"`
+	RevokedAt          *string      `json:"revoked_at,omitempty"`
+	Usage              *APIKeyUsage `json:"usage,omitempty"`
+}
+
+// APIKeyUsage summarizes recent activity for an API key.
+type APIKeyUsage struct {
+	Events24h int64 `json:"events_24h"`
 }
 
 // Create creates a new API key for the authenticated organization and project.
@@ -53,6 +98,15 @@ func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var maxConns pgtype.Int4
+	if req.MaxConnections != nil {
+		if *req.MaxConnections < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "max_connections must be at least 1"})
+			return
+		}
+		maxConns = pgtype.Int4{Int32: int32(*req.MaxConnections), Valid: true}
+	}
+
 	// Use project from request or current context
 	projectID := req.ProjectID
 	if projectID == "" {
@@ -73,6 +127,21 @@ func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scopes, authorized, status, msg := h.validateScopes(r.Context(), authCtx.OrgID, middleware.GetAPIKey(r.Context()), req.Scopes, req.AuthorizedProjects)
+	if status != 0 {
+		writeJSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expires_at must be in the future"})
+			return
+		}
+		expiresAt = pgtype.Timestamptz{Time: req.ExpiresAt.UTC(), Valid: true}
+	}
+
 	// Generate key
 	fullKey, prefix, hash := domain.GenerateAPIKey()
 
@@ -84,22 +153,241 @@ func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
 		RateLimitPerSecond: pgtype.Int4{Int32: 100, Valid: true},
 		OrgID:              pgtype.Text{String: authCtx.OrgID, Valid: true},
 		ProjectID:          projectID,
+		MaxConnections:     maxConns,
+		Scopes:             scopes,
+		AuthorizedProjects: authorized,
+		ExpiresAt:          expiresAt,
 	})
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create API key"})
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, APIKeyResponse{
-		ID:        uuid.UUID(apiKey.ID.Bytes).String(),
-		KeyPrefix: apiKey.KeyPrefix,
-		FullKey:   fullKey, // Only returned once!
-		Name:      apiKey.Name.String,
-		CreatedAt: apiKey.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+	resp := createdAPIKeyResponse(apiKey, fullKey)
+	h.audit(r, authCtx, "api_key.create", resp.ID, map[string]any{
+		"key_prefix": resp.KeyPrefix,
+		"name":       resp.Name,
+		"scopes":     resp.Scopes,
+		"expires_at": resp.ExpiresAt,
 	})
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// createdAPIKeyResponse describes a new key, including its full value.
+func createdAPIKeyResponse(apiKey db.CreateAPIKeyRow, fullKey string) APIKeyResponse {
+	resp := APIKeyResponse{
+		ID:                 uuid.UUID(apiKey.ID.Bytes).String(),
+		KeyPrefix:          apiKey.KeyPrefix,
+		FullKey:            fullKey, // Only returned once!
+		Name:               apiKey.Name.String,
+		Scopes:             apiKey.Scopes,
+		AuthorizedProjects: apiKey.AuthorizedProjects,
+		CreatedAt:          apiKey.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		ExpiresAt:          formatOptionalTime(apiKey.ExpiresAt),
+	}
+	if apiKey.MaxConnections.Valid {
+		resp.MaxConnections = &apiKey.MaxConnections.Int32
+	}
+	return resp
+}
+
+func formatOptionalTime(t pgtype.Timestamptz) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.UTC().Format("2006-01-02T15:04:05Z")
+	return &s
 }
 
-// List lists all API keys for the authenticated project.
+// audit records a key management action, when an audit log is set.
+func (h *APIKeyHandler) audit(r *http.Request, authCtx *middleware.AuthContext, action, keyID string, detail map[string]any) {
+	if h.auditLog == nil {
+		return
+	}
+	ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
+	h.auditLog.Log(ctx, auditActor(authCtx), action, authCtx.OrgID, keyID, detail)
+}
+
+const (
+	defaultKeyGracePeriod = 24 * time.Hour
+	maxKeyGracePeriod     = 7 * 24 * time.Hour
+)
+
+// RotateAPIKeyRequest is the request body for rotating an API key.
+type RotateAPIKeyRequest struct {
+	GracePeriod string `json:"grace_period,omitempty"` // e.g. "24h"; "0s" expires the old key immediately
+}
+
+// RotateAPIKeyResponse describes the new key and when the old one expires.
+type RotateAPIKeyResponse struct {
+	APIKeyResponse
+	PreviousKeyID        string `json:"previous_key_id"`
+	PreviousKeyExpiresAt string `json:"previous_key_expires_at"`
+}
+
+// Rotate replaces an API key with a new key value, returned once, with the
+// same name, scopes and limits. The old key keeps working until the grace
+// period ends, then fails auth as expired. A key with an expiry passes its
+// lifetime on: the new key expires as long after its creation as the old
+// one did. Creating the new key and expiring the old one is one transaction;
+// the old key's open connections are then closed when the grace period ends.
+func (h *APIKeyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid API key ID"})
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+			return
+		}
+	}
+	grace := defaultKeyGracePeriod
+	if req.GracePeriod != "" {
+		grace, err = time.ParseDuration(req.GracePeriod)
+		if err != nil || grace < 0 || grace > maxKeyGracePeriod {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "grace_period must be a duration between 0s and 168h"})
+			return
+		}
+	}
+
+	tx, err := h.pool.Begin(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to rotate API key"})
+		return
+	}
+	defer tx.Rollback(r.Context())
+	q := h.queries.WithTx(tx)
+
+	keyID := pgtype.UUID{Bytes: id, Valid: true}
+	orgID := pgtype.Text{String: authCtx.OrgID, Valid: true}
+	old, err := q.GetAPIKeyByProject(r.Context(), db.GetAPIKeyByProjectParams{
+		ID:        keyID,
+		OrgID:     orgID,
+		ProjectID: authCtx.ProjectID,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "API key not found"})
+		return
+	}
+
+	now := time.Now().UTC()
+	var expiresAt pgtype.Timestamptz
+	if old.ExpiresAt.Valid {
+		expiresAt = pgtype.Timestamptz{Time: now.Add(old.ExpiresAt.Time.Sub(old.CreatedAt.Time)), Valid: true}
+	}
+
+	fullKey, prefix, hash := domain.GenerateAPIKey()
+	apiKey, err := q.CreateAPIKey(r.Context(), db.CreateAPIKeyParams{
+		KeyHash:            hash,
+		KeyPrefix:          prefix,
+		Name:               old.Name,
+		RateLimitPerSecond: old.RateLimitPerSecond,
+		OrgID:              orgID,
+		ProjectID:          authCtx.ProjectID,
+		MaxConnections:     old.MaxConnections,
+		Scopes:             old.Scopes,
+		AuthorizedProjects: old.AuthorizedProjects,
+		ExpiresAt:          expiresAt,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to rotate API key"})
+		return
+	}
+
+	// Auth rejects the old key as expired from then on
+	previousExpiresAt := now.Add(grace)
+	if old.ExpiresAt.Valid && old.ExpiresAt.Time.Before(previousExpiresAt) {
+		previousExpiresAt = old.ExpiresAt.Time.UTC()
+	}
+	if err := q.ExpireAPIKeyByProject(r.Context(), db.ExpireAPIKeyByProjectParams{
+		ID:        keyID,
+		OrgID:     orgID,
+		ProjectID: authCtx.ProjectID,
+		ExpiresAt: pgtype.Timestamptz{Time: previousExpiresAt, Valid: true},
+	}); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to rotate API key"})
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to rotate API key"})
+		return
+	}
+	// Connections opened with the old key carry the expiry they had then
+	if h.hub != nil {
+		if n := h.hub.ExpireKey(id.String(), previousExpiresAt); n > 0 {
+			slog.Info("moved rotated API key's clients to its new expiry", "api_key_id", id.String(), "clients", n, "expires_at", previousExpiresAt)
+		}
+	}
+
+	resp := RotateAPIKeyResponse{
+		APIKeyResponse:       createdAPIKeyResponse(apiKey, fullKey),
+		PreviousKeyID:        idStr,
+		PreviousKeyExpiresAt: previousExpiresAt.Format("2006-01-02T15:04:05Z"),
+	}
+	h.audit(r, authCtx, "api_key.rotate", idStr, map[string]any{
+		"new_key_id":   resp.ID,
+		"key_prefix":   resp.KeyPrefix,
+		"grace_period": grace.String(),
+	})
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// validateScopes checks the scopes and authorized projects of a new key.
+// Only an admin key (or a dashboard user) may create an admin key, and every
+// authorized project must belong to the org. An API key caller can only
+// authorize its own project and the projects it is itself authorized for;
+// caller is nil for dashboard users, who are org-wide. A non-zero status is
+// the error to report.
+func (h *APIKeyHandler) validateScopes(ctx context.Context, orgID string, caller *db.GetAPIKeyByHashRow, scopes, projects []string) ([]string, []string, int, string) {
+	for _, scope := range scopes {
+		if !slices.Contains(domain.APIKeyScopes, scope) {
+			return nil, nil, http.StatusBadRequest, fmt.Sprintf("unknown scope %q", scope)
+		}
+	}
+	admin := slices.Contains(scopes, domain.ScopeAdmin)
+	if len(projects) > 0 && !admin {
+		return nil, nil, http.StatusBadRequest, "authorized_projects requires the admin scope"
+	}
+	if admin && caller != nil && !domain.HasScope(caller.Scopes, domain.ScopeAdmin) {
+		return nil, nil, http.StatusForbidden, "only an admin key can create admin keys"
+	}
+	for _, projectID := range projects {
+		if caller != nil && projectID != caller.ProjectID && !slices.Contains(caller.AuthorizedProjects, projectID) {
+			return nil, nil, http.StatusForbidden, "cannot authorize a project outside the calling key's projects: " + projectID
+		}
+	}
+	for _, projectID := range projects {
+		if _, err := h.queries.GetProjectByOrgAndID(ctx, db.GetProjectByOrgAndIDParams{
+			ID:    projectID,
+			OrgID: orgID,
+		}); err != nil {
+			return nil, nil, http.StatusBadRequest, "invalid authorized project: " + projectID
+		}
+	}
+
+	// The columns are NOT NULL, so store empty arrays rather than nil
+	if scopes == nil {
+		scopes = []string{}
+	}
+	if projects == nil {
+		projects = []string{}
+	}
+	return scopes, projects, 0, ""
+}
+
+// List lists all API keys for the authenticated project with a usage summary.
+// Revoked keys are hidden unless ?include_revoked=true.
 // Works with both Clerk auth (UserID) and API key auth (APIKeyID) in self-hosted mode.
 func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
 	authCtx := middleware.GetAuthContext(r.Context())
@@ -117,23 +405,50 @@ func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	includeRevoked := r.URL.Query().Get("include_revoked") == "true"
+
+	// Usage is best-effort: the listing is still useful without it
+	usage := make(map[[16]byte]int64)
+	counts, err := h.queries.CountAPIKeyEventsByProject(r.Context(), db.CountAPIKeyEventsByProjectParams{
+		OrgID:     authCtx.OrgID,
+		ProjectID: pgtype.Text{String: authCtx.ProjectID, Valid: true},
+		CreatedAt: pgtype.Timestamptz{Time: time.Now().Add(-24 * time.Hour), Valid: true},
+	})
+	if err != nil {
+		slog.Warn("failed to count API key usage", "error", err)
+	}
+	for _, c := range counts {
+		usage[c.ApiKeyID.Bytes] = c.Events
+	}
+
 	results := make([]APIKeyResponse, 0, len(keys))
 	for _, k := range keys {
-		// Skip revoked keys
-		if k.RevokedAt.Valid {
+		if k.RevokedAt.Valid && !includeRevoked {
 			continue
 		}
 
 		resp := APIKeyResponse{
-			ID:        uuid.UUID(k.ID.Bytes).String(),
-			KeyPrefix: k.KeyPrefix,
-			Name:      k.Name.String,
-			CreatedAt: k.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+			ID:                 uuid.UUID(k.ID.Bytes).String(),
+			KeyPrefix:          k.KeyPrefix,
+			Name:               k.Name.String,
+			RateLimitPerSecond: k.RateLimitPerSecond.Int32,
+			Scopes:             k.Scopes,
+			AuthorizedProjects: k.AuthorizedProjects,
+			CreatedAt:          k.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+			ExpiresAt:          formatOptionalTime(k.ExpiresAt),
+			Usage:              &APIKeyUsage{Events24h: usage[k.ID.Bytes]},
 		}
 		if k.LastUsedAt.Valid {
 			t := k.LastUsedAt.Time.Format("2006-01-02T15:04:05Z")
 			resp.LastUsedAt = &t
 		}
+		if k.RevokedAt.Valid {
+			t := k.RevokedAt.Time.Format("2006-01-02T15:04:05Z")
+			resp.RevokedAt = &t
+		}
+		if k.MaxConnections.Valid {
+			resp.MaxConnections = &k.MaxConnections.Int32
+		}
 		results = append(results, resp)
 	}
 
@@ -170,5 +485,12 @@ func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Open connections authenticated before the revoke
+	if h.hub != nil {
+		if n := h.hub.Kick(id.String()); n > 0 {
+			slog.Info("disconnected revoked API key's clients", "api_key_id", id.String(), "clients", n)
+		}
+	}
+
 	writeJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
 }
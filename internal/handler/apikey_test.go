@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+func TestValidateScopes_UnscopedKeyCreatesAdminKey(t *testing.T) {
+	h := &APIKeyHandler{}
+	// Like the bootstrap key: no scopes means full access
+	caller := &db.GetAPIKeyByHashRow{ProjectID: "prj_a", Scopes: []string{}}
+
+	scopes, projects, status, msg := h.validateScopes(context.Background(), "org_a", caller, []string{domain.ScopeAdmin}, nil)
+	if status != 0 {
+		t.Fatalf("status = %d (%s), want 0", status, msg)
+	}
+	if len(scopes) != 1 || scopes[0] != domain.ScopeAdmin || projects == nil {
+		t.Errorf("scopes = %v, projects = %v", scopes, projects)
+	}
+
+	scoped := &db.GetAPIKeyByHashRow{ProjectID: "prj_a", Scopes: []string{domain.ScopeEmit}}
+	if _, _, status, _ := h.validateScopes(context.Background(), "org_a", scoped, []string{domain.ScopeAdmin}, nil); status != http.StatusForbidden {
+		t.Errorf("scoped non-admin caller: status = %d, want 403", status)
+	}
+}
+
+func TestValidateScopes_AdminKeyCannotWidenProjects(t *testing.T) {
+	h := &APIKeyHandler{}
+	caller := &db.GetAPIKeyByHashRow{
+		ProjectID:          "prj_a",
+		Scopes:             []string{domain.ScopeAdmin},
+		AuthorizedProjects: []string{"prj_b"},
+	}
+
+	_, _, status, _ := h.validateScopes(context.Background(), "org_a", caller, []string{domain.ScopeAdmin}, []string{"prj_b", "prj_c"})
+	if status != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", status)
+	}
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -21,7 +23,9 @@ func NewAuditHandler(queries *db.Queries) *AuditHandler {
 	return &AuditHandler{queries: queries}
 }
 
-// List returns audit log entries filtered by query parameters.
+// List returns the caller's org's audit log entries, newest first, filtered
+// by action, actor and a from/to time range. A full page carries a
+// next_cursor; pass it back as before to fetch the next one.
 func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
 	authCtx := middleware.GetAuthContext(r.Context())
 	if authCtx == nil {
@@ -29,39 +33,17 @@ func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query params
-	actionFilter := r.URL.Query().Get("action")
-	sinceStr := r.URL.Query().Get("since")
-
-	limitStr := r.URL.Query().Get("limit")
-	limit := int32(50)
-	if limitStr != "" {
-		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 && n <= 1000 {
-			limit = int32(n)
-		}
-	}
-
-	params := db.ListAuditLogsParams{
-		Limit: limit,
-	}
-
 	// Enforce tenant isolation — OrgID is always required.
 	// Without this, callers with no org scope could read all orgs' audit logs.
 	if authCtx.OrgID == "" {
 		writeJSON(w, http.StatusForbidden, map[string]string{"error": "org scope required"})
 		return
 	}
-	params.OrgID = pgtype.Text{String: authCtx.OrgID, Valid: true}
 
-	if actionFilter != "" {
-		params.Action = pgtype.Text{String: actionFilter, Valid: true}
-	}
-
-	if sinceStr != "" {
-		if d, err := time.ParseDuration(sinceStr); err == nil {
-			since := time.Now().Add(-d)
-			params.Since = pgtype.Timestamptz{Time: since, Valid: true}
-		}
+	params, err := auditListParams(r.URL.Query(), authCtx.OrgID, time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
 	}
 
 	logs, err := h.queries.ListAuditLogs(r.Context(), params)
@@ -73,11 +55,14 @@ func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Format response
 	results := make([]map[string]any, len(logs))
 	for i, entry := range logs {
+		createdAt := entry.Timestamp.Time.Format(time.RFC3339)
 		result := map[string]any{
-			"id":        entry.ID,
-			"timestamp": entry.Timestamp.Time.Format(time.RFC3339),
-			"actor":     entry.Actor,
-			"action":    entry.Action,
+			"id":         entry.ID,
+			"created_at": createdAt,
+			"actor":      entry.Actor,
+			"action":     entry.Action,
+			// Deprecated: the old name of created_at, kept for one release
+			"timestamp": createdAt,
 		}
 		if entry.OrgID.Valid {
 			result["org_id"] = entry.OrgID.String
@@ -86,6 +71,8 @@ func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
 			result["target"] = entry.Target.String
 		}
 		if entry.Detail != nil {
+			result["metadata"] = json.RawMessage(entry.Detail)
+			// Deprecated: the old name of metadata, kept for one release
 			result["detail"] = json.RawMessage(entry.Detail)
 		}
 		if entry.IpAddress != nil {
@@ -94,8 +81,81 @@ func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
 		results[i] = result
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		"entries": results,
 		"count":   len(results),
-	})
+	}
+	// A full page may have more behind it; hand back a cursor to resume from.
+	if len(logs) == int(params.Limit) {
+		resp["next_cursor"] = logs[len(logs)-1].ID
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// auditListParams builds the audit query for orgID from the request's
+// query string. from and to take RFC 3339 or Unix seconds; since, a
+// duration back from now, is kept for older clients and loses to from.
+func auditListParams(query url.Values, orgID string, now time.Time) (db.ListAuditLogsParams, error) {
+	params := db.ListAuditLogsParams{
+		OrgID: pgtype.Text{String: orgID, Valid: true},
+		Limit: 50,
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
+			params.Limit = int32(min(n, 1000))
+		}
+	}
+	if action := query.Get("action"); action != "" {
+		params.Action = pgtype.Text{String: action, Valid: true}
+	}
+	if actor := query.Get("actor"); actor != "" {
+		params.Actor = pgtype.Text{String: actor, Valid: true}
+	}
+
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		d, err := time.ParseDuration(sinceStr)
+		if err != nil {
+			return params, fmt.Errorf("invalid since: must be a duration like 1h")
+		}
+		params.Since = pgtype.Timestamptz{Time: now.Add(-d), Valid: true}
+	}
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err := parseAuditTime(fromStr)
+		if err != nil {
+			return params, fmt.Errorf("invalid from: %w", err)
+		}
+		params.Since = pgtype.Timestamptz{Time: from, Valid: true}
+	}
+	if toStr := query.Get("to"); toStr != "" {
+		to, err := parseAuditTime(toStr)
+		if err != nil {
+			return params, fmt.Errorf("invalid to: %w", err)
+		}
+		params.Until = pgtype.Timestamptz{Time: to, Valid: true}
+	}
+	if params.Since.Valid && params.Until.Valid && !params.Since.Time.Before(params.Until.Time) {
+		return params, fmt.Errorf("from must be before to")
+	}
+
+	// Cursor: id of the last entry already seen
+	if beforeStr := query.Get("before"); beforeStr != "" {
+		before, err := strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil || before <= 0 {
+			return params, fmt.Errorf("invalid cursor")
+		}
+		params.Before = pgtype.Int8{Int64: before, Valid: true}
+	}
+	return params, nil
+}
+
+// parseAuditTime parses an RFC 3339 timestamp or Unix seconds.
+func parseAuditTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("must be RFC 3339 or Unix seconds")
 }
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAuditListParams(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	query := url.Values{
+		"action": {"api_key.create"},
+		"actor":  {"api:key_abc"},
+		"from":   {"2026-02-01T00:00:00Z"},
+		"to":     {"1772323200"}, // 2026-03-01T00:00:00Z
+		"before": {"42"},
+		"limit":  {"5000"},
+	}
+	params, err := auditListParams(query, "org_a", now)
+	if err != nil {
+		t.Fatalf("auditListParams: %v", err)
+	}
+	if params.OrgID.String != "org_a" || params.Action.String != "api_key.create" || params.Actor.String != "api:key_abc" {
+		t.Errorf("unexpected filters: %+v", params)
+	}
+	if !params.Since.Time.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) || !params.Until.Time.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range %v - %v", params.Since.Time, params.Until.Time)
+	}
+	if !params.Before.Valid || params.Before.Int64 != 42 {
+		t.Errorf("expected cursor 42, got %+v", params.Before)
+	}
+	if params.Limit != 1000 {
+		t.Errorf("expected limit capped at 1000, got %d", params.Limit)
+	}
+
+	// since is relative to now
+	params, err = auditListParams(url.Values{"since": {"1h"}}, "org_a", now)
+	if err != nil || !params.Since.Time.Equal(now.Add(-time.Hour)) || params.Limit != 50 {
+		t.Errorf("since: got %+v, %v", params, err)
+	}
+
+	for name, query := range map[string]url.Values{
+		"bad from":    {"from": {"yesterday"}},
+		"bad to":      {"to": {"2026-13-01"}},
+		"bad since":   {"since": {"a while"}},
+		"bad cursor":  {"before": {"abc"}},
+		"empty range": {"from": {"2026-03-01T00:00:00Z"}, "to": {"2026-02-01T00:00:00Z"}},
+		"zero cursor": {"before": {"0"}},
+	} {
+		if _, err := auditListParams(query, "org_a", now); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/filipexyz/notif/internal/blob"
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BlobHandler handles event attachment uploads.
+type BlobHandler struct {
+	blobs *blob.Service
+}
+
+// NewBlobHandler creates a new BlobHandler.
+func NewBlobHandler(blobs *blob.Service) *BlobHandler {
+	return &BlobHandler{blobs: blobs}
+}
+
+// CreateBlobRequest is the request body for POST /blobs.
+type CreateBlobRequest struct {
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"` // Upper bound for the upload, in bytes
+}
+
+// BlobResponse describes a blob. UploadURL is only set on create;
+// DownloadURL only once the upload has landed.
+type BlobResponse struct {
+	ID          string `json:"id"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Status      string `json:"status"` // "pending" or "uploaded"
+	CreatedAt   string `json:"created_at"`
+
+	UploadURL       string `json:"upload_url,omitempty"`
+	UploadMethod    string `json:"upload_method,omitempty"`
+	UploadExpiresAt string `json:"upload_expires_at,omitempty"`
+
+	DownloadURL       string `json:"download_url,omitempty"`
+	DownloadExpiresAt string `json:"download_expires_at,omitempty"`
+}
+
+// Create registers a blob and returns a presigned upload URL.
+func (h *BlobHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if h.blobs == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "blob storage is not configured"})
+		return
+	}
+
+	var req CreateBlobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	if req.Size <= 0 || req.Size > h.blobs.MaxSize() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "size must be between 1 byte and the server's blob limit"})
+		return
+	}
+
+	var apiKeyID pgtype.UUID
+	if apiKey := middleware.GetAPIKey(r.Context()); apiKey != nil {
+		apiKeyID = apiKey.ID
+	}
+
+	b, upload, err := h.blobs.Create(r.Context(), authCtx.OrgID, authCtx.ProjectID, apiKeyID, req.ContentType, req.Size)
+	if err != nil {
+		slog.Error("failed to create blob", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create blob"})
+		return
+	}
+
+	resp := blobResponse(b)
+	resp.UploadURL = absoluteURL(r, upload.URL)
+	resp.UploadMethod = upload.Method
+	resp.UploadExpiresAt = upload.ExpiresAt.Format("2006-01-02T15:04:05Z")
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// Get returns a blob's metadata and, once uploaded, a download URL.
+func (h *BlobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.blobs == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "blob storage is not configured"})
+		return
+	}
+
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	b, err := h.blobs.Get(r.Context(), authCtx.OrgID, authCtx.ProjectID, chi.URLParam(r, "id"))
+	if errors.Is(err, blob.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "blob not found"})
+		return
+	}
+	if errors.Is(err, blob.ErrSizeMismatch) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get blob", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get blob"})
+		return
+	}
+
+	resp := blobResponse(b)
+	if b.UploadedAt.Valid {
+		a, err := h.blobs.Attachment(r.Context(), b)
+		if err != nil {
+			slog.Error("failed to presign blob download", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get blob"})
+			return
+		}
+		resp.DownloadURL = absoluteURL(r, a.URL)
+		resp.DownloadExpiresAt = a.ExpiresAt.Format("2006-01-02T15:04:05Z")
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func blobResponse(b db.Blob) BlobResponse {
+	status := "pending"
+	if b.UploadedAt.Valid {
+		status = "uploaded"
+	}
+	return BlobResponse{
+		ID:          b.ID,
+		ContentType: b.ContentType,
+		Size:        b.Size,
+		Status:      status,
+		CreatedAt:   b.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// absoluteURL resolves a relative presigned URL (local store without
+// BLOB_PUBLIC_URL) against the address the request came in on.
+func absoluteURL(r *http.Request, u string) string {
+	if !strings.HasPrefix(u, "/") {
+		return u
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + u
+}
@@ -94,11 +94,13 @@ func (h *BootstrapHandler) Bootstrap(w http.ResponseWriter, r *http.Request) {
 
 	// Create the API key in the database
 	_, err = h.queries.CreateAPIKey(r.Context(), db.CreateAPIKeyParams{
-		KeyHash:   keyHash,
-		KeyPrefix: keyPrefix,
-		Name:      pgtype.Text{String: "Bootstrap Key", Valid: true},
-		OrgID:     pgtype.Text{String: h.cfg.DefaultOrgID, Valid: true},
-		ProjectID: projectID,
+		KeyHash:            keyHash,
+		KeyPrefix:          keyPrefix,
+		Name:               pgtype.Text{String: "Bootstrap Key", Valid: true},
+		OrgID:              pgtype.Text{String: h.cfg.DefaultOrgID, Valid: true},
+		ProjectID:          projectID,
+		Scopes:             []string{},
+		AuthorizedProjects: []string{},
 	})
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
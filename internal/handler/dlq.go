@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/filipexyz/notif/internal/middleware"
 	"github.com/filipexyz/notif/internal/nats"
@@ -14,6 +16,7 @@ import (
 type DLQHandler struct {
 	reader    *nats.DLQReader
 	publisher *nats.Publisher
+	replays   *DLQReplayGuard // nil disables the replay window
 }
 
 // NewDLQHandler creates a new DLQHandler.
@@ -24,6 +27,13 @@ func NewDLQHandler(reader *nats.DLQReader, publisher *nats.Publisher) *DLQHandle
 	}
 }
 
+// SetReplayGuard makes POST /dlq/replay skip messages replayed within the
+// guard's window. The guard outlives the handler, which routes build per
+// request.
+func (h *DLQHandler) SetReplayGuard(g *DLQReplayGuard) {
+	h.replays = g
+}
+
 // List returns messages from the DLQ (project-scoped).
 func (h *DLQHandler) List(w http.ResponseWriter, r *http.Request) {
 	authCtx := middleware.GetAuthContext(r.Context())
@@ -32,17 +42,36 @@ func (h *DLQHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	topic := r.URL.Query().Get("topic")
-	limitStr := r.URL.Query().Get("limit")
+	query := r.URL.Query()
+	filter := nats.DLQFilter{
+		Topic:         query.Get("topic"),
+		ConsumerGroup: query.Get("consumer_group"),
+	}
+	if s := query.Get("older_than"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid older_than duration"})
+			return
+		}
+		filter.OlderThan = d
+	}
+	if s := query.Get("min_attempts"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid min_attempts"})
+			return
+		}
+		filter.MinAttempts = n
+	}
 
 	limit := 100
-	if limitStr != "" {
+	if limitStr := query.Get("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
 			limit = l
 		}
 	}
 
-	entries, err := h.reader.List(r.Context(), authCtx.OrgID, authCtx.ProjectID, topic, limit)
+	entries, err := h.reader.ListFiltered(r.Context(), authCtx.OrgID, authCtx.ProjectID, filter, limit)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "failed to list DLQ",
@@ -54,13 +83,14 @@ func (h *DLQHandler) List(w http.ResponseWriter, r *http.Request) {
 	messages := make([]map[string]any, len(entries))
 	for i, entry := range entries {
 		messages[i] = map[string]any{
-			"seq":        entry.Seq,
-			"topic":      entry.Message.OriginalTopic,
-			"error":      entry.Message.LastError,
-			"attempts":   entry.Message.Attempts,
-			"created_at": entry.Message.FailedAt,
-			"event_id":   entry.Message.ID,
-			"data":       entry.Message.Data,
+			"seq":            entry.Seq,
+			"topic":          entry.Message.OriginalTopic,
+			"error":          entry.Message.LastError,
+			"attempts":       entry.Message.Attempts,
+			"created_at":     entry.Message.FailedAt,
+			"event_id":       entry.Message.ID,
+			"consumer_group": entry.Message.ConsumerGroup,
+			"data":           entry.Message.Data,
 		}
 	}
 
@@ -70,6 +100,26 @@ func (h *DLQHandler) List(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Stats returns DLQ message counts by topic and by consumer group
+// (project-scoped).
+func (h *DLQHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.OrgID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	stats, err := h.reader.Stats(r.Context(), authCtx.OrgID, authCtx.ProjectID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get DLQ stats",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
 // Get returns a specific DLQ message (with org and project verification).
 func (h *DLQHandler) Get(w http.ResponseWriter, r *http.Request) {
 	authCtx := middleware.GetAuthContext(r.Context())
@@ -228,29 +278,178 @@ func (h *DLQHandler) ReplayAll(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Purge deletes all messages from the DLQ (project-scoped), optionally filtered by topic.
-func (h *DLQHandler) Purge(w http.ResponseWriter, r *http.Request) {
+// maxDLQReplayBatch caps the messages one POST /dlq/replay re-injects.
+const maxDLQReplayBatch = 1000
+
+// DLQReplayRequest selects the DLQ messages to replay: either Seqs, the
+// message sequence numbers from GET /dlq, or every message on Topic.
+type DLQReplayRequest struct {
+	Seqs  []uint64 `json:"seqs,omitempty"`
+	Topic string   `json:"topic,omitempty"`
+}
+
+// ReplayBatch republishes the selected DLQ messages to their original
+// topics and removes them from the DLQ (project-scoped). Messages replayed
+// within the replay window are skipped.
+func (h *DLQHandler) ReplayBatch(w http.ResponseWriter, r *http.Request) {
 	authCtx := middleware.GetAuthContext(r.Context())
 	if authCtx == nil || authCtx.OrgID == "" {
 		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 		return
 	}
 
-	topic := r.URL.Query().Get("topic")
-
-	entries, err := h.reader.List(r.Context(), authCtx.OrgID, authCtx.ProjectID, topic, 1000)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "failed to list DLQ",
+	var req DLQReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON payload"})
+		return
+	}
+	if (len(req.Seqs) == 0) == (req.Topic == "") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "exactly one of seqs or topic is required"})
+		return
+	}
+	if len(req.Seqs) > maxDLQReplayBatch {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "too many seqs (max " + strconv.Itoa(maxDLQReplayBatch) + ")",
 		})
 		return
 	}
 
-	deleted := 0
+	var entries []nats.DLQEntry
+	notFound := 0
+	if req.Topic != "" {
+		var err error
+		entries, err = h.reader.List(r.Context(), authCtx.OrgID, authCtx.ProjectID, req.Topic, maxDLQReplayBatch)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to list DLQ",
+			})
+			return
+		}
+	} else {
+		for _, seq := range req.Seqs {
+			entry, err := h.reader.Get(r.Context(), seq)
+			// Another project's message reads as missing
+			if err != nil || entry.Message.OrgID != authCtx.OrgID || entry.Message.ProjectID != authCtx.ProjectID {
+				notFound++
+				continue
+			}
+			entries = append(entries, *entry)
+		}
+	}
+
+	replayed, skipped, failed := 0, 0, 0
+	now := time.Now()
 	for _, entry := range entries {
-		if err := h.reader.Delete(r.Context(), entry.Seq); err == nil {
-			deleted++
+		key := replayKey(entry)
+		if !h.replays.claim(key, now) {
+			skipped++
+			continue
 		}
+		if err := h.reader.Replay(r.Context(), entry.Seq, h.publisher); err != nil {
+			h.replays.release(key)
+			failed++
+			continue
+		}
+		replayed++
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"replayed":  replayed,
+		"skipped":   skipped,
+		"failed":    failed,
+		"not_found": notFound,
+	})
+}
+
+// replayKey identifies a DLQ message across dead-letterings: an event that
+// fails again after a replay comes back under a new seq but the same id.
+func replayKey(entry nats.DLQEntry) string {
+	id := entry.Message.ID
+	if id == "" {
+		id = "seq:" + strconv.FormatUint(entry.Seq, 10)
+	}
+	return entry.Message.OrgID + "/" + entry.Message.ProjectID + "/" + id
+}
+
+// DefaultDLQReplayWindow is how long a replayed DLQ message can't be
+// replayed again through POST /dlq/replay.
+const DefaultDLQReplayWindow = 5 * time.Minute
+
+// DLQReplayGuard remembers recently replayed DLQ messages, so repeating a
+// bulk replay (or replaying an event that promptly dead-letters again)
+// doesn't start a replay storm.
+type DLQReplayGuard struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+}
+
+// NewDLQReplayGuard creates a DLQReplayGuard that refuses a replay within
+// window of the previous one.
+func NewDLQReplayGuard(window time.Duration) *DLQReplayGuard {
+	return &DLQReplayGuard{window: window, seen: make(map[string]time.Time)}
+}
+
+// claim records key and reports whether it was not replayed within the
+// window. A nil guard claims everything.
+func (g *DLQReplayGuard) claim(key string, now time.Time) bool {
+	if g == nil || g.window <= 0 {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for k, at := range g.seen {
+		if now.Sub(at) >= g.window {
+			delete(g.seen, k)
+		}
+	}
+	if _, ok := g.seen[key]; ok {
+		return false
+	}
+	g.seen[key] = now
+	return true
+}
+
+// release forgets key, e.g. after its replay failed, so it can be retried
+// straight away.
+func (g *DLQReplayGuard) release(key string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.seen, key)
+}
+
+// Purge deletes the project's DLQ messages, optionally only those on topic
+// or that failed before an RFC 3339 time (?topic=&before=), and returns how
+// many it deleted.
+func (h *DLQHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.OrgID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	query := r.URL.Query()
+	filter := nats.DLQFilter{Topic: query.Get("topic")}
+	if s := query.Get("before"); s != "" {
+		before, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid before time (want RFC 3339)"})
+			return
+		}
+		filter.Before = before
+	}
+
+	deleted, err := h.reader.Purge(r.Context(), authCtx.OrgID, authCtx.ProjectID, filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error":   "failed to purge DLQ",
+			"deleted": deleted,
+		})
+		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
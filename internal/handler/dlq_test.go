@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/nats"
+)
+
+func newTestDLQHandler(t *testing.T) (*DLQHandler, *nats.DLQPublisher, *nats.Client) {
+	t.Helper()
+	srv, err := nats.StartEmbedded(nats.EmbeddedConfig{StoreDir: t.TempDir(), Port: -1})
+	if err != nil {
+		t.Fatalf("start embedded: %v", err)
+	}
+	t.Cleanup(srv.Shutdown)
+
+	nc, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+	if err := nc.EnsureStreams(context.Background()); err != nil {
+		t.Fatalf("ensure streams: %v", err)
+	}
+
+	reader, err := nats.NewDLQReader(nc.JetStream())
+	if err != nil {
+		t.Fatalf("dlq reader: %v", err)
+	}
+	h := NewDLQHandler(reader, nats.NewPublisher(nc.JetStream()))
+	h.SetReplayGuard(NewDLQReplayGuard(time.Minute))
+	return h, nats.NewDLQPublisher(nc.JetStream()), nc
+}
+
+func replayDLQ(t *testing.T, h *DLQHandler, body string) (int, map[string]int) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/dlq/replay", strings.NewReader(body))
+	req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{
+		OrgID:     "org_1",
+		ProjectID: "prj_1",
+	}))
+	rec := httptest.NewRecorder()
+	h.ReplayBatch(rec, req)
+
+	var counts map[string]int
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &counts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code, counts
+}
+
+func TestDLQReplayBatch(t *testing.T) {
+	h, dlq, nc := newTestDLQHandler(t)
+	ctx := context.Background()
+
+	for _, msg := range []*nats.DLQMessage{
+		{ID: "evt_1", OriginalTopic: "orders.created", ProjectID: "prj_1"},
+		{ID: "evt_2", OriginalTopic: "orders.created", ProjectID: "prj_1"},
+		{ID: "evt_3", OriginalTopic: "users.signup", ProjectID: "prj_1"},
+		{ID: "evt_4", OriginalTopic: "orders.created", ProjectID: "prj_other"},
+	} {
+		msg.OrgID = "org_1"
+		msg.Data = json.RawMessage(`{}`)
+		if err := dlq.Publish(ctx, msg); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	// Seqs 1-3 belong to prj_1; seq 4 is another project's
+	code, counts := replayDLQ(t, h, `{"seqs":[3,4]}`)
+	if code != http.StatusOK || counts["replayed"] != 1 || counts["not_found"] != 1 {
+		t.Fatalf("replay by seq = %d %v, want 1 replayed, 1 not found", code, counts)
+	}
+
+	code, counts = replayDLQ(t, h, `{"topic":"orders.created"}`)
+	if code != http.StatusOK || counts["replayed"] != 2 {
+		t.Fatalf("replay by topic = %d %v, want 2 replayed", code, counts)
+	}
+
+	info, err := nc.Stream().Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Msgs != 3 {
+		t.Errorf("events stream has %d messages, want 3", info.State.Msgs)
+	}
+	if n := info.State.NumSubjects; n != 2 {
+		t.Errorf("replayed to %d subjects, want 2", n)
+	}
+
+	// evt_1 dead-letters again right away: the window refuses it
+	if err := dlq.Publish(ctx, &nats.DLQMessage{ID: "evt_1", OrgID: "org_1", ProjectID: "prj_1", OriginalTopic: "orders.created", Data: json.RawMessage(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	code, counts = replayDLQ(t, h, `{"topic":"orders.created"}`)
+	if code != http.StatusOK || counts["replayed"] != 0 || counts["skipped"] != 1 {
+		t.Errorf("repeat replay = %d %v, want 1 skipped", code, counts)
+	}
+}
+
+func TestDLQReplayBatch_Validation(t *testing.T) {
+	h := NewDLQHandler(nil, nil)
+
+	for _, body := range []string{
+		`{`,
+		`{}`,
+		`{"seqs":[1],"topic":"orders.created"}`,
+		`{"seqs":[` + strings.Repeat("1,", maxDLQReplayBatch) + `1]}`,
+	} {
+		if code, _ := replayDLQ(t, h, body); code != http.StatusBadRequest {
+			t.Errorf("body %.40s: status = %d, want 400", body, code)
+		}
+	}
+}
+
+func TestDLQReplayGuard(t *testing.T) {
+	g := NewDLQReplayGuard(time.Minute)
+	now := time.Now()
+
+	if !g.claim("a", now) {
+		t.Fatal("first claim refused")
+	}
+	if g.claim("a", now.Add(30*time.Second)) {
+		t.Error("claim within the window allowed")
+	}
+	if !g.claim("a", now.Add(2*time.Minute)) {
+		t.Error("claim after the window refused")
+	}
+
+	g.claim("b", now)
+	g.release("b")
+	if !g.claim("b", now) {
+		t.Error("claim after release refused")
+	}
+}
+
+func TestDLQPurge(t *testing.T) {
+	h, dlq, _ := newTestDLQHandler(t)
+	ctx := context.Background()
+	cutoff := time.Now().Add(-time.Hour)
+
+	for _, msg := range []*nats.DLQMessage{
+		{ID: "evt_1", OriginalTopic: "orders.created", FailedAt: cutoff.Add(-time.Hour)},
+		{ID: "evt_2", OriginalTopic: "orders.created", FailedAt: time.Now()},
+		{ID: "evt_3", OriginalTopic: "users.signup", FailedAt: cutoff.Add(-time.Hour)},
+	} {
+		msg.OrgID, msg.ProjectID = "org_1", "prj_1"
+		msg.Data = json.RawMessage(`{}`)
+		if err := dlq.Publish(ctx, msg); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	purge := func(query string) (int, map[string]int) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/dlq?"+query, nil)
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{
+			OrgID:     "org_1",
+			ProjectID: "prj_1",
+		}))
+		rec := httptest.NewRecorder()
+		h.Purge(rec, req)
+		var counts map[string]int
+		json.Unmarshal(rec.Body.Bytes(), &counts)
+		return rec.Code, counts
+	}
+
+	if code, _ := purge("before=yesterday"); code != http.StatusBadRequest {
+		t.Errorf("invalid before: status = %d, want 400", code)
+	}
+	if code, counts := purge("topic=orders.created&before=" + cutoff.UTC().Format(time.RFC3339)); code != http.StatusOK || counts["deleted"] != 1 {
+		t.Errorf("purge by topic and age = %d %v, want 1 deleted", code, counts)
+	}
+	if code, counts := purge(""); code != http.StatusOK || counts["deleted"] != 2 {
+		t.Errorf("purge all = %d %v, want 2 deleted", code, counts)
+	}
+}
@@ -1,29 +1,56 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/filipexyz/notif/internal/audit"
+	"github.com/filipexyz/notif/internal/blob"
 	"github.com/filipexyz/notif/internal/config"
 	"github.com/filipexyz/notif/internal/db"
 	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/eventstore"
+	"github.com/filipexyz/notif/internal/idempotency"
+	"github.com/filipexyz/notif/internal/metrics"
 	"github.com/filipexyz/notif/internal/middleware"
 	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/outbox"
+	"github.com/filipexyz/notif/internal/pipeline"
 	"github.com/filipexyz/notif/internal/schema"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/filipexyz/notif/internal/tracing"
+	"github.com/filipexyz/notif/internal/websocket"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // EmitHandler handles POST /emit.
 type EmitHandler struct {
 	publisher      *nats.Publisher
 	queries        *db.Queries
+	events         eventstore.Store
+	keys           idempotency.Store
 	schemaRegistry *schema.Registry
+	validations    *schema.ValidationRecorder
 	cfg            *config.Config
 	auditLog       *audit.Logger
+	blobs          *blob.Service
+	outbox         *outbox.Relay
+	pipelines      *pipeline.Registry
+	rateLimits     *nats.EmitRateLimiter
+	keyLimits      *middleware.RateLimiter
 }
 
 // NewEmitHandler creates a new EmitHandler.
@@ -31,12 +58,61 @@ func NewEmitHandler(publisher *nats.Publisher, queries *db.Queries, schemaRegist
 	return &EmitHandler{
 		publisher:      publisher,
 		queries:        queries,
+		events:         eventstore.NewPostgres(queries),
+		keys:           idempotency.NewPostgres(queries),
 		schemaRegistry: schemaRegistry,
 		cfg:            cfg,
 		auditLog:       auditLog,
 	}
 }
 
+// SetEventStore replaces the Postgres store for emitted event metadata.
+func (h *EmitHandler) SetEventStore(events eventstore.Store) {
+	h.events = events
+}
+
+// SetIdempotencyStore replaces the Postgres store for emit idempotency keys.
+func (h *EmitHandler) SetIdempotencyStore(keys idempotency.Store) {
+	h.keys = keys
+}
+
+// SetValidationRecorder records emit-time schema validations for the
+// schemas' stats. Without it, validations aren't recorded.
+func (h *EmitHandler) SetValidationRecorder(validations *schema.ValidationRecorder) {
+	h.validations = validations
+}
+
+// SetBlobService enables event attachments. Without it, emits that
+// reference blobs are rejected.
+func (h *EmitHandler) SetBlobService(blobs *blob.Service) {
+	h.blobs = blobs
+}
+
+// SetOutbox makes emits durable: events are persisted to the outbox and
+// published by its relay, instead of directly.
+func (h *EmitHandler) SetOutbox(relay *outbox.Relay) {
+	h.outbox = relay
+}
+
+// SetPipelines applies the project's transformation pipelines to each
+// event's data after schema validation, before it is stored and published.
+func (h *EmitHandler) SetPipelines(pipelines *pipeline.Registry) {
+	h.pipelines = pipelines
+}
+
+// SetRateLimiter enforces EMIT_RATE_LIMITS. The limiter holds the token
+// buckets, so every EmitHandler of a server must share the same one.
+func (h *EmitHandler) SetRateLimiter(limits *nats.EmitRateLimiter) {
+	h.rateLimits = limits
+}
+
+// SetKeyRateLimiter charges websocket emits, and each event of a batch, to
+// the caller's per-key request rate limit, which the RateLimit middleware
+// charges once per HTTP request.
+func (h *EmitHandler) SetKeyRateLimiter(limits *middleware.RateLimiter) {
+	h.keyLimits = limits
+}
+
 // Emit publishes an event to a topic.
 func (h *EmitHandler) Emit(w http.ResponseWriter, r *http.Request) {
 	// Limit body size
@@ -57,87 +133,361 @@ func (h *EmitHandler) Emit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate topic
-	if err := validateTopic(req.Topic); err != nil {
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	resp, emitErr := h.emit(r.Context(), r, &req)
+	if emitErr != nil {
+		if emitErr.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(emitErr.retryAfter.Seconds()))))
+		}
+		writeJSON(w, emitErr.status, emitErr.body)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DefaultEmitBatchMaxEvents caps the number of events in one POST
+// /emit/batch when EMIT_BATCH_MAX_EVENTS is unset.
+const DefaultEmitBatchMaxEvents = 500
+
+// DefaultEmitBatchMaxBytes caps the body of one POST /emit/batch when
+// EMIT_BATCH_MAX_BYTES is unset.
+const DefaultEmitBatchMaxBytes = 4 << 20
+
+// EmitBatch publishes up to EMIT_BATCH_MAX_EVENTS events in one request.
+// Each event goes through the same validation as Emit; a rejected event
+// doesn't stop the rest, and its result carries the error instead of an ID.
+func (h *EmitHandler) EmitBatch(w http.ResponseWriter, r *http.Request) {
+	maxEvents := h.cfg.EmitBatchMaxEvents
+	if maxEvents <= 0 {
+		maxEvents = DefaultEmitBatchMaxEvents
+	}
+	maxSize := h.cfg.EmitBatchMaxBytes
+	if maxSize <= 0 {
+		maxSize = DefaultEmitBatchMaxBytes
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+
+	var req domain.EmitBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if strings.Contains(err.Error(), "http: request body too large") {
+			writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+				"error": fmt.Sprintf("payload too large, max %dKB", maxSize/1024),
+			})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid JSON payload",
+		})
+		return
+	}
+	if len(req.Events) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "events is required",
+		})
+		return
+	}
+	if len(req.Events) > maxEvents {
 		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": fmt.Sprintf("too many events, max %d per batch", maxEvents),
 		})
 		return
 	}
 
+	// Each event counts as a request against the key's rate limit; the
+	// RateLimit middleware already took one token for the batch
+	if h.keyLimits != nil {
+		if ok, ratePerSecond := h.keyLimits.AllowRequestN(r, len(req.Events)-1); !ok {
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(ratePerSecond))
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{
+				"error": "rate limit exceeded",
+			})
+			return
+		}
+	}
+
+	resp := domain.EmitBatchResponse{Results: make([]domain.EmitBatchResult, len(req.Events))}
+	for i := range req.Events {
+		result := domain.EmitBatchResult{Topic: req.Events[i].Topic}
+		emitted, emitErr := h.emit(r.Context(), r, &req.Events[i])
+		if emitErr != nil {
+			result.Error = emitErr.message()
+			result.Code = emitErr.code
+			resp.Failed++
+		} else {
+			result.ID = emitted.ID
+			result.CreatedAt = &emitted.CreatedAt
+			resp.Emitted++
+		}
+		resp.Results[i] = result
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// WebSocketEmitter returns the emitter for a websocket connection opened by
+// r. Events are published as r's authenticated identity, with the same
+// validation and limits as Emit.
+func (h *EmitHandler) WebSocketEmitter(r *http.Request) websocket.Emitter {
+	return func(ctx context.Context, req *domain.EmitRequest) (*domain.EmitResponse, error) {
+		if h.keyLimits != nil {
+			if ok, _ := h.keyLimits.AllowRequest(r); !ok {
+				return nil, &websocket.EmitError{Code: "RATE_LIMITED", Message: "rate limit exceeded"}
+			}
+		}
+		resp, emitErr := h.emit(ctx, r, req)
+		if emitErr != nil {
+			return nil, &websocket.EmitError{Code: emitErr.code, Message: emitErr.message()}
+		}
+		return resp, nil
+	}
+}
+
+// emitError is a rejected emit: the HTTP status and body for Emit, and the
+// error code for websocket clients.
+type emitError struct {
+	status int
+	code   string
+	body   map[string]any
+	// retryAfter is set when the emit was rate limited.
+	retryAfter time.Duration
+}
+
+func newEmitError(status int, code, message string) *emitError {
+	return &emitError{status: status, code: code, body: map[string]any{"error": message}}
+}
+
+func (e *emitError) message() string {
+	msg, _ := e.body["error"].(string)
+	if errs, ok := e.body["validation_errors"].([]schema.ValidationError); ok && len(errs) > 0 {
+		parts := make([]string, len(errs))
+		for i, ve := range errs {
+			parts[i] = ve.Field + ": " + ve.Message
+		}
+		msg += ": " + strings.Join(parts, "; ")
+	}
+	return msg
+}
+
+// emit validates and publishes req. The caller's identity and address come
+// from r; ctx bounds the work, since for websocket emits r has already
+// completed. The emit runs in a span continuing the caller's traceparent.
+func (h *EmitHandler) emit(ctx context.Context, r *http.Request, req *domain.EmitRequest) (*domain.EmitResponse, *emitError) {
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, r.Header), "notif.emit",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("notif.topic", req.Topic)),
+	)
+	defer span.End()
+
+	resp, eerr := h.emitEvent(ctx, r, req)
+	if eerr != nil {
+		span.SetStatus(codes.Error, eerr.message())
+	} else {
+		span.SetAttributes(attribute.String("notif.event_id", resp.ID))
+	}
+	return resp, eerr
+}
+
+func (h *EmitHandler) emitEvent(ctx context.Context, r *http.Request, req *domain.EmitRequest) (*domain.EmitResponse, *emitError) {
+	if maxSize := h.cfg.MaxPayloadSize; int64(len(req.Data)) > maxSize {
+		return nil, newEmitError(http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", fmt.Sprintf("payload too large, max %dKB", maxSize/1024))
+	}
+
+	// Validate topic
+	if err := validateTopic(req.Topic); err != nil {
+		return nil, newEmitError(http.StatusBadRequest, "INVALID_TOPIC", err.Error())
+	}
+	if err := validateData(req.Data); err != nil {
+		return nil, newEmitError(http.StatusBadRequest, "INVALID_DATA", err.Error())
+	}
+	if ok, retryAfter := h.rateLimits.Allow(middleware.GetProjectIDFromContext(r.Context()), req.Topic); !ok {
+		eerr := newEmitError(http.StatusTooManyRequests, "RATE_LIMITED", "emit rate limit exceeded for topic "+req.Topic)
+		eerr.retryAfter = retryAfter
+		return nil, eerr
+	}
+	if err := idempotency.ValidateKey(req.IdempotencyKey); err != nil {
+		return nil, newEmitError(http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", err.Error())
+	}
+	var ackDeadline time.Duration
+	if req.AckDeadline != "" {
+		d, err := nats.ParseAckDeadline(req.AckDeadline)
+		if err != nil {
+			return nil, newEmitError(http.StatusBadRequest, "INVALID_ACK_DEADLINE", err.Error())
+		}
+		ackDeadline = d
+	}
+
 	// Schema validation (if registry is configured and we have project context)
 	authCtx := middleware.GetAuthContext(r.Context())
+	var validatedSchema *schema.ValidationResult
 	if h.schemaRegistry != nil && authCtx != nil && authCtx.ProjectID != "" {
-		validationResult, err := h.schemaRegistry.ValidateEvent(r.Context(), authCtx.ProjectID, req.Topic, req.Data)
+		validationResult, err := h.schemaRegistry.ValidateEvent(ctx, authCtx.ProjectID, req.Topic, req.Data)
 		if err != nil {
 			slog.Error("schema validation error", "error", err, "topic", req.Topic)
 			// Don't block on validation errors - treat as no schema
-		} else if validationResult != nil && !validationResult.Valid {
-			// Get the schema to check validation mode
-			schemaForTopic, _ := h.schemaRegistry.GetSchemaForTopic(r.Context(), authCtx.ProjectID, req.Topic)
-			if schemaForTopic != nil && schemaForTopic.LatestVersion != nil {
-				switch schemaForTopic.LatestVersion.ValidationMode {
-				case schema.ValidationModeStrict:
-					switch schemaForTopic.LatestVersion.OnInvalid {
-					case schema.OnInvalidReject:
-						writeJSON(w, http.StatusBadRequest, map[string]any{
-							"error":             "schema validation failed",
-							"schema":            validationResult.Schema,
-							"version":           validationResult.Version,
-							"validation_errors": validationResult.Errors,
-						})
-						return
-					case schema.OnInvalidLog, schema.OnInvalidDLQ:
-						// Log but continue
-						slog.Warn("schema validation failed",
-							"topic", req.Topic,
-							"schema", validationResult.Schema,
-							"errors", validationResult.Errors,
-						)
-					}
-				case schema.ValidationModeWarn:
-					slog.Warn("schema validation warning",
-						"topic", req.Topic,
-						"schema", validationResult.Schema,
-						"errors", validationResult.Errors,
-					)
-				// ValidationModeDisabled - do nothing
+		} else if validationResult != nil && validationResult.Schema != "" {
+			validatedSchema = validationResult
+		}
+		if validationResult != nil && !validationResult.Valid {
+			switch {
+			case validationResult.Rejected():
+				h.recordValidation(authCtx, "", req.Topic, validationResult)
+				return nil, &emitError{
+					status: http.StatusUnprocessableEntity,
+					code:   "SCHEMA_VALIDATION_FAILED",
+					body: map[string]any{
+						"error":             "schema validation failed",
+						"schema":            validationResult.Schema,
+						"version":           validationResult.Version,
+						"validation_errors": validationResult.Errors,
+					},
 				}
+			case validationResult.Mode() == schema.ValidationModeStrict:
+				// on_invalid log or dlq: log but continue
+				slog.Warn("schema validation failed",
+					"topic", req.Topic,
+					"schema", validationResult.Schema,
+					"errors", validationResult.Errors,
+				)
+			case validationResult.Mode() == schema.ValidationModeWarn:
+				slog.Warn("schema validation warning",
+					"topic", req.Topic,
+					"schema", validationResult.Schema,
+					"errors", validationResult.Errors,
+				)
+				// ValidationModeDisabled - do nothing
 			}
 		}
 	}
 
+	// Transformation pipelines see the validated payload; subscribers only
+	// ever see their output
+	data := req.Data
+	if h.pipelines != nil && authCtx != nil && authCtx.ProjectID != "" {
+		transformed, applied, err := h.pipelines.Apply(ctx, authCtx.ProjectID, req.Topic, data)
+		if terr := (*pipeline.TransformError)(nil); errors.As(err, &terr) {
+			return nil, newEmitError(http.StatusUnprocessableEntity, "PIPELINE_FAILED", terr.Error())
+		}
+		if err != nil {
+			slog.Error("failed to apply pipelines", "error", err, "topic", req.Topic)
+			return nil, newEmitError(http.StatusInternalServerError, "PIPELINE_FAILED", "failed to apply pipelines")
+		}
+		if len(applied) > 0 {
+			slog.Debug("pipelines applied", "topic", req.Topic, "pipelines", applied)
+		}
+		data = transformed
+	}
+
 	// Create event with org and project context
-	event := domain.NewEvent(req.Topic, req.Data)
+	event := domain.NewEvent(req.Topic, data)
+	event.Traceparent = tracing.Traceparent(ctx)
+	if ackDeadline > 0 {
+		event.AckDeadline = ackDeadline.String()
+	}
 	if authCtx != nil {
 		event.OrgID = authCtx.OrgID
 		event.ProjectID = authCtx.ProjectID
 	}
+	if validatedSchema != nil {
+		event.Schema = validatedSchema.Schema
+		event.SchemaVersion = validatedSchema.Version
+	}
 
-	// Publish to NATS
-	if err := h.publisher.Publish(r.Context(), event); err != nil {
+	// Resolve attachments into presigned download links for subscribers
+	if len(req.Attachments) > 0 {
+		if h.blobs == nil {
+			return nil, newEmitError(http.StatusBadRequest, "BLOBS_UNAVAILABLE", "blob storage is not configured")
+		}
+		if authCtx == nil || authCtx.ProjectID == "" {
+			return nil, newEmitError(http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized")
+		}
+		attachments, err := h.blobs.Attach(ctx, authCtx.OrgID, authCtx.ProjectID, req.Attachments)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, blob.ErrNotFound) || errors.Is(err, blob.ErrNotUploaded) || errors.Is(err, blob.ErrSizeMismatch) || errors.Is(err, blob.ErrTooManyAttachments) {
+				status = http.StatusBadRequest
+			} else {
+				slog.Error("failed to resolve attachments", "error", err, "topic", req.Topic)
+			}
+			return nil, newEmitError(status, "INVALID_ATTACHMENT", "invalid attachment: "+err.Error())
+		}
+		for i := range attachments {
+			attachments[i].URL = absoluteURL(r, attachments[i].URL)
+		}
+		event.Attachments = attachments
+	}
+
+	// A retry of an emit already made returns the original event
+	if req.IdempotencyKey != "" && authCtx != nil && authCtx.ProjectID != "" {
+		original, claimed, err := h.keys.Claim(ctx, authCtx.ProjectID, req.IdempotencyKey, idempotency.Record{
+			EventID:   event.ID,
+			Topic:     event.Topic,
+			CreatedAt: event.Timestamp,
+		}, time.Now().Add(-h.idempotencyWindow()))
+		if err != nil {
+			slog.Error("failed to claim idempotency key", "error", err, "topic", req.Topic)
+			return nil, newEmitError(http.StatusInternalServerError, "PUBLISH_FAILED", "failed to check idempotency key")
+		}
+		if !claimed {
+			slog.Info("duplicate emit", "event_id", original.EventID, "topic", original.Topic)
+			return &domain.EmitResponse{
+				ID:        original.EventID,
+				Topic:     original.Topic,
+				CreatedAt: original.CreatedAt,
+				Duplicate: true,
+			}, nil
+		}
+	}
+
+	if validatedSchema != nil {
+		h.recordValidation(authCtx, event.ID, req.Topic, validatedSchema)
+	}
+
+	// Publish to NATS, or persist for the outbox relay to publish
+	var publishErr *emitError
+	if h.outbox != nil {
+		if err := h.outbox.Enqueue(ctx, event); err != nil {
+			slog.Error("failed to persist event to outbox", "error", err, "topic", req.Topic)
+			publishErr = newEmitError(http.StatusInternalServerError, "PUBLISH_FAILED", "failed to persist event")
+		}
+	} else if err := h.publisher.Publish(ctx, event); err != nil {
 		slog.Error("failed to publish event", "error", err, "topic", req.Topic)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "failed to publish event",
-		})
-		return
+		publishErr = newEmitError(http.StatusInternalServerError, "PUBLISH_FAILED", "failed to publish event")
+	}
+	if publishErr != nil {
+		if req.IdempotencyKey != "" && authCtx != nil && authCtx.ProjectID != "" {
+			// Nothing was published, so a retry must go through
+			if err := h.keys.Release(ctx, authCtx.ProjectID, req.IdempotencyKey); err != nil {
+				slog.Error("failed to release idempotency key", "error", err, "topic", req.Topic)
+			}
+		}
+		return nil, publishErr
 	}
+	metrics.EventsEmitted.WithLabelValues(metrics.TopicPrefix(event.Topic)).Inc()
 
 	// Store event metadata (sync, ensures event exists for delivery queries)
 	apiKey := middleware.GetAPIKey(r.Context())
 	if authCtx != nil && authCtx.OrgID != "" {
-		params := db.CreateEventParams{
+		rec := eventstore.Record{
 			ID:          event.ID,
 			Topic:       event.Topic,
 			OrgID:       authCtx.OrgID,
-			ProjectID:   pgtype.Text{String: authCtx.ProjectID, Valid: authCtx.ProjectID != ""},
-			PayloadSize: int32(len(req.Data)),
-			CreatedAt:   pgtype.Timestamptz{Time: event.Timestamp, Valid: true},
+			ProjectID:   authCtx.ProjectID,
+			PayloadSize: len(data),
+			CreatedAt:   event.Timestamp,
+		}
+		// Kept for data filters on GET /events
+		if max := h.cfg.EventDataMaxBytes; max > 0 && len(data) <= max {
+			rec.Data = data
 		}
-		if apiKey != nil {
-			params.ApiKeyID = apiKey.ID
+		if apiKey != nil && apiKey.ID.Valid {
+			keyID := uuid.UUID(apiKey.ID.Bytes)
+			rec.APIKeyID = &keyID
 		}
-		if err := h.queries.CreateEvent(r.Context(), params); err != nil {
+		if err := h.events.Append(ctx, rec); err != nil {
 			slog.Error("failed to store event metadata", "error", err, "event_id", event.ID)
 			// Don't fail the request, event was already published to NATS
 		}
@@ -146,7 +496,7 @@ func (h *EmitHandler) Emit(w http.ResponseWriter, r *http.Request) {
 	slog.Info("event emitted",
 		"event_id", event.ID,
 		"topic", event.Topic,
-		"size", len(req.Data),
+		"size", len(data),
 	)
 
 	// Audit log
@@ -156,18 +506,34 @@ func (h *EmitHandler) Emit(w http.ResponseWriter, r *http.Request) {
 		if authCtx != nil {
 			orgID = authCtx.OrgID
 		}
-		ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
-		h.auditLog.Log(ctx, actor, "event.emit", orgID, event.Topic, map[string]any{
+		auditCtx := audit.WithIP(ctx, audit.IPFromRequest(r))
+		h.auditLog.Log(auditCtx, actor, "event.emit", orgID, event.Topic, map[string]any{
 			"event_id": event.ID,
-			"size":     len(req.Data),
+			"size":     len(data),
 		})
 	}
 
-	writeJSON(w, http.StatusOK, domain.EmitResponse{
+	return &domain.EmitResponse{
 		ID:        event.ID,
 		Topic:     event.Topic,
 		CreatedAt: event.Timestamp,
-	})
+	}, nil
+}
+
+// idempotencyWindow is how long an idempotency key is remembered.
+func (h *EmitHandler) idempotencyWindow() time.Duration {
+	if h.cfg.EmitIdempotencyWindow > 0 {
+		return h.cfg.EmitIdempotencyWindow
+	}
+	return idempotency.DefaultWindow
+}
+
+// recordValidation queues an emit-time schema validation for the schema's
+// stats. It never blocks or fails the emit.
+func (h *EmitHandler) recordValidation(authCtx *middleware.AuthContext, eventID, topic string, result *schema.ValidationResult) {
+	if h.validations != nil {
+		h.validations.Record(authCtx.OrgID, authCtx.ProjectID, eventID, topic, result)
+	}
 }
 
 func validateTopic(topic string) error {
@@ -186,9 +552,59 @@ func validateTopic(topic string) error {
 	if strings.ContainsAny(topic, ">*") {
 		return &validationError{"topic cannot contain wildcard characters (> or *)"}
 	}
+	if !utf8.ValidString(topic) {
+		return &validationError{"topic must be valid UTF-8"}
+	}
+	if strings.IndexFunc(topic, unicode.IsControl) >= 0 {
+		return &validationError{"topic cannot contain control characters"}
+	}
 	return nil
 }
 
+// validateData rejects payloads that aren't valid UTF-8 or whose object
+// keys contain control characters; both break downstream rendering.
+// Malformed JSON is left to the caller's decoding.
+func validateData(data json.RawMessage) error {
+	if !utf8.Valid(data) {
+		return &validationError{"data must be valid UTF-8"}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var objects []bool // per nesting level: object (true) or array
+	expectKey := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			switch v {
+			case '{':
+				objects = append(objects, true)
+				expectKey = true
+				continue
+			case '[':
+				objects = append(objects, false)
+				expectKey = false
+				continue
+			default:
+				objects = objects[:len(objects)-1]
+			}
+		case string:
+			if expectKey {
+				if strings.IndexFunc(v, unicode.IsControl) >= 0 {
+					return &validationError{fmt.Sprintf("data key %q contains control characters", v)}
+				}
+				expectKey = false
+				continue
+			}
+		}
+		// A value ended; inside an object, a key comes next
+		expectKey = len(objects) > 0 && objects[len(objects)-1]
+	}
+}
+
 type validationError struct {
 	msg string
 }
@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/eventstore"
+	"github.com/filipexyz/notif/internal/idempotency"
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/outbox"
+	"github.com/filipexyz/notif/internal/pipeline"
+	"github.com/filipexyz/notif/internal/websocket"
+)
+
+func TestValidateTopic(t *testing.T) {
+	tests := []struct {
+		name  string
+		topic string
+		valid bool
+	}{
+		{"simple", "orders.created", true},
+		{"unicode", "pedidos.criação", true},
+		{"empty", "", false},
+		{"wildcard", "orders.*", false},
+		{"leading dot", ".orders", false},
+		{"embedded newline", "orders.\ncreated", false},
+		{"trailing newline", "orders.created\n", false},
+		{"embedded tab", "orders\t.created", false},
+		{"carriage return", "orders.created\r", false},
+		{"nul", "orders.\x00created", false},
+		{"delete", "orders.\x7fcreated", false},
+		{"invalid utf8", "orders.\xffcreated", false},
+		{"truncated utf8", "orders.cria\xc3", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTopic(tt.topic)
+			if (err == nil) != tt.valid {
+				t.Fatalf("validateTopic(%q) = %v, want valid=%v", tt.topic, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateData(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		valid bool
+	}{
+		{"object", `{"order_id":"123","items":[{"sku":"a"}]}`, true},
+		{"control chars in values", `{"note":"line1\nline2\ttab"}`, true},
+		{"array root", `[{"a":1},2,"three"]`, true},
+		{"scalar", `42`, true},
+		{"newline in key", `{"order\nid":"123"}`, false},
+		{"tab in nested key", `{"order":{"line\titems":[]}}`, false},
+		{"control key after array value", `{"items":[1,2],"bad\u0001":true}`, false},
+		{"control key in array of objects", `[{"ok":1},{"\u0000":2}]`, false},
+		{"invalid utf8", "{\"name\":\"\xff\"}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateData(json.RawMessage(tt.data))
+			if (err == nil) != tt.valid {
+				t.Fatalf("validateData(%s) = %v, want valid=%v", tt.data, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestEmitBatch_Validation(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+
+	tooMany := make([]string, DefaultEmitBatchMaxEvents+1)
+	for i := range tooMany {
+		tooMany[i] = `{"topic":"orders.created","data":{}}`
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"invalid json", `{"events":`, http.StatusBadRequest},
+		{"empty", `{"events":[]}`, http.StatusBadRequest},
+		{"too many", `{"events":[` + strings.Join(tooMany, ",") + `]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.EmitBatch(w, httptest.NewRequest(http.MethodPost, "/api/v1/emit/batch", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestEmitBatch_ConfiguredLimit(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024, EmitBatchMaxEvents: 2}, nil)
+
+	body := `{"events":[{"topic":"a.b","data":{}},{"topic":"a.b","data":{}},{"topic":"a.b","data":{}}]}`
+	w := httptest.NewRecorder()
+	h.EmitBatch(w, httptest.NewRequest(http.MethodPost, "/api/v1/emit/batch", strings.NewReader(body)))
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "max 2 per batch") {
+		t.Errorf("status = %d, body %s; want 400 with max 2", w.Code, w.Body.String())
+	}
+}
+
+func TestEmitBatch_BodyLimit(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024, EmitBatchMaxBytes: 2048}, nil)
+
+	// Every event is within MaxPayloadSize, the batch is not
+	event := `{"topic":"a.b","data":{"pad":"` + strings.Repeat("x", 900) + `"}}`
+	body := `{"events":[` + strings.Repeat(event+",", 3) + event + `]}`
+	w := httptest.NewRecorder()
+	h.EmitBatch(w, httptest.NewRequest(http.MethodPost, "/api/v1/emit/batch", strings.NewReader(body)))
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "max 2KB") {
+		t.Errorf("status = %d, body %s; want 413 with max 2KB", w.Code, w.Body.String())
+	}
+}
+
+func TestEmitBatch_ChargesKeyRateLimitPerEvent(t *testing.T) {
+	keyLimits := middleware.NewRateLimiter(middleware.RateLimitConfig{
+		DefaultRatePerSecond: 1,
+		DefaultBurst:         4,
+		CleanupInterval:      time.Minute,
+		MaxAge:               time.Minute,
+	})
+	t.Cleanup(keyLimits.Stop)
+
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+	h.SetKeyRateLimiter(keyLimits)
+	h.SetEventStore(eventstore.NewMemory())
+	h.SetOutbox(outbox.NewRelay(outbox.NewMemory(), func(context.Context, *domain.Event) error { return nil }, time.Second))
+
+	userID := "user_1"
+	batch := func(n int) *httptest.ResponseRecorder {
+		body := `{"events":[` + strings.TrimSuffix(strings.Repeat(`{"topic":"a.b","data":{}},`, n), ",") + `]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/emit/batch", strings.NewReader(body))
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{OrgID: "org_1", ProjectID: "prj_a", UserID: &userID}))
+		w := httptest.NewRecorder()
+		middleware.RateLimit(keyLimits)(http.HandlerFunc(h.EmitBatch)).ServeHTTP(w, req)
+		return w
+	}
+
+	if w := batch(3); w.Code != http.StatusOK {
+		t.Fatalf("first batch: status = %d (%s), want 200", w.Code, w.Body.String())
+	}
+	// One token left, the batch needs three
+	if w := batch(3); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second batch: status = %d (%s), want 429", w.Code, w.Body.String())
+	}
+}
+
+func TestEmitBatch_ReportsRejectedEvents(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+
+	body := fmt.Sprintf(`{"events":[{"topic":"orders.\u0007","data":{}},{"topic":"orders.created","data":%q}]}`, strings.Repeat("x", 2048))
+	w := httptest.NewRecorder()
+	h.EmitBatch(w, httptest.NewRequest(http.MethodPost, "/api/v1/emit/batch", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body.String())
+	}
+
+	var resp domain.EmitBatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Emitted != 0 || resp.Failed != 2 || len(resp.Results) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.Results[0].Code != "INVALID_TOPIC" || resp.Results[1].Code != "PAYLOAD_TOO_LARGE" {
+		t.Errorf("codes = %s, %s", resp.Results[0].Code, resp.Results[1].Code)
+	}
+	if resp.Results[1].Topic != "orders.created" || resp.Results[1].ID != "" {
+		t.Errorf("result = %+v", resp.Results[1])
+	}
+}
+
+func TestEmit_Outbox(t *testing.T) {
+	// No publisher: with an outbox the emit must not publish directly
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+	store := outbox.NewMemory()
+	h.SetOutbox(outbox.NewRelay(store, func(context.Context, *domain.Event) error {
+		return errors.New("nats: connection closed")
+	}, time.Second))
+
+	w := httptest.NewRecorder()
+	h.Emit(w, httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(`{"topic":"orders.created","data":{"id":1}}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", w.Code, w.Body.String())
+	}
+
+	var resp domain.EmitResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	due, _ := store.Due(context.Background(), time.Now(), 10)
+	if len(due) != 1 || due[0].Event.ID != resp.ID || due[0].Event.Topic != "orders.created" {
+		t.Errorf("expected event %s persisted to the outbox, got %+v", resp.ID, due)
+	}
+}
+
+func TestEmit_AppliesPipelinesBeforeDelivery(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+	h.SetEventStore(eventstore.NewMemory())
+	store := outbox.NewMemory()
+	h.SetOutbox(outbox.NewRelay(store, func(context.Context, *domain.Event) error { return nil }, time.Second))
+
+	pipelines := pipeline.NewRegistry(pipeline.NewMemory())
+	if _, err := pipelines.Create(context.Background(), &pipeline.Pipeline{
+		ProjectID: "prj_a", Name: "redact-card", TopicPattern: "orders.>", Redact: []string{"card.number"}, Enabled: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	h.SetPipelines(pipelines)
+
+	emit := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(body))
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{OrgID: "org_a", ProjectID: "prj_a"}))
+		w := httptest.NewRecorder()
+		h.Emit(w, req)
+		return w
+	}
+
+	if w := emit(`{"topic":"orders.created","data":{"card":{"number":"4242424242424242"},"total":10}}`); w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", w.Code, w.Body.String())
+	}
+	if w := emit(`{"topic":"users.signup","data":{"card":{"number":"4242424242424242"}}}`); w.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", w.Code, w.Body.String())
+	}
+
+	due, _ := store.Due(context.Background(), time.Now(), 10)
+	if len(due) != 2 {
+		t.Fatalf("expected 2 events to deliver, got %d", len(due))
+	}
+	if got := string(due[0].Event.Data); got != `{"card":{"number":"[redacted]"},"total":10}` {
+		t.Errorf("orders.created delivered %s, want the card number redacted", got)
+	}
+	if got := string(due[1].Event.Data); !strings.Contains(got, "4242424242424242") {
+		t.Errorf("users.signup delivered %s, want it untouched", got)
+	}
+}
+
+func TestEmit_IdempotencyKey(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024, EmitIdempotencyWindow: time.Hour}, nil)
+	h.SetEventStore(eventstore.NewMemory())
+	h.SetIdempotencyStore(idempotency.NewMemory())
+	store := outbox.NewMemory()
+	h.SetOutbox(outbox.NewRelay(store, func(context.Context, *domain.Event) error { return nil }, time.Second))
+
+	emit := func(projectID, key string) domain.EmitResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(`{"topic":"orders.created","data":{}}`))
+		req.Header.Set("Idempotency-Key", key)
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{OrgID: "org_a", ProjectID: projectID}))
+		w := httptest.NewRecorder()
+		h.Emit(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d (%s)", w.Code, w.Body.String())
+		}
+		var resp domain.EmitResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	first := emit("prj_a", "order-1")
+	retry := emit("prj_a", "order-1")
+	if retry.ID != first.ID || !retry.Duplicate || first.Duplicate {
+		t.Errorf("retry = %+v, want the original event %s marked duplicate", retry, first.ID)
+	}
+	if other := emit("prj_b", "order-1"); other.ID == first.ID || other.Duplicate {
+		t.Errorf("another project's key was deduplicated: %+v", other)
+	}
+	if next := emit("prj_a", "order-2"); next.ID == first.ID {
+		t.Error("a new key was deduplicated")
+	}
+
+	if n := store.Len(); n != 3 {
+		t.Errorf("published %d events, want 3", n)
+	}
+}
+
+func TestEmit_InvalidIdempotencyKey(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(`{"topic":"orders.created","data":{}}`))
+	req.Header.Set("Idempotency-Key", strings.Repeat("k", idempotency.MaxKeyLength+1))
+	w := httptest.NewRecorder()
+	h.Emit(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestEmit_InvalidAckDeadline(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+
+	for _, deadline := range []string{"soon", "500ms", "2h"} {
+		w := httptest.NewRecorder()
+		h.Emit(w, httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(`{"topic":"orders.created","data":{},"ack_deadline":"`+deadline+`"}`)))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "ack_deadline") {
+			t.Errorf("ack_deadline %q: status = %d (%s), want 400", deadline, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestEmit_TopicRateLimit(t *testing.T) {
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+	h.SetRateLimiter(nats.NewEmitRateLimiter(nats.EmitRateLimits{{Pattern: "orders.>", Rate: 1, Burst: 1}}))
+	h.SetEventStore(eventstore.NewMemory())
+	h.SetOutbox(outbox.NewRelay(outbox.NewMemory(), func(context.Context, *domain.Event) error { return nil }, time.Second))
+
+	emit := func(projectID, topic string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(`{"topic":"`+topic+`","data":{}}`))
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{OrgID: "org_1", ProjectID: projectID}))
+		w := httptest.NewRecorder()
+		h.Emit(w, req)
+		return w
+	}
+
+	if w := emit("prj_a", "orders.created"); w.Code != http.StatusOK {
+		t.Fatalf("first emit: status = %d (%s), want 200", w.Code, w.Body.String())
+	}
+	w := emit("prj_a", "orders.shipped")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second emit: status = %d (%s), want 429", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	// Other projects and topics keep their own budget
+	if w := emit("prj_b", "orders.created"); w.Code != http.StatusOK {
+		t.Errorf("other project: status = %d, want 200", w.Code)
+	}
+	if w := emit("prj_a", "users.signup"); w.Code != http.StatusOK {
+		t.Errorf("unlimited topic: status = %d, want 200", w.Code)
+	}
+}
+
+func TestWebSocketEmitter_KeyRateLimit(t *testing.T) {
+	keyLimits := middleware.NewRateLimiter(middleware.RateLimitConfig{
+		DefaultRatePerSecond: 1,
+		DefaultBurst:         1,
+		CleanupInterval:      time.Minute,
+		MaxAge:               time.Minute,
+	})
+	t.Cleanup(keyLimits.Stop)
+
+	h := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+	h.SetKeyRateLimiter(keyLimits)
+	h.SetEventStore(eventstore.NewMemory())
+	h.SetOutbox(outbox.NewRelay(outbox.NewMemory(), func(context.Context, *domain.Event) error { return nil }, time.Second))
+
+	userID := "user_1"
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	r = r.WithContext(middleware.SetAuthContext(r.Context(), &middleware.AuthContext{OrgID: "org_1", ProjectID: "prj_a", UserID: &userID}))
+	emit := h.WebSocketEmitter(r)
+
+	if _, err := emit(context.Background(), &domain.EmitRequest{Topic: "orders.created", Data: []byte(`{}`)}); err != nil {
+		t.Fatalf("first emit: %v", err)
+	}
+	_, err := emit(context.Background(), &domain.EmitRequest{Topic: "orders.created", Data: []byte(`{}`)})
+	var emitErr *websocket.EmitError
+	if !errors.As(err, &emitErr) || emitErr.Code != "RATE_LIMITED" {
+		t.Fatalf("second emit: err = %v, want RATE_LIMITED", err)
+	}
+}
@@ -7,22 +7,29 @@ import (
 	"time"
 
 	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/eventstore"
 	"github.com/filipexyz/notif/internal/middleware"
 	"github.com/filipexyz/notif/internal/nats"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // EventsHandler handles event query operations.
 type EventsHandler struct {
 	reader  *nats.EventReader
 	queries *db.Queries
+	events  eventstore.Store
 }
 
 // NewEventsHandler creates a new EventsHandler.
 func NewEventsHandler(reader *nats.EventReader, queries *db.Queries) *EventsHandler {
-	return &EventsHandler{reader: reader, queries: queries}
+	return &EventsHandler{reader: reader, queries: queries, events: eventstore.NewPostgres(queries)}
+}
+
+// SetEventStore replaces the Postgres store used for event stats.
+func (h *EventsHandler) SetEventStore(events eventstore.Store) {
+	h.events = events
 }
 
 // List returns historical events filtered by org.
@@ -68,6 +75,24 @@ func (h *EventsHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Data filters are answered from the event store, not the stream
+	if filters := r.URL.Query()["filter"]; len(filters) > 0 {
+		h.search(w, r, opts, filters)
+		return
+	}
+
+	// Parse cursor (stream sequence of the last event already seen)
+	if afterStr := r.URL.Query().Get("after"); afterStr != "" {
+		after, err := strconv.ParseUint(afterStr, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "invalid cursor",
+			})
+			return
+		}
+		opts.AfterSeq = after
+	}
+
 	events, err := h.reader.Query(r.Context(), opts)
 	if err != nil {
 		slog.Error("failed to query events", "error", err)
@@ -77,10 +102,81 @@ func (h *EventsHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		"events": events,
 		"count":  len(events),
+	}
+	// A full page may have more behind it; hand back a cursor to resume from.
+	if len(events) == opts.Limit {
+		resp["next_cursor"] = events[len(events)-1].Seq
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// searchedEvent is an event found by a data filter. It comes from the
+// event store, which doesn't know stream sequences.
+type searchedEvent struct {
+	Event     *domain.Event `json:"event"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// search lists the events whose stored data matches every filter, newest
+// first, paging with before (the id of the last event already seen).
+func (h *EventsHandler) search(w http.ResponseWriter, r *http.Request, opts nats.QueryOptions, filters []string) {
+	if r.URL.Query().Get("after") != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "after is not supported with filter; page with before",
+		})
+		return
+	}
+	filter, err := eventstore.ParseFilter(filters)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	records, err := h.events.Search(r.Context(), eventstore.SearchOptions{
+		OrgID:     opts.OrgID,
+		ProjectID: opts.ProjectID,
+		Filter:    filter,
+		Topic:     opts.Topic,
+		From:      opts.From,
+		To:        opts.To,
+		Before:    r.URL.Query().Get("before"),
+		Limit:     opts.Limit,
 	})
+	if err != nil {
+		slog.Error("failed to search events", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to search events",
+		})
+		return
+	}
+
+	events := make([]searchedEvent, len(records))
+	for i, rec := range records {
+		events[i] = searchedEvent{
+			Event: &domain.Event{
+				ID:        rec.ID,
+				Topic:     rec.Topic,
+				Data:      rec.Data,
+				Timestamp: rec.CreatedAt,
+				OrgID:     rec.OrgID,
+				ProjectID: rec.ProjectID,
+			},
+			Timestamp: rec.CreatedAt,
+		}
+	}
+
+	resp := map[string]any{
+		"events": events,
+		"count":  len(events),
+	}
+	// A full page may have more behind it; hand back a cursor to resume from.
+	if len(records) == opts.Limit {
+		resp["next_cursor"] = records[len(records)-1].ID
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // Get returns a specific event by sequence number (with org verification).
@@ -127,10 +223,7 @@ func (h *EventsHandler) Stats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	count, err := h.queries.CountEventsByProject(r.Context(), db.CountEventsByProjectParams{
-		OrgID:     authCtx.OrgID,
-		ProjectID: pgtype.Text{String: authCtx.ProjectID, Valid: authCtx.ProjectID != ""},
-	})
+	stats, err := h.events.Aggregate(r.Context(), authCtx.OrgID, authCtx.ProjectID)
 	if err != nil {
 		slog.Error("failed to get event stats", "error", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
@@ -140,7 +233,35 @@ func (h *EventsHandler) Stats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"messages": count,
+		"messages": stats.Total,
+	})
+}
+
+// TopicTree returns the project's topics as a tree of dot-separated
+// segments, each with the number of events on it and below it.
+func (h *EventsHandler) TopicTree(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.OrgID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	counts, err := h.events.CountByTopic(r.Context(), authCtx.OrgID, authCtx.ProjectID)
+	if err != nil {
+		slog.Error("failed to count events by topic", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to get topic tree",
+		})
+		return
+	}
+
+	var total int64
+	for _, tc := range counts {
+		total += tc.Count
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tree":  eventstore.TopicTree(counts),
+		"total": total,
 	})
 }
 
@@ -177,6 +298,7 @@ func (h *EventsHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
 			"receiver_type": d.ReceiverType,
 			"status":        d.Status,
 			"attempt":       d.Attempt,
+			"redeliveries":  d.Redeliveries,
 			"created_at":    d.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
 		}
 
@@ -197,6 +319,9 @@ func (h *EventsHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if d.FirstAttemptAt.Valid {
+			results[i]["first_attempt_at"] = d.FirstAttemptAt.Time.Format("2006-01-02T15:04:05Z")
+		}
 		if d.DeliveredAt.Valid {
 			results[i]["delivered_at"] = d.DeliveredAt.Time.Format("2006-01-02T15:04:05Z")
 		}
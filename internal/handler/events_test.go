@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/eventstore"
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/outbox"
+)
+
+func TestEventsHandler_Stats(t *testing.T) {
+	store := eventstore.NewMemory()
+	for i, rec := range []eventstore.Record{
+		{ID: "evt_1", Topic: "orders.created", OrgID: "org_a", ProjectID: "prj_a"},
+		{ID: "evt_2", Topic: "orders.shipped", OrgID: "org_a", ProjectID: "prj_a"},
+		{ID: "evt_3", Topic: "orders.created", OrgID: "org_a", ProjectID: "prj_b"},
+		{ID: "evt_4", Topic: "orders.created", OrgID: "org_b", ProjectID: "prj_a"},
+	} {
+		rec.CreatedAt = time.Now().Add(-time.Duration(i) * time.Minute)
+		if err := store.Append(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewEventsHandler(nil, nil)
+	h.SetEventStore(store)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stats", nil)
+	req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{
+		OrgID:     "org_a",
+		ProjectID: "prj_a",
+	}))
+	rec := httptest.NewRecorder()
+	h.Stats(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Messages int64 `json:"messages"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Messages != 2 {
+		t.Errorf("messages = %d, want 2", resp.Messages)
+	}
+}
+
+func TestEventsHandler_StatsUnauthorized(t *testing.T) {
+	h := NewEventsHandler(nil, nil)
+	h.SetEventStore(eventstore.NewMemory())
+
+	rec := httptest.NewRecorder()
+	h.Stats(rec, httptest.NewRequest(http.MethodGet, "/api/v1/events/stats", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestEventsHandler_TopicTree(t *testing.T) {
+	store := eventstore.NewMemory()
+	emitter := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024}, nil)
+	emitter.SetEventStore(store)
+	emitter.SetOutbox(outbox.NewRelay(outbox.NewMemory(), func(context.Context, *domain.Event) error { return nil }, time.Second))
+
+	auth := &middleware.AuthContext{OrgID: "org_a", ProjectID: "prj_a"}
+	for _, topic := range []string{"orders.created", "orders.updated", "orders.updated"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(`{"topic":"`+topic+`","data":{}}`))
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), auth))
+		w := httptest.NewRecorder()
+		emitter.Emit(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("emit %s: status = %d (%s)", topic, w.Code, w.Body)
+		}
+	}
+
+	h := NewEventsHandler(nil, nil)
+	h.SetEventStore(store)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/topics/tree", nil)
+	req = req.WithContext(middleware.SetAuthContext(req.Context(), auth))
+	rec := httptest.NewRecorder()
+	h.TopicTree(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Tree  []eventstore.TopicNode `json:"tree"`
+		Total int64                  `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 3 || len(resp.Tree) != 1 {
+		t.Fatalf("got %+v, want one root and 3 events", resp)
+	}
+	orders := resp.Tree[0]
+	if orders.Segment != "orders" || orders.Count != 3 || len(orders.Children) != 2 {
+		t.Fatalf("root = %+v, want orders with 3 events and 2 children", orders)
+	}
+	for i, want := range []eventstore.TopicNode{
+		{Segment: "created", Topic: "orders.created", Count: 1},
+		{Segment: "updated", Topic: "orders.updated", Count: 2},
+	} {
+		if got := *orders.Children[i]; got.Segment != want.Segment || got.Topic != want.Topic || got.Count != want.Count {
+			t.Errorf("child %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestEventsHandler_ListFilter(t *testing.T) {
+	store := eventstore.NewMemory()
+	emitter := NewEmitHandler(nil, nil, nil, &config.Config{MaxPayloadSize: 1024, EventDataMaxBytes: 64}, nil)
+	emitter.SetEventStore(store)
+	emitter.SetOutbox(outbox.NewRelay(outbox.NewMemory(), func(context.Context, *domain.Event) error { return nil }, time.Second))
+
+	auth := &middleware.AuthContext{OrgID: "org_a", ProjectID: "prj_a"}
+	for _, data := range []string{
+		`{"status":"error","job":1}`,
+		`{"status":"ok","job":2}`,
+		`{"status":"error","job":3}`,
+		`{"status":"error","job":4,"log":"` + strings.Repeat("x", 64) + `"}`, // too large to keep
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/emit", strings.NewReader(`{"topic":"jobs.run","data":`+data+`}`))
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), auth))
+		w := httptest.NewRecorder()
+		emitter.Emit(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("emit: status = %d (%s)", w.Code, w.Body)
+		}
+		time.Sleep(time.Millisecond) // distinct emit times
+	}
+
+	h := NewEventsHandler(nil, nil)
+	h.SetEventStore(store)
+	list := func(query string) (int, []searchedEvent, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events?"+query, nil)
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), auth))
+		rec := httptest.NewRecorder()
+		h.List(rec, req)
+		var resp struct {
+			Events     []searchedEvent `json:"events"`
+			NextCursor string          `json:"next_cursor"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp.Events, resp.NextCursor
+	}
+	jobs := func(events []searchedEvent) string {
+		out := make([]string, len(events))
+		for i, e := range events {
+			var data struct {
+				Job int `json:"job"`
+			}
+			json.Unmarshal(e.Event.Data, &data)
+			out[i] = strconv.Itoa(data.Job)
+		}
+		return strings.Join(out, ",")
+	}
+
+	code, events, cursor := list("filter=data.status%3Derror&topic=jobs.*&limit=1")
+	if code != http.StatusOK || jobs(events) != "3" || cursor == "" {
+		t.Fatalf("page 1: status %d, jobs %s, cursor %q", code, jobs(events), cursor)
+	}
+	code, events, _ = list("filter=data.status%3Derror&limit=1&before=" + cursor)
+	if code != http.StatusOK || jobs(events) != "1" {
+		t.Fatalf("page 2: status %d, jobs %s", code, jobs(events))
+	}
+	if _, events, _ = list("filter=data.status%3Derror&filter=data.job%3D1"); jobs(events) != "1" {
+		t.Errorf("two predicates: jobs %s", jobs(events))
+	}
+
+	if code, _, _ = list("filter=status%3Derror"); code != http.StatusBadRequest {
+		t.Errorf("invalid filter: status %d, want 400", code)
+	}
+	if code, _, _ = list("filter=data.status%3Derror&after=5"); code != http.StatusBadRequest {
+		t.Errorf("after with filter: status %d, want 400", code)
+	}
+}
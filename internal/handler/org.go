@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"regexp"
+	"time"
 
 	"github.com/filipexyz/notif/internal/accounts"
 	"github.com/filipexyz/notif/internal/audit"
@@ -24,6 +26,10 @@ type OrgHandler struct {
 	auditLog     *audit.Logger
 	onOrgCreated func(orgID string) // called after a new org is added to the pool
 	onOrgDeleted func(orgID string) // called before an org is removed from the pool
+	onOrgDrained func(orgID string) // called after an org is marked drained
+	onOrgResumed func(orgID string) // called after an org's drain is lifted
+
+	onStreamRecreated func(orgID string) // called after a migration recreated an org's stream
 }
 
 // NewOrgHandler creates a new OrgHandler.
@@ -48,6 +54,24 @@ func (h *OrgHandler) SetOnOrgDeleted(fn func(orgID string)) {
 	h.onOrgDeleted = fn
 }
 
+// SetOnOrgDrained sets a callback invoked after an org is drained for
+// maintenance. Used to pause deliveries (WebSocket, webhooks) for the org.
+func (h *OrgHandler) SetOnOrgDrained(fn func(orgID string)) {
+	h.onOrgDrained = fn
+}
+
+// SetOnOrgResumed sets a callback invoked after a drained org is resumed.
+func (h *OrgHandler) SetOnOrgResumed(fn func(orgID string)) {
+	h.onOrgResumed = fn
+}
+
+// SetOnStreamRecreated sets a callback invoked after a migration copied an
+// org's events stream into a new one, deleting its consumers. Used to
+// restart the org's webhook worker.
+func (h *OrgHandler) SetOnStreamRecreated(fn func(orgID string)) {
+	h.onStreamRecreated = fn
+}
+
 // CreateOrgRequest is the request body for creating an org.
 type CreateOrgRequest struct {
 	ID   string `json:"id"`
@@ -61,6 +85,7 @@ type OrgResponse struct {
 	NatsPublicKey string `json:"nats_public_key"`
 	BillingTier   string `json:"billing_tier"`
 	CreatedAt     string `json:"created_at"`
+	Drained       bool   `json:"drained,omitempty"` // in maintenance: emits refused, deliveries paused
 }
 
 // Create creates a new org with NATS account.
@@ -204,6 +229,9 @@ func (h *OrgHandler) List(w http.ResponseWriter, r *http.Request) {
 			BillingTier:   tier,
 			CreatedAt:     org.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
 		}
+		if h.pool != nil {
+			results[i].Drained = h.pool.IsDrained(org.ID)
+		}
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
@@ -212,6 +240,152 @@ func (h *OrgHandler) List(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Drain puts an org into maintenance: emits are refused with 503 and
+// subscribers stop receiving new events (WebSocket clients get a "draining"
+// message). Deliveries already in flight finish; nothing is lost, since
+// undelivered events wait in the org's stream until Resume.
+func (h *OrgHandler) Drain(w http.ResponseWriter, r *http.Request) {
+	h.setDrained(w, r, true)
+}
+
+// Resume lifts a drain and restarts deliveries.
+func (h *OrgHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.setDrained(w, r, false)
+}
+
+func (h *OrgHandler) setDrained(w http.ResponseWriter, r *http.Request, drained bool) {
+	if h.pool == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "multi-account mode not enabled",
+		})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	if err := h.pool.SetDrained(orgID, drained); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "org not found"})
+		return
+	}
+
+	action, status := "account.drain", "drained"
+	if drained {
+		if h.onOrgDrained != nil {
+			h.onOrgDrained(orgID)
+		}
+	} else {
+		action, status = "account.resume", "active"
+		if h.onOrgResumed != nil {
+			h.onOrgResumed(orgID)
+		}
+	}
+	slog.Info("org "+status, "org_id", orgID)
+
+	if h.auditLog != nil {
+		authCtx := middleware.GetAuthContext(r.Context())
+		ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
+		h.auditLog.Log(ctx, auditActor(authCtx), action, orgID, orgID, nil)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"org_id": orgID, "status": status})
+}
+
+// MigrateStreamRequest is the request body for migrating an org's events
+// stream. Omitted settings keep their current value.
+type MigrateStreamRequest struct {
+	MaxAge   string `json:"max_age,omitempty"` // Go duration, e.g. "72h"
+	MaxBytes int64  `json:"max_bytes,omitempty"`
+	Replicas int    `json:"replicas,omitempty"`
+	Storage  string `json:"storage,omitempty"` // "file" or "memory"
+
+	DryRun        bool `json:"dry_run,omitempty"`
+	AllowDataLoss bool `json:"allow_data_loss,omitempty"`
+}
+
+// MigrateStream moves an org's events stream to new settings, or with
+// dry_run only reports the planned change. Changing storage copies the
+// stream, which needs the org drained first.
+func (h *OrgHandler) MigrateStream(w http.ResponseWriter, r *http.Request) {
+	if h.pool == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "multi-account mode not enabled",
+		})
+		return
+	}
+
+	orgID := chi.URLParam(r, "id")
+	orgClient, err := h.pool.Get(orgID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "org not found"})
+		return
+	}
+
+	var req MigrateStreamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	settings := nats.StreamSettings{MaxBytes: req.MaxBytes, Replicas: req.Replicas, Storage: req.Storage}
+	if req.MaxAge != "" {
+		if settings.MaxAge, err = time.ParseDuration(req.MaxAge); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid max_age: " + err.Error()})
+			return
+		}
+	}
+
+	plan, err := orgClient.MigrateStream(r.Context(), settings, nats.MigrateOptions{DryRun: true})
+	if err != nil {
+		writeMigrateStreamError(w, orgID, plan, err)
+		return
+	}
+	if req.DryRun || len(plan.Changes) == 0 {
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+	if plan.Copy && !h.pool.IsDrained(orgID) {
+		writeJSON(w, http.StatusConflict, map[string]any{
+			"error": "changing storage copies the stream; drain the org first",
+			"plan":  plan,
+		})
+		return
+	}
+
+	migration, err := orgClient.MigrateStream(r.Context(), settings, nats.MigrateOptions{AllowDataLoss: req.AllowDataLoss})
+	if migration != nil && migration.Copy && migration.Applied && h.onStreamRecreated != nil {
+		h.onStreamRecreated(orgID)
+	}
+	if err != nil {
+		writeMigrateStreamError(w, orgID, migration, err)
+		return
+	}
+	slog.Info("org stream migrated", "org_id", orgID, "stream", migration.Stream, "changes", len(migration.Changes))
+
+	if h.auditLog != nil {
+		authCtx := middleware.GetAuthContext(r.Context())
+		ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
+		h.auditLog.Log(ctx, auditActor(authCtx), "account.stream_migrate", orgID, migration.Stream, map[string]any{
+			"changes": migration.Changes,
+			"copy":    migration.Copy,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, migration)
+}
+
+func writeMigrateStreamError(w http.ResponseWriter, orgID string, plan *nats.StreamMigration, err error) {
+	switch {
+	case errors.Is(err, nats.ErrInvalidStreamSettings):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, nats.ErrStreamDataLoss):
+		writeJSON(w, http.StatusConflict, map[string]any{
+			"error": "migration would discard stored messages; set allow_data_loss to proceed",
+			"plan":  plan,
+		})
+	default:
+		slog.Error("failed to migrate org stream", "org_id", orgID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to migrate stream"})
+	}
+}
+
 // UpdateLimitsRequest is the request body for updating org limits.
 type UpdateLimitsRequest struct {
 	BillingTier string `json:"billing_tier"`
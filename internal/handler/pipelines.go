@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/filipexyz/notif/internal/audit"
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/pipeline"
+	"github.com/go-chi/chi/v5"
+)
+
+// PipelineHandler handles the transformation pipelines applied at emit.
+type PipelineHandler struct {
+	registry *pipeline.Registry
+	auditLog *audit.Logger
+}
+
+// NewPipelineHandler creates a new PipelineHandler.
+func NewPipelineHandler(registry *pipeline.Registry, auditLog *audit.Logger) *PipelineHandler {
+	return &PipelineHandler{registry: registry, auditLog: auditLog}
+}
+
+// CreatePipelineRequest is the request body for creating a pipeline.
+type CreatePipelineRequest struct {
+	Name string `json:"name"`
+	// TopicPattern defaults to every topic (">").
+	TopicPattern string   `json:"topic_pattern"`
+	Redact       []string `json:"redact"`
+	Jq           string   `json:"jq"`
+	// Position defaults to after the project's existing pipelines.
+	Position *int  `json:"position"`
+	Enabled  *bool `json:"enabled"`
+}
+
+// UpdatePipelineRequest changes the fields that are present.
+type UpdatePipelineRequest struct {
+	TopicPattern *string  `json:"topic_pattern"`
+	Redact       []string `json:"redact"`
+	Jq           *string  `json:"jq"`
+	Position     *int     `json:"position"`
+	Enabled      *bool    `json:"enabled"`
+}
+
+// Create handles POST /api/v1/pipelines.
+func (h *PipelineHandler) Create(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var req CreatePipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON payload"})
+		return
+	}
+
+	p := &pipeline.Pipeline{
+		OrgID:        authCtx.OrgID,
+		ProjectID:    authCtx.ProjectID,
+		Name:         req.Name,
+		TopicPattern: req.TopicPattern,
+		Redact:       req.Redact,
+		Jq:           req.Jq,
+		Position:     -1,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}
+	if p.TopicPattern == "" {
+		p.TopicPattern = ">"
+	}
+	if req.Position != nil {
+		if *req.Position < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "position must not be negative"})
+			return
+		}
+		p.Position = *req.Position
+	}
+	if err := p.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	created, err := h.registry.Create(r.Context(), p)
+	if errors.Is(err, pipeline.ErrExists) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "pipeline already exists"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create pipeline"})
+		return
+	}
+
+	h.audit(r, authCtx, "pipeline.create", created)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// List handles GET /api/v1/pipelines, in the order pipelines apply.
+func (h *PipelineHandler) List(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	pipelines, err := h.registry.List(r.Context(), authCtx.ProjectID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to list pipelines"})
+		return
+	}
+	if pipelines == nil {
+		pipelines = []*pipeline.Pipeline{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pipelines": pipelines,
+		"count":     len(pipelines),
+	})
+}
+
+// Get handles GET /api/v1/pipelines/{name}.
+func (h *PipelineHandler) Get(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	p, ok := h.lookup(w, r, authCtx.ProjectID)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// Update handles PUT /api/v1/pipelines/{name}.
+func (h *PipelineHandler) Update(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var req UpdatePipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON payload"})
+		return
+	}
+
+	p, ok := h.lookup(w, r, authCtx.ProjectID)
+	if !ok {
+		return
+	}
+	if req.TopicPattern != nil {
+		p.TopicPattern = *req.TopicPattern
+	}
+	if req.Redact != nil {
+		p.Redact = req.Redact
+	}
+	if req.Jq != nil {
+		p.Jq = *req.Jq
+	}
+	if req.Position != nil {
+		if *req.Position < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "position must not be negative"})
+			return
+		}
+		p.Position = *req.Position
+	}
+	if req.Enabled != nil {
+		p.Enabled = *req.Enabled
+	}
+	if err := p.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.registry.Update(r.Context(), p)
+	if errors.Is(err, pipeline.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "pipeline not found"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update pipeline"})
+		return
+	}
+
+	h.audit(r, authCtx, "pipeline.update", updated)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// Delete handles DELETE /api/v1/pipelines/{name}.
+func (h *PipelineHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	p, ok := h.lookup(w, r, authCtx.ProjectID)
+	if !ok {
+		return
+	}
+	err := h.registry.Delete(r.Context(), authCtx.ProjectID, p.Name)
+	if errors.Is(err, pipeline.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "pipeline not found"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete pipeline"})
+		return
+	}
+
+	h.audit(r, authCtx, "pipeline.delete", p)
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// lookup loads the project's pipeline named in the URL, writing the error
+// response when there is none.
+func (h *PipelineHandler) lookup(w http.ResponseWriter, r *http.Request, projectID string) (*pipeline.Pipeline, bool) {
+	p, err := h.registry.Get(r.Context(), projectID, chi.URLParam(r, "name"))
+	if errors.Is(err, pipeline.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "pipeline not found"})
+		return nil, false
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get pipeline"})
+		return nil, false
+	}
+	return p, true
+}
+
+func (h *PipelineHandler) audit(r *http.Request, authCtx *middleware.AuthContext, action string, p *pipeline.Pipeline) {
+	if h.auditLog == nil {
+		return
+	}
+	ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
+	h.auditLog.Log(ctx, auditActor(authCtx), action, authCtx.OrgID, p.Name, map[string]any{
+		"project_id":    p.ProjectID,
+		"topic_pattern": p.TopicPattern,
+		"redact":        p.Redact,
+		"jq":            p.Jq,
+		"position":      p.Position,
+		"enabled":       p.Enabled,
+	})
+}
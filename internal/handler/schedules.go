@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -20,6 +21,9 @@ import (
 type SchedulesHandler struct {
 	queries   *db.Queries
 	scheduler *scheduler.Worker
+
+	// maxLeadTime caps how far ahead events can be scheduled (0 = unlimited)
+	maxLeadTime time.Duration
 }
 
 // NewSchedulesHandler creates a new SchedulesHandler.
@@ -30,12 +34,28 @@ func NewSchedulesHandler(queries *db.Queries, scheduler *scheduler.Worker) *Sche
 	}
 }
 
+// SetMaxLeadTime rejects schedules more than d in the future; 0 lifts the
+// limit.
+func (h *SchedulesHandler) SetMaxLeadTime(d time.Duration) {
+	h.maxLeadTime = d
+}
+
 // CreateScheduleRequest is the request body for POST /schedules.
 type CreateScheduleRequest struct {
 	Topic        string          `json:"topic"`
 	Data         json.RawMessage `json:"data"`
 	ScheduledFor *time.Time      `json:"scheduled_for,omitempty"`
 	In           string          `json:"in,omitempty"`
+	// MaxAttempts is how many times a failing fire is tried before the
+	// schedule is marked failed (default 3, max 10).
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// Cron makes the schedule recurring: after each run it is re-armed for
+	// the next match of this five-field expression (UTC). Without
+	// scheduled_for or in, the first run is the next match.
+	Cron string `json:"cron,omitempty"`
+	// MaxOccurrences stops a recurring schedule after that many runs
+	// (0 = unlimited).
+	MaxOccurrences int `json:"max_occurrences,omitempty"`
 }
 
 // CreateScheduleResponse is the response body for POST /schedules.
@@ -43,6 +63,7 @@ type CreateScheduleResponse struct {
 	ID           string    `json:"id"`
 	Topic        string    `json:"topic"`
 	ScheduledFor time.Time `json:"scheduled_for"`
+	Cron         string    `json:"cron,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
@@ -53,9 +74,18 @@ type ScheduleResponse struct {
 	Data         json.RawMessage `json:"data"`
 	ScheduledFor time.Time       `json:"scheduled_for"`
 	Status       string          `json:"status"`
+	Attempts     int32           `json:"attempts"`
+	MaxAttempts  int32           `json:"max_attempts"`
 	Error        *string         `json:"error,omitempty"`
 	CreatedAt    time.Time       `json:"created_at"`
 	ExecutedAt   *time.Time      `json:"executed_at,omitempty"`
+
+	// Recurring schedules only
+	Cron           string     `json:"cron,omitempty"`
+	NextRun        *time.Time `json:"next_run,omitempty"`
+	LastRun        *time.Time `json:"last_run,omitempty"`
+	Occurrences    int32      `json:"occurrences,omitempty"`
+	MaxOccurrences int32      `json:"max_occurrences,omitempty"`
 }
 
 // RunScheduleResponse is the response body for POST /schedules/:id/run.
@@ -83,6 +113,23 @@ func (h *SchedulesHandler) Create(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
+	if err := validateData(req.Data); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var cron *scheduler.CronSchedule
+	if req.Cron != "" {
+		var err error
+		if cron, err = scheduler.ParseCron(req.Cron); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cron: " + err.Error()})
+			return
+		}
+	}
+	if req.MaxOccurrences < 0 || (req.MaxOccurrences > 0 && cron == nil) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "max_occurrences must be positive and requires cron"})
+		return
+	}
 
 	// Calculate scheduled_for
 	var scheduledFor time.Time
@@ -95,14 +142,31 @@ func (h *SchedulesHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		scheduledFor = time.Now().Add(duration)
+	} else if cron != nil {
+		if scheduledFor = cron.Next(time.Now()); scheduledFor.IsZero() {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cron: expression never matches"})
+			return
+		}
 	} else {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "scheduled_for or in is required"})
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "scheduled_for, in or cron is required"})
 		return
 	}
 
-	// Validate scheduled_for is in the future
-	if scheduledFor.Before(time.Now()) {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "scheduled_for must be in the future"})
+	if errMsg := validateScheduleTime(scheduledFor, time.Now(), h.maxLeadTime); errMsg != "" {
+		resp := map[string]string{"error": errMsg}
+		if h.maxLeadTime > 0 {
+			resp["max_lead_time"] = h.maxLeadTime.String()
+		}
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+
+	maxAttempts := req.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = scheduler.DefaultMaxAttempts
+	}
+	if maxAttempts < 1 || maxAttempts > scheduler.MaxAttemptsLimit {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "max_attempts must be between 1 and 10"})
 		return
 	}
 
@@ -117,13 +181,16 @@ func (h *SchedulesHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Create scheduled event
 	sch, err := h.queries.CreateScheduledEvent(r.Context(), db.CreateScheduledEventParams{
-		ID:           id,
-		OrgID:        authCtx.OrgID,
-		ProjectID:    pgtype.Text{String: authCtx.ProjectID, Valid: authCtx.ProjectID != ""},
-		Topic:        req.Topic,
-		Data:         req.Data,
-		ScheduledFor: pgtype.Timestamptz{Time: scheduledFor, Valid: true},
-		ApiKeyID:     apiKeyID,
+		ID:             id,
+		OrgID:          authCtx.OrgID,
+		ProjectID:      pgtype.Text{String: authCtx.ProjectID, Valid: authCtx.ProjectID != ""},
+		Topic:          req.Topic,
+		Data:           req.Data,
+		ScheduledFor:   pgtype.Timestamptz{Time: scheduledFor, Valid: true},
+		ApiKeyID:       apiKeyID,
+		MaxAttempts:    int32(maxAttempts),
+		Cron:           pgtype.Text{String: req.Cron, Valid: cron != nil},
+		MaxOccurrences: int32(req.MaxOccurrences),
 	})
 	if err != nil {
 		slog.Error("failed to create scheduled event", "error", err)
@@ -135,12 +202,14 @@ func (h *SchedulesHandler) Create(w http.ResponseWriter, r *http.Request) {
 		"id", sch.ID,
 		"topic", sch.Topic,
 		"scheduled_for", scheduledFor,
+		"cron", req.Cron,
 	)
 
 	writeJSON(w, http.StatusCreated, CreateScheduleResponse{
 		ID:           sch.ID,
 		Topic:        sch.Topic,
 		ScheduledFor: sch.ScheduledFor.Time,
+		Cron:         sch.Cron.String,
 		CreatedAt:    sch.CreatedAt.Time,
 	})
 }
@@ -329,6 +398,8 @@ func scheduleToResponse(sch db.ScheduledEvent) ScheduleResponse {
 		Data:         sch.Data,
 		ScheduledFor: sch.ScheduledFor.Time,
 		Status:       sch.Status,
+		Attempts:     sch.Attempts,
+		MaxAttempts:  sch.MaxAttempts,
 		CreatedAt:    sch.CreatedAt.Time,
 	}
 	if sch.Error.Valid {
@@ -337,9 +408,33 @@ func scheduleToResponse(sch db.ScheduledEvent) ScheduleResponse {
 	if sch.ExecutedAt.Valid {
 		resp.ExecutedAt = &sch.ExecutedAt.Time
 	}
+	if sch.Cron.Valid {
+		resp.Cron = sch.Cron.String
+		resp.Occurrences = sch.Occurrences
+		resp.MaxOccurrences = sch.MaxOccurrences
+		if sch.Status == "pending" {
+			resp.NextRun = &sch.ScheduledFor.Time
+		}
+		if sch.LastRunAt.Valid {
+			resp.LastRun = &sch.LastRunAt.Time
+		}
+	}
 	return resp
 }
 
+// validateScheduleTime checks that scheduledFor is in the future and at
+// most maxLead (0 = unlimited) after now. It returns the error message, or
+// "" when the time is acceptable.
+func validateScheduleTime(scheduledFor, now time.Time, maxLead time.Duration) string {
+	if scheduledFor.Before(now) {
+		return "scheduled_for must be in the future"
+	}
+	if maxLead > 0 && scheduledFor.Sub(now) > maxLead {
+		return fmt.Sprintf("scheduled_for must be at most %s ahead", maxLead)
+	}
+	return ""
+}
+
 func generateScheduleID() string {
 	b := make([]byte, 12)
 	rand.Read(b)
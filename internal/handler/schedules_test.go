@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/middleware"
+)
+
+func TestValidateScheduleTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	year := 365 * 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		maxLead time.Duration
+		valid   bool
+	}{
+		{"soon", now.Add(time.Minute), year, true},
+		{"at the limit", now.Add(year), year, true},
+		{"beyond the limit", now.Add(year + time.Second), year, false},
+		{"past", now.Add(-time.Second), year, false},
+		{"unlimited", now.Add(10 * year), 0, true},
+		{"past unlimited", now.Add(-time.Second), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validateScheduleTime(tt.at, now, tt.maxLead)
+			if (msg == "") != tt.valid {
+				t.Fatalf("validateScheduleTime(%s) = %q, want valid=%v", tt.at.Sub(now), msg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestSchedulesCreate_BeyondMaxLeadTime(t *testing.T) {
+	h := NewSchedulesHandler(nil, nil)
+	h.SetMaxLeadTime(24 * time.Hour)
+
+	body := `{"topic":"orders.reminder","data":{},"in":"25h"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", strings.NewReader(body))
+	req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{
+		OrgID:     "org_test",
+		ProjectID: "prj_test",
+	}))
+	rec := httptest.NewRecorder()
+	h.Create(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["max_lead_time"] != "24h0m0s" {
+		t.Errorf("max_lead_time = %q, want 24h0m0s (body %v)", resp["max_lead_time"], resp)
+	}
+}
+
+func TestSchedulesCreate_InvalidCron(t *testing.T) {
+	h := NewSchedulesHandler(nil, nil)
+
+	for _, body := range []string{
+		`{"topic":"reports.daily","data":{},"cron":"0 25 * * *"}`,
+		`{"topic":"reports.daily","data":{},"cron":"0 0 30 2 *"}`,
+		`{"topic":"reports.daily","data":{},"in":"1h","max_occurrences":3}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", strings.NewReader(body))
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{
+			OrgID:     "org_test",
+			ProjectID: "prj_test",
+		}))
+		rec := httptest.NewRecorder()
+		h.Create(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, rec.Code)
+		}
+	}
+}
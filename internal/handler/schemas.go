@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/filipexyz/notif/internal/middleware"
@@ -73,7 +74,7 @@ func (h *SchemaHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetSchema handles GET /api/v1/schemas/{name}
+// GetSchema handles GET /api/v1/schemas/{name}[?bundle=true]
 func (h *SchemaHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	auth := middleware.GetAuthContext(ctx)
@@ -94,6 +95,18 @@ func (h *SchemaHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ?bundle=true inlines notif:// refs into the latest version's schema
+	if r.URL.Query().Get("bundle") == "true" && s.LatestVersion != nil {
+		bundled, err := h.registry.Bundle(ctx, auth.ProjectID, s.LatestVersion.SchemaJSON)
+		if err != nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		latest := *s.LatestVersion
+		latest.SchemaJSON = bundled
+		s.LatestVersion = &latest
+	}
+
 	writeJSON(w, http.StatusOK, s)
 }
 
@@ -206,6 +219,15 @@ func (h *SchemaHandler) CreateVersion(w http.ResponseWriter, r *http.Request) {
 		createdBy = *auth.UserID
 	}
 	v, err := h.registry.CreateVersion(ctx, existing.ID, &req, createdBy)
+	var compatErr *schema.CompatibilityError
+	if errors.As(err, &compatErr) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"error":            compatErr.Error(),
+			"compatibility":    compatErr.Mode,
+			"breaking_changes": compatErr.Changes,
+		})
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to create schema version"})
 		return
@@ -280,6 +302,47 @@ func (h *SchemaHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, v)
 }
 
+// PinVersion handles POST /api/v1/schemas/{name}/versions/{version}/pin
+func (h *SchemaHandler) PinVersion(w http.ResponseWriter, r *http.Request) {
+	h.setVersionPinned(w, r, true)
+}
+
+// UnpinVersion handles DELETE /api/v1/schemas/{name}/versions/{version}/pin
+func (h *SchemaHandler) UnpinVersion(w http.ResponseWriter, r *http.Request) {
+	h.setVersionPinned(w, r, false)
+}
+
+func (h *SchemaHandler) setVersionPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	ctx := r.Context()
+	auth := middleware.GetAuthContext(ctx)
+	if auth == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	version := chi.URLParam(r, "version")
+	if name == "" || version == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name and version are required"})
+		return
+	}
+
+	// Get existing schema
+	existing, err := h.registry.GetSchemaByName(ctx, auth.ProjectID, name)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "schema not found"})
+		return
+	}
+
+	v, err := h.registry.SetVersionPinned(ctx, existing.ID, version, pinned)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "version not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
 // Validate handles POST /api/v1/schemas/{name}/validate
 func (h *SchemaHandler) Validate(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -318,6 +381,36 @@ func (h *SchemaHandler) Validate(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+// GetStats handles GET /api/v1/schemas/{name}/stats
+func (h *SchemaHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	auth := middleware.GetAuthContext(ctx)
+	if auth == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+
+	existing, err := h.registry.GetSchemaByName(ctx, auth.ProjectID, name)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "schema not found"})
+		return
+	}
+
+	stats, err := h.registry.ValidationStats(ctx, existing)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get validation stats"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
 // GetSchemaForTopic handles GET /api/v1/schemas/for-topic/{topic}
 func (h *SchemaHandler) GetSchemaForTopic(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
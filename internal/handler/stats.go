@@ -1,18 +1,23 @@
 package handler
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/eventstore"
 	"github.com/filipexyz/notif/internal/middleware"
 	"github.com/filipexyz/notif/internal/nats"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
 // StatsHandler handles stats endpoints.
 type StatsHandler struct {
 	queries     *db.Queries
+	events      eventstore.Store
 	eventReader *nats.EventReader
 	dlqReader   *nats.DLQReader
 }
@@ -21,11 +26,17 @@ type StatsHandler struct {
 func NewStatsHandler(queries *db.Queries, eventReader *nats.EventReader, dlqReader *nats.DLQReader) *StatsHandler {
 	return &StatsHandler{
 		queries:     queries,
+		events:      eventstore.NewPostgres(queries),
 		eventReader: eventReader,
 		dlqReader:   dlqReader,
 	}
 }
 
+// SetEventStore replaces the Postgres store used for event totals.
+func (h *StatsHandler) SetEventStore(events eventstore.Store) {
+	h.events = events
+}
+
 // OverviewResponse is the response for stats overview.
 type OverviewResponse struct {
 	Events   EventsOverview   `json:"events"`
@@ -65,11 +76,8 @@ func (h *StatsHandler) Overview(w http.ResponseWriter, r *http.Request) {
 	resp := OverviewResponse{}
 
 	// Events stats from database (project-scoped)
-	if count, err := h.queries.CountEventsByProject(r.Context(), db.CountEventsByProjectParams{
-		OrgID:     authCtx.OrgID,
-		ProjectID: pgtype.Text{String: authCtx.ProjectID, Valid: authCtx.ProjectID != ""},
-	}); err == nil {
-		resp.Events.Total = uint64(count)
+	if stats, err := h.events.Aggregate(r.Context(), authCtx.OrgID, authCtx.ProjectID); err == nil {
+		resp.Events.Total = uint64(stats.Total)
 	}
 
 	// Webhook stats
@@ -99,11 +107,14 @@ type EventsStatsResponse struct {
 }
 
 type StreamStats struct {
+	Name      string `json:"name"`
 	Messages  uint64 `json:"messages"`
 	Bytes     uint64 `json:"bytes"`
 	Consumers int    `json:"consumers"`
 	FirstSeq  uint64 `json:"first_seq"`
 	LastSeq   uint64 `json:"last_seq"`
+	// MaxBytes is the stream's storage limit (-1 = unlimited).
+	MaxBytes int64 `json:"max_bytes"`
 }
 
 // Events returns event/stream stats (org-scoped).
@@ -114,26 +125,57 @@ func (h *StatsHandler) Events(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := h.queries.GetEventStats(r.Context(), orgID)
+	stats, err := h.events.Aggregate(r.Context(), orgID, "")
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get event stats"})
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"total":    stats.Total,
-		"last_24h": stats.Last24h,
-		"last_1h":  stats.LastHour,
+		"total":            stats.Total,
+		"last_24h":         stats.Last24h,
+		"last_1h":          stats.LastHour,
+		"redeliveries_24h": h.redeliveryStats(r.Context(), orgID, "websocket"),
+	})
+}
+
+// RedeliveryStats summarizes how often deliveries needed more than one
+// attempt. Completed counts final outcomes (acked, dlq or dropped).
+type RedeliveryStats struct {
+	Completed      int64   `json:"completed"`
+	Redelivered    int64   `json:"redelivered"`
+	Redeliveries   int64   `json:"redeliveries"`
+	RedeliveryRate float64 `json:"redelivery_rate"`
+}
+
+// redeliveryStats returns the last 24h of redelivery stats for one receiver
+// type. Errors yield zero stats, like the other optional stats sections.
+func (h *StatsHandler) redeliveryStats(ctx context.Context, orgID, receiverType string) RedeliveryStats {
+	var stats RedeliveryStats
+	row, err := h.queries.GetRedeliveryStats(ctx, db.GetRedeliveryStatsParams{
+		OrgID:        orgID,
+		ReceiverType: receiverType,
 	})
+	if err != nil {
+		return stats
+	}
+	stats.Completed = row.Completed
+	stats.Redelivered = row.Redelivered
+	stats.Redeliveries = row.Redeliveries
+	if row.Completed > 0 {
+		stats.RedeliveryRate = float64(row.Redelivered) / float64(row.Completed)
+	}
+	return stats
 }
 
 // WebhooksStatsResponse is the response for webhook stats.
 type WebhooksStatsResponse struct {
-	Total       int64           `json:"total"`
-	Enabled     int64           `json:"enabled"`
-	Disabled    int64           `json:"disabled"`
-	Deliveries  DeliveryStats   `json:"deliveries_24h"`
-	ByWebhook   []WebhookStats  `json:"by_webhook"`
+	Total        int64           `json:"total"`
+	Enabled      int64           `json:"enabled"`
+	Disabled     int64           `json:"disabled"`
+	Deliveries   DeliveryStats   `json:"deliveries_24h"`
+	Redeliveries RedeliveryStats `json:"redeliveries_24h"`
+	ByWebhook    []WebhookStats  `json:"by_webhook"`
 }
 
 type DeliveryStats struct {
@@ -183,6 +225,8 @@ func (h *StatsHandler) Webhooks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	resp.Redeliveries = h.redeliveryStats(r.Context(), orgID, "webhook")
+
 	// Per-webhook stats
 	if byWebhook, err := h.queries.GetWebhookDeliveryStatsByWebhook(r.Context(), orgIDParam); err == nil {
 		resp.ByWebhook = make([]WebhookStats, len(byWebhook))
@@ -226,3 +270,46 @@ func (h *StatsHandler) DLQ(w http.ResponseWriter, r *http.Request) {
 		Total: count,
 	})
 }
+
+// StreamsStatsResponse is the response for stream storage stats.
+type StreamsStatsResponse struct {
+	Streams []StreamStats `json:"streams"`
+}
+
+// Streams returns storage usage of the events and DLQ streams. Streams are
+// shared by every project in the org, so this is not project-scoped.
+func (h *StatsHandler) Streams(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "org_id required"})
+		return
+	}
+
+	resp := StreamsStatsResponse{Streams: []StreamStats{}}
+	for _, infoFn := range []func(context.Context) (*jetstream.StreamInfo, error){
+		h.eventReader.StreamInfo,
+		h.dlqReader.StreamInfo,
+	} {
+		info, err := infoFn(r.Context())
+		if err != nil {
+			slog.Error("failed to get stream info", "error", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get stream stats"})
+			return
+		}
+		resp.Streams = append(resp.Streams, newStreamStats(info))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func newStreamStats(info *jetstream.StreamInfo) StreamStats {
+	return StreamStats{
+		Name:      info.Config.Name,
+		Messages:  info.State.Msgs,
+		Bytes:     info.State.Bytes,
+		Consumers: info.State.Consumers,
+		FirstSeq:  info.State.FirstSeq,
+		LastSeq:   info.State.LastSeq,
+		MaxBytes:  info.Config.MaxBytes,
+	}
+}
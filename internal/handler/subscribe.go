@@ -4,14 +4,18 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 
 	"github.com/filipexyz/notif/internal/audit"
 	"github.com/filipexyz/notif/internal/config"
 	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
 	"github.com/filipexyz/notif/internal/middleware"
 	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/schema"
 	"github.com/filipexyz/notif/internal/websocket"
 	"github.com/google/uuid"
 	ws "github.com/gorilla/websocket"
@@ -21,6 +25,9 @@ func newUpgrader(allowedOrigins []string) ws.Upgrader {
 	return ws.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
+		// permessage-deflate is negotiated: clients that don't offer it
+		// get uncompressed frames
+		EnableCompression: true,
 		CheckOrigin: func(r *http.Request) bool {
 			origin := r.Header.Get("Origin")
 			if origin == "" {
@@ -45,6 +52,8 @@ type SubscribeHandler struct {
 	cfg          *config.Config
 	upgrader     ws.Upgrader
 	auditLog     *audit.Logger
+	emit         *EmitHandler
+	schemas      *schema.Registry
 }
 
 // NewSubscribeHandler creates a new SubscribeHandler.
@@ -60,6 +69,45 @@ func NewSubscribeHandler(hub *websocket.Hub, consumerMgr *nats.ConsumerManager,
 	}
 }
 
+// SetEmitHandler lets connections publish events with the "emit" action,
+// through the same checks as POST /emit.
+func (h *SubscribeHandler) SetEmitHandler(emit *EmitHandler) {
+	h.emit = emit
+}
+
+// SetSchemaRegistry enables the display_config subscribe option, pushing
+// schema display configs from registry.
+func (h *SubscribeHandler) SetSchemaRegistry(registry *schema.Registry) {
+	h.schemas = registry
+}
+
+// displayConfigSource reads display configs from the schemas' latest
+// versions, skipping schemas without one.
+func displayConfigSource(registry *schema.Registry) websocket.DisplayConfigSource {
+	return func(ctx context.Context, projectID string, topics []string) ([]websocket.DisplayConfig, error) {
+		schemas, err := registry.SchemasForTopics(ctx, projectID, topics)
+		if err != nil {
+			return nil, err
+		}
+		var configs []websocket.DisplayConfig
+		for _, s := range schemas {
+			if s.LatestVersion == nil {
+				continue
+			}
+			display := schema.ExtractDisplay(s.LatestVersion.SchemaJSON)
+			if display == nil {
+				continue
+			}
+			configs = append(configs, websocket.DisplayConfig{
+				Schema:       s.Name,
+				TopicPattern: s.TopicPattern,
+				Display:      display,
+			})
+		}
+		return configs, nil
+	}
+}
+
 // generateClientID creates a unique client identifier.
 func generateClientID() string {
 	b := make([]byte, 8)
@@ -69,16 +117,30 @@ func generateClientID() string {
 
 // Subscribe upgrades HTTP to WebSocket and handles subscriptions.
 func (h *SubscribeHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
-	conn, err := h.upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		slog.Error("websocket upgrade failed", "error", err)
-		return
-	}
-
 	apiKey := middleware.GetAPIKey(r.Context())
 	apiKeyID := ""
+	maxConns := h.cfg.WSMaxConnectionsPerKey
 	if apiKey != nil {
 		apiKeyID = uuid.UUID(apiKey.ID.Bytes).String()
+		if apiKey.MaxConnections.Valid {
+			maxConns = int(apiKey.MaxConnections.Int32)
+		}
+	}
+
+	// Reserve a slot before upgrading so the client gets a plain HTTP error
+	if !h.hub.AcquireConn(apiKeyID, maxConns) {
+		slog.Warn("websocket connection limit reached", "api_key_id", apiKeyID, "limit", maxConns)
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{
+			"error": fmt.Sprintf("connection limit reached: this API key allows %d concurrent connections", maxConns),
+		})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.hub.ReleaseConn(apiKeyID)
+		slog.Error("websocket upgrade failed", "error", err)
+		return
 	}
 
 	// Get org_id and project_id from auth context for multi-tenant isolation
@@ -92,6 +154,22 @@ func (h *SubscribeHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 
 	clientID := generateClientID()
 	client := websocket.NewClient(h.hub, conn, apiKeyID, orgID, projectID, h.dlqPublisher, h.queries, clientID, h.cfg.MaxPayloadSize)
+	client.SetMaxSubscriptions(h.cfg.MaxSubscriptionsPerProject)
+	client.SetGroupLimits(h.cfg.ConsumerGroupMaxMembers, h.cfg.ConsumerGroupMemberMaxInflight)
+	client.SetDLQPolicies(h.cfg.DLQPolicies)
+	if apiKey != nil && slices.Contains(apiKey.Scopes, domain.ScopeAdmin) {
+		// An admin key's own project is always among those it may span
+		client.SetAuthorizedProjects(append([]string{apiKey.ProjectID}, apiKey.AuthorizedProjects...))
+	}
+	if h.emit != nil && (apiKey == nil || domain.HasScope(apiKey.Scopes, domain.ScopeEmit)) {
+		client.SetEmitter(h.emit.WebSocketEmitter(r))
+	}
+	if h.schemas != nil {
+		client.SetDisplayConfigSource(displayConfigSource(h.schemas))
+	}
+	if authCtx != nil && authCtx.ExpiresAt != nil {
+		client.SetAuthExpiry(*authCtx.ExpiresAt)
+	}
 	h.hub.Register(client)
 
 	slog.Info("websocket client connected", "client_id", clientID)
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/websocket"
+	ws "github.com/gorilla/websocket"
+)
+
+// countingConn counts the bytes read off the wire.
+type countingConn struct {
+	net.Conn
+	read *atomic.Int64
+}
+
+func (c countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+// typicalEventFrame is an event frame of the size most subscribers see.
+func typicalEventFrame(t testing.TB) []byte {
+	data := json.RawMessage(`{"order_id":"ord_8f3a2c","customer":{"id":"cus_19d2","email":"jane@example.com","name":"Jane Doe"},"items":[{"sku":"SKU-1001","name":"Blue T-Shirt","quantity":2,"price":1999},{"sku":"SKU-2044","name":"Canvas Tote","quantity":1,"price":2499}],"currency":"USD","total":6497,"status":"paid"}`)
+	msg := websocket.NewEventMessage("evt_01HZX3Q9W8K2M4N6P8R0S2T4V6", "orders.created", data, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), 1, 5)
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal event: %v", err)
+	}
+	return b
+}
+
+// compressionServer upgrades with the subscribe handler's upgrader and
+// hands each connection to handle.
+func compressionServer(t testing.TB, handle func(*ws.Conn)) *httptest.Server {
+	upgrader := newUpgrader(nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dialCompression(t testing.TB, server *httptest.Server, compress bool, read *atomic.Int64) (*ws.Conn, *http.Response) {
+	dialer := ws.Dialer{
+		EnableCompression: compress,
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return countingConn{Conn: conn, read: read}, nil
+		},
+	}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return conn, resp
+}
+
+func TestUpgrader_Compression(t *testing.T) {
+	frame := typicalEventFrame(t)
+
+	for _, tc := range []struct {
+		name     string
+		compress bool
+	}{
+		{"negotiated", true},
+		{"plain client", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pong := make(chan string, 1)
+			server := compressionServer(t, func(conn *ws.Conn) {
+				conn.SetPongHandler(func(data string) error {
+					pong <- data
+					return nil
+				})
+				conn.WriteMessage(ws.TextMessage, frame)
+				conn.WriteControl(ws.PingMessage, []byte("hb"), time.Now().Add(time.Second))
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			})
+
+			var read atomic.Int64
+			conn, resp := dialCompression(t, server, tc.compress, &read)
+			defer conn.Close()
+
+			negotiated := strings.Contains(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+			if negotiated != tc.compress {
+				t.Fatalf("permessage-deflate negotiated = %v, want %v", negotiated, tc.compress)
+			}
+
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, got, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("read event: %v", err)
+			}
+			if string(got) != string(frame) {
+				t.Errorf("event frame changed in transit: %s", got)
+			}
+
+			// The ping is answered by the default handler during the next read
+			go conn.ReadMessage()
+			select {
+			case data := <-pong:
+				if data != "hb" {
+					t.Errorf("pong payload = %q, want %q", data, "hb")
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no pong for the server's ping")
+			}
+
+			if err := conn.WriteControl(ws.CloseMessage, ws.FormatCloseMessage(ws.CloseNormalClosure, ""), time.Now().Add(time.Second)); err != nil {
+				t.Errorf("close frame: %v", err)
+			}
+		})
+	}
+}
+
+// BenchmarkEventFrame_Compression reports the bytes on the wire per event
+// frame, with and without permessage-deflate.
+func BenchmarkEventFrame_Compression(b *testing.B) {
+	frame := typicalEventFrame(b)
+
+	for _, bc := range []struct {
+		name     string
+		compress bool
+	}{
+		{"plain", false},
+		{"deflate", true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			n := b.N
+			server := compressionServer(b, func(conn *ws.Conn) {
+				// Wait for the client, so the handshake isn't counted
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+				for i := 0; i < n; i++ {
+					if err := conn.WriteMessage(ws.TextMessage, frame); err != nil {
+						return
+					}
+				}
+				conn.ReadMessage()
+			})
+
+			var read atomic.Int64
+			conn, _ := dialCompression(b, server, bc.compress, &read)
+			defer conn.Close()
+			read.Store(0)
+			if err := conn.WriteMessage(ws.TextMessage, []byte("start")); err != nil {
+				b.Fatalf("start: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < n; i++ {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					b.Fatalf("read event: %v", err)
+				}
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(len(frame)), "json-bytes/op")
+			b.ReportMetric(float64(read.Load())/float64(n), "wire-bytes/op")
+		})
+	}
+}
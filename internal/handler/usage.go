@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/filipexyz/notif/internal/eventstore"
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/websocket"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// storageCacheTTL is how long storage usage is served from cache, so
+// polling clients don't hit NATS and Postgres on every request.
+const storageCacheTTL = 5 * time.Second
+
+// StreamSource resolves the readers for an org's events and DLQ streams.
+type StreamSource func(orgID string) (*nats.EventReader, *nats.DLQReader, error)
+
+// UsageHandler reports current resource usage against the server's caps.
+type UsageHandler struct {
+	hub *websocket.Hub
+	cfg *config.Config
+
+	events  eventstore.Store
+	streams StreamSource
+
+	storageMu    sync.Mutex
+	storageCache map[string]*StorageUsageResponse // by org/project
+}
+
+// NewUsageHandler creates a new UsageHandler.
+func NewUsageHandler(hub *websocket.Hub, cfg *config.Config) *UsageHandler {
+	return &UsageHandler{hub: hub, cfg: cfg, storageCache: make(map[string]*StorageUsageResponse)}
+}
+
+// SetStorageSource enables GET /usage/storage, reading stream info through
+// streams and persisted event sizes through events.
+func (h *UsageHandler) SetStorageSource(events eventstore.Store, streams StreamSource) {
+	h.events = events
+	h.streams = streams
+}
+
+// UsageLimit is a current count and its cap (0 = unlimited).
+type UsageLimit struct {
+	Active int `json:"active"`
+	Limit  int `json:"limit"`
+}
+
+// UsageResponse is the response for GET /usage.
+type UsageResponse struct {
+	ProjectID string `json:"project_id"`
+	// Subscriptions counts distinct active subscriptions in the project.
+	Subscriptions UsageLimit `json:"subscriptions"`
+	// Connections counts open WebSocket connections of the calling API key.
+	Connections *UsageLimit `json:"connections,omitempty"`
+}
+
+// Usage returns the project's active subscriptions and, for API key auth,
+// the key's open connections.
+func (h *UsageHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	resp := UsageResponse{
+		ProjectID: authCtx.ProjectID,
+		Subscriptions: UsageLimit{
+			Active: h.hub.SubscriptionCount(authCtx.ProjectID),
+			Limit:  h.cfg.MaxSubscriptionsPerProject,
+		},
+	}
+
+	if apiKey := middleware.GetAPIKey(r.Context()); apiKey != nil {
+		limit := h.cfg.WSMaxConnectionsPerKey
+		if apiKey.MaxConnections.Valid {
+			limit = int(apiKey.MaxConnections.Int32)
+		}
+		resp.Connections = &UsageLimit{
+			Active: h.hub.ConnCount(uuid.UUID(apiKey.ID.Bytes).String()),
+			Limit:  limit,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// StorageUsageResponse is the response for GET /usage/storage.
+type StorageUsageResponse struct {
+	ProjectID string `json:"project_id"`
+	// Streams are the org's JetStream streams, shared by all its projects.
+	Streams []StreamStats `json:"streams"`
+	// Messages counts the project's events still retained in the events
+	// stream.
+	Messages uint64 `json:"messages"`
+	// Events and EventBytes count the project's persisted event records and
+	// their payload bytes.
+	Events     int64 `json:"events"`
+	EventBytes int64 `json:"event_bytes"`
+	// AsOf is when the figures were read; they may be up to a few seconds old.
+	AsOf time.Time `json:"as_of"`
+}
+
+// Storage returns the project's storage usage: its org's stream sizes, its
+// retained stream messages, and its persisted event bytes.
+func (h *UsageHandler) Storage(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.OrgID == "" || authCtx.ProjectID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	if h.streams == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "storage usage is not available"})
+		return
+	}
+
+	key := authCtx.OrgID + "/" + authCtx.ProjectID
+	h.storageMu.Lock()
+	cached := h.storageCache[key]
+	h.storageMu.Unlock()
+	if cached != nil && time.Since(cached.AsOf) < storageCacheTTL {
+		writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	resp, err := h.readStorage(r.Context(), authCtx.OrgID, authCtx.ProjectID)
+	if err != nil {
+		slog.Error("failed to read storage usage", "error", err, "org_id", authCtx.OrgID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get storage usage"})
+		return
+	}
+
+	h.storageMu.Lock()
+	h.pruneStorageCache(time.Now())
+	h.storageCache[key] = resp
+	h.storageMu.Unlock()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// pruneStorageCache drops expired entries so the cache doesn't keep one per
+// project that ever asked. Callers hold storageMu.
+func (h *UsageHandler) pruneStorageCache(now time.Time) {
+	for key, cached := range h.storageCache {
+		if now.Sub(cached.AsOf) >= storageCacheTTL {
+			delete(h.storageCache, key)
+		}
+	}
+}
+
+func (h *UsageHandler) readStorage(ctx context.Context, orgID, projectID string) (*StorageUsageResponse, error) {
+	eventReader, dlqReader, err := h.streams(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &StorageUsageResponse{ProjectID: projectID, Streams: []StreamStats{}}
+	for _, infoFn := range []func(context.Context) (*jetstream.StreamInfo, error){
+		eventReader.StreamInfo,
+		dlqReader.StreamInfo,
+	} {
+		info, err := infoFn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp.Streams = append(resp.Streams, newStreamStats(info))
+	}
+
+	if resp.Messages, err = eventReader.ProjectMessages(ctx, orgID, projectID); err != nil {
+		return nil, err
+	}
+
+	stored, err := h.events.Aggregate(ctx, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	resp.Events = stored.Total
+	resp.EventBytes = stored.PayloadBytes
+	resp.AsOf = time.Now().UTC()
+	return resp, nil
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPruneStorageCache(t *testing.T) {
+	h := NewUsageHandler(nil, nil)
+	now := time.Now()
+	h.storageCache["org/stale"] = &StorageUsageResponse{AsOf: now.Add(-storageCacheTTL)}
+	h.storageCache["org/fresh"] = &StorageUsageResponse{AsOf: now.Add(-time.Second)}
+
+	h.pruneStorageCache(now)
+
+	if _, ok := h.storageCache["org/stale"]; ok {
+		t.Error("expired entry was not evicted")
+	}
+	if _, ok := h.storageCache["org/fresh"]; !ok {
+		t.Error("fresh entry was evicted")
+	}
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/filipexyz/notif/internal/version"
+)
+
+// VersionHandler reports the server's build, so operators can confirm what
+// is deployed.
+type VersionHandler struct{}
+
+// NewVersionHandler creates a new VersionHandler.
+func NewVersionHandler() *VersionHandler {
+	return &VersionHandler{}
+}
+
+// Version handles GET /version.
+func (h *VersionHandler) Version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, version.Get())
+}
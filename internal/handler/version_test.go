@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/version"
+)
+
+func TestVersion_ReportsInjectedBuild(t *testing.T) {
+	prev := version.Get()
+	version.Version, version.Commit, version.BuildTime = "1.4.2", "abc1234", "2024-05-01T12:00:00Z"
+	t.Cleanup(func() { version.Version, version.Commit, version.BuildTime = prev.Version, prev.Commit, prev.BuildTime })
+
+	rec := httptest.NewRecorder()
+	NewVersionHandler().Version(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var got version.Info
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := version.Info{Version: "1.4.2", Commit: "abc1234", BuildTime: "2024-05-01T12:00:00Z", GoVersion: runtime.Version()}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -4,21 +4,36 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/filipexyz/notif/internal/audit"
 	"github.com/filipexyz/notif/internal/db"
 	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/nats"
 	"github.com/filipexyz/notif/internal/security"
+	"github.com/filipexyz/notif/internal/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
+// RetrySource resolves an org's events stream, where the original events
+// of deliveries are looked up, and the JetStream retry jobs go to.
+type RetrySource func(orgID string) (*nats.EventReader, jetstream.JetStream, error)
+
 // WebhookHandler handles webhook CRUD operations.
 type WebhookHandler struct {
 	queries  *db.Queries
 	auditLog *audit.Logger
+	secrets  *security.SecretBox
+	retries  RetrySource
 }
 
 // NewWebhookHandler creates a new WebhookHandler.
@@ -26,10 +41,52 @@ func NewWebhookHandler(queries *db.Queries, auditLog *audit.Logger) *WebhookHand
 	return &WebhookHandler{queries: queries, auditLog: auditLog}
 }
 
+// SetSecretBox sets the key that encrypts secret-looking header values.
+func (h *WebhookHandler) SetSecretBox(box *security.SecretBox) {
+	h.secrets = box
+}
+
+// SetRetrySource enables POST /webhooks/{id}/deliveries/{delivery_id}/retry.
+func (h *WebhookHandler) SetRetrySource(retries RetrySource) {
+	h.retries = retries
+}
+
 // CreateWebhookRequest is the request body for creating a webhook.
 type CreateWebhookRequest struct {
-	URL    string   `json:"url"`
-	Topics []string `json:"topics"`
+	URL         string   `json:"url"`
+	Topics      []string `json:"topics"`
+	RetryBudget string   `json:"retry_budget,omitempty"` // e.g. "6h"; failing deliveries go to the DLQ after this
+
+	// BodyEncoding is "json" (default) or "form"; ContentType defaults to
+	// the encoding's standard type.
+	ContentType  string `json:"content_type,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+
+	// PayloadMode is "envelope" (default) or "raw", which sends only the
+	// event data as the body with its metadata in headers.
+	PayloadMode string `json:"payload_mode,omitempty"`
+
+	// Ordered delivers events one at a time in emit order; a failing event
+	// holds back the ones behind it until it succeeds or gives up.
+	Ordered bool `json:"ordered,omitempty"`
+
+	// Headers are static headers added to every delivery, e.g.
+	// Authorization. X-Notif-* and Content-Type are reserved.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// TenantField is a dot path into the event data, e.g. "tenant". Events
+	// whose tenant has an entry in TenantSecrets are signed with that secret
+	// instead of the webhook's own.
+	TenantField   string            `json:"tenant_field,omitempty"`
+	TenantSecrets map[string]string `json:"tenant_secrets,omitempty"`
+
+	// RetryPolicy overrides the default retry count and backoff, e.g.
+	// {"max_retries": 8, "backoff": ["5s", "1m", "10m"]}.
+	RetryPolicy *webhook.RetryPolicy `json:"retry_policy,omitempty"`
+
+	// FallbackURLs are tried in order, within the same attempt, when URL
+	// fails. The attempt only counts as failed when all of them fail.
+	FallbackURLs []string `json:"fallback_urls,omitempty"`
 }
 
 // WebhookResponse is the response for a webhook.
@@ -37,9 +94,30 @@ type WebhookResponse struct {
 	ID        string   `json:"id"`
 	URL       string   `json:"url"`
 	Topics    []string `json:"topics"`
-	Secret    string   `json:"secret,omitempty"` // Only returned on create
+	Secret    string   `json:"secret,omitempty"` // Only returned on create and rotate
 	Enabled   bool     `json:"enabled"`
 	CreatedAt string   `json:"created_at"`
+
+	RetryBudget string `json:"retry_budget"`
+
+	ContentType  string `json:"content_type"`
+	BodyEncoding string `json:"body_encoding"`
+	PayloadMode  string `json:"payload_mode"`
+	Ordered      bool   `json:"ordered"`
+
+	// Headers has secret-looking values replaced by "[redacted]".
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// TenantSecrets lists the mapped tenants; secrets read "[redacted]".
+	TenantField   string            `json:"tenant_field,omitempty"`
+	TenantSecrets map[string]string `json:"tenant_secrets,omitempty"`
+
+	// RetryPolicy is omitted when the webhook uses the default schedule.
+	RetryPolicy *webhook.RetryPolicy `json:"retry_policy,omitempty"`
+
+	FallbackURLs []string `json:"fallback_urls,omitempty"`
+
+	PreviousSecretExpiresAt string `json:"previous_secret_expires_at,omitempty"`
 }
 
 // Create creates a new webhook.
@@ -65,6 +143,47 @@ func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	budget := webhook.DefaultRetryBudget
+	if req.RetryBudget != "" {
+		var ok bool
+		if budget, ok = parseRetryBudget(req.RetryBudget); !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": retryBudgetError})
+			return
+		}
+	}
+
+	contentType, encoding, errMsg := resolveBodyEncoding(req.ContentType, req.BodyEncoding, "", webhook.BodyEncodingJSON)
+	if errMsg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": errMsg})
+		return
+	}
+	payloadMode, errMsg := resolvePayloadMode(req.PayloadMode, webhook.PayloadModeEnvelope, encoding)
+	if errMsg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": errMsg})
+		return
+	}
+
+	headers, err := sealHeaders(req.Headers, nil, h.secrets)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	tenantSecrets, err := sealTenantSecrets(req.TenantField, req.TenantSecrets, nil, h.secrets)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	retryPolicy, err := encodeRetryPolicy(req.RetryPolicy)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	fallbackURLs, err := validateFallbackURLs(req.URL, req.FallbackURLs)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
 	authCtx := middleware.GetAuthContext(r.Context())
 	if authCtx == nil {
 		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
@@ -74,37 +193,59 @@ func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Generate secret
 	secret := generateSecret()
 
-	webhook, err := h.queries.CreateWebhook(r.Context(), db.CreateWebhookParams{
-		OrgID:     pgtype.Text{String: authCtx.OrgID, Valid: true},
-		ProjectID: pgtype.Text{String: authCtx.ProjectID, Valid: authCtx.ProjectID != ""},
-		Url:       req.URL,
-		Topics:    req.Topics,
-		Secret:    secret,
+	wh, err := h.queries.CreateWebhook(r.Context(), db.CreateWebhookParams{
+		OrgID:              pgtype.Text{String: authCtx.OrgID, Valid: true},
+		ProjectID:          pgtype.Text{String: authCtx.ProjectID, Valid: authCtx.ProjectID != ""},
+		Url:                req.URL,
+		Topics:             req.Topics,
+		Secret:             secret,
+		RetryBudgetSeconds: int32(budget / time.Second),
+		ContentType:        contentType,
+		BodyEncoding:       encoding,
+		PayloadMode:        payloadMode,
+		Ordered:            req.Ordered,
+		Headers:            headers,
+		TenantField:        req.TenantField,
+		TenantSecrets:      tenantSecrets,
+		RetryPolicy:        retryPolicy,
+		FallbackUrls:       fallbackURLs,
 	})
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create webhook"})
 		return
 	}
 
-	webhookID := uuid.UUID(webhook.ID.Bytes).String()
+	webhookID := uuid.UUID(wh.ID.Bytes).String()
 
 	// Audit log
 	if h.auditLog != nil {
 		actor := auditActor(authCtx)
 		ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
 		h.auditLog.Log(ctx, actor, "webhook.create", authCtx.OrgID, webhookID, map[string]any{
-			"url":    req.URL,
-			"topics": req.Topics,
+			"url":           req.URL,
+			"topics":        req.Topics,
+			"fallback_urls": fallbackURLs,
 		})
 	}
 
 	writeJSON(w, http.StatusCreated, WebhookResponse{
-		ID:        webhookID,
-		URL:       webhook.Url,
-		Topics:    webhook.Topics,
-		Secret:    webhook.Secret, // Return secret only on create
-		Enabled:   webhook.Enabled,
-		CreatedAt: webhook.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		ID:          webhookID,
+		URL:         wh.Url,
+		Topics:      wh.Topics,
+		Secret:      wh.Secret, // Return secret only on create
+		Enabled:     wh.Enabled,
+		CreatedAt:   wh.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		RetryBudget: formatRetryBudget(wh.RetryBudgetSeconds),
+
+		ContentType:   wh.ContentType,
+		BodyEncoding:  wh.BodyEncoding,
+		PayloadMode:   wh.PayloadMode,
+		Ordered:       wh.Ordered,
+		Headers:       redactedHeaders(wh.Headers),
+		TenantField:   wh.TenantField,
+		TenantSecrets: redactedTenantSecrets(wh.TenantSecrets),
+		RetryPolicy:   retryPolicyResponse(wh.RetryPolicy),
+		FallbackURLs:  wh.FallbackUrls,
 	})
 }
 
@@ -128,11 +269,22 @@ func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
 	results := make([]WebhookResponse, len(webhooks))
 	for i, wh := range webhooks {
 		results[i] = WebhookResponse{
-			ID:        uuid.UUID(wh.ID.Bytes).String(),
-			URL:       wh.Url,
-			Topics:    wh.Topics,
-			Enabled:   wh.Enabled,
-			CreatedAt: wh.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+			ID:          uuid.UUID(wh.ID.Bytes).String(),
+			URL:         wh.Url,
+			Topics:      wh.Topics,
+			Enabled:     wh.Enabled,
+			CreatedAt:   wh.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+			RetryBudget: formatRetryBudget(wh.RetryBudgetSeconds),
+
+			ContentType:   wh.ContentType,
+			BodyEncoding:  wh.BodyEncoding,
+			PayloadMode:   wh.PayloadMode,
+			Ordered:       wh.Ordered,
+			Headers:       redactedHeaders(wh.Headers),
+			TenantField:   wh.TenantField,
+			TenantSecrets: redactedTenantSecrets(wh.TenantSecrets),
+			RetryPolicy:   retryPolicyResponse(wh.RetryPolicy),
+			FallbackURLs:  wh.FallbackUrls,
 		}
 	}
 
@@ -164,19 +316,52 @@ func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeJSON(w, http.StatusOK, WebhookResponse{
-		ID:        uuid.UUID(webhook.ID.Bytes).String(),
-		URL:       webhook.Url,
-		Topics:    webhook.Topics,
-		Enabled:   webhook.Enabled,
-		CreatedAt: webhook.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		ID:          uuid.UUID(webhook.ID.Bytes).String(),
+		URL:         webhook.Url,
+		Topics:      webhook.Topics,
+		Enabled:     webhook.Enabled,
+		CreatedAt:   webhook.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		RetryBudget: formatRetryBudget(webhook.RetryBudgetSeconds),
+
+		ContentType:   webhook.ContentType,
+		BodyEncoding:  webhook.BodyEncoding,
+		PayloadMode:   webhook.PayloadMode,
+		Ordered:       webhook.Ordered,
+		Headers:       redactedHeaders(webhook.Headers),
+		TenantField:   webhook.TenantField,
+		TenantSecrets: redactedTenantSecrets(webhook.TenantSecrets),
+		RetryPolicy:   retryPolicyResponse(webhook.RetryPolicy),
+		FallbackURLs:  webhook.FallbackUrls,
 	})
 }
 
 // UpdateWebhookRequest is the request body for updating a webhook.
 type UpdateWebhookRequest struct {
-	URL     string   `json:"url"`
-	Topics  []string `json:"topics"`
-	Enabled *bool    `json:"enabled"`
+	URL         string   `json:"url"`
+	Topics      []string `json:"topics"`
+	Enabled     *bool    `json:"enabled"`
+	RetryBudget string   `json:"retry_budget"`
+
+	ContentType  string `json:"content_type"`
+	BodyEncoding string `json:"body_encoding"`
+	PayloadMode  string `json:"payload_mode"`
+	Ordered      *bool  `json:"ordered"`
+
+	// Headers replaces the static headers when present; {} clears them.
+	// "[redacted]" keeps a header's stored value.
+	Headers map[string]string `json:"headers"`
+
+	// TenantSecrets replaces the tenant map the same way; an empty
+	// TenantField turns per-tenant signing off.
+	TenantField   *string           `json:"tenant_field"`
+	TenantSecrets map[string]string `json:"tenant_secrets"`
+
+	// RetryPolicy replaces the retry policy when present; {} restores the
+	// default schedule.
+	RetryPolicy *webhook.RetryPolicy `json:"retry_policy"`
+
+	// FallbackURLs replaces the fallback URLs when present; [] clears them.
+	FallbackURLs []string `json:"fallback_urls"`
 }
 
 // Update updates a webhook.
@@ -225,12 +410,86 @@ func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Enabled != nil {
 		enabled = *req.Enabled
 	}
+	budgetSeconds := webhook.RetryBudgetSeconds
+	if req.RetryBudget != "" {
+		budget, ok := parseRetryBudget(req.RetryBudget)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": retryBudgetError})
+			return
+		}
+		budgetSeconds = int32(budget / time.Second)
+	}
+	contentType, encoding, errMsg := resolveBodyEncoding(req.ContentType, req.BodyEncoding, webhook.ContentType, webhook.BodyEncoding)
+	if errMsg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": errMsg})
+		return
+	}
+	payloadMode, errMsg := resolvePayloadMode(req.PayloadMode, webhook.PayloadMode, encoding)
+	if errMsg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": errMsg})
+		return
+	}
+
+	ordered := webhook.Ordered
+	if req.Ordered != nil {
+		ordered = *req.Ordered
+	}
+	headers := webhook.Headers
+	if req.Headers != nil {
+		if headers, err = sealHeaders(req.Headers, webhook.Headers, h.secrets); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	tenantField := webhook.TenantField
+	if req.TenantField != nil {
+		tenantField = *req.TenantField
+	}
+	tenantSecrets := webhook.TenantSecrets
+	if req.TenantSecrets != nil || tenantField == "" {
+		secrets := req.TenantSecrets
+		if secrets == nil {
+			// Turning tenant signing off drops the stored secrets
+			secrets = map[string]string{}
+		}
+		if tenantSecrets, err = sealTenantSecrets(tenantField, secrets, webhook.TenantSecrets, h.secrets); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	retryPolicy := webhook.RetryPolicy
+	if req.RetryPolicy != nil {
+		if retryPolicy, err = encodeRetryPolicy(req.RetryPolicy); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	fallbackURLs := webhook.FallbackUrls
+	if req.FallbackURLs != nil || url != webhook.Url {
+		if req.FallbackURLs != nil {
+			fallbackURLs = req.FallbackURLs
+		}
+		if fallbackURLs, err = validateFallbackURLs(url, fallbackURLs); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
 
 	updated, err := h.queries.UpdateWebhook(r.Context(), db.UpdateWebhookParams{
-		ID:      webhook.ID,
-		Url:     url,
-		Topics:  topics,
-		Enabled: enabled,
+		ID:                 webhook.ID,
+		Url:                url,
+		Topics:             topics,
+		Enabled:            enabled,
+		RetryBudgetSeconds: budgetSeconds,
+		ContentType:        contentType,
+		BodyEncoding:       encoding,
+		PayloadMode:        payloadMode,
+		Ordered:            ordered,
+		Headers:            headers,
+		TenantField:        tenantField,
+		TenantSecrets:      tenantSecrets,
+		RetryPolicy:        retryPolicy,
+		FallbackUrls:       fallbackURLs,
 	})
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update webhook"})
@@ -238,11 +497,22 @@ func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeJSON(w, http.StatusOK, WebhookResponse{
-		ID:        uuid.UUID(updated.ID.Bytes).String(),
-		URL:       updated.Url,
-		Topics:    updated.Topics,
-		Enabled:   updated.Enabled,
-		CreatedAt: updated.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		ID:          uuid.UUID(updated.ID.Bytes).String(),
+		URL:         updated.Url,
+		Topics:      updated.Topics,
+		Enabled:     updated.Enabled,
+		CreatedAt:   updated.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		RetryBudget: formatRetryBudget(updated.RetryBudgetSeconds),
+
+		ContentType:   updated.ContentType,
+		BodyEncoding:  updated.BodyEncoding,
+		PayloadMode:   updated.PayloadMode,
+		Ordered:       updated.Ordered,
+		Headers:       redactedHeaders(updated.Headers),
+		TenantField:   updated.TenantField,
+		TenantSecrets: redactedTenantSecrets(updated.TenantSecrets),
+		RetryPolicy:   retryPolicyResponse(updated.RetryPolicy),
+		FallbackURLs:  updated.FallbackUrls,
 	})
 }
 
@@ -283,7 +553,164 @@ func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
-// Deliveries lists recent deliveries for a webhook.
+// maxFallbackURLs caps the URLs tried after a webhook's primary one.
+const maxFallbackURLs = 3
+
+const (
+	minRetryBudget   = time.Minute
+	maxRetryBudget   = 72 * time.Hour
+	retryBudgetError = "retry_budget must be a duration between 1m and 72h"
+)
+
+// parseRetryBudget parses a webhook retry budget such as "6h".
+func parseRetryBudget(s string) (time.Duration, bool) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < minRetryBudget || d > maxRetryBudget {
+		return 0, false
+	}
+	return d, true
+}
+
+func formatRetryBudget(seconds int32) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+// resolvePayloadMode applies a requested payload_mode change to a webhook's
+// current one. Raw bodies are the event's JSON data, so they can't be form
+// encoded. Returns an error message for invalid values.
+func resolvePayloadMode(mode, currentMode, encoding string) (string, string) {
+	if mode == "" {
+		mode = currentMode
+	} else if !webhook.ValidPayloadMode(mode) {
+		return "", "payload_mode must be envelope or raw"
+	}
+	if mode == webhook.PayloadModeRaw && encoding == webhook.BodyEncodingForm {
+		return "", "payload_mode raw requires body_encoding json"
+	}
+	return mode, ""
+}
+
+// resolveBodyEncoding applies requested content_type and body_encoding
+// changes to a webhook's current ones. Changing the encoding without a
+// content type switches to the new encoding's default type. Returns an error
+// message for invalid values.
+func resolveBodyEncoding(contentType, encoding, currentType, currentEncoding string) (string, string, string) {
+	if encoding != "" {
+		if !webhook.ValidBodyEncoding(encoding) {
+			return "", "", "body_encoding must be json or form"
+		}
+	} else {
+		encoding = currentEncoding
+	}
+
+	switch {
+	case contentType != "":
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return "", "", "content_type must be a valid media type"
+		}
+	case currentType == "" || encoding != currentEncoding:
+		contentType = webhook.DefaultContentType(encoding)
+	default:
+		contentType = currentType
+	}
+	return contentType, encoding, ""
+}
+
+const (
+	defaultSecretGracePeriod = 24 * time.Hour
+	maxSecretGracePeriod     = 7 * 24 * time.Hour
+)
+
+// RotateSecretRequest is the request body for rotating a webhook secret.
+type RotateSecretRequest struct {
+	GracePeriod string `json:"grace_period,omitempty"` // e.g. "24h"; "0s" drops the old secret immediately
+}
+
+// RotateSecret generates a new signing secret. The previous secret stays
+// valid (deliveries carry a second signature) until the grace period ends.
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+		return
+	}
+
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	webhook, err := h.queries.GetWebhook(r.Context(), pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil || webhook.OrgID.String != authCtx.OrgID || webhook.ProjectID.String != authCtx.ProjectID {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		return
+	}
+
+	var req RotateSecretRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+			return
+		}
+	}
+
+	grace := defaultSecretGracePeriod
+	if req.GracePeriod != "" {
+		grace, err = time.ParseDuration(req.GracePeriod)
+		if err != nil || grace < 0 || grace > maxSecretGracePeriod {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "grace_period must be a duration between 0s and 168h"})
+			return
+		}
+	}
+	expiresAt := time.Now().UTC().Add(grace)
+
+	rotated, err := h.queries.RotateWebhookSecret(r.Context(), db.RotateWebhookSecretParams{
+		ID:                      webhook.ID,
+		Secret:                  generateSecret(),
+		PreviousSecretExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to rotate secret"})
+		return
+	}
+
+	// Audit log
+	if h.auditLog != nil {
+		actor := auditActor(authCtx)
+		ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
+		h.auditLog.Log(ctx, actor, "webhook.rotate_secret", authCtx.OrgID, idStr, map[string]any{
+			"grace_period": grace.String(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, WebhookResponse{
+		ID:                      uuid.UUID(rotated.ID.Bytes).String(),
+		URL:                     rotated.Url,
+		Topics:                  rotated.Topics,
+		Secret:                  rotated.Secret,
+		Enabled:                 rotated.Enabled,
+		CreatedAt:               rotated.CreatedAt.Time.Format("2006-01-02T15:04:05Z"),
+		RetryBudget:             formatRetryBudget(rotated.RetryBudgetSeconds),
+		ContentType:             rotated.ContentType,
+		BodyEncoding:            rotated.BodyEncoding,
+		PayloadMode:             rotated.PayloadMode,
+		Ordered:                 rotated.Ordered,
+		Headers:                 redactedHeaders(rotated.Headers),
+		TenantField:             rotated.TenantField,
+		TenantSecrets:           redactedTenantSecrets(rotated.TenantSecrets),
+		RetryPolicy:             retryPolicyResponse(rotated.RetryPolicy),
+		FallbackURLs:            rotated.FallbackUrls,
+		PreviousSecretExpiresAt: expiresAt.Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+// validDeliveryStatuses are the statuses a deliveries listing can filter on.
+var validDeliveryStatuses = map[string]bool{"pending": true, "success": true, "failed": true}
+
+// Deliveries lists a webhook's deliveries, newest first. It pages with
+// ?limit= and ?before=<delivery id> and filters with ?status=.
 func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
@@ -305,10 +732,34 @@ func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deliveries, err := h.queries.GetWebhookDeliveries(r.Context(), db.GetWebhookDeliveriesParams{
+	params := db.GetWebhookDeliveriesParams{
 		WebhookID: webhook.ID,
 		Limit:     100,
-	})
+	}
+	query := r.URL.Query()
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			params.Limit = int32(min(l, 1000))
+		}
+	}
+	if status := query.Get("status"); status != "" {
+		if !validDeliveryStatuses[status] {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be pending, success or failed"})
+			return
+		}
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+	// Cursor: id of the last delivery already seen
+	if beforeStr := query.Get("before"); beforeStr != "" {
+		before, err := uuid.Parse(beforeStr)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+			return
+		}
+		params.Before = pgtype.UUID{Bytes: before, Valid: true}
+	}
+
+	deliveries, err := h.queries.GetWebhookDeliveries(r.Context(), params)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to get deliveries"})
 		return
@@ -340,10 +791,85 @@ func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		"deliveries": results,
 		"count":      len(results),
-	})
+	}
+	// A full page may have more behind it; hand back a cursor to resume from.
+	if len(deliveries) == int(params.Limit) {
+		resp["next_cursor"] = results[len(results)-1]["id"]
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// sealHeaders validates a webhook's static headers and encodes them for
+// storage; previous is the stored set an update replaces.
+func sealHeaders(headers map[string]string, previous []byte, box *security.SecretBox) ([]byte, error) {
+	if err := webhook.ValidateHeaders(headers); err != nil {
+		return nil, err
+	}
+	return webhook.SealHeaders(headers, previous, box)
+}
+
+// sealTenantSecrets validates a webhook's tenant secrets and encodes them
+// for storage; previous is the stored set an update replaces.
+func sealTenantSecrets(field string, secrets map[string]string, previous []byte, box *security.SecretBox) ([]byte, error) {
+	if err := webhook.ValidateTenantSecrets(field, secrets); err != nil {
+		return nil, err
+	}
+	return webhook.SealTenantSecrets(secrets, previous, box)
+}
+
+// encodeRetryPolicy validates a webhook's retry policy and encodes it for
+// storage. A nil or empty policy stores the default schedule.
+func encodeRetryPolicy(p *webhook.RetryPolicy) ([]byte, error) {
+	if p == nil || p.IsZero() {
+		return []byte("{}"), nil
+	}
+	if err := webhook.ValidateRetryPolicy(*p); err != nil {
+		return nil, err
+	}
+	return webhook.EncodeRetryPolicy(*p), nil
+}
+
+// validateFallbackURLs checks a webhook's fallback URLs against the same
+// SSRF rules as its primary URL. The result is never nil, as the column
+// isn't nullable.
+func validateFallbackURLs(primary string, urls []string) ([]string, error) {
+	if len(urls) > maxFallbackURLs {
+		return nil, fmt.Errorf("at most %d fallback_urls are allowed", maxFallbackURLs)
+	}
+	seen := map[string]bool{primary: true}
+	for _, u := range urls {
+		if seen[u] {
+			return nil, fmt.Errorf("fallback_urls must differ from url and each other")
+		}
+		seen[u] = true
+		if err := security.ValidateWebhookURL(u); err != nil {
+			return nil, fmt.Errorf("invalid fallback URL")
+		}
+	}
+	return append([]string{}, urls...), nil
+}
+
+// retryPolicyResponse returns a stored retry policy, or nil for the
+// default schedule.
+func retryPolicyResponse(stored []byte) *webhook.RetryPolicy {
+	p := webhook.DecodeRetryPolicy(stored)
+	if p.IsZero() {
+		return nil
+	}
+	return &p
+}
+
+// redactedHeaders returns stored headers with secret values hidden.
+func redactedHeaders(stored []byte) map[string]string {
+	return webhook.RedactHeaders(stored)
+}
+
+// redactedTenantSecrets returns the mapped tenants with secrets hidden.
+func redactedTenantSecrets(stored []byte) map[string]string {
+	return webhook.RedactTenantSecrets(stored)
 }
 
 func generateSecret() string {
@@ -365,3 +891,99 @@ func auditActor(authCtx *middleware.AuthContext) string {
 	}
 	return "unknown"
 }
+
+// RetryDelivery re-queues a failed delivery of an event to the webhook with
+// attempts starting over, without re-emitting the event.
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+		return
+	}
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "delivery_id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid delivery ID"})
+		return
+	}
+
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	// Verify ownership
+	wh, err := h.queries.GetWebhook(r.Context(), pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil || wh.OrgID.String != authCtx.OrgID || wh.ProjectID.String != authCtx.ProjectID {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		return
+	}
+
+	delivery, err := h.queries.GetWebhookDelivery(r.Context(), db.GetWebhookDeliveryParams{
+		ID:        pgtype.UUID{Bytes: deliveryID, Valid: true},
+		WebhookID: wh.ID,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "delivery not found"})
+		return
+	}
+	if delivery.Status == "success" {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "delivery already succeeded"})
+		return
+	}
+
+	if h.retries == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "delivery retry not available"})
+		return
+	}
+	reader, js, err := h.retries(authCtx.OrgID)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "delivery retry not available"})
+		return
+	}
+
+	stored, err := reader.GetByID(r.Context(), authCtx.OrgID, authCtx.ProjectID, delivery.Topic, delivery.EventID)
+	if errors.Is(err, nats.ErrEventNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "event no longer retained"})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to look up event for delivery retry", "error", err, "event_id", delivery.EventID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to look up event"})
+		return
+	}
+
+	// Show the delivery as pending until the worker picks the job up
+	err = h.queries.UpdateWebhookDelivery(r.Context(), db.UpdateWebhookDeliveryParams{
+		ID:      delivery.ID,
+		Status:  "pending",
+		Attempt: 1,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update delivery"})
+		return
+	}
+	if err := webhook.Redeliver(r.Context(), js, &wh, stored.Event, deliveryID.String()); err != nil {
+		slog.Error("failed to queue delivery retry", "error", err, "delivery_id", deliveryID)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to queue retry"})
+		return
+	}
+
+	// Audit log
+	if h.auditLog != nil {
+		actor := auditActor(authCtx)
+		ctx := audit.WithIP(r.Context(), audit.IPFromRequest(r))
+		h.auditLog.Log(ctx, actor, "webhook.retry_delivery", authCtx.OrgID, id.String(), map[string]any{
+			"delivery_id": deliveryID.String(),
+			"event_id":    delivery.EventID,
+		})
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]any{
+		"id":         deliveryID.String(),
+		"webhook_id": id.String(),
+		"event_id":   delivery.EventID,
+		"status":     "pending",
+		"attempt":    1,
+	})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/filipexyz/notif/internal/webhook"
+)
+
+func TestResolveBodyEncoding(t *testing.T) {
+	const (
+		jsonType = "application/json"
+		formType = "application/x-www-form-urlencoded"
+	)
+	tests := []struct {
+		name                         string
+		contentType, encoding        string
+		currentType, currentEncoding string
+		wantType, wantEncoding       string
+		wantErr                      bool
+	}{
+		{"create defaults", "", "", "", "json", jsonType, "json", false},
+		{"create form", "", "form", "", "json", formType, "form", false},
+		{"create vendor json", "application/vnd.acme+json", "", "", "json", "application/vnd.acme+json", "json", false},
+		{"update keeps custom type", "", "", "application/vnd.acme+json", "json", "application/vnd.acme+json", "json", false},
+		{"update switches to form", "", "form", jsonType, "json", formType, "form", false},
+		{"update same encoding keeps type", "", "json", "application/vnd.acme+json", "json", "application/vnd.acme+json", "json", false},
+		{"unknown encoding", "", "xml", "", "json", "", "", true},
+		{"invalid content type", "not a type", "", "", "json", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotEncoding, errMsg := resolveBodyEncoding(tt.contentType, tt.encoding, tt.currentType, tt.currentEncoding)
+			if (errMsg != "") != tt.wantErr {
+				t.Fatalf("error = %q, wantErr %v", errMsg, tt.wantErr)
+			}
+			if gotType != tt.wantType || gotEncoding != tt.wantEncoding {
+				t.Errorf("got (%q, %q), want (%q, %q)", gotType, gotEncoding, tt.wantType, tt.wantEncoding)
+			}
+		})
+	}
+}
+
+func TestResolvePayloadMode(t *testing.T) {
+	tests := []struct {
+		name                        string
+		mode, currentMode, encoding string
+		want                        string
+		wantErr                     bool
+	}{
+		{"create default", "", "envelope", "json", "envelope", false},
+		{"create raw", "raw", "envelope", "json", "raw", false},
+		{"update keeps raw", "", "raw", "json", "raw", false},
+		{"update back to envelope", "envelope", "raw", "json", "envelope", false},
+		{"unknown mode", "bare", "envelope", "json", "", true},
+		{"raw with form", "raw", "envelope", "form", "", true},
+		{"kept raw with form", "", "raw", "form", "", true},
+		{"envelope with form", "envelope", "raw", "form", "envelope", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errMsg := resolvePayloadMode(tt.mode, tt.currentMode, tt.encoding)
+			if (errMsg != "") != tt.wantErr {
+				t.Fatalf("error = %q, wantErr %v", errMsg, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeRetryPolicy(t *testing.T) {
+	stored, err := encodeRetryPolicy(nil)
+	if err != nil || retryPolicyResponse(stored) != nil {
+		t.Fatalf("nil policy should store the default schedule, got %s, %v", stored, err)
+	}
+
+	if _, err := encodeRetryPolicy(&webhook.RetryPolicy{MaxRetries: 50}); err == nil {
+		t.Error("expected max_retries above the cap to be rejected")
+	}
+	if _, err := encodeRetryPolicy(&webhook.RetryPolicy{Backoff: []string{"10"}}); err == nil {
+		t.Error("expected a backoff without a unit to be rejected")
+	}
+
+	stored, err = encodeRetryPolicy(&webhook.RetryPolicy{MaxRetries: 3, Backoff: []string{"5s", "1m"}})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got := retryPolicyResponse(stored)
+	if got == nil || got.MaxRetries != 3 || len(got.Backoff) != 2 || got.Backoff[1] != "1m" {
+		t.Errorf("round trip = %+v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/google/uuid"
+)
+
+// fullAccessScopes is reported for sessions and for API keys without
+// scopes, which can use the whole API of their project.
+var fullAccessScopes = []string{"*"}
+
+// WhoamiHandler reports the identity behind the request's credentials.
+type WhoamiHandler struct {
+	queries *db.Queries
+}
+
+// NewWhoamiHandler creates a new WhoamiHandler.
+func NewWhoamiHandler(queries *db.Queries) *WhoamiHandler {
+	return &WhoamiHandler{queries: queries}
+}
+
+// WhoamiResponse describes the authenticated caller.
+type WhoamiResponse struct {
+	AuthType    string   `json:"auth_type"` // "api_key" or "session"
+	OrgID       string   `json:"org_id"`
+	ProjectID   string   `json:"project_id"`
+	ProjectName string   `json:"project_name,omitempty"`
+	Scopes      []string `json:"scopes"`
+
+	// Set for API key auth
+	KeyID     string `json:"key_id,omitempty"`
+	KeyPrefix string `json:"key_prefix,omitempty"`
+	KeyName   string `json:"key_name,omitempty"`
+
+	// Set for session auth
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Whoami returns the org, project and key the request is authenticated as.
+func (h *WhoamiHandler) Whoami(w http.ResponseWriter, r *http.Request) {
+	authCtx := middleware.GetAuthContext(r.Context())
+	if authCtx == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	resp := WhoamiResponse{
+		OrgID:     authCtx.OrgID,
+		ProjectID: authCtx.ProjectID,
+		Scopes:    fullAccessScopes,
+	}
+
+	if apiKey := middleware.GetAPIKey(r.Context()); apiKey != nil {
+		resp.AuthType = "api_key"
+		resp.KeyID = uuid.UUID(apiKey.ID.Bytes).String()
+		resp.KeyPrefix = apiKey.KeyPrefix
+		resp.KeyName = apiKey.Name.String
+		if len(apiKey.Scopes) > 0 {
+			resp.Scopes = apiKey.Scopes
+		}
+	} else {
+		resp.AuthType = "session"
+		if authCtx.UserID != nil {
+			resp.UserID = *authCtx.UserID
+		}
+	}
+
+	// The name is a nicety; the IDs above are authoritative
+	if project, err := h.queries.GetProjectByOrgAndID(r.Context(), db.GetProjectByOrgAndIDParams{
+		ID:    authCtx.ProjectID,
+		OrgID: authCtx.OrgID,
+	}); err == nil {
+		resp.ProjectName = project.Name
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,39 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps claimed keys in process. It is meant for tests and
+// single-node development; nothing survives a restart.
+type Memory struct {
+	mu   sync.Mutex
+	keys map[string]Record // projectID + "/" + key
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{keys: make(map[string]Record)}
+}
+
+// Claim records rec unless the key is still held.
+func (m *Memory) Claim(_ context.Context, projectID, key string, rec Record, since time.Time) (Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := projectID + "/" + key
+	if existing, ok := m.keys[k]; ok && !existing.CreatedAt.Before(since) {
+		return existing, false, nil
+	}
+	m.keys[k] = rec
+	return rec, true, nil
+}
+
+// Release drops the claim.
+func (m *Memory) Release(_ context.Context, projectID, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, projectID+"/"+key)
+	return nil
+}
@@ -0,0 +1,48 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory_Claim(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	now := time.Now()
+	window := time.Hour
+
+	first := Record{EventID: "evt_1", Topic: "orders.created", CreatedAt: now}
+	if _, claimed, _ := m.Claim(ctx, "prj_a", "k", first, now.Add(-window)); !claimed {
+		t.Fatal("first claim refused")
+	}
+
+	got, claimed, _ := m.Claim(ctx, "prj_a", "k", Record{EventID: "evt_2", CreatedAt: now}, now.Add(-window))
+	if claimed || got.EventID != "evt_1" {
+		t.Errorf("repeat claim = %v %+v, want the original", claimed, got)
+	}
+
+	// Past the window the key is free again
+	later := now.Add(2 * window)
+	if _, claimed, _ := m.Claim(ctx, "prj_a", "k", Record{EventID: "evt_3", CreatedAt: later}, later.Add(-window)); !claimed {
+		t.Error("claim after the window refused")
+	}
+
+	m.Release(ctx, "prj_a", "k")
+	if _, claimed, _ := m.Claim(ctx, "prj_a", "k", Record{EventID: "evt_4", CreatedAt: later}, later.Add(-window)); !claimed {
+		t.Error("claim after release refused")
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	for key, valid := range map[string]bool{
+		"":                 true,
+		"order-1:retry":    true,
+		"with\nnewline":    false,
+		"emoji-\U0001F600": false,
+	} {
+		if err := ValidateKey(key); (err == nil) != valid {
+			t.Errorf("ValidateKey(%q) = %v, want valid %v", key, err, valid)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Postgres keeps claimed keys in the emit_idempotency_keys table.
+type Postgres struct {
+	queries *db.Queries
+}
+
+// NewPostgres returns the default store, backed by queries.
+func NewPostgres(queries *db.Queries) *Postgres {
+	return &Postgres{queries: queries}
+}
+
+// Claim prunes the project's expired keys, then inserts the claim unless
+// the key is still held.
+func (p *Postgres) Claim(ctx context.Context, projectID, key string, rec Record, since time.Time) (Record, bool, error) {
+	err := p.queries.DeleteExpiredIdempotencyKeys(ctx, db.DeleteExpiredIdempotencyKeysParams{
+		ProjectID: projectID,
+		CreatedAt: pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	_, err = p.queries.ClaimIdempotencyKey(ctx, db.ClaimIdempotencyKeyParams{
+		ProjectID: projectID,
+		Key:       key,
+		EventID:   rec.EventID,
+		Topic:     rec.Topic,
+		CreatedAt: pgtype.Timestamptz{Time: rec.CreatedAt, Valid: true},
+	})
+	if err == nil {
+		return rec, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Record{}, false, err
+	}
+
+	existing, err := p.queries.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{ProjectID: projectID, Key: key})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released between the two queries; the caller can retry
+		return Record{}, false, errors.New("idempotency key released concurrently")
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+	return Record{EventID: existing.EventID, Topic: existing.Topic, CreatedAt: existing.CreatedAt.Time}, false, nil
+}
+
+// Release deletes the claim.
+func (p *Postgres) Release(ctx context.Context, projectID, key string) error {
+	return p.queries.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{ProjectID: projectID, Key: key})
+}
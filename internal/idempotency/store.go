@@ -0,0 +1,48 @@
+// Package idempotency deduplicates retried emits. An emit carrying an
+// Idempotency-Key claims it for its project; repeating the emit with the
+// same key within the window returns the original event instead of
+// publishing it again.
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultWindow is how long a key is remembered when EMIT_IDEMPOTENCY_WINDOW
+// is unset.
+const DefaultWindow = 24 * time.Hour
+
+// MaxKeyLength bounds an idempotency key.
+const MaxKeyLength = 255
+
+// Record is the event emitted under a key.
+type Record struct {
+	EventID   string
+	Topic     string
+	CreatedAt time.Time
+}
+
+// Store is a pluggable backend for claimed keys.
+type Store interface {
+	// Claim records rec under the project's key, unless the key was
+	// claimed at or after since: then it returns that claim and false.
+	// Older claims are discarded.
+	Claim(ctx context.Context, projectID, key string, rec Record, since time.Time) (Record, bool, error)
+	// Release drops a claim, so the emit that made it can be retried.
+	Release(ctx context.Context, projectID, key string) error
+}
+
+// ValidateKey checks an Idempotency-Key header value.
+func ValidateKey(key string) error {
+	if len(key) > MaxKeyLength {
+		return fmt.Errorf("idempotency key must be at most %d characters", MaxKeyLength)
+	}
+	for _, r := range key {
+		if r < 0x20 || r > 0x7e {
+			return fmt.Errorf("idempotency key must be printable ASCII")
+		}
+	}
+	return nil
+}
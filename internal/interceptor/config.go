@@ -3,7 +3,10 @@ package interceptor
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	notifnats "github.com/filipexyz/notif/internal/nats"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,6 +22,39 @@ type InterceptorConfig struct {
 	To      string `yaml:"to"`
 	Jq      string `yaml:"jq"`
 	Enabled *bool  `yaml:"enabled"` // defaults to true if nil
+
+	// JqPipeline runs several jq expressions in order instead of Jq, each
+	// stage's output feeding the next; a stage with no output (a select
+	// that didn't match) drops the message.
+	JqPipeline []string `yaml:"jq_pipeline"`
+
+	// ToTemplate renders the output subject per event instead of To, with
+	// {year} {month} {day} {hour} from the event timestamp and {org}
+	// {project} {topic} from its subject.
+	ToTemplate string `yaml:"to_template"`
+
+	// Enrich merges a value looked up in a JetStream KV bucket into each
+	// payload before jq runs.
+	Enrich *EnrichConfig `yaml:"enrich"`
+
+	// Timeout bounds each jq transform (e.g. "500ms"); defaults to DefaultTimeout.
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxInputBytes caps the payload size fed to jq; defaults to DefaultMaxInputSize.
+	MaxInputBytes int `yaml:"max_input_bytes"`
+
+	// Workers processes up to this many messages at once (default 1). With
+	// more than one, output order no longer follows input order.
+	Workers int `yaml:"workers"`
+	// OnError is what happens to a message whose enrich, transform or
+	// publish fails: "ack", "nak" (the default) or "term".
+	OnError string `yaml:"on_error"`
+	// MaxDeliver caps deliveries under on_error "nak"; defaults to
+	// DefaultMaxDeliver.
+	MaxDeliver int `yaml:"max_deliver"`
+	// NakDelay is the wait before the first redelivery under on_error
+	// "nak" (e.g. "5s"), doubling per delivery up to MaxNakDelay; defaults
+	// to DefaultNakDelay.
+	NakDelay time.Duration `yaml:"nak_delay"`
 }
 
 // IsEnabled returns whether this interceptor is enabled (defaults to true).
@@ -26,6 +62,15 @@ func (c InterceptorConfig) IsEnabled() bool {
 	return c.Enabled == nil || *c.Enabled
 }
 
+// JqStages returns the jq expressions to run in order: the pipeline, or
+// the single jq expression if set.
+func (c InterceptorConfig) JqStages() []string {
+	if c.Jq != "" {
+		return []string{c.Jq}
+	}
+	return c.JqPipeline
+}
+
 // LoadConfig reads a YAML file and returns the parsed Config.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -38,3 +83,87 @@ func LoadConfig(path string) (*Config, error) {
 	}
 	return &cfg, nil
 }
+
+// Validate checks every interceptor, enabled or not, without touching NATS:
+// names are present and unique, subjects and to_templates are legal and
+// inside the events stream, jq expressions compile, enrich blocks are
+// complete, on_error policies are known and limits are non-negative. It returns all problems found rather
+// than stopping at the first.
+func (c *Config) Validate() []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, ic := range c.Interceptors {
+		label := fmt.Sprintf("interceptor #%d", i+1)
+		if ic.Name != "" {
+			label = fmt.Sprintf("interceptor %q", ic.Name)
+		}
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf(label+": "+format, args...))
+		}
+
+		switch {
+		case ic.Name == "":
+			fail("name is required")
+		case strings.Contains(ic.Name, ","):
+			fail("name must not contain commas")
+		case seen[ic.Name]:
+			fail("duplicate name")
+		}
+		seen[ic.Name] = true
+
+		subjects := []struct{ field, subject string }{{"from", ic.From}}
+		if ic.ToTemplate != "" {
+			if _, err := ParseSubjectTemplate(ic.ToTemplate); err != nil {
+				fail("%v", err)
+			}
+			if ic.To != "" {
+				fail("set either to or to_template, not both")
+			}
+		} else {
+			subjects = append(subjects, struct{ field, subject string }{"to", ic.To})
+		}
+		for _, s := range subjects {
+			if s.subject == "" {
+				fail("%s subject is required", s.field)
+				continue
+			}
+			if err := notifnats.ValidateSubject(s.subject, true); err != nil {
+				fail("%s: %v", s.field, err)
+				continue
+			}
+			if !strings.HasPrefix(s.subject, "events.") {
+				fail("%s: subject %q is outside the events stream (events.>)", s.field, s.subject)
+			}
+		}
+
+		if ic.Jq != "" && len(ic.JqPipeline) > 0 {
+			fail("set either jq or jq_pipeline, not both")
+		} else if _, err := compileJqPipeline(ic.JqStages()); err != nil {
+			fail("%v", err)
+		}
+		if ic.Enrich != nil {
+			if _, err := NewEnricher(*ic.Enrich); err != nil {
+				fail("%v", err)
+			}
+		}
+		if ic.Timeout < 0 {
+			fail("timeout must not be negative")
+		}
+		if ic.MaxInputBytes < 0 {
+			fail("max_input_bytes must not be negative")
+		}
+		if ic.Workers < 0 {
+			fail("workers must not be negative")
+		}
+		if ic.OnError != "" && !validOnError(ic.OnError) {
+			fail("invalid on_error %q (want ack, nak or term)", ic.OnError)
+		}
+		if ic.MaxDeliver < 0 {
+			fail("max_deliver must not be negative")
+		}
+		if ic.NakDelay < 0 {
+			fail("nak_delay must not be negative")
+		}
+	}
+	return errs
+}
@@ -0,0 +1,160 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+var keyPlaceholderRe = regexp.MustCompile(`\{([A-Za-z0-9_.]+)\}`)
+
+// EnrichConfig looks up a value in a JetStream KV bucket for each event and
+// merges it into the payload before the jq transform.
+type EnrichConfig struct {
+	// Bucket is the KV bucket to read; it must already exist.
+	Bucket string `yaml:"bucket"`
+	// Key renders the lookup key from payload fields, e.g.
+	// "users.{data.user_id}".
+	Key string `yaml:"key"`
+	// Into is the dotted path the value is set at, e.g. "data.user".
+	Into string `yaml:"into"`
+}
+
+// Enricher merges KV values into event payloads.
+type Enricher struct {
+	bucket string
+	key    string
+	into   []string
+	kv     jetstream.KeyValue
+}
+
+// NewEnricher checks cfg and returns an Enricher that must be bound to a
+// bucket before use.
+func NewEnricher(cfg EnrichConfig) (*Enricher, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("enrich: bucket is required")
+	}
+	if cfg.Key == "" {
+		return nil, fmt.Errorf("enrich: key is required")
+	}
+	if !keyPlaceholderRe.MatchString(cfg.Key) {
+		return nil, fmt.Errorf("enrich: key %q has no {field} placeholder", cfg.Key)
+	}
+	if strings.ContainsAny(keyPlaceholderRe.ReplaceAllString(cfg.Key, ""), "{}") {
+		return nil, fmt.Errorf("enrich: malformed placeholder in key %q", cfg.Key)
+	}
+	if cfg.Into == "" {
+		return nil, fmt.Errorf("enrich: into is required")
+	}
+	into := strings.Split(cfg.Into, ".")
+	for _, part := range into {
+		if part == "" {
+			return nil, fmt.Errorf("enrich: invalid into path %q", cfg.Into)
+		}
+	}
+	return &Enricher{bucket: cfg.Bucket, key: cfg.Key, into: into}, nil
+}
+
+// bind opens the enricher's KV bucket.
+func (e *Enricher) bind(ctx context.Context, js jetstream.JetStream) error {
+	kv, err := js.KeyValue(ctx, e.bucket)
+	if err != nil {
+		return fmt.Errorf("open KV bucket %s: %w", e.bucket, err)
+	}
+	e.kv = kv
+	return nil
+}
+
+// enrich returns data with the looked-up value set at the into path.
+// Payloads that aren't JSON objects, keys that can't be rendered and keys
+// missing from the bucket pass through unchanged; only a failed lookup
+// returns an error, which the interceptor's on_error policy settles.
+func (e *Enricher) enrich(ctx context.Context, data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var payload map[string]any
+	if err := dec.Decode(&payload); err != nil || payload == nil {
+		return data, nil
+	}
+
+	key, ok := renderKey(e.key, payload)
+	if !ok {
+		return data, nil
+	}
+	entry, err := e.kv.Get(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) || errors.Is(err, jetstream.ErrInvalidKey) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("look up %s in %s: %w", key, e.bucket, err)
+	}
+
+	// Non-JSON values are merged as strings
+	var value any = string(entry.Value())
+	if json.Valid(entry.Value()) {
+		value = json.RawMessage(entry.Value())
+	}
+	if !setPath(payload, e.into, value) {
+		return data, nil
+	}
+	return json.Marshal(payload)
+}
+
+// renderKey fills each {path} placeholder in tmpl with the string or number
+// at that dotted path in payload. ok is false when any is missing.
+func renderKey(tmpl string, payload map[string]any) (key string, ok bool) {
+	ok = true
+	key = keyPlaceholderRe.ReplaceAllStringFunc(tmpl, func(p string) string {
+		switch v := lookupPath(payload, strings.Split(p[1:len(p)-1], ".")).(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case json.Number:
+			return v.String()
+		}
+		ok = false
+		return ""
+	})
+	return key, ok
+}
+
+func lookupPath(payload map[string]any, path []string) any {
+	var cur any = payload
+	for _, part := range path {
+		obj, isObj := cur.(map[string]any)
+		if !isObj {
+			return nil
+		}
+		cur = obj[part]
+	}
+	return cur
+}
+
+// setPath sets value at path, creating missing objects along the way. It
+// reports false when a non-object is in the way.
+func setPath(payload map[string]any, path []string, value any) bool {
+	obj := payload
+	for _, part := range path[:len(path)-1] {
+		next, exists := obj[part]
+		if !exists || next == nil {
+			child := make(map[string]any)
+			obj[part] = child
+			obj = child
+			continue
+		}
+		child, isObj := next.(map[string]any)
+		if !isObj {
+			return false
+		}
+		obj = child
+	}
+	obj[path[len(path)-1]] = value
+	return true
+}
@@ -3,11 +3,14 @@ package interceptor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
+	"time"
 
+	notifnats "github.com/filipexyz/notif/internal/nats"
 	"github.com/itchyny/gojq"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
@@ -15,21 +18,65 @@ import (
 
 const headerKey = "X-Notif-Interceptor"
 
+const (
+	// DefaultTimeout bounds a single jq transform so a pathological
+	// expression cannot stall the consume loop.
+	DefaultTimeout = time.Second
+	// DefaultMaxInputSize is the largest payload handed to jq.
+	DefaultMaxInputSize = 1 << 20
+	// DefaultMaxDeliver caps how often a failing message is delivered under
+	// the nak policy before it is dead-lettered.
+	DefaultMaxDeliver = 5
+	// DefaultNakDelay is how long the nak policy waits before the first
+	// redelivery. Each further one waits twice as long, up to MaxNakDelay.
+	DefaultNakDelay = time.Second
+	// MaxNakDelay caps the wait between redeliveries under the nak policy.
+	MaxNakDelay = time.Minute
+)
+
+// Policies for a message whose enrich, transform or publish fails. Messages
+// that won't be delivered again are dead-lettered first.
+const (
+	// OnErrorAck acks the message: it is not redelivered.
+	OnErrorAck = "ack"
+	// OnErrorNak redelivers the message, up to the max deliver count.
+	OnErrorNak = "nak"
+	// OnErrorTerm terminates the message: it is not redelivered, and
+	// JetStream reports it as terminated rather than processed.
+	OnErrorTerm = "term"
+)
+
+// validOnError reports whether policy is a known on_error policy.
+func validOnError(policy string) bool {
+	return policy == OnErrorAck || policy == OnErrorNak || policy == OnErrorTerm
+}
+
 // Interceptor is a subscribe-transform-publish loop for reshaping NATS messages.
 type Interceptor struct {
 	name   string
 	from   string
 	to     string
-	jq     *gojq.Code
+	jq     []*gojq.Code // stages, run in order
 	js     jetstream.JetStream
 	stream jetstream.Stream
+	dlq    *notifnats.DLQPublisher
 	logger *slog.Logger
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+
+	timeout      time.Duration
+	maxInputSize int
+	toTemplate   *SubjectTemplate
+	enricher     *Enricher
+	workers      int
+	onError      string
+	maxDeliver   int
+	nakDelay     time.Duration
 }
 
-// New creates an Interceptor. If jqExpr is empty, messages pass through unchanged.
-func New(name, from, to, jqExpr string, js jetstream.JetStream, stream jetstream.Stream, logger *slog.Logger) (*Interceptor, error) {
+// New creates an Interceptor running the jq stages in order, each stage's
+// output feeding the next. With no stages, messages pass through unchanged.
+func New(name, from, to string, jq []string, js jetstream.JetStream, stream jetstream.Stream, logger *slog.Logger) (*Interceptor, error) {
 	if name == "" {
 		return nil, fmt.Errorf("interceptor name is required")
 	}
@@ -42,26 +89,110 @@ func New(name, from, to, jqExpr string, js jetstream.JetStream, stream jetstream
 	if to == "" {
 		return nil, fmt.Errorf("interceptor %q: to subject is required", name)
 	}
-	var compiled *gojq.Code
-	if jqExpr != "" {
-		query, err := gojq.Parse(jqExpr)
-		if err != nil {
-			return nil, fmt.Errorf("parse jq expression: %w", err)
-		}
-		code, err := gojq.Compile(query)
-		if err != nil {
-			return nil, fmt.Errorf("compile jq expression: %w", err)
-		}
-		compiled = code
+	compiled, err := compileJqPipeline(jq)
+	if err != nil {
+		return nil, err
 	}
 	return &Interceptor{
 		name: name, from: from, to: to, jq: compiled,
-		js: js, stream: stream, logger: logger,
+		js: js, stream: stream, dlq: notifnats.NewDLQPublisher(js), logger: logger,
+		timeout: DefaultTimeout, maxInputSize: DefaultMaxInputSize,
+		workers: 1, onError: OnErrorNak, maxDeliver: DefaultMaxDeliver, nakDelay: DefaultNakDelay,
 	}, nil
 }
 
+// compileJq parses and compiles a jq expression; empty returns nil.
+func compileJq(expr string) (*gojq.Code, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parse jq expression: %w", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("compile jq expression: %w", err)
+	}
+	return code, nil
+}
+
+// compileJqPipeline compiles each stage of a jq pipeline. A single stage
+// is the plain jq field, so its errors aren't numbered.
+func compileJqPipeline(stages []string) ([]*gojq.Code, error) {
+	var codes []*gojq.Code
+	for n, expr := range stages {
+		if expr == "" {
+			return nil, fmt.Errorf("jq stage %d is empty", n+1)
+		}
+		code, err := compileJq(expr)
+		if err != nil {
+			if len(stages) > 1 {
+				err = fmt.Errorf("jq stage %d: %w", n+1, err)
+			}
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// SetTimeout sets the per-message jq transform timeout.
+func (i *Interceptor) SetTimeout(d time.Duration) {
+	i.timeout = d
+}
+
+// SetMaxInputSize sets the largest payload (in bytes) the transform accepts.
+func (i *Interceptor) SetMaxInputSize(n int) {
+	i.maxInputSize = n
+}
+
+// SetToTemplate renders each output subject from t instead of mapping the
+// source subject onto the static prefix of `to`.
+func (i *Interceptor) SetToTemplate(t *SubjectTemplate) {
+	i.toTemplate = t
+}
+
+// SetEnricher merges a KV lookup into each payload before the jq transform.
+func (i *Interceptor) SetEnricher(e *Enricher) {
+	i.enricher = e
+}
+
+// SetWorkers processes up to n messages at once. With more than one worker,
+// messages may be published out of order.
+func (i *Interceptor) SetWorkers(n int) {
+	i.workers = n
+}
+
+// SetOnError sets what happens to a message that fails: OnErrorAck,
+// OnErrorNak or OnErrorTerm.
+func (i *Interceptor) SetOnError(policy string) {
+	i.onError = policy
+}
+
+// SetMaxDeliver caps how often a message is delivered under OnErrorNak.
+// The cap can't be lifted: n <= 0 keeps DefaultMaxDeliver, so a message
+// that keeps failing, e.g. on a KV lookup, is dead-lettered eventually.
+func (i *Interceptor) SetMaxDeliver(n int) {
+	if n <= 0 {
+		n = DefaultMaxDeliver
+	}
+	i.maxDeliver = n
+}
+
+// SetNakDelay sets the wait before the first redelivery under OnErrorNak.
+func (i *Interceptor) SetNakDelay(d time.Duration) {
+	i.nakDelay = d
+}
+
 // Start creates a durable consumer and begins processing messages.
 func (i *Interceptor) Start(ctx context.Context) error {
+	if i.enricher != nil {
+		if err := i.enricher.bind(ctx, i.js); err != nil {
+			return fmt.Errorf("interceptor %s: %w", i.name, err)
+		}
+	}
+
 	ctx, i.cancel = context.WithCancel(ctx)
 	consumerName := "interceptor-" + i.name
 
@@ -70,12 +201,35 @@ func (i *Interceptor) Start(ctx context.Context) error {
 		FilterSubjects: []string{i.from},
 		AckPolicy:      jetstream.AckExplicitPolicy,
 		DeliverPolicy:  jetstream.DeliverAllPolicy,
+		MaxDeliver:     i.maxDeliver,
 	})
 	if err != nil {
 		return fmt.Errorf("create consumer %s: %w", consumerName, err)
 	}
 
-	cons, err := consumer.Consume(func(msg jetstream.Msg) { i.handleMessage(ctx, msg) })
+	handler := func(msg jetstream.Msg) { i.handleMessage(ctx, msg) }
+	if i.workers > 1 {
+		// Consume calls back one message at a time; hand each to a worker,
+		// blocking while all of them are busy.
+		slots := make(chan struct{}, i.workers)
+		handler = func(msg jetstream.Msg) {
+			if ctx.Err() != nil {
+				return // unacked, so redelivered after a restart
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			i.wg.Add(1)
+			go func() {
+				defer i.wg.Done()
+				defer func() { <-slots }()
+				i.handleMessage(ctx, msg)
+			}()
+		}
+	}
+	cons, err := consumer.Consume(handler)
 	if err != nil {
 		return fmt.Errorf("start consume %s: %w", consumerName, err)
 	}
@@ -87,7 +241,7 @@ func (i *Interceptor) Start(ctx context.Context) error {
 		cons.Stop()
 	}()
 
-	i.logger.Info("interceptor started", "name", i.name, "from", i.from, "to", i.to)
+	i.logger.Info("interceptor started", "name", i.name, "from", i.from, "to", i.to, "workers", max(i.workers, 1), "on_error", i.onError)
 	return nil
 }
 
@@ -116,34 +270,35 @@ func (i *Interceptor) handleMessage(ctx context.Context, msg jetstream.Msg) {
 	}
 
 	data := msg.Data()
+	targetSubject := i.targetSubject(msg)
 
-	if i.jq != nil {
-		var input interface{}
-		if err := json.Unmarshal(data, &input); err != nil {
-			i.logger.Error("unmarshal for jq", "error", err, "interceptor", i.name, "subject", msg.Subject())
-			_ = msg.Ack()
-			return
-		}
-		iter := i.jq.Run(input)
-		v, ok := iter.Next()
-		if !ok {
-			_ = msg.Ack() // jq select filter dropped
+	if i.enricher != nil {
+		out, err := i.enricher.enrich(ctx, data)
+		if err != nil {
+			i.logger.Error("enrich", "error", err, "interceptor", i.name, "subject", msg.Subject())
+			i.fail(ctx, msg, err)
 			return
 		}
-		if err, isErr := v.(error); isErr {
-			i.logger.Error("jq transform", "error", err, "interceptor", i.name, "subject", msg.Subject())
-			_ = msg.Ack()
+		data = out
+	}
+
+	if len(i.jq) > 0 {
+		start := time.Now()
+		out, keep, err := i.transform(ctx, data)
+		elapsed := time.Since(start)
+		if err != nil {
+			i.logger.Error("jq transform", "error", err, "interceptor", i.name, "subject", msg.Subject(), "duration", elapsed)
+			i.fail(ctx, msg, err)
 			return
 		}
-		var err error
-		if data, err = json.Marshal(v); err != nil {
-			i.logger.Error("marshal jq result", "error", err, "interceptor", i.name)
-			_ = msg.Ack()
+		if !keep {
+			_ = msg.Ack() // jq select filter dropped
 			return
 		}
+		i.logger.Debug("jq transform", "interceptor", i.name, "duration", elapsed)
+		data = out
 	}
 
-	targetSubject := i.mapSubject(msg.Subject())
 	outMsg := &nats.Msg{Subject: targetSubject, Data: data, Header: nats.Header{}}
 
 	// Build interceptor chain: append our name to existing chain
@@ -155,13 +310,141 @@ func (i *Interceptor) handleMessage(ctx context.Context, msg jetstream.Msg) {
 
 	if _, err := i.js.PublishMsg(ctx, outMsg); err != nil {
 		i.logger.Error("publish", "error", err, "interceptor", i.name, "subject", targetSubject)
-		_ = msg.Nak()
+		i.fail(ctx, msg, err)
 		return
 	}
 	_ = msg.Ack()
 	i.logger.Debug("interceptor processed", "name", i.name, "from", msg.Subject(), "to", targetSubject)
 }
 
+// transform runs the jq stages on data, bounded by the input size guard and
+// the timeout, which covers the whole pipeline. Each stage gets the first
+// output of the one before; keep is false when a stage produced no output.
+func (i *Interceptor) transform(ctx context.Context, data []byte) (out []byte, keep bool, err error) {
+	if i.maxInputSize > 0 && len(data) > i.maxInputSize {
+		return nil, false, fmt.Errorf("payload of %d bytes exceeds max input size of %d", len(data), i.maxInputSize)
+	}
+
+	var input interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, false, fmt.Errorf("unmarshal for jq: %w", err)
+	}
+
+	if i.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+		defer cancel()
+	}
+
+	v := input
+	for n, code := range i.jq {
+		var ok bool
+		if v, ok = code.RunWithContext(ctx, v).Next(); !ok {
+			return nil, false, nil
+		}
+		if err, isErr := v.(error); isErr {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, false, fmt.Errorf("transform exceeded timeout of %s", i.timeout)
+			}
+			if len(i.jq) > 1 {
+				err = fmt.Errorf("jq stage %d: %w", n+1, err)
+			}
+			return nil, false, err
+		}
+	}
+	if out, err = json.Marshal(v); err != nil {
+		return nil, false, fmt.Errorf("marshal jq result: %w", err)
+	}
+	return out, true, nil
+}
+
+// fail settles a message that could not be processed according to the
+// on_error policy. Under nak it is redelivered after a backoff, and its
+// last delivery is dead-lettered and terminated rather than dropped when
+// JetStream stops redelivering.
+func (i *Interceptor) fail(ctx context.Context, msg jetstream.Msg, cause error) {
+	switch i.onError {
+	case OnErrorAck:
+		i.deadLetter(ctx, msg, cause)
+		_ = msg.Ack()
+	case OnErrorTerm:
+		i.deadLetter(ctx, msg, cause)
+		_ = msg.Term()
+	default:
+		delivered := 1
+		if meta, err := msg.Metadata(); err == nil {
+			delivered = int(meta.NumDelivered)
+		}
+		if delivered >= i.maxDeliver {
+			i.deadLetter(ctx, msg, cause)
+			_ = msg.Term()
+			return
+		}
+		_ = msg.NakWithDelay(i.nakBackoff(delivered))
+	}
+}
+
+// nakBackoff returns the wait before redelivering a message that failed on
+// its given delivery: nakDelay, doubling per delivery up to MaxNakDelay.
+func (i *Interceptor) nakBackoff(delivered int) time.Duration {
+	d := i.nakDelay
+	for n := 1; n < delivered && d < MaxNakDelay; n++ {
+		d *= 2
+	}
+	return min(d, MaxNakDelay)
+}
+
+// deadLetter moves a message that failed for good to the DLQ so it
+// neither blocks the consumer nor disappears silently.
+// Subjects follow events.{org_id}.{project_id}.{topic}.
+func (i *Interceptor) deadLetter(ctx context.Context, msg jetstream.Msg, cause error) {
+	parts := strings.SplitN(msg.Subject(), ".", 4)
+	if len(parts) != 4 || parts[0] != "events" {
+		i.logger.Warn("cannot dead-letter message outside events.{org}.{project}.{topic}, dropping",
+			"interceptor", i.name, "subject", msg.Subject())
+		return
+	}
+
+	data := msg.Data()
+	if !json.Valid(data) {
+		data, _ = json.Marshal(string(data))
+	}
+
+	attempts := 1
+	if meta, err := msg.Metadata(); err == nil {
+		attempts = int(meta.NumDelivered)
+	}
+
+	now := time.Now().UTC()
+	err := i.dlq.Publish(ctx, &notifnats.DLQMessage{
+		OrgID:         parts[1],
+		ProjectID:     parts[2],
+		OriginalTopic: parts[3],
+		Data:          data,
+		Timestamp:     now,
+		FailedAt:      now,
+		Attempts:      attempts,
+		LastError:     cause.Error(),
+		ConsumerGroup: "interceptor-" + i.name,
+	})
+	if err != nil {
+		i.logger.Error("dead-letter", "error", err, "interceptor", i.name, "subject", msg.Subject())
+	}
+}
+
+// targetSubject picks the output subject for msg, rendering the to_template
+// against the event's own timestamp (not the transformed payload's) when set.
+func (i *Interceptor) targetSubject(msg jetstream.Msg) string {
+	if i.toTemplate == nil {
+		return i.mapSubject(msg.Subject())
+	}
+	fallback := time.Now()
+	if meta, err := msg.Metadata(); err == nil {
+		fallback = meta.Timestamp
+	}
+	return i.toTemplate.Render(msg.Subject(), eventTimestamp(msg.Data(), fallback))
+}
+
 // mapSubject replaces the static prefix of `from` with the static prefix of `to`.
 func (i *Interceptor) mapSubject(subject string) string {
 	fromPrefix, toPrefix := staticPrefix(i.from), staticPrefix(i.to)
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	notifnats "github.com/filipexyz/notif/internal/nats"
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
@@ -110,7 +112,7 @@ func TestInterceptor_BasicForward(t *testing.T) {
 	env := setupTestEnv(t)
 	logger := testLogger()
 
-	intc, err := New("test-fwd", "events.org.proj.inbound.>", "events.org.proj.output.>", "", env.js, env.stream, logger)
+	intc, err := New("test-fwd", "events.org.proj.inbound.>", "events.org.proj.output.>", nil, env.js, env.stream, logger)
 	if err != nil {
 		t.Fatalf("create interceptor: %v", err)
 	}
@@ -153,7 +155,7 @@ func TestInterceptor_JqTransform(t *testing.T) {
 	logger := testLogger()
 
 	jqExpr := `{text: .textContent, sender: .senderDisplayName}`
-	intc, err := New("test-jq", "events.org.proj.inbound.>", "events.org.proj.transformed.>", jqExpr, env.js, env.stream, logger)
+	intc, err := New("test-jq", "events.org.proj.inbound.>", "events.org.proj.transformed.>", []string{jqExpr}, env.js, env.stream, logger)
 	if err != nil {
 		t.Fatalf("create interceptor: %v", err)
 	}
@@ -201,7 +203,7 @@ func TestInterceptor_JqSelectFilter(t *testing.T) {
 	logger := testLogger()
 
 	jqExpr := `select(.status == "active") | {name: .name}`
-	intc, err := New("test-select", "events.org.proj.inbound.>", "events.org.proj.filtered.>", jqExpr, env.js, env.stream, logger)
+	intc, err := New("test-select", "events.org.proj.inbound.>", "events.org.proj.filtered.>", []string{jqExpr}, env.js, env.stream, logger)
 	if err != nil {
 		t.Fatalf("create interceptor: %v", err)
 	}
@@ -241,12 +243,97 @@ func TestInterceptor_JqSelectFilter(t *testing.T) {
 	}
 }
 
+func TestInterceptor_JqPipeline(t *testing.T) {
+	env := setupTestEnv(t)
+
+	stages := []string{
+		`{name: .user.name, total: ([.items[].price] | add)}`,
+		`{summary: "\(.name): \(.total)"}`,
+	}
+	intc, err := New("test-pipeline", "events.org.proj.inbound.>", "events.org.proj.staged.>", stages, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	if _, err := env.js.Publish(ctx, "events.org.proj.inbound.order", []byte(`{"user":{"name":"Alice"},"items":[{"price":3},{"price":4}]}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	msg := waitForMessage(t, env, "events.org.proj.staged.>", 5*time.Second)
+	if string(msg.Data()) != `{"summary":"Alice: 7"}` {
+		t.Errorf("expected the second stage to see the first one's output, got %s", msg.Data())
+	}
+}
+
+func TestInterceptor_JqPipelineFilterThenReshape(t *testing.T) {
+	env := setupTestEnv(t)
+
+	cfg := &Config{Interceptors: []InterceptorConfig{{
+		Name:       "filter-reshape",
+		From:       "events.org.proj.inbound.>",
+		To:         "events.org.proj.filtered.>",
+		JqPipeline: []string{`select(.status == "active")`, `{name: .name}`},
+	}}}
+	mgr, err := NewManager(cfg, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create manager: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("start manager: %v", err)
+	}
+	defer mgr.Stop()
+
+	for _, payload := range []string{`{"name":"Bob","status":"inactive"}`, `{"name":"Alice","status":"active"}`} {
+		if _, err := env.js.Publish(ctx, "events.org.proj.inbound.user", []byte(payload)); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	// Bob was dropped by the first stage, so Alice comes first
+	msg := waitForMessage(t, env, "events.org.proj.filtered.>", 5*time.Second)
+	if string(msg.Data()) != `{"name":"Alice"}` {
+		t.Errorf("expected only the active user, reshaped, got %s", msg.Data())
+	}
+}
+
+func TestValidate_JqPipeline(t *testing.T) {
+	cfg := &Config{Interceptors: []InterceptorConfig{
+		{Name: "ok", From: "events.a.>", To: "events.b.>", JqPipeline: []string{`.`, `{x: .y}`}},
+		{Name: "both", From: "events.a.>", To: "events.b.>", Jq: `.`, JqPipeline: []string{`.`}},
+		{Name: "broken", From: "events.a.>", To: "events.b.>", JqPipeline: []string{`.`, `{x: .y`}},
+		{Name: "empty", From: "events.a.>", To: "events.b.>", JqPipeline: []string{`.`, ``}},
+	}}
+	got := cfg.Validate()
+	want := []string{
+		`interceptor "both": set either jq or jq_pipeline, not both`,
+		`interceptor "broken": jq stage 2: parse jq expression`,
+		`interceptor "empty": jq stage 2 is empty`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(got[i].Error(), w) {
+			t.Errorf("error %d = %q, want prefix %q", i, got[i], w)
+		}
+	}
+}
+
 // Test 4: Passthrough mode (no jq) forwards data unchanged
 func TestInterceptor_Passthrough(t *testing.T) {
 	env := setupTestEnv(t)
 	logger := testLogger()
 
-	intc, err := New("test-pass", "events.org.proj.src.>", "events.org.proj.dst.>", "", env.js, env.stream, logger)
+	intc, err := New("test-pass", "events.org.proj.src.>", "events.org.proj.dst.>", nil, env.js, env.stream, logger)
 	if err != nil {
 		t.Fatalf("create interceptor: %v", err)
 	}
@@ -279,7 +366,7 @@ func TestInterceptor_LoopPrevention(t *testing.T) {
 	logger := testLogger()
 
 	// This interceptor reads from src and writes to dst
-	intc, err := New("test-loop", "events.org.proj.src.>", "events.org.proj.dst.>", "", env.js, env.stream, logger)
+	intc, err := New("test-loop", "events.org.proj.src.>", "events.org.proj.dst.>", nil, env.js, env.stream, logger)
 	if err != nil {
 		t.Fatalf("create interceptor: %v", err)
 	}
@@ -365,12 +452,128 @@ func TestInterceptor_SubjectMapping(t *testing.T) {
 	}
 }
 
+func TestSubjectTemplate_Render(t *testing.T) {
+	ts := time.Date(2024, 1, 5, 7, 30, 0, 0, time.FixedZone("BRT", -3*3600))
+	tests := []struct {
+		name     string
+		template string
+		subject  string
+		want     string
+	}{
+		{
+			name:     "date parts in UTC",
+			template: "events.proj.{year}.{month}.{day}.{hour}.out",
+			subject:  "events.org.proj.orders",
+			want:     "events.proj.2024.01.05.10.out",
+		},
+		{
+			name:     "subject parts",
+			template: "events.{org}.{project}.archive.{year}.{topic}",
+			subject:  "events.org.proj.orders.created",
+			want:     "events.org.proj.archive.2024.orders.created",
+		},
+		{
+			name:     "no placeholders",
+			template: "events.org.proj.static",
+			subject:  "events.org.proj.orders",
+			want:     "events.org.proj.static",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseSubjectTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := tmpl.Render(tt.subject, ts); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.subject, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSubjectTemplate_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"events.{org}.{week}":        "unknown placeholder {week}",
+		"orders.{year}":              "outside the events stream",
+		"events.{org}.>":             "wildcard",
+		"events.{org}.{year":         "malformed placeholder",
+		"events.{org}..{year}.{day}": "empty token",
+	}
+	for template, want := range tests {
+		_, err := ParseSubjectTemplate(template)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseSubjectTemplate(%q) = %v, want error containing %q", template, err, want)
+		}
+	}
+}
+
+func TestInterceptor_DatePartitionedRouting(t *testing.T) {
+	env := setupTestEnv(t)
+
+	intc, err := New("by-day", "events.org.proj.orders.>", "events.org.proj.archive.>", nil, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	tmpl, err := ParseSubjectTemplate("events.{org}.{project}.archive.{year}.{month}.{day}.out")
+	if err != nil {
+		t.Fatalf("parse template: %v", err)
+	}
+	intc.SetToTemplate(tmpl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+	time.Sleep(200 * time.Millisecond)
+
+	// One second apart, on either side of midnight (and of a month boundary)
+	for _, ts := range []string{"2024-01-31T23:59:59Z", "2024-02-01T00:00:00Z"} {
+		data := fmt.Sprintf(`{"id":"evt_%s","topic":"orders.created","data":{},"timestamp":%q}`, ts[:10], ts)
+		if _, err := env.js.Publish(ctx, "events.org.proj.orders.created", []byte(data)); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	for _, want := range []string{"events.org.proj.archive.2024.01.31.out", "events.org.proj.archive.2024.02.01.out"} {
+		msg := waitForMessage(t, env, want, 5*time.Second)
+		if msg.Subject() != want {
+			t.Errorf("expected subject %s, got %s", want, msg.Subject())
+		}
+	}
+}
+
+func TestValidate_ToTemplate(t *testing.T) {
+	cfg := &Config{Interceptors: []InterceptorConfig{
+		{Name: "ok", From: "events.a.>", ToTemplate: "events.a.{year}.{month}.out"},
+		{Name: "both", From: "events.a.>", To: "events.b.>", ToTemplate: "events.a.{year}.out"},
+		{Name: "bad", From: "events.a.>", ToTemplate: "events.a.{weekday}"},
+	}}
+
+	got := cfg.Validate()
+	want := []string{
+		`interceptor "both": set either to or to_template, not both`,
+		`interceptor "bad": to_template: unknown placeholder {weekday}`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Error() != w {
+			t.Errorf("error %d = %q, want %q", i, got[i], w)
+		}
+	}
+}
+
 // Test 7: Start/Stop lifecycle
 func TestInterceptor_StartStop(t *testing.T) {
 	env := setupTestEnv(t)
 	logger := testLogger()
 
-	intc, err := New("test-lifecycle", "events.org.proj.life.>", "events.org.proj.dest.>", "", env.js, env.stream, logger)
+	intc, err := New("test-lifecycle", "events.org.proj.life.>", "events.org.proj.dest.>", nil, env.js, env.stream, logger)
 	if err != nil {
 		t.Fatalf("create interceptor: %v", err)
 	}
@@ -450,6 +653,58 @@ interceptors:
 	}
 }
 
+func TestValidate_ExampleConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../interceptors.example.yaml")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if errs := cfg.Validate(); len(errs) != 0 {
+		t.Fatalf("expected example config to be valid, got %v", errs)
+	}
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	content := `
+interceptors:
+  - name: broken-jq
+    from: "events.a.>"
+    to: "events.b.>"
+    jq: '{text: .textContent'
+  - name: broken-jq
+    from: "events.a.>.x"
+    to: "orders.b.>"
+  - from: "events.c.>"
+    max_input_bytes: -1
+`
+	tmpFile := t.TempDir() + "/interceptors.yaml"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("write temp config: %v", err)
+	}
+	cfg, err := LoadConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	got := cfg.Validate()
+	want := []string{
+		`interceptor "broken-jq": parse jq expression`,
+		`interceptor "broken-jq": duplicate name`,
+		`interceptor "broken-jq": from: subject "events.a.>.x": > must be the last token`,
+		`interceptor "broken-jq": to: subject "orders.b.>" is outside the events stream`,
+		`interceptor #3: name is required`,
+		`interceptor #3: to subject is required`,
+		`interceptor #3: max_input_bytes must not be negative`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(got[i].Error(), w) {
+			t.Errorf("error %d = %q, want prefix %q", i, got[i], w)
+		}
+	}
+}
+
 // Test: Manager creates only enabled interceptors
 func TestManager_SkipsDisabled(t *testing.T) {
 	env := setupTestEnv(t)
@@ -562,7 +817,7 @@ func TestInterceptor_OutputHasHeader(t *testing.T) {
 	env := setupTestEnv(t)
 	logger := testLogger()
 
-	intc, err := New("test-hdr", "events.org.proj.hdr.>", "events.org.proj.hdrout.>", "", env.js, env.stream, logger)
+	intc, err := New("test-hdr", "events.org.proj.hdr.>", "events.org.proj.hdrout.>", nil, env.js, env.stream, logger)
 	if err != nil {
 		t.Fatalf("create interceptor: %v", err)
 	}
@@ -592,3 +847,482 @@ func TestInterceptor_OutputHasHeader(t *testing.T) {
 		t.Errorf("expected header %s=test-hdr, got %q", headerKey, val)
 	}
 }
+
+// waitForDLQ creates the DLQ stream and returns a function that waits for
+// the next dead-lettered message.
+func waitForDLQ(t *testing.T, env *testEnv) func(timeout time.Duration) notifnats.DLQMessage {
+	t.Helper()
+	dlq, err := env.js.CreateOrUpdateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "NOTIF_DLQ",
+		Subjects: []string{"dlq.>"},
+		Storage:  jetstream.MemoryStorage,
+	})
+	if err != nil {
+		t.Fatalf("create dlq stream: %v", err)
+	}
+	cons, err := dlq.CreateOrUpdateConsumer(context.Background(), jetstream.ConsumerConfig{
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		t.Fatalf("create dlq consumer: %v", err)
+	}
+	return func(timeout time.Duration) notifnats.DLQMessage {
+		t.Helper()
+		msg, err := cons.Next(jetstream.FetchMaxWait(timeout))
+		if err != nil {
+			t.Fatalf("waiting for DLQ message: %v", err)
+		}
+		_ = msg.Ack()
+		if msg.Subject() != "dlq.org.proj.inbound.msg" {
+			t.Errorf("unexpected DLQ subject %s", msg.Subject())
+		}
+		var entry notifnats.DLQMessage
+		if err := json.Unmarshal(msg.Data(), &entry); err != nil {
+			t.Fatalf("unmarshal DLQ message: %v", err)
+		}
+		return entry
+	}
+}
+
+// Test: a transform running past its timeout is aborted and dead-lettered
+func TestInterceptor_TransformTimeout(t *testing.T) {
+	env := setupTestEnv(t)
+	nextDLQ := waitForDLQ(t, env)
+
+	// Effectively endless: only the timeout can stop it
+	intc, err := New("test-slow", "events.org.proj.inbound.>", "events.org.proj.output.>", []string{`last(range(1e15))`}, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	intc.SetTimeout(100 * time.Millisecond)
+	intc.SetNakDelay(time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	start := time.Now()
+	if _, err := env.js.Publish(ctx, "events.org.proj.inbound.msg", []byte(`{"n":1}`)); err != nil {
+		t.Fatalf("publish test message: %v", err)
+	}
+
+	entry := nextDLQ(5 * time.Second)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("transform was not aborted promptly: %v", elapsed)
+	}
+	if !strings.Contains(entry.LastError, "timeout") {
+		t.Errorf("expected timeout error, got %q", entry.LastError)
+	}
+	if entry.OrgID != "org" || entry.ProjectID != "proj" || entry.OriginalTopic != "inbound.msg" {
+		t.Errorf("unexpected DLQ routing: %+v", entry)
+	}
+	if string(entry.Data) != `{"n":1}` {
+		t.Errorf("expected original payload, got %s", entry.Data)
+	}
+	if entry.ConsumerGroup != "interceptor-test-slow" {
+		t.Errorf("expected consumer group interceptor-test-slow, got %s", entry.ConsumerGroup)
+	}
+}
+
+// Test: payloads over the input size guard are dead-lettered without running jq
+func TestInterceptor_MaxInputSize(t *testing.T) {
+	env := setupTestEnv(t)
+	nextDLQ := waitForDLQ(t, env)
+
+	intc, err := New("test-size", "events.org.proj.inbound.>", "events.org.proj.output.>", []string{`.`}, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	intc.SetMaxInputSize(16)
+	intc.SetNakDelay(time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	payload := []byte(`{"text":"this payload is well over sixteen bytes"}`)
+	if _, err := env.js.Publish(ctx, "events.org.proj.inbound.msg", payload); err != nil {
+		t.Fatalf("publish test message: %v", err)
+	}
+
+	entry := nextDLQ(5 * time.Second)
+	if !strings.Contains(entry.LastError, "exceeds max input size") {
+		t.Errorf("expected size error, got %q", entry.LastError)
+	}
+}
+
+// Test: with on_error term, a failing transform is dead-lettered once and
+// not redelivered
+func TestInterceptor_OnErrorTerm(t *testing.T) {
+	env := setupTestEnv(t)
+	nextDLQ := waitForDLQ(t, env)
+
+	intc, err := New("test-term", "events.org.proj.inbound.>", "events.org.proj.output.>", []string{`error("boom")`}, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	intc.SetOnError(OnErrorTerm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	if _, err := env.js.Publish(ctx, "events.org.proj.inbound.msg", []byte(`{"n":1}`)); err != nil {
+		t.Fatalf("publish test message: %v", err)
+	}
+
+	entry := nextDLQ(5 * time.Second)
+	if entry.Attempts != 1 || !strings.Contains(entry.LastError, "boom") {
+		t.Errorf("expected one failed attempt with the jq error, got %+v", entry)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	cons, err := env.stream.Consumer(ctx, "interceptor-test-term")
+	if err != nil {
+		t.Fatalf("get consumer: %v", err)
+	}
+	info, err := cons.Info(ctx)
+	if err != nil {
+		t.Fatalf("consumer info: %v", err)
+	}
+	if info.NumRedelivered != 0 || info.NumAckPending != 0 || info.Delivered.Consumer != 1 {
+		t.Errorf("expected a single delivery, got %d delivered, %d redelivered, %d pending",
+			info.Delivered.Consumer, info.NumRedelivered, info.NumAckPending)
+	}
+}
+
+// Test: with the default nak policy, a failing transform is retried up to
+// max deliver and then dead-lettered
+func TestInterceptor_OnErrorNakCapped(t *testing.T) {
+	env := setupTestEnv(t)
+	nextDLQ := waitForDLQ(t, env)
+
+	intc, err := New("test-nak", "events.org.proj.inbound.>", "events.org.proj.output.>", []string{`error("boom")`}, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	intc.SetMaxDeliver(3)
+	intc.SetNakDelay(100 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	if _, err := env.js.Publish(ctx, "events.org.proj.inbound.msg", []byte(`{"n":1}`)); err != nil {
+		t.Fatalf("publish test message: %v", err)
+	}
+
+	start := time.Now()
+	if entry := nextDLQ(5 * time.Second); entry.Attempts != 3 {
+		t.Errorf("expected dead-lettering on the 3rd attempt, got attempt %d", entry.Attempts)
+	}
+	// Redelivered after 100ms, then 200ms
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("dead-lettered after %v, want redeliveries backed off", elapsed)
+	}
+}
+
+func TestInterceptor_NakBackoff(t *testing.T) {
+	intc := &Interceptor{nakDelay: time.Second}
+	for delivered, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		4:  8 * time.Second,
+		7:  MaxNakDelay,
+		50: MaxNakDelay,
+	} {
+		if got := intc.nakBackoff(delivered); got != want {
+			t.Errorf("nakBackoff(%d) = %v, want %v", delivered, got, want)
+		}
+	}
+}
+
+// Test: workers process messages concurrently, all of them get published
+func TestInterceptor_Workers(t *testing.T) {
+	env := setupTestEnv(t)
+
+	intc, err := New("test-workers", "events.org.proj.inbound.>", "events.org.proj.output.>", []string{`.n += 1`}, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	intc.SetWorkers(4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	const n = 20
+	for k := 0; k < n; k++ {
+		if _, err := env.js.Publish(ctx, "events.org.proj.inbound.msg", []byte(fmt.Sprintf(`{"n":%d}`, k))); err != nil {
+			t.Fatalf("publish test message: %v", err)
+		}
+	}
+
+	cons, err := env.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		FilterSubject: "events.org.proj.output.>",
+		AckPolicy:     jetstream.AckNonePolicy,
+	})
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	seen := make(map[string]bool)
+	deadline := time.Now().Add(10 * time.Second)
+	for len(seen) < n && time.Now().Before(deadline) {
+		msgs, err := cons.Fetch(n, jetstream.FetchMaxWait(time.Second))
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		for msg := range msgs.Messages() {
+			seen[string(msg.Data())] = true
+		}
+	}
+	if len(seen) != n {
+		t.Errorf("expected %d distinct messages, got %d", n, len(seen))
+	}
+}
+
+func TestLoadConfig_Limits(t *testing.T) {
+	content := `
+interceptors:
+  - name: bounded
+    from: "events.a.>"
+    to: "events.b.>"
+    jq: '.'
+    timeout: 250ms
+    max_input_bytes: 4096
+`
+	tmpFile := t.TempDir() + "/interceptors.yaml"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("write temp config: %v", err)
+	}
+
+	cfg, err := LoadConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Interceptors[0].Timeout != 250*time.Millisecond {
+		t.Errorf("expected timeout 250ms, got %v", cfg.Interceptors[0].Timeout)
+	}
+	if cfg.Interceptors[0].MaxInputBytes != 4096 {
+		t.Errorf("expected max_input_bytes 4096, got %d", cfg.Interceptors[0].MaxInputBytes)
+	}
+}
+
+// seedKV creates a KV bucket holding the given entries.
+func seedKV(t *testing.T, env *testEnv, bucket string, entries map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+	kv, err := env.js.CreateKeyValue(ctx, jetstream.KeyValueConfig{Bucket: bucket, Storage: jetstream.MemoryStorage})
+	if err != nil {
+		t.Fatalf("create KV bucket: %v", err)
+	}
+	for key, value := range entries {
+		if _, err := kv.PutString(ctx, key, value); err != nil {
+			t.Fatalf("put %s: %v", key, err)
+		}
+	}
+}
+
+func TestInterceptor_KVEnrichment(t *testing.T) {
+	env := setupTestEnv(t)
+	seedKV(t, env, "user-profiles", map[string]string{
+		"users.u_1": `{"name":"Alice","tier":"gold"}`,
+		"users.42":  `plain text`,
+	})
+
+	intc, err := New("test-enrich", "events.org.proj.orders.>", "events.org.proj.enriched.>", nil, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	enricher, err := NewEnricher(EnrichConfig{Bucket: "user-profiles", Key: "users.{data.user_id}", Into: "data.user"})
+	if err != nil {
+		t.Fatalf("create enricher: %v", err)
+	}
+	intc.SetEnricher(enricher)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	for subject, data := range map[string]string{
+		"events.org.proj.orders.found":   `{"id":"evt_1","data":{"user_id":"u_1","total":10}}`,
+		"events.org.proj.orders.number":  `{"id":"evt_2","data":{"user_id":42}}`,
+		"events.org.proj.orders.missing": `{"id":"evt_3","data":{"user_id":"u_404"}}`,
+	} {
+		if _, err := env.js.Publish(ctx, subject, []byte(data)); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	var found struct {
+		Data struct {
+			UserID string         `json:"user_id"`
+			Total  int            `json:"total"`
+			User   map[string]any `json:"user"`
+		} `json:"data"`
+	}
+	msg := waitForMessage(t, env, "events.org.proj.enriched.found", 5*time.Second)
+	if err := json.Unmarshal(msg.Data(), &found); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if found.Data.User["name"] != "Alice" || found.Data.User["tier"] != "gold" {
+		t.Errorf("expected profile merged into data.user, got %s", msg.Data())
+	}
+	if found.Data.UserID != "u_1" || found.Data.Total != 10 {
+		t.Errorf("expected original fields kept, got %s", msg.Data())
+	}
+
+	msg = waitForMessage(t, env, "events.org.proj.enriched.number", 5*time.Second)
+	if !strings.Contains(string(msg.Data()), `"user":"plain text"`) {
+		t.Errorf("expected numeric key lookup with a string value, got %s", msg.Data())
+	}
+
+	msg = waitForMessage(t, env, "events.org.proj.enriched.missing", 5*time.Second)
+	if string(msg.Data()) != `{"id":"evt_3","data":{"user_id":"u_404"}}` {
+		t.Errorf("expected missing key to pass through unchanged, got %s", msg.Data())
+	}
+}
+
+func TestInterceptor_KVEnrichmentBeforeJq(t *testing.T) {
+	env := setupTestEnv(t)
+	seedKV(t, env, "user-profiles", map[string]string{"u_1": `{"name":"Alice"}`})
+
+	cfg := &Config{Interceptors: []InterceptorConfig{{
+		Name:   "enrich-jq",
+		From:   "events.org.proj.orders.>",
+		To:     "events.org.proj.out.>",
+		Jq:     `{order: .id, customer: .profile.name}`,
+		Enrich: &EnrichConfig{Bucket: "user-profiles", Key: "{user_id}", Into: "profile"},
+	}}}
+	mgr, err := NewManager(cfg, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create manager: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := mgr.Start(ctx); err != nil {
+		t.Fatalf("start manager: %v", err)
+	}
+	defer mgr.Stop()
+
+	if _, err := env.js.Publish(ctx, "events.org.proj.orders.created", []byte(`{"id":"ord_1","user_id":"u_1"}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	msg := waitForMessage(t, env, "events.org.proj.out.>", 5*time.Second)
+	if string(msg.Data()) != `{"customer":"Alice","order":"ord_1"}` {
+		t.Errorf("expected jq to see the enriched payload, got %s", msg.Data())
+	}
+}
+
+func TestInterceptor_KVEnrichmentFailureDeadLetters(t *testing.T) {
+	env := setupTestEnv(t)
+	nextDLQ := waitForDLQ(t, env)
+	seedKV(t, env, "user-profiles", map[string]string{"users.u_1": `{"name":"Alice"}`})
+
+	intc, err := New("test-enrich", "events.org.proj.inbound.>", "events.org.proj.output.>", nil, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	enricher, err := NewEnricher(EnrichConfig{Bucket: "user-profiles", Key: "users.{user_id}", Into: "user"})
+	if err != nil {
+		t.Fatalf("create enricher: %v", err)
+	}
+	intc.SetEnricher(enricher)
+	intc.SetMaxDeliver(2)
+	intc.SetNakDelay(time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := intc.Start(ctx); err != nil {
+		t.Fatalf("start interceptor: %v", err)
+	}
+	defer intc.Stop()
+
+	// Every lookup fails from here on
+	if err := env.js.DeleteKeyValue(ctx, "user-profiles"); err != nil {
+		t.Fatalf("delete KV bucket: %v", err)
+	}
+	if _, err := env.js.Publish(ctx, "events.org.proj.inbound.msg", []byte(`{"user_id":"u_1"}`)); err != nil {
+		t.Fatalf("publish test message: %v", err)
+	}
+
+	entry := nextDLQ(5 * time.Second)
+	if entry.Attempts != 2 {
+		t.Errorf("expected dead-lettering on the 2nd attempt, got attempt %d", entry.Attempts)
+	}
+	if !strings.Contains(entry.LastError, "look up users.u_1") {
+		t.Errorf("expected the lookup error, got %q", entry.LastError)
+	}
+}
+
+func TestInterceptor_KVEnrichmentMissingBucket(t *testing.T) {
+	env := setupTestEnv(t)
+
+	intc, err := New("test-enrich", "events.org.proj.orders.>", "events.org.proj.enriched.>", nil, env.js, env.stream, testLogger())
+	if err != nil {
+		t.Fatalf("create interceptor: %v", err)
+	}
+	enricher, err := NewEnricher(EnrichConfig{Bucket: "absent", Key: "{user_id}", Into: "user"})
+	if err != nil {
+		t.Fatalf("create enricher: %v", err)
+	}
+	intc.SetEnricher(enricher)
+
+	if err := intc.Start(context.Background()); err == nil {
+		intc.Stop()
+		t.Fatal("expected start to fail for a missing bucket")
+	}
+}
+
+func TestValidate_Enrich(t *testing.T) {
+	tests := []struct {
+		enrich EnrichConfig
+		want   string
+	}{
+		{EnrichConfig{Key: "{user_id}", Into: "user"}, "enrich: bucket is required"},
+		{EnrichConfig{Bucket: "b", Into: "user"}, "enrich: key is required"},
+		{EnrichConfig{Bucket: "b", Key: "users.all", Into: "user"}, `enrich: key "users.all" has no {field} placeholder`},
+		{EnrichConfig{Bucket: "b", Key: "{user_id}.{x", Into: "user"}, `enrich: malformed placeholder in key "{user_id}.{x"`},
+		{EnrichConfig{Bucket: "b", Key: "{user_id}"}, "enrich: into is required"},
+		{EnrichConfig{Bucket: "b", Key: "{user_id}", Into: "data..user"}, `enrich: invalid into path "data..user"`},
+		{EnrichConfig{Bucket: "b", Key: "users.{data.user_id}", Into: "data.user"}, ""},
+	}
+	for _, tt := range tests {
+		enrich := tt.enrich
+		cfg := &Config{Interceptors: []InterceptorConfig{{
+			Name: "enrich", From: "events.a.>", To: "events.b.>", Enrich: &enrich,
+		}}}
+		errs := cfg.Validate()
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%+v: expected valid, got %v", tt.enrich, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].Error() != `interceptor "enrich": `+tt.want {
+			t.Errorf("%+v: got %v, want %q", tt.enrich, errs, tt.want)
+		}
+	}
+}
@@ -27,10 +27,50 @@ func NewManager(cfg *Config, js jetstream.JetStream, stream jetstream.Stream, lo
 			return nil, fmt.Errorf("duplicate interceptor name: %q", ic.Name)
 		}
 		seen[ic.Name] = true
-		intc, err := New(ic.Name, ic.From, ic.To, ic.Jq, js, stream, logger)
+		to := ic.To
+		var toTemplate *SubjectTemplate
+		if ic.ToTemplate != "" {
+			var err error
+			if toTemplate, err = ParseSubjectTemplate(ic.ToTemplate); err != nil {
+				return nil, fmt.Errorf("create interceptor %s: %w", ic.Name, err)
+			}
+			to = ic.ToTemplate // only used for logging once the template is set
+		}
+		intc, err := New(ic.Name, ic.From, to, ic.JqStages(), js, stream, logger)
 		if err != nil {
 			return nil, fmt.Errorf("create interceptor %s: %w", ic.Name, err)
 		}
+		if toTemplate != nil {
+			intc.SetToTemplate(toTemplate)
+		}
+		if ic.Enrich != nil {
+			enricher, err := NewEnricher(*ic.Enrich)
+			if err != nil {
+				return nil, fmt.Errorf("create interceptor %s: %w", ic.Name, err)
+			}
+			intc.SetEnricher(enricher)
+		}
+		if ic.Timeout > 0 {
+			intc.SetTimeout(ic.Timeout)
+		}
+		if ic.MaxInputBytes > 0 {
+			intc.SetMaxInputSize(ic.MaxInputBytes)
+		}
+		if ic.Workers > 0 {
+			intc.SetWorkers(ic.Workers)
+		}
+		if ic.OnError != "" {
+			if !validOnError(ic.OnError) {
+				return nil, fmt.Errorf("create interceptor %s: invalid on_error %q", ic.Name, ic.OnError)
+			}
+			intc.SetOnError(ic.OnError)
+		}
+		if ic.MaxDeliver > 0 {
+			intc.SetMaxDeliver(ic.MaxDeliver)
+		}
+		if ic.NakDelay > 0 {
+			intc.SetNakDelay(ic.NakDelay)
+		}
 		interceptors = append(interceptors, intc)
 	}
 	return &Manager{interceptors: interceptors, logger: logger}, nil
@@ -0,0 +1,97 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	notifnats "github.com/filipexyz/notif/internal/nats"
+)
+
+var placeholderRe = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// templateFields are the placeholders a to_template may use. Date parts come
+// from the event timestamp in UTC, zero-padded; org, project and topic come
+// from the source subject events.{org}.{project}.{topic}.
+var templateFields = map[string]string{
+	"year":    "2006",
+	"month":   "01",
+	"day":     "02",
+	"hour":    "15",
+	"org":     "",
+	"project": "",
+	"topic":   "",
+}
+
+// SubjectTemplate renders an output subject per message, e.g.
+// "events.{org}.{project}.{year}.{month}.out" routes into date partitions.
+type SubjectTemplate struct {
+	raw string
+}
+
+// ParseSubjectTemplate checks that every placeholder is known and that the
+// rendered subject is a literal subject inside the events stream.
+func ParseSubjectTemplate(raw string) (*SubjectTemplate, error) {
+	for _, m := range placeholderRe.FindAllStringSubmatch(raw, -1) {
+		if _, ok := templateFields[m[1]]; !ok {
+			return nil, fmt.Errorf("to_template: unknown placeholder {%s}", m[1])
+		}
+	}
+	t := &SubjectTemplate{raw: raw}
+
+	sample := t.Render("events.org.project.topic", time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC))
+	if strings.ContainsAny(sample, "{}") {
+		return nil, fmt.Errorf("to_template: malformed placeholder in %q", raw)
+	}
+	if err := notifnats.ValidateSubject(sample, false); err != nil {
+		return nil, fmt.Errorf("to_template: %v", err)
+	}
+	if !strings.HasPrefix(sample, "events.") {
+		return nil, fmt.Errorf("to_template: subject %q is outside the events stream (events.>)", raw)
+	}
+	return t, nil
+}
+
+// String returns the template as written.
+func (t *SubjectTemplate) String() string {
+	return t.raw
+}
+
+// Render fills the template for a message on subject stamped at ts. Subject
+// placeholders render as "unknown" when subject isn't events.{org}.{project}.{topic}.
+func (t *SubjectTemplate) Render(subject string, ts time.Time) string {
+	ts = ts.UTC()
+	parts := strings.SplitN(subject, ".", 4)
+	if len(parts) != 4 || parts[0] != "events" {
+		parts = []string{"events", "unknown", "unknown", "unknown"}
+	}
+	return placeholderRe.ReplaceAllStringFunc(t.raw, func(p string) string {
+		switch name := p[1 : len(p)-1]; name {
+		case "org":
+			return parts[1]
+		case "project":
+			return parts[2]
+		case "topic":
+			return parts[3]
+		default:
+			if layout, ok := templateFields[name]; ok {
+				return ts.Format(layout)
+			}
+			return p
+		}
+	})
+}
+
+// eventTimestamp returns the timestamp of a notif event payload, falling back
+// to fallback when the payload has none.
+func eventTimestamp(data []byte, fallback time.Time) time.Time {
+	var event struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil || event.Timestamp.IsZero() {
+		return fallback
+	}
+	return event.Timestamp
+}
@@ -0,0 +1,112 @@
+// Package metrics defines the server's Prometheus metrics.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server-wide metrics, recorded by the packages that do the work.
+var (
+	EventsEmitted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "notif_events_emitted_total",
+		Help: "Events emitted, by the first segment of their topic.",
+	}, []string{"topic_prefix"})
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "notif_webhook_deliveries_total",
+		Help: "Webhook delivery attempts, by outcome.",
+	}, []string{"status"})
+	WebhookDeliveryDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "notif_webhook_delivery_duration_seconds",
+		Help:    "Time spent on one webhook delivery attempt.",
+		Buckets: prometheus.DefBuckets,
+	})
+	ScheduleExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "notif_schedule_executions_total",
+		Help: "Scheduled event executions, by outcome.",
+	}, []string{"status"})
+	FanoutOverflow = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "notif_fanout_overflow_total",
+		Help: "Receivers past an event's fan-out limit, by topic prefix and whether they were shed or only logged.",
+	}, []string{"topic_prefix", "action"})
+)
+
+// TopicPrefix returns the first segment of topic, the label events are
+// counted under to keep cardinality bounded.
+func TopicPrefix(topic string) string {
+	prefix, _, _ := strings.Cut(topic, ".")
+	return prefix
+}
+
+// ObserveWebhookDelivery records one webhook delivery attempt.
+func ObserveWebhookDelivery(ok bool, elapsed time.Duration) {
+	status := "success"
+	if !ok {
+		status = "failed"
+	}
+	WebhookDeliveries.WithLabelValues(status).Inc()
+	WebhookDeliveryDuration.Observe(elapsed.Seconds())
+}
+
+// NewRegistry creates a registry holding the server-wide metrics, the Go
+// runtime and process collectors, and the given collectors.
+func NewRegistry(cs ...prometheus.Collector) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		EventsEmitted,
+		WebhookDeliveries,
+		WebhookDeliveryDuration,
+		ScheduleExecutions,
+		FanoutOverflow,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	reg.MustRegister(cs...)
+	return reg
+}
+
+// Handler serves reg in the Prometheus exposition format.
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})
+}
+
+// Sample is one gauge value reported by a GaugeFunc.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a gauge whose values are read when the registry is scraped.
+// Unlike prometheus.GaugeFunc it may report one value per label set, e.g.
+// one per org.
+type GaugeFunc struct {
+	desc *prometheus.Desc
+	fn   func() []Sample
+}
+
+// NewGaugeFunc creates a gauge that calls fn on every scrape.
+func NewGaugeFunc(name, help string, labels []string, fn func() []Sample) *GaugeFunc {
+	return &GaugeFunc{desc: prometheus.NewDesc(name, help, labels, nil), fn: fn}
+}
+
+// Describe implements prometheus.Collector.
+func (g *GaugeFunc) Describe(ch chan<- *prometheus.Desc) {
+	ch <- g.desc
+}
+
+// Collect implements prometheus.Collector.
+func (g *GaugeFunc) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range g.fn() {
+		m, err := prometheus.NewConstMetric(g.desc, prometheus.GaugeValue, s.Value, s.LabelValues...)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(g.desc, err)
+			continue
+		}
+		ch <- m
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGaugeFunc_Collect(t *testing.T) {
+	connected := NewGaugeFunc("test_connected", "Connected.", []string{"org_id"}, func() []Sample {
+		return []Sample{{LabelValues: []string{"org_b"}, Value: 0}, {LabelValues: []string{"org_a"}, Value: 1}}
+	})
+
+	want := `# HELP test_connected Connected.
+# TYPE test_connected gauge
+test_connected{org_id="org_a"} 1
+test_connected{org_id="org_b"} 0
+`
+	if err := testutil.CollectAndCompare(connected, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	EventsEmitted.WithLabelValues("handlertest").Inc()
+	gauge := NewGaugeFunc("test_gauge", "Test.", nil, func() []Sample {
+		return []Sample{{Value: 3}}
+	})
+
+	rec := httptest.NewRecorder()
+	Handler(NewRegistry(gauge)).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"test_gauge 3\n",
+		`notif_events_emitted_total{topic_prefix="handlertest"} 1` + "\n",
+		"# TYPE notif_webhook_delivery_duration_seconds histogram\n",
+		"go_goroutines ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q", want)
+		}
+	}
+}
+
+func TestTopicPrefix(t *testing.T) {
+	for topic, want := range map[string]string{"orders.created": "orders", "orders": "orders", "a.b.c": "a"} {
+		if got := TopicPrefix(topic); got != want {
+			t.Errorf("TopicPrefix(%q) = %q, want %q", topic, got, want)
+		}
+	}
+}
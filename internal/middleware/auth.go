@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/filipexyz/notif/internal/db"
 	"github.com/filipexyz/notif/internal/domain"
@@ -48,6 +49,10 @@ func (a *Auth) Handler(next http.Handler) http.Handler {
 			writeError(w, http.StatusUnauthorized, "invalid api key")
 			return
 		}
+		if apiKeyExpired(&apiKey, time.Now()) {
+			writeErrorCode(w, http.StatusUnauthorized, "KEY_EXPIRED", "api key expired")
+			return
+		}
 
 		// Update last used (async, don't block request)
 		go func() {
@@ -107,6 +112,11 @@ func ClerkQueryParamAuth() func(http.Handler) http.Handler {
 	}
 }
 
+// apiKeyExpired reports whether key's expiry has passed at now.
+func apiKeyExpired(key *db.GetAPIKeyByHashRow, now time.Time) bool {
+	return key.ExpiresAt.Valid && !now.Before(key.ExpiresAt.Time)
+}
+
 func hashKey(key string) string {
 	h := sha256.Sum256([]byte(key))
 	return hex.EncodeToString(h[:])
@@ -117,3 +127,10 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
+
+// writeErrorCode writes an error with a machine-readable code.
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message, "code": code})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestAPIKeyExpired(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		name      string
+		expiresAt pgtype.Timestamptz
+		want      bool
+	}{
+		{"no expiry", pgtype.Timestamptz{}, false},
+		{"future", pgtype.Timestamptz{Time: now.Add(time.Hour), Valid: true}, false},
+		{"now", pgtype.Timestamptz{Time: now, Valid: true}, true},
+		{"past", pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true}, true},
+	} {
+		key := &db.GetAPIKeyByHashRow{ExpiresAt: tc.expiresAt}
+		if got := apiKeyExpired(key, now); got != tc.want {
+			t.Errorf("%s: expired = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWriteErrorCode(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeErrorCode(rec, http.StatusUnauthorized, "KEY_EXPIRED", "api key expired")
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["code"] != "KEY_EXPIRED" || body["error"] != "api key expired" {
+		t.Errorf("body = %v", body)
+	}
+}
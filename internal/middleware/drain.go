@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DrainRetryAfter is the Retry-After hint sent with requests refused because
+// the org is drained.
+const DrainRetryAfter = 30 * time.Second
+
+// RejectDrained refuses requests from orgs that isDrained reports as drained
+// for maintenance with 503 and a Retry-After hint. Must run after UnifiedAuth.
+func RejectDrained(isDrained func(orgID string) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if authCtx := GetAuthContext(r.Context()); authCtx != nil && isDrained(authCtx.OrgID) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(DrainRetryAfter.Seconds())))
+				writeError(w, http.StatusServiceUnavailable, "org is drained for maintenance, retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRejectDrained(t *testing.T) {
+	drained := map[string]bool{"org_maint": true}
+	handler := RejectDrained(func(orgID string) bool { return drained[orgID] })(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	request := func(orgID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/emit", nil)
+		if orgID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), authCtxKey, &AuthContext{OrgID: orgID}))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("org_maint")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("drained org: expected 503, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("expected Retry-After 30, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	if rec := request("org_live"); rec.Code != http.StatusOK {
+		t.Errorf("other org: expected 200, got %d", rec.Code)
+	}
+
+	// Resuming lets the org through again
+	delete(drained, "org_maint")
+	if rec := request("org_maint"); rec.Code != http.StatusOK {
+		t.Errorf("resumed org: expected 200, got %d", rec.Code)
+	}
+}
@@ -1,30 +1,144 @@
 package middleware
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-// Logger is a request logging middleware using slog.
-func Logger(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
-
-		defer func() {
-			slog.Info("request",
-				"method", r.Method,
-				"path", r.URL.Path,
-				"status", ww.Status(),
-				"duration_ms", time.Since(start).Milliseconds(),
-				"bytes", ww.BytesWritten(),
-				"request_id", middleware.GetReqID(r.Context()),
-			)
-		}()
-
-		next.ServeHTTP(ww, r)
-	})
+// Access log modes (ACCESS_LOG).
+const (
+	AccessLogOff   = "off"
+	AccessLogInfo  = "info"
+	AccessLogDebug = "debug"
+)
+
+// redactedHeaders are never written to the access log.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+}
+
+// redactedQueryParams are query parameters whose values are never written to
+// the access log: websocket and SSE clients pass API keys and Clerk JWTs as
+// ?token=, since browsers can't set headers there. Matched case-insensitively.
+var redactedQueryParams = map[string]bool{
+	"token":        true,
+	"access_token": true,
+	"api_key":      true,
+	"apikey":       true,
+	"key":          true,
+	"secret":       true,
+	"password":     true,
+	"signature":    true,
+}
+
+// accessInfo collects what inner handlers learn about a request, for the
+// access log record written once it completes.
+type accessInfo struct {
+	projectID string
+}
+
+type accessInfoKey struct{}
+
+// noteAccessProject records the request's project for the access log.
+func noteAccessProject(ctx context.Context, projectID string) {
+	if info, ok := ctx.Value(accessInfoKey{}).(*accessInfo); ok {
+		info.projectID = projectID
+	}
+}
+
+// NewAccessLogger returns the logger for access records, separate from the
+// application logger so LOG_LEVEL doesn't hide them. format is LOG_FORMAT.
+func NewAccessLogger(w io.Writer, format string) *slog.Logger {
+	var handler slog.Handler
+	if format == "text" {
+		handler = slog.NewTextHandler(w, nil)
+	} else {
+		handler = slog.NewJSONHandler(w, nil)
+	}
+	return slog.New(handler).With("log", "access")
+}
+
+// AccessLog writes one "access" record per request to logger: method, path,
+// status, duration, bytes, request and project IDs. In debug mode the query,
+// client address, user agent and headers are added, with credentials
+// redacted. Mode off disables it.
+func AccessLog(logger *slog.Logger, mode string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if mode == AccessLogOff {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			info := &accessInfo{}
+			r = r.WithContext(context.WithValue(r.Context(), accessInfoKey{}, info))
+
+			defer func() {
+				attrs := []any{
+					"method", r.Method,
+					"path", r.URL.Path,
+					"status", ww.Status(),
+					"duration_ms", time.Since(start).Milliseconds(),
+					"bytes", ww.BytesWritten(),
+					"request_id", middleware.GetReqID(r.Context()),
+					"project_id", info.projectID,
+				}
+				if mode == AccessLogDebug {
+					attrs = append(attrs,
+						"query", accessQuery(r.URL.RawQuery),
+						"remote_addr", r.RemoteAddr,
+						"user_agent", r.UserAgent(),
+						"headers", accessHeaders(r.Header),
+					)
+				}
+				logger.Info("access", attrs...)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// accessHeaders flattens h for logging, redacting credentials.
+func accessHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name, values := range h {
+		if redactedHeaders[name] {
+			headers[name] = "[REDACTED]"
+			continue
+		}
+		if len(values) > 0 {
+			headers[name] = values[0]
+		}
+	}
+	return headers
+}
+
+// accessQuery returns rawQuery for logging, with the values of credential
+// parameters redacted. The other parameters are kept as sent.
+func accessQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if redactedQueryParams[strings.ToLower(name)] {
+			params[i] = name + "=[REDACTED]"
+		}
+	}
+	return strings.Join(params, "&")
 }
@@ -124,42 +124,7 @@ func (rl *RateLimiter) Allow(key string, ratePerSecond, burst int) bool {
 func RateLimit(rl *RateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			var key string
-			var ratePerSecond, burst int
-
-			// Check if we have an authenticated context with API key info
-			authCtx := GetAuthContext(r.Context())
-
-			if authCtx != nil && authCtx.APIKeyID != nil {
-				// Use API key ID as the rate limit key
-				key = "apikey:" + authCtx.APIKeyID.String()
-
-				// Get rate limit from context (set by auth middleware)
-				if customRate := GetRateLimit(r.Context()); customRate > 0 {
-					ratePerSecond = customRate
-					burst = customRate * 2 // Allow burst of 2x the rate
-				} else {
-					ratePerSecond = rl.config.DefaultRatePerSecond
-					burst = rl.config.DefaultBurst
-				}
-			} else if authCtx != nil && authCtx.UserID != nil {
-				// Clerk user - use user ID as key
-				key = "user:" + *authCtx.UserID
-				ratePerSecond = rl.config.DefaultRatePerSecond
-				burst = rl.config.DefaultBurst
-			} else {
-				// Unauthenticated - use IP as key with stricter limits
-				// Extract IP without port
-				ip := r.RemoteAddr
-				if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
-					ip = host
-				}
-				key = "ip:" + ip
-				ratePerSecond = rl.config.UnauthRatePerSecond
-				burst = rl.config.UnauthBurst
-			}
-
-			if !rl.Allow(key, ratePerSecond, burst) {
+			if ok, ratePerSecond := rl.AllowRequest(r); !ok {
 				w.Header().Set("Content-Type", "application/json")
 				w.Header().Set("Retry-After", "1")
 				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(ratePerSecond))
@@ -174,6 +139,67 @@ func RateLimit(rl *RateLimiter) func(http.Handler) http.Handler {
 	}
 }
 
+// AllowRequest takes a token for r's caller, keyed and limited the way
+// RateLimit does, and returns the caller's rate. Websocket connections call
+// it for each action they count as a request, such as an emit.
+func (rl *RateLimiter) AllowRequest(r *http.Request) (bool, int) {
+	return rl.AllowRequestN(r, 1)
+}
+
+// AllowRequestN takes n tokens for r's caller, for a request that counts as
+// n, such as a batch of n events. One larger than the caller's burst is
+// admitted when the burst is available and leaves the bucket in debt, so
+// the caller's next requests wait until the rate has paid for it.
+func (rl *RateLimiter) AllowRequestN(r *http.Request, n int) (bool, int) {
+	var key string
+	var ratePerSecond, burst int
+
+	// Check if we have an authenticated context with API key info
+	authCtx := GetAuthContext(r.Context())
+
+	if authCtx != nil && authCtx.APIKeyID != nil {
+		// Use API key ID as the rate limit key
+		key = "apikey:" + authCtx.APIKeyID.String()
+
+		// Get rate limit from context (set by auth middleware)
+		if customRate := GetRateLimit(r.Context()); customRate > 0 {
+			ratePerSecond = customRate
+			burst = customRate * 2 // Allow burst of 2x the rate
+		} else {
+			ratePerSecond = rl.config.DefaultRatePerSecond
+			burst = rl.config.DefaultBurst
+		}
+	} else if authCtx != nil && authCtx.UserID != nil {
+		// Clerk user - use user ID as key
+		key = "user:" + *authCtx.UserID
+		ratePerSecond = rl.config.DefaultRatePerSecond
+		burst = rl.config.DefaultBurst
+	} else {
+		// Unauthenticated - use IP as key with stricter limits
+		// Extract IP without port
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			ip = host
+		}
+		key = "ip:" + ip
+		ratePerSecond = rl.config.UnauthRatePerSecond
+		burst = rl.config.UnauthBurst
+	}
+
+	if n <= 0 {
+		return true, ratePerSecond
+	}
+	limiter := rl.getLimiter(key, ratePerSecond, burst)
+	now := time.Now()
+	if !limiter.AllowN(now, min(n, burst)) {
+		return false, ratePerSecond
+	}
+	for rest := n - burst; rest > 0; rest -= burst {
+		limiter.ReserveN(now, min(rest, burst))
+	}
+	return true, ratePerSecond
+}
+
 // Context key for rate limit
 type rateLimitKey struct{}
 
@@ -158,6 +158,39 @@ func TestRateLimitMiddleware_UnauthenticatedByIP(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_AllowRequestN(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{
+		UnauthRatePerSecond: 5,
+		UnauthBurst:         5,
+		CleanupInterval:     time.Minute,
+		MaxAge:              time.Minute,
+	})
+	defer rl.Stop()
+
+	req := httptest.NewRequest("POST", "/emit/batch", nil)
+	req.RemoteAddr = "192.168.1.2:12345"
+
+	if ok, _ := rl.AllowRequestN(req, 3); !ok {
+		t.Fatal("3 of 5 tokens should be allowed")
+	}
+	if ok, _ := rl.AllowRequestN(req, 3); ok {
+		t.Error("3 more tokens should be rate limited with 2 left")
+	}
+	if ok, _ := rl.AllowRequestN(req, 2); !ok {
+		t.Error("the remaining 2 tokens should be allowed")
+	}
+
+	// Larger than the burst: admitted on a full bucket, then in debt
+	other := httptest.NewRequest("POST", "/emit/batch", nil)
+	other.RemoteAddr = "192.168.1.3:12345"
+	if ok, _ := rl.AllowRequestN(other, 12); !ok {
+		t.Fatal("a batch larger than the burst should be allowed on a full bucket")
+	}
+	if ok, _ := rl.AllowRequest(other); ok {
+		t.Error("request after the oversized batch should be rate limited")
+	}
+}
+
 func TestRateLimitMiddleware_CustomRateFromContext(t *testing.T) {
 	config := RateLimitConfig{
 		DefaultRatePerSecond: 5,
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+// requiredScope returns the API key scope a request needs, or "" when any
+// key may make it. Reads are open to every key, except API key listings and
+// the audit log; writes without a scope of their own need admin.
+func requiredScope(method, path string) string {
+	switch path {
+	case "/ws":
+		return domain.ScopeSubscribe
+	case "/ws/terminal":
+		return domain.ScopeAdmin
+	}
+
+	route, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return ""
+	}
+	resource, _, _ := strings.Cut(route, "/")
+	if resource == "api-keys" || resource == "audit" {
+		return domain.ScopeAdmin
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ""
+	}
+
+	switch resource {
+	case "emit", "blobs", "schedules":
+		return domain.ScopeEmit
+	case "schemas":
+		if strings.HasSuffix(route, "/validate") {
+			return ""
+		}
+		return domain.ScopeSchemasWrite
+	case "webhooks":
+		return domain.ScopeWebhooksWrite
+	}
+	return domain.ScopeAdmin
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+func TestRequiredScope(t *testing.T) {
+	for _, tc := range []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/ws", domain.ScopeSubscribe},
+		{http.MethodPost, "/api/v1/emit", domain.ScopeEmit},
+		{http.MethodPost, "/api/v1/emit/batch", domain.ScopeEmit},
+		{http.MethodPost, "/api/v1/schemas", domain.ScopeSchemasWrite},
+		{http.MethodPut, "/api/v1/schemas/order", domain.ScopeSchemasWrite},
+		{http.MethodPost, "/api/v1/schemas/order/validate", ""},
+		{http.MethodDelete, "/api/v1/webhooks/wh_1", domain.ScopeWebhooksWrite},
+		{http.MethodPost, "/api/v1/dlq/replay-all", domain.ScopeAdmin},
+		{http.MethodGet, "/api/v1/api-keys", domain.ScopeAdmin},
+		{http.MethodGet, "/api/v1/audit", domain.ScopeAdmin},
+		{http.MethodGet, "/api/v1/events", ""},
+		{http.MethodGet, "/api/v1/whoami", ""},
+	} {
+		if got := requiredScope(tc.method, tc.path); got != tc.want {
+			t.Errorf("requiredScope(%s %s) = %q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestRequiredScope_Keys(t *testing.T) {
+	allowed := func(scopes []string, method, path string) bool {
+		scope := requiredScope(method, path)
+		return scope == "" || domain.HasScope(scopes, scope)
+	}
+
+	emitOnly := []string{domain.ScopeEmit}
+	if !allowed(emitOnly, http.MethodPost, "/api/v1/emit") {
+		t.Error("emit-only key was refused /emit")
+	}
+	if allowed(emitOnly, http.MethodGet, "/ws") {
+		t.Error("emit-only key was allowed to subscribe")
+	}
+	if allowed([]string{domain.ScopeSubscribe}, http.MethodPost, "/api/v1/emit") {
+		t.Error("subscribe-only key was allowed to emit")
+	}
+
+	// Keys from before scopes existed keep full access, as do admin keys
+	for _, scopes := range [][]string{nil, {domain.ScopeAdmin}} {
+		for _, path := range []string{"/ws", "/api/v1/emit", "/api/v1/webhooks", "/api/v1/api-keys"} {
+			if !allowed(scopes, http.MethodPost, path) {
+				t.Errorf("key with scopes %v was refused %s", scopes, path)
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 	"github.com/filipexyz/notif/internal/config"
@@ -23,6 +24,7 @@ type AuthContext struct {
 	ProjectID string     // Project ID - derived from API key or X-Project-ID header
 	APIKeyID  *uuid.UUID // Set if authenticated via API key
 	UserID    *string    // Set if authenticated via Clerk
+	ExpiresAt *time.Time // When the Clerk session token or the API key expires, if it does
 }
 
 // UnifiedAuth creates middleware that accepts both API key and Clerk auth.
@@ -40,6 +42,15 @@ func UnifiedAuth(queries *db.Queries, cfg *config.Config) func(http.Handler) htt
 					keyHash := hashKey(token)
 					apiKey, err := queries.GetAPIKeyByHash(r.Context(), keyHash)
 					if err == nil {
+						if apiKeyExpired(&apiKey, time.Now()) {
+							writeErrorCode(w, http.StatusUnauthorized, "KEY_EXPIRED", "api key expired")
+							return
+						}
+						if scope := requiredScope(r.Method, r.URL.Path); scope != "" && !domain.HasScope(apiKey.Scopes, scope) {
+							writeError(w, http.StatusForbidden, "api key lacks the "+scope+" scope")
+							return
+						}
+
 						// Valid API key - derive project from API key
 						keyID := uuid.UUID(apiKey.ID.Bytes)
 						authCtx = &AuthContext{
@@ -47,6 +58,9 @@ func UnifiedAuth(queries *db.Queries, cfg *config.Config) func(http.Handler) htt
 							ProjectID: apiKey.ProjectID,
 							APIKeyID:  &keyID,
 						}
+						if apiKey.ExpiresAt.Valid {
+							authCtx.ExpiresAt = &apiKey.ExpiresAt.Time
+						}
 
 						// Update last used (async)
 						go func() {
@@ -55,7 +69,7 @@ func UnifiedAuth(queries *db.Queries, cfg *config.Config) func(http.Handler) htt
 
 						// Also store API key in context for handlers that need it
 						ctx := context.WithValue(r.Context(), apiKeyContextKey, &apiKey)
-						ctx = context.WithValue(ctx, authCtxKey, authCtx)
+						ctx = SetAuthContext(ctx, authCtx)
 
 						// Store rate limit from API key for rate limiter middleware
 						if apiKey.RateLimitPerSecond.Valid && apiKey.RateLimitPerSecond.Int32 > 0 {
@@ -112,6 +126,10 @@ func UnifiedAuth(queries *db.Queries, cfg *config.Config) func(http.Handler) htt
 					ProjectID: projectID,
 					UserID:    &userID,
 				}
+				if claims.Expiry != nil {
+					expiresAt := time.Unix(*claims.Expiry, 0)
+					authCtx.ExpiresAt = &expiresAt
+				}
 
 				// Store clerk session for handlers that need it
 				session := &ClerkSession{
@@ -119,7 +137,7 @@ func UnifiedAuth(queries *db.Queries, cfg *config.Config) func(http.Handler) htt
 					OrgID:  orgID,
 				}
 				ctx := context.WithValue(r.Context(), clerkSessionKey, session)
-				ctx = context.WithValue(ctx, authCtxKey, authCtx)
+				ctx = SetAuthContext(ctx, authCtx)
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
@@ -162,6 +180,12 @@ func RequireClerkAuth(cfg *config.Config) func(http.Handler) http.Handler {
 	}
 }
 
+// SetAuthContext stores the auth context in ctx, as the auth middleware does.
+func SetAuthContext(ctx context.Context, authCtx *AuthContext) context.Context {
+	noteAccessProject(ctx, authCtx.ProjectID)
+	return context.WithValue(ctx, authCtxKey, authCtx)
+}
+
 // GetAuthContext retrieves the auth context from the request.
 func GetAuthContext(ctx context.Context) *AuthContext {
 	authCtx, _ := ctx.Value(authCtxKey).(*AuthContext)
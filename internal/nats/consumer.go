@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -14,14 +16,83 @@ import (
 
 // SubscriptionOptions configures a consumer subscription.
 type SubscriptionOptions struct {
-	Topics     []string
-	OrgID      string // Required: filter by organization
-	ProjectID  string // Required: filter by project
+	Topics    []string
+	OrgID     string // Required: filter by organization
+	ProjectID string // Required: filter by project
+	// ProjectIDs, when set, subscribes across these projects instead of
+	// ProjectID. Callers must check the key is authorized for each.
+	ProjectIDs []string
 	Group      string // Empty = ephemeral, non-empty = durable consumer group
 	AutoAck    bool
 	MaxRetries int
 	AckTimeout time.Duration
-	From       string // "latest" (default), "beginning", or timestamp
+	From       string // "latest" (default), "beginning", timestamp, resume token, or "resume"
+	FromSeq    uint64 // Starts at this stream sequence instead of From
+
+	// AttemptLimit, when lower than MaxRetries, caps JetStream's own
+	// redeliveries instead, e.g. at the subscribed topics' DLQ policy.
+	AttemptLimit int
+
+	// ResumeToken is the position a From "resume" subscription continues
+	// from. Without a group, such a subscription is a durable resume
+	// cursor: reconnecting with any token of the cursor picks up exactly
+	// where its last connection left off, unacked events included.
+	ResumeToken string
+
+	// Sequential makes a consumer group hand out one event at a time: the
+	// next is not delivered to any member until the previous one is acked
+	// or given up on, and redeliveries go out before newer events. It
+	// serializes the whole group, not each key.
+	Sequential bool
+
+	// MaxInFlight caps the consumer's unacked events (JetStream's
+	// MaxAckPending); 0 keeps the server default. A group shares one cap
+	// across its members, fixed when the group is created.
+	MaxInFlight int
+}
+
+// Bounds applied to client-requested subscription options.
+const (
+	MaxSubscriptionRetries  = 100
+	MinAckTimeout           = time.Second
+	MaxAckTimeout           = time.Hour
+	MaxSubscriptionInFlight = 10000
+)
+
+// ParseAckDeadline parses an event's ack_deadline hint, e.g. "15m". It must
+// fall within the bounds of a subscription's ack timeout.
+func ParseAckDeadline(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ack_deadline %q: want a duration like \"30s\" or \"15m\"", s)
+	}
+	if d < MinAckTimeout || d > MaxAckTimeout {
+		return 0, fmt.Errorf("ack_deadline must be between %s and %s", MinAckTimeout, MaxAckTimeout)
+	}
+	return d, nil
+}
+
+// ErrBeforeRetention is returned for a subscription starting before the
+// oldest event the stream still retains.
+var ErrBeforeRetention = errors.New("start is outside the stream's retention")
+
+// ErrInvalidFrom is returned for a From that is none of latest, beginning,
+// resume, a resume token or an RFC3339 time.
+var ErrInvalidFrom = errors.New("invalid from")
+
+// ValidateFrom checks a subscription's From.
+func ValidateFrom(from string) error {
+	switch from {
+	case "", "latest", "beginning", "resume":
+		return nil
+	}
+	if _, ok := ParseResumeToken(from); ok {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, from); err != nil {
+		return fmt.Errorf("%w %q: want latest, beginning, resume, a resume token or an RFC3339 time", ErrInvalidFrom, from)
+	}
+	return nil
 }
 
 // DefaultSubscriptionOptions returns sensible defaults.
@@ -33,14 +104,132 @@ func DefaultSubscriptionOptions() SubscriptionOptions {
 	}
 }
 
+// Clamp bounds MaxRetries and AckTimeout to the supported range and
+// normalizes a resume From to the deliver policy that will actually be
+// used. Other From values are checked by ValidateFrom, not rewritten.
+func (o *SubscriptionOptions) Clamp() {
+	if o.MaxRetries < 1 {
+		o.MaxRetries = 1
+	}
+	if o.MaxRetries > MaxSubscriptionRetries {
+		o.MaxRetries = MaxSubscriptionRetries
+	}
+	if o.AckTimeout < MinAckTimeout {
+		o.AckTimeout = MinAckTimeout
+	}
+	if o.AckTimeout > MaxAckTimeout {
+		o.AckTimeout = MaxAckTimeout
+	}
+	if o.MaxInFlight < 0 {
+		o.MaxInFlight = 0
+	}
+	if o.MaxInFlight > MaxSubscriptionInFlight {
+		o.MaxInFlight = MaxSubscriptionInFlight
+	}
+	switch o.From {
+	case "":
+		if o.FromSeq == 0 {
+			o.From = "latest"
+		}
+	case "resume":
+		seq, ok := ParseResumeToken(o.ResumeToken)
+		if !ok {
+			o.From, o.ResumeToken = "latest", ""
+			break
+		}
+		// A plain token starts a new cursor
+		if ResumeCursor(o.ResumeToken) == "" && o.Group == "" {
+			o.ResumeToken = CursorResumeToken(NewResumeCursor(), seq)
+		}
+	}
+}
+
+// Projects returns the projects the subscription spans: ProjectIDs if set,
+// else just ProjectID.
+func (o *SubscriptionOptions) Projects() []string {
+	if len(o.ProjectIDs) > 0 {
+		return o.ProjectIDs
+	}
+	return []string{o.ProjectID}
+}
+
+// filterSubjects returns the stream subjects the subscription's topics
+// cover in each of its projects.
+func (o *SubscriptionOptions) filterSubjects() []string {
+	projects := o.Projects()
+	topics := NormalizeTopics(o.Topics)
+	subjects := make([]string, 0, len(projects)*len(topics))
+	for _, projectID := range projects {
+		for _, topic := range topics {
+			subjects = append(subjects, "events."+o.OrgID+"."+projectID+"."+topic)
+		}
+	}
+	return subjects
+}
+
+// DefaultGroupTTL is how long a consumer group may sit with no members
+// before JetStream deletes its durable consumer.
+const DefaultGroupTTL = 72 * time.Hour
+
+// DefaultResumeTTL is how long a resume cursor may sit unused before
+// JetStream deletes its durable consumer. Cursors exist to bridge
+// reconnects, which are quick, and every SDK connection makes one.
+const DefaultResumeTTL = time.Hour
+
 // ConsumerManager manages NATS consumers for subscriptions.
 type ConsumerManager struct {
-	stream jetstream.Stream
+	stream    jetstream.Stream
+	groupTTL  time.Duration
+	resumeTTL time.Duration
 }
 
 // NewConsumerManager creates a new ConsumerManager.
 func NewConsumerManager(stream jetstream.Stream) *ConsumerManager {
-	return &ConsumerManager{stream: stream}
+	return &ConsumerManager{stream: stream, groupTTL: DefaultGroupTTL, resumeTTL: DefaultResumeTTL}
+}
+
+// SetGroupTTL sets how long an abandoned consumer group is retained.
+// Zero keeps group consumers forever.
+func (cm *ConsumerManager) SetGroupTTL(ttl time.Duration) {
+	cm.groupTTL = ttl
+}
+
+// SetResumeTTL sets how long an unused resume cursor is retained. Zero
+// keeps cursors forever.
+func (cm *ConsumerManager) SetResumeTTL(ttl time.Duration) {
+	cm.resumeTTL = ttl
+}
+
+// checkRetention returns ErrBeforeRetention if the stream's MaxAge has
+// already discarded events from start.
+func (cm *ConsumerManager) checkRetention(ctx context.Context, start time.Time) error {
+	info, err := cm.stream.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("get stream info: %w", err)
+	}
+	maxAge := info.Config.MaxAge
+	if maxAge <= 0 {
+		return nil
+	}
+	if oldest := time.Now().Add(-maxAge); start.Before(oldest) {
+		return fmt.Errorf("%w: the stream keeps %s of events, so from must be after %s",
+			ErrBeforeRetention, maxAge, oldest.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkRetentionSeq returns ErrBeforeRetention if the stream has already
+// discarded the event at seq.
+func (cm *ConsumerManager) checkRetentionSeq(ctx context.Context, seq uint64) error {
+	info, err := cm.stream.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("get stream info: %w", err)
+	}
+	if first := info.State.FirstSeq; seq < first {
+		return fmt.Errorf("%w: the stream's oldest event is sequence %d, so from_seq must be at least that",
+			ErrBeforeRetention, first)
+	}
+	return nil
 }
 
 // CreateConsumer creates a JetStream consumer for the given options.
@@ -56,57 +245,120 @@ func (cm *ConsumerManager) CreateConsumer(ctx context.Context, opts Subscription
 	// Convert topics to NATS subjects with org and project isolation
 	// "leads.*" -> "events.{org_id}.{project_id}.leads.*"
 	// "agent.*.error" -> "events.{org_id}.{project_id}.agent.*.error"
+	// "a.*.b.>" -> "events.{org_id}.{project_id}.a.*.b.>"
 	// Special case: "*" -> "events.{org_id}.{project_id}.>" (match all topics)
 	// In NATS, "*" matches exactly one token, while ">" matches one or more tokens.
 	// A standalone "*" subscription should match all topics, including multi-segment
-	// ones like "orders.created", so we convert it to ">" which is the NATS wildcard
-	// for "one or more tokens".
-	filterSubjects := make([]string, len(opts.Topics))
-	for i, topic := range opts.Topics {
-		if topic == "*" {
-			// Standalone "*" means "all topics" - use ">" to match any depth
-			filterSubjects[i] = "events." + opts.OrgID + "." + opts.ProjectID + ".>"
-		} else {
-			filterSubjects[i] = "events." + opts.OrgID + "." + opts.ProjectID + "." + topic
-		}
-	}
+	// ones like "orders.created", so NormalizeTopics converts it to ">" which is the
+	// NATS wildcard for "one or more tokens". It also drops topics another one
+	// covers, which JetStream would otherwise reject as overlapping.
+	filterSubjects := opts.filterSubjects()
 
 	// Determine deliver policy based on From option
 	deliverPolicy := jetstream.DeliverNewPolicy // Default: only new messages
 	var optStartTime time.Time
+	var optStartSeq uint64
 	switch opts.From {
 	case "", "latest":
 		deliverPolicy = jetstream.DeliverNewPolicy
 	case "beginning":
 		deliverPolicy = jetstream.DeliverAllPolicy
+	case "resume":
+		if seq, ok := ParseResumeToken(opts.ResumeToken); ok {
+			deliverPolicy = jetstream.DeliverByStartSequencePolicy
+			optStartSeq = seq + 1
+		}
 	default:
-		// Try to parse as timestamp (RFC3339)
-		if t, err := time.Parse(time.RFC3339, opts.From); err == nil {
-			deliverPolicy = jetstream.DeliverByStartTimePolicy
-			optStartTime = t
+		// A resume token continues right after the event it was issued for
+		if seq, ok := ParseResumeToken(opts.From); ok {
+			deliverPolicy = jetstream.DeliverByStartSequencePolicy
+			optStartSeq = seq + 1
+			break
+		}
+		t, err := time.Parse(time.RFC3339, opts.From)
+		if err != nil {
+			return nil, ValidateFrom(opts.From)
+		}
+		if err := cm.checkRetention(ctx, t); err != nil {
+			return nil, err
 		}
-		// If parsing fails, default to DeliverNewPolicy (latest)
+		deliverPolicy = jetstream.DeliverByStartTimePolicy
+		optStartTime = t
+	}
+	if opts.FromSeq > 0 {
+		if err := cm.checkRetentionSeq(ctx, opts.FromSeq); err != nil {
+			return nil, err
+		}
+		deliverPolicy = jetstream.DeliverByStartSequencePolicy
+		optStartSeq = opts.FromSeq
+	}
+
+	attempts := opts.MaxRetries
+	if opts.AttemptLimit > 0 && opts.AttemptLimit < attempts {
+		attempts = opts.AttemptLimit
 	}
 
 	config := jetstream.ConsumerConfig{
 		AckPolicy:      jetstream.AckExplicitPolicy,
 		AckWait:        opts.AckTimeout,
-		MaxDeliver:     opts.MaxRetries + 1,
+		MaxDeliver:     attempts + 1, // The last one is dead-lettered on arrival
 		FilterSubjects: filterSubjects,
 		DeliverPolicy:  deliverPolicy,
+		MaxAckPending:  opts.MaxInFlight,
 	}
 
 	// Set OptStartTime if using DeliverByStartTimePolicy
 	if deliverPolicy == jetstream.DeliverByStartTimePolicy {
 		config.OptStartTime = &optStartTime
 	}
+	if deliverPolicy == jetstream.DeliverByStartSequencePolicy {
+		config.OptStartSeq = optStartSeq
+	}
 
 	if opts.Group != "" {
 		// Durable consumer for consumer groups (load balanced)
-		// Include topic hash so different topic patterns get separate consumers
-		consumerName := opts.Group + "-" + hashTopics(opts.Topics)
+		// Include topic hash so different topic patterns get separate consumers,
+		// and the projects so a cross-project group doesn't join a single-project one
+		keys := opts.Topics
+		if len(opts.ProjectIDs) > 0 {
+			keys = append(slices.Clone(opts.Topics), opts.ProjectIDs...)
+		}
+		consumerName := opts.Group + "-" + hashTopics(keys)
 		config.Durable = consumerName
 		config.DeliverGroup = consumerName
+		// Groups with no members are cleaned up by JetStream after the TTL
+		config.InactiveThreshold = cm.groupTTL
+		if opts.Sequential {
+			config.MaxAckPending = 1
+		}
+
+		// Rejoining an existing group resumes from its last ack position.
+		// The deliver policy is fixed at creation, so keep the original one
+		// instead of letting a later member's "from" reject the update.
+		// Sequential delivery is fixed too, so one member can't switch it off for all.
+		if existing, err := cm.stream.Consumer(ctx, consumerName); err == nil {
+			if info := existing.CachedInfo(); info != nil {
+				config.DeliverPolicy = info.Config.DeliverPolicy
+				config.OptStartTime = info.Config.OptStartTime
+				config.OptStartSeq = info.Config.OptStartSeq
+				config.MaxAckPending = info.Config.MaxAckPending
+			}
+		}
+	} else if cursor := ResumeCursor(opts.ResumeToken); opts.From == "resume" && cursor != "" {
+		// A resume cursor is durable so a reconnect continues it. The
+		// subjects are part of the name: the same cursor on other topics or
+		// in another org or project is a different consumer.
+		config.Durable = "resume-" + cursor + "-" + hashTopics(filterSubjects)
+		config.InactiveThreshold = cm.resumeTTL
+
+		// An existing cursor keeps its position; the token only starts it
+		if existing, err := cm.stream.Consumer(ctx, config.Durable); err == nil {
+			if info := existing.CachedInfo(); info != nil {
+				config.DeliverPolicy = info.Config.DeliverPolicy
+				config.OptStartTime = info.Config.OptStartTime
+				config.OptStartSeq = info.Config.OptStartSeq
+			}
+		}
 	}
 	// Else: ephemeral consumer (unique per connection)
 
@@ -118,6 +370,13 @@ func (cm *ConsumerManager) CreateConsumer(ctx context.Context, opts Subscription
 	return consumer, nil
 }
 
+// IsSequential reports whether the consumer is a sequential group, per its
+// cached info.
+func IsSequential(consumer jetstream.Consumer) bool {
+	info := consumer.CachedInfo()
+	return info != nil && info.Config.Durable != "" && info.Config.MaxAckPending == 1
+}
+
 // hashTopics returns a short hash of the sorted topics for consumer naming.
 func hashTopics(topics []string) string {
 	sorted := make([]string, len(topics))
@@ -0,0 +1,502 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// startTestClient starts an embedded JetStream server with the notif streams.
+func startTestClient(t *testing.T) *Client {
+	t.Helper()
+	srv, err := StartEmbedded(EmbeddedConfig{
+		StoreDir: t.TempDir(),
+		Port:     -1,
+	})
+	if err != nil {
+		t.Fatalf("start embedded: %v", err)
+	}
+	t.Cleanup(srv.Shutdown)
+
+	nc, err := Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	if err := nc.EnsureStreams(context.Background()); err != nil {
+		t.Fatalf("ensure streams: %v", err)
+	}
+	return nc
+}
+
+func publishTestEvents(t *testing.T, pub *Publisher, topic string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		data, _ := json.Marshal(map[string]int{"n": i})
+		event := domain.NewEvent(topic, data)
+		event.OrgID = "org_test"
+		event.ProjectID = "prj_test"
+		if err := pub.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+}
+
+// fetchN pulls n messages from the consumer and returns their "n" values.
+func fetchN(t *testing.T, consumer jetstream.Consumer, n int, ack bool) []int {
+	t.Helper()
+	batch, err := consumer.Fetch(n, jetstream.FetchMaxWait(2*time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var got []int
+	for msg := range batch.Messages() {
+		var event domain.Event
+		if err := json.Unmarshal(msg.Data(), &event); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		var data map[string]int
+		json.Unmarshal(event.Data, &data)
+		got = append(got, data["n"])
+		if ack {
+			msg.Ack()
+		}
+	}
+	return got
+}
+
+func TestConsumerGroup_ResumesFromLastAck(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	pub := NewPublisher(nc.JetStream())
+	ctx := context.Background()
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.*"}
+	opts.OrgID = "org_test"
+	opts.ProjectID = "prj_test"
+	opts.Group = "billing"
+
+	// First member joins and processes the first two events
+	consumer, err := cm.CreateConsumer(ctx, opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	publishTestEvents(t, pub, "orders.created", 2)
+	if got := fetchN(t, consumer, 2, true); len(got) != 2 {
+		t.Fatalf("expected 2 events, got %v", got)
+	}
+
+	// All members gone; more events arrive meanwhile
+	publishTestEvents(t, pub, "orders.created", 2)
+
+	// A rejoining member asking for "beginning" must still resume, not replay
+	opts.From = "beginning"
+	rejoined, err := cm.CreateConsumer(ctx, opts)
+	if err != nil {
+		t.Fatalf("rejoin consumer: %v", err)
+	}
+	got := fetchN(t, rejoined, 10, true)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events after rejoin, got %v", got)
+	}
+	// The second batch restarts numbering at 0
+	if got[0] != 0 || got[1] != 1 {
+		t.Errorf("expected second batch [0 1], got %v", got)
+	}
+
+	info, err := rejoined.Info(ctx)
+	if err != nil {
+		t.Fatalf("consumer info: %v", err)
+	}
+	if info.Delivered.Stream != 4 {
+		t.Errorf("expected delivery to continue at stream seq 4, got %d", info.Delivered.Stream)
+	}
+}
+
+func TestConsumerGroup_InactiveThreshold(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	cm.SetGroupTTL(time.Hour)
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.*"}
+	opts.OrgID = "org_test"
+	opts.ProjectID = "prj_test"
+	opts.Group = "billing"
+
+	consumer, err := cm.CreateConsumer(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	info := consumer.CachedInfo()
+	if info.Config.InactiveThreshold != time.Hour {
+		t.Errorf("expected inactive threshold 1h, got %v", info.Config.InactiveThreshold)
+	}
+	if info.Config.Durable == "" {
+		t.Error("expected group consumer to be durable")
+	}
+}
+
+func TestConsumerGroup_Sequential(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	pub := NewPublisher(nc.JetStream())
+	ctx := context.Background()
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.*"}
+	opts.OrgID = "org_test"
+	opts.ProjectID = "prj_test"
+	opts.Group = "fulfillment"
+	opts.Sequential = true
+
+	// Two members of the same group, each slow to ack
+	const total = 6
+	var (
+		mu       sync.Mutex
+		inFlight int
+		overlap  bool
+		got      []int
+		done     = make(chan struct{})
+	)
+	for member := 0; member < 2; member++ {
+		consumer, err := cm.CreateConsumer(ctx, opts)
+		if err != nil {
+			t.Fatalf("create consumer: %v", err)
+		}
+		if !IsSequential(consumer) {
+			t.Fatal("expected sequential group consumer")
+		}
+		consCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+			var event domain.Event
+			json.Unmarshal(msg.Data(), &event)
+			var data map[string]int
+			json.Unmarshal(event.Data, &data)
+
+			mu.Lock()
+			inFlight++
+			if inFlight > 1 {
+				overlap = true
+			}
+			mu.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			got = append(got, data["n"])
+			if len(got) == total {
+				close(done)
+			}
+			mu.Unlock()
+			msg.Ack()
+		}, jetstream.PullMaxMessages(1))
+		if err != nil {
+			t.Fatalf("consume: %v", err)
+		}
+		defer consCtx.Stop()
+	}
+
+	publishTestEvents(t, pub, "orders.created", total)
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out, processed %v", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if overlap {
+		t.Error("two events were in flight at once")
+	}
+	for i, n := range got {
+		if n != i {
+			t.Fatalf("events processed out of order: %v", got)
+		}
+	}
+}
+
+func TestConsumerGroup_SequentialFixedAtCreation(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	ctx := context.Background()
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.*"}
+	opts.OrgID = "org_test"
+	opts.ProjectID = "prj_test"
+	opts.Group = "fulfillment"
+	opts.Sequential = true
+	if _, err := cm.CreateConsumer(ctx, opts); err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+
+	// A later member that doesn't ask for it still joins a sequential group
+	opts.Sequential = false
+	joined, err := cm.CreateConsumer(ctx, opts)
+	if err != nil {
+		t.Fatalf("join consumer: %v", err)
+	}
+	if !IsSequential(joined) {
+		t.Error("joining without sequential switched the group's sequential delivery off")
+	}
+}
+
+// fetchTopics pulls up to n messages from the consumer and returns their
+// topics.
+func fetchTopics(t *testing.T, consumer jetstream.Consumer, n int) []string {
+	t.Helper()
+	batch, err := consumer.Fetch(n, jetstream.FetchMaxWait(time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var got []string
+	for msg := range batch.Messages() {
+		var event domain.Event
+		if err := json.Unmarshal(msg.Data(), &event); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		got = append(got, event.Topic)
+		msg.Ack()
+	}
+	return got
+}
+
+func TestCreateConsumer_MixedWildcards(t *testing.T) {
+	tests := []struct {
+		name   string
+		topics []string
+		want   []string
+	}{
+		{"mid star with trailing >", []string{"a.*.b.>"}, []string{"a.x.b.c.d", "a.y.b.c"}},
+		{"several patterns", []string{"orders.*.shipped", "logs.>"}, []string{"orders.eu.shipped", "logs.api.error", "logs.db"}},
+		{"standalone star", []string{"*"}, []string{"a.x.b.c.d", "a.y.b.c", "a.x.b", "a.x.y.b.c", "orders.eu.shipped", "orders.eu.created", "logs.api.error", "logs.db"}},
+		{"standalone >", []string{">"}, []string{"a.x.b.c.d", "a.y.b.c", "a.x.b", "a.x.y.b.c", "orders.eu.shipped", "orders.eu.created", "logs.api.error", "logs.db"}},
+		{"star with other topics", []string{"orders.eu.shipped", "*"}, []string{"a.x.b.c.d", "a.y.b.c", "a.x.b", "a.x.y.b.c", "orders.eu.shipped", "orders.eu.created", "logs.api.error", "logs.db"}},
+		{"covered topic", []string{"orders.*.shipped", "orders.>"}, []string{"orders.eu.shipped", "orders.eu.created"}},
+	}
+	published := []string{"a.x.b.c.d", "a.y.b.c", "a.x.b", "a.x.y.b.c", "orders.eu.shipped", "orders.eu.created", "logs.api.error", "logs.db"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nc := startTestClient(t)
+			cm := NewConsumerManager(nc.Stream())
+			pub := NewPublisher(nc.JetStream())
+
+			opts := DefaultSubscriptionOptions()
+			opts.Topics = tt.topics
+			opts.OrgID = "org_test"
+			opts.ProjectID = "prj_test"
+			opts.From = "beginning"
+			for _, topic := range published {
+				publishTestEvents(t, pub, topic, 1)
+			}
+
+			consumer, err := cm.CreateConsumer(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("create consumer: %v", err)
+			}
+			got := fetchTopics(t, consumer, len(published)+1)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("topics %v received %v, want %v", tt.topics, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublish_CarriesTraceparent(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	pub := NewPublisher(nc.JetStream())
+
+	traced := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+	traced.OrgID, traced.ProjectID = "org_test", "prj_test"
+	traced.Traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	untraced := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+	untraced.OrgID, untraced.ProjectID = "org_test", "prj_test"
+	for _, event := range []*domain.Event{traced, untraced} {
+		if err := pub.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.created"}
+	opts.OrgID, opts.ProjectID = "org_test", "prj_test"
+	opts.From = "beginning"
+	consumer, err := cm.CreateConsumer(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	batch, err := consumer.Fetch(2, jetstream.FetchMaxWait(time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var got []string
+	for msg := range batch.Messages() {
+		event, err := EventFromMsg(msg)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		got = append(got, event.Traceparent)
+	}
+	if want := []string{traced.Traceparent, ""}; !slices.Equal(got, want) {
+		t.Errorf("traceparents %q, want %q", got, want)
+	}
+}
+
+func TestCreateConsumer_ResumeToken(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	pub := NewPublisher(nc.JetStream())
+	publishTestEvents(t, pub, "orders.created", 5)
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.created"}
+	opts.OrgID, opts.ProjectID = "org_test", "prj_test"
+	opts.From = "beginning"
+	consumer, err := cm.CreateConsumer(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	batch, err := consumer.Fetch(2, jetstream.FetchMaxWait(time.Second))
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var lastSeq uint64
+	for msg := range batch.Messages() {
+		meta, err := msg.Metadata()
+		if err != nil {
+			t.Fatalf("metadata: %v", err)
+		}
+		lastSeq = meta.Sequence.Stream
+		msg.Ack()
+	}
+
+	opts.From = ResumeToken(lastSeq)
+	opts.Clamp()
+	if opts.From != ResumeToken(lastSeq) {
+		t.Fatalf("Clamp replaced the token with %q", opts.From)
+	}
+	resumed, err := cm.CreateConsumer(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create resumed consumer: %v", err)
+	}
+	if got := fetchN(t, resumed, 3, true); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("resumed at %v, want [2 3 4]", got)
+	}
+}
+
+func TestCreateConsumer_ResumeCursor(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	publishTestEvents(t, NewPublisher(nc.JetStream()), "orders.created", 5)
+	ctx := context.Background()
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.created"}
+	opts.OrgID, opts.ProjectID = "org_test", "prj_test"
+	opts.From, opts.ResumeToken = "resume", ResumeToken(1)
+	opts.Clamp()
+	cursor := ResumeCursor(opts.ResumeToken)
+	if seq, _ := ParseResumeToken(opts.ResumeToken); cursor == "" || seq != 1 {
+		t.Fatalf("Clamp made token %q, want a new cursor at seq 1", opts.ResumeToken)
+	}
+
+	consumer, err := cm.CreateConsumer(ctx, opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	if got := fetchN(t, consumer, 2, true); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("started at %v, want [1 2]", got)
+	}
+	info, err := consumer.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Config.Durable == "" {
+		t.Fatal("resume cursor consumer is not durable")
+	}
+	if info.Config.InactiveThreshold != DefaultResumeTTL {
+		t.Errorf("inactive threshold = %v, want %v", info.Config.InactiveThreshold, DefaultResumeTTL)
+	}
+
+	// Reconnecting with an older token of the cursor continues the cursor
+	opts.ResumeToken = CursorResumeToken(cursor, 1)
+	opts.Clamp()
+	resumed, err := cm.CreateConsumer(ctx, opts)
+	if err != nil {
+		t.Fatalf("resume consumer: %v", err)
+	}
+	if got := fetchN(t, resumed, 5, true); !slices.Equal(got, []int{3, 4}) {
+		t.Errorf("resumed at %v, want [3 4]", got)
+	}
+
+	// The same cursor on other topics is another consumer
+	opts.Topics = []string{"orders.*"}
+	other, err := cm.CreateConsumer(ctx, opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	if otherInfo, err := other.Info(ctx); err != nil || otherInfo.Name == info.Name {
+		t.Errorf("other topics joined cursor consumer %s: %v", info.Name, err)
+	}
+}
+
+func TestCreateConsumer_FromTimeAndSeq(t *testing.T) {
+	nc := startTestClient(t)
+	cm := NewConsumerManager(nc.Stream())
+	pub := NewPublisher(nc.JetStream())
+	publishTestEvents(t, pub, "orders.created", 5)
+
+	opts := DefaultSubscriptionOptions()
+	opts.Topics = []string{"orders.created"}
+	opts.OrgID, opts.ProjectID = "org_test", "prj_test"
+	opts.FromSeq = 3
+	consumer, err := cm.CreateConsumer(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	if got := fetchN(t, consumer, 3, true); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("from_seq 3 delivered %v, want [2 3 4]", got)
+	}
+
+	opts.FromSeq = 0
+	opts.From = time.Now().Add(-time.Minute).Format(time.RFC3339)
+	consumer, err = cm.CreateConsumer(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	if got := fetchN(t, consumer, 5, true); !slices.Equal(got, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("from a minute ago delivered %v, want all five", got)
+	}
+
+	opts.From = time.Now().Add(-30 * 24 * time.Hour).Format(time.RFC3339)
+	if _, err := cm.CreateConsumer(context.Background(), opts); !errors.Is(err, ErrBeforeRetention) {
+		t.Errorf("from a month ago: err = %v, want ErrBeforeRetention", err)
+	}
+
+	opts.From = "yesterday"
+	if _, err := cm.CreateConsumer(context.Background(), opts); !errors.Is(err, ErrInvalidFrom) {
+		t.Errorf("from yesterday: err = %v, want ErrInvalidFrom", err)
+	}
+
+	// Sequences the stream no longer holds
+	if err := nc.Stream().Purge(context.Background(), jetstream.WithPurgeSequence(3)); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	opts.From, opts.FromSeq = "", 2
+	if _, err := cm.CreateConsumer(context.Background(), opts); !errors.Is(err, ErrBeforeRetention) {
+		t.Errorf("from_seq 2 after purge: err = %v, want ErrBeforeRetention", err)
+	}
+}
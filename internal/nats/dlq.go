@@ -3,6 +3,7 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -84,6 +85,15 @@ func NewDLQReaderForOrg(js jetstream.JetStream, orgID string) (*DLQReader, error
 	return &DLQReader{js: js, stream: stream}, nil
 }
 
+// Depth returns how many messages the DLQ stream holds.
+func (r *DLQReader) Depth(ctx context.Context) (uint64, error) {
+	info, err := r.stream.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get DLQ stream info: %w", err)
+	}
+	return info.State.Msgs, nil
+}
+
 // DLQEntry represents a DLQ message with its sequence number.
 type DLQEntry struct {
 	Seq     uint64      `json:"seq"`
@@ -91,8 +101,52 @@ type DLQEntry struct {
 	Message *DLQMessage `json:"message"`
 }
 
+// dlqFetchBatch is how many messages a DLQ scan pulls per fetch.
+const dlqFetchBatch = 256
+
+// DLQFilter narrows a DLQ listing. Zero fields match every message.
+type DLQFilter struct {
+	Topic string
+	// OlderThan keeps messages that failed at least this long ago.
+	OlderThan time.Duration
+	// Before keeps messages that failed before this time.
+	Before        time.Time
+	MinAttempts   int
+	ConsumerGroup string
+}
+
+func (f DLQFilter) matches(msg *DLQMessage, now time.Time) bool {
+	if f.OlderThan > 0 && now.Sub(msg.FailedAt) < f.OlderThan {
+		return false
+	}
+	if !f.Before.IsZero() && !msg.FailedAt.Before(f.Before) {
+		return false
+	}
+	if f.MinAttempts > 0 && msg.Attempts < f.MinAttempts {
+		return false
+	}
+	if f.ConsumerGroup != "" && msg.ConsumerGroup != f.ConsumerGroup {
+		return false
+	}
+	return true
+}
+
+// DLQStats counts a project's dead-lettered messages.
+type DLQStats struct {
+	Total   int64            `json:"total"`
+	ByTopic map[string]int64 `json:"by_topic"`
+	// ByConsumerGroup leaves out messages that had no consumer group.
+	ByConsumerGroup map[string]int64 `json:"by_consumer_group"`
+}
+
 // List returns messages from the DLQ, filtered by org, project, and optionally by topic.
 func (r *DLQReader) List(ctx context.Context, orgID, projectID, topic string, limit int) ([]DLQEntry, error) {
+	return r.ListFiltered(ctx, orgID, projectID, DLQFilter{Topic: topic}, limit)
+}
+
+// ListFiltered returns up to limit messages from the DLQ that match filter,
+// oldest first.
+func (r *DLQReader) ListFiltered(ctx context.Context, orgID, projectID string, filter DLQFilter, limit int) ([]DLQEntry, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -105,52 +159,137 @@ func (r *DLQReader) List(ctx context.Context, orgID, projectID, topic string, li
 		return nil, fmt.Errorf("project_id is required for DLQ queries")
 	}
 
-	// Create ephemeral consumer to read messages with org and project filtering
-	// Subject format: dlq.{org_id}.{project_id}.{topic}
-	var filterSubject string
+	entries := make([]DLQEntry, 0, limit)
+	now := time.Now()
+	err := r.scan(ctx, dlqFilterSubject(orgID, projectID, filter.Topic), func(entry DLQEntry) bool {
+		if filter.matches(entry.Message, now) {
+			entries = append(entries, entry)
+		}
+		return len(entries) < limit
+	})
+	return entries, err
+}
+
+// Purge deletes every DLQ message of an org and project that matches
+// filter and returns how many were deleted.
+func (r *DLQReader) Purge(ctx context.Context, orgID, projectID string, filter DLQFilter) (int, error) {
+	if orgID == "" {
+		return 0, fmt.Errorf("org_id is required for DLQ purge")
+	}
+	if projectID == "" {
+		return 0, fmt.Errorf("project_id is required for DLQ purge")
+	}
+
+	var seqs []uint64
+	now := time.Now()
+	err := r.scan(ctx, dlqFilterSubject(orgID, projectID, filter.Topic), func(entry DLQEntry) bool {
+		if filter.matches(entry.Message, now) {
+			seqs = append(seqs, entry.Seq)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, seq := range seqs {
+		if err := r.stream.DeleteMsg(ctx, seq); err != nil {
+			// Expired or deleted concurrently
+			if errors.Is(err, jetstream.ErrMsgNotFound) {
+				continue
+			}
+			return deleted, fmt.Errorf("delete DLQ message %d: %w", seq, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Stats counts the DLQ messages of an org and project by topic and by
+// consumer group.
+func (r *DLQReader) Stats(ctx context.Context, orgID, projectID string) (*DLQStats, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("org_id is required for DLQ stats")
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("project_id is required for DLQ stats")
+	}
+
+	stats := &DLQStats{
+		ByTopic:         map[string]int64{},
+		ByConsumerGroup: map[string]int64{},
+	}
+	err := r.scan(ctx, dlqFilterSubject(orgID, projectID, ""), func(entry DLQEntry) bool {
+		stats.Total++
+		stats.ByTopic[entry.Message.OriginalTopic]++
+		if entry.Message.ConsumerGroup != "" {
+			stats.ByConsumerGroup[entry.Message.ConsumerGroup]++
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// dlqFilterSubject returns the DLQ subject for a project, narrowed to topic
+// when set. Subject format: dlq.{org_id}.{project_id}.{topic}
+func dlqFilterSubject(orgID, projectID, topic string) string {
 	if topic != "" {
-		filterSubject = "dlq." + orgID + "." + projectID + "." + topic
-	} else {
-		filterSubject = "dlq." + orgID + "." + projectID + ".>"
+		return "dlq." + orgID + "." + projectID + "." + topic
 	}
+	return "dlq." + orgID + "." + projectID + ".>"
+}
 
+// scan calls fn for each DLQ message on filterSubject, oldest first, until
+// fn returns false or the messages pending at the start have been read.
+func (r *DLQReader) scan(ctx context.Context, filterSubject string, fn func(DLQEntry) bool) error {
 	consumer, err := r.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
 		FilterSubject: filterSubject,
 		AckPolicy:     jetstream.AckNonePolicy,
 		DeliverPolicy: jetstream.DeliverAllPolicy,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("create DLQ consumer: %w", err)
+		return fmt.Errorf("create DLQ consumer: %w", err)
 	}
 
-	entries := make([]DLQEntry, 0, limit)
-
-	// Fetch messages
-	msgs, err := consumer.Fetch(limit, jetstream.FetchMaxWait(time.Second))
+	info, err := consumer.Info(ctx)
 	if err != nil {
-		return entries, nil // No messages or timeout
+		return fmt.Errorf("get consumer info: %w", err)
 	}
 
-	for msg := range msgs.Messages() {
-		var dlqMsg DLQMessage
-		if err := json.Unmarshal(msg.Data(), &dlqMsg); err != nil {
-			continue
+	for remaining := int(info.NumPending); remaining > 0; {
+		msgs, err := consumer.Fetch(min(remaining, dlqFetchBatch), jetstream.FetchMaxWait(time.Second))
+		if err != nil {
+			return nil // No messages or timeout
 		}
 
-		meta, _ := msg.Metadata()
-		seq := uint64(0)
-		if meta != nil {
-			seq = meta.Sequence.Stream
+		fetched := 0
+		for msg := range msgs.Messages() {
+			fetched++
+			var dlqMsg DLQMessage
+			if err := json.Unmarshal(msg.Data(), &dlqMsg); err != nil {
+				continue
+			}
+
+			meta, _ := msg.Metadata()
+			seq := uint64(0)
+			if meta != nil {
+				seq = meta.Sequence.Stream
+			}
+
+			if !fn(DLQEntry{Seq: seq, Subject: msg.Subject(), Message: &dlqMsg}) {
+				return nil
+			}
 		}
-
-		entries = append(entries, DLQEntry{
-			Seq:     seq,
-			Subject: msg.Subject(),
-			Message: &dlqMsg,
-		})
+		if fetched == 0 {
+			return nil
+		}
+		remaining -= fetched
 	}
-
-	return entries, nil
+	return nil
 }
 
 // Get retrieves a specific DLQ message by sequence number.
@@ -177,6 +316,11 @@ func (r *DLQReader) Delete(ctx context.Context, seq uint64) error {
 	return r.stream.DeleteMsg(ctx, seq)
 }
 
+// StreamInfo returns DLQ stream information.
+func (r *DLQReader) StreamInfo(ctx context.Context) (*jetstream.StreamInfo, error) {
+	return r.stream.Info(ctx)
+}
+
 // Count returns the total number of messages in the DLQ for a specific org and project.
 func (r *DLQReader) Count(ctx context.Context, orgID, projectID string) (int64, error) {
 	if orgID == "" {
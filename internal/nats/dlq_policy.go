@@ -0,0 +1,135 @@
+package nats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DLQAction is what happens to an event once its retries are exhausted.
+type DLQAction string
+
+const (
+	// DLQActionDLQ moves the event to the dead letter queue (the default).
+	DLQActionDLQ DLQAction = "dlq"
+	// DLQActionDrop discards the event.
+	DLQActionDrop DLQAction = "drop"
+)
+
+// DLQPolicy is the dead-letter behavior for topics matching Pattern.
+type DLQPolicy struct {
+	Pattern string
+	Action  DLQAction
+	// MaxAttempts caps delivery attempts before the action applies
+	// (dlq-after-N). 0 keeps the subscriber's or webhook's own limit.
+	MaxAttempts int
+}
+
+// AttemptLimit returns how many attempts an event gets under the policy,
+// given the receiver's own limit. A policy can lower the limit, never raise
+// it: JetStream stops redelivering past the receiver's limit.
+func (p DLQPolicy) AttemptLimit(limit int) int {
+	if p.MaxAttempts > 0 && p.MaxAttempts < limit {
+		return p.MaxAttempts
+	}
+	return limit
+}
+
+// Drops reports whether exhausted events are discarded instead of
+// dead-lettered.
+func (p DLQPolicy) Drops() bool {
+	return p.Action == DLQActionDrop
+}
+
+// DLQPolicies is an ordered list of per-topic policies; the first matching
+// pattern wins. Topics matching none get the default policy.
+type DLQPolicies []DLQPolicy
+
+// For returns the policy for topic.
+func (ps DLQPolicies) For(topic string) DLQPolicy {
+	for _, p := range ps {
+		if MatchSubject(p.Pattern, topic) {
+			return p
+		}
+	}
+	return DLQPolicy{Pattern: ">", Action: DLQActionDLQ}
+}
+
+// AttemptLimit returns the most attempts any event on topics gets, given
+// the receiver's own limit. It is the limit for a consumer filtering on
+// topics, which JetStream enforces on redeliveries no one nacked.
+func (ps DLQPolicies) AttemptLimit(topics []string, limit int) int {
+	if len(topics) == 0 {
+		return limit
+	}
+	highest := 0
+	for _, topic := range topics {
+		covered := false
+		for _, p := range ps {
+			if !subjectsOverlap(p.Pattern, topic) {
+				continue
+			}
+			highest = max(highest, p.AttemptLimit(limit))
+			if subjectCovers(p.Pattern, topic) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			// Some of the topic's events get the default policy
+			return limit
+		}
+	}
+	return highest
+}
+
+// ParseDLQPolicies parses a comma-separated list of pattern=policy pairs,
+// where policy is "drop", "dlq" or "dlq-after-N", e.g.
+// "audit.>=drop,payments.*=dlq-after-1".
+func ParseDLQPolicies(s string) (DLQPolicies, error) {
+	var policies DLQPolicies
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("dlq policy %q: expected pattern=policy", entry)
+		}
+		pattern, spec = strings.TrimSpace(pattern), strings.TrimSpace(spec)
+		if err := ValidateSubject(pattern, true); err != nil {
+			return nil, fmt.Errorf("dlq policy %q: %w", entry, err)
+		}
+
+		policy := DLQPolicy{Pattern: pattern}
+		switch {
+		case spec == string(DLQActionDrop):
+			policy.Action = DLQActionDrop
+		case spec == string(DLQActionDLQ):
+			policy.Action = DLQActionDLQ
+		case strings.HasPrefix(spec, "dlq-after-"):
+			n, err := strconv.Atoi(strings.TrimPrefix(spec, "dlq-after-"))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("dlq policy %q: dlq-after-N needs N >= 1", entry)
+			}
+			policy.Action = DLQActionDLQ
+			policy.MaxAttempts = n
+		default:
+			return nil, fmt.Errorf("dlq policy %q: unknown policy %q (want drop, dlq or dlq-after-N)", entry, spec)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// UnmarshalText parses policies in the ParseDLQPolicies format, so they can
+// be loaded straight from the environment.
+func (ps *DLQPolicies) UnmarshalText(text []byte) error {
+	policies, err := ParseDLQPolicies(string(text))
+	if err != nil {
+		return err
+	}
+	*ps = policies
+	return nil
+}
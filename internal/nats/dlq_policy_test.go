@@ -0,0 +1,79 @@
+package nats
+
+import "testing"
+
+func TestParseDLQPolicies(t *testing.T) {
+	policies, err := ParseDLQPolicies("audit.>=drop, payments.*=dlq-after-1,orders.created=dlq")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		topic string
+		want  DLQPolicy
+	}{
+		{"audit.login", DLQPolicy{Pattern: "audit.>", Action: DLQActionDrop}},
+		{"audit.user.deleted", DLQPolicy{Pattern: "audit.>", Action: DLQActionDrop}},
+		{"payments.charged", DLQPolicy{Pattern: "payments.*", Action: DLQActionDLQ, MaxAttempts: 1}},
+		{"payments.eu.charged", DLQPolicy{Pattern: ">", Action: DLQActionDLQ}},
+		{"orders.created", DLQPolicy{Pattern: "orders.created", Action: DLQActionDLQ}},
+		{"audit", DLQPolicy{Pattern: ">", Action: DLQActionDLQ}},
+	}
+	for _, tt := range tests {
+		if got := policies.For(tt.topic); got != tt.want {
+			t.Errorf("For(%q) = %+v, want %+v", tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestParseDLQPolicies_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"audit.>",
+		"audit.>=retry",
+		"audit.>=dlq-after-0",
+		"audit.>=dlq-after-x",
+		"audit.>.x=drop",
+	} {
+		if _, err := ParseDLQPolicies(s); err == nil {
+			t.Errorf("ParseDLQPolicies(%q) = nil error, want error", s)
+		}
+	}
+}
+
+func TestDLQPolicy_AttemptLimit(t *testing.T) {
+	if got := (DLQPolicy{MaxAttempts: 1}).AttemptLimit(5); got != 1 {
+		t.Errorf("dlq-after-1 with limit 5 = %d, want 1", got)
+	}
+	if got := (DLQPolicy{MaxAttempts: 10}).AttemptLimit(5); got != 5 {
+		t.Errorf("dlq-after-10 with limit 5 = %d, want 5", got)
+	}
+	if got := (DLQPolicy{}).AttemptLimit(5); got != 5 {
+		t.Errorf("default policy with limit 5 = %d, want 5", got)
+	}
+}
+
+func TestDLQPolicies_AttemptLimit(t *testing.T) {
+	policies, err := ParseDLQPolicies("audit.>=drop,payments.*=dlq-after-1,payments.refunds.*=dlq-after-3")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		topics []string
+		want   int
+	}{
+		{[]string{"payments.charged"}, 1},
+		{[]string{"payments.*"}, 1},
+		{[]string{"payments.>"}, 5},
+		{[]string{"payments.refunds.*"}, 3},
+		{[]string{"payments.*", "payments.refunds.issued"}, 3},
+		{[]string{"audit.login"}, 5},
+		{[]string{"orders.*"}, 5},
+		{nil, 5},
+	}
+	for _, tt := range tests {
+		if got := policies.AttemptLimit(tt.topics, 5); got != tt.want {
+			t.Errorf("AttemptLimit(%v, 5) = %d, want %d", tt.topics, got, tt.want)
+		}
+	}
+}
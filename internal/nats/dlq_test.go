@@ -0,0 +1,134 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestDLQ(t *testing.T) (*DLQPublisher, *DLQReader) {
+	t.Helper()
+	nc := startTestClient(t)
+	reader, err := NewDLQReader(nc.JetStream())
+	if err != nil {
+		t.Fatalf("dlq reader: %v", err)
+	}
+	return NewDLQPublisher(nc.JetStream()), reader
+}
+
+func TestDLQReader_ListFilteredAndStats(t *testing.T) {
+	publisher, reader := newTestDLQ(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, msg := range []*DLQMessage{
+		{ID: "old", OriginalTopic: "orders.created", FailedAt: now.Add(-2 * time.Hour), Attempts: 3, ConsumerGroup: "billing"},
+		{ID: "new", OriginalTopic: "orders.created", FailedAt: now, Attempts: 5, ConsumerGroup: "email"},
+		{ID: "nogroup", OriginalTopic: "users.signup", FailedAt: now, Attempts: 1},
+		{ID: "other-project", OriginalTopic: "orders.created", FailedAt: now, Attempts: 9, ConsumerGroup: "billing", ProjectID: "prj_other"},
+	} {
+		msg.OrgID = "org_1"
+		if msg.ProjectID == "" {
+			msg.ProjectID = "prj_1"
+		}
+		msg.Data = json.RawMessage(`{}`)
+		if err := publisher.Publish(ctx, msg); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter DLQFilter
+		limit  int
+		want   string
+	}{
+		{"no filter", DLQFilter{}, 0, "old,new,nogroup"},
+		{"topic", DLQFilter{Topic: "orders.created"}, 0, "old,new"},
+		{"older than", DLQFilter{OlderThan: time.Hour}, 0, "old"},
+		{"min attempts", DLQFilter{MinAttempts: 3}, 0, "old,new"},
+		{"consumer group", DLQFilter{ConsumerGroup: "email"}, 0, "new"},
+		{"combined", DLQFilter{ConsumerGroup: "billing", MinAttempts: 4}, 0, ""},
+		{"limit counts matches", DLQFilter{MinAttempts: 3}, 1, "old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := reader.ListFiltered(ctx, "org_1", "prj_1", tt.filter, tt.limit)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			ids := make([]string, len(entries))
+			for i, e := range entries {
+				ids[i] = e.Message.ID
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	stats, err := reader.Stats(ctx, "org_1", "prj_1")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Total != 3 {
+		t.Errorf("total = %d, want 3", stats.Total)
+	}
+	if stats.ByTopic["orders.created"] != 2 || stats.ByTopic["users.signup"] != 1 {
+		t.Errorf("by_topic = %v", stats.ByTopic)
+	}
+	if len(stats.ByConsumerGroup) != 2 || stats.ByConsumerGroup["billing"] != 1 || stats.ByConsumerGroup["email"] != 1 {
+		t.Errorf("by_consumer_group = %v", stats.ByConsumerGroup)
+	}
+}
+
+func TestDLQReader_Purge(t *testing.T) {
+	publisher, reader := newTestDLQ(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, msg := range []*DLQMessage{
+		{ID: "old", OriginalTopic: "orders.created", FailedAt: now.Add(-2 * time.Hour)},
+		{ID: "new", OriginalTopic: "orders.created", FailedAt: now},
+		{ID: "old-signup", OriginalTopic: "users.signup", FailedAt: now.Add(-2 * time.Hour)},
+		{ID: "other-project", OriginalTopic: "orders.created", FailedAt: now.Add(-2 * time.Hour), ProjectID: "prj_other"},
+	} {
+		msg.OrgID = "org_1"
+		if msg.ProjectID == "" {
+			msg.ProjectID = "prj_1"
+		}
+		msg.Data = json.RawMessage(`{}`)
+		if err := publisher.Publish(ctx, msg); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	deleted, err := reader.Purge(ctx, "org_1", "prj_1", DLQFilter{Topic: "orders.created", Before: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted = %d, want 1", deleted)
+	}
+
+	entries, err := reader.ListFiltered(ctx, "org_1", "prj_1", DLQFilter{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.Message.ID
+	}
+	if got := strings.Join(ids, ","); got != "new,old-signup" {
+		t.Errorf("left %q, want %q", got, "new,old-signup")
+	}
+
+	if deleted, _ := reader.Purge(ctx, "org_1", "prj_1", DLQFilter{}); deleted != 2 {
+		t.Errorf("purge all deleted %d, want 2", deleted)
+	}
+	if n, _ := reader.Count(ctx, "org_1", "prj_other"); n != 1 {
+		t.Errorf("other project has %d messages, want 1", n)
+	}
+}
@@ -0,0 +1,215 @@
+package nats
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+// fanoutTTL is how long an event's receivers are remembered, long enough to
+// cover redeliveries of the same event.
+const fanoutTTL = 10 * time.Minute
+
+// FanoutLimit caps how many receivers (webhooks and WebSocket
+// subscriptions) one event on a topic matching Pattern is delivered to.
+type FanoutLimit struct {
+	Pattern string
+	Max     int
+}
+
+// FanoutLimits is an ordered list of per-topic caps; the first matching
+// pattern wins. Topics matching none are unlimited.
+type FanoutLimits []FanoutLimit
+
+// For returns the cap for topic, or 0 when it is unlimited.
+func (ls FanoutLimits) For(topic string) int {
+	for _, l := range ls {
+		if MatchSubject(l.Pattern, topic) {
+			return l.Max
+		}
+	}
+	return 0
+}
+
+// ParseFanoutLimits parses a comma-separated list of pattern=N pairs, e.g.
+// "orders.>=20,>=500".
+func ParseFanoutLimits(s string) (FanoutLimits, error) {
+	var limits FanoutLimits
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("fan-out limit %q: expected pattern=N", entry)
+		}
+		pattern, spec = strings.TrimSpace(pattern), strings.TrimSpace(spec)
+		if err := ValidateSubject(pattern, true); err != nil {
+			return nil, fmt.Errorf("fan-out limit %q: %w", entry, err)
+		}
+		n, err := strconv.Atoi(spec)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("fan-out limit %q: N must be >= 1", entry)
+		}
+		limits = append(limits, FanoutLimit{Pattern: pattern, Max: n})
+	}
+	return limits, nil
+}
+
+// UnmarshalText parses limits in the ParseFanoutLimits format, so they can
+// be loaded straight from the environment.
+func (ls *FanoutLimits) UnmarshalText(text []byte) error {
+	limits, err := ParseFanoutLimits(string(text))
+	if err != nil {
+		return err
+	}
+	*ls = limits
+	return nil
+}
+
+// FanoutPriority ranks receivers for shedding.
+type FanoutPriority int
+
+const (
+	// FanoutPriorityLow is for receivers that can catch up on their own,
+	// such as ephemeral WebSocket subscriptions.
+	FanoutPriorityLow FanoutPriority = iota
+	// FanoutPriorityHigh is for webhooks and consumer groups.
+	FanoutPriorityHigh
+)
+
+// FanoutOverflow describes one receiver past an event's fan-out limit.
+type FanoutOverflow struct {
+	Event    *domain.Event
+	Receiver string
+	Limit    int
+	// Shed is set when the receiver is skipped rather than only reported.
+	Shed bool
+	// First is set for the event's first receiver past the limit.
+	First bool
+}
+
+// maxFanoutEvents bounds how many events a gate remembers; the least
+// recently seen are forgotten first.
+const maxFanoutEvents = 100_000
+
+// FanoutGate counts the receivers each event is delivered to, across the
+// webhook worker and WebSocket clients, and enforces FanoutLimits.
+// Receivers are admitted first come, first served, except that
+// low-priority ones only fill the cap up to a quarter short, leaving the
+// rest for high-priority ones. Receivers past that are recorded as overflow
+// and, when shedding, skipped. A nil gate admits everything.
+type FanoutGate struct {
+	limits     FanoutLimits
+	shed       bool
+	onOverflow func(context.Context, FanoutOverflow)
+
+	mu        sync.Mutex
+	events    map[string]*list.Element
+	lru       *list.List // of *fanoutEntry, most recently seen first
+	maxEvents int
+}
+
+type fanoutEntry struct {
+	id       string
+	admitted map[string]bool
+	overflow map[string]bool
+	seen     time.Time
+}
+
+// NewFanoutGate creates a gate enforcing limits. When shed is false,
+// receivers past the cap are still delivered to; the overflow is only
+// reported.
+func NewFanoutGate(limits FanoutLimits, shed bool) *FanoutGate {
+	return &FanoutGate{
+		limits:    limits,
+		shed:      shed,
+		events:    make(map[string]*list.Element),
+		lru:       list.New(),
+		maxEvents: maxFanoutEvents,
+	}
+}
+
+// SetOverflowFunc sets the function called for each receiver past an
+// event's limit, e.g. to count and audit it. It must not block.
+func (g *FanoutGate) SetOverflowFunc(fn func(context.Context, FanoutOverflow)) {
+	g.onOverflow = fn
+}
+
+// Admit reports whether event should be delivered to receiver, a key
+// unique to the webhook or subscription. A receiver admitted once stays
+// admitted, so redeliveries aren't counted twice.
+func (g *FanoutGate) Admit(ctx context.Context, event *domain.Event, receiver string, priority FanoutPriority) bool {
+	if g == nil {
+		return true
+	}
+	limit := g.limits.For(event.Topic)
+	if limit == 0 {
+		return true
+	}
+	capacity := limit
+	if priority == FanoutPriorityLow {
+		capacity -= limit / 4
+	}
+
+	g.mu.Lock()
+	entry := g.entry(event.ID, time.Now())
+	switch {
+	case entry.admitted[receiver]:
+		g.mu.Unlock()
+		return true
+	case entry.overflow[receiver]:
+		g.mu.Unlock()
+		return !g.shed
+	case len(entry.admitted) < capacity:
+		entry.admitted[receiver] = true
+		g.mu.Unlock()
+		return true
+	}
+	entry.overflow[receiver] = true
+	first := len(entry.overflow) == 1
+	g.mu.Unlock()
+
+	if first {
+		slog.Warn("fan-out limit reached",
+			"event_id", event.ID, "topic", event.Topic, "org_id", event.OrgID,
+			"limit", limit, "receiver", receiver, "shed", g.shed)
+	}
+	if g.onOverflow != nil {
+		g.onOverflow(ctx, FanoutOverflow{Event: event, Receiver: receiver, Limit: limit, Shed: g.shed, First: first})
+	}
+	return !g.shed
+}
+
+// entry returns the event's entry, creating it, and marks it seen. Events
+// not seen for fanoutTTL, and the least recently seen past maxEvents, are
+// forgotten. Callers hold g.mu.
+func (g *FanoutGate) entry(id string, now time.Time) *fanoutEntry {
+	var entry *fanoutEntry
+	if el, ok := g.events[id]; ok {
+		g.lru.MoveToFront(el)
+		entry = el.Value.(*fanoutEntry)
+	} else {
+		entry = &fanoutEntry{id: id, admitted: make(map[string]bool), overflow: make(map[string]bool)}
+		g.events[id] = g.lru.PushFront(entry)
+	}
+	entry.seen = now
+
+	for el := g.lru.Back(); el != nil; el = g.lru.Back() {
+		oldest := el.Value.(*fanoutEntry)
+		if g.lru.Len() <= g.maxEvents && now.Sub(oldest.seen) < fanoutTTL {
+			break
+		}
+		g.lru.Remove(el)
+		delete(g.events, oldest.id)
+	}
+	return entry
+}
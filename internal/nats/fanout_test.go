@@ -0,0 +1,156 @@
+package nats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+// recordOverflow collects the gate's overflow reports.
+func recordOverflow(g *FanoutGate) *[]FanoutOverflow {
+	var got []FanoutOverflow
+	g.SetOverflowFunc(func(_ context.Context, o FanoutOverflow) { got = append(got, o) })
+	return &got
+}
+
+func TestParseFanoutLimits(t *testing.T) {
+	limits, err := ParseFanoutLimits("orders.>=20, logs.*=5,>=500")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for topic, want := range map[string]int{
+		"orders.created":    20,
+		"orders.eu.shipped": 20,
+		"logs.app":          5,
+		"logs.app.debug":    500,
+		"users":             500,
+	} {
+		if got := limits.For(topic); got != want {
+			t.Errorf("For(%q) = %d, want %d", topic, got, want)
+		}
+	}
+
+	if got := FanoutLimits(nil).For("orders.created"); got != 0 {
+		t.Errorf("no limits: For = %d, want 0 (unlimited)", got)
+	}
+}
+
+func TestParseFanoutLimits_Invalid(t *testing.T) {
+	for _, s := range []string{"orders.>", "orders.>=0", "orders.>=x", "orders.>.x=5"} {
+		if _, err := ParseFanoutLimits(s); err == nil {
+			t.Errorf("ParseFanoutLimits(%q) = nil error, want error", s)
+		}
+	}
+}
+
+func TestFanoutGate_Shed(t *testing.T) {
+	g := NewFanoutGate(FanoutLimits{{Pattern: "fanshed.>", Max: 2}}, true)
+	overflow := recordOverflow(g)
+	event := &domain.Event{ID: "evt_1", Topic: "fanshed.created"}
+	ctx := context.Background()
+
+	var admitted []string
+	for _, r := range []string{"a", "b", "c", "d"} {
+		if g.Admit(ctx, event, r, FanoutPriorityHigh) {
+			admitted = append(admitted, r)
+		}
+	}
+	if len(admitted) != 2 || admitted[0] != "a" || admitted[1] != "b" {
+		t.Fatalf("admitted = %v, want [a b]", admitted)
+	}
+
+	// Redeliveries keep their decision and aren't counted again
+	if !g.Admit(ctx, event, "a", FanoutPriorityHigh) || g.Admit(ctx, event, "c", FanoutPriorityHigh) {
+		t.Error("redelivery changed the admission decision")
+	}
+	if len(*overflow) != 2 {
+		t.Fatalf("overflow = %d, want 2", len(*overflow))
+	}
+	if o := (*overflow)[0]; o.Receiver != "c" || o.Limit != 2 || !o.Shed || !o.First {
+		t.Errorf("first overflow = %+v", o)
+	}
+	if (*overflow)[1].First {
+		t.Error("second overflow marked first")
+	}
+
+	// Each event has its own count
+	if !g.Admit(ctx, &domain.Event{ID: "evt_2", Topic: "fanshed.created"}, "c", FanoutPriorityHigh) {
+		t.Error("another event was shed")
+	}
+}
+
+func TestFanoutGate_LogOnly(t *testing.T) {
+	g := NewFanoutGate(FanoutLimits{{Pattern: "fanlog.>", Max: 1}}, false)
+	overflow := recordOverflow(g)
+	event := &domain.Event{ID: "evt_1", Topic: "fanlog.created"}
+
+	for _, r := range []string{"a", "b", "c"} {
+		if !g.Admit(context.Background(), event, r, FanoutPriorityLow) {
+			t.Errorf("receiver %s shed without shedding enabled", r)
+		}
+	}
+	if len(*overflow) != 2 || (*overflow)[0].Shed {
+		t.Errorf("overflow = %+v, want 2 logged", *overflow)
+	}
+}
+
+func TestFanoutGate_Unlimited(t *testing.T) {
+	var nilGate *FanoutGate
+	event := &domain.Event{ID: "evt_1", Topic: "users.created"}
+	if !nilGate.Admit(context.Background(), event, "a", FanoutPriorityLow) {
+		t.Error("nil gate shed a receiver")
+	}
+
+	g := NewFanoutGate(FanoutLimits{{Pattern: "orders.>", Max: 1}}, true)
+	for _, r := range []string{"a", "b"} {
+		if !g.Admit(context.Background(), event, r, FanoutPriorityLow) {
+			t.Errorf("receiver %s shed on an unlimited topic", r)
+		}
+	}
+}
+
+func TestFanoutGate_ShedsLowPriorityFirst(t *testing.T) {
+	g := NewFanoutGate(FanoutLimits{{Pattern: "fanprio.>", Max: 4}}, true)
+	event := &domain.Event{ID: "evt_1", Topic: "fanprio.created"}
+	ctx := context.Background()
+
+	// Low-priority receivers leave a quarter of the cap free
+	for _, r := range []string{"ws1", "ws2", "ws3"} {
+		if !g.Admit(ctx, event, r, FanoutPriorityLow) {
+			t.Fatalf("receiver %s shed under the cap", r)
+		}
+	}
+	if g.Admit(ctx, event, "ws4", FanoutPriorityLow) {
+		t.Error("low-priority receiver took the reserved slot")
+	}
+	if !g.Admit(ctx, event, "webhook", FanoutPriorityHigh) {
+		t.Error("high-priority receiver shed with a reserved slot free")
+	}
+	if g.Admit(ctx, event, "group", FanoutPriorityHigh) {
+		t.Error("high-priority receiver admitted past the cap")
+	}
+}
+
+func TestFanoutGate_ForgetsLeastRecentlySeen(t *testing.T) {
+	g := NewFanoutGate(FanoutLimits{{Pattern: "fanlru.>", Max: 1}}, true)
+	g.maxEvents = 2
+	ctx := context.Background()
+	evt := func(id string) *domain.Event { return &domain.Event{ID: id, Topic: "fanlru.created"} }
+
+	g.Admit(ctx, evt("evt_1"), "a", FanoutPriorityHigh)
+	g.Admit(ctx, evt("evt_2"), "a", FanoutPriorityHigh)
+	g.Admit(ctx, evt("evt_1"), "a", FanoutPriorityHigh) // evt_2 is now the oldest
+	g.Admit(ctx, evt("evt_3"), "a", FanoutPriorityHigh)
+
+	if len(g.events) != 2 || g.lru.Len() != 2 {
+		t.Fatalf("remembered %d events, want 2", len(g.events))
+	}
+	if _, ok := g.events["evt_2"]; ok {
+		t.Error("least recently seen event was kept")
+	}
+	// evt_1 still remembers its receiver
+	if g.Admit(ctx, evt("evt_1"), "b", FanoutPriorityHigh) {
+		t.Error("evt_1 lost its count")
+	}
+}
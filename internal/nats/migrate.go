@@ -0,0 +1,234 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// migrateCopySuffix names the temporary stream a copying migration stages
+// messages in.
+const migrateCopySuffix = "_MIGRATE"
+
+// migratePollInterval is how often a copying migration checks whether a
+// stream has caught up with its source.
+const migratePollInterval = 50 * time.Millisecond
+
+var (
+	// ErrStreamDataLoss is returned when applying a migration would discard
+	// stored messages and it wasn't explicitly allowed.
+	ErrStreamDataLoss = errors.New("migration would discard stored messages")
+	// ErrInvalidStreamSettings is returned for settings out of range.
+	ErrInvalidStreamSettings = errors.New("invalid stream settings")
+)
+
+// StreamSettings are the settings an operator may migrate an events stream
+// to. Zero fields keep the stream's current value.
+type StreamSettings struct {
+	MaxAge   time.Duration
+	MaxBytes int64
+	Replicas int
+	// Storage is "file" or "memory".
+	Storage string
+}
+
+// MigrateOptions controls how MigrateStream applies a migration.
+type MigrateOptions struct {
+	// DryRun only plans the migration.
+	DryRun bool
+	// AllowDataLoss applies a migration that discards stored messages.
+	AllowDataLoss bool
+}
+
+// StreamChange is one setting a migration changes.
+type StreamChange struct {
+	Setting string `json:"setting"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// StreamMigration reports a planned or applied stream migration.
+type StreamMigration struct {
+	Stream  string         `json:"stream"`
+	Changes []StreamChange `json:"changes"`
+	// Copy is set when JetStream can't apply the changes in place (a storage
+	// change), so messages are copied into a recreated stream.
+	Copy bool `json:"copy"`
+	// DataLoss describes stored messages the new settings would discard.
+	DataLoss []string `json:"data_loss,omitempty"`
+	// Messages is the stream's message count before the migration, and
+	// MessagesAfter once applied.
+	Messages      uint64 `json:"messages"`
+	MessagesAfter uint64 `json:"messages_after,omitempty"`
+	Applied       bool   `json:"applied"`
+}
+
+// MigrateStream moves the stream name to settings. Changes JetStream can
+// apply to a live stream go through CreateOrUpdateStream; a storage change
+// copies the messages into a recreated stream, keeping their sequence
+// numbers, which deletes the stream's consumers. A migration that would
+// discard stored messages fails with ErrStreamDataLoss unless allowed.
+func MigrateStream(ctx context.Context, js jetstream.JetStream, name string, settings StreamSettings, opts MigrateOptions) (*StreamMigration, error) {
+	stream, err := js.Stream(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("get stream %s: %w", name, err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get stream %s info: %w", name, err)
+	}
+
+	cfg, m, err := planStreamMigration(info, settings)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun || len(m.Changes) == 0 {
+		return m, nil
+	}
+	if len(m.DataLoss) > 0 && !opts.AllowDataLoss {
+		return m, ErrStreamDataLoss
+	}
+
+	if m.Copy {
+		err = copyStream(ctx, js, info, cfg)
+	} else {
+		_, err = js.CreateOrUpdateStream(ctx, cfg)
+	}
+	if err != nil {
+		return m, fmt.Errorf("migrate stream %s: %w", name, err)
+	}
+
+	after, err := js.Stream(ctx, name)
+	if err != nil {
+		return m, fmt.Errorf("get migrated stream %s: %w", name, err)
+	}
+	m.Applied = true
+	m.MessagesAfter = after.CachedInfo().State.Msgs
+	if m.MessagesAfter < m.Messages && len(m.DataLoss) == 0 {
+		return m, fmt.Errorf("migrate stream %s: %d messages before, %d after", name, m.Messages, m.MessagesAfter)
+	}
+	return m, nil
+}
+
+// MigrateStream migrates the org's events stream, as MigrateStream does.
+func (c *OrgClient) MigrateStream(ctx context.Context, settings StreamSettings, opts MigrateOptions) (*StreamMigration, error) {
+	return MigrateStream(ctx, c.js, StreamName+"_"+c.orgID, settings, opts)
+}
+
+// planStreamMigration returns the config info's stream would have with
+// settings, and the migration that gets it there.
+func planStreamMigration(info *jetstream.StreamInfo, settings StreamSettings) (jetstream.StreamConfig, *StreamMigration, error) {
+	cfg := info.Config
+	m := &StreamMigration{Stream: cfg.Name, Changes: []StreamChange{}, Messages: info.State.Msgs}
+
+	if settings.MaxAge < 0 || settings.MaxBytes < 0 || settings.Replicas < 0 || settings.Replicas > 5 {
+		return cfg, nil, fmt.Errorf("%w: max age and max bytes must be >= 0, replicas 1-5", ErrInvalidStreamSettings)
+	}
+	if settings.MaxAge != 0 && settings.MaxAge != cfg.MaxAge {
+		m.Changes = append(m.Changes, StreamChange{Setting: "max_age", From: cfg.MaxAge.String(), To: settings.MaxAge.String()})
+		cfg.MaxAge = settings.MaxAge
+		if oldest := info.State.FirstTime; info.State.Msgs > 0 && time.Since(oldest) > cfg.MaxAge {
+			m.DataLoss = append(m.DataLoss, fmt.Sprintf("messages older than %s are discarded (the oldest is from %s)", cfg.MaxAge, oldest.UTC().Format(time.RFC3339)))
+		}
+	}
+	if settings.MaxBytes != 0 && settings.MaxBytes != cfg.MaxBytes {
+		m.Changes = append(m.Changes, StreamChange{Setting: "max_bytes", From: strconv.FormatInt(cfg.MaxBytes, 10), To: strconv.FormatInt(settings.MaxBytes, 10)})
+		cfg.MaxBytes = settings.MaxBytes
+		if info.State.Bytes > uint64(cfg.MaxBytes) {
+			m.DataLoss = append(m.DataLoss, fmt.Sprintf("the stream holds %d bytes, so the oldest messages beyond %d bytes are discarded", info.State.Bytes, cfg.MaxBytes))
+		}
+	}
+	if settings.Replicas != 0 && settings.Replicas != cfg.Replicas {
+		m.Changes = append(m.Changes, StreamChange{Setting: "replicas", From: strconv.Itoa(cfg.Replicas), To: strconv.Itoa(settings.Replicas)})
+		cfg.Replicas = settings.Replicas
+	}
+	if settings.Storage != "" {
+		var storage jetstream.StorageType
+		switch settings.Storage {
+		case "file":
+			storage = jetstream.FileStorage
+		case "memory":
+			storage = jetstream.MemoryStorage
+		default:
+			return cfg, nil, fmt.Errorf("%w: storage %q must be file or memory", ErrInvalidStreamSettings, settings.Storage)
+		}
+		if storage != cfg.Storage {
+			m.Changes = append(m.Changes, StreamChange{Setting: "storage", From: cfg.Storage.String(), To: storage.String()})
+			cfg.Storage = storage
+			m.Copy = true
+		}
+	}
+	return cfg, m, nil
+}
+
+// copyStream recreates the stream described by info with cfg, copying its
+// messages through a temporary stream. JetStream sources a stream's
+// messages in order, and the recreated stream starts at the old first
+// sequence, so sequence numbers survive as long as there are no gaps.
+func copyStream(ctx context.Context, js jetstream.JetStream, info *jetstream.StreamInfo, cfg jetstream.StreamConfig) error {
+	name := cfg.Name
+	tmpName := name + migrateCopySuffix
+	if _, err := js.Stream(ctx, tmpName); err == nil {
+		return fmt.Errorf("stream %s exists, left by an earlier migration; inspect and delete it first", tmpName)
+	}
+
+	tmpCfg := cfg
+	tmpCfg.Name = tmpName
+	tmpCfg.Description = "migration copy of " + name
+	tmpCfg.Subjects = nil
+	tmpCfg.Sources = []*jetstream.StreamSource{{Name: name}}
+	if _, err := js.CreateStream(ctx, tmpCfg); err != nil {
+		return fmt.Errorf("create %s: %w", tmpName, err)
+	}
+	if err := waitForMessages(ctx, js, tmpName, info.State.Msgs); err != nil {
+		return err
+	}
+	// Sourcing back from a stream that still sources name would be a cycle
+	tmpCfg.Sources = nil
+	if _, err := js.UpdateStream(ctx, tmpCfg); err != nil {
+		return fmt.Errorf("detach %s from %s: %w", tmpName, name, err)
+	}
+
+	if err := js.DeleteStream(ctx, name); err != nil {
+		return fmt.Errorf("delete %s: %w", name, err)
+	}
+	newCfg := cfg
+	newCfg.FirstSeq = info.State.FirstSeq
+	newCfg.Sources = []*jetstream.StreamSource{{Name: tmpName}}
+	if _, err := js.CreateStream(ctx, newCfg); err != nil {
+		return fmt.Errorf("recreate %s (messages are kept in %s): %w", name, tmpName, err)
+	}
+	if err := waitForMessages(ctx, js, name, info.State.Msgs); err != nil {
+		return fmt.Errorf("%w (messages are kept in %s)", err, tmpName)
+	}
+
+	newCfg.Sources = nil
+	if _, err := js.UpdateStream(ctx, newCfg); err != nil {
+		return fmt.Errorf("detach %s from %s: %w", name, tmpName, err)
+	}
+	return js.DeleteStream(ctx, tmpName)
+}
+
+// waitForMessages waits until stream name holds at least n messages.
+func waitForMessages(ctx context.Context, js jetstream.JetStream, name string, n uint64) error {
+	ticker := time.NewTicker(migratePollInterval)
+	defer ticker.Stop()
+	for {
+		stream, err := js.Stream(ctx, name)
+		if err != nil {
+			return fmt.Errorf("get stream %s: %w", name, err)
+		}
+		if stream.CachedInfo().State.Msgs >= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("copy into %s: %w", name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
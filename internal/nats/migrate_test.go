@@ -0,0 +1,122 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestMigrateStream_Retention(t *testing.T) {
+	nc := startTestClient(t)
+	publishTestEvents(t, NewPublisher(nc.JetStream()), "orders.created", 5)
+	ctx := context.Background()
+
+	m, err := MigrateStream(ctx, nc.JetStream(), StreamName, StreamSettings{MaxAge: 72 * time.Hour}, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	want := StreamChange{Setting: "max_age", From: "24h0m0s", To: "72h0m0s"}
+	if len(m.Changes) != 1 || m.Changes[0] != want {
+		t.Errorf("changes = %+v, want [%+v]", m.Changes, want)
+	}
+	if !m.Applied || m.Copy || len(m.DataLoss) != 0 {
+		t.Errorf("migration = %+v, want applied in place without data loss", m)
+	}
+	if m.Messages != 5 || m.MessagesAfter != 5 {
+		t.Errorf("messages = %d before, %d after, want 5", m.Messages, m.MessagesAfter)
+	}
+
+	info, err := nc.Stream().Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Config.MaxAge != 72*time.Hour {
+		t.Errorf("max age = %v, want 72h", info.Config.MaxAge)
+	}
+
+	// Migrating again to the same settings changes nothing
+	if m, err := MigrateStream(ctx, nc.JetStream(), StreamName, StreamSettings{MaxAge: 72 * time.Hour}, MigrateOptions{}); err != nil || len(m.Changes) != 0 || m.Applied {
+		t.Errorf("repeat migration = %+v, %v, want no changes", m, err)
+	}
+}
+
+func TestMigrateStream_DryRunFlagsDataLoss(t *testing.T) {
+	nc := startTestClient(t)
+	publishTestEvents(t, NewPublisher(nc.JetStream()), "orders.created", 20)
+	ctx := context.Background()
+
+	before, err := nc.Stream().Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := StreamSettings{MaxBytes: int64(before.State.Bytes / 2)}
+
+	m, err := MigrateStream(ctx, nc.JetStream(), StreamName, settings, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if m.Applied || len(m.Changes) != 1 || len(m.DataLoss) != 1 {
+		t.Errorf("dry run = %+v, want one change flagged as data loss, not applied", m)
+	}
+
+	if _, err := MigrateStream(ctx, nc.JetStream(), StreamName, settings, MigrateOptions{}); !errors.Is(err, ErrStreamDataLoss) {
+		t.Errorf("apply err = %v, want ErrStreamDataLoss", err)
+	}
+	after, err := nc.Stream().Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Config.MaxBytes != before.Config.MaxBytes || after.State.Msgs != 20 {
+		t.Errorf("stream changed by a refused migration: max bytes %d, %d messages", after.Config.MaxBytes, after.State.Msgs)
+	}
+
+	if _, err := MigrateStream(ctx, nc.JetStream(), StreamName, StreamSettings{Storage: "tape"}, MigrateOptions{DryRun: true}); err == nil {
+		t.Error("unknown storage type accepted")
+	}
+}
+
+func TestMigrateStream_StorageCopies(t *testing.T) {
+	nc := startTestClient(t)
+	publishTestEvents(t, NewPublisher(nc.JetStream()), "orders.created", 10)
+	ctx := context.Background()
+
+	m, err := MigrateStream(ctx, nc.JetStream(), StreamName, StreamSettings{Storage: "memory"}, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !m.Copy || m.Applied {
+		t.Fatalf("dry run = %+v, want a copy, not applied", m)
+	}
+
+	m, err = MigrateStream(ctx, nc.JetStream(), StreamName, StreamSettings{Storage: "memory"}, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if !m.Applied || m.MessagesAfter != 10 {
+		t.Errorf("migration = %+v, want 10 messages copied", m)
+	}
+
+	stream, err := nc.JetStream().Stream(ctx, StreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := stream.CachedInfo()
+	if info.Config.Storage != jetstream.MemoryStorage || len(info.Config.Sources) != 0 {
+		t.Errorf("config = %+v, want memory storage without sources", info.Config)
+	}
+	if info.State.FirstSeq != 1 || info.State.LastSeq != 10 {
+		t.Errorf("sequences %d-%d, want 1-10", info.State.FirstSeq, info.State.LastSeq)
+	}
+	if _, err := nc.JetStream().Stream(ctx, StreamName+migrateCopySuffix); !errors.Is(err, jetstream.ErrStreamNotFound) {
+		t.Errorf("temporary stream left behind: %v", err)
+	}
+
+	// New events still land in the migrated stream
+	publishTestEvents(t, NewPublisher(nc.JetStream()), "orders.created", 1)
+	if info, err := stream.Info(ctx); err != nil || info.State.LastSeq != 11 {
+		t.Errorf("after publish: %+v, %v, want last seq 11", info, err)
+	}
+}
@@ -62,6 +62,10 @@ type ClientPool struct {
 
 	// Track account key pairs for user JWT generation (in-memory only)
 	accountKeys map[string]nkeys.KeyPair // orgID -> account key pair
+
+	// Orgs drained for maintenance (in-memory only): emits are refused and
+	// deliveries paused until resumed.
+	drained map[string]bool
 }
 
 // NewClientPool creates a new ClientPool.
@@ -73,6 +77,7 @@ func NewClientPool(natsURL string, operatorKP nkeys.KeyPair, jwtMgr *accounts.JW
 		jwtMgr:      jwtMgr,
 		auditLog:    auditLog,
 		accountKeys: make(map[string]nkeys.KeyPair),
+		drained:     make(map[string]bool),
 	}
 }
 
@@ -292,11 +297,35 @@ func (p *ClientPool) Remove(orgID string) error {
 	client.Close()
 	delete(p.clients, orgID)
 	delete(p.accountKeys, orgID)
+	delete(p.drained, orgID)
 
 	slog.Info("org disconnected from NATS", "org_id", orgID)
 	return nil
 }
 
+// SetDrained marks an org as drained for maintenance, or clears the mark.
+func (p *ClientPool) SetDrained(orgID string, drained bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.clients[orgID]; !ok {
+		return fmt.Errorf("no connection for org %s", orgID)
+	}
+	if drained {
+		p.drained[orgID] = true
+	} else {
+		delete(p.drained, orgID)
+	}
+	return nil
+}
+
+// IsDrained reports whether an org is drained for maintenance.
+func (p *ClientPool) IsDrained(orgID string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.drained[orgID]
+}
+
 // AccountKey returns the account key pair for an org (in-memory only).
 func (p *ClientPool) AccountKey(orgID string) (nkeys.KeyPair, error) {
 	p.mu.RLock()
@@ -458,7 +487,7 @@ func (p *ClientPool) Boot(ctx context.Context, queries *db.Queries) error {
 // ensureStreamsForOrg creates the 3 per-account streams for an org.
 func ensureStreamsForOrg(ctx context.Context, js jetstream.JetStream, orgID string) (jetstream.Stream, error) {
 	// Main events stream
-	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+	eventsCfg := jetstream.StreamConfig{
 		Name:        StreamName + "_" + orgID,
 		Description: fmt.Sprintf("notif.sh events for org %s", orgID),
 		Subjects:    []string{"events.>"},
@@ -468,7 +497,16 @@ func ensureStreamsForOrg(ctx context.Context, js jetstream.JetStream, orgID stri
 		MaxBytes:    1 << 30, // 1GB
 		Replicas:    1,
 		Discard:     jetstream.DiscardOld,
-	})
+	}
+	// Keep settings an operator migrated the stream to (see MigrateStream)
+	if existing, err := js.Stream(ctx, eventsCfg.Name); err == nil {
+		tuned := existing.CachedInfo().Config
+		eventsCfg.Storage = tuned.Storage
+		eventsCfg.MaxAge = tuned.MaxAge
+		eventsCfg.MaxBytes = tuned.MaxBytes
+		eventsCfg.Replicas = tuned.Replicas
+	}
+	stream, err := js.CreateOrUpdateStream(ctx, eventsCfg)
 	if err != nil {
 		return nil, fmt.Errorf("create events stream for %s: %w", orgID, err)
 	}
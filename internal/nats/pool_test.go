@@ -0,0 +1,30 @@
+package nats
+
+import "testing"
+
+func TestClientPool_Drained(t *testing.T) {
+	p := NewClientPool("", nil, nil, nil)
+	p.clients["org_maint"] = &OrgClient{orgID: "org_maint"}
+	p.clients["org_live"] = &OrgClient{orgID: "org_live"}
+
+	if err := p.SetDrained("org_maint", true); err != nil {
+		t.Fatalf("SetDrained: %v", err)
+	}
+	if !p.IsDrained("org_maint") {
+		t.Error("org_maint should be drained")
+	}
+	if p.IsDrained("org_live") {
+		t.Error("draining one org must not affect another")
+	}
+
+	if err := p.SetDrained("org_maint", false); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if p.IsDrained("org_maint") {
+		t.Error("org_maint should be active after resume")
+	}
+
+	if err := p.SetDrained("org_unknown", true); err == nil {
+		t.Error("expected error for an org not in the pool")
+	}
+}
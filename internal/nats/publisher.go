@@ -7,9 +7,24 @@ import (
 	"log/slog"
 
 	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/tracing"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+// EventFromMsg decodes the event in msg, restoring its trace context from
+// the message headers.
+func EventFromMsg(msg jetstream.Msg) (*domain.Event, error) {
+	var event domain.Event
+	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+		return nil, err
+	}
+	if h := msg.Headers(); h != nil {
+		event.Traceparent = h.Get(tracing.TraceparentHeader)
+	}
+	return &event, nil
+}
+
 // Publisher publishes events to JetStream.
 type Publisher struct {
 	js jetstream.JetStream
@@ -38,8 +53,13 @@ func (p *Publisher) Publish(ctx context.Context, event *domain.Event) error {
 		return fmt.Errorf("marshal event: %w", err)
 	}
 
+	msg := &nats.Msg{Subject: subject, Data: data, Header: nats.Header{}}
+	if event.Traceparent != "" {
+		msg.Header.Set(tracing.TraceparentHeader, event.Traceparent)
+	}
+
 	// Synchronous publish with ack from JetStream
-	ack, err := p.js.Publish(ctx, subject, data,
+	ack, err := p.js.PublishMsg(ctx, msg,
 		jetstream.WithMsgID(event.ID), // Deduplication
 	)
 	if err != nil {
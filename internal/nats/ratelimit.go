@@ -0,0 +1,174 @@
+package nats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// emitRateSweepInterval is how often idle token buckets are looked for.
+const emitRateSweepInterval = time.Minute
+
+// EmitRateLimit allows Rate events per second, with bursts of up to Burst,
+// on the topics matching Pattern in one project.
+type EmitRateLimit struct {
+	Pattern string
+	Rate    float64
+	Burst   int
+}
+
+// EmitRateLimits is an ordered list of per-topic emit rate limits; the
+// first matching pattern wins. Topics matching none are unlimited.
+type EmitRateLimits []EmitRateLimit
+
+// For returns the limit for topic, or nil when it is unlimited.
+func (ls EmitRateLimits) For(topic string) *EmitRateLimit {
+	for i := range ls {
+		if MatchSubject(ls[i].Pattern, topic) {
+			return &ls[i]
+		}
+	}
+	return nil
+}
+
+// ParseEmitRateLimits parses a comma-separated list of pattern=N[:B]
+// entries, N events per second with bursts of B (default N), e.g.
+// "orders.>=50:100,>=1000".
+func ParseEmitRateLimits(s string) (EmitRateLimits, error) {
+	var limits EmitRateLimits
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("emit rate limit %q: expected pattern=N[:B]", entry)
+		}
+		pattern, spec = strings.TrimSpace(pattern), strings.TrimSpace(spec)
+		if err := ValidateSubject(pattern, true); err != nil {
+			return nil, fmt.Errorf("emit rate limit %q: %w", entry, err)
+		}
+		rateSpec, burstSpec, hasBurst := strings.Cut(spec, ":")
+		n, err := strconv.ParseFloat(rateSpec, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("emit rate limit %q: N must be > 0", entry)
+		}
+		burst := max(int(n), 1)
+		if hasBurst {
+			burst, err = strconv.Atoi(burstSpec)
+			if err != nil || burst < 1 {
+				return nil, fmt.Errorf("emit rate limit %q: B must be >= 1", entry)
+			}
+		}
+		limits = append(limits, EmitRateLimit{Pattern: pattern, Rate: n, Burst: burst})
+	}
+	return limits, nil
+}
+
+// UnmarshalText parses limits in the ParseEmitRateLimits format, so they
+// can be loaded straight from the environment.
+func (ls *EmitRateLimits) UnmarshalText(text []byte) error {
+	limits, err := ParseEmitRateLimits(string(text))
+	if err != nil {
+		return err
+	}
+	*ls = limits
+	return nil
+}
+
+// EmitRateLimiter enforces EmitRateLimits with a token bucket per project
+// and pattern, so one project's traffic never draws on another's. A bucket
+// idle long enough to refill is dropped, since a new one starts full; that
+// keeps memory bounded by the projects emitting recently. A nil limiter
+// allows everything.
+type EmitRateLimiter struct {
+	limits EmitRateLimits
+
+	mu        sync.Mutex
+	buckets   map[emitRateKey]*emitRateBucket
+	lastSweep time.Time
+}
+
+type emitRateKey struct {
+	projectID string
+	pattern   string
+}
+
+type emitRateBucket struct {
+	limiter *rate.Limiter
+	idleTTL time.Duration
+	seen    time.Time
+}
+
+// NewEmitRateLimiter creates a limiter enforcing limits, or returns nil
+// when there are none.
+func NewEmitRateLimiter(limits EmitRateLimits) *EmitRateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	return &EmitRateLimiter{
+		limits:    limits,
+		buckets:   make(map[emitRateKey]*emitRateBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token for an emit to topic in projectID. When the bucket
+// is empty it returns false and how long until a token is available.
+func (l *EmitRateLimiter) Allow(projectID, topic string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	limit := l.limits.For(topic)
+	if limit == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.sweep(now)
+
+	key := emitRateKey{projectID: projectID, pattern: limit.Pattern}
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &emitRateBucket{
+			limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst),
+			idleTTL: time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second)),
+		}
+		l.buckets[key] = bucket
+	}
+	bucket.seen = now
+
+	r := bucket.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep drops buckets idle long enough to have refilled. Callers hold l.mu.
+func (l *EmitRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < emitRateSweepInterval {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.seen) >= bucket.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// size returns the number of live buckets.
+func (l *EmitRateLimiter) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
@@ -0,0 +1,87 @@
+package nats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEmitRateLimits(t *testing.T) {
+	limits, err := ParseEmitRateLimits("orders.>=50:100, logs.*=0.5,>=1000")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for topic, want := range map[string]EmitRateLimit{
+		"orders.created": {Pattern: "orders.>", Rate: 50, Burst: 100},
+		"logs.app":       {Pattern: "logs.*", Rate: 0.5, Burst: 1},
+		"users":          {Pattern: ">", Rate: 1000, Burst: 1000},
+	} {
+		if got := limits.For(topic); got == nil || *got != want {
+			t.Errorf("For(%q) = %v, want %v", topic, got, want)
+		}
+	}
+
+	if got := EmitRateLimits(nil).For("orders.created"); got != nil {
+		t.Errorf("no limits: For = %v, want nil (unlimited)", got)
+	}
+}
+
+func TestParseEmitRateLimits_Invalid(t *testing.T) {
+	for _, s := range []string{"orders.>", "orders.>=0", "orders.>=x", "orders.>=5:0", "orders.>=5:x", "orders.>.x=5"} {
+		if _, err := ParseEmitRateLimits(s); err == nil {
+			t.Errorf("ParseEmitRateLimits(%q) = nil error, want error", s)
+		}
+	}
+}
+
+func TestEmitRateLimiter_PerProject(t *testing.T) {
+	l := NewEmitRateLimiter(EmitRateLimits{{Pattern: "orders.>", Rate: 1, Burst: 2}})
+
+	for i := range 2 {
+		if ok, _ := l.Allow("prj_a", "orders.created"); !ok {
+			t.Fatalf("emit %d within the burst was limited", i+1)
+		}
+	}
+	// Topics under one pattern share its bucket
+	ok, retryAfter := l.Allow("prj_a", "orders.eu.shipped")
+	if ok {
+		t.Fatal("emit past the burst was allowed")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retry after = %v, want (0, 1s]", retryAfter)
+	}
+
+	// Another project and unlimited topics are unaffected
+	if ok, _ := l.Allow("prj_b", "orders.created"); !ok {
+		t.Error("other project was limited")
+	}
+	if ok, _ := l.Allow("prj_a", "users.signup"); !ok {
+		t.Error("unlimited topic was limited")
+	}
+
+	if ok, _ := (*EmitRateLimiter)(nil).Allow("prj_a", "orders.created"); !ok {
+		t.Error("nil limiter limited an emit")
+	}
+}
+
+func TestEmitRateLimiter_ExpiresIdleBuckets(t *testing.T) {
+	l := NewEmitRateLimiter(EmitRateLimits{{Pattern: ">", Rate: 10, Burst: 10}})
+	for _, project := range []string{"prj_a", "prj_b", "prj_c"} {
+		l.Allow(project, "orders.created")
+	}
+	if got := l.size(); got != 3 {
+		t.Fatalf("buckets = %d, want 3", got)
+	}
+
+	// Buckets refill in 1s; backdate them and the last sweep
+	l.mu.Lock()
+	for _, bucket := range l.buckets {
+		bucket.seen = bucket.seen.Add(-2 * time.Second)
+	}
+	l.lastSweep = l.lastSweep.Add(-emitRateSweepInterval)
+	l.mu.Unlock()
+
+	l.Allow("prj_a", "orders.created")
+	if got := l.size(); got != 1 {
+		t.Errorf("buckets after sweep = %d, want 1", got)
+	}
+}
@@ -3,6 +3,7 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,6 +28,7 @@ type QueryOptions struct {
 	ProjectID string    // Required: filter by project
 	From      time.Time // Start time (inclusive)
 	To        time.Time // End time (exclusive), zero means now
+	AfterSeq  uint64    // Cursor: only events after this stream sequence
 	Limit     int
 }
 
@@ -65,7 +67,10 @@ func (r *EventReader) Query(ctx context.Context, opts QueryOptions) ([]StoredEve
 		AckPolicy:     jetstream.AckNonePolicy,
 	}
 
-	if !opts.From.IsZero() {
+	if opts.AfterSeq > 0 {
+		consumerCfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		consumerCfg.OptStartSeq = opts.AfterSeq + 1
+	} else if !opts.From.IsZero() {
 		consumerCfg.DeliverPolicy = jetstream.DeliverByStartTimePolicy
 		consumerCfg.OptStartTime = &opts.From
 	} else {
@@ -103,6 +108,9 @@ func (r *EventReader) Query(ctx context.Context, opts QueryOptions) ([]StoredEve
 		if !opts.To.IsZero() && msgTime.After(opts.To) {
 			break
 		}
+		if opts.AfterSeq > 0 && msgTime.Before(opts.From) {
+			continue
+		}
 
 		events = append(events, StoredEvent{
 			Seq:       seq,
@@ -137,7 +145,74 @@ func (r *EventReader) GetBySeq(ctx context.Context, seq uint64) (*StoredEvent, e
 	}, nil
 }
 
+// ErrEventNotFound is returned by GetByID when the event is not retained
+// in the stream.
+var ErrEventNotFound = errors.New("event not found in stream")
+
+// GetByID finds a project's event by ID among those retained on topic.
+func (r *EventReader) GetByID(ctx context.Context, orgID, projectID, topic, id string) (*StoredEvent, error) {
+	if orgID == "" || projectID == "" {
+		return nil, fmt.Errorf("org_id and project_id are required")
+	}
+
+	consumer, err := r.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		FilterSubject: "events." + orgID + "." + projectID + "." + topic,
+		AckPolicy:     jetstream.AckNonePolicy,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	info, err := consumer.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for remaining := int(info.NumPending); remaining > 0; {
+		msgs, err := consumer.Fetch(min(remaining, 256), jetstream.FetchMaxWait(2*time.Second))
+		if err != nil {
+			break
+		}
+		fetched := 0
+		for msg := range msgs.Messages() {
+			fetched++
+			var event domain.Event
+			if err := json.Unmarshal(msg.Data(), &event); err != nil || event.ID != id {
+				continue
+			}
+			stored := &StoredEvent{Event: &event, Timestamp: event.Timestamp}
+			if meta, _ := msg.Metadata(); meta != nil {
+				stored.Seq = meta.Sequence.Stream
+				stored.Timestamp = meta.Timestamp
+			}
+			return stored, nil
+		}
+		if fetched == 0 {
+			break
+		}
+		remaining -= fetched
+	}
+	return nil, ErrEventNotFound
+}
+
 // StreamInfo returns information about the events stream.
 func (r *EventReader) StreamInfo(ctx context.Context) (*jetstream.StreamInfo, error) {
 	return r.stream.Info(ctx)
 }
+
+// ProjectMessages counts the project's events currently retained in the
+// stream.
+func (r *EventReader) ProjectMessages(ctx context.Context, orgID, projectID string) (uint64, error) {
+	if orgID == "" || projectID == "" {
+		return 0, fmt.Errorf("org_id and project_id are required")
+	}
+	info, err := r.stream.Info(ctx, jetstream.WithSubjectFilter("events."+orgID+"."+projectID+".>"))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, count := range info.State.Subjects {
+		n += count
+	}
+	return n, nil
+}
@@ -0,0 +1,44 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+func TestEventReader_GetByID(t *testing.T) {
+	nc := startTestClient(t)
+	pub := NewPublisher(nc.JetStream())
+	publishTestEvents(t, pub, "orders.created", 3)
+
+	target := domain.NewEvent("orders.created", []byte(`{"n":99}`))
+	target.OrgID = "org_test"
+	target.ProjectID = "prj_test"
+	if err := pub.Publish(context.Background(), target); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	publishTestEvents(t, pub, "orders.created", 2)
+
+	reader := NewEventReader(nc.Stream())
+	ctx := context.Background()
+
+	got, err := reader.GetByID(ctx, "org_test", "prj_test", "orders.created", target.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Event.ID != target.ID || string(got.Event.Data) != `{"n":99}` || got.Seq == 0 {
+		t.Errorf("got %+v", got)
+	}
+
+	// Other projects and topics don't see it
+	for _, tt := range []struct{ project, topic string }{
+		{"prj_other", "orders.created"},
+		{"prj_test", "orders.updated"},
+	} {
+		if _, err := reader.GetByID(ctx, "org_test", tt.project, tt.topic, target.ID); !errors.Is(err, ErrEventNotFound) {
+			t.Errorf("GetByID(%s, %s): err = %v, want ErrEventNotFound", tt.project, tt.topic, err)
+		}
+	}
+}
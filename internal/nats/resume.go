@@ -0,0 +1,88 @@
+package nats
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+)
+
+// resumeTokenPrefix marks a subscribe "from" value as a resume token.
+const resumeTokenPrefix = "rt_"
+
+// Versions of an encoded token, its first byte. A cursor token also names
+// the durable consumer a "resume" subscription continues.
+const (
+	resumeTokenVersion       = 1
+	resumeCursorTokenVersion = 2
+)
+
+// resumeCursorSize is the size in bytes of a resume cursor ID.
+const resumeCursorSize = 8
+
+// ResumeToken returns an opaque token that resumes a subscription right
+// after the event at stream sequence seq.
+func ResumeToken(seq uint64) string {
+	b := make([]byte, 9)
+	b[0] = resumeTokenVersion
+	binary.BigEndian.PutUint64(b[1:], seq)
+	return resumeTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CursorResumeToken returns a token for the resume cursor at stream
+// sequence seq. The cursor is an ID from NewResumeCursor; without one this
+// is ResumeToken.
+func CursorResumeToken(cursor string, seq uint64) string {
+	id, err := hex.DecodeString(cursor)
+	if err != nil || len(id) != resumeCursorSize {
+		return ResumeToken(seq)
+	}
+	b := make([]byte, 9, 9+resumeCursorSize)
+	b[0] = resumeCursorTokenVersion
+	binary.BigEndian.PutUint64(b[1:], seq)
+	b = append(b, id...)
+	return resumeTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// NewResumeCursor returns a new random resume cursor ID.
+func NewResumeCursor() string {
+	id := make([]byte, resumeCursorSize)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// ParseResumeToken returns the stream sequence a token was issued for.
+func ParseResumeToken(token string) (uint64, bool) {
+	seq, _, ok := parseResumeToken(token)
+	return seq, ok
+}
+
+// ResumeCursor returns the cursor ID a token carries, or "" if it has none.
+func ResumeCursor(token string) string {
+	_, cursor, _ := parseResumeToken(token)
+	return cursor
+}
+
+func parseResumeToken(token string) (seq uint64, cursor string, ok bool) {
+	encoded, ok := strings.CutPrefix(token, resumeTokenPrefix)
+	if !ok {
+		return 0, "", false
+	}
+	b, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(b) < 9 {
+		return 0, "", false
+	}
+	switch {
+	case b[0] == resumeTokenVersion && len(b) == 9:
+	case b[0] == resumeCursorTokenVersion && len(b) == 9+resumeCursorSize:
+		cursor = hex.EncodeToString(b[9:])
+	default:
+		return 0, "", false
+	}
+	seq = binary.BigEndian.Uint64(b[1:9])
+	if seq == 0 {
+		return 0, "", false
+	}
+	return seq, cursor, true
+}
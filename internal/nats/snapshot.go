@@ -0,0 +1,129 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// KeySnapshot picks, among the events stored when a subscription started,
+// the latest one per key. Events stored later are always delivered.
+type KeySnapshot struct {
+	// Through is the stream sequence of the last stored event scanned.
+	Through uint64
+	// Keys is the number of distinct keys.
+	Keys   int
+	latest map[uint64]struct{}
+}
+
+// Skip reports whether the event at stream sequence seq is superseded by a
+// later event with the same key.
+func (s *KeySnapshot) Skip(seq uint64) bool {
+	if s == nil || seq > s.Through {
+		return false
+	}
+	_, ok := s.latest[seq]
+	return !ok
+}
+
+// ParseKeyField splits a dot-separated path into event data, e.g.
+// "customer.id".
+func ParseKeyField(field string) ([]string, error) {
+	path := strings.Split(field, ".")
+	for _, segment := range path {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid key field %q", field)
+		}
+	}
+	return path, nil
+}
+
+// LatestPerKey scans the stored events the subscription's topics match and
+// returns a snapshot of the latest event per distinct value of the data
+// field at keyPath. Events without the field have no key and are never
+// skipped.
+func (cm *ConsumerManager) LatestPerKey(ctx context.Context, opts SubscriptionOptions, keyPath []string) (*KeySnapshot, error) {
+	if opts.OrgID == "" || opts.ProjectID == "" {
+		return nil, fmt.Errorf("org_id and project_id are required")
+	}
+
+	consumer, err := cm.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		FilterSubjects: opts.filterSubjects(),
+		AckPolicy:      jetstream.AckNonePolicy,
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot consumer: %w", err)
+	}
+	info, err := consumer.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cm.stream.DeleteConsumer(context.WithoutCancel(ctx), info.Name)
+
+	snapshot := &KeySnapshot{latest: make(map[uint64]struct{})}
+	byKey := make(map[string]uint64)
+	for remaining := int(info.NumPending); remaining > 0; {
+		msgs, err := consumer.Fetch(min(remaining, 256), jetstream.FetchMaxWait(2*time.Second))
+		if err != nil {
+			return nil, fmt.Errorf("scan stored events: %w", err)
+		}
+		fetched := 0
+		for msg := range msgs.Messages() {
+			fetched++
+			meta, err := msg.Metadata()
+			if err != nil {
+				continue
+			}
+			seq := meta.Sequence.Stream
+			snapshot.Through = seq
+
+			key, ok := eventKey(msg.Data(), keyPath)
+			if !ok {
+				snapshot.latest[seq] = struct{}{}
+				continue
+			}
+			if prev, ok := byKey[key]; ok {
+				delete(snapshot.latest, prev)
+			}
+			byKey[key] = seq
+			snapshot.latest[seq] = struct{}{}
+		}
+		if fetched == 0 {
+			break
+		}
+		remaining -= fetched
+	}
+	snapshot.Keys = len(byKey)
+	return snapshot, nil
+}
+
+// eventKey returns the JSON encoding of the event data field at path, so
+// the string "1" and the number 1 are different keys.
+func eventKey(raw []byte, path []string) (string, bool) {
+	var event struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return "", false
+	}
+	value := event.Data
+	for _, segment := range path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return "", false
+		}
+		var ok bool
+		if value, ok = obj[segment]; !ok {
+			return "", false
+		}
+	}
+	if string(value) == "null" {
+		return "", false
+	}
+	return string(value), true
+}
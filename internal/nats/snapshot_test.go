@@ -0,0 +1,56 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+func TestLatestPerKey(t *testing.T) {
+	nc := startTestClient(t)
+	pub := NewPublisher(nc.JetStream())
+	ctx := context.Background()
+
+	for _, data := range []string{
+		`{"customer":{"id":1},"v":1}`, // seq 1, superseded by 3
+		`{"customer":{"id":"1"},"v":2}`,
+		`{"customer":{"id":1},"v":3}`,
+		`{"v":4}`, // no key: kept
+		`{"customer":{"id":null},"v":5}`,
+	} {
+		event := domain.NewEvent("customers.updated", json.RawMessage(data))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		if err := pub.Publish(ctx, event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	path, err := ParseKeyField("customer.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := NewConsumerManager(nc.Stream())
+	opts := SubscriptionOptions{Topics: []string{"customers.*"}, OrgID: "org_test", ProjectID: "prj_test"}
+	snapshot, err := cm.LatestPerKey(ctx, opts, path)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	if snapshot.Through != 5 || snapshot.Keys != 2 {
+		t.Errorf("snapshot through %d with %d keys, want 5 and 2", snapshot.Through, snapshot.Keys)
+	}
+	for seq, skip := range map[uint64]bool{1: true, 2: false, 3: false, 4: false, 5: false, 6: false} {
+		if got := snapshot.Skip(seq); got != skip {
+			t.Errorf("Skip(%d) = %v, want %v", seq, got, skip)
+		}
+	}
+	if (*KeySnapshot)(nil).Skip(1) {
+		t.Error("nil snapshot skipped an event")
+	}
+
+	if _, err := ParseKeyField("customer..id"); err == nil {
+		t.Error("empty path segment accepted")
+	}
+}
@@ -0,0 +1,119 @@
+package nats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateSubject checks that subject is a legal NATS subject. With
+// wildcards, `*` may stand for any single token and a trailing `>` for the
+// rest of the subject; otherwise the subject must be concrete.
+func ValidateSubject(subject string, wildcards bool) error {
+	if subject == "" {
+		return fmt.Errorf("subject is empty")
+	}
+	if strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("subject %q contains whitespace", subject)
+	}
+	tokens := strings.Split(subject, ".")
+	for i, tok := range tokens {
+		switch {
+		case tok == "":
+			return fmt.Errorf("subject %q has an empty token", subject)
+		case tok == "*" || tok == ">":
+			if !wildcards {
+				return fmt.Errorf("subject %q must not contain wildcards", subject)
+			}
+			if tok == ">" && i != len(tokens)-1 {
+				return fmt.Errorf("subject %q: > must be the last token", subject)
+			}
+		case strings.ContainsAny(tok, "*>"):
+			return fmt.Errorf("subject %q: wildcards must be whole tokens", subject)
+		}
+	}
+	return nil
+}
+
+// MatchSubject reports whether subject matches pattern, where `*` matches
+// one token and a trailing `>` one or more.
+func MatchSubject(pattern, subject string) bool {
+	pt := strings.Split(pattern, ".")
+	st := strings.Split(subject, ".")
+	for i, tok := range pt {
+		if tok == ">" {
+			return len(st) > i
+		}
+		if i >= len(st) || (tok != "*" && tok != st[i]) {
+			return false
+		}
+	}
+	return len(pt) == len(st)
+}
+
+// NormalizeTopics returns the topics a subscription filters on. A
+// standalone `*` means every topic, so it becomes `>`; topics another one
+// already covers, such as `orders.*.shipped` next to `orders.>`, and
+// repeats are dropped, since JetStream refuses overlapping filters.
+// Topics that only partly overlap are kept and still rejected.
+func NormalizeTopics(topics []string) []string {
+	all := make([]string, len(topics))
+	for i, topic := range topics {
+		if topic == "*" {
+			topic = ">"
+		}
+		all[i] = topic
+	}
+
+	kept := make([]string, 0, len(all))
+	for i, topic := range all {
+		covered := false
+		for j, other := range all {
+			// Of two equal topics the first is kept
+			if i != j && subjectCovers(other, topic) && (other != topic || j < i) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, topic)
+		}
+	}
+	return kept
+}
+
+// subjectCovers reports whether every subject matching pattern b also
+// matches pattern a.
+func subjectCovers(a, b string) bool {
+	at := strings.Split(a, ".")
+	bt := strings.Split(b, ".")
+	for i, tok := range at {
+		if tok == ">" {
+			return len(bt) > i
+		}
+		if i >= len(bt) || bt[i] == ">" {
+			return false
+		}
+		if tok != "*" && tok != bt[i] {
+			return false
+		}
+	}
+	return len(at) == len(bt)
+}
+
+// subjectsOverlap reports whether some subject matches both pattern a and
+// pattern b.
+func subjectsOverlap(a, b string) bool {
+	at := strings.Split(a, ".")
+	bt := strings.Split(b, ".")
+	for i := 0; ; i++ {
+		if i == len(at) || i == len(bt) {
+			return len(at) == len(bt)
+		}
+		if at[i] == ">" || bt[i] == ">" {
+			return true
+		}
+		if at[i] != "*" && bt[i] != "*" && at[i] != bt[i] {
+			return false
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package nats
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidateSubject(t *testing.T) {
+	tests := []struct {
+		subject   string
+		wildcards bool
+		ok        bool
+	}{
+		{"events.org.project.orders", false, true},
+		{"events.org.*.orders", true, true},
+		{"events.org.>", true, true},
+		{"events.org.>", false, false},
+		{"events.>.orders", true, false},
+		{"events.org*.orders", true, false},
+		{"events..orders", true, false},
+		{".events", true, false},
+		{"events.", true, false},
+		{"events orders", true, false},
+		{"", true, false},
+	}
+	for _, tt := range tests {
+		err := ValidateSubject(tt.subject, tt.wildcards)
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateSubject(%q, %v) = %v, want ok=%v", tt.subject, tt.wildcards, err, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeTopics(t *testing.T) {
+	tests := []struct {
+		name   string
+		topics []string
+		want   []string
+	}{
+		{"mid star with trailing >", []string{"a.*.b.>"}, []string{"a.*.b.>"}},
+		{"disjoint patterns", []string{"orders.*.shipped", "logs.>"}, []string{"orders.*.shipped", "logs.>"}},
+		{"standalone star", []string{"*"}, []string{">"}},
+		{"standalone >", []string{">"}, []string{">"}},
+		{"star covers everything", []string{"orders.created", "*", "a.*.b.>"}, []string{">"}},
+		{"covered by trailing >", []string{"orders.*.shipped", "orders.>"}, []string{"orders.>"}},
+		{"covered by mid star", []string{"a.x.b.>", "a.*.b.>"}, []string{"a.*.b.>"}},
+		{"repeats", []string{"logs.>", "logs.>"}, []string{"logs.>"}},
+		{"> needs a token", []string{"a.*.b", "a.*.b.>"}, []string{"a.*.b", "a.*.b.>"}},
+		{"partial overlap kept", []string{"a.*.c", "a.b.*"}, []string{"a.*.c", "a.b.*"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTopics(tt.topics); !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeTopics(%v) = %v, want %v", tt.topics, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResumeToken(t *testing.T) {
+	for _, seq := range []uint64{1, 42, 1 << 40} {
+		got, ok := ParseResumeToken(ResumeToken(seq))
+		if !ok || got != seq {
+			t.Errorf("ParseResumeToken(ResumeToken(%d)) = %d, %v", seq, got, ok)
+		}
+	}
+	cursor := NewResumeCursor()
+	token := CursorResumeToken(cursor, 42)
+	if seq, ok := ParseResumeToken(token); !ok || seq != 42 || ResumeCursor(token) != cursor {
+		t.Errorf("cursor token %q = seq %d, cursor %q, %v", token, seq, ResumeCursor(token), ok)
+	}
+	if ResumeCursor(ResumeToken(42)) != "" {
+		t.Error("plain token has a cursor")
+	}
+	for _, token := range []string{"", "latest", "2024-01-01T00:00:00Z", "rt_", "rt_!!!", "rt_AQ", ResumeToken(0), CursorResumeToken(cursor, 0)} {
+		if _, ok := ParseResumeToken(token); ok {
+			t.Errorf("expected %q to be rejected", token)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+// Memory keeps pending events in process. It is meant for tests and
+// single-node development; nothing survives a restart.
+type Memory struct {
+	mu      sync.Mutex
+	entries []*memoryEntry
+}
+
+type memoryEntry struct {
+	Entry
+	next time.Time
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Enqueue adds the event, due immediately.
+func (m *Memory) Enqueue(_ context.Context, event *domain.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, &memoryEntry{Entry: Entry{Event: event}})
+	return nil
+}
+
+// Due claims the entries ready for another attempt, oldest first.
+func (m *Memory) Due(_ context.Context, now time.Time, limit int) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []Entry
+	for _, e := range m.entries {
+		if len(due) == limit {
+			break
+		}
+		if !e.next.After(now) {
+			e.next = now.Add(ClaimLease)
+			due = append(due, e.Entry)
+		}
+	}
+	return due, nil
+}
+
+// Delete removes a published entry.
+func (m *Memory) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = slices.DeleteFunc(m.entries, func(e *memoryEntry) bool {
+		return e.Event.ID == id
+	})
+	return nil
+}
+
+// Retry records a failed publish.
+func (m *Memory) Retry(_ context.Context, id, _ string, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.Event.ID == id {
+			e.Attempts++
+			e.next = next
+		}
+	}
+	return nil
+}
+
+// Release makes the entries due again at next.
+func (m *Memory) Release(_ context.Context, ids []string, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if slices.Contains(ids, e.Event.ID) {
+			e.next = next
+		}
+	}
+	return nil
+}
+
+// Len returns the number of pending events.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
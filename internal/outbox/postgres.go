@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres keeps pending events in the event_outbox table.
+type Postgres struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+// NewPostgres returns the default store, backed by pool.
+func NewPostgres(pool *pgxpool.Pool) *Postgres {
+	return &Postgres{pool: pool, queries: db.New(pool)}
+}
+
+// storedEvent is the payload column: the event plus its trace context,
+// which the event's own JSON leaves out.
+type storedEvent struct {
+	*domain.Event
+	Traceparent string `json:"traceparent,omitempty"`
+}
+
+// Enqueue inserts the event, due immediately.
+func (p *Postgres) Enqueue(ctx context.Context, event *domain.Event) error {
+	payload, err := json.Marshal(storedEvent{Event: event, Traceparent: event.Traceparent})
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	return p.queries.InsertOutboxEvent(ctx, db.InsertOutboxEventParams{
+		ID:      event.ID,
+		OrgID:   event.OrgID,
+		Payload: payload,
+	})
+}
+
+// Due claims the entries ready for another attempt, oldest first. The rows
+// are locked with SKIP LOCKED and leased in one transaction, so relays on
+// other instances neither block on them nor publish them again.
+func (p *Postgres) Due(ctx context.Context, now time.Time, limit int) ([]Entry, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := p.queries.WithTx(tx)
+	rows, err := q.GetDueOutboxEvents(ctx, db.GetDueOutboxEventsParams{
+		NextAttemptAt: pgtype.Timestamptz{Time: now, Valid: true},
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	if err := q.LeaseOutboxEvents(ctx, db.LeaseOutboxEventsParams{
+		NextAttemptAt: pgtype.Timestamptz{Time: now.Add(ClaimLease), Valid: true},
+		Ids:           ids,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		stored := storedEvent{Event: &domain.Event{}}
+		if err := json.Unmarshal(row.Payload, &stored); err != nil {
+			return nil, fmt.Errorf("unmarshal outbox event %s: %w", row.ID, err)
+		}
+		stored.Event.Traceparent = stored.Traceparent
+		entries = append(entries, Entry{Event: stored.Event, Attempts: int(row.Attempts)})
+	}
+	return entries, nil
+}
+
+// Delete removes a published entry.
+func (p *Postgres) Delete(ctx context.Context, id string) error {
+	return p.queries.DeleteOutboxEvent(ctx, id)
+}
+
+// Retry records a failed publish.
+func (p *Postgres) Retry(ctx context.Context, id, lastErr string, next time.Time) error {
+	return p.queries.RecordOutboxFailure(ctx, db.RecordOutboxFailureParams{
+		ID:            id,
+		LastError:     pgtype.Text{String: lastErr, Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: next, Valid: true},
+	})
+}
+
+// Release makes the entries due again at next.
+func (p *Postgres) Release(ctx context.Context, ids []string, next time.Time) error {
+	return p.queries.LeaseOutboxEvents(ctx, db.LeaseOutboxEventsParams{
+		NextAttemptAt: pgtype.Timestamptz{Time: next, Valid: true},
+		Ids:           ids,
+	})
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+const (
+	// DefaultInterval is how often the relay polls for due events when
+	// nothing wakes it sooner.
+	DefaultInterval = time.Second
+
+	// relayBatch caps the events published per pass.
+	relayBatch = 100
+
+	// minBackoff is the delay after the first failed publish; it doubles
+	// on each further failure up to maxBackoff.
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// PublishFunc publishes an event to JetStream.
+type PublishFunc func(ctx context.Context, event *domain.Event) error
+
+// Relay persists emitted events and publishes them in the background.
+// Every enqueued event is published at least once; JetStream deduplicates
+// republishes by event ID within its duplicate window.
+type Relay struct {
+	store    Store
+	publish  PublishFunc
+	interval time.Duration
+	wake     chan struct{}
+}
+
+// NewRelay creates a relay publishing store's events with publish.
+func NewRelay(store Store, publish PublishFunc, interval time.Duration) *Relay {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Relay{
+		store:    store,
+		publish:  publish,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Enqueue persists event for publishing. Once it returns nil, the event
+// survives NATS being unavailable.
+func (r *Relay) Enqueue(ctx context.Context, event *domain.Event) error {
+	if err := r.store.Enqueue(ctx, event); err != nil {
+		return err
+	}
+	// Publish right away rather than at the next tick
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start runs the relay until the context is cancelled.
+func (r *Relay) Start(ctx context.Context) {
+	slog.Info("outbox relay started", "interval", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.relayDue(ctx, time.Now())
+		select {
+		case <-ticker.C:
+		case <-r.wake:
+		case <-ctx.Done():
+			slog.Info("outbox relay stopped")
+			return
+		}
+	}
+}
+
+// relayDue publishes the events due at now and returns how many went out.
+// A failed publish is rescheduled with backoff and ends the pass: NATS is
+// most likely down for the rest as well.
+func (r *Relay) relayDue(ctx context.Context, now time.Time) int {
+	entries, err := r.store.Due(ctx, now, relayBatch)
+	if err != nil {
+		slog.Error("failed to get due outbox events", "error", err)
+		return 0
+	}
+
+	published := 0
+	for i, entry := range entries {
+		if err := r.publish(ctx, entry.Event); err != nil {
+			attempts := entry.Attempts + 1
+			slog.Warn("outbox publish failed", "event_id", entry.Event.ID, "attempts", attempts, "error", err)
+			if err := r.store.Retry(ctx, entry.Event.ID, err.Error(), now.Add(backoff(attempts))); err != nil {
+				slog.Error("failed to reschedule outbox event", "error", err, "event_id", entry.Event.ID)
+			}
+			r.release(ctx, entries[i+1:], now)
+			break
+		}
+		published++
+		// If this fails the event is published again later, and dropped
+		// as a duplicate by JetStream
+		if err := r.store.Delete(ctx, entry.Event.ID); err != nil {
+			slog.Error("failed to delete outbox event", "error", err, "event_id", entry.Event.ID)
+		}
+	}
+	return published
+}
+
+// release hands the claimed entries a pass did not attempt back to the
+// store, so they don't wait out the claim lease.
+func (r *Relay) release(ctx context.Context, entries []Entry, now time.Time) {
+	if len(entries) == 0 {
+		return
+	}
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.Event.ID
+	}
+	if err := r.store.Release(ctx, ids, now); err != nil {
+		slog.Error("failed to release outbox events", "error", err, "count", len(ids))
+	}
+}
+
+// backoff returns the delay after the given number of failed publishes.
+func backoff(attempts int) time.Duration {
+	d := minBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
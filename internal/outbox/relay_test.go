@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/nats"
+)
+
+func startEmbedded(t *testing.T, storeDir string, port int) *nats.EmbeddedServer {
+	t.Helper()
+	srv, err := nats.StartEmbedded(nats.EmbeddedConfig{StoreDir: storeDir, Port: port})
+	if err != nil {
+		t.Fatalf("start embedded: %v", err)
+	}
+	return srv
+}
+
+func testEvent() *domain.Event {
+	event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+	event.OrgID, event.ProjectID = "org_test", "prj_test"
+	return event
+}
+
+func TestRelay_PersistsWhileNATSDownAndRelaysAfter(t *testing.T) {
+	storeDir := t.TempDir()
+	srv := startEmbedded(t, storeDir, -1)
+	u, _ := url.Parse(srv.ClientURL())
+	port, _ := strconv.Atoi(u.Port())
+
+	nc, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+	if err := nc.EnsureStreams(context.Background()); err != nil {
+		t.Fatalf("ensure streams: %v", err)
+	}
+
+	publisher := nats.NewPublisher(nc.JetStream())
+	store := NewMemory()
+	relay := NewRelay(store, func(ctx context.Context, event *domain.Event) error {
+		ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer cancel()
+		return publisher.Publish(ctx, event)
+	}, time.Second)
+
+	srv.Shutdown()
+
+	// Emits succeed while NATS is down: the events are only persisted
+	ctx := context.Background()
+	for range 3 {
+		if err := relay.Enqueue(ctx, testEvent()); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	if n := relay.relayDue(ctx, time.Now()); n != 0 {
+		t.Fatalf("published %d events with NATS down", n)
+	}
+	if store.Len() != 3 {
+		t.Fatalf("expected 3 pending events, got %d", store.Len())
+	}
+	if due, _ := store.Due(ctx, time.Now(), relayBatch); len(due) != 2 {
+		t.Errorf("expected the failed event to back off, %d still due", len(due))
+	}
+
+	srv = startEmbedded(t, storeDir, port)
+	t.Cleanup(srv.Shutdown)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for store.Len() > 0 && time.Now().Before(deadline) {
+		relay.relayDue(ctx, time.Now().Add(ClaimLease))
+		time.Sleep(100 * time.Millisecond)
+	}
+	if store.Len() != 0 {
+		t.Fatalf("expected every event relayed once NATS returned, %d pending", store.Len())
+	}
+
+	info, err := nc.Stream().Info(ctx)
+	if err != nil {
+		t.Fatalf("stream info: %v", err)
+	}
+	if info.State.Msgs != 3 {
+		t.Errorf("expected 3 events in the stream, got %d", info.State.Msgs)
+	}
+}
+
+func TestMemory_DueClaimsEntries(t *testing.T) {
+	store := NewMemory()
+	ctx := context.Background()
+	for range 3 {
+		store.Enqueue(ctx, testEvent())
+	}
+
+	now := time.Now()
+	if due, _ := store.Due(ctx, now, 2); len(due) != 2 {
+		t.Fatalf("expected 2 claimed, got %d", len(due))
+	}
+	if due, _ := store.Due(ctx, now, relayBatch); len(due) != 1 {
+		t.Errorf("expected claimed entries skipped, got %d due", len(due))
+	}
+	if due, _ := store.Due(ctx, now.Add(ClaimLease), relayBatch); len(due) != 3 {
+		t.Errorf("expected every entry due once the lease passed, got %d", len(due))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{7, time.Minute},
+		{50, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
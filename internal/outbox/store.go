@@ -0,0 +1,39 @@
+// Package outbox makes emits durable across NATS outages. Events are
+// persisted to a store before the emit returns, and a relay publishes them
+// to JetStream in the background, retrying until the publish succeeds.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+)
+
+// ClaimLease is how long an entry returned by Due stays hidden from other
+// relays. An entry whose relay dies mid-pass is picked up again after it.
+const ClaimLease = time.Minute
+
+// Entry is an event waiting to be published.
+type Entry struct {
+	Event *domain.Event
+	// Attempts counts the failed publishes so far.
+	Attempts int
+}
+
+// Store is a pluggable backend for pending events.
+type Store interface {
+	// Enqueue persists an event for publishing.
+	Enqueue(ctx context.Context, event *domain.Event) error
+	// Due claims up to limit entries whose next attempt is at or before
+	// now, oldest first. Claimed entries are not due again until ClaimLease
+	// has passed, unless Retry reschedules them first.
+	Due(ctx context.Context, now time.Time, limit int) ([]Entry, error)
+	// Delete removes a published entry.
+	Delete(ctx context.Context, id string) error
+	// Retry records a failed publish and when to try the entry again.
+	Retry(ctx context.Context, id, lastErr string, next time.Time) error
+	// Release ends the claim on entries that were not attempted, making
+	// them due again at next.
+	Release(ctx context.Context, ids []string, next time.Time) error
+}
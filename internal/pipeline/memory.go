@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Memory keeps pipelines in process. It is meant for tests and single-node
+// development; nothing survives a restart.
+type Memory struct {
+	mu        sync.Mutex
+	pipelines []*Pipeline
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// List returns a project's pipelines ordered by position, then name.
+func (m *Memory) List(_ context.Context, projectID string) ([]*Pipeline, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*Pipeline
+	for _, p := range m.pipelines {
+		if p.ProjectID == projectID {
+			c := *p
+			out = append(out, &c)
+		}
+	}
+	slices.SortFunc(out, func(a, b *Pipeline) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.Name, b.Name))
+	})
+	return out, nil
+}
+
+// Get returns a project's pipeline by name.
+func (m *Memory) Get(_ context.Context, projectID, name string) (*Pipeline, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.find(projectID, name); i >= 0 {
+		c := *m.pipelines[i]
+		return &c, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Create adds a new pipeline.
+func (m *Memory) Create(_ context.Context, p *Pipeline) (*Pipeline, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.find(p.ProjectID, p.Name) >= 0 {
+		return nil, ErrExists
+	}
+	c := *p
+	c.ID = generateID()
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+	m.pipelines = append(m.pipelines, &c)
+	out := c
+	return &out, nil
+}
+
+// Update stores changes to the project's pipeline named p.Name.
+func (m *Memory) Update(_ context.Context, p *Pipeline) (*Pipeline, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.find(p.ProjectID, p.Name)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	stored := m.pipelines[i]
+	stored.TopicPattern = p.TopicPattern
+	stored.Redact = p.Redact
+	stored.Jq = p.Jq
+	stored.Position = p.Position
+	stored.Enabled = p.Enabled
+	stored.UpdatedAt = time.Now()
+	out := *stored
+	return &out, nil
+}
+
+// Delete removes a project's pipeline by name.
+func (m *Memory) Delete(_ context.Context, projectID, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.find(projectID, name)
+	if i < 0 {
+		return ErrNotFound
+	}
+	m.pipelines = slices.Delete(m.pipelines, i, i+1)
+	return nil
+}
+
+func (m *Memory) find(projectID, name string) int {
+	return slices.IndexFunc(m.pipelines, func(p *Pipeline) bool {
+		return p.ProjectID == projectID && p.Name == name
+	})
+}
@@ -0,0 +1,318 @@
+// Package pipeline transforms events at emit. A project's pipelines are
+// named, managed through the API and applied in position order to each
+// matching event before it is stored and delivered, e.g. to always redact
+// a field.
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	notifnats "github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/schema"
+	"github.com/itchyny/gojq"
+)
+
+// Redacted replaces the value of every redacted field.
+const Redacted = "[redacted]"
+
+// DefaultTimeout bounds a pipeline's jq transform so a pathological
+// expression cannot stall an emit.
+const DefaultTimeout = time.Second
+
+// DefaultCacheTTL bounds how long a Registry serves a project's cached
+// pipelines. Changes made through a Registry drop its own cache at once;
+// the TTL is how long other server instances may keep applying the old ones.
+const DefaultCacheTTL = 30 * time.Second
+
+// Pipeline is a named transformation of a project's events. Redact runs
+// first, then Jq, whose single output replaces the event data.
+type Pipeline struct {
+	ID        string `json:"id"`
+	OrgID     string `json:"org_id"`
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	// TopicPattern selects the events the pipeline applies to, with the
+	// same wildcards as subscriptions.
+	TopicPattern string `json:"topic_pattern"`
+	// Redact lists dotted paths into the data, e.g. "card.number".
+	Redact    []string  `json:"redact"`
+	Jq        string    `json:"jq,omitempty"`
+	Position  int       `json:"position"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that p has a name, a legal topic pattern and at least one
+// step, and that its jq expression compiles.
+func (p *Pipeline) Validate() error {
+	_, err := compile(p)
+	return err
+}
+
+// TransformError is an event a pipeline could not transform.
+type TransformError struct {
+	Pipeline string
+	Err      error
+}
+
+func (e *TransformError) Error() string {
+	return fmt.Sprintf("pipeline %q: %v", e.Pipeline, e.Err)
+}
+
+func (e *TransformError) Unwrap() error {
+	return e.Err
+}
+
+// compiled is a pipeline ready to apply.
+type compiled struct {
+	*Pipeline
+	redact [][]string
+	jq     *gojq.Code
+}
+
+func compile(p *Pipeline) (*compiled, error) {
+	switch {
+	case p.Name == "":
+		return nil, fmt.Errorf("name is required")
+	case len(p.Name) > 255 || strings.ContainsAny(p.Name, " \t\r\n/"):
+		return nil, fmt.Errorf("name must be at most 255 characters, without whitespace or slashes")
+	case p.TopicPattern == "":
+		return nil, fmt.Errorf("topic_pattern is required")
+	case len(p.Redact) == 0 && p.Jq == "":
+		return nil, fmt.Errorf("redact or jq is required")
+	}
+	if err := notifnats.ValidateSubject(p.TopicPattern, true); err != nil {
+		return nil, fmt.Errorf("topic_pattern: %v", err)
+	}
+
+	c := &compiled{Pipeline: p}
+	for _, path := range p.Redact {
+		parts := strings.Split(path, ".")
+		for _, part := range parts {
+			if part == "" {
+				return nil, fmt.Errorf("invalid redact path %q", path)
+			}
+		}
+		c.redact = append(c.redact, parts)
+	}
+	if p.Jq != "" {
+		query, err := gojq.Parse(p.Jq)
+		if err != nil {
+			return nil, fmt.Errorf("parse jq expression: %w", err)
+		}
+		if c.jq, err = gojq.Compile(query); err != nil {
+			return nil, fmt.Errorf("compile jq expression: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// apply runs the pipeline on data. Data a redact-only pipeline leaves
+// alone is returned as is.
+func (c *compiled) apply(ctx context.Context, data json.RawMessage, timeout time.Duration) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if c.jq == nil {
+		dec.UseNumber() // gojq only takes float64 numbers
+	}
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode data: %w", err)
+	}
+
+	changed := false
+	for _, path := range c.redact {
+		changed = redact(v, path) || changed
+	}
+
+	if c.jq != nil {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		out, ok := c.jq.RunWithContext(ctx, v).Next()
+		if !ok {
+			return nil, fmt.Errorf("jq produced no output")
+		}
+		if err, isErr := out.(error); isErr {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("transform exceeded timeout of %s", timeout)
+			}
+			return nil, err
+		}
+		v, changed = out, true
+	}
+	if !changed {
+		return data, nil
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode data: %w", err)
+	}
+	return out, nil
+}
+
+// redact replaces the value at path in v and reports whether there was one.
+func redact(v any, path []string) bool {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		if _, ok := obj[path[0]]; !ok {
+			return false
+		}
+		obj[path[0]] = Redacted
+		return true
+	}
+	return redact(obj[path[0]], path[1:])
+}
+
+// Registry manages a project's pipelines and applies them at emit. It
+// caches each project's compiled pipelines until they change, or for the
+// cache TTL when they are changed elsewhere.
+type Registry struct {
+	store    Store
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	cache sync.Map // map[projectID]*cachedPipelines
+}
+
+// cachedPipelines are a project's compiled pipelines and when they were
+// loaded.
+type cachedPipelines struct {
+	pipelines []*compiled
+	loadedAt  time.Time
+}
+
+// NewRegistry creates a Registry backed by store.
+func NewRegistry(store Store) *Registry {
+	return &Registry{store: store, timeout: DefaultTimeout, cacheTTL: DefaultCacheTTL}
+}
+
+// SetTimeout sets the per-pipeline jq transform timeout.
+func (r *Registry) SetTimeout(d time.Duration) {
+	r.timeout = d
+}
+
+// SetCacheTTL sets how long a project's pipelines are cached before they
+// are reloaded from the store.
+func (r *Registry) SetCacheTTL(d time.Duration) {
+	r.cacheTTL = d
+}
+
+// List returns a project's pipelines in the order they apply.
+func (r *Registry) List(ctx context.Context, projectID string) ([]*Pipeline, error) {
+	return r.store.List(ctx, projectID)
+}
+
+// Get returns a project's pipeline by name, or ErrNotFound.
+func (r *Registry) Get(ctx context.Context, projectID, name string) (*Pipeline, error) {
+	return r.store.Get(ctx, projectID, name)
+}
+
+// Create validates and stores a new pipeline. A negative position places
+// it after the project's existing pipelines.
+func (r *Registry) Create(ctx context.Context, p *Pipeline) (*Pipeline, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if p.Position < 0 {
+		existing, err := r.store.List(ctx, p.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		p.Position = 0
+		for _, e := range existing {
+			p.Position = max(p.Position, e.Position+1)
+		}
+	}
+	created, err := r.store.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Delete(p.ProjectID)
+	return created, nil
+}
+
+// Update validates and stores changes to an existing pipeline.
+func (r *Registry) Update(ctx context.Context, p *Pipeline) (*Pipeline, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	updated, err := r.store.Update(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Delete(p.ProjectID)
+	return updated, nil
+}
+
+// Delete removes a project's pipeline by name.
+func (r *Registry) Delete(ctx context.Context, projectID, name string) error {
+	if err := r.store.Delete(ctx, projectID, name); err != nil {
+		return err
+	}
+	r.cache.Delete(projectID)
+	return nil
+}
+
+// Apply runs the project's enabled pipelines that match topic on data, in
+// order, and returns the transformed data with the names of the pipelines
+// applied. A pipeline that fails returns a *TransformError; the event must
+// not be published untransformed.
+func (r *Registry) Apply(ctx context.Context, projectID, topic string, data json.RawMessage) (json.RawMessage, []string, error) {
+	pipelines, err := r.compiled(ctx, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var applied []string
+	for _, c := range pipelines {
+		if !c.Enabled || !schema.MatchTopic(c.TopicPattern, topic) {
+			continue
+		}
+		out, err := c.apply(ctx, data, r.timeout)
+		if err != nil {
+			return nil, nil, &TransformError{Pipeline: c.Name, Err: err}
+		}
+		data = out
+		applied = append(applied, c.Name)
+	}
+	return data, applied, nil
+}
+
+// compiled returns the project's pipelines, compiled, from the cache.
+func (r *Registry) compiled(ctx context.Context, projectID string) ([]*compiled, error) {
+	if cached, ok := r.cache.Load(projectID); ok {
+		if c := cached.(*cachedPipelines); time.Since(c.loadedAt) < r.cacheTTL {
+			return c.pipelines, nil
+		}
+	}
+
+	pipelines, err := r.store.List(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("list pipelines: %w", err)
+	}
+	out := make([]*compiled, 0, len(pipelines))
+	for _, p := range pipelines {
+		c, err := compile(p)
+		if err != nil {
+			// Stored pipelines were validated; only a changed rule gets here
+			return nil, &TransformError{Pipeline: p.Name, Err: err}
+		}
+		out = append(out, c)
+	}
+	r.cache.Store(projectID, &cachedPipelines{pipelines: out, loadedAt: time.Now()})
+	return out, nil
+}
@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(t *testing.T, pipelines ...*Pipeline) *Registry {
+	t.Helper()
+	r := NewRegistry(NewMemory())
+	for _, p := range pipelines {
+		p.ProjectID = "prj_1"
+		if p.TopicPattern == "" {
+			p.TopicPattern = ">"
+		}
+		if _, err := r.Create(context.Background(), p); err != nil {
+			t.Fatalf("create %s: %v", p.Name, err)
+		}
+	}
+	return r
+}
+
+func TestRegistry_Apply(t *testing.T) {
+	r := newTestRegistry(t,
+		&Pipeline{Name: "tag", Jq: `.tagged = (.card.number == "[redacted]")`, Position: 2, Enabled: true},
+		&Pipeline{Name: "redact-card", Redact: []string{"card.number", "missing.field"}, Position: 1, Enabled: true},
+		&Pipeline{Name: "orders-only", TopicPattern: "orders.*", Jq: `.order = true`, Position: 3, Enabled: true},
+		&Pipeline{Name: "disabled", Jq: `.disabled = true`, Position: 4},
+	)
+
+	out, applied, err := r.Apply(context.Background(), "prj_1", "users.signup", []byte(`{"card":{"number":"4242","exp":"12/30"}}`))
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if want := `{"card":{"exp":"12/30","number":"[redacted]"},"tagged":true}`; string(out) != want {
+		t.Errorf("data = %s, want %s", out, want)
+	}
+	if got := strings.Join(applied, ","); got != "redact-card,tag" {
+		t.Errorf("applied = %s, want redact-card,tag in position order", got)
+	}
+
+	out, _, err = r.Apply(context.Background(), "prj_1", "orders.created", []byte(`{}`))
+	if err != nil || string(out) != `{"order":true,"tagged":false}` {
+		t.Errorf("orders.created = %s, %v", out, err)
+	}
+
+	// Other projects are untouched
+	out, applied, err = r.Apply(context.Background(), "prj_2", "users.signup", []byte(`{"card":{"number":"4242"}}`))
+	if err != nil || len(applied) != 0 || string(out) != `{"card":{"number":"4242"}}` {
+		t.Errorf("other project = %s, %v, %v", out, applied, err)
+	}
+}
+
+func TestRegistry_ApplyKeepsUntouchedData(t *testing.T) {
+	r := newTestRegistry(t, &Pipeline{Name: "redact", Redact: []string{"secret"}, Enabled: true})
+
+	in := []byte(`{"z": 1, "a": 12345678901234567890}`)
+	out, _, err := r.Apply(context.Background(), "prj_1", "orders.created", in)
+	if err != nil || string(out) != string(in) {
+		t.Errorf("data = %s, %v; want it unchanged", out, err)
+	}
+
+	out, _, err = r.Apply(context.Background(), "prj_1", "orders.created", []byte(`{"secret":"x","n":12345678901234567890}`))
+	if err != nil || string(out) != `{"n":12345678901234567890,"secret":"[redacted]"}` {
+		t.Errorf("data = %s, %v; want numbers kept exactly", out, err)
+	}
+}
+
+func TestRegistry_ApplyTransformError(t *testing.T) {
+	r := newTestRegistry(t, &Pipeline{Name: "drop", Jq: `select(.keep)`, Enabled: true})
+
+	_, _, err := r.Apply(context.Background(), "prj_1", "orders.created", []byte(`{"keep":false}`))
+	var terr *TransformError
+	if !errors.As(err, &terr) || terr.Pipeline != "drop" {
+		t.Fatalf("err = %v, want a TransformError from drop", err)
+	}
+}
+
+func TestRegistry_ChangesInvalidateCache(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRegistry(t, &Pipeline{Name: "redact", Redact: []string{"a"}, Enabled: true})
+	if out, _, _ := r.Apply(ctx, "prj_1", "t", []byte(`{"a":1,"b":2}`)); string(out) != `{"a":"[redacted]","b":2}` {
+		t.Fatalf("data = %s", out)
+	}
+
+	p, err := r.Get(ctx, "prj_1", "redact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Redact = []string{"b"}
+	if _, err := r.Update(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if out, _, _ := r.Apply(ctx, "prj_1", "t", []byte(`{"a":1,"b":2}`)); string(out) != `{"a":1,"b":"[redacted]"}` {
+		t.Errorf("after update = %s", out)
+	}
+
+	if err := r.Delete(ctx, "prj_1", "redact"); err != nil {
+		t.Fatal(err)
+	}
+	if out, _, _ := r.Apply(ctx, "prj_1", "t", []byte(`{"a":1,"b":2}`)); string(out) != `{"a":1,"b":2}` {
+		t.Errorf("after delete = %s", out)
+	}
+}
+
+func TestRegistry_CacheExpiresChangesFromElsewhere(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory()
+	r := NewRegistry(store)
+	r.SetCacheTTL(20 * time.Millisecond)
+	if out, _, _ := r.Apply(ctx, "prj_1", "t", []byte(`{"a":1}`)); string(out) != `{"a":1}` {
+		t.Fatalf("data = %s", out)
+	}
+
+	// Another instance's registry shares the store, not the cache
+	other := NewRegistry(store)
+	if _, err := other.Create(ctx, &Pipeline{ProjectID: "prj_1", Name: "redact", TopicPattern: ">", Redact: []string{"a"}, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if out, _, _ := r.Apply(ctx, "prj_1", "t", []byte(`{"a":1}`)); string(out) != `{"a":1}` {
+		t.Fatalf("within TTL = %s, want cached pipelines", out)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if out, _, _ := r.Apply(ctx, "prj_1", "t", []byte(`{"a":1}`)); string(out) != `{"a":"[redacted]"}` {
+		t.Errorf("after TTL = %s", out)
+	}
+}
+
+func TestRegistry_CreateAppendsAndRejectsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRegistry(t, &Pipeline{Name: "first", Redact: []string{"a"}, Position: 5, Enabled: true})
+
+	p, err := r.Create(ctx, &Pipeline{ProjectID: "prj_1", Name: "second", TopicPattern: ">", Redact: []string{"b"}, Position: -1})
+	if err != nil || p.Position != 6 {
+		t.Fatalf("position = %v, %v; want 6", p, err)
+	}
+	if _, err := r.Create(ctx, &Pipeline{ProjectID: "prj_1", Name: "first", TopicPattern: ">", Redact: []string{"a"}}); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate create = %v, want ErrExists", err)
+	}
+}
+
+func TestPipeline_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Pipeline
+		ok   bool
+	}{
+		{"redact", Pipeline{Name: "p", TopicPattern: "orders.>", Redact: []string{"card.number"}}, true},
+		{"jq", Pipeline{Name: "p", TopicPattern: "*", Jq: "del(.secret)"}, true},
+		{"no name", Pipeline{TopicPattern: ">", Jq: "."}, false},
+		{"name with slash", Pipeline{Name: "a/b", TopicPattern: ">", Jq: "."}, false},
+		{"no steps", Pipeline{Name: "p", TopicPattern: ">"}, false},
+		{"bad pattern", Pipeline{Name: "p", TopicPattern: "orders..x", Jq: "."}, false},
+		{"bad redact path", Pipeline{Name: "p", TopicPattern: ">", Redact: []string{"card."}}, false},
+		{"bad jq", Pipeline{Name: "p", TopicPattern: ">", Jq: ".["}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok=%v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Postgres keeps pipelines in the pipelines table.
+type Postgres struct {
+	queries *db.Queries
+}
+
+// NewPostgres returns the default store, backed by queries.
+func NewPostgres(queries *db.Queries) *Postgres {
+	return &Postgres{queries: queries}
+}
+
+// List returns a project's pipelines ordered by position, then name.
+func (s *Postgres) List(ctx context.Context, projectID string) ([]*Pipeline, error) {
+	rows, err := s.queries.ListPipelines(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	pipelines := make([]*Pipeline, len(rows))
+	for i, row := range rows {
+		pipelines[i] = fromDB(row)
+	}
+	return pipelines, nil
+}
+
+// Get returns a project's pipeline by name.
+func (s *Postgres) Get(ctx context.Context, projectID, name string) (*Pipeline, error) {
+	row, err := s.queries.GetPipelineByName(ctx, db.GetPipelineByNameParams{ProjectID: projectID, Name: name})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromDB(row), nil
+}
+
+// Create inserts a new pipeline.
+func (s *Postgres) Create(ctx context.Context, p *Pipeline) (*Pipeline, error) {
+	row, err := s.queries.CreatePipeline(ctx, db.CreatePipelineParams{
+		ID:           generateID(),
+		OrgID:        p.OrgID,
+		ProjectID:    p.ProjectID,
+		Name:         p.Name,
+		TopicPattern: p.TopicPattern,
+		Redact:       nonNil(p.Redact),
+		Jq:           p.Jq,
+		Position:     int32(p.Position),
+		Enabled:      p.Enabled,
+	})
+	if pgErr := (*pgconn.PgError)(nil); errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromDB(row), nil
+}
+
+// Update stores changes to the project's pipeline named p.Name.
+func (s *Postgres) Update(ctx context.Context, p *Pipeline) (*Pipeline, error) {
+	existing, err := s.Get(ctx, p.ProjectID, p.Name)
+	if err != nil {
+		return nil, err
+	}
+	row, err := s.queries.UpdatePipeline(ctx, db.UpdatePipelineParams{
+		ID:           existing.ID,
+		TopicPattern: p.TopicPattern,
+		Redact:       nonNil(p.Redact),
+		Jq:           p.Jq,
+		Position:     int32(p.Position),
+		Enabled:      p.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromDB(row), nil
+}
+
+// Delete removes a project's pipeline by name.
+func (s *Postgres) Delete(ctx context.Context, projectID, name string) error {
+	existing, err := s.Get(ctx, projectID, name)
+	if err != nil {
+		return err
+	}
+	return s.queries.DeletePipeline(ctx, existing.ID)
+}
+
+func fromDB(row db.Pipeline) *Pipeline {
+	return &Pipeline{
+		ID:           row.ID,
+		OrgID:        row.OrgID,
+		ProjectID:    row.ProjectID,
+		Name:         row.Name,
+		TopicPattern: row.TopicPattern,
+		Redact:       row.Redact,
+		Jq:           row.Jq,
+		Position:     int(row.Position),
+		Enabled:      row.Enabled,
+		CreatedAt:    timestamp(row.CreatedAt),
+		UpdatedAt:    timestamp(row.UpdatedAt),
+	}
+}
+
+func timestamp(ts pgtype.Timestamptz) time.Time {
+	if !ts.Valid {
+		return time.Time{}
+	}
+	return ts.Time
+}
+
+// nonNil keeps a nil list out of the NOT NULL redact column.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned for a pipeline name the project doesn't have.
+	ErrNotFound = errors.New("pipeline not found")
+	// ErrExists is returned when creating a pipeline under a taken name.
+	ErrExists = errors.New("pipeline already exists")
+)
+
+// Store is a pluggable backend for pipelines.
+type Store interface {
+	// List returns a project's pipelines ordered by position, then name.
+	List(ctx context.Context, projectID string) ([]*Pipeline, error)
+	// Get returns a project's pipeline by name, or ErrNotFound.
+	Get(ctx context.Context, projectID, name string) (*Pipeline, error)
+	// Create stores a new pipeline, or returns ErrExists.
+	Create(ctx context.Context, p *Pipeline) (*Pipeline, error)
+	// Update stores the topic pattern, steps, position and enabled flag of
+	// the project's pipeline named p.Name, or returns ErrNotFound.
+	Update(ctx context.Context, p *Pipeline) (*Pipeline, error)
+	// Delete removes a project's pipeline by name, or returns ErrNotFound.
+	Delete(ctx context.Context, projectID, name string) error
+}
+
+func generateID() string {
+	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = charset[int(b[i])%len(charset)]
+	}
+	return "ppl_" + string(b)
+}
@@ -3,31 +3,62 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/filipexyz/notif/internal/db"
 	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/metrics"
 	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/pipeline"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const (
+	// DefaultMaxAttempts is how many times a schedule fires before it is
+	// marked failed, unless the schedule sets its own max_attempts.
+	DefaultMaxAttempts = 3
+	// MaxAttemptsLimit caps the per-schedule max_attempts.
+	MaxAttemptsLimit = 10
+
+	// DefaultRetryBackoff is the delay before the first retry; it doubles on
+	// each further attempt up to maxRetryBackoff.
+	DefaultRetryBackoff = 30 * time.Second
+	maxRetryBackoff     = time.Hour
+)
+
 // Worker polls for pending scheduled events and publishes them.
 type Worker struct {
-	queries   *db.Queries
-	publisher *nats.Publisher
-	interval  time.Duration
+	queries      *db.Queries
+	publish      func(ctx context.Context, event *domain.Event) error
+	pipelines    *pipeline.Registry
+	interval     time.Duration
+	retryBackoff time.Duration
 }
 
 // NewWorker creates a new scheduler worker.
 func NewWorker(queries *db.Queries, publisher *nats.Publisher, interval time.Duration) *Worker {
 	return &Worker{
-		queries:   queries,
-		publisher: publisher,
-		interval:  interval,
+		queries:      queries,
+		publish:      publisher.Publish,
+		interval:     interval,
+		retryBackoff: DefaultRetryBackoff,
 	}
 }
 
+// SetRetryBackoff sets the delay before the first retry of a failed fire.
+func (w *Worker) SetRetryBackoff(d time.Duration) {
+	w.retryBackoff = d
+}
+
+// SetPipelines applies the project's transformation pipelines to each
+// scheduled event when it fires, as at emit.
+func (w *Worker) SetPipelines(pipelines *pipeline.Registry) {
+	w.pipelines = pipelines
+}
+
 // Start runs the scheduler worker until the context is cancelled.
 func (w *Worker) Start(ctx context.Context) {
 	slog.Info("scheduler worker started", "interval", w.interval)
@@ -67,32 +98,137 @@ func (w *Worker) processPending(ctx context.Context) {
 	}
 }
 
-func (w *Worker) executeScheduled(ctx context.Context, sch db.ScheduledEvent) {
-	// Create event from scheduled event
+// fireResult is the state a schedule moves to after one execution attempt.
+type fireResult struct {
+	eventID string
+	err     error
+	// params is the row update to persist for this attempt.
+	params db.RecordScheduledEventAttemptParams
+}
+
+// fire publishes the scheduled event once and applies the retry policy:
+// a failed publish is rescheduled with exponential backoff until the
+// schedule's max_attempts is reached, then the schedule is marked failed.
+// Recurring schedules are re-armed for their next cron occurrence instead
+// of completing or failing.
+func (w *Worker) fire(ctx context.Context, sch db.ScheduledEvent, now time.Time) fireResult {
 	event := domain.NewEvent(sch.Topic, json.RawMessage(sch.Data))
 	event.OrgID = sch.OrgID
+	event.ProjectID = sch.ProjectID.String
+
+	attempts := sch.Attempts + 1
+	res := fireResult{
+		params: db.RecordScheduledEventAttemptParams{
+			ID:           sch.ID,
+			Attempts:     attempts,
+			ScheduledFor: sch.ScheduledFor,
+			Occurrences:  sch.Occurrences,
+			LastRunAt:    sch.LastRunAt,
+		},
+	}
+
+	if err := w.send(ctx, event); err != nil {
+		metrics.ScheduleExecutions.WithLabelValues("failed").Inc()
+		res.err = err
+		res.params.Error = pgtype.Text{String: err.Error(), Valid: true}
+
+		maxAttempts := sch.MaxAttempts
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+		if attempts < maxAttempts {
+			res.params.Status = "pending"
+			res.params.ScheduledFor = pgtype.Timestamptz{Time: now.Add(w.backoff(attempts)), Valid: true}
+		} else if next := nextOccurrence(sch, now); !next.IsZero() {
+			// A recurring schedule skips the occurrence it could not deliver
+			res.params.Status = "pending"
+			res.params.Attempts = 0
+			res.params.ScheduledFor = pgtype.Timestamptz{Time: next, Valid: true}
+		} else {
+			res.params.Status = "failed"
+		}
+		return res
+	}
+
+	metrics.ScheduleExecutions.WithLabelValues("success").Inc()
+	res.eventID = event.ID
+	res.params.Status = "completed"
+	if sch.Cron.Valid {
+		res.params.Occurrences++
+		res.params.LastRunAt = pgtype.Timestamptz{Time: now, Valid: true}
+		capped := sch.MaxOccurrences > 0 && res.params.Occurrences >= sch.MaxOccurrences
+		if next := nextOccurrence(sch, now); !capped && !next.IsZero() {
+			res.params.Status = "pending"
+			res.params.Attempts = 0
+			res.params.ScheduledFor = pgtype.Timestamptz{Time: next, Valid: true}
+		}
+	}
+	return res
+}
+
+// send applies the project's pipelines to event and publishes it. The
+// schedule keeps its data untransformed, so every fire applies the
+// pipelines in effect at that time; a failing pipeline fails the attempt.
+func (w *Worker) send(ctx context.Context, event *domain.Event) error {
+	if w.pipelines != nil && event.ProjectID != "" {
+		data, applied, err := w.pipelines.Apply(ctx, event.ProjectID, event.Topic, event.Data)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			slog.Debug("pipelines applied", "topic", event.Topic, "pipelines", applied)
+		}
+		event.Data = data
+	}
+	return w.publish(ctx, event)
+}
+
+// nextOccurrence returns when a recurring schedule fires next after now,
+// or the zero time for one-shot schedules and exhausted expressions.
+func nextOccurrence(sch db.ScheduledEvent, now time.Time) time.Time {
+	if !sch.Cron.Valid {
+		return time.Time{}
+	}
+	c, err := ParseCron(sch.Cron.String)
+	if err != nil {
+		slog.Error("invalid cron on scheduled event", "scheduled_id", sch.ID, "cron", sch.Cron.String, "error", err)
+		return time.Time{}
+	}
+	return c.Next(now)
+}
+
+// backoff returns the delay before the retry following the given attempt.
+func (w *Worker) backoff(attempt int32) time.Duration {
+	d := w.retryBackoff
+	for i := int32(1); i < attempt && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryBackoff)
+}
 
-	// Publish to NATS
-	if err := w.publisher.Publish(ctx, event); err != nil {
+func (w *Worker) executeScheduled(ctx context.Context, sch db.ScheduledEvent) {
+	res := w.fire(ctx, sch, time.Now())
+
+	switch res.params.Status {
+	case "pending":
+		slog.Warn("failed to publish scheduled event, will retry",
+			"scheduled_id", sch.ID,
+			"topic", sch.Topic,
+			"attempt", res.params.Attempts,
+			"max_attempts", sch.MaxAttempts,
+			"next_attempt", res.params.ScheduledFor.Time,
+			"error", res.err,
+		)
+	case "failed":
 		slog.Error("failed to publish scheduled event",
 			"scheduled_id", sch.ID,
 			"topic", sch.Topic,
-			"error", err,
+			"attempts", res.params.Attempts,
+			"error", res.err,
 		)
-		w.queries.UpdateScheduledEventStatus(ctx, db.UpdateScheduledEventStatusParams{
-			ID:     sch.ID,
-			Status: "failed",
-			Error:  pgtype.Text{String: err.Error(), Valid: true},
-		})
-		return
 	}
 
-	// Mark as completed
-	if err := w.queries.UpdateScheduledEventStatus(ctx, db.UpdateScheduledEventStatusParams{
-		ID:     sch.ID,
-		Status: "completed",
-		Error:  pgtype.Text{Valid: false},
-	}); err != nil {
+	if err := w.queries.RecordScheduledEventAttempt(ctx, res.params); err != nil {
 		slog.Error("failed to update scheduled event status",
 			"scheduled_id", sch.ID,
 			"error", err,
@@ -100,11 +236,19 @@ func (w *Worker) executeScheduled(ctx context.Context, sch db.ScheduledEvent) {
 		return
 	}
 
-	slog.Info("scheduled event executed",
-		"scheduled_id", sch.ID,
-		"event_id", event.ID,
-		"topic", sch.Topic,
-	)
+	if res.err == nil {
+		slog.Info("scheduled event executed",
+			"scheduled_id", sch.ID,
+			"event_id", res.eventID,
+			"topic", sch.Topic,
+		)
+		if res.params.Status == "pending" {
+			slog.Debug("recurring schedule re-armed",
+				"scheduled_id", sch.ID,
+				"next_run", res.params.ScheduledFor.Time,
+			)
+		}
+	}
 }
 
 // ExecuteNow executes a scheduled event immediately.
@@ -119,26 +263,20 @@ func (w *Worker) ExecuteNow(ctx context.Context, orgID, scheduleID string) (stri
 		return "", err
 	}
 
-	// Create event from scheduled event
-	event := domain.NewEvent(sch.Topic, json.RawMessage(sch.Data))
-	event.OrgID = sch.OrgID
-
-	// Publish to NATS
-	if err := w.publisher.Publish(ctx, event); err != nil {
-		w.queries.UpdateScheduledEventStatus(ctx, db.UpdateScheduledEventStatusParams{
-			ID:     sch.ID,
-			Status: "failed",
-			Error:  pgtype.Text{String: err.Error(), Valid: true},
-		})
-		return "", err
+	// A failed manual run counts as an attempt and follows the retry policy
+	res := w.fire(ctx, sch, time.Now())
+	if res.err != nil {
+		if err := w.queries.RecordScheduledEventAttempt(ctx, res.params); err != nil {
+			slog.Error("failed to update scheduled event status after failed execution",
+				"scheduled_id", sch.ID,
+				"error", err,
+			)
+			return "", errors.Join(res.err, fmt.Errorf("record attempt: %w", err))
+		}
+		return "", res.err
 	}
 
-	// Mark as completed
-	if err := w.queries.UpdateScheduledEventStatus(ctx, db.UpdateScheduledEventStatusParams{
-		ID:     sch.ID,
-		Status: "completed",
-		Error:  pgtype.Text{Valid: false},
-	}); err != nil {
+	if err := w.queries.RecordScheduledEventAttempt(ctx, res.params); err != nil {
 		slog.Error("failed to update scheduled event status after execution",
 			"scheduled_id", sch.ID,
 			"error", err,
@@ -148,9 +286,9 @@ func (w *Worker) ExecuteNow(ctx context.Context, orgID, scheduleID string) (stri
 
 	slog.Info("scheduled event executed immediately",
 		"scheduled_id", sch.ID,
-		"event_id", event.ID,
+		"event_id", res.eventID,
 		"topic", sch.Topic,
 	)
 
-	return event.ID, nil
+	return res.eventID, nil
 }
@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/pipeline"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// flakyPublisher fails the first failures calls, then succeeds.
+type flakyPublisher struct {
+	failures int
+	calls    int
+	last     *domain.Event
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("nats: no responders available for request")
+	}
+	p.last = event
+	return nil
+}
+
+func newTestWorker(pub *flakyPublisher) *Worker {
+	return &Worker{publish: pub.Publish, retryBackoff: time.Second}
+}
+
+// runUntilDone fires the schedule the way the poller would, applying each
+// recorded attempt to the row, until it leaves the pending state.
+func runUntilDone(t *testing.T, w *Worker, sch db.ScheduledEvent) (db.ScheduledEvent, []time.Duration) {
+	t.Helper()
+	now := time.Now()
+	var delays []time.Duration
+	for i := 0; i < MaxAttemptsLimit+1; i++ {
+		res := w.fire(context.Background(), sch, now)
+		sch.Status = res.params.Status
+		sch.Attempts = res.params.Attempts
+		sch.Error = res.params.Error
+		if sch.Status != "pending" {
+			return sch, delays
+		}
+		delays = append(delays, res.params.ScheduledFor.Time.Sub(now))
+		now = res.params.ScheduledFor.Time
+		sch.ScheduledFor = res.params.ScheduledFor
+	}
+	t.Fatalf("schedule still pending after %d fires", MaxAttemptsLimit+1)
+	return sch, nil
+}
+
+func testSchedule(maxAttempts int32) db.ScheduledEvent {
+	return db.ScheduledEvent{
+		ID:           "sch_test",
+		OrgID:        "org_test",
+		Topic:        "reports.daily",
+		Data:         []byte(`{}`),
+		Status:       "pending",
+		ScheduledFor: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		MaxAttempts:  maxAttempts,
+	}
+}
+
+func TestFire_TransientFailureRetriesThenCompletes(t *testing.T) {
+	pub := &flakyPublisher{failures: 2}
+	w := newTestWorker(pub)
+
+	sch, delays := runUntilDone(t, w, testSchedule(3))
+
+	if sch.Status != "completed" {
+		t.Fatalf("expected completed, got %s (error: %s)", sch.Status, sch.Error.String)
+	}
+	if sch.Attempts != 3 || pub.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d (publish calls %d)", sch.Attempts, pub.calls)
+	}
+	if sch.Error.Valid {
+		t.Errorf("expected error cleared on success, got %q", sch.Error.String)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second}
+	if len(delays) != len(want) || delays[0] != want[0] || delays[1] != want[1] {
+		t.Errorf("expected exponential backoff %v, got %v", want, delays)
+	}
+}
+
+func TestFire_PersistentFailureMarkedFailed(t *testing.T) {
+	pub := &flakyPublisher{failures: 1000}
+	w := newTestWorker(pub)
+
+	sch, _ := runUntilDone(t, w, testSchedule(4))
+
+	if sch.Status != "failed" {
+		t.Fatalf("expected failed, got %s", sch.Status)
+	}
+	if sch.Attempts != 4 || pub.calls != 4 {
+		t.Errorf("expected 4 attempts, got %d (publish calls %d)", sch.Attempts, pub.calls)
+	}
+	if !sch.Error.Valid || sch.Error.String == "" {
+		t.Error("expected last error to be recorded")
+	}
+}
+
+func TestFire_SingleAttemptFailsImmediately(t *testing.T) {
+	pub := &flakyPublisher{failures: 1}
+	w := newTestWorker(pub)
+
+	sch, _ := runUntilDone(t, w, testSchedule(1))
+
+	if sch.Status != "failed" || pub.calls != 1 {
+		t.Errorf("expected failed after 1 call, got %s after %d", sch.Status, pub.calls)
+	}
+}
+
+func TestBackoff_Capped(t *testing.T) {
+	w := &Worker{retryBackoff: 30 * time.Minute}
+	if got := w.backoff(1); got != 30*time.Minute {
+		t.Errorf("backoff(1) = %v, want 30m", got)
+	}
+	if got := w.backoff(5); got != maxRetryBackoff {
+		t.Errorf("backoff(5) = %v, want %v", got, maxRetryBackoff)
+	}
+}
+
+func recurringSchedule(cron string, maxOccurrences int32) db.ScheduledEvent {
+	sch := testSchedule(1)
+	sch.Cron = pgtype.Text{String: cron, Valid: true}
+	sch.MaxOccurrences = maxOccurrences
+	return sch
+}
+
+func TestFire_RecurringReArms(t *testing.T) {
+	w := newTestWorker(&flakyPublisher{})
+	now := time.Date(2026, 1, 14, 10, 17, 0, 0, time.UTC)
+
+	res := w.fire(context.Background(), recurringSchedule("*/15 * * * *", 0), now)
+
+	if res.params.Status != "pending" {
+		t.Fatalf("expected pending, got %s", res.params.Status)
+	}
+	if want := time.Date(2026, 1, 14, 10, 30, 0, 0, time.UTC); !res.params.ScheduledFor.Time.Equal(want) {
+		t.Errorf("next run = %v, want %v", res.params.ScheduledFor.Time, want)
+	}
+	if res.params.Occurrences != 1 || !res.params.LastRunAt.Time.Equal(now) {
+		t.Errorf("expected 1 occurrence at %v, got %d at %v", now, res.params.Occurrences, res.params.LastRunAt.Time)
+	}
+	if res.params.Attempts != 0 {
+		t.Errorf("expected attempts reset, got %d", res.params.Attempts)
+	}
+}
+
+func TestFire_RecurringStopsAtMaxOccurrences(t *testing.T) {
+	w := newTestWorker(&flakyPublisher{})
+	sch := recurringSchedule("* * * * *", 3)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		res := w.fire(context.Background(), sch, now)
+		sch.Status = res.params.Status
+		sch.Occurrences = res.params.Occurrences
+		sch.ScheduledFor = res.params.ScheduledFor
+		now = res.params.ScheduledFor.Time
+	}
+
+	if sch.Status != "completed" || sch.Occurrences != 3 {
+		t.Errorf("expected completed after 3 occurrences, got %s after %d", sch.Status, sch.Occurrences)
+	}
+}
+
+func TestFire_RecurringSkipsFailedOccurrence(t *testing.T) {
+	w := newTestWorker(&flakyPublisher{failures: 1})
+	now := time.Date(2026, 1, 14, 10, 17, 0, 0, time.UTC)
+
+	res := w.fire(context.Background(), recurringSchedule("0 * * * *", 0), now)
+
+	if res.params.Status != "pending" {
+		t.Fatalf("expected pending, got %s", res.params.Status)
+	}
+	if want := time.Date(2026, 1, 14, 11, 0, 0, 0, time.UTC); !res.params.ScheduledFor.Time.Equal(want) {
+		t.Errorf("next run = %v, want %v", res.params.ScheduledFor.Time, want)
+	}
+	if res.params.Occurrences != 0 || !res.params.Error.Valid {
+		t.Errorf("expected no occurrence and error kept, got %d, %v", res.params.Occurrences, res.params.Error)
+	}
+}
+
+func TestFire_AppliesPipelines(t *testing.T) {
+	pipelines := pipeline.NewRegistry(pipeline.NewMemory())
+	if _, err := pipelines.Create(context.Background(), &pipeline.Pipeline{
+		ProjectID: "prj_test", Name: "redact", TopicPattern: "reports.*", Redact: []string{"secret"}, Enabled: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	pub := &flakyPublisher{}
+	w := newTestWorker(pub)
+	w.SetPipelines(pipelines)
+
+	sch := testSchedule(1)
+	sch.ProjectID = pgtype.Text{String: "prj_test", Valid: true}
+	sch.Data = []byte(`{"secret":"s3cr3t"}`)
+	if res := w.fire(context.Background(), sch, time.Now()); res.err != nil {
+		t.Fatalf("fire: %v", res.err)
+	}
+	if pub.last.ProjectID != "prj_test" {
+		t.Errorf("project_id = %q, want prj_test", pub.last.ProjectID)
+	}
+	if got := string(pub.last.Data); got != `{"secret":"[redacted]"}` {
+		t.Errorf("data = %s, want the secret redacted", got)
+	}
+}
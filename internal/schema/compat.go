@@ -0,0 +1,255 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Change types reported by CheckCompatibility.
+const (
+	ChangeRequiredAdded            = "required_added"
+	ChangeRequiredRemoved          = "required_removed"
+	ChangePropertyAdded            = "property_added"
+	ChangePropertyRemoved          = "property_removed"
+	ChangeTypeNarrowed             = "type_narrowed"
+	ChangeTypeWidened              = "type_widened"
+	ChangeEnumNarrowed             = "enum_narrowed"
+	ChangeEnumWidened              = "enum_widened"
+	ChangeAdditionalPropsDisabled  = "additional_properties_disabled"
+	ChangeAdditionalPropsReenabled = "additional_properties_enabled"
+)
+
+// BreakingChange is one difference between two schema versions that
+// violates the declared compatibility.
+type BreakingChange struct {
+	Field      string `json:"field"`
+	ChangeType string `json:"change_type"`
+	Message    string `json:"message"`
+}
+
+// CompatibilityError rejects a version that breaks compatibility with the
+// latest one.
+type CompatibilityError struct {
+	Mode    Compatibility
+	Against string // the latest version
+	Changes []BreakingChange
+}
+
+func (e *CompatibilityError) Error() string {
+	return fmt.Sprintf("schema is not %s compatible with version %s: %d breaking change(s)", e.Mode, e.Against, len(e.Changes))
+}
+
+// schemaChange is a difference and the directions it breaks. A change
+// breaks backward compatibility when data written with the old schema may
+// fail the new one, and forward when new data may fail the old schema.
+type schemaChange struct {
+	BreakingChange
+	backward bool
+	forward  bool
+}
+
+// CheckCompatibility returns the changes from oldJSON to newJSON that
+// break mode. Object properties, required fields, types, enums and
+// additionalProperties are compared, recursing into properties and items;
+// unresolved $refs are not followed.
+func CheckCompatibility(mode Compatibility, oldJSON, newJSON json.RawMessage) ([]BreakingChange, error) {
+	if mode == CompatibilityNone || mode == "" {
+		return nil, nil
+	}
+	var oldSchema, newSchema any
+	if err := json.Unmarshal(oldJSON, &oldSchema); err != nil {
+		return nil, fmt.Errorf("invalid previous schema: %w", err)
+	}
+	if err := json.Unmarshal(newJSON, &newSchema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var breaking []BreakingChange
+	for _, ch := range diffSchemas("", oldSchema, newSchema) {
+		switch {
+		case mode == CompatibilityBackward && ch.backward,
+			mode == CompatibilityForward && ch.forward,
+			mode == CompatibilityFull && (ch.backward || ch.forward):
+			breaking = append(breaking, ch.BreakingChange)
+		}
+	}
+	return breaking, nil
+}
+
+// diffSchemas compares two subschemas at path.
+func diffSchemas(path string, oldNode, newNode any) []schemaChange {
+	oldObj, _ := oldNode.(map[string]any)
+	newObj, _ := newNode.(map[string]any)
+	if oldObj == nil || newObj == nil {
+		return nil
+	}
+
+	var changes []schemaChange
+	add := func(field, changeType, message string, backward, forward bool) {
+		changes = append(changes, schemaChange{
+			BreakingChange: BreakingChange{Field: field, ChangeType: changeType, Message: message},
+			backward:       backward,
+			forward:        forward,
+		})
+	}
+	field := path
+	if field == "" {
+		field = "(root)"
+	}
+
+	// Types: dropping one rejects old data, adding one lets new data through
+	oldTypes, newTypes := schemaTypes(oldObj), schemaTypes(newObj)
+	if oldTypes != nil && newTypes != nil {
+		if removed := typesMissing(oldTypes, newTypes); len(removed) > 0 {
+			add(field, ChangeTypeNarrowed, fmt.Sprintf("type no longer allows %s", strings.Join(removed, ", ")), true, false)
+		}
+		if added := typesMissing(newTypes, oldTypes); len(added) > 0 {
+			add(field, ChangeTypeWidened, fmt.Sprintf("type now also allows %s", strings.Join(added, ", ")), false, true)
+		}
+	}
+
+	// Enums
+	oldEnum, oldHasEnum := oldObj["enum"].([]any)
+	newEnum, newHasEnum := newObj["enum"].([]any)
+	switch {
+	case newHasEnum && !oldHasEnum:
+		add(field, ChangeEnumNarrowed, "values are now restricted to an enum", true, false)
+	case oldHasEnum && !newHasEnum:
+		add(field, ChangeEnumWidened, "values are no longer restricted to an enum", false, true)
+	case oldHasEnum && newHasEnum:
+		if removed := enumMissing(oldEnum, newEnum); len(removed) > 0 {
+			add(field, ChangeEnumNarrowed, fmt.Sprintf("enum no longer allows %s", strings.Join(removed, ", ")), true, false)
+		}
+		if added := enumMissing(newEnum, oldEnum); len(added) > 0 {
+			add(field, ChangeEnumWidened, fmt.Sprintf("enum now also allows %s", strings.Join(added, ", ")), false, true)
+		}
+	}
+
+	// Required fields
+	oldRequired, newRequired := stringList(oldObj["required"]), stringList(newObj["required"])
+	for _, name := range newRequired {
+		if !slices.Contains(oldRequired, name) {
+			add(joinField(path, name), ChangeRequiredAdded, "field is now required", true, false)
+		}
+	}
+	for _, name := range oldRequired {
+		if !slices.Contains(newRequired, name) {
+			add(joinField(path, name), ChangeRequiredRemoved, "field is no longer required", false, true)
+		}
+	}
+
+	// additionalProperties
+	oldClosed, newClosed := closedObject(oldObj), closedObject(newObj)
+	switch {
+	case newClosed && !oldClosed:
+		add(field, ChangeAdditionalPropsDisabled, "additional properties are no longer allowed", true, false)
+	case oldClosed && !newClosed:
+		add(field, ChangeAdditionalPropsReenabled, "additional properties are now allowed", false, true)
+	}
+
+	// Properties
+	oldProps, _ := oldObj["properties"].(map[string]any)
+	newProps, _ := newObj["properties"].(map[string]any)
+	for _, name := range sortedKeys(oldProps) {
+		newProp, ok := newProps[name]
+		if !ok {
+			// Old data carrying it only fails a closed object
+			if newClosed {
+				add(joinField(path, name), ChangePropertyRemoved, "property was removed from a closed object", true, false)
+			}
+			continue
+		}
+		changes = append(changes, diffSchemas(joinField(path, name), oldProps[name], newProp)...)
+	}
+	for _, name := range sortedKeys(newProps) {
+		if _, ok := oldProps[name]; !ok && oldClosed {
+			// New data carrying it fails the old, closed object
+			add(joinField(path, name), ChangePropertyAdded, "property was added to a closed object", false, true)
+		}
+	}
+
+	// Array items
+	changes = append(changes, diffSchemas(path+"[]", oldObj["items"], newObj["items"])...)
+	return changes
+}
+
+// schemaTypes returns the types a schema allows, or nil when it doesn't
+// say. "integer" is implied by "number".
+func schemaTypes(obj map[string]any) []string {
+	var types []string
+	switch t := obj["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		types = stringList(t)
+	default:
+		return nil
+	}
+	if slices.Contains(types, "number") && !slices.Contains(types, "integer") {
+		types = append(types, "integer")
+	}
+	return types
+}
+
+// typesMissing returns the types in a that b doesn't allow.
+func typesMissing(a, b []string) []string {
+	var missing []string
+	for _, t := range a {
+		if !slices.Contains(b, t) {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// enumMissing returns the values in a that aren't in b, JSON-encoded.
+func enumMissing(a, b []any) []string {
+	encoded := make(map[string]bool, len(b))
+	for _, v := range b {
+		data, _ := json.Marshal(v)
+		encoded[string(data)] = true
+	}
+	var missing []string
+	for _, v := range a {
+		data, _ := json.Marshal(v)
+		if !encoded[string(data)] {
+			missing = append(missing, string(data))
+		}
+	}
+	return missing
+}
+
+func closedObject(obj map[string]any) bool {
+	closed, ok := obj["additionalProperties"].(bool)
+	return ok && !closed
+}
+
+func stringList(v any) []string {
+	items, _ := v.([]any)
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/db"
+)
+
+const compatBase = `{
+	"type": "object",
+	"required": ["id"],
+	"properties": {
+		"id": {"type": "string"},
+		"amount": {"type": "number"},
+		"status": {"type": "string", "enum": ["paid", "refunded"]},
+		"lines": {"type": "array", "items": {"type": "object", "properties": {"sku": {"type": "string"}}}}
+	}
+}`
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		newSchema  string
+		field      string
+		changeType string
+		backward   bool // breaks backward compatibility
+		forward    bool // breaks forward compatibility
+	}{
+		{
+			name:       "required field added",
+			newSchema:  `{"type": "object", "required": ["id", "amount"], "properties": {"id": {"type": "string"}, "amount": {"type": "number"}}}`,
+			field:      "amount",
+			changeType: ChangeRequiredAdded,
+			backward:   true,
+		},
+		{
+			name:       "required field removed",
+			newSchema:  `{"type": "object", "properties": {"id": {"type": "string"}}}`,
+			field:      "id",
+			changeType: ChangeRequiredRemoved,
+			forward:    true,
+		},
+		{
+			name:       "type narrowed",
+			newSchema:  `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "amount": {"type": "integer"}}}`,
+			field:      "amount",
+			changeType: ChangeTypeNarrowed,
+			backward:   true,
+		},
+		{
+			name:       "type widened",
+			newSchema:  `{"type": "object", "required": ["id"], "properties": {"id": {"type": ["string", "integer"]}}}`,
+			field:      "id",
+			changeType: ChangeTypeWidened,
+			forward:    true,
+		},
+		{
+			name:       "enum value removed",
+			newSchema:  `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "status": {"type": "string", "enum": ["paid"]}}}`,
+			field:      "status",
+			changeType: ChangeEnumNarrowed,
+			backward:   true,
+		},
+		{
+			name:       "enum value added",
+			newSchema:  `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "status": {"type": "string", "enum": ["paid", "refunded", "void"]}}}`,
+			field:      "status",
+			changeType: ChangeEnumWidened,
+			forward:    true,
+		},
+		{
+			name:       "object closed",
+			newSchema:  `{"type": "object", "required": ["id"], "additionalProperties": false, "properties": {"id": {"type": "string"}, "amount": {"type": "number"}, "status": {"type": "string", "enum": ["paid", "refunded"]}, "lines": {"type": "array"}}}`,
+			field:      "(root)",
+			changeType: ChangeAdditionalPropsDisabled,
+			backward:   true,
+		},
+		{
+			name:       "nested item type changed",
+			newSchema:  `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "lines": {"type": "array", "items": {"type": "object", "properties": {"sku": {"type": "integer"}}}}}}`,
+			field:      "lines[].sku",
+			changeType: ChangeTypeNarrowed,
+			backward:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for mode, wantBreak := range map[Compatibility]bool{
+				CompatibilityBackward: tt.backward,
+				CompatibilityForward:  tt.forward,
+				CompatibilityFull:     tt.backward || tt.forward,
+				CompatibilityNone:     false,
+			} {
+				changes, err := CheckCompatibility(mode, json.RawMessage(compatBase), json.RawMessage(tt.newSchema))
+				if err != nil {
+					t.Fatalf("%s: %v", mode, err)
+				}
+				found := false
+				for _, ch := range changes {
+					if ch.Field == tt.field && ch.ChangeType == tt.changeType {
+						found = true
+					}
+				}
+				if found != wantBreak {
+					t.Errorf("%s: changes = %+v, want %s on %s: %v", mode, changes, tt.changeType, tt.field, wantBreak)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCompatibility_CompatibleChanges(t *testing.T) {
+	// An optional field added to an open object breaks neither direction
+	newSchema := `{
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"type": "string"},
+			"amount": {"type": "number"},
+			"status": {"type": "string", "enum": ["paid", "refunded"]},
+			"lines": {"type": "array", "items": {"type": "object", "properties": {"sku": {"type": "string"}}}},
+			"note": {"type": "string"}
+		}
+	}`
+	changes, err := CheckCompatibility(CompatibilityFull, json.RawMessage(compatBase), json.RawMessage(newSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
+
+func TestCheckCompatibility_ClosedObjects(t *testing.T) {
+	closed := `{"type": "object", "additionalProperties": false, "properties": {"id": {"type": "string"}}}`
+	withNote := `{"type": "object", "additionalProperties": false, "properties": {"id": {"type": "string"}, "note": {"type": "string"}}}`
+
+	changes, _ := CheckCompatibility(CompatibilityForward, json.RawMessage(closed), json.RawMessage(withNote))
+	if len(changes) != 1 || changes[0].ChangeType != ChangePropertyAdded || changes[0].Field != "note" {
+		t.Errorf("property added to closed object: changes = %+v", changes)
+	}
+	changes, _ = CheckCompatibility(CompatibilityBackward, json.RawMessage(withNote), json.RawMessage(closed))
+	if len(changes) != 1 || changes[0].ChangeType != ChangePropertyRemoved || changes[0].Field != "note" {
+		t.Errorf("property removed from closed object: changes = %+v", changes)
+	}
+}
+
+func TestCheckVersionCompatibility(t *testing.T) {
+	latest := db.SchemaVersion{Version: "1.0.0", SchemaJson: json.RawMessage(compatBase)}
+	newSchema := json.RawMessage(`{"type": "object", "required": ["id", "amount"], "properties": {"id": {"type": "string"}, "amount": {"type": "number"}}}`)
+
+	// Undeclared: the backward changes are warnings only
+	warnings, err := checkVersionCompatibility("", latest, newSchema)
+	if err != nil {
+		t.Fatalf("expected no error without a declared compatibility, got %v", err)
+	}
+	if len(warnings) == 0 || warnings[0].ChangeType != ChangeRequiredAdded {
+		t.Errorf("warnings = %+v, want required_added", warnings)
+	}
+
+	// Declared: enforced
+	_, err = checkVersionCompatibility(CompatibilityBackward, latest, newSchema)
+	var compatErr *CompatibilityError
+	if !errors.As(err, &compatErr) || compatErr.Against != "1.0.0" {
+		t.Fatalf("expected a CompatibilityError against 1.0.0, got %v", err)
+	}
+
+	if warnings, err := checkVersionCompatibility(CompatibilityNone, latest, newSchema); err != nil || len(warnings) != 0 {
+		t.Errorf("none: warnings = %+v, err = %v", warnings, err)
+	}
+}
@@ -0,0 +1,21 @@
+package schema
+
+import "encoding/json"
+
+// DisplayExtension is the JSON Schema keyword holding the CLI display
+// config (template, fields, conditions) for a schema's events.
+const DisplayExtension = "x-notif-display"
+
+// ExtractDisplay returns the schema's display config, or nil when it has
+// none.
+func ExtractDisplay(schemaJSON json.RawMessage) json.RawMessage {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(schemaJSON, &doc); err != nil {
+		return nil
+	}
+	display := doc[DisplayExtension]
+	if len(display) == 0 || string(display) == "null" {
+		return nil
+	}
+	return display
+}
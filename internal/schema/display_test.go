@@ -0,0 +1,24 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtractDisplay(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`{"type":"object","x-notif-display":{"template":"{{.data.id}}"}}`, `{"template":"{{.data.id}}"}`},
+		{`{"type":"object"}`, ``},
+		{`{"type":"object","x-notif-display":null}`, ``},
+		{`not json`, ``},
+	}
+
+	for _, tt := range tests {
+		if got := ExtractDisplay(json.RawMessage(tt.schema)); string(got) != tt.want {
+			t.Errorf("ExtractDisplay(%s) = %s, want %s", tt.schema, got, tt.want)
+		}
+	}
+}
@@ -49,6 +49,25 @@ func matchParts(pattern, topic []string) bool {
 	return pi == len(pattern) && ti == len(topic)
 }
 
+// PatternsOverlap reports whether some topic matches both patterns, e.g.
+// "orders.*" and "*.placed" overlap on "orders.placed".
+func PatternsOverlap(a, b string) bool {
+	return overlapParts(strings.Split(a, "."), strings.Split(b, "."))
+}
+
+func overlapParts(a, b []string) bool {
+	for len(a) > 0 && len(b) > 0 {
+		if a[0] == ">" || b[0] == ">" {
+			return true
+		}
+		if a[0] != "*" && b[0] != "*" && a[0] != b[0] {
+			return false
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) == 0 && len(b) == 0
+}
+
 // FindBestMatch finds the most specific matching pattern for a topic.
 // More specific patterns (longer, fewer wildcards) are preferred.
 func FindBestMatch(patterns []string, topic string) string {
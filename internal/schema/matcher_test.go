@@ -54,6 +54,33 @@ func TestMatchTopic(t *testing.T) {
 	}
 }
 
+func TestPatternsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"orders.placed", "orders.placed", true},
+		{"orders.placed", "orders.shipped", false},
+		{"orders.*", "orders.placed", true},
+		{"orders.*", "*.placed", true},
+		{"orders.*", "orders.us.placed", false},
+		{"orders.>", "orders.us.placed", true},
+		{"orders.>", "*.us.*", true},
+		{"orders.>", "orders", false},
+		{">", "inventory.updated", true},
+		{"orders.*.placed", "orders.us.shipped", false},
+	}
+
+	for _, tt := range tests {
+		if got := PatternsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("PatternsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := PatternsOverlap(tt.b, tt.a); got != tt.want {
+			t.Errorf("PatternsOverlap(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
 func TestFindBestMatch(t *testing.T) {
 	tests := []struct {
 		name     string
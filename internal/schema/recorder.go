@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/filipexyz/notif/internal/db"
+)
+
+// ValidationRecorder writes emit-time validation results for schema stats
+// in the background, keeping the insert off the emit path.
+type ValidationRecorder struct {
+	queries *db.Queries
+	ch      chan db.CreateSchemaValidationParams
+	mu      sync.Mutex // guards closed + ch send, as in audit.Logger
+	closed  bool
+	once    sync.Once
+}
+
+// NewValidationRecorder creates a recorder queueing up to buffer results.
+func NewValidationRecorder(queries *db.Queries, buffer int) *ValidationRecorder {
+	if buffer <= 0 {
+		buffer = 256
+	}
+	r := &ValidationRecorder{
+		queries: queries,
+		ch:      make(chan db.CreateSchemaValidationParams, buffer),
+	}
+	go r.drain()
+	return r
+}
+
+// Record queues a result from ValidateEvent. eventID is empty for rejected
+// events. Results without a schema, or from a version with validation
+// disabled, aren't recorded. When the queue is full the result is dropped.
+func (r *ValidationRecorder) Record(orgID, projectID, eventID, topic string, result *ValidationResult) {
+	params, ok := validationRecord(orgID, projectID, eventID, topic, result)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.ch <- params:
+	default:
+		slog.Warn("schema validation queue full, dropping result", "topic", topic)
+	}
+}
+
+// drain inserts queued results into Postgres.
+func (r *ValidationRecorder) drain() {
+	for params := range r.ch {
+		if r.queries == nil {
+			continue
+		}
+		if _, err := r.queries.CreateSchemaValidation(context.Background(), params); err != nil {
+			slog.Warn("failed to record schema validation", "error", err, "topic", params.Topic.String)
+		}
+	}
+}
+
+// Close stops accepting results; queued ones are still written. Safe to
+// call multiple times.
+func (r *ValidationRecorder) Close() {
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.ch)
+		r.mu.Unlock()
+	})
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/db"
+)
+
+func TestValidationRecorder_QueuesRecordedResults(t *testing.T) {
+	r := cachedRegistry("prj_test", "orders.created", &Schema{
+		ID:   "sch_orders",
+		Name: "order-created",
+		LatestVersion: &SchemaVersion{
+			ID:             "schv_orders_1",
+			SchemaJSON:     json.RawMessage(`{"type":"object","required":["id"]}`),
+			ValidationMode: ValidationModeWarn,
+		},
+	})
+	result, err := r.ValidateEvent(context.Background(), "prj_test", "orders.created", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("ValidateEvent: %v", err)
+	}
+
+	// Not drained, so the queue can be inspected
+	rec := &ValidationRecorder{ch: make(chan db.CreateSchemaValidationParams, 1)}
+	rec.Record("org_test", "prj_test", "evt_1", "orders.created", result)
+	rec.Record("org_test", "prj_test", "evt_2", "orders.created", result) // Queue full: dropped
+	rec.Record("org_test", "prj_test", "evt_3", "audit.login", nil)
+
+	if len(rec.ch) != 1 {
+		t.Fatalf("expected 1 queued result, got %d", len(rec.ch))
+	}
+	if params := <-rec.ch; params.EventID.String != "evt_1" || params.Valid {
+		t.Errorf("queued %+v, want invalid evt_1", params)
+	}
+}
+
+func TestValidationRecorder_RecordAfterClose(t *testing.T) {
+	rec := NewValidationRecorder(nil, 16)
+	rec.Close()
+	rec.Close() // second call must not panic
+	rec.Record("org_test", "prj_test", "evt_1", "orders.created", &ValidationResult{schemaID: "sch_orders"})
+}
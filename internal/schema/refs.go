@@ -0,0 +1,211 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RefPrefix starts a $ref to another registered schema in the same
+// project, e.g. "notif://schemas/money#/definitions/Amount". It resolves
+// against the latest version of the named schema.
+const RefPrefix = "notif://schemas/"
+
+// bundleDefPrefix prefixes the definitions key a referenced schema is
+// inlined under.
+const bundleDefPrefix = "notif:"
+
+// SchemaLookup returns the JSON Schema registered under name.
+type SchemaLookup func(name string) (json.RawMessage, error)
+
+// Bundle inlines every schema referenced through RefPrefix into the
+// definitions of schemaJSON and rewrites the refs to point there, giving a
+// self-contained JSON Schema. Schemas without such refs are returned as is.
+func Bundle(schemaJSON json.RawMessage, lookup SchemaLookup) (json.RawMessage, error) {
+	if !bytes.Contains(schemaJSON, []byte(RefPrefix)) {
+		return schemaJSON, nil
+	}
+
+	root, err := decodeSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	rootObj, ok := root.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("schema with %s refs must be an object", RefPrefix)
+	}
+
+	b := &bundler{lookup: lookup, defs: make(map[string]any)}
+	if _, err := b.rewrite(rootObj, ""); err != nil {
+		return nil, err
+	}
+
+	defs, _ := rootObj["definitions"].(map[string]any)
+	if defs == nil {
+		if _, exists := rootObj["definitions"]; exists {
+			return nil, fmt.Errorf("schema definitions must be an object")
+		}
+		defs = make(map[string]any)
+		rootObj["definitions"] = defs
+	}
+	for key, def := range b.defs {
+		if _, exists := defs[key]; exists {
+			return nil, fmt.Errorf("definition %q is reserved for bundled refs", key)
+		}
+		defs[key] = def
+	}
+	return json.Marshal(rootObj)
+}
+
+// bundler collects referenced schemas, keyed by their definitions key.
+type bundler struct {
+	lookup SchemaLookup
+	defs   map[string]any
+}
+
+// rewrite rewrites the refs in node, which belongs to the referenced
+// schema name, or to the root schema when name is empty.
+func (b *bundler) rewrite(node any, name string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, child := range n {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				rewritten, err := b.rewriteRef(ref, name)
+				if err != nil {
+					return nil, err
+				}
+				n[key] = rewritten
+				continue
+			}
+			rewritten, err := b.rewrite(child, name)
+			if err != nil {
+				return nil, err
+			}
+			n[key] = rewritten
+		}
+	case []any:
+		for i, child := range n {
+			rewritten, err := b.rewrite(child, name)
+			if err != nil {
+				return nil, err
+			}
+			n[i] = rewritten
+		}
+	}
+	return node, nil
+}
+
+func (b *bundler) rewriteRef(ref, name string) (string, error) {
+	if target, ok := strings.CutPrefix(ref, RefPrefix); ok {
+		target, fragment, _ := strings.Cut(target, "#")
+		if target == "" {
+			return "", fmt.Errorf("$ref %q names no schema", ref)
+		}
+		if err := b.include(target); err != nil {
+			return "", err
+		}
+		return "#/definitions/" + bundleDefKey(target) + fragment, nil
+	}
+	// Local refs inside a referenced schema now live under its definition
+	if name != "" && strings.HasPrefix(ref, "#") {
+		return "#/definitions/" + bundleDefKey(name) + ref[1:], nil
+	}
+	return ref, nil
+}
+
+// include inlines the named schema once; refs back to a schema already
+// being inlined resolve to the same definition.
+func (b *bundler) include(name string) error {
+	key := bundleDefKey(name)
+	if _, ok := b.defs[key]; ok {
+		return nil
+	}
+	b.defs[key] = nil
+
+	raw, err := b.lookup(name)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeSchema(raw)
+	if err != nil {
+		return fmt.Errorf("referenced schema %q: %w", name, err)
+	}
+	if obj, ok := doc.(map[string]any); ok {
+		// An inlined $id would change how the refs inside it resolve
+		delete(obj, "$id")
+		delete(obj, "$schema")
+	}
+	def, err := b.rewrite(doc, name)
+	if err != nil {
+		return err
+	}
+	b.defs[key] = def
+	return nil
+}
+
+// bundleDefKey is the definitions key name is inlined under, escaped for
+// use in a JSON pointer.
+func bundleDefKey(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(bundleDefPrefix + name)
+}
+
+func decodeSchema(schemaJSON json.RawMessage) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(schemaJSON))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+	return doc, nil
+}
+
+// Bundle inlines the RefPrefix refs in schemaJSON with the latest versions
+// of the project's schemas they name.
+func (r *Registry) Bundle(ctx context.Context, projectID string, schemaJSON json.RawMessage) (json.RawMessage, error) {
+	return Bundle(schemaJSON, func(name string) (json.RawMessage, error) {
+		s, err := r.GetSchemaByName(ctx, projectID, name)
+		if err != nil {
+			return nil, fmt.Errorf("referenced schema %q not found", name)
+		}
+		if s.LatestVersion == nil {
+			return nil, fmt.Errorf("referenced schema %q has no versions", name)
+		}
+		return s.LatestVersion.SchemaJSON, nil
+	})
+}
+
+// bundledSchema is a version's schema with refs inlined.
+type bundledSchema struct {
+	schemaJSON  json.RawMessage
+	fingerprint string
+}
+
+// validateVersion validates data against sv with its refs resolved.
+// Bundles are cached until the project's schemas change, and compiled
+// schemas are found by fingerprint, so repeated emits compile nothing.
+func (r *Registry) validateVersion(ctx context.Context, projectID string, sv *SchemaVersion, data json.RawMessage) (*ValidationResult, error) {
+	if !bytes.Contains(sv.SchemaJSON, []byte(RefPrefix)) {
+		return r.validator.ValidateWithVersion(sv, data)
+	}
+
+	cacheKey := projectID + ":" + sv.ID
+	cached, ok := r.bundleCache.Load(cacheKey)
+	if !ok {
+		schemaJSON, err := r.Bundle(ctx, projectID, sv.SchemaJSON)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+		cached = &bundledSchema{schemaJSON: schemaJSON, fingerprint: Fingerprint(schemaJSON)}
+		r.bundleCache.Store(cacheKey, cached)
+	}
+	bundle := cached.(*bundledSchema)
+
+	result, err := r.validator.validate(bundle.fingerprint, bundle.schemaJSON, data)
+	if err != nil {
+		return nil, err
+	}
+	result.Version = sv.Version
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testLookup serves registered schemas from a map.
+func testLookup(schemas map[string]string) SchemaLookup {
+	return func(name string) (json.RawMessage, error) {
+		s, ok := schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("referenced schema %q not found", name)
+		}
+		return json.RawMessage(s), nil
+	}
+}
+
+var refSchemas = map[string]string{
+	"money": `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"definitions": {
+			"Currency": {"type": "string", "enum": ["USD", "EUR"]},
+			"Amount": {
+				"type": "object",
+				"required": ["value", "currency"],
+				"properties": {
+					"value": {"type": "number", "minimum": 0},
+					"currency": {"$ref": "#/definitions/Currency"}
+				}
+			}
+		}
+	}`,
+	"address": `{
+		"type": "object",
+		"required": ["country"],
+		"properties": {"country": {"type": "string"}}
+	}`,
+}
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["total", "ship_to"],
+	"properties": {
+		"total": {"$ref": "notif://schemas/money#/definitions/Amount"},
+		"ship_to": {"$ref": "notif://schemas/address"}
+	}
+}`
+
+func TestBundle_ValidatesAcrossSchemas(t *testing.T) {
+	bundled, err := Bundle(json.RawMessage(orderSchema), testLookup(refSchemas))
+	if err != nil {
+		t.Fatalf("Bundle: %v", err)
+	}
+	if strings.Contains(string(bundled), RefPrefix) {
+		t.Fatalf("bundle still has notif refs: %s", bundled)
+	}
+	if err := IsValidSchema(bundled); err != nil {
+		t.Fatalf("bundle is not a valid schema: %v", err)
+	}
+
+	v := NewValidator()
+	tests := []struct {
+		data      string
+		wantValid bool
+	}{
+		{`{"total": {"value": 10, "currency": "USD"}, "ship_to": {"country": "PT"}}`, true},
+		{`{"total": {"value": 10, "currency": "BRL"}, "ship_to": {"country": "PT"}}`, false},
+		{`{"total": {"value": -1, "currency": "USD"}, "ship_to": {"country": "PT"}}`, false},
+		{`{"total": {"value": 10, "currency": "USD"}, "ship_to": {}}`, false},
+	}
+	for _, tt := range tests {
+		result, err := v.Validate(bundled, json.RawMessage(tt.data))
+		if err != nil {
+			t.Fatalf("Validate(%s): %v", tt.data, err)
+		}
+		if result.Valid != tt.wantValid {
+			t.Errorf("Validate(%s) = %v, want %v (errors %v)", tt.data, result.Valid, tt.wantValid, result.Errors)
+		}
+	}
+}
+
+func TestBundle_NestedAndCyclicRefs(t *testing.T) {
+	schemas := map[string]string{
+		"line":  `{"type": "object", "properties": {"price": {"$ref": "notif://schemas/money#/definitions/Amount"}, "parent": {"$ref": "notif://schemas/line"}}}`,
+		"money": refSchemas["money"],
+	}
+	root := `{"type": "array", "items": {"$ref": "notif://schemas/line"}}`
+
+	bundled, err := Bundle(json.RawMessage(root), testLookup(schemas))
+	if err != nil {
+		t.Fatalf("Bundle: %v", err)
+	}
+
+	var doc struct {
+		Definitions map[string]json.RawMessage `json:"definitions"`
+	}
+	if err := json.Unmarshal(bundled, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Definitions) != 2 || doc.Definitions["notif:line"] == nil || doc.Definitions["notif:money"] == nil {
+		t.Fatalf("definitions = %s", bundled)
+	}
+
+	result, err := NewValidator().Validate(bundled, json.RawMessage(`[{"price": {"value": 1, "currency": "EUR"}, "parent": {"price": {"value": 1, "currency": "GBP"}}}]`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if result.Valid {
+		t.Error("invalid nested currency passed validation")
+	}
+}
+
+func TestBundle_WithoutRefs(t *testing.T) {
+	s := json.RawMessage(`{"type": "object"}`)
+	bundled, err := Bundle(s, testLookup(nil))
+	if err != nil {
+		t.Fatalf("Bundle: %v", err)
+	}
+	if string(bundled) != string(s) {
+		t.Errorf("Bundle changed a schema without refs: %s", bundled)
+	}
+}
+
+func TestBundle_Errors(t *testing.T) {
+	for name, s := range map[string]string{
+		"unknown schema":      `{"properties": {"a": {"$ref": "notif://schemas/missing"}}}`,
+		"no schema name":      `{"properties": {"a": {"$ref": "notif://schemas/#/definitions/X"}}}`,
+		"reserved definition": `{"definitions": {"notif:address": {}}, "properties": {"a": {"$ref": "notif://schemas/address"}}}`,
+	} {
+		if _, err := Bundle(json.RawMessage(s), testLookup(refSchemas)); err == nil {
+			t.Errorf("%s: Bundle = nil error, want error", name)
+		}
+	}
+}
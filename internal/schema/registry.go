@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 
@@ -19,6 +20,15 @@ type Registry struct {
 
 	// Cache for schema lookups by topic
 	topicCache sync.Map // map[projectID:topic]*SchemaVersion
+
+	// Versions with notif:// refs, bundled
+	bundleCache sync.Map // map[projectID:versionID]*bundledSchema
+
+	// onChange is called with the project ID after a schema changes
+	onChange func(projectID string)
+
+	// retention prunes old versions when a new one is created
+	retention RetentionPolicy
 }
 
 // NewRegistry creates a new schema registry.
@@ -29,6 +39,12 @@ func NewRegistry(queries *db.Queries) *Registry {
 	}
 }
 
+// SetOnChange registers a callback invoked after a project's schema is
+// updated, deleted or gets a new version.
+func (r *Registry) SetOnChange(fn func(projectID string)) {
+	r.onChange = fn
+}
+
 // CreateSchema creates a new schema.
 func (r *Registry) CreateSchema(ctx context.Context, orgID, projectID string, req *CreateSchemaRequest) (*Schema, error) {
 	id := generateSchemaID()
@@ -109,6 +125,26 @@ func (r *Registry) ListSchemas(ctx context.Context, projectID string) ([]*Schema
 	return schemas, nil
 }
 
+// SchemasForTopics lists the project's schemas whose topic pattern overlaps
+// any of topics, with their latest versions.
+func (r *Registry) SchemasForTopics(ctx context.Context, projectID string, topics []string) ([]*Schema, error) {
+	schemas, err := r.ListSchemas(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*Schema
+	for _, s := range schemas {
+		for _, topic := range topics {
+			if PatternsOverlap(s.TopicPattern, topic) {
+				matched = append(matched, s)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
 // UpdateSchema updates a schema's metadata.
 func (r *Registry) UpdateSchema(ctx context.Context, id string, req *UpdateSchemaRequest) (*Schema, error) {
 	existing, err := r.queries.GetSchema(ctx, id)
@@ -141,8 +177,7 @@ func (r *Registry) UpdateSchema(ctx context.Context, id string, req *UpdateSchem
 		return nil, fmt.Errorf("failed to update schema: %w", err)
 	}
 
-	// Invalidate cache
-	r.invalidateTopicCache(existing.ProjectID)
+	r.changed(existing.ProjectID)
 
 	return dbSchemaToSchema(dbSchema), nil
 }
@@ -158,25 +193,28 @@ func (r *Registry) DeleteSchema(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to delete schema: %w", err)
 	}
 
-	// Invalidate cache
-	r.invalidateTopicCache(existing.ProjectID)
+	r.changed(existing.ProjectID)
 
 	return nil
 }
 
 // CreateVersion creates a new version of a schema.
 func (r *Registry) CreateVersion(ctx context.Context, schemaID string, req *CreateSchemaVersionRequest, createdBy string) (*SchemaVersion, error) {
-	// Validate the JSON schema itself
-	if err := IsValidSchema(req.Schema); err != nil {
-		return nil, fmt.Errorf("invalid JSON schema: %w", err)
-	}
-
 	// Get the schema to find project ID
 	schema, err := r.queries.GetSchema(ctx, schemaID)
 	if err != nil {
 		return nil, fmt.Errorf("schema not found: %w", err)
 	}
 
+	// Validate the JSON schema itself, with the schemas it references
+	bundled, err := r.Bundle(ctx, schema.ProjectID, req.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	if err := IsValidSchema(bundled); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
 	// Set defaults
 	validationMode := req.ValidationMode
 	if validationMode == "" {
@@ -193,6 +231,17 @@ func (r *Registry) CreateVersion(ctx context.Context, schemaID string, req *Crea
 		compatibility = CompatibilityBackward
 	}
 
+	var warnings []BreakingChange
+	if latest, err := r.queries.GetLatestSchemaVersion(ctx, schemaID); err == nil {
+		if warnings, err = checkVersionCompatibility(req.Compatibility, latest, req.Schema); err != nil {
+			return nil, err
+		}
+		if len(warnings) > 0 {
+			slog.Warn("schema version is not backward compatible; declare a compatibility to enforce it",
+				"schema_id", schemaID, "version", req.Version, "against", latest.Version, "breaking_changes", len(warnings))
+		}
+	}
+
 	// Compute fingerprint
 	fingerprint := Fingerprint(req.Schema)
 
@@ -223,10 +272,35 @@ func (r *Registry) CreateVersion(ctx context.Context, schemaID string, req *Crea
 		return nil, fmt.Errorf("failed to create version: %w", err)
 	}
 
-	// Invalidate cache
-	r.invalidateTopicCache(schema.ProjectID)
+	// The new version is already committed; a failed prune is retried on
+	// the next create.
+	if _, err := r.pruneVersions(ctx, schemaID); err != nil {
+		slog.Warn("failed to prune schema versions", "schema_id", schemaID, "error", err)
+	}
+
+	r.changed(schema.ProjectID)
 
-	return dbVersionToVersion(dbVersion), nil
+	v := dbVersionToVersion(dbVersion)
+	v.CompatibilityWarnings = warnings
+	return v, nil
+}
+
+// checkVersionCompatibility checks a new version against the latest. A
+// declared compatibility is enforced with a CompatibilityError; without
+// one, the backward changes are only returned, as warnings.
+func checkVersionCompatibility(declared Compatibility, latest db.SchemaVersion, newJSON json.RawMessage) ([]BreakingChange, error) {
+	mode := declared
+	if mode == "" {
+		mode = CompatibilityBackward
+	}
+	changes, err := CheckCompatibility(mode, latest.SchemaJson, newJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	if len(changes) > 0 && declared != "" {
+		return nil, &CompatibilityError{Mode: mode, Against: latest.Version, Changes: changes}
+	}
+	return changes, nil
 }
 
 // GetVersion retrieves a specific version.
@@ -305,34 +379,107 @@ func (r *Registry) ValidateEvent(ctx context.Context, projectID, topic string, d
 		return &ValidationResult{Valid: true}, nil
 	}
 
-	result, err := r.validator.ValidateWithVersion(schema.LatestVersion, data)
+	result, err := r.validateVersion(ctx, projectID, schema.LatestVersion, data)
 	if err != nil {
 		return nil, err
 	}
 
 	result.Schema = schema.Name
+	result.schemaID = schema.ID
+	result.versionID = schema.LatestVersion.ID
+	result.mode = schema.LatestVersion.ValidationMode
+	result.onInvalid = schema.LatestVersion.OnInvalid
 	return result, nil
 }
 
+// validationStatsWindow is how far back ValidationStats counts.
+const validationStatsWindow = "24h"
+
+// validationRecord builds the schema_validations row for a result, or
+// reports false if the result isn't recorded.
+func validationRecord(orgID, projectID, eventID, topic string, result *ValidationResult) (db.CreateSchemaValidationParams, bool) {
+	if result == nil || result.schemaID == "" || result.mode == ValidationModeDisabled {
+		return db.CreateSchemaValidationParams{}, false
+	}
+	params := db.CreateSchemaValidationParams{
+		ID:              generateValidationID(),
+		OrgID:           orgID,
+		ProjectID:       projectID,
+		EventID:         pgtype.Text{String: eventID, Valid: eventID != ""},
+		SchemaID:        pgtype.Text{String: result.schemaID, Valid: true},
+		SchemaVersionID: pgtype.Text{String: result.versionID, Valid: result.versionID != ""},
+		Topic:           pgtype.Text{String: topic, Valid: topic != ""},
+		Valid:           result.Valid,
+	}
+	if len(result.Errors) > 0 {
+		params.Errors, _ = json.Marshal(result.Errors)
+	}
+	return params, true
+}
+
+// ValidationStats returns the recorded validation outcomes of a schema over
+// the last 24 hours.
+func (r *Registry) ValidationStats(ctx context.Context, s *Schema) (*SchemaValidationStats, error) {
+	rows, err := r.queries.GetValidationStatsBySchemaVersion(ctx, pgtype.Text{String: s.ID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validation stats: %w", err)
+	}
+
+	stats := &SchemaValidationStats{
+		Schema:   s.Name,
+		Window:   validationStatsWindow,
+		Versions: make([]VersionValidationStats, 0, len(rows)),
+	}
+	for _, row := range rows {
+		stats.Total += row.Total
+		stats.ValidCount += row.ValidCount
+		stats.InvalidCount += row.InvalidCount
+		stats.Versions = append(stats.Versions, VersionValidationStats{
+			Version: row.Version,
+			ValidationStats: ValidationStats{
+				Total:        row.Total,
+				ValidCount:   row.ValidCount,
+				InvalidCount: row.InvalidCount,
+			},
+		})
+	}
+	return stats, nil
+}
+
 // Validate validates data against a specific schema.
 func (r *Registry) Validate(ctx context.Context, schemaID string, data json.RawMessage) (*ValidationResult, error) {
+	schema, err := r.queries.GetSchema(ctx, schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("schema not found: %w", err)
+	}
+
 	latestVersion, err := r.queries.GetLatestSchemaVersion(ctx, schemaID)
 	if err != nil {
 		return nil, fmt.Errorf("no version found for schema: %w", err)
 	}
 
 	sv := dbVersionToVersion(latestVersion)
-	return r.validator.ValidateWithVersion(sv, data)
+	return r.validateVersion(ctx, schema.ProjectID, sv, data)
+}
+
+// changed drops the project's cached lookups and reports the change.
+func (r *Registry) changed(projectID string) {
+	r.invalidateTopicCache(projectID)
+	if r.onChange != nil {
+		r.onChange(projectID)
+	}
 }
 
 func (r *Registry) invalidateTopicCache(projectID string) {
 	// Simple approach: clear all entries for this project
-	r.topicCache.Range(func(key, value interface{}) bool {
-		if strings.HasPrefix(key.(string), projectID+":") {
-			r.topicCache.Delete(key)
-		}
-		return true
-	})
+	for _, cache := range []*sync.Map{&r.topicCache, &r.bundleCache} {
+		cache.Range(func(key, value interface{}) bool {
+			if strings.HasPrefix(key.(string), projectID+":") {
+				cache.Delete(key)
+			}
+			return true
+		})
+	}
 }
 
 // Helper functions
@@ -341,6 +488,10 @@ func generateSchemaID() string {
 	return "sch_" + generateRandomID(24)
 }
 
+func generateValidationID() string {
+	return "val_" + generateRandomID(24)
+}
+
 func generateVersionID() string {
 	return "schv_" + generateRandomID(24)
 }
@@ -384,5 +535,6 @@ func dbVersionToVersion(dbv db.SchemaVersion) *SchemaVersion {
 		IsLatest:       dbv.IsLatest.Bool,
 		CreatedAt:      dbv.CreatedAt.Time,
 		CreatedBy:      dbv.CreatedBy.String,
+		Pinned:         dbv.Pinned,
 	}
 }
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// cachedRegistry returns a registry whose topic lookups are served from
+// the cache, so no database is needed.
+func cachedRegistry(projectID, topic string, s *Schema) *Registry {
+	r := NewRegistry(nil)
+	r.topicCache.Store(projectID+":"+topic, s)
+	return r
+}
+
+func TestValidationRecord_MixedResults(t *testing.T) {
+	r := cachedRegistry("prj_test", "orders.created", &Schema{
+		ID:   "sch_orders",
+		Name: "order-created",
+		LatestVersion: &SchemaVersion{
+			ID:             "schv_orders_1",
+			SchemaID:       "sch_orders",
+			Version:        "1.0.0",
+			SchemaJSON:     json.RawMessage(`{"type":"object","required":["id"]}`),
+			ValidationMode: ValidationModeWarn,
+		},
+	})
+
+	var valid, invalid int
+	for _, data := range []string{`{"id":"1"}`, `{}`, `{"id":"2"}`, `{"amount":3}`, `{"id":"4"}`} {
+		result, err := r.ValidateEvent(context.Background(), "prj_test", "orders.created", json.RawMessage(data))
+		if err != nil {
+			t.Fatalf("ValidateEvent: %v", err)
+		}
+		params, ok := validationRecord("org_test", "prj_test", "evt_1", "orders.created", result)
+		if !ok {
+			t.Fatalf("expected %s to be recorded", data)
+		}
+		if params.SchemaID.String != "sch_orders" || params.SchemaVersionID.String != "schv_orders_1" {
+			t.Errorf("recorded schema %q version %q", params.SchemaID.String, params.SchemaVersionID.String)
+		}
+		if params.Valid {
+			valid++
+		} else {
+			invalid++
+			if len(params.Errors) == 0 {
+				t.Errorf("expected errors to be recorded for %s", data)
+			}
+		}
+	}
+	if valid != 3 || invalid != 2 {
+		t.Errorf("got %d valid and %d invalid, want 3 and 2", valid, invalid)
+	}
+}
+
+func TestValidationRecord_Skipped(t *testing.T) {
+	// Topics without a schema
+	r := cachedRegistry("prj_test", "audit.login", nil)
+	result, err := r.ValidateEvent(context.Background(), "prj_test", "audit.login", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("ValidateEvent: %v", err)
+	}
+	if _, ok := validationRecord("org_test", "prj_test", "evt_1", "audit.login", result); ok {
+		t.Error("expected results without a schema not to be recorded")
+	}
+
+	// Versions with validation disabled
+	r = cachedRegistry("prj_test", "orders.created", &Schema{
+		ID:   "sch_orders",
+		Name: "order-created",
+		LatestVersion: &SchemaVersion{
+			ID:             "schv_orders_1",
+			SchemaJSON:     json.RawMessage(`{"type":"object"}`),
+			ValidationMode: ValidationModeDisabled,
+		},
+	})
+	result, err = r.ValidateEvent(context.Background(), "prj_test", "orders.created", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("ValidateEvent: %v", err)
+	}
+	if _, ok := validationRecord("org_test", "prj_test", "evt_1", "orders.created", result); ok {
+		t.Error("expected disabled validation not to be recorded")
+	}
+}
+
+func TestValidateEvent_Rejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      ValidationMode
+		onInvalid OnInvalid
+		data      string
+		want      bool
+	}{
+		{"strict reject invalid", ValidationModeStrict, OnInvalidReject, `{}`, true},
+		{"strict reject valid", ValidationModeStrict, OnInvalidReject, `{"id":"1"}`, false},
+		{"strict log invalid", ValidationModeStrict, OnInvalidLog, `{}`, false},
+		{"warn invalid", ValidationModeWarn, OnInvalidReject, `{}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := cachedRegistry("prj_test", "orders.created", &Schema{
+				ID:   "sch_orders",
+				Name: "order-created",
+				LatestVersion: &SchemaVersion{
+					ID:             "schv_orders_1",
+					Version:        "1.0.0",
+					SchemaJSON:     json.RawMessage(`{"type":"object","required":["id"]}`),
+					ValidationMode: tt.mode,
+					OnInvalid:      tt.onInvalid,
+				},
+			})
+			result, err := r.ValidateEvent(context.Background(), "prj_test", "orders.created", json.RawMessage(tt.data))
+			if err != nil {
+				t.Fatalf("ValidateEvent: %v", err)
+			}
+			if got := result.Rejected(); got != tt.want {
+				t.Errorf("Rejected() = %v, want %v", got, tt.want)
+			}
+			if result.Mode() != tt.mode {
+				t.Errorf("Mode() = %q, want %q", result.Mode(), tt.mode)
+			}
+		})
+	}
+
+	// Topics without a schema are never rejected
+	r := cachedRegistry("prj_test", "audit.login", nil)
+	result, err := r.ValidateEvent(context.Background(), "prj_test", "audit.login", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("ValidateEvent: %v", err)
+	}
+	if result.Rejected() {
+		t.Error("expected topics without a schema not to be rejected")
+	}
+}
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+)
+
+// RetentionPolicy bounds how many versions a schema keeps. The latest
+// version and pinned versions are never pruned.
+type RetentionPolicy struct {
+	// MaxVersions keeps only the newest N versions. 0 = unlimited.
+	MaxVersions int
+	// MaxAge prunes versions created longer ago than this. 0 = never.
+	MaxAge time.Duration
+}
+
+// prunable returns the versions the policy removes. versions must be
+// ordered newest first, as ListVersions returns them.
+func (p RetentionPolicy) prunable(versions []*SchemaVersion, now time.Time) []*SchemaVersion {
+	var prune []*SchemaVersion
+	for i, v := range versions {
+		if v.IsLatest || v.Pinned {
+			continue
+		}
+		tooMany := p.MaxVersions > 0 && i >= p.MaxVersions
+		tooOld := p.MaxAge > 0 && now.Sub(v.CreatedAt) > p.MaxAge
+		if tooMany || tooOld {
+			prune = append(prune, v)
+		}
+	}
+	return prune
+}
+
+// SetRetention sets the policy enforced each time a version is created.
+func (r *Registry) SetRetention(policy RetentionPolicy) {
+	r.retention = policy
+}
+
+// SetVersionPinned pins or unpins a version. Pinned versions are kept
+// regardless of the retention policy.
+func (r *Registry) SetVersionPinned(ctx context.Context, schemaID, version string, pinned bool) (*SchemaVersion, error) {
+	v, err := r.GetVersion(ctx, schemaID, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.queries.SetSchemaVersionPinned(ctx, db.SetSchemaVersionPinnedParams{
+		SchemaID: schemaID,
+		Version:  version,
+		Pinned:   pinned,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update version: %w", err)
+	}
+	v.Pinned = pinned
+	return v, nil
+}
+
+// pruneVersions deletes the schema's versions that fall outside the
+// retention policy and returns how many were removed.
+func (r *Registry) pruneVersions(ctx context.Context, schemaID string) (int, error) {
+	if r.retention.MaxVersions <= 0 && r.retention.MaxAge <= 0 {
+		return 0, nil
+	}
+
+	versions, err := r.ListVersions(ctx, schemaID)
+	if err != nil {
+		return 0, err
+	}
+
+	pruned := 0
+	for _, v := range r.retention.prunable(versions, time.Now()) {
+		if err := r.queries.DeleteSchemaVersion(ctx, v.ID); err != nil {
+			return pruned, fmt.Errorf("failed to delete version %s: %w", v.Version, err)
+		}
+		slog.Info("pruned schema version", "schema_id", schemaID, "version", v.Version)
+		pruned++
+	}
+	return pruned, nil
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// versionHistory returns n versions ordered newest first, one hour apart,
+// with the newest marked latest.
+func versionHistory(n int, now time.Time) []*SchemaVersion {
+	versions := make([]*SchemaVersion, n)
+	for i := range versions {
+		versions[i] = &SchemaVersion{
+			ID:        fmt.Sprintf("sv_%d", n-i),
+			Version:   fmt.Sprintf("1.%d.0", n-i),
+			CreatedAt: now.Add(-time.Duration(i) * time.Hour),
+			IsLatest:  i == 0,
+		}
+	}
+	return versions
+}
+
+func versionNames(versions []*SchemaVersion) []string {
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		names[i] = v.Version
+	}
+	return names
+}
+
+func TestRetentionPolicy_MaxVersions(t *testing.T) {
+	now := time.Now()
+	policy := RetentionPolicy{MaxVersions: 3}
+
+	// N versions: nothing to prune.
+	if prune := policy.prunable(versionHistory(3, now), now); len(prune) != 0 {
+		t.Errorf("prunable(3 versions) = %v, want none", versionNames(prune))
+	}
+
+	// Creating the (N+1)th version prunes the oldest.
+	prune := policy.prunable(versionHistory(4, now), now)
+	if got := versionNames(prune); len(got) != 1 || got[0] != "1.1.0" {
+		t.Errorf("prunable(4 versions) = %v, want [1.1.0]", got)
+	}
+}
+
+func TestRetentionPolicy_KeepsLatest(t *testing.T) {
+	now := time.Now()
+	versions := versionHistory(3, now)
+	// Every version is ancient; only the latest survives.
+	for _, v := range versions {
+		v.CreatedAt = now.Add(-365 * 24 * time.Hour)
+	}
+
+	for _, policy := range []RetentionPolicy{
+		{MaxVersions: 1},
+		{MaxAge: time.Hour},
+		{MaxVersions: 1, MaxAge: time.Minute},
+	} {
+		prune := policy.prunable(versions, now)
+		if len(prune) != 2 {
+			t.Errorf("%+v: prunable = %v, want 2", policy, versionNames(prune))
+		}
+		for _, v := range prune {
+			if v.IsLatest {
+				t.Errorf("%+v: latest version %s was pruned", policy, v.Version)
+			}
+		}
+	}
+}
+
+func TestRetentionPolicy_KeepsPinned(t *testing.T) {
+	now := time.Now()
+	versions := versionHistory(5, now)
+	versions[4].Pinned = true // oldest, 1.1.0
+
+	prune := RetentionPolicy{MaxVersions: 2}.prunable(versions, now)
+	if got := versionNames(prune); len(got) != 2 || got[0] != "1.3.0" || got[1] != "1.2.0" {
+		t.Errorf("prunable = %v, want [1.3.0 1.2.0]", got)
+	}
+}
+
+func TestRetentionPolicy_MaxAge(t *testing.T) {
+	now := time.Now()
+	versions := versionHistory(4, now) // 0h, 1h, 2h, 3h old
+
+	prune := RetentionPolicy{MaxAge: 90 * time.Minute}.prunable(versions, now)
+	if got := versionNames(prune); len(got) != 2 || got[0] != "1.2.0" || got[1] != "1.1.0" {
+		t.Errorf("prunable = %v, want [1.2.0 1.1.0]", got)
+	}
+
+	if prune := (RetentionPolicy{}).prunable(versions, now); len(prune) != 0 {
+		t.Errorf("zero policy pruned %v", versionNames(prune))
+	}
+}
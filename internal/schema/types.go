@@ -63,6 +63,12 @@ type SchemaVersion struct {
 	IsLatest       bool            `json:"is_latest"`
 	CreatedAt      time.Time       `json:"created_at"`
 	CreatedBy      string          `json:"created_by,omitempty"`
+	// Pinned versions are exempt from version retention.
+	Pinned bool `json:"pinned"`
+	// CompatibilityWarnings, set on creation only, lists how a version
+	// created without a declared compatibility breaks backward
+	// compatibility with the previous latest.
+	CompatibilityWarnings []BreakingChange `json:"compatibility_warnings,omitempty"`
 }
 
 // SchemaValidation represents a validation result log entry.
@@ -92,6 +98,22 @@ type ValidationResult struct {
 	Errors  []ValidationError `json:"errors,omitempty"`
 	Schema  string            `json:"schema,omitempty"`
 	Version string            `json:"version,omitempty"`
+
+	// Set by ValidateEvent so the result can be recorded for stats
+	schemaID  string
+	versionID string
+	mode      ValidationMode
+	onInvalid OnInvalid
+}
+
+// Mode is the validation mode of the version a ValidateEvent result came
+// from, or "" when no schema matched.
+func (r *ValidationResult) Mode() ValidationMode { return r.mode }
+
+// Rejected reports whether the event failed a version that rejects
+// invalid events (strict mode with on_invalid "reject").
+func (r *ValidationResult) Rejected() bool {
+	return !r.Valid && r.mode == ValidationModeStrict && r.onInvalid == OnInvalidReject
 }
 
 // SchemaDefinition represents the YAML schema file structure.
@@ -153,3 +175,18 @@ type ValidationStats struct {
 	ValidCount   int64 `json:"valid_count"`
 	InvalidCount int64 `json:"invalid_count"`
 }
+
+// SchemaValidationStats is a schema's emit-time validation outcomes over the
+// last Window, overall and per version (most recently used first).
+type SchemaValidationStats struct {
+	Schema string `json:"schema"`
+	Window string `json:"window"`
+	ValidationStats
+	Versions []VersionValidationStats `json:"versions"`
+}
+
+// VersionValidationStats is the validation outcomes of one schema version.
+type VersionValidationStats struct {
+	Version string `json:"version"`
+	ValidationStats
+}
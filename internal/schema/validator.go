@@ -22,8 +22,14 @@ func NewValidator() *Validator {
 
 // Validate validates data against a schema.
 func (v *Validator) Validate(schemaJSON, data json.RawMessage) (*ValidationResult, error) {
+	return v.validate(Fingerprint(schemaJSON), schemaJSON, data)
+}
+
+// validate validates data against a schema whose fingerprint is already
+// known, so the compiled schema is found without re-encoding it.
+func (v *Validator) validate(fingerprint string, schemaJSON, data json.RawMessage) (*ValidationResult, error) {
 	// Get or compile schema
-	compiled, err := v.getCompiledSchema(schemaJSON)
+	compiled, err := v.getCompiledSchema(fingerprint, schemaJSON)
 	if err != nil {
 		return nil, fmt.Errorf("invalid schema: %w", err)
 	}
@@ -57,7 +63,11 @@ func (v *Validator) Validate(schemaJSON, data json.RawMessage) (*ValidationResul
 
 // ValidateWithVersion validates and includes schema info in the result.
 func (v *Validator) ValidateWithVersion(sv *SchemaVersion, data json.RawMessage) (*ValidationResult, error) {
-	result, err := v.Validate(sv.SchemaJSON, data)
+	fingerprint := sv.Fingerprint
+	if fingerprint == "" {
+		fingerprint = Fingerprint(sv.SchemaJSON)
+	}
+	result, err := v.validate(fingerprint, sv.SchemaJSON, data)
 	if err != nil {
 		return nil, err
 	}
@@ -67,9 +77,7 @@ func (v *Validator) ValidateWithVersion(sv *SchemaVersion, data json.RawMessage)
 }
 
 // getCompiledSchema retrieves a compiled schema from cache or compiles it.
-func (v *Validator) getCompiledSchema(schemaJSON json.RawMessage) (*gojsonschema.Schema, error) {
-	fingerprint := Fingerprint(schemaJSON)
-
+func (v *Validator) getCompiledSchema(fingerprint string, schemaJSON json.RawMessage) (*gojsonschema.Schema, error) {
 	// Check cache
 	if cached, ok := v.cache.Load(fingerprint); ok {
 		return cached.(*gojsonschema.Schema), nil
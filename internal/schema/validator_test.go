@@ -353,3 +353,30 @@ func TestValidator_Caching(t *testing.T) {
 		t.Error("Cached validation should produce same result")
 	}
 }
+
+func TestValidator_CachesByVersionFingerprint(t *testing.T) {
+	v := NewValidator()
+	sv := &SchemaVersion{
+		Version:     "1.0.0",
+		SchemaJSON:  json.RawMessage(`{"type": "object", "required": ["id"]}`),
+		Fingerprint: "fp_orders_1",
+	}
+
+	for range 3 {
+		if _, err := v.ValidateWithVersion(sv, json.RawMessage(`{"id": "1"}`)); err != nil {
+			t.Fatalf("ValidateWithVersion() error = %v", err)
+		}
+	}
+
+	var entries int
+	v.cache.Range(func(key, _ any) bool {
+		entries++
+		if key != "fp_orders_1" {
+			t.Errorf("cache key = %v, want the version fingerprint", key)
+		}
+		return true
+	})
+	if entries != 1 {
+		t.Errorf("cache entries = %d, want 1", entries)
+	}
+}
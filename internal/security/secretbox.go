@@ -0,0 +1,90 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marks a value encrypted by SecretBox.
+const sealedPrefix = "enc:v1:"
+
+// ErrNoSecretKey is returned when opening a sealed value without a key.
+var ErrNoSecretKey = errors.New("value is encrypted but SECRETS_ENCRYPTION_KEY is not set")
+
+// SecretBox encrypts credentials stored at rest, such as webhook header
+// values, with AES-256-GCM. A nil *SecretBox leaves values in plaintext.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// ParseSecretKey decodes a base64-encoded 32-byte key.
+func ParseSecretKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("secret key must be base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secret key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// NewSecretBox creates a SecretBox from a 32-byte key.
+func NewSecretBox(key []byte) (*SecretBox, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts plaintext. Without a key it is returned unchanged.
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	if b == nil {
+		return plaintext, nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value from Seal. Values that were stored without a key
+// are returned as-is.
+func (b *SecretBox) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	if b == nil {
+		return "", ErrNoSecretKey
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode sealed value: %w", err)
+	}
+	n := b.aead.NonceSize()
+	if len(raw) < n {
+		return "", errors.New("sealed value too short")
+	}
+	plaintext, err := b.aead.Open(nil, raw[:n], raw[n:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt sealed value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value was encrypted by Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
@@ -0,0 +1,64 @@
+package security
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestSecretBox_RoundTrip(t *testing.T) {
+	box, err := NewSecretBox(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := box.Seal("Bearer abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || sealed == "Bearer abc123" {
+		t.Fatalf("expected sealed value, got %q", sealed)
+	}
+	if got, err := box.Open(sealed); err != nil || got != "Bearer abc123" {
+		t.Errorf("Open = %q, %v", got, err)
+	}
+
+	// Values stored before a key was configured still open
+	if got, err := box.Open("plain"); err != nil || got != "plain" {
+		t.Errorf("Open(plain) = %q, %v", got, err)
+	}
+}
+
+func TestSecretBox_Nil(t *testing.T) {
+	var box *SecretBox
+	if got, _ := box.Seal("token"); got != "token" {
+		t.Errorf("nil Seal = %q, want plaintext", got)
+	}
+
+	other, _ := NewSecretBox(bytes.Repeat([]byte{1}, 32))
+	sealed, _ := other.Seal("token")
+	if _, err := box.Open(sealed); !errors.Is(err, ErrNoSecretKey) {
+		t.Errorf("nil Open of sealed value: err = %v, want ErrNoSecretKey", err)
+	}
+}
+
+func TestSecretBox_WrongKey(t *testing.T) {
+	a, _ := NewSecretBox(bytes.Repeat([]byte{1}, 32))
+	b, _ := NewSecretBox(bytes.Repeat([]byte{2}, 32))
+	sealed, _ := a.Seal("token")
+	if _, err := b.Open(sealed); err == nil {
+		t.Error("expected error opening with the wrong key")
+	}
+}
+
+func TestParseSecretKey(t *testing.T) {
+	if _, err := ParseSecretKey(base64.StdEncoding.EncodeToString(make([]byte, 32))); err != nil {
+		t.Errorf("valid key: %v", err)
+	}
+	for _, s := range []string{"not base64!", base64.StdEncoding.EncodeToString(make([]byte, 16))} {
+		if _, err := ParseSecretKey(s); err == nil {
+			t.Errorf("ParseSecretKey(%q): expected error", s)
+		}
+	}
+}
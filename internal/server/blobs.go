@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/filipexyz/notif/internal/blob"
+	"github.com/filipexyz/notif/internal/db"
+)
+
+// newBlobService builds the blob service selected by BLOB_STORE. It returns
+// a nil service when attachments are disabled, plus the handler to mount at
+// blob.LocalPathPrefix when notifd serves the blobs itself.
+func (s *Server) newBlobService(queries *db.Queries) (*blob.Service, http.Handler, error) {
+	var (
+		store blob.Store
+		serve http.Handler
+	)
+	switch s.cfg.BlobStore {
+	case "":
+		return nil, nil, nil
+	case "local":
+		local, err := blob.NewLocalStore(s.cfg.BlobLocalDir, s.cfg.BlobPublicURL, []byte(s.cfg.BlobSigningKey), s.cfg.BlobMaxSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		store, serve = local, local
+	case "s3":
+		s3, err := blob.NewS3Store(blob.S3Config{
+			Bucket:          s.cfg.BlobS3Bucket,
+			Region:          s.cfg.BlobS3Region,
+			Endpoint:        s.cfg.BlobS3Endpoint,
+			AccessKeyID:     s.cfg.BlobS3AccessKeyID,
+			SecretAccessKey: s.cfg.BlobS3SecretAccessKey,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		store = s3
+	default:
+		return nil, nil, fmt.Errorf("unknown BLOB_STORE %q (want local or s3)", s.cfg.BlobStore)
+	}
+
+	svc := blob.NewService(queries, store, s.cfg.BlobMaxSize)
+	svc.SetURLTTLs(s.cfg.BlobUploadTTL, s.cfg.BlobDownloadTTL)
+	slog.Info("blob attachments enabled", "store", s.cfg.BlobStore)
+	return svc, serve, nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+
+	"github.com/filipexyz/notif/internal/audit"
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/filipexyz/notif/internal/metrics"
+	"github.com/filipexyz/notif/internal/nats"
+)
+
+// newFanoutGate builds the gate shared by the webhook workers and the
+// WebSocket hub, so one event's receivers are counted across both.
+func newFanoutGate(cfg *config.Config, auditLog *audit.Logger) *nats.FanoutGate {
+	if len(cfg.FanoutLimits) == 0 {
+		return nil
+	}
+	gate := nats.NewFanoutGate(cfg.FanoutLimits, cfg.FanoutShed)
+	gate.SetOverflowFunc(fanoutOverflowRecorder(auditLog))
+	return gate
+}
+
+// fanoutOverflowRecorder counts every receiver past a fan-out limit and
+// audits the first one per event.
+func fanoutOverflowRecorder(auditLog *audit.Logger) func(context.Context, nats.FanoutOverflow) {
+	return func(ctx context.Context, o nats.FanoutOverflow) {
+		action := "logged"
+		if o.Shed {
+			action = "shed"
+		}
+		metrics.FanoutOverflow.WithLabelValues(metrics.TopicPrefix(o.Event.Topic), action).Inc()
+		if !o.First || auditLog == nil {
+			return
+		}
+		auditLog.Log(ctx, "notifd", "event.fanout_limited", o.Event.OrgID, o.Event.ID, map[string]any{
+			"topic": o.Event.Topic,
+			"limit": o.Limit,
+			"shed":  o.Shed,
+		})
+	}
+}
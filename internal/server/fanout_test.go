@@ -0,0 +1,27 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/metrics"
+	"github.com/filipexyz/notif/internal/nats"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFanoutOverflowRecorder(t *testing.T) {
+	record := fanoutOverflowRecorder(nil)
+	event := &domain.Event{ID: "evt_1", Topic: "fanrec.created"}
+
+	record(context.Background(), nats.FanoutOverflow{Event: event, Receiver: "a", Limit: 1, Shed: true, First: true})
+	record(context.Background(), nats.FanoutOverflow{Event: event, Receiver: "b", Limit: 1, Shed: true})
+	record(context.Background(), nats.FanoutOverflow{Event: event, Receiver: "c", Limit: 1})
+
+	if got := testutil.ToFloat64(metrics.FanoutOverflow.WithLabelValues("fanrec", "shed")); got != 2 {
+		t.Errorf("shed = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(metrics.FanoutOverflow.WithLabelValues("fanrec", "logged")); got != 1 {
+		t.Errorf("logged = %v, want 1", got)
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/filipexyz/notif/internal/metrics"
+	"github.com/filipexyz/notif/internal/nats"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsScrapeTimeout bounds the NATS lookups made during one scrape.
+const metricsScrapeTimeout = 2 * time.Second
+
+// setupMetrics builds the metrics registry and, when METRICS_PORT is set,
+// the admin server that serves it. Metrics are never served on the main
+// listener: they name every org, and scrapers don't carry API keys.
+func (s *Server) setupMetrics() {
+	s.metrics = s.newMetrics()
+	if s.cfg.MetricsPort == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler(s.metrics))
+	s.metricsServer = &http.Server{
+		Addr:    ":" + s.cfg.MetricsPort,
+		Handler: mux,
+	}
+}
+
+// newMetrics builds the registry served at /metrics: the server-wide
+// metrics plus gauges read from the hub and NATS at scrape time.
+func (s *Server) newMetrics() *prometheus.Registry {
+	reg := metrics.NewRegistry(
+		metrics.NewGaugeFunc("notif_websocket_connections",
+			"Active WebSocket connections.", nil,
+			func() []metrics.Sample {
+				return []metrics.Sample{{Value: float64(s.hub.ClientCount())}}
+			}),
+	)
+
+	if s.pool != nil {
+		reg.MustRegister(metrics.NewGaugeFunc("notif_nats_connected",
+			"Whether each org's NATS connection is up (1) or down (0).", []string{"org_id"},
+			s.poolHealthSamples))
+		reg.MustRegister(metrics.NewGaugeFunc("notif_dlq_depth",
+			"Messages in each org's dead letter queue.", []string{"org_id"},
+			s.poolDLQSamples))
+		return reg
+	}
+
+	reg.MustRegister(metrics.NewGaugeFunc("notif_nats_connected",
+		"Whether the NATS connection is up (1) or down (0).", nil,
+		func() []metrics.Sample {
+			return []metrics.Sample{{Value: boolValue(s.nats.IsConnected())}}
+		}))
+	reg.MustRegister(metrics.NewGaugeFunc("notif_dlq_depth",
+		"Messages in the dead letter queue.", nil,
+		func() []metrics.Sample {
+			ctx, cancel := context.WithTimeout(context.Background(), metricsScrapeTimeout)
+			defer cancel()
+			reader, err := nats.NewDLQReader(s.nats.JetStream())
+			if err != nil {
+				return nil
+			}
+			depth, err := reader.Depth(ctx)
+			if err != nil {
+				slog.Warn("metrics: failed to read DLQ depth", "error", err)
+				return nil
+			}
+			return []metrics.Sample{{Value: float64(depth)}}
+		}))
+	return reg
+}
+
+// poolHealthSamples reports every org's NATS connection state.
+func (s *Server) poolHealthSamples() []metrics.Sample {
+	var samples []metrics.Sample
+	for _, orgID := range s.pool.OrgIDs() {
+		client, err := s.pool.Get(orgID)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{orgID},
+			Value:       boolValue(client.IsConnected()),
+		})
+	}
+	return samples
+}
+
+// poolDLQSamples reports the depth of every org's DLQ stream. Orgs whose
+// stream can't be read are left out of the scrape.
+func (s *Server) poolDLQSamples() []metrics.Sample {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsScrapeTimeout)
+	defer cancel()
+
+	var samples []metrics.Sample
+	for _, orgID := range s.pool.OrgIDs() {
+		client, err := s.pool.Get(orgID)
+		if err != nil {
+			continue
+		}
+		reader, err := nats.NewDLQReaderForOrg(client.JetStream(), orgID)
+		if err != nil {
+			continue
+		}
+		depth, err := reader.Depth(ctx)
+		if err != nil {
+			slog.Warn("metrics: failed to read DLQ depth", "org_id", orgID, "error", err)
+			continue
+		}
+		samples = append(samples, metrics.Sample{LabelValues: []string{orgID}, Value: float64(depth)})
+	}
+	return samples
+}
+
+// startMetricsServer serves /metrics on METRICS_PORT, when it is set.
+func (s *Server) startMetricsServer() {
+	if s.metricsServer == nil {
+		return
+	}
+	go func() {
+		slog.Info("starting metrics server", "port", s.cfg.MetricsPort)
+		if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server error", "error", err)
+		}
+	}()
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
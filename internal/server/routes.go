@@ -1,17 +1,24 @@
 package server
 
 import (
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
 
 	clerkhttp "github.com/clerk/clerk-sdk-go/v2/http"
+	"github.com/filipexyz/notif/internal/blob"
 	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/eventstore"
 	"github.com/filipexyz/notif/internal/handler"
 	"github.com/filipexyz/notif/internal/middleware"
 	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/pipeline"
 	"github.com/filipexyz/notif/internal/schema"
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
 func (s *Server) routes() http.Handler {
@@ -20,7 +27,7 @@ func (s *Server) routes() http.Handler {
 	// Global middleware
 	r.Use(chimw.RequestID)
 	r.Use(chimw.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(middleware.AccessLog(middleware.NewAccessLogger(os.Stdout, s.cfg.LogFormat), s.cfg.AccessLog))
 	r.Use(chimw.Recoverer)
 
 	// Clerk JWT parsing (non-blocking - just parses if present)
@@ -52,6 +59,9 @@ func (s *Server) routes() http.Handler {
 		r.Get("/ready", healthHandler.Ready)
 	}
 
+	// Build info (no auth)
+	r.Get("/version", handler.NewVersionHandler().Version)
+
 	// Bootstrap endpoints for self-hosted setup (no auth, but rate limited)
 	bootstrapHandler := handler.NewBootstrapHandler(queries, s.cfg)
 	r.Group(func(r chi.Router) {
@@ -60,6 +70,25 @@ func (s *Server) routes() http.Handler {
 		r.Post("/api/v1/bootstrap", bootstrapHandler.Bootstrap)
 	})
 
+	// Blob attachments (optional). Local presigned URLs carry their own
+	// signature, so the blob endpoint sits outside API key auth.
+	blobs, blobServer, err := s.newBlobService(queries)
+	if err != nil {
+		slog.Error("blob attachments disabled", "error", err)
+	}
+	s.blobs = blobs
+	if blobServer != nil {
+		r.Handle(blob.LocalPathPrefix+"*", blobServer)
+	}
+
+	// Event metadata lives in Postgres; other eventstore.Store backends
+	// plug in here.
+	s.events = eventstore.NewPostgres(queries)
+
+	// One limiter for the whole server: handlers are built per request in
+	// multi-account mode, and must not start with fresh buckets.
+	s.emitLimits = nats.NewEmitRateLimiter(s.cfg.EmitRateLimits)
+
 	// Build handlers based on mode
 	if s.pool != nil {
 		s.routesMultiAccount(r, queries)
@@ -73,11 +102,20 @@ func (s *Server) routes() http.Handler {
 // routesMultiAccount sets up routes for multi-account mode using ClientPool.
 func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 	schemaRegistry := schema.NewRegistry(queries)
+	schemaRegistry.SetOnChange(s.pushDisplayConfigs)
+	schemaRegistry.SetRetention(schema.RetentionPolicy{
+		MaxVersions: s.cfg.SchemaMaxVersions,
+		MaxAge:      s.cfg.SchemaVersionMaxAge,
+	})
+	pipelines := pipeline.NewRegistry(pipeline.NewPostgres(queries))
 
 	// Org management endpoints (admin only)
 	orgHandler := handler.NewOrgHandler(queries, s.pool, s.accountMgr, s.auditLog)
 	orgHandler.SetOnOrgCreated(s.StartOrgWebhookWorker)
 	orgHandler.SetOnOrgDeleted(s.StopOrgWebhookWorker)
+	orgHandler.SetOnOrgDrained(s.PauseOrgDeliveries)
+	orgHandler.SetOnOrgResumed(s.ResumeOrgDeliveries)
+	orgHandler.SetOnStreamRecreated(s.RestartOrgWebhookWorker)
 	r.Route("/api/v1/orgs", func(r chi.Router) {
 		r.Use(middleware.RateLimit(s.rateLimiter))
 		r.Use(middleware.UnifiedAuth(queries, s.cfg))
@@ -88,6 +126,9 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 		r.Delete("/{id}", orgHandler.Delete)
 		r.Get("/{id}/limits", orgHandler.Limits)
 		r.Put("/{id}/limits", orgHandler.Limits)
+		r.Post("/{id}/drain", orgHandler.Drain)
+		r.Post("/{id}/resume", orgHandler.Resume)
+		r.Post("/{id}/stream/migrate", orgHandler.MigrateStream)
 	})
 
 	// WebSocket endpoint
@@ -108,8 +149,13 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 			}
 
 			consumerMgr := nats.NewConsumerManager(orgClient.Stream())
+			consumerMgr.SetGroupTTL(s.cfg.ConsumerGroupTTL)
+			consumerMgr.SetResumeTTL(s.cfg.ResumeCursorTTL)
 			dlqPublisher := nats.NewDLQPublisher(orgClient.JetStream())
 			subscribeHandler := handler.NewSubscribeHandler(s.hub, consumerMgr, dlqPublisher, queries, s.cfg, s.auditLog)
+			emitHandler := s.newEmitHandler(nats.NewPublisher(orgClient.JetStream()), queries, schemaRegistry, pipelines)
+			subscribeHandler.SetEmitHandler(emitHandler)
+			subscribeHandler.SetSchemaRegistry(schemaRegistry)
 			subscribeHandler.Subscribe(w, r)
 		})
 	})
@@ -126,25 +172,35 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 		r.Use(middleware.RateLimit(s.rateLimiter))
 		r.Use(middleware.UnifiedAuth(queries, s.cfg))
 
-		// Events — resolve orgID → pool.Get(orgID)
-		r.Post("/emit", func(w http.ResponseWriter, r *http.Request) {
-			authCtx := middleware.GetAuthContext(r.Context())
-			if authCtx == nil || authCtx.OrgID == "" {
-				handler.WriteJSONPublic(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
-				return
+		whoamiHandler := handler.NewWhoamiHandler(queries)
+		r.Get("/whoami", whoamiHandler.Whoami)
+		usageHandler := handler.NewUsageHandler(s.hub, s.cfg)
+		usageHandler.SetStorageSource(s.events, func(orgID string) (*nats.EventReader, *nats.DLQReader, error) {
+			orgClient, err := s.pool.Get(orgID)
+			if err != nil {
+				return nil, nil, err
 			}
-
-			orgClient, err := s.pool.Get(authCtx.OrgID)
+			dlqReader, err := nats.NewDLQReaderForOrg(orgClient.JetStream(), orgID)
 			if err != nil {
-				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{
-					"error": "org not connected",
-				})
-				return
+				return nil, nil, err
 			}
+			return nats.NewEventReader(orgClient.Stream()), dlqReader, nil
+		})
+		r.Get("/usage", usageHandler.Usage)
+		r.Get("/usage/storage", usageHandler.Storage)
 
-			publisher := nats.NewPublisher(orgClient.JetStream())
-			emitHandler := handler.NewEmitHandler(publisher, queries, schemaRegistry, s.cfg, s.auditLog)
-			emitHandler.Emit(w, r)
+		// Blob metadata lives in Postgres, so it doesn't need the org's NATS client
+		blobHandler := handler.NewBlobHandler(s.blobs)
+		r.Post("/blobs", blobHandler.Create)
+		r.Get("/blobs/{id}", blobHandler.Get)
+
+		// Events — resolve orgID → pool.Get(orgID); drained orgs get 503
+		s.routesOrgEmit(r, func(orgID string) (*handler.EmitHandler, error) {
+			orgClient, err := s.pool.Get(orgID)
+			if err != nil {
+				return nil, err
+			}
+			return s.newEmitHandler(nats.NewPublisher(orgClient.JetStream()), queries, schemaRegistry, pipelines), nil
 		})
 
 		r.Get("/events", func(w http.ResponseWriter, r *http.Request) {
@@ -162,6 +218,7 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 
 			eventReader := nats.NewEventReader(orgClient.Stream())
 			eventsHandler := handler.NewEventsHandler(eventReader, queries)
+			eventsHandler.SetEventStore(s.events)
 			eventsHandler.List(w, r)
 		})
 		r.Get("/events/stats", func(w http.ResponseWriter, r *http.Request) {
@@ -177,6 +234,7 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 			}
 			eventReader := nats.NewEventReader(orgClient.Stream())
 			eventsHandler := handler.NewEventsHandler(eventReader, queries)
+			eventsHandler.SetEventStore(s.events)
 			eventsHandler.Stats(w, r)
 		})
 		r.Get("/events/{seq}", func(w http.ResponseWriter, r *http.Request) {
@@ -192,6 +250,7 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 			}
 			eventReader := nats.NewEventReader(orgClient.Stream())
 			eventsHandler := handler.NewEventsHandler(eventReader, queries)
+			eventsHandler.SetEventStore(s.events)
 			eventsHandler.Get(w, r)
 		})
 		r.Get("/events/{id}/deliveries", func(w http.ResponseWriter, r *http.Request) {
@@ -207,17 +266,33 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 			}
 			eventReader := nats.NewEventReader(orgClient.Stream())
 			eventsHandler := handler.NewEventsHandler(eventReader, queries)
+			eventsHandler.SetEventStore(s.events)
 			eventsHandler.Deliveries(w, r)
 		})
+		r.Get("/topics/tree", func(w http.ResponseWriter, r *http.Request) {
+			eventsHandler := handler.NewEventsHandler(nil, queries)
+			eventsHandler.SetEventStore(s.events)
+			eventsHandler.TopicTree(w, r)
+		})
 
 		// Webhooks
 		webhookHandler := handler.NewWebhookHandler(queries, s.auditLog)
+		webhookHandler.SetSecretBox(s.secrets)
+		webhookHandler.SetRetrySource(func(orgID string) (*nats.EventReader, jetstream.JetStream, error) {
+			orgClient, err := s.pool.Get(orgID)
+			if err != nil {
+				return nil, nil, err
+			}
+			return nats.NewEventReader(orgClient.Stream()), orgClient.JetStream(), nil
+		})
 		r.Post("/webhooks", webhookHandler.Create)
 		r.Get("/webhooks", webhookHandler.List)
 		r.Get("/webhooks/{id}", webhookHandler.Get)
 		r.Put("/webhooks/{id}", webhookHandler.Update)
 		r.Delete("/webhooks/{id}", webhookHandler.Delete)
+		r.Post("/webhooks/{id}/rotate-secret", webhookHandler.RotateSecret)
 		r.Get("/webhooks/{id}/deliveries", webhookHandler.Deliveries)
+		r.Post("/webhooks/{id}/deliveries/{delivery_id}/retry", webhookHandler.RetryDelivery)
 
 		// DLQ — resolve orgID → pool.Get(orgID) for per-account DLQ
 		r.Get("/dlq", func(w http.ResponseWriter, r *http.Request) {
@@ -240,6 +315,26 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 			dlqHandler := handler.NewDLQHandler(dlqReader, publisher)
 			dlqHandler.List(w, r)
 		})
+		r.Get("/dlq/stats", func(w http.ResponseWriter, r *http.Request) {
+			authCtx := middleware.GetAuthContext(r.Context())
+			if authCtx == nil || authCtx.OrgID == "" {
+				handler.WriteJSONPublic(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+			orgClient, err := s.pool.Get(authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "org not connected"})
+				return
+			}
+			dlqReader, err := nats.NewDLQReaderForOrg(orgClient.JetStream(), authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "DLQ not available"})
+				return
+			}
+			publisher := nats.NewPublisher(orgClient.JetStream())
+			dlqHandler := handler.NewDLQHandler(dlqReader, publisher)
+			dlqHandler.Stats(w, r)
+		})
 		r.Get("/dlq/{seq}", func(w http.ResponseWriter, r *http.Request) {
 			authCtx := middleware.GetAuthContext(r.Context())
 			if authCtx == nil || authCtx.OrgID == "" {
@@ -300,6 +395,27 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 			dlqHandler := handler.NewDLQHandler(dlqReader, publisher)
 			dlqHandler.Delete(w, r)
 		})
+		r.Post("/dlq/replay", func(w http.ResponseWriter, r *http.Request) {
+			authCtx := middleware.GetAuthContext(r.Context())
+			if authCtx == nil || authCtx.OrgID == "" {
+				handler.WriteJSONPublic(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+			orgClient, err := s.pool.Get(authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "org not connected"})
+				return
+			}
+			dlqReader, err := nats.NewDLQReaderForOrg(orgClient.JetStream(), authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "DLQ not available"})
+				return
+			}
+			publisher := nats.NewPublisher(orgClient.JetStream())
+			dlqHandler := handler.NewDLQHandler(dlqReader, publisher)
+			dlqHandler.SetReplayGuard(s.dlqReplays)
+			dlqHandler.ReplayBatch(w, r)
+		})
 		r.Post("/dlq/replay-all", func(w http.ResponseWriter, r *http.Request) {
 			authCtx := middleware.GetAuthContext(r.Context())
 			if authCtx == nil || authCtx.OrgID == "" {
@@ -340,6 +456,26 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 			dlqHandler := handler.NewDLQHandler(dlqReader, publisher)
 			dlqHandler.Purge(w, r)
 		})
+		r.Delete("/dlq", func(w http.ResponseWriter, r *http.Request) {
+			authCtx := middleware.GetAuthContext(r.Context())
+			if authCtx == nil || authCtx.OrgID == "" {
+				handler.WriteJSONPublic(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+			orgClient, err := s.pool.Get(authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "org not connected"})
+				return
+			}
+			dlqReader, err := nats.NewDLQReaderForOrg(orgClient.JetStream(), authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "DLQ not available"})
+				return
+			}
+			publisher := nats.NewPublisher(orgClient.JetStream())
+			dlqHandler := handler.NewDLQHandler(dlqReader, publisher)
+			dlqHandler.Purge(w, r)
+		})
 
 		// Schedules — disabled in multi-account mode until per-org scheduling is implemented.
 		// Each org needs its own scheduler worker; the current single-worker design would
@@ -357,7 +493,6 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 		r.Get("/stats/schedules", http.HandlerFunc(notImplemented))
 
 		// Schemas
-		schemaRegistry := schema.NewRegistry(queries)
 		schemaHandler := handler.NewSchemaHandler(schemaRegistry)
 		r.Post("/schemas", schemaHandler.CreateSchema)
 		r.Get("/schemas", schemaHandler.ListSchemas)
@@ -368,7 +503,18 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 		r.Post("/schemas/{name}/versions", schemaHandler.CreateVersion)
 		r.Get("/schemas/{name}/versions", schemaHandler.ListVersions)
 		r.Get("/schemas/{name}/versions/{version}", schemaHandler.GetVersion)
+		r.Post("/schemas/{name}/versions/{version}/pin", schemaHandler.PinVersion)
+		r.Delete("/schemas/{name}/versions/{version}/pin", schemaHandler.UnpinVersion)
 		r.Post("/schemas/{name}/validate", schemaHandler.Validate)
+		r.Get("/schemas/{name}/stats", schemaHandler.GetStats)
+
+		// Transformation pipelines, applied at emit
+		pipelineHandler := handler.NewPipelineHandler(pipelines, s.auditLog)
+		r.Post("/pipelines", pipelineHandler.Create)
+		r.Get("/pipelines", pipelineHandler.List)
+		r.Get("/pipelines/{name}", pipelineHandler.Get)
+		r.Put("/pipelines/{name}", pipelineHandler.Update)
+		r.Delete("/pipelines/{name}", pipelineHandler.Delete)
 
 		// Audit log
 		auditHandler := handler.NewAuditHandler(queries)
@@ -393,6 +539,7 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 				return
 			}
 			statsHandler := handler.NewStatsHandler(queries, eventReader, dlqReader)
+			statsHandler.SetEventStore(s.events)
 			statsHandler.Overview(w, r)
 		})
 		r.Get("/stats/events", func(w http.ResponseWriter, r *http.Request) {
@@ -413,6 +560,7 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 				return
 			}
 			statsHandler := handler.NewStatsHandler(queries, eventReader, dlqReader)
+			statsHandler.SetEventStore(s.events)
 			statsHandler.Events(w, r)
 		})
 		r.Get("/stats/webhooks", func(w http.ResponseWriter, r *http.Request) {
@@ -433,6 +581,7 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 				return
 			}
 			statsHandler := handler.NewStatsHandler(queries, eventReader, dlqReader)
+			statsHandler.SetEventStore(s.events)
 			statsHandler.Webhooks(w, r)
 		})
 		r.Get("/stats/dlq", func(w http.ResponseWriter, r *http.Request) {
@@ -453,17 +602,42 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 				return
 			}
 			statsHandler := handler.NewStatsHandler(queries, eventReader, dlqReader)
+			statsHandler.SetEventStore(s.events)
 			statsHandler.DLQ(w, r)
 		})
+		r.Get("/stats/streams", func(w http.ResponseWriter, r *http.Request) {
+			authCtx := middleware.GetAuthContext(r.Context())
+			if authCtx == nil || authCtx.OrgID == "" {
+				handler.WriteJSONPublic(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return
+			}
+			orgClient, err := s.pool.Get(authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "org not connected"})
+				return
+			}
+			eventReader := nats.NewEventReader(orgClient.Stream())
+			dlqReader, err := nats.NewDLQReaderForOrg(orgClient.JetStream(), authCtx.OrgID)
+			if err != nil {
+				handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{"error": "DLQ not available"})
+				return
+			}
+			statsHandler := handler.NewStatsHandler(queries, eventReader, dlqReader)
+			statsHandler.SetEventStore(s.events)
+			statsHandler.Streams(w, r)
+		})
 
 		// Dashboard routes (requires Clerk auth)
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.RequireClerkAuth(s.cfg))
 
-			apiKeyHandler := handler.NewAPIKeyHandler(queries)
+			apiKeyHandler := handler.NewAPIKeyHandler(s.db)
+			apiKeyHandler.SetHub(s.hub)
+			apiKeyHandler.SetAuditLog(s.auditLog)
 			r.Post("/api-keys", apiKeyHandler.Create)
 			r.Get("/api-keys", apiKeyHandler.List)
 			r.Delete("/api-keys/{id}", apiKeyHandler.Revoke)
+			r.Post("/api-keys/{id}/rotate", apiKeyHandler.Rotate)
 
 			projectHandler := handler.NewProjectHandler(queries)
 			r.Post("/projects", projectHandler.Create)
@@ -475,30 +649,106 @@ func (s *Server) routesMultiAccount(r chi.Router, queries *db.Queries) {
 	})
 }
 
+// routesOrgEmit mounts the emit endpoints of multi-account mode. The
+// handler for the caller's org is built per request by orgEmitHandler.
+func (s *Server) routesOrgEmit(r chi.Router, orgEmitHandler func(orgID string) (*handler.EmitHandler, error)) {
+	resolve := func(w http.ResponseWriter, r *http.Request) *handler.EmitHandler {
+		authCtx := middleware.GetAuthContext(r.Context())
+		if authCtx == nil || authCtx.OrgID == "" {
+			handler.WriteJSONPublic(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return nil
+		}
+
+		emitHandler, err := orgEmitHandler(authCtx.OrgID)
+		if err != nil {
+			handler.WriteJSONPublic(w, http.StatusServiceUnavailable, map[string]string{
+				"error": "org not connected",
+			})
+			return nil
+		}
+		return emitHandler
+	}
+	r.With(middleware.RejectDrained(s.pool.IsDrained)).Post("/emit", func(w http.ResponseWriter, r *http.Request) {
+		if emitHandler := resolve(w, r); emitHandler != nil {
+			emitHandler.Emit(w, r)
+		}
+	})
+	r.With(middleware.RejectDrained(s.pool.IsDrained)).Post("/emit/batch", func(w http.ResponseWriter, r *http.Request) {
+		if emitHandler := resolve(w, r); emitHandler != nil {
+			emitHandler.EmitBatch(w, r)
+		}
+	})
+}
+
+// newEmitHandler creates an EmitHandler publishing with publisher, sharing
+// the server's stores and emit rate limiter.
+func (s *Server) newEmitHandler(publisher *nats.Publisher, queries *db.Queries, schemaRegistry *schema.Registry, pipelines *pipeline.Registry) *handler.EmitHandler {
+	emitHandler := handler.NewEmitHandler(publisher, queries, schemaRegistry, s.cfg, s.auditLog)
+	emitHandler.SetEventStore(s.events)
+	emitHandler.SetValidationRecorder(s.validations)
+	emitHandler.SetBlobService(s.blobs)
+	emitHandler.SetOutbox(s.outbox)
+	emitHandler.SetPipelines(pipelines)
+	emitHandler.SetRateLimiter(s.emitLimits)
+	emitHandler.SetKeyRateLimiter(s.rateLimiter)
+	return emitHandler
+}
+
 // routesLegacy sets up routes for legacy single-connection mode (unchanged behavior).
 func (s *Server) routesLegacy(r chi.Router, queries *db.Queries) {
 	publisher := nats.NewPublisher(s.nats.JetStream())
 	schemaRegistry := schema.NewRegistry(queries)
-	emitHandler := handler.NewEmitHandler(publisher, queries, schemaRegistry, s.cfg, s.auditLog)
+	schemaRegistry.SetOnChange(s.pushDisplayConfigs)
+	schemaRegistry.SetRetention(schema.RetentionPolicy{
+		MaxVersions: s.cfg.SchemaMaxVersions,
+		MaxAge:      s.cfg.SchemaVersionMaxAge,
+	})
+	pipelines := pipeline.NewRegistry(pipeline.NewPostgres(queries))
+	emitHandler := s.newEmitHandler(publisher, queries, schemaRegistry, pipelines)
+	s.schedulerWorker.SetPipelines(pipelines)
 
 	consumerMgr := nats.NewConsumerManager(s.nats.Stream())
+	consumerMgr.SetGroupTTL(s.cfg.ConsumerGroupTTL)
+	consumerMgr.SetResumeTTL(s.cfg.ResumeCursorTTL)
 	dlqPublisher := nats.NewDLQPublisher(s.nats.JetStream())
 	subscribeHandler := handler.NewSubscribeHandler(s.hub, consumerMgr, dlqPublisher, queries, s.cfg, s.auditLog)
+	subscribeHandler.SetEmitHandler(emitHandler)
+	subscribeHandler.SetSchemaRegistry(schemaRegistry)
 
 	dlqReader, _ := nats.NewDLQReader(s.nats.JetStream())
 	dlqHandler := handler.NewDLQHandler(dlqReader, publisher)
+	dlqHandler.SetReplayGuard(s.dlqReplays)
 
 	eventReader := nats.NewEventReader(s.nats.Stream())
 	eventsHandler := handler.NewEventsHandler(eventReader, queries)
+	eventsHandler.SetEventStore(s.events)
 
 	webhookHandler := handler.NewWebhookHandler(queries, s.auditLog)
-	apiKeyHandler := handler.NewAPIKeyHandler(queries)
+	webhookHandler.SetSecretBox(s.secrets)
+	webhookHandler.SetRetrySource(func(string) (*nats.EventReader, jetstream.JetStream, error) {
+		return eventReader, s.nats.JetStream(), nil
+	})
+	apiKeyHandler := handler.NewAPIKeyHandler(s.db)
+	apiKeyHandler.SetHub(s.hub)
+	apiKeyHandler.SetAuditLog(s.auditLog)
 	statsHandler := handler.NewStatsHandler(queries, eventReader, dlqReader)
+	statsHandler.SetEventStore(s.events)
 	schedulesHandler := handler.NewSchedulesHandler(queries, s.schedulerWorker)
+	schedulesHandler.SetMaxLeadTime(s.cfg.ScheduleMaxLeadTime)
 	projectHandler := handler.NewProjectHandler(queries)
 
 	schemaHandler := handler.NewSchemaHandler(schemaRegistry)
+	pipelineHandler := handler.NewPipelineHandler(pipelines, s.auditLog)
 	auditHandler := handler.NewAuditHandler(queries)
+	blobHandler := handler.NewBlobHandler(s.blobs)
+	whoamiHandler := handler.NewWhoamiHandler(queries)
+	usageHandler := handler.NewUsageHandler(s.hub, s.cfg)
+	usageHandler.SetStorageSource(s.events, func(string) (*nats.EventReader, *nats.DLQReader, error) {
+		if dlqReader == nil {
+			return nil, nil, fmt.Errorf("DLQ stream not available")
+		}
+		return eventReader, dlqReader, nil
+	})
 
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.UnifiedAuth(queries, s.cfg))
@@ -518,26 +768,40 @@ func (s *Server) routesLegacy(r chi.Router, queries *db.Queries) {
 		r.Use(middleware.RateLimit(s.rateLimiter))
 		r.Use(middleware.UnifiedAuth(queries, s.cfg))
 
+		r.Get("/whoami", whoamiHandler.Whoami)
+		r.Get("/usage", usageHandler.Usage)
+		r.Get("/usage/storage", usageHandler.Storage)
+
 		r.Post("/emit", emitHandler.Emit)
+		r.Post("/emit/batch", emitHandler.EmitBatch)
 		r.Get("/events", eventsHandler.List)
 		r.Get("/events/stats", eventsHandler.Stats)
 		r.Get("/events/{seq}", eventsHandler.Get)
 		r.Get("/events/{id}/deliveries", eventsHandler.Deliveries)
+		r.Get("/topics/tree", eventsHandler.TopicTree)
 
 		r.Post("/webhooks", webhookHandler.Create)
 		r.Get("/webhooks", webhookHandler.List)
 		r.Get("/webhooks/{id}", webhookHandler.Get)
 		r.Put("/webhooks/{id}", webhookHandler.Update)
 		r.Delete("/webhooks/{id}", webhookHandler.Delete)
+		r.Post("/webhooks/{id}/rotate-secret", webhookHandler.RotateSecret)
 		r.Get("/webhooks/{id}/deliveries", webhookHandler.Deliveries)
+		r.Post("/webhooks/{id}/deliveries/{delivery_id}/retry", webhookHandler.RetryDelivery)
 
 		r.Get("/dlq", dlqHandler.List)
+		r.Get("/dlq/stats", dlqHandler.Stats)
 		r.Get("/dlq/{seq}", dlqHandler.Get)
 		r.Post("/dlq/{seq}/replay", dlqHandler.Replay)
 		r.Delete("/dlq/{seq}", dlqHandler.Delete)
+		r.Post("/dlq/replay", dlqHandler.ReplayBatch)
 		r.Post("/dlq/replay-all", dlqHandler.ReplayAll)
+		r.Delete("/dlq", dlqHandler.Purge)
 		r.Delete("/dlq/purge", dlqHandler.Purge)
 
+		r.Post("/blobs", blobHandler.Create)
+		r.Get("/blobs/{id}", blobHandler.Get)
+
 		r.Post("/schedules", schedulesHandler.Create)
 		r.Get("/schedules", schedulesHandler.List)
 		r.Get("/schedules/{id}", schedulesHandler.Get)
@@ -553,7 +817,16 @@ func (s *Server) routesLegacy(r chi.Router, queries *db.Queries) {
 		r.Post("/schemas/{name}/versions", schemaHandler.CreateVersion)
 		r.Get("/schemas/{name}/versions", schemaHandler.ListVersions)
 		r.Get("/schemas/{name}/versions/{version}", schemaHandler.GetVersion)
+		r.Post("/schemas/{name}/versions/{version}/pin", schemaHandler.PinVersion)
+		r.Delete("/schemas/{name}/versions/{version}/pin", schemaHandler.UnpinVersion)
 		r.Post("/schemas/{name}/validate", schemaHandler.Validate)
+		r.Get("/schemas/{name}/stats", schemaHandler.GetStats)
+
+		r.Post("/pipelines", pipelineHandler.Create)
+		r.Get("/pipelines", pipelineHandler.List)
+		r.Get("/pipelines/{name}", pipelineHandler.Get)
+		r.Put("/pipelines/{name}", pipelineHandler.Update)
+		r.Delete("/pipelines/{name}", pipelineHandler.Delete)
 
 		r.Get("/audit", auditHandler.List)
 
@@ -561,6 +834,7 @@ func (s *Server) routesLegacy(r chi.Router, queries *db.Queries) {
 		r.Get("/stats/events", statsHandler.Events)
 		r.Get("/stats/webhooks", statsHandler.Webhooks)
 		r.Get("/stats/dlq", statsHandler.DLQ)
+		r.Get("/stats/streams", statsHandler.Streams)
 		r.Get("/stats/schedules", schedulesHandler.Stats)
 
 		r.Group(func(r chi.Router) {
@@ -569,6 +843,7 @@ func (s *Server) routesLegacy(r chi.Router, queries *db.Queries) {
 			r.Post("/api-keys", apiKeyHandler.Create)
 			r.Get("/api-keys", apiKeyHandler.List)
 			r.Delete("/api-keys/{id}", apiKeyHandler.Revoke)
+			r.Post("/api-keys/{id}/rotate", apiKeyHandler.Rotate)
 
 			r.Post("/projects", projectHandler.Create)
 			r.Get("/projects", projectHandler.List)
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/eventstore"
+	"github.com/filipexyz/notif/internal/handler"
+	"github.com/filipexyz/notif/internal/middleware"
+	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/outbox"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRoutesOrgEmit_SharesRateLimit(t *testing.T) {
+	s := &Server{
+		cfg:        &config.Config{MaxPayloadSize: 1024},
+		pool:       nats.NewClientPool("", nil, nil, nil),
+		events:     eventstore.NewMemory(),
+		outbox:     outbox.NewRelay(outbox.NewMemory(), func(context.Context, *domain.Event) error { return nil }, time.Second),
+		emitLimits: nats.NewEmitRateLimiter(nats.EmitRateLimits{{Pattern: "orders.>", Rate: 1, Burst: 1}}),
+	}
+	r := chi.NewRouter()
+	s.routesOrgEmit(r, func(orgID string) (*handler.EmitHandler, error) {
+		return s.newEmitHandler(nil, nil, nil, nil), nil
+	})
+
+	emit := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/emit", strings.NewReader(`{"topic":"orders.created","data":{}}`))
+		req = req.WithContext(middleware.SetAuthContext(req.Context(), &middleware.AuthContext{OrgID: "org_1", ProjectID: "prj_a"}))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := emit(); w.Code != http.StatusOK {
+		t.Fatalf("first emit: status = %d (%s), want 200", w.Code, w.Body.String())
+	}
+	// The second request gets a new handler, but not a new bucket
+	if w := emit(); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second emit: status = %d (%s), want 429", w.Code, w.Body.String())
+	}
+}
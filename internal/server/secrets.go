@@ -0,0 +1,22 @@
+package server
+
+import (
+	"log/slog"
+
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/filipexyz/notif/internal/security"
+)
+
+// newSecretBox builds the encrypter for credentials stored at rest. The key
+// was validated by config.Load; without one, values are stored in plaintext.
+func newSecretBox(cfg *config.Config) *security.SecretBox {
+	box, err := cfg.SecretBox()
+	if err != nil {
+		slog.Error("invalid SECRETS_ENCRYPTION_KEY, storing secrets unencrypted", "error", err)
+		return nil
+	}
+	if box == nil {
+		slog.Warn("SECRETS_ENCRYPTION_KEY not set, webhook credentials are stored unencrypted")
+	}
+	return box
+}
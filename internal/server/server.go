@@ -11,35 +11,55 @@ import (
 	"github.com/clerk/clerk-sdk-go/v2"
 	"github.com/filipexyz/notif/internal/accounts"
 	"github.com/filipexyz/notif/internal/audit"
+	"github.com/filipexyz/notif/internal/blob"
 	"github.com/filipexyz/notif/internal/config"
 	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/eventstore"
+	"github.com/filipexyz/notif/internal/handler"
 	"github.com/filipexyz/notif/internal/middleware"
 	"github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/outbox"
 	"github.com/filipexyz/notif/internal/scheduler"
+	"github.com/filipexyz/notif/internal/schema"
+	"github.com/filipexyz/notif/internal/security"
 	"github.com/filipexyz/notif/internal/terminal"
 	"github.com/filipexyz/notif/internal/webhook"
 	"github.com/filipexyz/notif/internal/websocket"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Server is the HTTP server.
 type Server struct {
-	cfg             *config.Config
-	db              *pgxpool.Pool
-	nats            *nats.Client      // legacy single-connection mode
-	pool            *nats.ClientPool  // multi-account mode
-	accountMgr      *accounts.Manager // multi-account mode
-	hub             *websocket.Hub
-	terminalManager *terminal.Manager
-	schedulerWorker *scheduler.Worker
-	rateLimiter     *middleware.RateLimiter
-	auditLog        *audit.Logger
-	server          *http.Server
-	webhookCtx      context.Context    // lifetime context for webhook workers
-	webhookCancel   context.CancelFunc
-	orgWorkerMu     sync.Mutex                    // guards orgWorkerCancels
+	cfg              *config.Config
+	db               *pgxpool.Pool
+	nats             *nats.Client      // legacy single-connection mode
+	pool             *nats.ClientPool  // multi-account mode
+	accountMgr       *accounts.Manager // multi-account mode
+	hub              *websocket.Hub
+	terminalManager  *terminal.Manager
+	schedulerWorker  *scheduler.Worker
+	rateLimiter      *middleware.RateLimiter
+	auditLog         *audit.Logger
+	validations      *schema.ValidationRecorder
+	blobs            *blob.Service         // nil when BLOB_STORE is unset
+	secrets          *security.SecretBox   // nil when SECRETS_ENCRYPTION_KEY is unset
+	events           eventstore.Store      // event metadata backend
+	outbox           *outbox.Relay         // nil unless EMIT_OUTBOX is set
+	fanout           *nats.FanoutGate      // nil unless FANOUT_LIMITS is set
+	emitLimits       *nats.EmitRateLimiter // nil unless EMIT_RATE_LIMITS is set
+	metrics          *prometheus.Registry
+	dlqReplays       *handler.DLQReplayGuard
+	server           *http.Server
+	metricsServer    *http.Server    // nil unless METRICS_PORT is set
+	webhookCtx       context.Context // lifetime context for webhook workers
+	webhookCancel    context.CancelFunc
+	orgWorkerMu      sync.Mutex                    // guards orgWorkerCancels, orgWorkers
 	orgWorkerCancels map[string]context.CancelFunc // per-org webhook worker cancellation
-	schedulerCancel context.CancelFunc
+	orgWorkers       map[string]*webhook.Worker    // per-org webhook workers, for pausing
+	schedulerCancel  context.CancelFunc
+	outboxCancel     context.CancelFunc
 }
 
 // New creates a new Server in legacy single-connection mode.
@@ -68,12 +88,22 @@ func New(cfg *config.Config, pool *pgxpool.Pool, nc *nats.Client) *Server {
 		schedulerWorker: schedWorker,
 		rateLimiter:     rateLimiter,
 		auditLog:        auditLog,
+		validations:     schema.NewValidationRecorder(queries, 256),
+		secrets:         newSecretBox(cfg),
+		fanout:          newFanoutGate(cfg, auditLog),
+		dlqReplays:      handler.NewDLQReplayGuard(handler.DefaultDLQReplayWindow),
+	}
+	hub.SetFanoutGate(s.fanout)
+	if cfg.EmitOutbox {
+		s.outbox = outbox.NewRelay(outbox.NewPostgres(pool), publisher.Publish, cfg.OutboxRelayInterval)
 	}
 
+	s.setupMetrics()
 	s.server = &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: s.routes(),
 	}
+	s.startOutbox()
 
 	// Start webhook worker
 	webhookCtx, webhookCancel := context.WithCancel(context.Background())
@@ -81,6 +111,9 @@ func New(cfg *config.Config, pool *pgxpool.Pool, nc *nats.Client) *Server {
 
 	dlqPublisher := nats.NewDLQPublisher(nc.JetStream())
 	worker := webhook.NewWorker(queries, nc.Stream(), nc.JetStream(), dlqPublisher)
+	worker.SetDLQPolicies(cfg.DLQPolicies)
+	worker.SetFanoutGate(s.fanout)
+	worker.SetSecretBox(s.secrets)
 	go func() {
 		if err := worker.Start(webhookCtx); err != nil && webhookCtx.Err() == nil {
 			slog.Error("webhook worker error", "error", err)
@@ -117,12 +150,22 @@ func NewWithPool(cfg *config.Config, dbPool *pgxpool.Pool, pool *nats.ClientPool
 		terminalManager: termMgr,
 		rateLimiter:     rateLimiter,
 		auditLog:        auditLog,
+		validations:     schema.NewValidationRecorder(queries, 256),
+		secrets:         newSecretBox(cfg),
+		fanout:          newFanoutGate(cfg, auditLog),
+		dlqReplays:      handler.NewDLQReplayGuard(handler.DefaultDLQReplayWindow),
+	}
+	hub.SetFanoutGate(s.fanout)
+	if cfg.EmitOutbox {
+		s.outbox = outbox.NewRelay(outbox.NewPostgres(dbPool), s.publishToOrg, cfg.OutboxRelayInterval)
 	}
 
+	s.setupMetrics()
 	s.server = &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: s.routes(),
 	}
+	s.startOutbox()
 
 	// Start webhook workers for each org
 	// NOTE: Scheduler is disabled in multi-account mode until per-org scheduling is implemented.
@@ -130,6 +173,7 @@ func NewWithPool(cfg *config.Config, dbPool *pgxpool.Pool, pool *nats.ClientPool
 	s.webhookCtx = webhookCtx
 	s.webhookCancel = webhookCancel
 	s.orgWorkerCancels = make(map[string]context.CancelFunc)
+	s.orgWorkers = make(map[string]*webhook.Worker)
 
 	for _, orgID := range pool.OrgIDs() {
 		s.startOrgWorker(orgID, queries)
@@ -148,12 +192,20 @@ func (s *Server) startOrgWorker(orgID string, queries *db.Queries) {
 
 	orgCtx, orgCancel := context.WithCancel(s.webhookCtx)
 
+	dlqPublisher := nats.NewDLQPublisher(orgClient.JetStream())
+	worker := webhook.NewWorker(queries, orgClient.Stream(), orgClient.JetStream(), dlqPublisher)
+	worker.SetDLQPolicies(s.cfg.DLQPolicies)
+	worker.SetFanoutGate(s.fanout)
+	worker.SetSecretBox(s.secrets)
+	if s.pool.IsDrained(orgID) {
+		worker.Pause()
+	}
+
 	s.orgWorkerMu.Lock()
 	s.orgWorkerCancels[orgID] = orgCancel
+	s.orgWorkers[orgID] = worker
 	s.orgWorkerMu.Unlock()
 
-	dlqPublisher := nats.NewDLQPublisher(orgClient.JetStream())
-	worker := webhook.NewWorker(queries, orgClient.Stream(), orgClient.JetStream(), dlqPublisher)
 	go func(oid string) {
 		if err := worker.Start(orgCtx); err != nil && orgCtx.Err() == nil {
 			slog.Error("webhook worker error", "org_id", oid, "error", err)
@@ -179,6 +231,7 @@ func (s *Server) StopOrgWebhookWorker(orgID string) {
 	cancel, ok := s.orgWorkerCancels[orgID]
 	if ok {
 		delete(s.orgWorkerCancels, orgID)
+		delete(s.orgWorkers, orgID)
 	}
 	s.orgWorkerMu.Unlock()
 
@@ -188,6 +241,66 @@ func (s *Server) StopOrgWebhookWorker(orgID string) {
 	}
 }
 
+// RestartOrgWebhookWorker restarts an org's webhook worker, recreating its
+// consumers. Called by OrgHandler.MigrateStream after the org's stream was
+// recreated.
+func (s *Server) RestartOrgWebhookWorker(orgID string) {
+	s.StopOrgWebhookWorker(orgID)
+	s.StartOrgWebhookWorker(orgID)
+}
+
+// startOutbox runs the outbox relay, when EMIT_OUTBOX is set.
+func (s *Server) startOutbox() {
+	if s.outbox == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.outboxCancel = cancel
+	go s.outbox.Start(ctx)
+}
+
+// publishToOrg publishes an outbox event through its org's NATS account.
+func (s *Server) publishToOrg(ctx context.Context, event *domain.Event) error {
+	orgClient, err := s.pool.Get(event.OrgID)
+	if err != nil {
+		return err
+	}
+	return nats.NewPublisher(orgClient.JetStream()).Publish(ctx, event)
+}
+
+// pushDisplayConfigs refreshes the display configs of a project's
+// subscribers after one of its schemas changed.
+func (s *Server) pushDisplayConfigs(projectID string) {
+	go s.hub.PushDisplayConfigs(context.Background(), projectID)
+}
+
+// PauseOrgDeliveries stops delivering new events to a drained org's
+// WebSocket subscribers and webhooks. Called by OrgHandler.Drain.
+func (s *Server) PauseOrgDeliveries(orgID string) {
+	s.hub.DrainOrg(orgID)
+
+	s.orgWorkerMu.Lock()
+	worker := s.orgWorkers[orgID]
+	s.orgWorkerMu.Unlock()
+	if worker != nil {
+		worker.Pause()
+	}
+}
+
+// ResumeOrgDeliveries undoes PauseOrgDeliveries. Called by OrgHandler.Resume.
+func (s *Server) ResumeOrgDeliveries(orgID string) {
+	s.hub.ResumeOrg(orgID)
+
+	s.orgWorkerMu.Lock()
+	worker := s.orgWorkers[orgID]
+	s.orgWorkerMu.Unlock()
+	if worker != nil {
+		if err := worker.Resume(); err != nil {
+			slog.Error("failed to resume webhook worker", "org_id", orgID, "error", err)
+		}
+	}
+}
+
 func initClerk(cfg *config.Config) {
 	if cfg.IsSelfHosted() {
 		slog.Info("Running in self-hosted mode",
@@ -207,11 +320,13 @@ func initClerk(cfg *config.Config) {
 
 // Start starts the HTTP server.
 func (s *Server) Start() error {
+	s.startMetricsServer()
 	return s.server.ListenAndServe()
 }
 
 // Serve starts the HTTP server on the given listener.
 func (s *Server) Serve(l net.Listener) error {
+	s.startMetricsServer()
 	return s.server.Serve(l)
 }
 
@@ -223,14 +338,26 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if s.schedulerCancel != nil {
 		s.schedulerCancel()
 	}
+	if s.outboxCancel != nil {
+		s.outboxCancel()
+	}
 	if s.rateLimiter != nil {
 		s.rateLimiter.Stop()
 	}
+	// WebSocket connections are hijacked, so http.Server.Shutdown doesn't
+	// see them: tell clients to reconnect elsewhere before it returns
+	s.hub.CloseAll()
 	// Shutdown HTTP server first (drains inflight requests),
 	// then close audit logger (safe: no more Log() calls after server stops).
 	err := s.server.Shutdown(ctx)
+	if s.metricsServer != nil {
+		s.metricsServer.Shutdown(ctx)
+	}
 	if s.auditLog != nil {
 		s.auditLog.Close()
 	}
+	if s.validations != nil {
+		s.validations.Close()
+	}
 	return err
 }
@@ -0,0 +1,50 @@
+// Package tracing carries W3C trace context from emit through delivery.
+//
+// Spans are started on the global OpenTelemetry tracer provider, which
+// records nothing until Setup registers an OTLP exporter from the
+// environment. The traceparent is propagated either way, so receivers can
+// stitch traces across services using notif.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceparentHeader is the W3C trace context header, used on HTTP requests
+// and on event messages in NATS.
+const TraceparentHeader = "traceparent"
+
+// propagator is always W3C trace context, whatever global propagator is set.
+var propagator = propagation.TraceContext{}
+
+// Tracer returns the tracer notif's spans are started on.
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/filipexyz/notif")
+}
+
+// Extract returns ctx carrying the trace context of an incoming request.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(h))
+}
+
+// ContextWithTraceparent returns ctx carrying the remote span described by
+// traceparent. Empty or malformed values leave ctx unchanged.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{TraceparentHeader: traceparent})
+}
+
+// Traceparent returns the span in ctx as a traceparent value, or "" when
+// ctx carries no valid span.
+func Traceparent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier[TraceparentHeader]
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTraceparent_RoundTrip(t *testing.T) {
+	ctx := ContextWithTraceparent(context.Background(), testTraceparent)
+	if got := Traceparent(ctx); got != testTraceparent {
+		t.Errorf("Traceparent() = %q, want %q", got, testTraceparent)
+	}
+}
+
+func TestTraceparent_Empty(t *testing.T) {
+	for _, tp := range []string{"", "not-a-traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+		if got := Traceparent(ContextWithTraceparent(context.Background(), tp)); got != "" {
+			t.Errorf("%q: expected no traceparent, got %q", tp, got)
+		}
+	}
+}
+
+func TestExtract_SpanKeepsTrace(t *testing.T) {
+	h := http.Header{}
+	h.Set(TraceparentHeader, testTraceparent)
+
+	// Without a registered provider the span continues the caller's trace
+	ctx, span := Tracer().Start(Extract(context.Background(), h), "test")
+	defer span.End()
+
+	if got := Traceparent(ctx); !strings.HasPrefix(got, testTraceparent[:35]) {
+		t.Errorf("expected trace %s to continue, got %q", testTraceparent[3:35], got)
+	}
+}
+
+func TestSetup_WithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	before := otel.GetTracerProvider()
+
+	shutdown, err := Setup(context.Background())
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if otel.GetTracerProvider() != before {
+		t.Error("expected no tracer provider registered without an endpoint")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
+
+func TestSetup_WithEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:4318")
+	before := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(before) })
+
+	shutdown, err := Setup(context.Background())
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); !ok {
+		t.Errorf("expected the SDK tracer provider registered, got %T", otel.GetTracerProvider())
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
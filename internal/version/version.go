@@ -0,0 +1,31 @@
+// Package version reports the build of the running server.
+package version
+
+import "runtime"
+
+// Set at build time via ldflags, e.g.
+//
+//	-X github.com/filipexyz/notif/internal/version.Version=1.2.3
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build info of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
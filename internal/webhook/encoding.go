@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Body encodings a webhook can ask for.
+const (
+	BodyEncodingJSON = "json"
+	BodyEncodingForm = "form"
+)
+
+// Payload modes: envelope wraps the event data with its metadata; raw sends
+// the data alone as the body, with the metadata in X-Notif-* headers.
+const (
+	PayloadModeEnvelope = "envelope"
+	PayloadModeRaw      = "raw"
+)
+
+// ValidPayloadMode reports whether mode is supported.
+func ValidPayloadMode(mode string) bool {
+	return mode == PayloadModeEnvelope || mode == PayloadModeRaw
+}
+
+// DefaultContentType returns the Content-Type sent for a body encoding when
+// the webhook doesn't set its own.
+func DefaultContentType(encoding string) string {
+	if encoding == BodyEncodingForm {
+		return "application/x-www-form-urlencoded"
+	}
+	return "application/json"
+}
+
+// ValidBodyEncoding reports whether encoding is supported.
+func ValidBodyEncoding(encoding string) bool {
+	return encoding == BodyEncodingJSON || encoding == BodyEncodingForm
+}
+
+// encodePayload serializes the payload for the webhook's payload mode and
+// body encoding. Raw bodies are the event data as-is. Form bodies carry the
+// envelope fields as form values, with data (and attachments, if any) as
+// JSON strings.
+func encodePayload(payload WebhookPayload, mode, encoding string) ([]byte, error) {
+	if mode == PayloadModeRaw {
+		return payload.Data, nil
+	}
+	if encoding != BodyEncodingForm {
+		return json.Marshal(payload)
+	}
+
+	form := url.Values{}
+	form.Set("envelope_version", strconv.Itoa(payload.EnvelopeVersion))
+	form.Set("id", payload.ID)
+	form.Set("topic", payload.Topic)
+	form.Set("data", string(payload.Data))
+	form.Set("timestamp", payload.Timestamp.Format(time.RFC3339Nano))
+	if len(payload.Attachments) > 0 {
+		attachments, err := json.Marshal(payload.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		form.Set("attachments", string(attachments))
+	}
+	return []byte(form.Encode()), nil
+}
@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/filipexyz/notif/internal/security"
+)
+
+const (
+	// RedactedHeaderValue replaces secret header values in API responses.
+	// Sent back on update, it keeps the stored value.
+	RedactedHeaderValue = "[redacted]"
+
+	// MaxCustomHeaders caps the static headers on one webhook.
+	MaxCustomHeaders = 20
+	// maxHeaderValueLen caps a single custom header value.
+	maxHeaderValueLen = 4096
+)
+
+// reservedHeaders are set by deliver and can't be overridden. All
+// X-Notif-* headers are reserved too.
+var reservedHeaders = map[string]bool{
+	"Content-Type":   true,
+	"Content-Length": true,
+	"Host":           true,
+}
+
+// secretHeaderHints mark header names whose values are credentials.
+var secretHeaderHints = []string{"auth", "token", "secret", "key", "password", "signature", "credential", "cookie"}
+
+// IsReservedHeader reports whether name is set by notif on every delivery.
+func IsReservedHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	return reservedHeaders[name] || strings.HasPrefix(name, "X-Notif-")
+}
+
+// IsSecretHeader reports whether name looks like it carries a credential,
+// e.g. Authorization or X-Api-Key. Such values are encrypted at rest and
+// redacted in responses.
+func IsSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, hint := range secretHeaderHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateHeaders checks custom header names and values.
+func ValidateHeaders(headers map[string]string) error {
+	if len(headers) > MaxCustomHeaders {
+		return fmt.Errorf("at most %d headers allowed", MaxCustomHeaders)
+	}
+	for name, value := range headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if IsReservedHeader(name) {
+			return fmt.Errorf("header %q is reserved", http.CanonicalHeaderKey(name))
+		}
+		if len(value) > maxHeaderValueLen {
+			return fmt.Errorf("header %q is longer than %d bytes", name, maxHeaderValueLen)
+		}
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("header %q contains invalid characters", name)
+		}
+	}
+	return nil
+}
+
+// validHeaderName reports whether name is an RFC 7230 token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SealHeaders encodes headers for storage, encrypting secret-looking
+// values with box. A value of RedactedHeaderValue keeps the one stored in
+// previous, so a GET response can be sent back unchanged.
+func SealHeaders(headers map[string]string, previous []byte, box *security.SecretBox) ([]byte, error) {
+	canonical := make(map[string]string, len(headers))
+	for name, value := range headers {
+		canonical[http.CanonicalHeaderKey(name)] = value
+	}
+	return sealValues("header", canonical, previous, box, IsSecretHeader)
+}
+
+// sealValues encodes values as a JSON object for storage, encrypting those
+// whose key passes secret. RedactedHeaderValue keeps the stored value.
+func sealValues(kind string, values map[string]string, previous []byte, box *security.SecretBox, secret func(string) bool) ([]byte, error) {
+	stored := decodeHeaders(previous)
+	sealed := make(map[string]string, len(values))
+	for key, value := range values {
+		if value == RedactedHeaderValue {
+			prev, ok := stored[key]
+			if !ok {
+				return nil, fmt.Errorf("%s %q has no stored value to keep", kind, key)
+			}
+			sealed[key] = prev
+			continue
+		}
+		if secret(key) {
+			var err error
+			if value, err = box.Seal(value); err != nil {
+				return nil, fmt.Errorf("encrypt %s %q: %w", kind, key, err)
+			}
+		}
+		sealed[key] = value
+	}
+	return json.Marshal(sealed)
+}
+
+// RedactHeaders decodes stored headers for API responses, hiding secret
+// values.
+func RedactHeaders(stored []byte) map[string]string {
+	headers := decodeHeaders(stored)
+	for name, value := range headers {
+		if IsSecretHeader(name) || security.IsSealed(value) {
+			headers[name] = RedactedHeaderValue
+		}
+	}
+	return headers
+}
+
+// openHeaders decodes stored headers for delivery, decrypting sealed
+// values.
+func openHeaders(stored []byte, box *security.SecretBox) (map[string]string, error) {
+	headers := decodeHeaders(stored)
+	for name, value := range headers {
+		plain, err := box.Open(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %q: %w", name, err)
+		}
+		headers[name] = plain
+	}
+	return headers, nil
+}
+
+func decodeHeaders(stored []byte) map[string]string {
+	var headers map[string]string
+	if len(stored) > 0 {
+		json.Unmarshal(stored, &headers)
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
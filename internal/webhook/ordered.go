@@ -0,0 +1,270 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	notifnats "github.com/filipexyz/notif/internal/nats"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// orderedConsumerPrefix names the durable consumer each ordered
+	// webhook is delivered from, followed by the webhook ID.
+	orderedConsumerPrefix = "webhook-ordered-"
+
+	// orderedAckWait bounds one delivery attempt to an ordered webhook,
+	// failover URLs included, before JetStream hands the event out again.
+	orderedAckWait = 5 * time.Minute
+)
+
+// Ordered webhooks are delivered from a durable JetStream consumer of their
+// own with MaxAckPending=1: the next event isn't handed out until the
+// current one is acked, and a failing event is naked with the retry delay,
+// so it stays pending and nothing behind it overtakes it. The queue
+// survives restarts, and every attempt reads the webhook's current config.
+
+func orderedConsumerName(webhookID pgtype.UUID) string {
+	return orderedConsumerPrefix + pgUUIDToString(webhookID)
+}
+
+// orderedSubjects are the stream subjects wh's topics cover in its org.
+func orderedSubjects(wh *db.Webhook) []string {
+	topics := notifnats.NormalizeTopics(wh.Topics)
+	subjects := make([]string, len(topics))
+	for i, topic := range topics {
+		subjects[i] = "events." + wh.OrgID.String + ".*." + topic
+	}
+	return subjects
+}
+
+// orderedConsumerConfig is the consumer config for wh. A new consumer
+// starts at startSeq, or with the next event published when it's 0.
+func orderedConsumerConfig(wh *db.Webhook, startSeq uint64) jetstream.ConsumerConfig {
+	cfg := jetstream.ConsumerConfig{
+		Durable:        orderedConsumerName(wh.ID),
+		FilterSubjects: orderedSubjects(wh),
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        orderedAckWait,
+		MaxAckPending:  1,
+		MaxDeliver:     -1, // giveUpReason decides, as for retry jobs
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+	}
+	if startSeq > 0 {
+		cfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = startSeq
+	}
+	return cfg
+}
+
+// ensureOrdered makes sure wh's ordered consumer exists, follows wh's
+// current topics and is being consumed. A consumer created here starts at
+// startSeq, the event that found it missing.
+func (w *Worker) ensureOrdered(ctx context.Context, wh *db.Webhook, startSeq uint64) error {
+	name := orderedConsumerName(wh.ID)
+	cfg := orderedConsumerConfig(wh, startSeq)
+	subjects := strings.Join(cfg.FilterSubjects, ",")
+
+	w.orderedMu.Lock()
+	defer w.orderedMu.Unlock()
+	if w.ordered[name] == subjects {
+		return nil
+	}
+
+	existing, err := w.stream.Consumer(ctx, name)
+	var consumer jetstream.Consumer
+	switch {
+	case err == nil:
+		// Keep its position; only the topics may have changed
+		info := existing.CachedInfo()
+		cfg.DeliverPolicy = info.Config.DeliverPolicy
+		cfg.OptStartSeq = info.Config.OptStartSeq
+		cfg.OptStartTime = info.Config.OptStartTime
+		consumer, err = w.stream.UpdateConsumer(ctx, cfg)
+	case errors.Is(err, jetstream.ErrConsumerNotFound):
+		consumer, err = w.stream.CreateConsumer(ctx, cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("ordered consumer %s: %w", name, err)
+	}
+
+	if _, consuming := w.ordered[name]; !consuming {
+		webhookID := wh.ID
+		if err := w.consume(consumer, func(msg jetstream.Msg) {
+			w.processOrdered(ctx, webhookID, msg)
+		}); err != nil {
+			return fmt.Errorf("consume %s: %w", name, err)
+		}
+	}
+	if w.ordered == nil {
+		w.ordered = make(map[string]string)
+	}
+	w.ordered[name] = subjects
+	return nil
+}
+
+// startOrdered resumes the ordered consumers left from before a restart
+// and deletes those whose webhook is gone, disabled or no longer ordered.
+func (w *Worker) startOrdered(ctx context.Context) {
+	lister := w.stream.ConsumerNames(ctx)
+	var names []string
+	for name := range lister.Name() {
+		if strings.HasPrefix(name, orderedConsumerPrefix) {
+			names = append(names, name)
+		}
+	}
+	if err := lister.Err(); err != nil {
+		slog.Error("webhook: failed to list ordered consumers", "error", err)
+	}
+
+	for _, name := range names {
+		webhookID := parseUUID(strings.TrimPrefix(name, orderedConsumerPrefix))
+		wh, err := w.getWebhook(ctx, webhookID)
+		switch {
+		case err == nil && wh.Enabled && wh.Ordered:
+			if err := w.ensureOrdered(ctx, &wh, 0); err != nil {
+				slog.Error("webhook: failed to resume ordered consumer", "error", err, "consumer", name)
+			}
+		case err == nil || errors.Is(err, pgx.ErrNoRows):
+			w.dropOrdered(webhookID)
+		default:
+			slog.Error("webhook: failed to get webhook for ordered consumer", "error", err, "consumer", name)
+		}
+	}
+}
+
+// dropOrdered stops and deletes the ordered consumer of a webhook that was
+// deleted, disabled or made unordered, discarding the events it still held.
+// Enabling the webhook again starts a new queue with the next event.
+func (w *Worker) dropOrdered(webhookID pgtype.UUID) {
+	name := orderedConsumerName(webhookID)
+	w.orderedMu.Lock()
+	delete(w.ordered, name)
+	w.orderedMu.Unlock()
+	w.unconsume(name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := w.stream.DeleteConsumer(ctx, name); err != nil && !errors.Is(err, jetstream.ErrConsumerNotFound) {
+		slog.Warn("webhook: failed to delete ordered consumer", "error", err, "consumer", name)
+	}
+}
+
+// getWebhook reads a webhook's current config.
+func (w *Worker) getWebhook(ctx context.Context, id pgtype.UUID) (db.Webhook, error) {
+	if w.lookupWebhook != nil {
+		return w.lookupWebhook(ctx, id)
+	}
+	return w.queries.GetWebhook(ctx, id)
+}
+
+// processOrdered makes one delivery attempt of an event to an ordered
+// webhook. A failed attempt naks the event with the retry delay; it stays
+// pending, so the webhook's later events wait behind it until it succeeds
+// or is given up on.
+func (w *Worker) processOrdered(ctx context.Context, webhookID pgtype.UUID, msg jetstream.Msg) {
+	event, err := notifnats.EventFromMsg(msg)
+	if err != nil {
+		slog.Error("webhook: failed to unmarshal event", "error", err)
+		msg.Ack()
+		return
+	}
+	meta, err := msg.Metadata()
+	if err != nil {
+		slog.Error("webhook: failed to read message metadata", "error", err)
+		msg.Ack()
+		return
+	}
+	attempt := int(meta.NumDelivered)
+
+	// The webhook may have changed since the event was queued
+	wh, err := w.getWebhook(ctx, webhookID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		msg.Ack()
+		w.dropOrdered(webhookID)
+		return
+	case err != nil:
+		slog.Error("webhook: failed to get webhook", "error", err, "webhook_id", pgUUIDToString(webhookID))
+		msg.NakWithDelay(time.Minute)
+		return
+	case !wh.Enabled || !wh.Ordered:
+		msg.Ack()
+		w.dropOrdered(webhookID)
+		return
+	}
+	if !matchesTopic(wh.Topics, event.Topic) {
+		msg.Ack()
+		return
+	}
+	if attempt == 1 && !w.fanout.Admit(ctx, event, "webhook:"+pgUUIDToString(wh.ID), notifnats.FanoutPriorityHigh) {
+		msg.Ack()
+		return
+	}
+
+	deliveryID, firstAttemptAt := w.orderedDelivery(ctx, &wh, event.ID, event.Topic, attempt)
+
+	errMsg := w.deliver(ctx, &wh, event)
+	if errMsg == "" {
+		w.updateDeliverySuccess(ctx, deliveryID)
+		w.recordEventDelivery(ctx, wh.ID, event.ID, "acked", int32(attempt), firstAttemptAt)
+		slog.Debug("webhook: delivered ordered event", "event_id", event.ID, "webhook_id", pgUUIDToString(wh.ID), "attempt", attempt)
+		msg.Ack()
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down: the attempt didn't really happen
+		msg.Nak()
+		return
+	}
+	w.updateDeliveryFailed(ctx, deliveryID, int32(attempt), errMsg)
+
+	job := newRetryJob(&wh, event, attempt, errMsg, pgUUIDToString(deliveryID))
+	if !firstAttemptAt.IsZero() {
+		job.FirstAttemptAt = firstAttemptAt
+	}
+	sched := scheduleFor(&wh)
+	policy := w.dlqPolicies.For(event.Topic)
+	if reason := giveUpReason(job, sched, policy.AttemptLimit(sched.maxRetries), retryBudget(&wh), time.Now()); reason != "" {
+		w.giveUp(ctx, job, policy, reason)
+		msg.Ack()
+		return
+	}
+	msg.NakWithDelay(sched.delay(attempt + 1))
+}
+
+// orderedDelivery returns the delivery record of an ordered event and when
+// its first attempt was made, zero if this is it. The record is created on
+// the first attempt and found again on later ones.
+func (w *Worker) orderedDelivery(ctx context.Context, wh *db.Webhook, eventID, topic string, attempt int) (pgtype.UUID, time.Time) {
+	if w.queries == nil {
+		return pgtype.UUID{}, time.Time{}
+	}
+	if attempt > 1 {
+		deliveries, err := w.queries.GetDeliveriesByEventID(ctx, eventID)
+		if err != nil {
+			slog.Warn("webhook: failed to get delivery records", "error", err, "event_id", eventID)
+		}
+		for _, d := range deliveries {
+			if d.WebhookID == wh.ID {
+				return d.ID, d.CreatedAt.Time
+			}
+		}
+	}
+	delivery, err := w.queries.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+		WebhookID: wh.ID,
+		EventID:   eventID,
+		Topic:     topic,
+	})
+	if err != nil {
+		slog.Error("webhook: failed to create delivery record", "error", err)
+	}
+	return delivery.ID, time.Time{}
+}
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+)
+
+// MaxPolicyRetries caps max_retries in a webhook's retry policy.
+const MaxPolicyRetries = 20
+
+// RetryPolicy overrides the default retry schedule of one webhook. A zero
+// MaxRetries keeps the default attempt count and an empty Backoff keeps the
+// default delays. Backoff[i] is waited before retry i+1; retries past the
+// end of the list reuse its last delay.
+type RetryPolicy struct {
+	MaxRetries int      `json:"max_retries,omitempty"`
+	Backoff    []string `json:"backoff,omitempty"`
+}
+
+// IsZero reports whether the policy leaves the default schedule in place.
+func (p RetryPolicy) IsZero() bool {
+	return p.MaxRetries == 0 && len(p.Backoff) == 0
+}
+
+// ValidateRetryPolicy checks that max_retries is within 1..MaxPolicyRetries
+// when set and that every backoff entry is a positive Go duration.
+func ValidateRetryPolicy(p RetryPolicy) error {
+	if p.MaxRetries != 0 && (p.MaxRetries < 1 || p.MaxRetries > MaxPolicyRetries) {
+		return fmt.Errorf("max_retries must be between 1 and %d", MaxPolicyRetries)
+	}
+	if len(p.Backoff) > MaxPolicyRetries {
+		return fmt.Errorf("at most %d backoff delays allowed", MaxPolicyRetries)
+	}
+	for _, s := range p.Backoff {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid backoff %q: use a duration like 30s or 5m", s)
+		}
+		if d <= 0 {
+			return fmt.Errorf("backoff %q must be positive", s)
+		}
+	}
+	return nil
+}
+
+// EncodeRetryPolicy encodes a validated policy for storage.
+func EncodeRetryPolicy(p RetryPolicy) []byte {
+	data, _ := json.Marshal(p)
+	return data
+}
+
+// DecodeRetryPolicy decodes a stored policy. Unset or unreadable policies
+// decode to the zero policy.
+func DecodeRetryPolicy(stored []byte) RetryPolicy {
+	var p RetryPolicy
+	if len(stored) > 0 {
+		_ = json.Unmarshal(stored, &p)
+	}
+	return p
+}
+
+// retrySchedule is the attempt limit and backoff a webhook's failed
+// deliveries follow.
+type retrySchedule struct {
+	maxRetries int
+	delays     []time.Duration
+}
+
+// scheduleFor returns wh's retry schedule, filling whatever its policy
+// leaves unset from maxRetries and retryDelays.
+func scheduleFor(wh *db.Webhook) retrySchedule {
+	p := DecodeRetryPolicy(wh.RetryPolicy)
+	sched := retrySchedule{maxRetries: maxRetries, delays: retryDelays}
+	if p.MaxRetries > 0 {
+		sched.maxRetries = p.MaxRetries
+	}
+	if len(p.Backoff) > 0 {
+		delays := make([]time.Duration, 0, len(p.Backoff))
+		for _, s := range p.Backoff {
+			if d, err := time.ParseDuration(s); err == nil && d > 0 {
+				delays = append(delays, d)
+			}
+		}
+		if len(delays) > 0 {
+			sched.delays = delays
+		}
+	}
+	return sched
+}
+
+// delay returns the backoff before the given attempt number.
+func (s retrySchedule) delay(attempt int) time.Duration {
+	if attempt-1 < len(s.delays) {
+		return s.delays[attempt-1]
+	}
+	return s.delays[len(s.delays)-1]
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/security"
+)
+
+// MaxTenantSecrets caps the per-tenant signing secrets on one webhook.
+const MaxTenantSecrets = 1000
+
+// ValidateTenantSecrets checks a webhook's tenant field, a dot-separated
+// path into the event data such as "tenant" or "customer.org", and its
+// tenant→secret map.
+func ValidateTenantSecrets(field string, secrets map[string]string) error {
+	if len(secrets) > 0 && field == "" {
+		return fmt.Errorf("tenant_secrets requires tenant_field")
+	}
+	if field != "" {
+		for _, segment := range strings.Split(field, ".") {
+			if segment == "" {
+				return fmt.Errorf("invalid tenant_field %q", field)
+			}
+		}
+	}
+	if len(secrets) > MaxTenantSecrets {
+		return fmt.Errorf("at most %d tenant secrets allowed", MaxTenantSecrets)
+	}
+	for tenant, secret := range secrets {
+		if tenant == "" || secret == "" {
+			return fmt.Errorf("tenant secrets need a tenant and a secret")
+		}
+	}
+	return nil
+}
+
+// SealTenantSecrets encodes tenant secrets for storage, encrypting every
+// value with box. RedactedHeaderValue keeps a tenant's stored secret.
+func SealTenantSecrets(secrets map[string]string, previous []byte, box *security.SecretBox) ([]byte, error) {
+	return sealValues("tenant", secrets, previous, box, func(string) bool { return true })
+}
+
+// RedactTenantSecrets lists the tenants with a secret for API responses,
+// hiding the secrets.
+func RedactTenantSecrets(stored []byte) map[string]string {
+	secrets := decodeHeaders(stored)
+	for tenant := range secrets {
+		secrets[tenant] = RedactedHeaderValue
+	}
+	return secrets
+}
+
+// signingSecret returns the secret event is signed with: the secret of the
+// event's tenant when the webhook maps tenants and has one for it, else the
+// webhook's own secret. tenant reports whether a tenant secret was chosen.
+func (w *Worker) signingSecret(wh *db.Webhook, event *domain.Event) (secret string, tenant bool, err error) {
+	if wh.TenantField == "" {
+		return wh.Secret, false, nil
+	}
+	id, ok := tenantOf(event.Data, wh.TenantField)
+	if !ok {
+		return wh.Secret, false, nil
+	}
+	sealed, ok := decodeHeaders(wh.TenantSecrets)[id]
+	if !ok {
+		return wh.Secret, false, nil
+	}
+	secret, err = w.secrets.Open(sealed)
+	if err != nil {
+		return "", false, fmt.Errorf("tenant %q secret: %w", id, err)
+	}
+	return secret, true, nil
+}
+
+// tenantOf reads the tenant at the dot-separated path in data. Strings and
+// numbers are accepted.
+func tenantOf(data json.RawMessage, path string) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return "", false
+	}
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if value, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	}
+	return "", false
+}
@@ -13,29 +13,41 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/filipexyz/notif/internal/db"
 	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/metrics"
 	notifnats "github.com/filipexyz/notif/internal/nats"
 	"github.com/filipexyz/notif/internal/security"
+	"github.com/filipexyz/notif/internal/tracing"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	maxRetries     = 5
 	requestTimeout = 30 * time.Second
+
+	// DefaultRetryBudget is how long a failing delivery keeps retrying, from
+	// its first attempt, when the webhook doesn't set its own budget.
+	DefaultRetryBudget = 6 * time.Hour
 )
 
-// retryDelays defines exponential backoff delays for retries
+// retryDelays defines exponential backoff delays for retries, unless a
+// webhook's retry policy sets its own.
 var retryDelays = []time.Duration{
-	10 * time.Second,  // 1st retry
-	30 * time.Second,  // 2nd retry
-	2 * time.Minute,   // 3rd retry
-	10 * time.Minute,  // 4th retry
-	30 * time.Minute,  // 5th retry
+	10 * time.Second, // 1st retry
+	30 * time.Second, // 2nd retry
+	2 * time.Minute,  // 3rd retry
+	10 * time.Minute, // 4th retry
+	30 * time.Minute, // 5th retry
 }
 
 // RetryJob represents a webhook delivery retry job.
@@ -45,12 +57,24 @@ type RetryJob struct {
 	WebhookID  string          `json:"webhook_id"`
 	EventID    string          `json:"event_id"`
 	OrgID      string          `json:"org_id"`
+	ProjectID  string          `json:"project_id,omitempty"`
 	Topic      string          `json:"topic"`
 	Data       json.RawMessage `json:"data"`
 	Timestamp  time.Time       `json:"timestamp"`
 	Attempt    int             `json:"attempt"`
 	LastError  string          `json:"last_error"`
 	DeliveryID string          `json:"delivery_id"`
+
+	// FirstAttemptAt starts the retry budget clock. Zero for jobs queued
+	// before budgets existed, which are only bound by maxRetries.
+	FirstAttemptAt time.Time `json:"first_attempt_at,omitempty"`
+
+	Attachments []domain.Attachment `json:"attachments,omitempty"`
+
+	Schema        string `json:"schema,omitempty"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+
+	Traceparent string `json:"traceparent,omitempty"`
 }
 
 // Worker handles webhook deliveries.
@@ -60,6 +84,30 @@ type Worker struct {
 	stream       jetstream.Stream
 	js           jetstream.JetStream
 	dlqPublisher *notifnats.DLQPublisher
+	dlqPolicies  notifnats.DLQPolicies
+	fanout       *notifnats.FanoutGate
+	secrets      *security.SecretBox
+
+	// Consumption state, so the worker can be paused while its org is
+	// drained without cancelling in-flight deliveries.
+	mu        sync.Mutex
+	paused    bool
+	consumers []pausableConsumer
+
+	// ordered maps the consumer of each ordered webhook being consumed to
+	// the subjects it was set up with.
+	orderedMu sync.Mutex
+	ordered   map[string]string
+
+	// lookupWebhook overrides how webhooks are read, for tests.
+	lookupWebhook func(ctx context.Context, id pgtype.UUID) (db.Webhook, error)
+}
+
+// pausableConsumer is a consumer the worker can stop and restart.
+type pausableConsumer struct {
+	consumer jetstream.Consumer
+	handler  jetstream.MessageHandler
+	consCtx  jetstream.ConsumeContext // nil while paused
 }
 
 // NewWorker creates a new webhook worker.
@@ -73,6 +121,23 @@ func NewWorker(queries *db.Queries, stream jetstream.Stream, js jetstream.JetStr
 	}
 }
 
+// SetDLQPolicies sets the per-topic dead-letter policies applied when a
+// delivery runs out of retries.
+func (w *Worker) SetDLQPolicies(p notifnats.DLQPolicies) {
+	w.dlqPolicies = p
+}
+
+// SetFanoutGate sets the gate that caps how many receivers each event is
+// delivered to.
+func (w *Worker) SetFanoutGate(g *notifnats.FanoutGate) {
+	w.fanout = g
+}
+
+// SetSecretBox sets the key that decrypts stored header credentials.
+func (w *Worker) SetSecretBox(box *security.SecretBox) {
+	w.secrets = box
+}
+
 // Start begins processing events for webhook delivery.
 func (w *Worker) Start(ctx context.Context) error {
 	// Create a consumer for all events
@@ -88,22 +153,96 @@ func (w *Worker) Start(ctx context.Context) error {
 	}
 
 	// Start consuming events
-	consCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+	if err := w.consume(consumer, func(msg jetstream.Msg) {
 		w.processMessage(ctx, msg)
-	})
-	if err != nil {
+	}); err != nil {
 		return fmt.Errorf("start webhook consumer: %w", err)
 	}
 
 	// Start retry consumer
 	go w.startRetryConsumer(ctx)
 
+	// Pick ordered webhooks back up where they left off
+	w.startOrdered(ctx)
+
 	slog.Info("webhook worker started")
 
 	// Wait for context cancellation
 	<-ctx.Done()
-	consCtx.Stop()
+	w.stopConsumers()
+
+	return nil
+}
+
+// consume registers a consumer and starts it unless the worker is paused.
+func (w *Worker) consume(consumer jetstream.Consumer, handler jetstream.MessageHandler) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pc := pausableConsumer{consumer: consumer, handler: handler}
+	if !w.paused {
+		consCtx, err := consumer.Consume(handler)
+		if err != nil {
+			return err
+		}
+		pc.consCtx = consCtx
+	}
+	w.consumers = append(w.consumers, pc)
+	return nil
+}
+
+// unconsume stops and forgets the consumer named name.
+func (w *Worker) unconsume(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, pc := range w.consumers {
+		if info := pc.consumer.CachedInfo(); info == nil || info.Name != name {
+			continue
+		}
+		if pc.consCtx != nil {
+			pc.consCtx.Stop()
+		}
+		w.consumers = append(w.consumers[:i], w.consumers[i+1:]...)
+		return
+	}
+}
+
+func (w *Worker) stopConsumers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range w.consumers {
+		if w.consumers[i].consCtx != nil {
+			w.consumers[i].consCtx.Stop()
+			w.consumers[i].consCtx = nil
+		}
+	}
+}
 
+// Pause stops pulling new events and retries. Deliveries already in flight
+// run to completion; unpulled messages wait in the stream until Resume.
+func (w *Worker) Pause() {
+	w.mu.Lock()
+	w.paused = true
+	w.mu.Unlock()
+	w.stopConsumers()
+	slog.Info("webhook worker paused")
+}
+
+// Resume restarts consumption after Pause.
+func (w *Worker) Resume() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = false
+	for i := range w.consumers {
+		if w.consumers[i].consCtx != nil {
+			continue
+		}
+		consCtx, err := w.consumers[i].consumer.Consume(w.consumers[i].handler)
+		if err != nil {
+			return fmt.Errorf("resume webhook consumer: %w", err)
+		}
+		w.consumers[i].consCtx = consCtx
+	}
+	slog.Info("webhook worker resumed")
 	return nil
 }
 
@@ -126,10 +265,9 @@ func (w *Worker) startRetryConsumer(ctx context.Context) {
 		return
 	}
 
-	consCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+	if err := w.consume(consumer, func(msg jetstream.Msg) {
 		w.processRetry(ctx, msg)
-	})
-	if err != nil {
+	}); err != nil {
 		slog.Error("failed to start retry consumer", "error", err)
 		return
 	}
@@ -137,12 +275,12 @@ func (w *Worker) startRetryConsumer(ctx context.Context) {
 	slog.Info("webhook retry worker started")
 
 	<-ctx.Done()
-	consCtx.Stop()
+	w.stopConsumers()
 }
 
 func (w *Worker) processMessage(ctx context.Context, msg jetstream.Msg) {
-	var event domain.Event
-	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+	event, err := notifnats.EventFromMsg(msg)
+	if err != nil {
 		slog.Error("webhook: failed to unmarshal event", "error", err)
 		msg.Ack() // Don't retry malformed messages
 		return
@@ -162,9 +300,18 @@ func (w *Worker) processMessage(ctx context.Context, msg jetstream.Msg) {
 		return
 	}
 
-	// Find matching webhooks and attempt delivery
-	for _, wh := range webhooks {
-		if !matchesTopic(wh.Topics, event.Topic) {
+	// Attempt delivery to matching webhooks within the fan-out limit
+	for _, wh := range w.receivers(ctx, event, webhooks) {
+		// Ordered webhooks deliver from their own consumer, behind any
+		// earlier event still being retried
+		if wh.Ordered {
+			var startSeq uint64
+			if meta, err := msg.Metadata(); err == nil {
+				startSeq = meta.Sequence.Stream
+			}
+			if err := w.ensureOrdered(ctx, &wh, startSeq); err != nil {
+				slog.Error("webhook: failed to start ordered delivery", "error", err, "event_id", event.ID)
+			}
 			continue
 		}
 
@@ -182,22 +329,40 @@ func (w *Worker) processMessage(ctx context.Context, msg jetstream.Msg) {
 		deliveryID := pgUUIDToString(delivery.ID)
 
 		// Attempt delivery
-		errMsg := w.deliver(ctx, &wh, &event)
+		errMsg := w.deliver(ctx, &wh, event)
 		if errMsg == "" {
 			// Success
 			w.updateDeliverySuccess(ctx, delivery.ID)
-			w.recordEventDelivery(ctx, wh.ID, event.ID, "acked", 1)
+			w.recordEventDelivery(ctx, wh.ID, event.ID, "acked", 1, time.Time{})
 			slog.Debug("webhook: delivered event", "event_id", event.ID, "webhook_id", pgUUIDToString(wh.ID))
 		} else {
 			// Failed - schedule retry
 			w.updateDeliveryFailed(ctx, delivery.ID, 1, errMsg)
-			w.scheduleRetry(ctx, &wh, &event, 1, errMsg, deliveryID)
+			w.scheduleRetry(ctx, &wh, event, 1, errMsg, deliveryID)
 		}
 	}
 
 	msg.Ack()
 }
 
+// receivers returns the webhooks matching event that the fan-out gate
+// admits, each once however many of its patterns match. Shed webhooks get
+// a "shed" event delivery record.
+func (w *Worker) receivers(ctx context.Context, event *domain.Event, webhooks []db.Webhook) []db.Webhook {
+	var matched []db.Webhook
+	for _, wh := range webhooks {
+		if !matchesTopic(wh.Topics, event.Topic) {
+			continue
+		}
+		if !w.fanout.Admit(ctx, event, "webhook:"+pgUUIDToString(wh.ID), notifnats.FanoutPriorityHigh) {
+			w.recordEventDelivery(ctx, wh.ID, event.ID, "shed", 0, time.Time{})
+			continue
+		}
+		matched = append(matched, wh)
+	}
+	return matched
+}
+
 func (w *Worker) processRetry(ctx context.Context, msg jetstream.Msg) {
 	var job RetryJob
 	if err := json.Unmarshal(msg.Data(), &job); err != nil {
@@ -216,9 +381,20 @@ func (w *Worker) processRetry(ctx context.Context, msg jetstream.Msg) {
 	}
 
 	wh := &db.Webhook{
-		ID:     dbWebhook.ID,
-		Url:    dbWebhook.Url,
-		Secret: dbWebhook.Secret,
+		ID:                      dbWebhook.ID,
+		Url:                     dbWebhook.Url,
+		Secret:                  dbWebhook.Secret,
+		PreviousSecret:          dbWebhook.PreviousSecret,
+		PreviousSecretExpiresAt: dbWebhook.PreviousSecretExpiresAt,
+		RetryBudgetSeconds:      dbWebhook.RetryBudgetSeconds,
+		ContentType:             dbWebhook.ContentType,
+		BodyEncoding:            dbWebhook.BodyEncoding,
+		PayloadMode:             dbWebhook.PayloadMode,
+		Headers:                 dbWebhook.Headers,
+		TenantField:             dbWebhook.TenantField,
+		TenantSecrets:           dbWebhook.TenantSecrets,
+		RetryPolicy:             dbWebhook.RetryPolicy,
+		FallbackUrls:            dbWebhook.FallbackUrls,
 	}
 
 	event := &domain.Event{
@@ -227,6 +403,13 @@ func (w *Worker) processRetry(ctx context.Context, msg jetstream.Msg) {
 		Topic:     job.Topic,
 		Data:      job.Data,
 		Timestamp: job.Timestamp,
+
+		Attachments: job.Attachments,
+
+		Schema:        job.Schema,
+		SchemaVersion: job.SchemaVersion,
+
+		Traceparent: job.Traceparent,
 	}
 
 	// Attempt delivery
@@ -237,59 +420,134 @@ func (w *Worker) processRetry(ctx context.Context, msg jetstream.Msg) {
 	if errMsg == "" {
 		// Success
 		w.updateDeliverySuccess(ctx, deliveryID)
-		w.recordEventDelivery(ctx, parseUUID(job.WebhookID), event.ID, "acked", int32(job.Attempt))
+		w.recordEventDelivery(ctx, parseUUID(job.WebhookID), event.ID, "acked", int32(job.Attempt), job.FirstAttemptAt)
 		slog.Info("webhook: retry succeeded", "event_id", event.ID, "attempt", job.Attempt)
 	} else {
 		// Failed
 		w.updateDeliveryFailed(ctx, deliveryID, int32(job.Attempt), errMsg)
 
-		if job.Attempt >= maxRetries {
-			// Max retries reached - move to DLQ
-			w.moveToDLQ(ctx, &job, errMsg)
-			w.recordEventDelivery(ctx, parseUUID(job.WebhookID), event.ID, "dlq", int32(job.Attempt))
-			slog.Warn("webhook: max retries reached, moved to DLQ",
-				"event_id", event.ID,
-				"webhook_id", job.WebhookID,
-				"attempts", job.Attempt,
-			)
-		} else {
-			// Schedule next retry
-			job.Attempt++
-			job.LastError = errMsg
-			w.publishRetryJob(ctx, &job)
-		}
+		job.LastError = errMsg
+		w.retryOrDLQ(ctx, &job, wh)
 	}
 
 	msg.Ack()
 }
 
+// deliver makes one delivery attempt in a span continuing the event's
+// trace, and records it in the server metrics. It returns the failure
+// reason, or "" on success.
 func (w *Worker) deliver(ctx context.Context, wh *db.Webhook, event *domain.Event) string {
+	ctx, span := tracing.Tracer().Start(tracing.ContextWithTraceparent(ctx, event.Traceparent), "notif.webhook.deliver",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("notif.event_id", event.ID),
+			attribute.String("notif.topic", event.Topic),
+			attribute.String("notif.webhook_id", pgUUIDToString(wh.ID)),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	errMsg := w.sendWithFailover(ctx, wh, event)
+	metrics.ObserveWebhookDelivery(errMsg == "", time.Since(start))
+	if errMsg != "" {
+		span.SetStatus(codes.Error, errMsg)
+	}
+	return errMsg
+}
+
+// sendWithFailover tries wh's URL, then each fallback URL in order, until
+// one accepts the event. The attempt only fails when every URL does.
+func (w *Worker) sendWithFailover(ctx context.Context, wh *db.Webhook, event *domain.Event) string {
+	errMsg := w.send(ctx, wh, wh.Url, event)
+	if errMsg == "" || len(wh.FallbackUrls) == 0 {
+		return errMsg
+	}
+
+	errs := []string{"primary: " + errMsg}
+	for i, url := range wh.FallbackUrls {
+		slog.Debug("webhook: failing over", "event_id", event.ID, "webhook_id", pgUUIDToString(wh.ID), "fallback", i+1, "error", errs[len(errs)-1])
+		trace.SpanFromContext(ctx).AddEvent("failover", trace.WithAttributes(attribute.Int("notif.fallback", i+1)))
+
+		errMsg := w.send(ctx, wh, url, event)
+		if errMsg == "" {
+			return ""
+		}
+		errs = append(errs, fmt.Sprintf("fallback %d: %s", i+1, errMsg))
+	}
+	return strings.Join(errs, "; ")
+}
+
+// send POSTs event to url for wh, propagating the trace context in ctx.
+func (w *Worker) send(ctx context.Context, wh *db.Webhook, url string, event *domain.Event) string {
 	// Build payload
 	payload := WebhookPayload{
-		ID:        event.ID,
-		Topic:     event.Topic,
-		Data:      event.Data,
-		Timestamp: event.Timestamp,
+		EnvelopeVersion: domain.EnvelopeVersion,
+		ID:              event.ID,
+		Topic:           event.Topic,
+		Data:            event.Data,
+		Timestamp:       event.Timestamp,
+		Attachments:     event.Attachments,
 	}
 
-	body, err := json.Marshal(payload)
+	body, err := encodePayload(payload, wh.PayloadMode, wh.BodyEncoding)
 	if err != nil {
 		return fmt.Sprintf("marshal payload: %v", err)
 	}
 
-	// Create signature
-	signature := sign(body, wh.Secret)
+	// Create signature, with the event's tenant secret if the webhook has one
+	secret, tenantSecret, err := w.signingSecret(wh, event)
+	if err != nil {
+		return fmt.Sprintf("resolve signing secret: %v", err)
+	}
+	signature := sign(body, secret)
 
 	// Make request
-	req, err := http.NewRequestWithContext(ctx, "POST", wh.Url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Sprintf("create request: %v", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	// Static headers go first so the notif headers below always win
+	headers, err := openHeaders(wh.Headers, w.secrets)
+	if err != nil {
+		return fmt.Sprintf("decrypt headers: %v", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	contentType := wh.ContentType
+	if contentType == "" {
+		contentType = DefaultContentType(wh.BodyEncoding)
+	}
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("X-Notif-Signature", signature)
+	// During a rotation grace period, also sign with the previous secret so
+	// receivers that haven't picked up the new secret keep verifying.
+	// Rotation only applies to the webhook's own secret.
+	if prev := activePreviousSecret(wh, time.Now()); prev != "" && !tenantSecret {
+		req.Header.Set("X-Notif-Signature-Previous", sign(body, prev))
+	}
+	req.Header.Set("X-Notif-Envelope-Version", strconv.Itoa(payload.EnvelopeVersion))
 	req.Header.Set("X-Notif-Event-ID", event.ID)
 	req.Header.Set("X-Notif-Topic", event.Topic)
+	req.Header.Set("X-Notif-Timestamp", event.Timestamp.Format(time.RFC3339Nano))
+	if traceparent := tracing.Traceparent(ctx); traceparent != "" {
+		req.Header.Set(tracing.TraceparentHeader, traceparent)
+	}
+	// Raw bodies have no room for attachments, so they travel as a header
+	if wh.PayloadMode == PayloadModeRaw && len(event.Attachments) > 0 {
+		attachments, err := json.Marshal(event.Attachments)
+		if err != nil {
+			return fmt.Sprintf("marshal attachments: %v", err)
+		}
+		req.Header.Set("X-Notif-Attachments", string(attachments))
+	}
+	if event.Schema != "" {
+		req.Header.Set("X-Notif-Schema", event.Schema)
+		req.Header.Set("X-Notif-Schema-Version", event.SchemaVersion)
+	}
 
 	resp, err := w.httpClient.Do(req)
 	if err != nil {
@@ -306,35 +564,101 @@ func (w *Worker) deliver(ctx context.Context, wh *db.Webhook, event *domain.Even
 }
 
 func (w *Worker) scheduleRetry(ctx context.Context, wh *db.Webhook, event *domain.Event, attempt int, lastError, deliveryID string) {
-	job := &RetryJob{
-		WebhookID:  pgUUIDToString(wh.ID),
-		EventID:    event.ID,
-		OrgID:      event.OrgID,
-		Topic:      event.Topic,
-		Data:       event.Data,
-		Timestamp:  event.Timestamp,
-		Attempt:    attempt + 1,
-		LastError:  lastError,
-		DeliveryID: deliveryID,
+	job := newRetryJob(wh, event, attempt, lastError, deliveryID)
+	w.retryOrDLQ(ctx, job, wh)
+}
+
+// newRetryJob describes the delivery of event to wh whose first attempt
+// just failed.
+func newRetryJob(wh *db.Webhook, event *domain.Event, attempt int, lastError, deliveryID string) *RetryJob {
+	return &RetryJob{
+		WebhookID:      pgUUIDToString(wh.ID),
+		EventID:        event.ID,
+		OrgID:          event.OrgID,
+		ProjectID:      event.ProjectID,
+		Topic:          event.Topic,
+		Data:           event.Data,
+		Timestamp:      event.Timestamp,
+		Attempt:        attempt,
+		LastError:      lastError,
+		DeliveryID:     deliveryID,
+		FirstAttemptAt: time.Now(),
+		Attachments:    event.Attachments,
+		Schema:         event.Schema,
+		SchemaVersion:  event.SchemaVersion,
+		Traceparent:    event.Traceparent,
+	}
+}
+
+// retryOrDLQ queues the next attempt for a job whose attempt just failed, or
+// moves it to the DLQ when wh's attempts or retry budget are exhausted. Jobs
+// whose topic's DLQ policy is "drop" are discarded instead.
+func (w *Worker) retryOrDLQ(ctx context.Context, job *RetryJob, wh *db.Webhook) {
+	policy := w.dlqPolicies.For(job.Topic)
+	sched := scheduleFor(wh)
+	if reason := giveUpReason(job, sched, policy.AttemptLimit(sched.maxRetries), retryBudget(wh), time.Now()); reason != "" {
+		w.giveUp(ctx, job, policy, reason)
+		return
+	}
+
+	job.Attempt++
+	w.publishRetryJob(ctx, job, sched.delay(job.Attempt))
+}
+
+// giveUp dead-letters a job that won't be retried, or drops it when its
+// topic's DLQ policy says so.
+func (w *Worker) giveUp(ctx context.Context, job *RetryJob, policy notifnats.DLQPolicy, reason string) {
+	if policy.Drops() {
+		w.recordEventDelivery(ctx, parseUUID(job.WebhookID), job.EventID, "dropped", int32(job.Attempt), job.FirstAttemptAt)
+		slog.Warn("webhook: "+reason+", dropped by DLQ policy",
+			"event_id", job.EventID,
+			"webhook_id", job.WebhookID,
+			"attempts", job.Attempt,
+		)
+		return
+	}
+	w.moveToDLQ(ctx, job, job.LastError)
+	w.recordEventDelivery(ctx, parseUUID(job.WebhookID), job.EventID, "dlq", int32(job.Attempt), job.FirstAttemptAt)
+	slog.Warn("webhook: "+reason+", moved to DLQ",
+		"event_id", job.EventID,
+		"webhook_id", job.WebhookID,
+		"attempts", job.Attempt,
+	)
+}
+
+// giveUpReason reports why a job whose attempt just failed should not be
+// retried, or "" if it should. A retry is dropped once limit attempts were
+// made, or when sched would run it after the budget measured from the first
+// attempt, even if attempts remain.
+func giveUpReason(job *RetryJob, sched retrySchedule, limit int, budget time.Duration, now time.Time) string {
+	if job.Attempt >= limit {
+		return "max retries reached"
+	}
+	if budget > 0 && !job.FirstAttemptAt.IsZero() {
+		next := now.Add(sched.delay(job.Attempt + 1))
+		if next.After(job.FirstAttemptAt.Add(budget)) {
+			return "retry budget exceeded"
+		}
 	}
+	return ""
+}
 
-	w.publishRetryJob(ctx, job)
+// retryBudget returns the webhook's retry budget, or DefaultRetryBudget.
+func retryBudget(wh *db.Webhook) time.Duration {
+	if wh.RetryBudgetSeconds <= 0 {
+		return DefaultRetryBudget
+	}
+	return time.Duration(wh.RetryBudgetSeconds) * time.Second
 }
 
-func (w *Worker) publishRetryJob(ctx context.Context, job *RetryJob) {
+func (w *Worker) publishRetryJob(ctx context.Context, job *RetryJob, delay time.Duration) {
 	data, err := json.Marshal(job)
 	if err != nil {
 		slog.Error("webhook: failed to marshal retry job", "error", err)
 		return
 	}
 
-	// Calculate delay based on attempt number
-	delay := retryDelays[0]
-	if job.Attempt-1 < len(retryDelays) {
-		delay = retryDelays[job.Attempt-1]
-	}
-
-	subject := fmt.Sprintf("webhook-retry.%s.%s", job.OrgID, job.WebhookID)
+	subject := retrySubject(job)
 
 	// Publish with headers (NATS doesn't support native delay, so we'll use AckWait on consumer)
 	// For now, use a simple approach: publish immediately and the retry consumer picks it up
@@ -349,6 +673,27 @@ func (w *Worker) publishRetryJob(ctx context.Context, job *RetryJob) {
 	}()
 }
 
+// retrySubject is the retry stream subject a job is published on.
+func retrySubject(job *RetryJob) string {
+	return fmt.Sprintf("webhook-retry.%s.%s", job.OrgID, job.WebhookID)
+}
+
+// Redeliver queues an immediate new delivery of event to wh through the
+// retry stream, reusing the delivery record deliveryID. Attempts and the
+// retry budget start over; the worker reads the webhook's current URL and
+// secret when it picks the job up.
+func Redeliver(ctx context.Context, js jetstream.JetStream, wh *db.Webhook, event *domain.Event, deliveryID string) error {
+	job := newRetryJob(wh, event, 1, "", deliveryID)
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshal retry job: %w", err)
+	}
+	if _, err := js.Publish(ctx, retrySubject(job), data); err != nil {
+		return fmt.Errorf("publish retry job: %w", err)
+	}
+	return nil
+}
+
 func (w *Worker) moveToDLQ(ctx context.Context, job *RetryJob, lastError string) {
 	if w.dlqPublisher == nil {
 		slog.Warn("webhook: DLQ publisher not configured")
@@ -358,6 +703,7 @@ func (w *Worker) moveToDLQ(ctx context.Context, job *RetryJob, lastError string)
 	dlqMsg := &notifnats.DLQMessage{
 		ID:            job.EventID,
 		OrgID:         job.OrgID,
+		ProjectID:     job.ProjectID,
 		OriginalTopic: job.Topic,
 		Data:          job.Data,
 		Timestamp:     job.Timestamp,
@@ -373,6 +719,9 @@ func (w *Worker) moveToDLQ(ctx context.Context, job *RetryJob, lastError string)
 }
 
 func (w *Worker) updateDeliverySuccess(ctx context.Context, deliveryID pgtype.UUID) {
+	if w.queries == nil {
+		return
+	}
 	now := time.Now()
 	w.queries.UpdateWebhookDelivery(ctx, db.UpdateWebhookDeliveryParams{
 		ID:          deliveryID,
@@ -383,6 +732,9 @@ func (w *Worker) updateDeliverySuccess(ctx context.Context, deliveryID pgtype.UU
 }
 
 func (w *Worker) updateDeliveryFailed(ctx context.Context, deliveryID pgtype.UUID, attempt int32, errMsg string) {
+	if w.queries == nil {
+		return
+	}
 	w.queries.UpdateWebhookDelivery(ctx, db.UpdateWebhookDeliveryParams{
 		ID:      deliveryID,
 		Status:  "failed",
@@ -391,7 +743,12 @@ func (w *Worker) updateDeliveryFailed(ctx context.Context, deliveryID pgtype.UUI
 	})
 }
 
-func (w *Worker) recordEventDelivery(ctx context.Context, webhookID pgtype.UUID, eventID, status string, attempt int32) {
+// recordEventDelivery records the final outcome of delivering an event to a
+// webhook. firstAttemptAt is zero when the first attempt is this one.
+func (w *Worker) recordEventDelivery(ctx context.Context, webhookID pgtype.UUID, eventID, status string, attempt int32, firstAttemptAt time.Time) {
+	if w.queries == nil {
+		return
+	}
 	now := time.Now()
 	var deliveredAt pgtype.Timestamptz
 	if status == "acked" {
@@ -399,12 +756,13 @@ func (w *Worker) recordEventDelivery(ctx context.Context, webhookID pgtype.UUID,
 	}
 
 	_, err := w.queries.CreateEventDelivery(ctx, db.CreateEventDeliveryParams{
-		EventID:      eventID,
-		ReceiverType: "webhook",
-		ReceiverID:   webhookID,
-		Status:       status,
-		Attempt:      attempt,
-		DeliveredAt:  deliveredAt,
+		EventID:        eventID,
+		ReceiverType:   "webhook",
+		ReceiverID:     webhookID,
+		Status:         status,
+		Attempt:        attempt,
+		DeliveredAt:    deliveredAt,
+		FirstAttemptAt: pgtype.Timestamptz{Time: firstAttemptAt, Valid: !firstAttemptAt.IsZero()},
 	})
 	if err != nil {
 		slog.Warn("webhook: failed to create event delivery", "error", err, "event_id", eventID)
@@ -413,10 +771,13 @@ func (w *Worker) recordEventDelivery(ctx context.Context, webhookID pgtype.UUID,
 
 // WebhookPayload is the payload sent to webhook endpoints.
 type WebhookPayload struct {
-	ID        string          `json:"id"`
-	Topic     string          `json:"topic"`
-	Data      json.RawMessage `json:"data"`
-	Timestamp time.Time       `json:"timestamp"`
+	EnvelopeVersion int             `json:"envelope_version"`
+	ID              string          `json:"id"`
+	Topic           string          `json:"topic"`
+	Data            json.RawMessage `json:"data"`
+	Timestamp       time.Time       `json:"timestamp"`
+
+	Attachments []domain.Attachment `json:"attachments,omitempty"`
 }
 
 // sign creates an HMAC-SHA256 signature.
@@ -426,6 +787,33 @@ func sign(payload []byte, secret string) string {
 	return "sha256=" + hex.EncodeToString(h.Sum(nil))
 }
 
+// VerifySignature reports whether signature is a valid X-Notif-Signature for
+// payload under any of the given secrets. Receivers rotating secrets pass both
+// the new and the old secret until the grace period ends.
+func VerifySignature(payload []byte, signature string, secrets ...string) bool {
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		if hmac.Equal([]byte(sign(payload, secret)), []byte(signature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// activePreviousSecret returns the pre-rotation secret while its grace period
+// is still running, or "" once it has expired.
+func activePreviousSecret(wh *db.Webhook, now time.Time) string {
+	if !wh.PreviousSecret.Valid || wh.PreviousSecret.String == "" {
+		return ""
+	}
+	if !wh.PreviousSecretExpiresAt.Valid || !now.Before(wh.PreviousSecretExpiresAt.Time) {
+		return ""
+	}
+	return wh.PreviousSecret.String
+}
+
 // matchesTopic checks if an event topic matches any of the webhook patterns.
 func matchesTopic(patterns []string, topic string) bool {
 	for _, pattern := range patterns {
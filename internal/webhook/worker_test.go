@@ -0,0 +1,980 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/db"
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/metrics"
+	notifnats "github.com/filipexyz/notif/internal/nats"
+	"github.com/filipexyz/notif/internal/security"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// capturedRequest is what a test receiver saw for one delivery.
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestReceiver records every delivery. The worker's SSRF-safe client
+// refuses loopback addresses, so tests use a plain client instead.
+func newTestReceiver(t *testing.T) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+	received := make(chan capturedRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- capturedRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received
+}
+
+func newTestWorker() *Worker {
+	return &Worker{httpClient: http.DefaultClient}
+}
+
+func testEvent() *domain.Event {
+	return &domain.Event{
+		ID:        "evt_test",
+		Topic:     "orders.created",
+		Data:      json.RawMessage(`{"order_id":"123"}`),
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+func TestDeliver_SignsWithCurrentSecret(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	wh := &db.Webhook{Url: srv.URL, Secret: "current-secret"}
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	if !VerifySignature(req.body, req.header.Get("X-Notif-Signature"), "current-secret") {
+		t.Error("signature does not verify with current secret")
+	}
+	if req.header.Get("X-Notif-Signature-Previous") != "" {
+		t.Error("expected no previous signature without a rotation")
+	}
+}
+
+func TestDeliver_PropagatesTraceparent(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret"}
+
+	event := testEvent()
+	event.Traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if errMsg := w.deliver(context.Background(), wh, event); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+	if got := (<-received).header.Get("traceparent"); !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+		t.Errorf("expected the event's trace in traceparent, got %q", got)
+	}
+
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+	if got := (<-received).header.Get("traceparent"); got != "" {
+		t.Errorf("expected no traceparent for an untraced event, got %q", got)
+	}
+}
+
+func TestDeliver_RotationGraceWindow(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	wh := &db.Webhook{
+		Url:                     srv.URL,
+		Secret:                  "new-secret",
+		PreviousSecret:          pgtype.Text{String: "old-secret", Valid: true},
+		PreviousSecretExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	sig := req.header.Get("X-Notif-Signature")
+	if !VerifySignature(req.body, sig, "new-secret") {
+		t.Error("primary signature should use the new secret")
+	}
+	if VerifySignature(req.body, sig, "old-secret") {
+		t.Error("primary signature must not use the old secret")
+	}
+
+	// A receiver still holding the old secret verifies the previous signature
+	prev := req.header.Get("X-Notif-Signature-Previous")
+	if !VerifySignature(req.body, prev, "old-secret") {
+		t.Error("previous signature should verify with the old secret")
+	}
+}
+
+func TestDeliver_RotationGraceExpired(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	wh := &db.Webhook{
+		Url:                     srv.URL,
+		Secret:                  "new-secret",
+		PreviousSecret:          pgtype.Text{String: "old-secret", Valid: true},
+		PreviousSecretExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	}
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	if req.header.Get("X-Notif-Signature-Previous") != "" {
+		t.Error("previous signature must be dropped after the grace period")
+	}
+}
+
+func TestDeliver_EnvelopeVersion(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret"}
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	var payload map[string]any
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload["envelope_version"] != float64(1) {
+		t.Errorf("expected envelope_version 1, got %v", payload["envelope_version"])
+	}
+	if got := req.header.Get("X-Notif-Envelope-Version"); got != "1" {
+		t.Errorf("expected X-Notif-Envelope-Version 1, got %q", got)
+	}
+}
+
+func TestDeliver_SchemaHeaders(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret"}
+
+	// Event validated against a schema at emit
+	event := testEvent()
+	event.Schema, event.SchemaVersion = "order-created", "1.2.0"
+	if errMsg := w.deliver(context.Background(), wh, event); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+	req := <-received
+	if got := req.header.Get("X-Notif-Schema"); got != "order-created" {
+		t.Errorf("expected X-Notif-Schema order-created, got %q", got)
+	}
+	if got := req.header.Get("X-Notif-Schema-Version"); got != "1.2.0" {
+		t.Errorf("expected X-Notif-Schema-Version 1.2.0, got %q", got)
+	}
+
+	// Topic without a schema
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+	req = <-received
+	if _, ok := req.header["X-Notif-Schema"]; ok {
+		t.Error("expected no X-Notif-Schema header for an unschematized event")
+	}
+	if _, ok := req.header["X-Notif-Schema-Version"]; ok {
+		t.Error("expected no X-Notif-Schema-Version header for an unschematized event")
+	}
+}
+
+func TestDeliver_FormEncoding(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret", BodyEncoding: BodyEncodingForm}
+	event := testEvent()
+	if errMsg := w.deliver(context.Background(), wh, event); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	if got := req.header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+		t.Errorf("expected form content type, got %q", got)
+	}
+	form, err := url.ParseQuery(string(req.body))
+	if err != nil {
+		t.Fatalf("invalid form body %q: %v", req.body, err)
+	}
+	if form.Get("id") != event.ID || form.Get("topic") != event.Topic || form.Get("envelope_version") != "1" {
+		t.Errorf("unexpected envelope fields: %v", form)
+	}
+	if form.Get("data") != string(event.Data) {
+		t.Errorf("expected data %s, got %q", event.Data, form.Get("data"))
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, form.Get("timestamp")); err != nil || !ts.Equal(event.Timestamp) {
+		t.Errorf("expected timestamp %v, got %q", event.Timestamp, form.Get("timestamp"))
+	}
+	if !VerifySignature(req.body, req.header.Get("X-Notif-Signature"), "secret") {
+		t.Error("signature does not verify over the form body")
+	}
+}
+
+func TestDeliver_RawPayload(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret", PayloadMode: PayloadModeRaw}
+	event := testEvent()
+	event.Attachments = []domain.Attachment{{ID: "blob_1"}}
+	if errMsg := w.deliver(context.Background(), wh, event); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	if string(req.body) != string(event.Data) {
+		t.Errorf("expected the unwrapped data %s as body, got %s", event.Data, req.body)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("expected application/json, got %q", got)
+	}
+	if got := req.header.Get("X-Notif-Event-ID"); got != event.ID {
+		t.Errorf("X-Notif-Event-ID = %q, want %q", got, event.ID)
+	}
+	if got := req.header.Get("X-Notif-Topic"); got != event.Topic {
+		t.Errorf("X-Notif-Topic = %q, want %q", got, event.Topic)
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, req.header.Get("X-Notif-Timestamp")); err != nil || !ts.Equal(event.Timestamp) {
+		t.Errorf("X-Notif-Timestamp = %q, want %v", req.header.Get("X-Notif-Timestamp"), event.Timestamp)
+	}
+	var attachments []domain.Attachment
+	if err := json.Unmarshal([]byte(req.header.Get("X-Notif-Attachments")), &attachments); err != nil || len(attachments) != 1 || attachments[0].ID != "blob_1" {
+		t.Errorf("X-Notif-Attachments = %q", req.header.Get("X-Notif-Attachments"))
+	}
+	if !VerifySignature(req.body, req.header.Get("X-Notif-Signature"), "secret") {
+		t.Error("signature does not verify over the raw body")
+	}
+}
+
+func TestDeliver_EnvelopeHasNoAttachmentsHeader(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	event := testEvent()
+	event.Attachments = []domain.Attachment{{ID: "blob_1"}}
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret", PayloadMode: PayloadModeEnvelope}
+	if errMsg := w.deliver(context.Background(), wh, event); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	var payload WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil || payload.ID != event.ID || len(payload.Attachments) != 1 {
+		t.Errorf("expected an envelope with attachments, got %s", req.body)
+	}
+	if got := req.header.Get("X-Notif-Attachments"); got != "" {
+		t.Errorf("envelope deliveries carry attachments in the body, got header %q", got)
+	}
+}
+
+func TestDeliver_ContentType(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	// Default: JSON
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret"}
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+	if got := (<-received).header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("expected application/json, got %q", got)
+	}
+
+	// Vendor type over a JSON body
+	wh.ContentType = "application/vnd.acme+json"
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+	req := <-received
+	if got := req.header.Get("Content-Type"); got != "application/vnd.acme+json" {
+		t.Errorf("expected vendor content type, got %q", got)
+	}
+	if !json.Valid(req.body) {
+		t.Errorf("expected a JSON body, got %q", req.body)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	sig := sign(payload, "new-secret")
+
+	tests := []struct {
+		name    string
+		secrets []string
+		want    bool
+	}{
+		{"matching secret", []string{"new-secret"}, true},
+		{"either of current and previous", []string{"new-secret", "old-secret"}, true},
+		{"previous listed first", []string{"old-secret", "new-secret"}, true},
+		{"wrong secret", []string{"old-secret"}, false},
+		{"empty secrets ignored", []string{""}, false},
+		{"no secrets", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(payload, sig, tt.secrets...); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGiveUpReason(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		job    RetryJob
+		budget time.Duration
+		now    time.Time
+		want   string
+	}{
+		{"within budget", RetryJob{Attempt: 1, FirstAttemptAt: start}, time.Hour, start, ""},
+		{"max retries", RetryJob{Attempt: maxRetries, FirstAttemptAt: start}, time.Hour, start, "max retries reached"},
+		{"next retry past budget", RetryJob{Attempt: 2, FirstAttemptAt: start}, time.Hour, start.Add(59 * time.Minute), "retry budget exceeded"},
+		{"next retry exactly at budget", RetryJob{Attempt: 2, FirstAttemptAt: start}, time.Hour, start.Add(58 * time.Minute), ""},
+		{"legacy job without start", RetryJob{Attempt: 2}, time.Hour, start.Add(48 * time.Hour), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := giveUpReason(&tt.job, scheduleFor(&db.Webhook{}), maxRetries, tt.budget, tt.now); got != tt.want {
+				t.Errorf("giveUpReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBudget_DLQAtBoundary(t *testing.T) {
+	// Every attempt fails. With a 5m budget the schedule is: attempt 1 at 0,
+	// attempt 2 at +30s, attempt 3 at +2m30s; attempt 4 would run at +12m30s,
+	// so the job goes to the DLQ after attempt 3 with attempts to spare.
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	budget := 5 * time.Minute
+	job := &RetryJob{Attempt: 1, FirstAttemptAt: start}
+
+	sched := scheduleFor(&db.Webhook{})
+	now := start
+	var reason string
+	for {
+		if reason = giveUpReason(job, sched, maxRetries, budget, now); reason != "" {
+			break
+		}
+		job.Attempt++
+		now = now.Add(sched.delay(job.Attempt))
+		if now.After(start.Add(budget)) {
+			t.Fatalf("attempt %d ran at +%s, past the %s budget", job.Attempt, now.Sub(start), budget)
+		}
+	}
+
+	if reason != "retry budget exceeded" {
+		t.Errorf("expected budget to end retries, got %q", reason)
+	}
+	if job.Attempt != 3 {
+		t.Errorf("expected DLQ after attempt 3, got %d", job.Attempt)
+	}
+	if job.Attempt >= maxRetries {
+		t.Errorf("budget should stop retries before maxRetries")
+	}
+}
+
+func TestRetryBudget_Default(t *testing.T) {
+	if got := retryBudget(&db.Webhook{}); got != DefaultRetryBudget {
+		t.Errorf("expected default budget %s, got %s", DefaultRetryBudget, got)
+	}
+	if got := retryBudget(&db.Webhook{RetryBudgetSeconds: 600}); got != 10*time.Minute {
+		t.Errorf("expected 10m budget, got %s", got)
+	}
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		wantErr bool
+	}{
+		{"unset", RetryPolicy{}, false},
+		{"valid", RetryPolicy{MaxRetries: 3, Backoff: []string{"5s", "1m"}}, false},
+		{"max retries only", RetryPolicy{MaxRetries: 20}, false},
+		{"too many retries", RetryPolicy{MaxRetries: 21}, true},
+		{"negative retries", RetryPolicy{MaxRetries: -1}, true},
+		{"unparseable backoff", RetryPolicy{Backoff: []string{"soon"}}, true},
+		{"zero backoff", RetryPolicy{Backoff: []string{"0s"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRetryPolicy(tt.policy); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRetryPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleFor_RetryPolicy(t *testing.T) {
+	sched := scheduleFor(&db.Webhook{})
+	if sched.maxRetries != maxRetries || sched.delay(1) != retryDelays[0] {
+		t.Errorf("unset policy should use the defaults, got %+v", sched)
+	}
+
+	wh := &db.Webhook{RetryPolicy: EncodeRetryPolicy(RetryPolicy{MaxRetries: 8, Backoff: []string{"1s", "5s"}})}
+	sched = scheduleFor(wh)
+	if sched.maxRetries != 8 {
+		t.Errorf("expected 8 max retries, got %d", sched.maxRetries)
+	}
+	if got := sched.delay(2); got != 5*time.Second {
+		t.Errorf("delay(2) = %s, want 5s", got)
+	}
+	if got := sched.delay(6); got != 5*time.Second {
+		t.Errorf("delay past the list should reuse the last entry, got %s", got)
+	}
+
+	// Backoff alone keeps the default attempt count.
+	wh = &db.Webhook{RetryPolicy: EncodeRetryPolicy(RetryPolicy{Backoff: []string{"1s"}})}
+	if got := scheduleFor(wh).maxRetries; got != maxRetries {
+		t.Errorf("expected default max retries, got %d", got)
+	}
+}
+
+func TestGiveUpReason_CustomMaxRetries(t *testing.T) {
+	sched := scheduleFor(&db.Webhook{RetryPolicy: EncodeRetryPolicy(RetryPolicy{MaxRetries: 2})})
+	job := &RetryJob{Attempt: 2, FirstAttemptAt: time.Now()}
+	if got := giveUpReason(job, sched, sched.maxRetries, DefaultRetryBudget, time.Now()); got != "max retries reached" {
+		t.Errorf("expected max retries after 2 attempts, got %q", got)
+	}
+}
+
+// newTestNATS starts an embedded JetStream server with the notif streams.
+func newTestNATS(t *testing.T) *notifnats.Client {
+	t.Helper()
+	srv, err := notifnats.StartEmbedded(notifnats.EmbeddedConfig{
+		StoreDir: t.TempDir(),
+		Port:     -1,
+	})
+	if err != nil {
+		t.Fatalf("start embedded: %v", err)
+	}
+	t.Cleanup(srv.Shutdown)
+
+	nc, err := notifnats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	if err := nc.EnsureStreams(context.Background()); err != nil {
+		t.Fatalf("ensure streams: %v", err)
+	}
+	return nc
+}
+
+// newTestDLQ returns a publisher and reader for an embedded server's DLQ.
+func newTestDLQ(t *testing.T) (*notifnats.DLQPublisher, *notifnats.DLQReader) {
+	t.Helper()
+	nc := newTestNATS(t)
+	reader, err := notifnats.NewDLQReader(nc.JetStream())
+	if err != nil {
+		t.Fatalf("dlq reader: %v", err)
+	}
+	return notifnats.NewDLQPublisher(nc.JetStream()), reader
+}
+
+func TestRetryOrDLQ_Policies(t *testing.T) {
+	policies, err := notifnats.ParseDLQPolicies("audit.>=drop,payments.*=dlq-after-1")
+	if err != nil {
+		t.Fatalf("parse policies: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		topic   string
+		attempt int
+		wantDLQ int64
+	}{
+		{"drop never dead-letters", "audit.login", maxRetries, 0},
+		{"dlq-after-1 dead-letters on first failure", "payments.charged", 1, 1},
+		{"default dead-letters at max retries", "orders.created", maxRetries, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dlqPublisher, dlqReader := newTestDLQ(t)
+			w := newTestWorker()
+			w.dlqPublisher = dlqPublisher
+			w.SetDLQPolicies(policies)
+
+			w.retryOrDLQ(context.Background(), &RetryJob{
+				WebhookID:      "00000000-0000-0000-0000-000000000001",
+				EventID:        "evt_test",
+				OrgID:          "org_test",
+				ProjectID:      "prj_test",
+				Topic:          tt.topic,
+				Data:           json.RawMessage(`{}`),
+				Attempt:        tt.attempt,
+				LastError:      "HTTP 500",
+				FirstAttemptAt: time.Now(),
+			}, &db.Webhook{})
+
+			got, err := dlqReader.Count(context.Background(), "org_test", "prj_test")
+			if err != nil {
+				t.Fatalf("count DLQ: %v", err)
+			}
+			if got != tt.wantDLQ {
+				t.Errorf("DLQ has %d messages, want %d", got, tt.wantDLQ)
+			}
+		})
+	}
+}
+
+// publishOrderedEvents publishes events with the given IDs to org_1.
+func publishOrderedEvents(t *testing.T, nc *notifnats.Client, ids ...string) {
+	t.Helper()
+	pub := notifnats.NewPublisher(nc.JetStream())
+	for _, id := range ids {
+		event := testEvent()
+		event.ID = id
+		event.OrgID, event.ProjectID = "org_1", "prj_1"
+		if err := pub.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+}
+
+func TestOrdered_DeliversInEmitOrder(t *testing.T) {
+	prevDelays := retryDelays
+	retryDelays = []time.Duration{50 * time.Millisecond}
+	t.Cleanup(func() { retryDelays = prevDelays })
+
+	// The receiver fails the first attempt of evt_1; the others must wait
+	// behind its retry instead of overtaking it.
+	var (
+		mu       sync.Mutex
+		attempts []string
+		failed   bool
+	)
+	delivered := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Notif-Event-ID")
+		mu.Lock()
+		attempts = append(attempts, id)
+		fail := id == "evt_1" && !failed
+		failed = failed || fail
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		delivered <- id
+	}))
+	t.Cleanup(srv.Close)
+
+	nc := newTestNATS(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	wh := db.Webhook{
+		ID:      parseUUID("00000000-0000-0000-0000-000000000001"),
+		OrgID:   pgtype.Text{String: "org_1", Valid: true},
+		Url:     srv.URL,
+		Secret:  "secret",
+		Topics:  []string{"orders.*"},
+		Enabled: true,
+		Ordered: true,
+	}
+	w := newTestWorker()
+	w.stream = nc.Stream()
+	w.lookupWebhook = func(context.Context, pgtype.UUID) (db.Webhook, error) { return wh, nil }
+	t.Cleanup(w.stopConsumers)
+
+	want := []string{"evt_1", "evt_2", "evt_3", "evt_4"}
+	publishOrderedEvents(t, nc, want...)
+	if err := w.ensureOrdered(ctx, &wh, 1); err != nil {
+		t.Fatalf("ensureOrdered: %v", err)
+	}
+
+	var got []string
+	for range want {
+		select {
+		case id := <-delivered:
+			got = append(got, id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out; delivered %v", got)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("delivered %v, want %v", got, want)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 5 || attempts[0] != "evt_1" || attempts[1] != "evt_1" {
+		t.Errorf("attempts = %v, want evt_1 retried before anything else", attempts)
+	}
+}
+
+func TestOrdered_DropsDisabledWebhook(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	nc := newTestNATS(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	wh := db.Webhook{
+		ID:      parseUUID("00000000-0000-0000-0000-000000000002"),
+		OrgID:   pgtype.Text{String: "org_1", Valid: true},
+		Url:     srv.URL,
+		Topics:  []string{"orders.*"},
+		Enabled: true,
+		Ordered: true,
+	}
+	w := newTestWorker()
+	w.stream = nc.Stream()
+	// Disabled after its events were queued
+	w.lookupWebhook = func(context.Context, pgtype.UUID) (db.Webhook, error) {
+		disabled := wh
+		disabled.Enabled = false
+		return disabled, nil
+	}
+	t.Cleanup(w.stopConsumers)
+
+	publishOrderedEvents(t, nc, "evt_1")
+	if err := w.ensureOrdered(ctx, &wh, 1); err != nil {
+		t.Fatalf("ensureOrdered: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := nc.Stream().Consumer(ctx, orderedConsumerName(wh.ID))
+		if errors.Is(err, jetstream.ErrConsumerNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ordered consumer still exists: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	select {
+	case req := <-received:
+		t.Errorf("delivered %s to a disabled webhook", req.header.Get("X-Notif-Event-ID"))
+	default:
+	}
+}
+
+func TestDeliver_CustomHeaders(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	box, err := security.NewSecretBox(bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWorker()
+	w.SetSecretBox(box)
+
+	headers, err := SealHeaders(map[string]string{
+		"authorization": "Bearer s3cret",
+		"X-Tenant-ID":   "acme",
+	}, nil, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(headers, []byte("s3cret")) {
+		t.Fatalf("expected Authorization encrypted at rest, got %s", headers)
+	}
+
+	wh := &db.Webhook{Url: srv.URL, Secret: "secret", Headers: headers}
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+
+	req := <-received
+	if got := req.header.Get("Authorization"); got != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want decrypted value", got)
+	}
+	if got := req.header.Get("X-Tenant-Id"); got != "acme" {
+		t.Errorf("X-Tenant-ID = %q, want acme", got)
+	}
+	if !VerifySignature(req.body, req.header.Get("X-Notif-Signature"), "secret") {
+		t.Error("signature does not verify")
+	}
+}
+
+func TestValidateHeaders_Reserved(t *testing.T) {
+	for _, name := range []string{"X-Notif-Signature", "x-notif-event-id", "X-Notif-Topic", "content-type"} {
+		if err := ValidateHeaders(map[string]string{name: "x"}); err == nil {
+			t.Errorf("expected %s to be rejected", name)
+		}
+	}
+	if err := ValidateHeaders(map[string]string{"Bad Name": "x"}); err == nil {
+		t.Error("expected invalid header name to be rejected")
+	}
+	if err := ValidateHeaders(map[string]string{"X-Tenant": "a\r\nX-Injected: 1"}); err == nil {
+		t.Error("expected header value with CRLF to be rejected")
+	}
+}
+
+func TestHeaders_RedactAndKeep(t *testing.T) {
+	stored, err := SealHeaders(map[string]string{"X-Api-Key": "k1", "X-Tenant-ID": "acme"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redacted := RedactHeaders(stored)
+	if redacted["X-Api-Key"] != RedactedHeaderValue || redacted["X-Tenant-Id"] != "acme" {
+		t.Errorf("unexpected redaction: %v", redacted)
+	}
+
+	// Sending the redacted view back keeps the stored secret
+	updated, err := SealHeaders(redacted, stored, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, _ := openHeaders(updated, nil)
+	if opened["X-Api-Key"] != "k1" {
+		t.Errorf("X-Api-Key = %q, want stored value kept", opened["X-Api-Key"])
+	}
+
+	if _, err := SealHeaders(map[string]string{"X-Other-Key": RedactedHeaderValue}, stored, nil); err == nil {
+		t.Error("expected error keeping a header that was never stored")
+	}
+}
+
+func TestDeliver_TenantSecrets(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	box, err := security.NewSecretBox(bytes.Repeat([]byte{3}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWorker()
+	w.SetSecretBox(box)
+
+	secrets, err := SealTenantSecrets(map[string]string{"acme": "acme-secret", "42": "globex-secret"}, nil, box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(secrets, []byte("acme-secret")) {
+		t.Fatalf("expected tenant secrets encrypted at rest, got %s", secrets)
+	}
+	wh := &db.Webhook{
+		Url:           srv.URL,
+		Secret:        "default-secret",
+		TenantField:   "customer.tenant",
+		TenantSecrets: secrets,
+	}
+
+	tests := []struct {
+		data   string
+		secret string
+	}{
+		{`{"customer":{"tenant":"acme"}}`, "acme-secret"},
+		{`{"customer":{"tenant":42}}`, "globex-secret"},
+		{`{"customer":{"tenant":"initech"}}`, "default-secret"},
+		{`{"order_id":"123"}`, "default-secret"},
+	}
+	for _, tt := range tests {
+		event := testEvent()
+		event.Data = json.RawMessage(tt.data)
+		if errMsg := w.deliver(context.Background(), wh, event); errMsg != "" {
+			t.Fatalf("deliver failed: %s", errMsg)
+		}
+		req := <-received
+		if !VerifySignature(req.body, req.header.Get("X-Notif-Signature"), tt.secret) {
+			t.Errorf("%s: signature does not verify with %s", tt.data, tt.secret)
+		}
+	}
+}
+
+func TestValidateTenantSecrets(t *testing.T) {
+	if err := ValidateTenantSecrets("", map[string]string{"acme": "s"}); err == nil {
+		t.Error("expected tenant_secrets without tenant_field to be rejected")
+	}
+	if err := ValidateTenantSecrets("customer..tenant", nil); err == nil {
+		t.Error("expected invalid tenant_field to be rejected")
+	}
+	if err := ValidateTenantSecrets("tenant", map[string]string{"acme": ""}); err == nil {
+		t.Error("expected empty secret to be rejected")
+	}
+	if err := ValidateTenantSecrets("tenant", map[string]string{"acme": "s"}); err != nil {
+		t.Errorf("valid tenant secrets: %v", err)
+	}
+}
+
+func TestRedeliver_QueuesFirstAttempt(t *testing.T) {
+	nc := newTestNATS(t)
+	ctx := context.Background()
+
+	wh := &db.Webhook{ID: parseUUID("7f3c2a9e-1b2d-4c5e-8f90-123456789abc")}
+	event := &domain.Event{
+		ID:        "evt_1",
+		OrgID:     "org_1",
+		ProjectID: "prj_1",
+		Topic:     "orders.created",
+		Data:      json.RawMessage(`{"id":1}`),
+	}
+	if err := Redeliver(ctx, nc.JetStream(), wh, event, "d-1"); err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+
+	stream, err := nc.JetStream().Stream(ctx, notifnats.WebhookRetryStream)
+	if err != nil {
+		t.Fatalf("retry stream: %v", err)
+	}
+	msg, err := stream.GetLastMsgForSubject(ctx, "webhook-retry.org_1.7f3c2a9e-1b2d-4c5e-8f90-123456789abc")
+	if err != nil {
+		t.Fatalf("get retry job: %v", err)
+	}
+	var job RetryJob
+	if err := json.Unmarshal(msg.Data, &job); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if job.Attempt != 1 || job.DeliveryID != "d-1" || job.EventID != "evt_1" || job.ProjectID != "prj_1" || job.FirstAttemptAt.IsZero() {
+		t.Errorf("unexpected job: %+v", job)
+	}
+}
+
+func TestReceivers_FanoutLimit(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+	gate := notifnats.NewFanoutGate(notifnats.FanoutLimits{{Pattern: "fanout.>", Max: 3}}, true)
+	var overflow int
+	gate.SetOverflowFunc(func(context.Context, notifnats.FanoutOverflow) { overflow++ })
+	w.SetFanoutGate(gate)
+
+	var webhooks []db.Webhook
+	for i := range 8 {
+		webhooks = append(webhooks, db.Webhook{
+			ID:     parseUUID(fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1)),
+			Url:    srv.URL,
+			Secret: "secret",
+			Topics: []string{"fanout.*"},
+		})
+	}
+	webhooks = append(webhooks, db.Webhook{ID: parseUUID("00000000-0000-0000-0000-000000000099"), Topics: []string{"other.>"}})
+
+	event := testEvent()
+	event.Topic = "fanout.created"
+	ctx := context.Background()
+	for _, wh := range w.receivers(ctx, event, webhooks) {
+		if errMsg := w.deliver(ctx, &wh, event); errMsg != "" {
+			t.Fatalf("deliver: %s", errMsg)
+		}
+	}
+
+	if got := len(received); got != 3 {
+		t.Errorf("delivered to %d webhooks, want 3", got)
+	}
+	if overflow != 5 {
+		t.Errorf("overflow = %d, want 5", overflow)
+	}
+
+	// Redelivering the event reaches the same webhooks
+	again := w.receivers(ctx, event, webhooks)
+	if len(again) != 3 || again[0].ID != webhooks[0].ID {
+		t.Errorf("redelivery admitted %d webhooks, want the same 3", len(again))
+	}
+}
+
+func TestReceivers_OverlappingPatternsDeliverOnce(t *testing.T) {
+	srv, received := newTestReceiver(t)
+	w := newTestWorker()
+
+	// Both patterns match; the webhook must still get one delivery per event
+	webhooks := []db.Webhook{{
+		ID:     parseUUID("00000000-0000-0000-0000-000000000001"),
+		Url:    srv.URL,
+		Secret: "secret",
+		Topics: []string{"orders.>", "orders.*"},
+	}}
+
+	event := testEvent()
+	event.Topic = "orders.created"
+	ctx := context.Background()
+	for _, wh := range w.receivers(ctx, event, webhooks) {
+		if errMsg := w.deliver(ctx, &wh, event); errMsg != "" {
+			t.Fatalf("deliver: %s", errMsg)
+		}
+	}
+
+	if got := len(received); got != 1 {
+		t.Errorf("delivered %d times, want 1", got)
+	}
+}
+
+func TestDeliver_FailsOverToFallbackURL(t *testing.T) {
+	primaryHits := make(chan struct{}, 10)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits <- struct{}{}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(primary.Close)
+	fallback, received := newTestReceiver(t)
+
+	w := newTestWorker()
+	wh := &db.Webhook{Url: primary.URL, FallbackUrls: []string{fallback.URL}, Secret: "secret"}
+
+	successes := testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("success"))
+	failures := testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("failed"))
+	if errMsg := w.deliver(context.Background(), wh, testEvent()); errMsg != "" {
+		t.Fatalf("deliver failed: %s", errMsg)
+	}
+	if len(primaryHits) != 1 {
+		t.Errorf("primary hits = %d, want 1", len(primaryHits))
+	}
+	req := <-received
+	if !VerifySignature(req.body, req.header.Get("X-Notif-Signature"), "secret") {
+		t.Error("fallback delivery signature does not verify")
+	}
+
+	// The attempt counts once, as a success
+	if got := testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("success")) - successes; got != 1 {
+		t.Errorf("successful deliveries = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.WebhookDeliveries.WithLabelValues("failed")) - failures; got != 0 {
+		t.Errorf("failed deliveries = %v, want 0", got)
+	}
+}
+
+func TestDeliver_FailsWhenAllURLsFail(t *testing.T) {
+	var hits []string
+	var mu sync.Mutex
+	newFailing := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits = append(hits, name)
+			mu.Unlock()
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	primary, first, second := newFailing("primary"), newFailing("first"), newFailing("second")
+
+	w := newTestWorker()
+	wh := &db.Webhook{Url: primary.URL, FallbackUrls: []string{first.URL, second.URL}, Secret: "secret"}
+	errMsg := w.deliver(context.Background(), wh, testEvent())
+	if !strings.Contains(errMsg, "primary: HTTP 502") || !strings.Contains(errMsg, "fallback 2: HTTP 502") {
+		t.Errorf("error = %q, want each URL's failure", errMsg)
+	}
+	if want := []string{"primary", "first", "second"}; !slices.Equal(hits, want) {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+}
@@ -3,8 +3,13 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/filipexyz/notif/internal/db"
@@ -20,6 +25,7 @@ type pendingMsg struct {
 	msg        jetstream.Msg
 	event      *domain.Event
 	attempt    int
+	streamSeq  uint64      // For the resume token issued on ack
 	deliveryID pgtype.UUID // Tracks delivery in event_deliveries table
 }
 
@@ -27,6 +33,11 @@ const (
 	writeWait  = 10 * time.Second
 	pongWait   = 60 * time.Second
 	pingPeriod = (pongWait * 9) / 10
+
+	// sendBufferSize bounds the frames queued for a connection. Events may
+	// fill it up to sendBufferEvents, keeping the rest for control frames.
+	sendBufferSize   = 256
+	sendBufferEvents = sendBufferSize - 32
 )
 
 // Client represents a WebSocket client connection.
@@ -49,8 +60,102 @@ type Client struct {
 	pendingMessages map[string]*pendingMsg
 	autoAck         bool
 	maxRetries      int
+	ackTimeout      time.Duration
 	group           string
 	dlqPublisher    *nats.DLQPublisher
+
+	// Server-side sampling: deliver 1 in sampleEvery, or with probability
+	// sampleRate. Zero values deliver everything.
+	sampleEvery int
+	sampleRate  float64
+	sampleSeen  uint64
+
+	// projection reshapes the data of delivered events; nil sends it whole.
+	projection *Projection
+
+	// envelopeVersion is the event envelope version negotiated at subscribe.
+	envelopeVersion int
+
+	// paused is set while the client's org is drained: the consumer stays,
+	// but nothing is pulled from it.
+	paused bool
+
+	// Per-project subscription cap (0 = unlimited) and the hub slot held by
+	// the current subscription.
+	maxSubscriptions int
+	subKey           string
+
+	// Consumer group caps (0 = unlimited): members per group, and unacked
+	// events per member. A member at maxInflight stops pulling (saturated)
+	// until it settles some, leaving the backlog to the other members.
+	maxGroupMembers int
+	maxInflight     int
+	saturated       bool
+
+	// slow is set while the client reads too slowly for its events to fit
+	// the send buffer, until WritePump drains it. Auto-ack events are
+	// dropped meanwhile (counted in dropped); manual-ack delivery stops.
+	slow    atomic.Bool
+	dropped int
+
+	// emitter publishes "emit" actions; nil disables emitting.
+	emitter Emitter
+
+	// dlqPolicies picks, per topic, when exhausted events are dead-lettered
+	// or dropped. Nil dead-letters everything after maxRetries.
+	dlqPolicies nats.DLQPolicies
+
+	// untilCaughtUp subscriptions end after catchUpRemaining more
+	// first-time deliveries: the events stored when they subscribed.
+	untilCaughtUp    bool
+	catchUpRemaining uint64
+
+	// snapshot skips stored events superseded by a later one with the same
+	// key, for latest_per_key subscriptions.
+	snapshot *nats.KeySnapshot
+
+	// resumeCursor is the from "resume" subscription's cursor, carried by
+	// the resume tokens it is sent.
+	resumeCursor string
+
+	// displayConfigs looks up schema display configs; displayTopics are the
+	// topics they're pushed for, nil unless the subscription asked.
+	displayConfigs DisplayConfigSource
+	displayTopics  []string
+
+	// authorizedProjects are the projects an admin key may subscribe
+	// across; nil for other keys. crossProject marks the current
+	// subscription as spanning projects, so frames carry project_id.
+	authorizedProjects []string
+	crossProject       bool
+
+	// closing is why the server is closing the connection, once it is;
+	// authTimer closes it when the session it was opened with expires.
+	closing   CloseReason
+	authTimer *time.Timer
+}
+
+// groupSubKeyPrefix marks the hub subscription key shared by the members of
+// a consumer group.
+const groupSubKeyPrefix = "group:"
+
+// DisplayConfigSource returns the display configs of the project's schemas
+// matching topics.
+type DisplayConfigSource func(ctx context.Context, projectID string, topics []string) ([]DisplayConfig, error)
+
+// Emitter publishes an event on behalf of a connection, with the same
+// validation and limits as POST /emit. An *EmitError is reported to the
+// client with its code; any other error as EMIT_FAILED.
+type Emitter func(ctx context.Context, req *domain.EmitRequest) (*domain.EmitResponse, error)
+
+// EmitError is an emit rejected by the Emitter.
+type EmitError struct {
+	Code    string
+	Message string
+}
+
+func (e *EmitError) Error() string {
+	return e.Message
 }
 
 // NewClient creates a new WebSocket client.
@@ -58,7 +163,7 @@ func NewClient(hub *Hub, conn *websocket.Conn, apiKeyID, orgID, projectID string
 	return &Client{
 		hub:             hub,
 		conn:            conn,
-		send:            make(chan []byte, 256),
+		send:            make(chan []byte, sendBufferSize),
 		apiKeyID:        apiKeyID,
 		orgID:           orgID,
 		projectID:       projectID,
@@ -71,6 +176,53 @@ func NewClient(hub *Hub, conn *websocket.Conn, apiKeyID, orgID, projectID string
 	}
 }
 
+// SetMaxSubscriptions caps the distinct subscriptions the client's project
+// may hold across all connections. 0 means unlimited.
+func (c *Client) SetMaxSubscriptions(n int) {
+	c.maxSubscriptions = n
+}
+
+// SetGroupLimits caps the members of each consumer group the client joins
+// and the unacked events it may hold as a member. 0 means unlimited.
+func (c *Client) SetGroupLimits(maxMembers, maxInflight int) {
+	c.maxGroupMembers = maxMembers
+	c.maxInflight = maxInflight
+}
+
+// SetDLQPolicies sets the per-topic dead-letter policies.
+func (c *Client) SetDLQPolicies(p nats.DLQPolicies) {
+	c.dlqPolicies = p
+}
+
+// SetEmitter enables the "emit" action.
+func (c *Client) SetEmitter(e Emitter) {
+	c.emitter = e
+}
+
+// SetDisplayConfigSource enables the display_config subscribe option.
+func (c *Client) SetDisplayConfigSource(src DisplayConfigSource) {
+	c.displayConfigs = src
+}
+
+// SetAuthorizedProjects allows cross-project subscriptions over projects,
+// for admin keys.
+func (c *Client) SetAuthorizedProjects(projects []string) {
+	c.authorizedProjects = projects
+}
+
+// SetAuthExpiry closes the connection with auth_expired at t, when the
+// session it was opened with expires. A later call replaces the deadline.
+func (c *Client) SetAuthExpiry(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.authTimer != nil {
+		c.authTimer.Stop()
+	}
+	c.authTimer = time.AfterFunc(time.Until(t), func() {
+		c.hub.closeClient(c, CloseAuthExpired, "session expired, reconnect with fresh credentials")
+	})
+}
+
 // ReadPump reads messages from the WebSocket connection.
 func (c *Client) ReadPump(ctx context.Context, consumerMgr *nats.ConsumerManager) {
 	defer func() {
@@ -87,13 +239,21 @@ func (c *Client) ReadPump(ctx context.Context, consumerMgr *nats.ConsumerManager
 	})
 
 	for {
-		_, message, err := c.conn.ReadMessage()
+		messageType, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				slog.Warn("websocket read error", "error", err)
 			}
 			return
 		}
+		// Once closing, wait for the client's close reply
+		if c.isClosing() {
+			continue
+		}
+		if messageType != websocket.TextMessage {
+			c.closeWithReason(CloseProtocolError, "binary frames are not supported, send JSON text frames")
+			continue
+		}
 
 		c.handleMessage(ctx, message, consumerMgr)
 	}
@@ -115,10 +275,21 @@ func (c *Client) WritePump() {
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
+			if message == nil {
+				// closeAfterSend: everything queued before it is written
+				c.mu.RLock()
+				reason := c.closing
+				c.mu.RUnlock()
+				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(reason.Code(), string(reason)))
+				return
+			}
 
 			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
 				return
 			}
+			if len(c.send) == 0 && c.slow.Load() {
+				c.recoverSlow()
+			}
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
@@ -161,6 +332,33 @@ func (c *Client) handleMessage(ctx context.Context, data []byte, consumerMgr *na
 		}
 		c.handleNack(&nack)
 
+	case "in_progress", "working":
+		var ip InProgressMessage
+		if err := json.Unmarshal(data, &ip); err != nil {
+			c.sendError("INVALID_JSON", "invalid "+msg.Action+" message")
+			return
+		}
+		c.handleInProgress(&ip)
+
+	case "term":
+		var term TermMessage
+		if err := json.Unmarshal(data, &term); err != nil {
+			c.sendError("INVALID_JSON", "invalid term message")
+			return
+		}
+		c.handleTerm(&term)
+
+	case "unsubscribe":
+		c.handleUnsubscribe()
+
+	case "emit":
+		var emit EmitMessage
+		if err := json.Unmarshal(data, &emit); err != nil {
+			c.sendError("INVALID_JSON", "invalid emit message")
+			return
+		}
+		c.handleEmit(ctx, &emit)
+
 	case "ping":
 		c.sendJSON(NewPongMessage())
 
@@ -185,14 +383,128 @@ func (c *Client) handleSubscribe(ctx context.Context, msg *SubscribeMessage, con
 		return
 	}
 
+	// Cross-project subscriptions are for admin keys, and only over the
+	// projects the key is authorized for
+	var projects []string
+	if len(msg.Options.Projects) > 0 {
+		if c.authorizedProjects == nil {
+			c.sendError("FORBIDDEN", "subscribing across projects requires an admin key")
+			return
+		}
+		for _, projectID := range msg.Options.Projects {
+			if !slices.Contains(c.authorizedProjects, projectID) {
+				c.sendError("FORBIDDEN", "project not authorized for this key: "+projectID)
+				return
+			}
+		}
+		projects = slices.Compact(slices.Sorted(slices.Values(msg.Options.Projects)))
+	}
+
+	// Only v1 exists today; pinning lets clients fail fast once it changes
+	envelopeVersion := msg.Options.EnvelopeVersion
+	if envelopeVersion == 0 {
+		envelopeVersion = domain.EnvelopeVersion
+	}
+	if !domain.SupportedEnvelopeVersion(envelopeVersion) {
+		c.sendError("UNSUPPORTED_ENVELOPE_VERSION", fmt.Sprintf("envelope_version %d is not supported", envelopeVersion))
+		return
+	}
+
+	sample, sampleRate := msg.Options.Sample, msg.Options.SampleRate
+	if sample < 0 || sampleRate < 0 || sampleRate > 1 || (sample > 0 && sampleRate > 0) {
+		c.sendError("INVALID_SAMPLE", "use either sample (N >= 1, deliver 1 in N) or sample_rate (0 < rate <= 1)")
+		return
+	}
+
+	projection, err := ParseProjection(msg.Options.Project)
+	if err != nil {
+		c.sendError("INVALID_PROJECT", "invalid project: "+err.Error())
+		return
+	}
+
+	if msg.Options.MaxInFlight < 0 {
+		c.sendError("INVALID_OPTIONS", "max_in_flight must not be negative")
+		return
+	}
+
+	if msg.Options.Sequential && msg.Options.Group == "" {
+		c.sendError("INVALID_OPTIONS", "sequential requires a consumer group")
+		return
+	}
+
+	untilCaughtUp := false
+	switch msg.Options.Until {
+	case "":
+	case UntilCaughtUp:
+		if msg.Options.Group != "" {
+			c.sendError("INVALID_OPTIONS", "until caught_up is not supported for consumer groups")
+			return
+		}
+		untilCaughtUp = true
+	default:
+		c.sendError("INVALID_OPTIONS", fmt.Sprintf("unknown until %q (want %q)", msg.Options.Until, UntilCaughtUp))
+		return
+	}
+
+	if err := nats.ValidateFrom(msg.Options.From); err != nil {
+		c.sendError("INVALID_OPTIONS", err.Error())
+		return
+	}
+	if msg.Options.From == "resume" {
+		if msg.Options.Group != "" {
+			c.sendError("INVALID_OPTIONS", "from resume is not supported for consumer groups")
+			return
+		}
+		if _, ok := nats.ParseResumeToken(msg.Options.ResumeToken); !ok {
+			c.sendError("INVALID_OPTIONS", "from resume requires a valid resume_token")
+			return
+		}
+	} else if msg.Options.ResumeToken != "" {
+		c.sendError("INVALID_OPTIONS", "resume_token requires from resume")
+		return
+	}
+
+	var keyPath []string
+	if msg.Options.LatestPerKey != "" {
+		if msg.Options.Group != "" {
+			c.sendError("INVALID_OPTIONS", "latest_per_key is not supported for consumer groups")
+			return
+		}
+		if (msg.Options.From != "" && msg.Options.From != "beginning") || msg.Options.FromSeq > 0 {
+			c.sendError("INVALID_OPTIONS", "latest_per_key requires from beginning")
+			return
+		}
+		if keyPath, err = nats.ParseKeyField(msg.Options.LatestPerKey); err != nil {
+			c.sendError("INVALID_OPTIONS", err.Error())
+			return
+		}
+	}
+
+	if msg.Options.FromSeq > 0 && msg.Options.From != "" {
+		c.sendError("INVALID_OPTIONS", "from_seq cannot be combined with from")
+		return
+	}
+
+	// Without a source the option is off, as echoed in the applied options
+	displayConfig := msg.Options.DisplayConfig && c.displayConfigs != nil
+
 	// Parse options
 	opts := nats.DefaultSubscriptionOptions()
 	opts.Topics = msg.Topics
 	opts.OrgID = c.orgID
 	opts.ProjectID = c.projectID
+	opts.ProjectIDs = projects
 	opts.AutoAck = msg.Options.AutoAck
 	opts.Group = msg.Options.Group
 	opts.From = msg.Options.From
+	opts.FromSeq = msg.Options.FromSeq
+	opts.ResumeToken = msg.Options.ResumeToken
+	opts.Sequential = msg.Options.Sequential
+	opts.MaxInFlight = msg.Options.MaxInFlight
+	if (untilCaughtUp || keyPath != nil) && opts.From == "" && opts.FromSeq == 0 {
+		// Catching up means replaying what's stored
+		opts.From = "beginning"
+	}
 
 	if msg.Options.MaxRetries > 0 {
 		opts.MaxRetries = msg.Options.MaxRetries
@@ -200,54 +512,345 @@ func (c *Client) handleSubscribe(ctx context.Context, msg *SubscribeMessage, con
 	if msg.Options.AckTimeout != "" {
 		opts.AckTimeout = ParseDuration(msg.Options.AckTimeout)
 	}
+	opts.Clamp()
+	opts.AttemptLimit = c.dlqPolicies.AttemptLimit(opts.Topics, opts.MaxRetries)
+
+	// Scanned before the consumer exists: later events are all delivered
+	var snapshot *nats.KeySnapshot
+	if keyPath != nil {
+		if snapshot, err = consumerMgr.LatestPerKey(ctx, opts, keyPath); err != nil {
+			slog.Error("failed to scan stored events", "error", err, "client_id", c.clientID)
+			c.sendError("CONSUMER_ERROR", "failed to build the latest_per_key snapshot")
+			return
+		}
+	}
+
+	// Members of a consumer group share one consumer, so one slot
+	subKey := "client:" + c.clientID
+	if opts.Group != "" {
+		subKey = groupSubKeyPrefix + opts.Group
+	}
+	// Re-subscribing to the same group keeps the member's slot
+	c.mu.RLock()
+	rejoin := c.subKey == subKey
+	c.mu.RUnlock()
+	if c.hub != nil && !rejoin {
+		switch err := c.hub.AcquireSubscription(c.projectID, subKey, c.maxSubscriptions, c.maxGroupMembers); {
+		case errors.Is(err, ErrGroupFull):
+			c.sendError("GROUP_FULL", fmt.Sprintf("consumer group %q is full: groups allow %d members", opts.Group, c.maxGroupMembers))
+			return
+		case err != nil:
+			c.sendError("LIMIT_EXCEEDED", fmt.Sprintf("subscription limit reached: this project allows %d active subscriptions", c.maxSubscriptions))
+			return
+		}
+	}
 
 	c.mu.Lock()
 	c.autoAck = opts.AutoAck
 	c.maxRetries = opts.MaxRetries
+	c.ackTimeout = opts.AckTimeout
 	c.group = opts.Group
+	c.sampleEvery = sample
+	c.sampleRate = sampleRate
+	c.sampleSeen = 0
+	c.projection = projection
+	c.envelopeVersion = envelopeVersion
+	c.crossProject = len(projects) > 0
 	c.mu.Unlock()
 
 	// Create consumer
 	consumer, err := consumerMgr.CreateConsumer(ctx, opts)
 	if err != nil {
-		slog.Error("failed to create consumer", "error", err)
-		c.sendError("CONSUMER_ERROR", "failed to create subscription")
+		if c.hub != nil && !rejoin {
+			c.hub.ReleaseSubscription(c.projectID, subKey)
+		}
+		code, message := consumerErrorCode(err)
+		slog.Error("failed to create consumer", "error", err, "code", code)
+		c.sendError(code, message)
 		return
 	}
 
+	info, _ := consumer.Info(ctx)
+	consumerName := ""
+	var stored uint64
+	maxInFlight := opts.MaxInFlight
+	if info != nil {
+		consumerName = info.Name
+		stored = info.NumPending
+		if maxInFlight > 0 {
+			// A group keeps the cap it was created with
+			maxInFlight = info.Config.MaxAckPending
+		}
+	}
+
 	c.mu.Lock()
+	if c.consumerContext != nil {
+		// A new subscribe replaces the previous one
+		c.consumerContext.Stop()
+		c.consumerContext = nil
+	}
 	c.consumer = consumer
+	c.consumerName = consumerName
+	c.saturated = false
+	c.slow.Store(false)
+	c.dropped = 0
+	paused := c.paused
+	prevSubKey := c.subKey
+	c.subKey = subKey
+	c.untilCaughtUp = untilCaughtUp
+	c.catchUpRemaining = stored
+	c.snapshot = snapshot
+	c.resumeCursor = nats.ResumeCursor(opts.ResumeToken)
+	c.displayTopics = nil
+	if displayConfig {
+		// Normalized so a standalone "*" overlaps every schema's pattern
+		c.displayTopics = nats.NormalizeTopics(msg.Topics)
+	}
 	c.mu.Unlock()
 
-	// Start consuming
-	consCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+	if c.hub != nil && prevSubKey != "" && !rejoin {
+		c.hub.ReleaseSubscription(c.projectID, prevSubKey)
+	}
+	if c.hub != nil && prevSubKey != subKey {
+		c.hub.rebalanceGroup(c.projectID, prevSubKey, c)
+		c.hub.rebalanceGroup(c.projectID, subKey, c)
+	}
+
+	// Start consuming, unless the org is drained: resume starts it later
+	if !paused {
+		if err := c.startConsuming(); err != nil {
+			slog.Error("failed to start consuming", "error", err)
+			c.sendError("CONSUMER_ERROR", "failed to start subscription")
+			return
+		}
+	}
+
+	c.sendJSON(NewSubscribedMessage(msg.Topics, consumerName, &AppliedOptions{
+		AutoAck:    opts.AutoAck,
+		From:       opts.From,
+		FromSeq:    opts.FromSeq,
+		Group:      opts.Group,
+		MaxRetries: opts.MaxRetries,
+		AckTimeout: opts.AckTimeout.String(),
+
+		EnvelopeVersion: envelopeVersion,
+
+		Sample:     sample,
+		SampleRate: sampleRate,
+
+		Sequential: nats.IsSequential(consumer),
+		Until:      msg.Options.Until,
+
+		DisplayConfig: displayConfig,
+
+		Project:  msg.Options.Project,
+		Projects: projects,
+
+		MaxInFlight: maxInFlight,
+
+		LatestPerKey: msg.Options.LatestPerKey,
+
+		ResumeToken: opts.ResumeToken,
+	}))
+	if displayConfig {
+		c.pushDisplayConfigs(ctx)
+	}
+	slog.Info("client subscribed", "topics", msg.Topics, "consumer", consumerName, "client_id", c.clientID)
+	if paused {
+		c.sendJSON(NewDrainingMessage())
+	}
+	if untilCaughtUp && stored == 0 {
+		c.finishCatchUp()
+	}
+}
+
+// pushDisplayConfigs sends the display configs for the subscribed topics,
+// if the subscription asked for them.
+func (c *Client) pushDisplayConfigs(ctx context.Context) {
+	if msg := c.loadDisplayConfigs(ctx); msg != nil {
+		c.sendJSON(msg)
+	}
+}
+
+// loadDisplayConfigs builds the display config push for the subscribed
+// topics, or returns nil when the subscription didn't ask for one.
+func (c *Client) loadDisplayConfigs(ctx context.Context) *DisplayConfigMessage {
+	c.mu.RLock()
+	topics := c.displayTopics
+	c.mu.RUnlock()
+	if topics == nil {
+		return nil
+	}
+
+	configs, err := c.displayConfigs(ctx, c.projectID, topics)
+	if err != nil {
+		slog.Error("failed to load display configs", "error", err, "client_id", c.clientID)
+		return nil
+	}
+	return NewDisplayConfigMessage(configs)
+}
+
+// catchUp counts a delivery toward an until=caught_up subscription. It
+// reports whether the delivery comes after the subscription caught up (and
+// must not be sent), and whether it is the last stored event.
+func (c *Client) catchUp(attempt int) (past, last bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.untilCaughtUp {
+		return false, false
+	}
+	if c.catchUpRemaining == 0 {
+		return true, false
+	}
+	if attempt > 1 {
+		return false, false
+	}
+	c.catchUpRemaining--
+	return false, c.catchUpRemaining == 0
+}
+
+// finishCatchUp ends an until=caught_up subscription with a "done" frame.
+func (c *Client) finishCatchUp() {
+	c.sendJSON(NewDoneMessage())
+	c.closeAfterSend(CloseCaughtUp)
+	slog.Info("client caught up", "client_id", c.clientID)
+}
+
+// startConsuming pulls from the client's consumer, if it has one and isn't
+// already consuming.
+func (c *Client) startConsuming() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.consumer == nil || c.consumerContext != nil || c.saturated || (c.slow.Load() && !c.autoAck) {
+		return nil
+	}
+	var opts []jetstream.PullConsumeOpt
+	if c.group != "" && c.maxInflight > 0 {
+		// Don't buffer more than the member may still take
+		opts = append(opts, jetstream.PullMaxMessages(max(1, c.maxInflight-len(c.pendingMessages))))
+	}
+	consCtx, err := c.consumer.Consume(func(msg jetstream.Msg) {
 		c.deliverMessage(msg)
-	})
+	}, opts...)
 	if err != nil {
-		slog.Error("failed to start consuming", "error", err)
-		c.sendError("CONSUMER_ERROR", "failed to start subscription")
+		return err
+	}
+	c.consumerContext = consCtx
+	return nil
+}
+
+// unsaturate resumes pulling for a group member that stopped at its
+// in-flight cap, once it holds fewer unacked events.
+func (c *Client) unsaturate() {
+	c.mu.Lock()
+	if !c.saturated || len(c.pendingMessages) >= c.maxInflight {
+		c.mu.Unlock()
 		return
 	}
+	c.saturated = false
+	paused := c.paused
+	c.mu.Unlock()
 
-	info, _ := consumer.Info(ctx)
-	consumerName := ""
-	if info != nil {
-		consumerName = info.Name
+	if paused {
+		return
 	}
+	if err := c.startConsuming(); err != nil {
+		slog.Error("failed to resume consuming", "error", err, "client_id", c.clientID)
+		c.sendError("CONSUMER_ERROR", "failed to resume subscription")
+	}
+}
 
+// pause stops pulling new events while the client's org is drained.
+// In-flight events stay pending and can still be acked or nacked.
+func (c *Client) pause() {
 	c.mu.Lock()
-	c.consumerContext = consCtx
-	c.consumerName = consumerName
+	if c.paused {
+		c.mu.Unlock()
+		return
+	}
+	c.paused = true
+	if c.consumerContext != nil {
+		c.consumerContext.Stop()
+		c.consumerContext = nil
+	}
 	c.mu.Unlock()
 
-	c.sendJSON(NewSubscribedMessage(msg.Topics, consumerName))
-	slog.Info("client subscribed", "topics", msg.Topics, "consumer", consumerName, "client_id", c.clientID)
+	c.sendJSON(NewDrainingMessage())
+}
+
+// JetStream API error codes without a jetstream.Err* value. The server
+// reports a malformed filter subject as an invalid consumer config; the
+// only user-supplied part of the config is the topics.
+const (
+	jsErrCodeInvalidConfig   jetstream.ErrorCode = 10052
+	jsErrCodeFilterNotSubset jetstream.ErrorCode = 10093
+)
+
+// consumerErrorCode maps a consumer creation failure to the subscribe error
+// code and message sent to the client. Unrecognized errors stay a generic
+// CONSUMER_ERROR so server internals aren't leaked.
+func consumerErrorCode(err error) (code, message string) {
+	if errors.Is(err, nats.ErrBeforeRetention) || errors.Is(err, nats.ErrInvalidFrom) {
+		return "INVALID_OPTIONS", err.Error()
+	}
+	var jsErr jetstream.JetStreamError
+	if errors.As(err, &jsErr) && jsErr.APIError() != nil {
+		apiErr := jsErr.APIError()
+		switch apiErr.ErrorCode {
+		case jetstream.JSErrCodeMaximumConsumersLimit:
+			return "TOO_MANY_CONSUMERS", "too many active subscriptions on the server, retry later"
+		case jetstream.JSErrCodeStreamNotFound:
+			return "STREAM_NOT_FOUND", "event stream not found"
+		case jsErrCodeInvalidConfig, jsErrCodeFilterNotSubset,
+			jetstream.JSErrCodeOverlappingFilterSubjects,
+			jetstream.JSErrCodeDuplicateFilterSubjects,
+			jetstream.JSErrCodeConsumerEmptyFilter:
+			return "INVALID_FILTER", "invalid topic filter: " + apiErr.Description
+		}
+	}
+	return "CONSUMER_ERROR", "failed to create subscription"
+}
+
+// resume restarts delivery after pause.
+func (c *Client) resume() {
+	c.mu.Lock()
+	if !c.paused {
+		c.mu.Unlock()
+		return
+	}
+	c.paused = false
+	c.mu.Unlock()
+
+	if err := c.startConsuming(); err != nil {
+		slog.Error("failed to resume consuming", "error", err, "client_id", c.clientID)
+		c.sendError("CONSUMER_ERROR", "failed to resume subscription")
+		return
+	}
+	c.sendJSON(NewResumedMessage())
+}
+
+// admitFanout reports whether the event is within its fan-out limit for
+// this subscription. Consumer group members share one slot, as they share
+// the consumer, and rank above ephemeral subscriptions.
+func (c *Client) admitFanout(event *domain.Event, consumerName, group string) bool {
+	if c.hub == nil {
+		return true
+	}
+	receiver := consumerName
+	if receiver == "" {
+		receiver = c.clientID
+	}
+	priority := nats.FanoutPriorityLow
+	if group != "" {
+		priority = nats.FanoutPriorityHigh
+	}
+	return c.hub.fanout.Admit(context.Background(), event, "websocket:"+receiver, priority)
 }
 
 func (c *Client) deliverMessage(msg jetstream.Msg) {
 	// Parse the event from NATS message
-	var event domain.Event
-	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+	event, err := nats.EventFromMsg(msg)
+	if err != nil {
 		slog.Error("failed to unmarshal event", "error", err)
 		msg.Nak()
 		return
@@ -256,16 +859,63 @@ func (c *Client) deliverMessage(msg jetstream.Msg) {
 	// Get metadata for attempt count
 	meta, _ := msg.Metadata()
 	attempt := 1
+	var streamSeq, consumerSeq uint64
 	if meta != nil {
 		attempt = int(meta.NumDelivered)
+		streamSeq, consumerSeq = meta.Sequence.Stream, meta.Sequence.Consumer
+	}
+
+	past, last := c.catchUp(attempt)
+	if past {
+		// Newer than the catch-up point; the connection is closing
+		return
+	}
+	if last {
+		defer c.finishCatchUp()
+	}
+
+	c.mu.RLock()
+	snapshot := c.snapshot
+	c.mu.RUnlock()
+	if snapshot.Skip(streamSeq) {
+		// A later stored event has the same key
+		msg.Ack()
+		return
+	}
+
+	// Redeliveries were sampled in the first time around
+	if attempt == 1 && !c.sampleIn() {
+		msg.Ack()
+		return
 	}
 
 	c.mu.RLock()
 	autoAck := c.autoAck
 	maxRetries := c.maxRetries
+	ackTimeout := c.ackTimeout
 	consumerName := c.consumerName
+	group := c.group
+	projection := c.projection
+	envelopeVersion := c.envelopeVersion
+	crossProject := c.crossProject
 	c.mu.RUnlock()
 
+	if attempt > c.dlqPolicies.For(event.Topic).AttemptLimit(maxRetries) {
+		// Redelivered after its last attempt's ack timeout ran out
+		c.giveUp(&pendingMsg{msg: msg, event: event, attempt: attempt, streamSeq: streamSeq}, group, "ack timeout exceeded at max retries")
+		return
+	}
+
+	if !c.admitFanout(event, consumerName, group) {
+		msg.Ack()
+		return
+	}
+
+	if len(c.send) >= sendBufferEvents {
+		c.overflow(msg, event, autoAck)
+		return
+	}
+
 	// Track delivery in database
 	var deliveryID pgtype.UUID
 	if c.queries != nil {
@@ -288,7 +938,22 @@ func (c *Client) deliverMessage(msg jetstream.Msg) {
 	}
 
 	// Send to client
-	eventMsg := NewEventMessage(event.ID, event.Topic, event.Data, event.Timestamp, attempt, maxRetries)
+	maxAttempts := c.dlqPolicies.For(event.Topic).AttemptLimit(maxRetries)
+	data := event.Data
+	if projection != nil {
+		data = projection.Apply(data)
+	}
+	eventMsg := NewEventMessage(event.ID, event.Topic, data, event.Timestamp, attempt, maxAttempts)
+	if envelopeVersion != 0 {
+		eventMsg.EnvelopeVersion = envelopeVersion
+	}
+	eventMsg.StreamSeq, eventMsg.ConsumerSeq = streamSeq, consumerSeq
+	eventMsg.Attachments = event.Attachments
+	eventMsg.Traceparent = event.Traceparent
+	eventMsg.AckDeadline = event.AckDeadline
+	if crossProject {
+		eventMsg.ProjectID = event.ProjectID
+	}
 	c.sendJSON(eventMsg)
 
 	if autoAck {
@@ -301,34 +966,222 @@ func (c *Client) deliverMessage(msg jetstream.Msg) {
 		}
 	} else {
 		// Store for manual ack with metadata for DLQ handling
-		c.mu.Lock()
-		c.pendingMessages[event.ID] = &pendingMsg{
+		pending := &pendingMsg{
 			msg:        msg,
-			event:      &event,
+			event:      event,
 			attempt:    attempt,
+			streamSeq:  streamSeq,
 			deliveryID: deliveryID,
 		}
+		c.mu.Lock()
+		c.pendingMessages[event.ID] = pending
+		if c.group != "" && c.maxInflight > 0 && len(c.pendingMessages) >= c.maxInflight && c.consumerContext != nil {
+			// This member holds its share; the others take the rest
+			c.consumerContext.Stop()
+			c.consumerContext = nil
+			c.saturated = true
+		}
+		c.mu.Unlock()
+
+		if event.AckDeadline != "" {
+			if deadline, err := nats.ParseAckDeadline(event.AckDeadline); err == nil && deadline != ackTimeout {
+				c.enforceAckDeadline(pending, deadline, ackTimeout)
+			}
+		}
+	}
+}
+
+// enforceAckDeadline applies an event's ack deadline in place of the
+// subscription's ack timeout. A longer deadline keeps the message in
+// progress until it passes; a shorter one hands it back early. Either
+// stops once the event is settled or the subscription ends.
+func (c *Client) enforceAckDeadline(pending *pendingMsg, deadline, ackTimeout time.Duration) {
+	if deadline < ackTimeout {
+		time.AfterFunc(deadline, func() { c.expireAckDeadline(pending) })
+		return
+	}
+
+	// Each in-progress restarts JetStream's ack timer at ackTimeout; renew it
+	// well before it runs out, and last so that it runs out at the deadline
+	expires := time.Now().Add(deadline)
+	renewEvery := ackTimeout * 3 / 4
+	var renew func()
+	next := func() {
+		if wait := time.Until(expires) - ackTimeout; wait > 0 {
+			time.AfterFunc(min(wait, renewEvery), renew)
+		}
+	}
+	renew = func() {
+		if !c.isPending(pending) {
+			return
+		}
+		if err := pending.msg.InProgress(); err != nil {
+			slog.Warn("failed to extend ack deadline", "error", err, "event_id", pending.event.ID)
+			return
+		}
+		next()
+	}
+	next()
+}
+
+// isPending reports whether pending is still awaiting its ack on this
+// connection.
+func (c *Client) isPending(pending *pendingMsg) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pendingMessages[pending.event.ID] == pending
+}
+
+// expireAckDeadline hands back an event its subscriber didn't settle within
+// the event's ack deadline, as if its ack timeout had run out: redelivered,
+// or moved to the DLQ when out of retries.
+func (c *Client) expireAckDeadline(pending *pendingMsg) {
+	c.mu.Lock()
+	if c.pendingMessages[pending.event.ID] != pending {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.pendingMessages, pending.event.ID)
+	maxRetries := c.maxRetries
+	group := c.group
+	c.mu.Unlock()
+	defer c.unsaturate()
+
+	if c.exhausted(pending, maxRetries) {
+		c.giveUp(pending, group, "ack deadline exceeded at max retries")
+		return
+	}
+	if err := pending.msg.Nak(); err != nil {
+		slog.Error("failed to nack", "error", err, "event_id", pending.event.ID)
+		return
+	}
+	if c.queries != nil && pending.deliveryID.Valid {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		c.queries.UpdateEventDeliveryNacked(ctx, db.UpdateEventDeliveryNackedParams{
+			ID:    pending.deliveryID,
+			Error: pgtype.Text{String: "ack deadline exceeded", Valid: true},
+		})
+		cancel()
+	}
+	slog.Debug("event ack deadline exceeded", "event_id", pending.event.ID)
+}
+
+// overflow handles an event that doesn't fit the send buffer of a client
+// reading too slowly. Auto-ack subscriptions drop it; manual-ack ones hand
+// it back for redelivery and stop pulling until the buffer drains. The
+// client is warned once per episode with a SLOW_CONSUMER error.
+func (c *Client) overflow(msg jetstream.Msg, event *domain.Event, autoAck bool) {
+	c.mu.Lock()
+	first := !c.slow.Swap(true)
+	if autoAck {
+		c.dropped++
+	} else if c.consumerContext != nil {
+		c.consumerContext.Stop()
+		c.consumerContext = nil
+	}
+	c.mu.Unlock()
+
+	if autoAck {
+		msg.Ack()
+	} else {
+		msg.Nak()
+	}
+	if !first {
+		return
+	}
+
+	slog.Warn("slow consumer", "client_id", c.clientID, "event_id", event.ID, "auto_ack", autoAck)
+	if autoAck {
+		c.sendError("SLOW_CONSUMER", "send buffer full: dropping events until the client catches up")
+	} else {
+		c.sendError("SLOW_CONSUMER", "send buffer full: delivery paused until the client catches up")
+	}
+}
+
+// recoverSlow ends a slow-consumer episode once the send buffer has
+// drained, resuming manual-ack delivery.
+func (c *Client) recoverSlow() {
+	c.mu.Lock()
+	if !c.slow.Swap(false) {
 		c.mu.Unlock()
+		return
+	}
+	dropped := c.dropped
+	c.dropped = 0
+	paused := c.paused
+	c.mu.Unlock()
+
+	if dropped > 0 {
+		slog.Warn("slow consumer caught up", "client_id", c.clientID, "dropped", dropped)
+	}
+	if paused {
+		return
+	}
+	if err := c.startConsuming(); err != nil {
+		slog.Error("failed to resume consuming", "error", err, "client_id", c.clientID)
+		c.sendError("CONSUMER_ERROR", "failed to resume subscription")
 	}
 }
 
+// sampleIn reports whether the next first-time delivery passes the
+// subscription's sampling, counting it toward 1-in-N sampling.
+func (c *Client) sampleIn() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.sampleEvery > 1:
+		c.sampleSeen++
+		return c.sampleSeen%uint64(c.sampleEvery) == 1
+	case c.sampleRate > 0:
+		return rand.Float64() < c.sampleRate
+	}
+	return true
+}
+
 func (c *Client) handleAck(msg *AckMessage) {
+	ids := msg.EventIDs()
+	if len(ids) == 0 {
+		c.sendError("INVALID_IDS", "id or ids required")
+		return
+	}
+	// Confirm with a resume token for the furthest event acked
+	var acked []string
+	var lastSeq uint64
+	for _, id := range ids {
+		if seq, ok := c.ackEvent(id); ok {
+			acked = append(acked, id)
+			lastSeq = max(lastSeq, seq)
+		}
+	}
+	if lastSeq > 0 {
+		c.mu.RLock()
+		cursor := c.resumeCursor
+		c.mu.RUnlock()
+		c.sendJSON(NewAckedMessage(acked, nats.CursorResumeToken(cursor, lastSeq)))
+	}
+}
+
+// ackEvent acks one pending event, returning its stream sequence.
+func (c *Client) ackEvent(eventID string) (uint64, bool) {
 	c.mu.Lock()
-	pending, ok := c.pendingMessages[msg.ID]
+	pending, ok := c.pendingMessages[eventID]
 	if ok {
-		delete(c.pendingMessages, msg.ID)
+		delete(c.pendingMessages, eventID)
 	}
 	c.mu.Unlock()
+	if ok {
+		defer c.unsaturate()
+	}
 
 	if !ok {
-		c.sendError("UNKNOWN_EVENT", "unknown event ID: "+msg.ID)
-		return
+		c.sendError("UNKNOWN_EVENT", "unknown event ID: "+eventID)
+		return 0, false
 	}
 
 	if err := pending.msg.Ack(); err != nil {
-		slog.Error("failed to ack", "error", err, "event_id", msg.ID)
+		slog.Error("failed to ack", "error", err, "event_id", eventID)
 		c.sendError("ACK_ERROR", "failed to acknowledge")
-		return
+		return 0, false
 	}
 
 	// Track ACK in database
@@ -338,50 +1191,124 @@ func (c *Client) handleAck(msg *AckMessage) {
 		cancel()
 	}
 
-	slog.Debug("event acked", "event_id", msg.ID)
+	slog.Debug("event acked", "event_id", eventID)
+	return pending.streamSeq, true
+}
+
+func (c *Client) handleInProgress(msg *InProgressMessage) {
+	ids := msg.EventIDs()
+	if len(ids) == 0 {
+		c.sendError("INVALID_IDS", "id or ids required")
+		return
+	}
+	for _, id := range ids {
+		c.mu.RLock()
+		pending, ok := c.pendingMessages[id]
+		c.mu.RUnlock()
+
+		if !ok {
+			c.sendError("UNKNOWN_EVENT", "unknown event ID: "+id)
+			continue
+		}
+		if err := pending.msg.InProgress(); err != nil {
+			slog.Error("failed to extend ack deadline", "error", err, "event_id", id)
+			c.sendError("ACK_ERROR", "failed to extend ack deadline")
+		}
+	}
+}
+
+func (c *Client) handleTerm(msg *TermMessage) {
+	ids := msg.EventIDs()
+	if len(ids) == 0 {
+		c.sendError("INVALID_IDS", "id or ids required")
+		return
+	}
+	reason := msg.Reason
+	if reason == "" {
+		reason = "terminated by client"
+	}
+	for _, id := range ids {
+		c.termEvent(id, reason)
+	}
+}
+
+func (c *Client) termEvent(eventID, reason string) {
+	c.mu.Lock()
+	pending, ok := c.pendingMessages[eventID]
+	if ok {
+		delete(c.pendingMessages, eventID)
+	}
+	c.mu.Unlock()
+	if ok {
+		defer c.unsaturate()
+	}
+
+	if !ok {
+		c.sendError("UNKNOWN_EVENT", "unknown event ID: "+eventID)
+		return
+	}
+
+	if err := pending.msg.TermWithReason(reason); err != nil {
+		slog.Error("failed to terminate message", "error", err, "event_id", eventID)
+		c.sendError("ACK_ERROR", "failed to terminate")
+		return
+	}
+
+	if c.queries != nil && pending.deliveryID.Valid {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		c.queries.UpdateEventDeliveryStatus(ctx, db.UpdateEventDeliveryStatusParams{
+			ID:     pending.deliveryID,
+			Status: "terminated",
+			Error:  pgtype.Text{String: reason, Valid: true},
+		})
+		cancel()
+	}
+
+	slog.Debug("event terminated", "event_id", eventID, "reason", reason)
 }
 
 func (c *Client) handleNack(msg *NackMessage) {
+	ids := msg.EventIDs()
+	if len(ids) == 0 {
+		c.sendError("INVALID_IDS", "id or ids required")
+		return
+	}
+	for _, id := range ids {
+		c.nackEvent(id, msg.RetryIn)
+	}
+}
+
+func (c *Client) nackEvent(eventID, retryIn string) {
 	c.mu.Lock()
-	pending, ok := c.pendingMessages[msg.ID]
+	pending, ok := c.pendingMessages[eventID]
 	if ok {
-		delete(c.pendingMessages, msg.ID)
+		delete(c.pendingMessages, eventID)
 	}
 	maxRetries := c.maxRetries
 	group := c.group
 	c.mu.Unlock()
+	if ok {
+		defer c.unsaturate()
+	}
 
 	if !ok {
-		c.sendError("UNKNOWN_EVENT", "unknown event ID: "+msg.ID)
+		c.sendError("UNKNOWN_EVENT", "unknown event ID: "+eventID)
 		return
 	}
 
-	// If at max retries, move to DLQ instead of nacking
-	if pending.attempt >= maxRetries {
-		c.moveToDLQ(pending, group, "max retries exceeded")
-		if err := pending.msg.Term(); err != nil {
-			slog.Error("failed to terminate message", "error", err, "event_id", msg.ID)
-		}
-		// Track DLQ in database
-		if c.queries != nil && pending.deliveryID.Valid {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			c.queries.UpdateEventDeliveryDLQ(ctx, db.UpdateEventDeliveryDLQParams{
-				ID:    pending.deliveryID,
-				Error: pgtype.Text{String: "max retries exceeded", Valid: true},
-			})
-			cancel()
-		}
-		slog.Info("event moved to DLQ", "event_id", msg.ID, "attempts", pending.attempt)
+	// If at max retries, move to DLQ (or drop) instead of nacking
+	if c.exhausted(pending, maxRetries) {
+		c.giveUp(pending, group, "max retries exceeded")
 		return
 	}
 
-	delay := ParseDuration(msg.RetryIn)
+	delay := ParseDuration(retryIn)
 	if delay == 0 {
 		delay = 5 * time.Minute
 	}
 
 	if err := pending.msg.NakWithDelay(delay); err != nil {
-		slog.Error("failed to nack", "error", err, "event_id", msg.ID)
+		slog.Error("failed to nack", "error", err, "event_id", eventID)
 		c.sendError("NACK_ERROR", "failed to negative acknowledge")
 		return
 	}
@@ -396,33 +1323,106 @@ func (c *Client) handleNack(msg *NackMessage) {
 		cancel()
 	}
 
-	slog.Debug("event nacked", "event_id", msg.ID, "retry_in", delay)
+	slog.Debug("event nacked", "event_id", eventID, "retry_in", delay)
 }
 
-func (c *Client) cleanup() {
+// handleUnsubscribe ends the current subscription, freeing its slot toward
+// the project's subscription cap. Pending events are handed back as on
+// disconnect.
+func (c *Client) handleUnsubscribe() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.consumer == nil {
+		c.mu.Unlock()
+		c.sendError("NOT_SUBSCRIBED", "no active subscription")
+		return
+	}
+	if c.consumerContext != nil {
+		c.consumerContext.Stop()
+		c.consumerContext = nil
+	}
+	c.consumer = nil
+	c.saturated = false
+	c.slow.Store(false)
+	c.dropped = 0
+	c.releasePending("client unsubscribed")
+	c.pendingMessages = make(map[string]*pendingMsg)
+	subKey := c.subKey
+	c.subKey = ""
+	c.displayTopics = nil
+	c.mu.Unlock()
+
+	if c.hub != nil && subKey != "" {
+		c.hub.ReleaseSubscription(c.projectID, subKey)
+		c.hub.rebalanceGroup(c.projectID, subKey, c)
+	}
+	c.sendJSON(NewUnsubscribedMessage())
+	slog.Info("client unsubscribed", "client_id", c.clientID)
+}
+
+// handleEmit publishes an event. It runs on the read loop, so emits from one
+// connection are published and answered in order.
+func (c *Client) handleEmit(ctx context.Context, msg *EmitMessage) {
+	if c.emitter == nil {
+		c.sendError("EMIT_UNAVAILABLE", "emit is not enabled on this connection")
+		return
+	}
+
+	c.mu.RLock()
+	paused := c.paused
+	c.mu.RUnlock()
+	if paused {
+		c.sendError("ORG_DRAINED", "org is drained for maintenance, retry later")
+		return
+	}
 
+	resp, err := c.emitter(ctx, &domain.EmitRequest{
+		Topic:       msg.Topic,
+		Data:        msg.Data,
+		Attachments: msg.Attachments,
+		AckDeadline: msg.AckDeadline,
+	})
+	if err != nil {
+		if emitErr, ok := err.(*EmitError); ok {
+			c.sendError(emitErr.Code, emitErr.Message)
+			return
+		}
+		slog.Error("websocket emit failed", "error", err, "client_id", c.clientID)
+		c.sendError("EMIT_FAILED", "failed to emit event")
+		return
+	}
+	c.sendJSON(NewEmittedMessage(resp))
+}
+
+func (c *Client) cleanup() {
+	c.mu.Lock()
+	if c.authTimer != nil {
+		c.authTimer.Stop()
+	}
 	if c.consumerContext != nil {
 		c.consumerContext.Stop()
 	}
 
-	// Handle pending messages - either nack for retry or move to DLQ
+	c.releasePending("client disconnected")
+	c.pendingMessages = nil
+
+	subKey := c.subKey
+	c.subKey = ""
+	c.mu.Unlock()
+
+	// Outside c.mu: rebalancing locks every member's mutex
+	if c.hub != nil && subKey != "" {
+		c.hub.ReleaseSubscription(c.projectID, subKey)
+		c.hub.rebalanceGroup(c.projectID, subKey, c)
+	}
+}
+
+// releasePending hands unacked events back: nacked for redelivery, or moved
+// to the DLQ when out of retries. Caller must hold c.mu.
+func (c *Client) releasePending(reason string) {
 	for _, pending := range c.pendingMessages {
-		if pending.attempt >= c.maxRetries {
-			// At max retries, move to DLQ
-			c.moveToDLQ(pending, c.group, "client disconnected at max retries")
-			pending.msg.Term()
-			// Track DLQ in database
-			if c.queries != nil && pending.deliveryID.Valid {
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-				c.queries.UpdateEventDeliveryDLQ(ctx, db.UpdateEventDeliveryDLQParams{
-					ID:    pending.deliveryID,
-					Error: pgtype.Text{String: "client disconnected at max retries", Valid: true},
-				})
-				cancel()
-			}
-			slog.Info("event moved to DLQ on disconnect", "event_id", pending.event.ID)
+		if c.exhausted(pending, c.maxRetries) {
+			// At max retries, move to DLQ (or drop)
+			c.giveUp(pending, c.group, reason+" at max retries")
 		} else {
 			// Still has retries left, nack for redelivery
 			pending.msg.Nak()
@@ -431,13 +1431,54 @@ func (c *Client) cleanup() {
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				c.queries.UpdateEventDeliveryNacked(ctx, db.UpdateEventDeliveryNackedParams{
 					ID:    pending.deliveryID,
-					Error: pgtype.Text{String: "client disconnected", Valid: true},
+					Error: pgtype.Text{String: reason, Valid: true},
 				})
 				cancel()
 			}
 		}
 	}
-	c.pendingMessages = nil
+}
+
+// exhausted reports whether pending has used all its attempts: the
+// subscription's max retries, or fewer if its topic's DLQ policy says so.
+func (c *Client) exhausted(pending *pendingMsg, maxRetries int) bool {
+	return pending.attempt >= c.dlqPolicies.For(pending.event.Topic).AttemptLimit(maxRetries)
+}
+
+// giveUp stops redelivery of an exhausted event, moving it to the DLQ unless
+// its topic's policy drops it.
+func (c *Client) giveUp(pending *pendingMsg, group, reason string) {
+	drop := c.dlqPolicies.For(pending.event.Topic).Drops()
+	if !drop {
+		c.moveToDLQ(pending, group, reason)
+	}
+	if err := pending.msg.Term(); err != nil {
+		slog.Error("failed to terminate message", "error", err, "event_id", pending.event.ID)
+	}
+
+	// Track DLQ (or drop) in database
+	if c.queries != nil && pending.deliveryID.Valid {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if drop {
+			c.queries.UpdateEventDeliveryStatus(ctx, db.UpdateEventDeliveryStatusParams{
+				ID:     pending.deliveryID,
+				Status: "dropped",
+				Error:  pgtype.Text{String: reason, Valid: true},
+			})
+		} else {
+			c.queries.UpdateEventDeliveryDLQ(ctx, db.UpdateEventDeliveryDLQParams{
+				ID:    pending.deliveryID,
+				Error: pgtype.Text{String: reason, Valid: true},
+			})
+		}
+		cancel()
+	}
+
+	if drop {
+		slog.Info("event dropped by DLQ policy", "event_id", pending.event.ID, "attempts", pending.attempt, "reason", reason)
+	} else {
+		slog.Info("event moved to DLQ", "event_id", pending.event.ID, "attempts", pending.attempt, "reason", reason)
+	}
 }
 
 func (c *Client) moveToDLQ(pending *pendingMsg, group, reason string) {
@@ -445,10 +1486,16 @@ func (c *Client) moveToDLQ(pending *pendingMsg, group, reason string) {
 		return
 	}
 
+	// Cross-project events dead-letter into the project they came from
+	projectID := pending.event.ProjectID
+	if projectID == "" {
+		projectID = c.projectID
+	}
+
 	dlqMsg := &nats.DLQMessage{
 		ID:            pending.event.ID,
 		OrgID:         c.orgID,
-		ProjectID:     c.projectID,
+		ProjectID:     projectID,
 		OriginalTopic: pending.event.Topic,
 		Data:          pending.event.Data,
 		Timestamp:     pending.event.Timestamp,
@@ -476,10 +1523,86 @@ func (c *Client) sendJSON(v any) {
 	select {
 	case c.send <- data:
 	default:
-		slog.Warn("client send buffer full, dropping message")
+		// Events leave room for control frames; a client that lets even
+		// those pile up isn't reading
+		slog.Warn("client send buffer full, dropping message", "client_id", c.clientID)
+		if c.beginClose(CloseSlowConsumer) {
+			c.closeNow()
+		}
 	}
 }
 
+// closeAfterSend closes the connection for reason once every frame queued
+// so far has been written, waiting for room in the send buffer if needed.
+// The caller has sent the frame explaining the close.
+func (c *Client) closeAfterSend(reason CloseReason) {
+	if !c.beginClose(reason) {
+		return
+	}
+	select {
+	case c.send <- nil:
+	case <-time.After(writeWait):
+		slog.Warn("client send buffer full, closing without flushing", "client_id", c.clientID)
+		c.closeNow()
+	}
+}
+
+// closeWithReason closes the connection for reason after the frames queued
+// so far, or right away if they fill the send buffer. It never blocks, so
+// the hub can call it while holding its lock.
+func (c *Client) closeWithReason(reason CloseReason, message string) {
+	if !c.beginClose(reason) {
+		return
+	}
+	slog.Info("closing websocket client", "client_id", c.clientID, "reason", reason)
+
+	data, err := json.Marshal(NewClosingMessage(reason, message))
+	if err == nil {
+		select {
+		case c.send <- data:
+			select {
+			case c.send <- nil:
+				return
+			default:
+			}
+		default:
+		}
+	}
+	c.closeNow()
+}
+
+// beginClose records reason as why the connection is closing. It reports
+// false if it was already closing.
+func (c *Client) beginClose(reason CloseReason) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing != "" {
+		return false
+	}
+	c.closing = reason
+	return true
+}
+
+// isClosing reports whether the server is closing the connection.
+func (c *Client) isClosing() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.closing != ""
+}
+
+// closeNow sends the close frame for the closing reason without waiting for
+// queued frames, then closes the connection.
+func (c *Client) closeNow() {
+	if c.conn == nil {
+		return
+	}
+	c.mu.RLock()
+	reason := c.closing
+	c.mu.RUnlock()
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(reason.Code(), string(reason)), time.Now().Add(writeWait))
+	c.conn.Close()
+}
+
 func (c *Client) sendError(code, message string) {
 	c.sendJSON(NewErrorMessage(code, message))
 }
@@ -0,0 +1,1622 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/filipexyz/notif/internal/nats"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// fakeMsg is an in-memory jetstream.Msg that records ack calls.
+type fakeMsg struct {
+	mu     sync.Mutex
+	data   []byte
+	acked  bool
+	nacked bool
+	termed bool
+	reason string
+	// inProgress counts ack deadline extensions
+	inProgress int
+	delay      time.Duration
+	// delivered is the delivery count reported; 0 reports a first delivery
+	delivered uint64
+}
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{
+		NumDelivered: max(m.delivered, 1),
+		Sequence:     jetstream.SequencePair{Stream: 42, Consumer: 7},
+	}, nil
+}
+func (m *fakeMsg) Data() []byte                    { return m.data }
+func (m *fakeMsg) Headers() natsgo.Header          { return nil }
+func (m *fakeMsg) Subject() string                 { return "" }
+func (m *fakeMsg) Reply() string                   { return "" }
+func (m *fakeMsg) DoubleAck(context.Context) error { return m.Ack() }
+func (m *fakeMsg) Nak() error                      { return m.NakWithDelay(0) }
+
+func (m *fakeMsg) InProgress() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inProgress++
+	return nil
+}
+
+func (m *fakeMsg) TermWithReason(reason string) error {
+	m.mu.Lock()
+	m.reason = reason
+	m.mu.Unlock()
+	return m.Term()
+}
+
+func (m *fakeMsg) Ack() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.acked = true
+	return nil
+}
+
+func (m *fakeMsg) NakWithDelay(delay time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nacked = true
+	m.delay = delay
+	return nil
+}
+
+func (m *fakeMsg) Term() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.termed = true
+	return nil
+}
+
+func newTestClient() *Client {
+	return NewClient(nil, nil, "key", "org_test", "prj_test", nil, nil, "ws_test", 1<<20)
+}
+
+// newTestConsumerManager starts an embedded JetStream server with the
+// notif streams and returns a ConsumerManager bound to it.
+func newTestConsumerManager(t *testing.T) *nats.ConsumerManager {
+	t.Helper()
+	consumerMgr, _ := newTestJetStream(t)
+	return consumerMgr
+}
+
+// newTestJetStream is newTestConsumerManager that also returns a publisher
+// for emitting events into the stream.
+func newTestJetStream(t *testing.T) (*nats.ConsumerManager, *nats.Publisher) {
+	t.Helper()
+	nc := newTestNATS(t)
+	return nats.NewConsumerManager(nc.Stream()), nats.NewPublisher(nc.JetStream())
+}
+
+// newTestNATS starts an embedded JetStream server with the notif streams and
+// returns a client connected to it.
+func newTestNATS(t *testing.T) *nats.Client {
+	t.Helper()
+	srv, err := nats.StartEmbedded(nats.EmbeddedConfig{
+		StoreDir: t.TempDir(),
+		Port:     -1,
+	})
+	if err != nil {
+		t.Fatalf("start embedded: %v", err)
+	}
+	t.Cleanup(srv.Shutdown)
+
+	nc, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	if err := nc.EnsureStreams(context.Background()); err != nil {
+		t.Fatalf("ensure streams: %v", err)
+	}
+	return nc
+}
+
+// addPending registers a fake in-flight message for manual ack.
+func addPending(c *Client, id string) *fakeMsg {
+	msg := &fakeMsg{}
+	c.pendingMessages[id] = &pendingMsg{
+		msg:     msg,
+		event:   &domain.Event{ID: id, Topic: "orders.created"},
+		attempt: 1,
+	}
+	return msg
+}
+
+// drainSent returns all frames queued for the client so far.
+func drainSent(t *testing.T, c *Client) []map[string]any {
+	t.Helper()
+	var frames []map[string]any
+	for {
+		select {
+		case data := <-c.send:
+			var frame map[string]any
+			if err := json.Unmarshal(data, &frame); err != nil {
+				t.Fatalf("invalid frame: %v", err)
+			}
+			frames = append(frames, frame)
+		default:
+			return frames
+		}
+	}
+}
+
+func TestHandleAck_Batch(t *testing.T) {
+	c := newTestClient()
+	msgs := []*fakeMsg{addPending(c, "evt_1"), addPending(c, "evt_2"), addPending(c, "evt_3")}
+
+	c.handleMessage(context.Background(), []byte(`{"action":"ack","ids":["evt_1","evt_2","evt_3"]}`), nil)
+
+	for i, m := range msgs {
+		if !m.acked {
+			t.Errorf("message %d not acked", i)
+		}
+	}
+	if len(c.pendingMessages) != 0 {
+		t.Errorf("expected no pending messages, got %d", len(c.pendingMessages))
+	}
+	if frames := drainSent(t, c); len(frames) != 0 {
+		t.Errorf("expected no error frames, got %v", frames)
+	}
+}
+
+func TestHandleAck_BatchUnknownID(t *testing.T) {
+	c := newTestClient()
+	known := addPending(c, "evt_1")
+
+	c.handleMessage(context.Background(), []byte(`{"action":"ack","ids":["evt_1","evt_missing"]}`), nil)
+
+	if !known.acked {
+		t.Error("known message should be acked")
+	}
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["code"] != "UNKNOWN_EVENT" {
+		t.Errorf("expected one UNKNOWN_EVENT error, got %v", frames)
+	}
+}
+
+func TestHandleAck_Single(t *testing.T) {
+	c := newTestClient()
+	msg := addPending(c, "evt_1")
+
+	c.handleMessage(context.Background(), []byte(`{"action":"ack","id":"evt_1"}`), nil)
+
+	if !msg.acked {
+		t.Error("message not acked")
+	}
+}
+
+func TestHandleAck_NoIDs(t *testing.T) {
+	c := newTestClient()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"ack"}`), nil)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["code"] != "INVALID_IDS" {
+		t.Errorf("expected INVALID_IDS error, got %v", frames)
+	}
+}
+
+func TestHandleNack_Batch(t *testing.T) {
+	c := newTestClient()
+	msgs := []*fakeMsg{addPending(c, "evt_1"), addPending(c, "evt_2")}
+
+	c.handleMessage(context.Background(), []byte(`{"action":"nack","ids":["evt_1","evt_2"],"retry_in":"30s"}`), nil)
+
+	for i, m := range msgs {
+		if !m.nacked {
+			t.Errorf("message %d not nacked", i)
+		}
+		if m.delay != 30*time.Second {
+			t.Errorf("message %d: expected 30s delay, got %v", i, m.delay)
+		}
+	}
+	if len(c.pendingMessages) != 0 {
+		t.Errorf("expected no pending messages, got %d", len(c.pendingMessages))
+	}
+}
+
+func TestHandleInProgress(t *testing.T) {
+	c := newTestClient()
+	msgs := []*fakeMsg{addPending(c, "evt_1"), addPending(c, "evt_2")}
+
+	c.handleMessage(context.Background(), []byte(`{"action":"in_progress","ids":["evt_1","evt_2"]}`), nil)
+	c.handleMessage(context.Background(), []byte(`{"action":"in_progress","id":"evt_1"}`), nil)
+
+	if msgs[0].inProgress != 2 || msgs[1].inProgress != 1 {
+		t.Errorf("expected 2 and 1 deadline extensions, got %d and %d", msgs[0].inProgress, msgs[1].inProgress)
+	}
+	for i, m := range msgs {
+		if m.acked || m.nacked || m.termed {
+			t.Errorf("message %d should still be in flight", i)
+		}
+	}
+	// Still pending, so the handler can ack once done
+	if len(c.pendingMessages) != 2 {
+		t.Errorf("expected 2 pending messages, got %d", len(c.pendingMessages))
+	}
+	if frames := drainSent(t, c); len(frames) != 0 {
+		t.Errorf("expected no frames, got %v", frames)
+	}
+}
+
+func TestHandleWorking_PreventsRedelivery(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["jobs.*"],"options":{"auto_ack":false,"ack_timeout":"1s"}}`), consumerMgr)
+	if frames := drainSent(t, c); len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", frames)
+	}
+
+	event := domain.NewEvent("jobs.slow", json.RawMessage(`{}`))
+	event.OrgID, event.ProjectID = "org_test", "prj_test"
+	if err := pub.Publish(context.Background(), event); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	nextEvent := func(wait time.Duration) map[string]any {
+		deadline := time.After(wait)
+		for {
+			select {
+			case data := <-c.send:
+				var frame map[string]any
+				if err := json.Unmarshal(data, &frame); err != nil {
+					t.Fatalf("invalid frame: %v", err)
+				}
+				if frame["type"] == "error" {
+					t.Fatalf("unexpected error frame: %v", frame)
+				}
+				if frame["type"] == "event" {
+					return frame
+				}
+			case <-deadline:
+				return nil
+			}
+		}
+	}
+	if nextEvent(5*time.Second) == nil {
+		t.Fatal("event not delivered")
+	}
+
+	// Keep working well past the 1s ack timeout
+	working := []byte(`{"action":"working","id":"` + event.ID + `"}`)
+	for i := 0; i < 6; i++ {
+		time.Sleep(400 * time.Millisecond)
+		c.handleMessage(context.Background(), working, consumerMgr)
+		if frame := nextEvent(0); frame != nil {
+			t.Fatalf("event redelivered while working: %v", frame)
+		}
+	}
+
+	// Once the signals stop, the ack timeout applies again
+	frame := nextEvent(3 * time.Second)
+	if frame == nil || frame["attempt"] != float64(2) {
+		t.Fatalf("expected redelivery after working stopped, got %v", frame)
+	}
+}
+
+func TestHandleTerm(t *testing.T) {
+	c := newTestClient()
+	msg := addPending(c, "evt_1")
+
+	c.handleMessage(context.Background(), []byte(`{"action":"term","id":"evt_1","reason":"poison message"}`), nil)
+
+	if !msg.termed {
+		t.Fatal("message not terminated")
+	}
+	if msg.reason != "poison message" {
+		t.Errorf("expected reason 'poison message', got %q", msg.reason)
+	}
+	if msg.nacked {
+		t.Error("terminated message must not be nacked for redelivery")
+	}
+	if len(c.pendingMessages) != 0 {
+		t.Errorf("expected no pending messages, got %d", len(c.pendingMessages))
+	}
+
+	// A terminated event can no longer be acked
+	c.handleMessage(context.Background(), []byte(`{"action":"ack","id":"evt_1"}`), nil)
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["code"] != "UNKNOWN_EVENT" {
+		t.Errorf("expected UNKNOWN_EVENT error, got %v", frames)
+	}
+}
+
+func TestHandleTerm_DefaultReason(t *testing.T) {
+	c := newTestClient()
+	msg := addPending(c, "evt_1")
+
+	c.handleMessage(context.Background(), []byte(`{"action":"term","ids":["evt_1"]}`), nil)
+
+	if msg.reason != "terminated by client" {
+		t.Errorf("expected default reason, got %q", msg.reason)
+	}
+}
+
+func TestHandleSubscribe_AppliedOptions(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{
+		"action": "subscribe",
+		"topics": ["orders.*"],
+		"options": {"max_retries": 5000, "ack_timeout": "2ms"}
+	}`), consumerMgr)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", frames)
+	}
+	if frames[0]["consumer_id"] == "" {
+		t.Error("expected resolved consumer_id")
+	}
+
+	opts, ok := frames[0]["options"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected options in subscribed frame, got %v", frames[0])
+	}
+	if opts["max_retries"] != float64(nats.MaxSubscriptionRetries) {
+		t.Errorf("expected max_retries clamped to %d, got %v", nats.MaxSubscriptionRetries, opts["max_retries"])
+	}
+	if opts["ack_timeout"] != nats.MinAckTimeout.String() {
+		t.Errorf("expected ack_timeout clamped to %s, got %v", nats.MinAckTimeout, opts["ack_timeout"])
+	}
+	if opts["from"] != "latest" {
+		t.Errorf("expected from defaulted to latest, got %v", opts["from"])
+	}
+	if opts["auto_ack"] != false {
+		t.Errorf("expected auto_ack false, got %v", opts["auto_ack"])
+	}
+}
+
+func TestHandleSubscribe_InvalidFilter(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+
+	for _, topics := range []string{`["orders..created"]`, `["orders.>.x"]`, `["orders.*.shipped","orders.eu.*"]`} {
+		c := newTestClient()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":`+topics+`}`), consumerMgr)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "INVALID_FILTER" {
+			t.Errorf("%s: expected INVALID_FILTER error, got %v", topics, frames)
+		}
+		c.cleanup()
+	}
+}
+
+func TestHandleSubscribe_CoveredTopics(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+
+	// A standalone "*" and topics covered by another pattern no longer
+	// collide in JetStream, so these subscribe instead of failing.
+	for _, topics := range []string{`["*","orders.created"]`, `["orders.*","orders.created"]`, `["a.*.b.>"]`} {
+		c := newTestClient()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":`+topics+`}`), consumerMgr)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+			t.Errorf("%s: expected subscribed frame, got %v", topics, frames)
+		}
+		c.cleanup()
+	}
+}
+
+func TestConsumerErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{fmt.Errorf("create consumer: %w", jetstream.ErrMaximumConsumersLimit), "TOO_MANY_CONSUMERS"},
+		{fmt.Errorf("create consumer: %w", jetstream.ErrStreamNotFound), "STREAM_NOT_FOUND"},
+		{fmt.Errorf("create consumer: %w", jetstream.ErrOverlappingFilterSubjects), "INVALID_FILTER"},
+		{fmt.Errorf("%w: the stream keeps 24h0m0s of events", nats.ErrBeforeRetention), "INVALID_OPTIONS"},
+		{fmt.Errorf("%w \"yesterday\"", nats.ErrInvalidFrom), "INVALID_OPTIONS"},
+		{errors.New("connection closed"), "CONSUMER_ERROR"},
+	}
+	for _, tt := range tests {
+		if code, _ := consumerErrorCode(tt.err); code != tt.code {
+			t.Errorf("consumerErrorCode(%v) = %s, want %s", tt.err, code, tt.code)
+		}
+	}
+}
+
+func TestHandleSubscribe_DefaultOptions(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"auto_ack":true}}`), consumerMgr)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 {
+		t.Fatalf("expected one frame, got %v", frames)
+	}
+	opts, _ := frames[0]["options"].(map[string]any)
+	if opts["max_retries"] != float64(5) {
+		t.Errorf("expected default max_retries 5, got %v", opts["max_retries"])
+	}
+	if opts["ack_timeout"] != "5m0s" {
+		t.Errorf("expected default ack_timeout 5m0s, got %v", opts["ack_timeout"])
+	}
+	if opts["auto_ack"] != true {
+		t.Errorf("expected auto_ack true, got %v", opts["auto_ack"])
+	}
+}
+
+func TestDeliverMessage_DefaultEnvelope(t *testing.T) {
+	c := newTestClient()
+	event := domain.NewEvent("orders.created", json.RawMessage(`{"order_id":"123"}`))
+	data, _ := json.Marshal(event)
+
+	c.deliverMessage(&fakeMsg{data: data})
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "event" {
+		t.Fatalf("expected one event frame, got %v", frames)
+	}
+	if frames[0]["envelope_version"] != float64(1) {
+		t.Errorf("expected envelope_version 1, got %v", frames[0]["envelope_version"])
+	}
+	for _, field := range []string{"id", "topic", "data", "timestamp", "attempt", "max_attempts"} {
+		if _, ok := frames[0][field]; !ok {
+			t.Errorf("v1 envelope missing %q", field)
+		}
+	}
+	if frames[0]["stream_seq"] != float64(42) || frames[0]["consumer_seq"] != float64(7) {
+		t.Errorf("expected stream_seq 42 and consumer_seq 7, got %v and %v", frames[0]["stream_seq"], frames[0]["consumer_seq"])
+	}
+}
+
+func TestHandleSubscribe_EnvelopeVersion(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+
+	t.Run("v1", func(t *testing.T) {
+		c := newTestClient()
+		defer c.cleanup()
+
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"envelope_version":1}}`), consumerMgr)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+			t.Fatalf("expected subscribed frame, got %v", frames)
+		}
+		opts, _ := frames[0]["options"].(map[string]any)
+		if opts["envelope_version"] != float64(1) {
+			t.Errorf("expected envelope_version 1, got %v", opts["envelope_version"])
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		c := newTestClient()
+		defer c.cleanup()
+
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"envelope_version":2}}`), consumerMgr)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "UNSUPPORTED_ENVELOPE_VERSION" {
+			t.Errorf("expected UNSUPPORTED_ENVELOPE_VERSION error, got %v", frames)
+		}
+	})
+}
+
+// countEvents drains event frames until none arrive for a short while.
+func countEvents(t *testing.T, c *Client) int {
+	t.Helper()
+	n := 0
+	for {
+		select {
+		case data := <-c.send:
+			var frame map[string]any
+			if err := json.Unmarshal(data, &frame); err != nil {
+				t.Fatalf("invalid frame: %v", err)
+			}
+			if frame["type"] == "event" {
+				n++
+			}
+		case <-time.After(500 * time.Millisecond):
+			return n
+		}
+	}
+}
+
+func TestHandleSubscribe_Sample(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  string
+		min, max int
+	}{
+		{"one in four", `{"auto_ack":true,"sample":4}`, 20, 30},
+		{"rate", `{"auto_ack":true,"sample_rate":0.25}`, 10, 40},
+		{"no sampling", `{"auto_ack":true}`, 100, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumerMgr, pub := newTestJetStream(t)
+			c := newTestClient()
+			defer c.cleanup()
+
+			c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":`+tt.options+`}`), consumerMgr)
+			if frames := drainSent(t, c); len(frames) != 1 || frames[0]["type"] != "subscribed" {
+				t.Fatalf("expected subscribed frame, got %v", frames)
+			}
+
+			for i := 0; i < 100; i++ {
+				event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+				event.OrgID, event.ProjectID = "org_test", "prj_test"
+				if err := pub.Publish(context.Background(), event); err != nil {
+					t.Fatalf("publish: %v", err)
+				}
+			}
+
+			if got := countEvents(t, c); got < tt.min || got > tt.max {
+				t.Errorf("delivered %d of 100 events, want %d-%d", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestDeliverMessage_SampleAcksSkipped(t *testing.T) {
+	c := newTestClient()
+	c.sampleEvery = 4
+
+	var msgs []*fakeMsg
+	for i := 0; i < 8; i++ {
+		data, _ := json.Marshal(domain.NewEvent("orders.created", json.RawMessage(`{}`)))
+		msg := &fakeMsg{data: data}
+		msgs = append(msgs, msg)
+		c.deliverMessage(msg)
+	}
+
+	if frames := drainSent(t, c); len(frames) != 2 {
+		t.Fatalf("expected 2 of 8 events delivered, got %d", len(frames))
+	}
+	if len(c.pendingMessages) != 2 {
+		t.Errorf("expected 2 pending for manual ack, got %d", len(c.pendingMessages))
+	}
+	skipped := 0
+	for _, m := range msgs {
+		if m.acked {
+			skipped++
+		}
+	}
+	if skipped != 6 {
+		t.Errorf("expected 6 skipped events acked, got %d", skipped)
+	}
+}
+
+func TestHandleSubscribe_InvalidSample(t *testing.T) {
+	c := newTestClient()
+	for _, options := range []string{`{"sample":-1}`, `{"sample_rate":1.5}`, `{"sample":4,"sample_rate":0.5}`} {
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":`+options+`}`), nil)
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "INVALID_SAMPLE" {
+			t.Errorf("options %s: expected INVALID_SAMPLE, got %v", options, frames)
+		}
+	}
+}
+
+func TestDeliverMessage_Projection(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		want    string
+	}{
+		{"fields", `["id","customer.email","missing"]`, `{"customer":{"email":"a@example.com"},"id":"ord_1"}`},
+		{"jq", `"{id, total: .amount}"`, `{"id":"ord_1","total":42}`},
+		{"jq error", `".id | tonumber"`, `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projection, err := ParseProjection(json.RawMessage(tt.project))
+			if err != nil {
+				t.Fatalf("ParseProjection: %v", err)
+			}
+			c := newTestClient()
+			c.projection = projection
+
+			original := json.RawMessage(`{"id":"ord_1","amount":42,"customer":{"email":"a@example.com","name":"Ann"}}`)
+			data, _ := json.Marshal(domain.NewEvent("orders.created", original))
+			c.deliverMessage(&fakeMsg{data: data})
+
+			frames := drainSent(t, c)
+			if len(frames) != 1 || frames[0]["type"] != "event" {
+				t.Fatalf("expected one event frame, got %v", frames)
+			}
+			got, _ := json.Marshal(frames[0]["data"])
+			if string(got) != tt.want {
+				t.Errorf("data = %s, want %s", got, tt.want)
+			}
+
+			// Acks and the DLQ work on the full event
+			for _, p := range c.pendingMessages {
+				if string(p.event.Data) != string(original) {
+					t.Errorf("pending event data = %s, want the original", p.event.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSubscribe_InvalidProject(t *testing.T) {
+	c := newTestClient()
+	for _, project := range []string{`"{"`, `[]`, `["a..b"]`, `[1]`, `42`} {
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"project":`+project+`}}`), nil)
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "INVALID_PROJECT" {
+			t.Errorf("project %s: expected INVALID_PROJECT, got %v", project, frames)
+		}
+	}
+}
+
+func TestHandleSubscribe_Project(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"auto_ack":true,"project":["id"]}}`), consumerMgr)
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", frames)
+	}
+	applied, _ := frames[0]["options"].(map[string]any)
+	if got, _ := json.Marshal(applied["project"]); string(got) != `["id"]` {
+		t.Errorf("applied project = %s, want [\"id\"]", got)
+	}
+
+	event := domain.NewEvent("orders.created", json.RawMessage(`{"id":"ord_1","secret":"s3cr3t"}`))
+	event.OrgID, event.ProjectID = "org_test", "prj_test"
+	if err := pub.Publish(context.Background(), event); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case data := <-c.send:
+		var frame EventMessage
+		if err := json.Unmarshal(data, &frame); err != nil {
+			t.Fatalf("invalid frame: %v", err)
+		}
+		if string(frame.Data) != `{"id":"ord_1"}` {
+			t.Errorf("data = %s, want only the id", frame.Data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event delivered")
+	}
+}
+
+func TestHandleEmit_DeliveredToSubscriber(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+
+	sub := newTestClient()
+	defer sub.cleanup()
+	sub.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["chat.*"],"options":{"auto_ack":true}}`), consumerMgr)
+	if frames := drainSent(t, sub); len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", frames)
+	}
+
+	emitter := newTestClient()
+	emitter.SetEmitter(func(ctx context.Context, req *domain.EmitRequest) (*domain.EmitResponse, error) {
+		event := domain.NewEvent(req.Topic, req.Data)
+		event.OrgID, event.ProjectID = emitter.orgID, emitter.projectID
+		if err := pub.Publish(ctx, event); err != nil {
+			return nil, err
+		}
+		return &domain.EmitResponse{ID: event.ID, Topic: event.Topic, CreatedAt: event.Timestamp}, nil
+	})
+	emitter.handleMessage(context.Background(), []byte(`{"action":"emit","topic":"chat.message","data":{"text":"hi"}}`), nil)
+
+	frames := drainSent(t, emitter)
+	if len(frames) != 1 || frames[0]["type"] != "emitted" {
+		t.Fatalf("expected emitted frame, got %v", frames)
+	}
+	id := frames[0]["id"]
+
+	select {
+	case data := <-sub.send:
+		var frame map[string]any
+		if err := json.Unmarshal(data, &frame); err != nil {
+			t.Fatalf("invalid frame: %v", err)
+		}
+		if frame["type"] != "event" || frame["id"] != id || frame["topic"] != "chat.message" {
+			t.Errorf("expected event %v on chat.message, got %v", id, frame)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber did not receive the emitted event")
+	}
+}
+
+func TestHandleEmit_Rejected(t *testing.T) {
+	c := newTestClient()
+	c.SetEmitter(func(ctx context.Context, req *domain.EmitRequest) (*domain.EmitResponse, error) {
+		return nil, &EmitError{Code: "INVALID_TOPIC", Message: "topic is required"}
+	})
+
+	c.handleMessage(context.Background(), []byte(`{"action":"emit","data":{}}`), nil)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "error" || frames[0]["code"] != "INVALID_TOPIC" {
+		t.Fatalf("expected INVALID_TOPIC error, got %v", frames)
+	}
+}
+
+func TestHandleEmit_Unavailable(t *testing.T) {
+	c := newTestClient()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"emit","topic":"chat.message","data":{}}`), nil)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["code"] != "EMIT_UNAVAILABLE" {
+		t.Fatalf("expected EMIT_UNAVAILABLE error, got %v", frames)
+	}
+}
+
+func TestHandleEmit_Drained(t *testing.T) {
+	c := newTestClient()
+	called := false
+	c.SetEmitter(func(ctx context.Context, req *domain.EmitRequest) (*domain.EmitResponse, error) {
+		called = true
+		return &domain.EmitResponse{}, nil
+	})
+	c.paused = true
+
+	c.handleMessage(context.Background(), []byte(`{"action":"emit","topic":"chat.message","data":{}}`), nil)
+
+	if called {
+		t.Error("emitter called while org is drained")
+	}
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["code"] != "ORG_DRAINED" {
+		t.Fatalf("expected ORG_DRAINED error, got %v", frames)
+	}
+}
+
+func TestNack_DLQPolicies(t *testing.T) {
+	policies, err := nats.ParseDLQPolicies("audit.>=drop,payments.*=dlq-after-1")
+	if err != nil {
+		t.Fatalf("parse policies: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		topic   string
+		attempt int
+		wantDLQ int64
+	}{
+		{"drop never dead-letters", "audit.login", 5, 0},
+		{"dlq-after-1 dead-letters on first nack", "payments.charged", 1, 1},
+		{"default waits for max retries", "orders.created", 1, 0},
+		{"default dead-letters at max retries", "orders.created", 5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nc := newTestNATS(t)
+			dlqReader, err := nats.NewDLQReader(nc.JetStream())
+			if err != nil {
+				t.Fatalf("dlq reader: %v", err)
+			}
+			c := NewClient(nil, nil, "key", "org_test", "prj_test", nats.NewDLQPublisher(nc.JetStream()), nil, "ws_test", 1<<20)
+			c.SetDLQPolicies(policies)
+
+			msg := &fakeMsg{}
+			c.pendingMessages["evt_1"] = &pendingMsg{
+				msg:     msg,
+				event:   &domain.Event{ID: "evt_1", Topic: tt.topic, OrgID: "org_test", ProjectID: "prj_test"},
+				attempt: tt.attempt,
+			}
+			c.handleMessage(context.Background(), []byte(`{"action":"nack","id":"evt_1"}`), nil)
+
+			got, err := dlqReader.Count(context.Background(), "org_test", "prj_test")
+			if err != nil {
+				t.Fatalf("count DLQ: %v", err)
+			}
+			if got != tt.wantDLQ {
+				t.Errorf("DLQ has %d messages, want %d", got, tt.wantDLQ)
+			}
+			exhausted := tt.wantDLQ > 0 || tt.topic == "audit.login"
+			if msg.termed != exhausted || msg.nacked == exhausted {
+				t.Errorf("termed=%v nacked=%v, want exhausted=%v", msg.termed, msg.nacked, exhausted)
+			}
+		})
+	}
+}
+
+func TestDeliverMessage_RedeliveryPastDLQPolicy(t *testing.T) {
+	policies, err := nats.ParseDLQPolicies("payments.*=dlq-after-1")
+	if err != nil {
+		t.Fatalf("parse policies: %v", err)
+	}
+	nc := newTestNATS(t)
+	dlqReader, err := nats.NewDLQReader(nc.JetStream())
+	if err != nil {
+		t.Fatalf("dlq reader: %v", err)
+	}
+	c := NewClient(nil, nil, "key", "org_test", "prj_test", nats.NewDLQPublisher(nc.JetStream()), nil, "ws_test", 1<<20)
+	c.SetDLQPolicies(policies)
+
+	event := domain.NewEvent("payments.charged", json.RawMessage(`{}`))
+	event.OrgID, event.ProjectID = "org_test", "prj_test"
+	data, _ := json.Marshal(event)
+
+	// The first attempt's ack timeout ran out in JetStream
+	msg := &fakeMsg{data: data, delivered: 2}
+	c.deliverMessage(msg)
+
+	if frames := drainSent(t, c); len(frames) != 0 {
+		t.Errorf("expected nothing sent past the policy's attempts, got %v", frames)
+	}
+	if !msg.termed {
+		t.Error("expected the redelivery terminated")
+	}
+	if got, _ := dlqReader.Count(context.Background(), "org_test", "prj_test"); got != 1 {
+		t.Errorf("DLQ has %d messages, want 1", got)
+	}
+}
+
+func TestHandleSubscribe_Sequential(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"fulfillment","sequential":true}}`), consumerMgr)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", frames)
+	}
+	opts, _ := frames[0]["options"].(map[string]any)
+	if opts["sequential"] != true {
+		t.Errorf("expected sequential applied, got %v", opts["sequential"])
+	}
+}
+
+func TestHandleSubscribe_SequentialRequiresGroup(t *testing.T) {
+	c := newTestClient()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"sequential":true}}`), nil)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["code"] != "INVALID_OPTIONS" {
+		t.Fatalf("expected INVALID_OPTIONS error, got %v", frames)
+	}
+}
+
+// readUntilClose returns the frames queued for the client up to
+// closeAfterSend's marker, failing if it doesn't arrive in time.
+func readUntilClose(t *testing.T, c *Client) []map[string]any {
+	t.Helper()
+	var frames []map[string]any
+	for {
+		select {
+		case data := <-c.send:
+			if data == nil {
+				return frames
+			}
+			var frame map[string]any
+			if err := json.Unmarshal(data, &frame); err != nil {
+				t.Fatalf("invalid frame: %v", err)
+			}
+			frames = append(frames, frame)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("connection was not closed, got %v", frames)
+		}
+	}
+}
+
+func TestHandleSubscribe_UntilCaughtUp(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	ctx := context.Background()
+
+	var stored []string
+	for i := 0; i < 3; i++ {
+		event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		if err := pub.Publish(ctx, event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		stored = append(stored, event.ID)
+	}
+
+	c := newTestClient()
+	defer c.cleanup()
+	c.handleMessage(ctx, []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"auto_ack":true,"until":"caught_up"}}`), consumerMgr)
+
+	// Published after subscribing: not part of the catch-up
+	later := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+	later.OrgID, later.ProjectID = "org_test", "prj_test"
+	if err := pub.Publish(ctx, later); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	frames := readUntilClose(t, c)
+	if len(frames) != len(stored)+2 {
+		t.Fatalf("expected subscribed, %d events and done, got %v", len(stored), frames)
+	}
+	if frames[0]["type"] != "subscribed" {
+		t.Errorf("expected subscribed first, got %v", frames[0])
+	}
+	for i, id := range stored {
+		if f := frames[i+1]; f["type"] != "event" || f["id"] != id {
+			t.Errorf("frame %d: expected event %s, got %v", i+1, id, f)
+		}
+	}
+	if done := frames[len(frames)-1]; done["type"] != "done" || done["reason"] != "caught_up" {
+		t.Errorf("expected done frame last, got %v", done)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if extra := drainSent(t, c); len(extra) != 0 {
+		t.Errorf("expected nothing after done, got %v", extra)
+	}
+}
+
+func TestHandleSubscribe_UntilCaughtUpEmpty(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"until":"caught_up"}}`), consumerMgr)
+
+	frames := readUntilClose(t, c)
+	if len(frames) != 2 || frames[0]["type"] != "subscribed" || frames[1]["type"] != "done" {
+		t.Fatalf("expected subscribed then done, got %v", frames)
+	}
+	opts, _ := frames[0]["options"].(map[string]any)
+	if opts["from"] != "beginning" || opts["until"] != "caught_up" {
+		t.Errorf("expected from beginning until caught_up, got %v", opts)
+	}
+}
+
+func TestHandleSubscribe_FromSeqInvalid(t *testing.T) {
+	c := newTestClient()
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"from":"beginning","from_seq":3}}`), nil)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["code"] != "INVALID_OPTIONS" {
+		t.Errorf("expected INVALID_OPTIONS error, got %v", frames)
+	}
+}
+
+func TestHandleSubscribe_FromInvalid(t *testing.T) {
+	for _, from := range []string{"yesterday", "2024-01-02", "2024-01-02 10:00:00"} {
+		c := newTestClient()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"from":"`+from+`"}}`), nil)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "INVALID_OPTIONS" {
+			t.Errorf("from %q: expected INVALID_OPTIONS error, got %v", from, frames)
+		}
+	}
+}
+
+func TestHandleSubscribe_UntilInvalid(t *testing.T) {
+	for _, options := range []string{
+		`{"until":"forever"}`,
+		`{"until":"caught_up","group":"batch"}`,
+	} {
+		c := newTestClient()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":`+options+`}`), nil)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "INVALID_OPTIONS" {
+			t.Errorf("options %s: expected INVALID_OPTIONS error, got %v", options, frames)
+		}
+	}
+}
+
+func TestHandleSubscribe_LatestPerKey(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	ctx := context.Background()
+
+	publish := func(data string) *domain.Event {
+		t.Helper()
+		event := domain.NewEvent("prices.updated", json.RawMessage(data))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		if err := pub.Publish(ctx, event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		return event
+	}
+	publish(`{"sku":"a","price":1}`)
+	latestB := publish(`{"sku":"b","price":5}`)
+	latestA := publish(`{"sku":"a","price":2}`)
+
+	c := newTestClient()
+	defer c.cleanup()
+	c.handleMessage(ctx, []byte(`{"action":"subscribe","topics":["prices.*"],"options":{"auto_ack":true,"latest_per_key":"sku"}}`), consumerMgr)
+
+	// Live events are delivered even when their key was in the snapshot
+	live := publish(`{"sku":"a","price":3}`)
+
+	var frames []map[string]any
+	deadline := time.After(5 * time.Second)
+	for len(frames) < 4 {
+		select {
+		case data := <-c.send:
+			var frame map[string]any
+			if err := json.Unmarshal(data, &frame); err != nil {
+				t.Fatalf("invalid frame: %v", err)
+			}
+			frames = append(frames, frame)
+		case <-deadline:
+			t.Fatalf("expected subscribed and 3 events, got %v", frames)
+		}
+	}
+
+	opts, _ := frames[0]["options"].(map[string]any)
+	if frames[0]["type"] != "subscribed" || opts["latest_per_key"] != "sku" || opts["from"] != "beginning" {
+		t.Errorf("expected subscribed from beginning with latest_per_key, got %v", frames[0])
+	}
+	for i, want := range []string{latestB.ID, latestA.ID, live.ID} {
+		if f := frames[i+1]; f["type"] != "event" || f["id"] != want {
+			t.Errorf("frame %d: expected event %s, got %v", i+1, want, f)
+		}
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if extra := drainSent(t, c); len(extra) != 0 {
+		t.Errorf("expected no more events, got %v", extra)
+	}
+}
+
+func TestHandleSubscribe_LatestPerKeyInvalid(t *testing.T) {
+	for _, options := range []string{
+		`{"latest_per_key":"sku","group":"batch"}`,
+		`{"latest_per_key":"sku","from":"latest"}`,
+		`{"latest_per_key":"customer..id"}`,
+	} {
+		c := newTestClient()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["prices.*"],"options":`+options+`}`), nil)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "INVALID_OPTIONS" {
+			t.Errorf("options %s: expected INVALID_OPTIONS error, got %v", options, frames)
+		}
+	}
+}
+
+// fakeDisplayConfigs serves a fixed display config for "orders.*".
+func fakeDisplayConfigs(display string) DisplayConfigSource {
+	return func(_ context.Context, projectID string, topics []string) ([]DisplayConfig, error) {
+		if projectID != "prj_test" || len(topics) != 1 || topics[0] != "orders.*" {
+			return nil, nil
+		}
+		return []DisplayConfig{{
+			Schema:       "order",
+			TopicPattern: "orders.*",
+			Display:      json.RawMessage(display),
+		}}, nil
+	}
+}
+
+func TestHandleSubscribe_DisplayConfig(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	c := newTestClient()
+	defer c.cleanup()
+	c.SetDisplayConfigSource(fakeDisplayConfigs(`{"template":"{{.data.id}}"}`))
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"display_config":true}}`), consumerMgr)
+
+	frames := drainSent(t, c)
+	if len(frames) != 2 || frames[0]["type"] != "subscribed" || frames[1]["type"] != "display_config" {
+		t.Fatalf("expected subscribed then display_config, got %v", frames)
+	}
+	if opts, _ := frames[0]["options"].(map[string]any); opts["display_config"] != true {
+		t.Errorf("expected display_config in applied options, got %v", opts)
+	}
+	configs, _ := frames[1]["configs"].([]any)
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 config, got %v", frames[1])
+	}
+	cfg := configs[0].(map[string]any)
+	display, _ := cfg["display"].(map[string]any)
+	if cfg["schema"] != "order" || cfg["topic_pattern"] != "orders.*" || display["template"] != "{{.data.id}}" {
+		t.Errorf("unexpected config %v", cfg)
+	}
+}
+
+func TestHandleSubscribe_DisplayConfigNotRequested(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	c := newTestClient()
+	defer c.cleanup()
+	c.SetDisplayConfigSource(fakeDisplayConfigs(`{"template":"x"}`))
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"]}`), consumerMgr)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected only subscribed, got %v", frames)
+	}
+}
+
+func TestHandleSubscribe_DisplayConfigUnavailable(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"display_config":true}}`), consumerMgr)
+
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected only subscribed, got %v", frames)
+	}
+	if opts, _ := frames[0]["options"].(map[string]any); opts["display_config"] != nil {
+		t.Errorf("expected display_config off without a source, got %v", opts)
+	}
+}
+
+func TestHandleSubscribe_CrossProject(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	ctx := context.Background()
+
+	want := make(map[string]string)
+	for _, projectID := range []string{"prj_test", "prj_other", "prj_private"} {
+		event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+		event.OrgID, event.ProjectID = "org_test", projectID
+		if err := pub.Publish(ctx, event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		if projectID != "prj_private" {
+			want[event.ID] = projectID
+		}
+	}
+
+	c := newTestClient()
+	defer c.cleanup()
+	c.SetAuthorizedProjects([]string{"prj_test", "prj_other"})
+	c.handleMessage(ctx, []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"auto_ack":true,"from":"beginning","projects":["prj_test","prj_other"]}}`), consumerMgr)
+
+	got := make(map[string]string)
+	deadline := time.After(5 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case data := <-c.send:
+			var frame map[string]any
+			if err := json.Unmarshal(data, &frame); err != nil {
+				t.Fatalf("invalid frame: %v", err)
+			}
+			if frame["type"] == "event" {
+				got[frame["id"].(string)], _ = frame["project_id"].(string)
+			}
+		case <-deadline:
+			t.Fatalf("timed out with events %v, want %v", got, want)
+		}
+	}
+	for id, projectID := range want {
+		if got[id] != projectID {
+			t.Errorf("event %s: project_id %q, want %q", id, got[id], projectID)
+		}
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	for _, f := range drainSent(t, c) {
+		if f["type"] == "event" {
+			t.Errorf("unexpected event from an unlisted project: %v", f)
+		}
+	}
+}
+
+func TestHandleSubscribe_CrossProjectForbidden(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+
+	tests := []struct {
+		name       string
+		authorized []string
+		projects   string
+	}{
+		{"normal key", nil, `["prj_test","prj_other"]`},
+		{"unauthorized project", []string{"prj_test", "prj_other"}, `["prj_test","prj_private"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient()
+			defer c.cleanup()
+			c.SetAuthorizedProjects(tt.authorized)
+			c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"projects":`+tt.projects+`}}`), consumerMgr)
+
+			frames := drainSent(t, c)
+			if len(frames) != 1 || frames[0]["code"] != "FORBIDDEN" {
+				t.Fatalf("expected FORBIDDEN error, got %v", frames)
+			}
+		})
+	}
+}
+
+// nextFrame waits for the next frame sent to c.
+func nextFrame(t *testing.T, c *Client) map[string]any {
+	t.Helper()
+	select {
+	case data := <-c.send:
+		var frame map[string]any
+		if err := json.Unmarshal(data, &frame); err != nil {
+			t.Fatalf("invalid frame: %v", err)
+		}
+		return frame
+	case <-time.After(5 * time.Second):
+		t.Fatal("no frame received")
+		return nil
+	}
+}
+
+func TestHandleAck_ResumeToken(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		if err := pub.Publish(ctx, event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		ids = append(ids, event.ID)
+	}
+
+	first := newTestClient()
+	first.handleMessage(ctx, []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"from":"beginning"}}`), consumerMgr)
+	if f := nextFrame(t, first); f["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", f)
+	}
+	for _, id := range ids[:2] {
+		if f := nextFrame(t, first); f["id"] != id {
+			t.Fatalf("expected event %s, got %v", id, f)
+		}
+	}
+	first.handleMessage(ctx, []byte(`{"action":"ack","ids":["`+ids[0]+`","`+ids[1]+`"]}`), nil)
+
+	var acked map[string]any
+	for acked == nil {
+		if f := nextFrame(t, first); f["type"] == "acked" {
+			acked = f
+		}
+	}
+	first.cleanup()
+	token, _ := acked["resume_token"].(string)
+	if token == "" {
+		t.Fatalf("expected a resume token, got %v", acked)
+	}
+
+	// A new connection presenting the token continues after the acked events
+	second := newTestClient()
+	defer second.cleanup()
+	second.handleMessage(ctx, []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"auto_ack":true,"from":"`+token+`"}}`), consumerMgr)
+	f := nextFrame(t, second)
+	if f["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", f)
+	}
+	if opts, _ := f["options"].(map[string]any); opts["from"] != token {
+		t.Errorf("expected from echoed as the token, got %v", f["options"])
+	}
+	for _, id := range ids[2:] {
+		if f := nextFrame(t, second); f["type"] != "event" || f["id"] != id {
+			t.Errorf("expected event %s, got %v", id, f)
+		}
+	}
+}
+
+func TestHandleSubscribe_ResumeCursor(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		if err := pub.Publish(ctx, event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		ids = append(ids, event.ID)
+	}
+	subscribe := func(c *Client, token string) string {
+		t.Helper()
+		c.handleMessage(ctx, []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"from":"resume","resume_token":"`+token+`","ack_timeout":"1s"}}`), consumerMgr)
+		f := nextFrame(t, c)
+		opts, _ := f["options"].(map[string]any)
+		cursorToken, _ := opts["resume_token"].(string)
+		if f["type"] != "subscribed" || opts["from"] != "resume" || nats.ResumeCursor(cursorToken) == "" {
+			t.Fatalf("expected subscribed with a cursor token, got %v", f)
+		}
+		return cursorToken
+	}
+
+	// A plain token for the second event starts a cursor right after it
+	first := newTestClient()
+	cursorToken := subscribe(first, nats.ResumeToken(2))
+	for _, id := range ids[2:] {
+		if f := nextFrame(t, first); f["id"] != id {
+			t.Fatalf("expected event %s, got %v", id, f)
+		}
+	}
+	first.handleMessage(ctx, []byte(`{"action":"ack","ids":["`+ids[2]+`","`+ids[3]+`"]}`), nil)
+	var acked map[string]any
+	for acked == nil {
+		if f := nextFrame(t, first); f["type"] == "acked" {
+			acked = f
+		}
+	}
+	first.cleanup()
+	if token, _ := acked["resume_token"].(string); nats.ResumeCursor(token) != nats.ResumeCursor(cursorToken) {
+		t.Errorf("acked token %q is not for cursor %q", token, nats.ResumeCursor(cursorToken))
+	}
+
+	// Reconnecting with any token of the cursor continues it: only the
+	// event left unacked comes again, none before it
+	second := newTestClient()
+	defer second.cleanup()
+	subscribe(second, cursorToken)
+	if f := nextFrame(t, second); f["type"] != "event" || f["id"] != ids[4] {
+		t.Errorf("expected unacked event %s again, got %v", ids[4], f)
+	}
+}
+
+func TestHandleSubscribe_ResumeInvalid(t *testing.T) {
+	token := nats.ResumeToken(1)
+	for _, options := range []string{
+		`{"from":"resume"}`,
+		`{"from":"resume","resume_token":"rt_bogus"}`,
+		`{"from":"resume","resume_token":"` + token + `","group":"batch"}`,
+		`{"from":"beginning","resume_token":"` + token + `"}`,
+	} {
+		c := newTestClient()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":`+options+`}`), nil)
+
+		frames := drainSent(t, c)
+		if len(frames) != 1 || frames[0]["code"] != "INVALID_OPTIONS" {
+			t.Errorf("options %s: expected INVALID_OPTIONS error, got %v", options, frames)
+		}
+	}
+}
+
+func TestDeliverMessage_GroupInflightCap(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+
+	// events collects the event frames sent until the client goes quiet
+	events := func(c *Client) []string {
+		t.Helper()
+		var ids []string
+		for {
+			select {
+			case data := <-c.send:
+				var frame map[string]any
+				if err := json.Unmarshal(data, &frame); err != nil {
+					t.Fatalf("invalid frame: %v", err)
+				}
+				if frame["type"] == "event" {
+					ids = append(ids, frame["id"].(string))
+				}
+			case <-time.After(500 * time.Millisecond):
+				return ids
+			}
+		}
+	}
+
+	member := newTestClient()
+	member.SetGroupLimits(0, 2)
+	defer member.cleanup()
+	member.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"workers"}}`), consumerMgr)
+	if frames := drainSent(t, member); len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", frames)
+	}
+
+	for i := 0; i < 5; i++ {
+		event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		if err := pub.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	got := events(member)
+	if len(got) != 2 {
+		t.Fatalf("expected the member to stop at 2 unacked events, got %d", len(got))
+	}
+
+	// Acking frees a place
+	member.handleMessage(context.Background(), []byte(`{"action":"ack","id":"`+got[0]+`"}`), consumerMgr)
+	if more := events(member); len(more) != 1 {
+		t.Errorf("after ack: expected 1 more event, got %d", len(more))
+	}
+
+	// The rest of the backlog goes to the other members
+	other := newTestClient()
+	other.clientID = "ws_other"
+	defer other.cleanup()
+	other.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"workers"}}`), consumerMgr)
+	if rest := events(other); len(rest) != 2 {
+		t.Errorf("other member: expected the remaining 2 events, got %d", len(rest))
+	}
+}
+
+func TestDeliverMessage_AckDeadline(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+
+	// redeliveries collects when each event comes back for a second attempt
+	redeliveries := func(c *Client, start time.Time, wait time.Duration) map[string]time.Duration {
+		t.Helper()
+		got := map[string]time.Duration{}
+		deadline := time.After(wait)
+		for {
+			select {
+			case data := <-c.send:
+				var frame map[string]any
+				if err := json.Unmarshal(data, &frame); err != nil {
+					t.Fatalf("invalid frame: %v", err)
+				}
+				if frame["type"] == "event" && frame["attempt"] == float64(2) {
+					if _, seen := got[frame["id"].(string)]; !seen {
+						got[frame["id"].(string)] = time.Since(start)
+					}
+				}
+			case <-deadline:
+				return got
+			}
+		}
+	}
+	publish := func(topic, ackDeadline string) *domain.Event {
+		t.Helper()
+		event := domain.NewEvent(topic, json.RawMessage(`{}`))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		event.AckDeadline = ackDeadline
+		if err := pub.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		return event
+	}
+
+	t.Run("longer than ack timeout", func(t *testing.T) {
+		c := newTestClient()
+		defer c.cleanup()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["slow.*"],"options":{"ack_timeout":"1s"}}`), consumerMgr)
+		drainSent(t, c)
+
+		start := time.Now()
+		quick := publish("slow.default", "")
+		slow := publish("slow.report", "3s")
+
+		got := redeliveries(c, start, 5*time.Second)
+		if _, ok := got[quick.ID]; !ok {
+			t.Error("event without a deadline was not redelivered after the 1s ack timeout")
+		}
+		if after, ok := got[slow.ID]; !ok || after < 2500*time.Millisecond {
+			t.Errorf("event with a 3s ack_deadline redelivered after %s (redelivered=%v), want about 3s", after, ok)
+		}
+	})
+
+	t.Run("shorter than ack timeout", func(t *testing.T) {
+		c := newTestClient()
+		c.clientID = "ws_short"
+		defer c.cleanup()
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["fast.*"],"options":{"ack_timeout":"1m"}}`), consumerMgr)
+		drainSent(t, c)
+
+		start := time.Now()
+		event := publish("fast.ping", "1s")
+		if after, ok := redeliveries(c, start, 3*time.Second)[event.ID]; !ok || after > 2*time.Second {
+			t.Errorf("event with a 1s ack_deadline redelivered after %s (redelivered=%v), want about 1s", after, ok)
+		}
+	})
+}
+
+func TestDeliverMessage_SlowConsumer(t *testing.T) {
+	// frames waits for delivery to stop with the client not reading, then
+	// counts what was queued
+	frames := func(c *Client) (events int, codes []string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !c.slow.Load() && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(300 * time.Millisecond)
+		for _, frame := range drainSent(t, c) {
+			switch frame["type"] {
+			case "event":
+				events++
+			case "error":
+				codes = append(codes, frame["code"].(string))
+			}
+		}
+		return events, codes
+	}
+
+	for _, autoAck := range []bool{true, false} {
+		t.Run(fmt.Sprintf("auto_ack=%v", autoAck), func(t *testing.T) {
+			consumerMgr, pub := newTestJetStream(t)
+			c := newTestClient()
+			defer c.cleanup()
+			c.handleMessage(context.Background(), []byte(fmt.Sprintf(`{"action":"subscribe","topics":["orders.*"],"options":{"auto_ack":%v}}`, autoAck)), consumerMgr)
+			drainSent(t, c)
+
+			total := sendBufferEvents + 20
+			for i := 0; i < total; i++ {
+				event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+				event.OrgID, event.ProjectID = "org_test", "prj_test"
+				if err := pub.Publish(context.Background(), event); err != nil {
+					t.Fatalf("publish: %v", err)
+				}
+			}
+
+			events, codes := frames(c)
+			if events != sendBufferEvents {
+				t.Errorf("queued %d events, want the %d that fit", events, sendBufferEvents)
+			}
+			if !slices.Equal(codes, []string{"SLOW_CONSUMER"}) {
+				t.Errorf("error frames %v, want one SLOW_CONSUMER", codes)
+			}
+
+			// The client read everything; WritePump would now find the buffer empty
+			c.recoverSlow()
+			time.Sleep(500 * time.Millisecond)
+			rest := 0
+			for _, frame := range drainSent(t, c) {
+				if frame["type"] == "event" {
+					rest++
+				}
+			}
+			if autoAck && rest != 0 {
+				t.Errorf("auto-ack: %d dropped events delivered after catching up", rest)
+			}
+			if !autoAck && rest != total-sendBufferEvents {
+				t.Errorf("manual ack: %d events delivered after catching up, want the %d held back", rest, total-sendBufferEvents)
+			}
+		})
+	}
+}
+
+func TestHandleSubscribe_MaxInFlight(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	c := newTestClient()
+	defer c.cleanup()
+
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"max_in_flight":2}}`), consumerMgr)
+	frames := drainSent(t, c)
+	if len(frames) != 1 || frames[0]["type"] != "subscribed" {
+		t.Fatalf("expected subscribed frame, got %v", frames)
+	}
+	if opts := frames[0]["options"].(map[string]any); opts["max_in_flight"] != float64(2) {
+		t.Errorf("applied max_in_flight = %v, want 2", opts["max_in_flight"])
+	}
+
+	for i := 0; i < 5; i++ {
+		event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+		event.OrgID, event.ProjectID = "org_test", "prj_test"
+		if err := pub.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	if n := countEvents(t, c); n != 2 {
+		t.Fatalf("delivered %d events, want max_in_flight 2", n)
+	}
+	c.mu.RLock()
+	var id string
+	for id = range c.pendingMessages {
+		break
+	}
+	c.mu.RUnlock()
+	c.handleMessage(context.Background(), []byte(`{"action":"ack","id":"`+id+`"}`), consumerMgr)
+	if n := countEvents(t, c); n != 1 {
+		t.Errorf("after ack: delivered %d more events, want 1", n)
+	}
+}
+
+func TestHandleSubscribe_InvalidMaxInFlight(t *testing.T) {
+	c := newTestClient()
+	c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"max_in_flight":-1}}`), nil)
+	if frames := drainSent(t, c); len(frames) != 1 || frames[0]["code"] != "INVALID_OPTIONS" {
+		t.Errorf("expected INVALID_OPTIONS, got %v", frames)
+	}
+}
@@ -1,8 +1,14 @@
 package websocket
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/filipexyz/notif/internal/nats"
 )
 
 // Hub manages all active WebSocket clients.
@@ -11,6 +17,23 @@ type Hub struct {
 	clients    map[*Client]bool
 	register   chan *Client
 	unregister chan *Client
+
+	// Open connections per API key, reserved at upgrade time and
+	// released when the client unregisters.
+	connMu   sync.Mutex
+	keyConns map[string]int
+
+	// Distinct subscriptions per project: subscription key -> number of
+	// clients sharing it (consumer group members share one). Guarded by connMu.
+	projectSubs map[string]map[string]int
+
+	// Orgs drained for maintenance; their clients don't receive new events.
+	// Guarded by mu.
+	drainedOrgs map[string]bool
+
+	// fanout caps how many receivers each event is delivered to; shared
+	// with the webhook worker.
+	fanout *nats.FanoutGate
 }
 
 // NewHub creates a new Hub.
@@ -19,6 +42,11 @@ func NewHub() *Hub {
 		clients:    make(map[*Client]bool),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		keyConns:   make(map[string]int),
+
+		projectSubs: make(map[string]map[string]int),
+
+		drainedOrgs: make(map[string]bool),
 	}
 }
 
@@ -29,6 +57,9 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			if h.drainedOrgs[client.orgID] {
+				client.pause()
+			}
 			h.mu.Unlock()
 			slog.Debug("client registered", "total", len(h.clients))
 
@@ -37,6 +68,7 @@ func (h *Hub) Run() {
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
 				close(client.send)
+				h.ReleaseConn(client.apiKeyID)
 			}
 			h.mu.Unlock()
 			slog.Debug("client unregistered", "total", len(h.clients))
@@ -49,6 +81,235 @@ func (h *Hub) Register(client *Client) {
 	h.register <- client
 }
 
+// AcquireConn reserves a connection slot for the API key. It returns false
+// when the key already has limit open connections; limit <= 0 means
+// unlimited. Every successful acquire must be paired with ReleaseConn,
+// which unregistering the client does.
+func (h *Hub) AcquireConn(apiKeyID string, limit int) bool {
+	if apiKeyID == "" {
+		return true
+	}
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	if limit > 0 && h.keyConns[apiKeyID] >= limit {
+		return false
+	}
+	h.keyConns[apiKeyID]++
+	return true
+}
+
+// ReleaseConn frees a connection slot reserved by AcquireConn.
+func (h *Hub) ReleaseConn(apiKeyID string) {
+	if apiKeyID == "" {
+		return
+	}
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	if h.keyConns[apiKeyID] <= 1 {
+		delete(h.keyConns, apiKeyID)
+		return
+	}
+	h.keyConns[apiKeyID]--
+}
+
+// ConnCount returns the number of open connections for the API key.
+func (h *Hub) ConnCount(apiKeyID string) int {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	return h.keyConns[apiKeyID]
+}
+
+// Errors from AcquireSubscription.
+var (
+	ErrSubscriptionLimit = errors.New("subscription limit reached")
+	ErrGroupFull         = errors.New("consumer group is full")
+)
+
+// AcquireSubscription reserves a subscription slot for the project. subKey
+// identifies the underlying consumer: clients joining an existing consumer
+// group share its slot. It returns ErrSubscriptionLimit when the project
+// already has limit distinct subscriptions, and ErrGroupFull when the
+// consumer group already has maxMembers members; values <= 0 mean
+// unlimited. Pair every successful acquire with ReleaseSubscription.
+func (h *Hub) AcquireSubscription(projectID, subKey string, limit, maxMembers int) error {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	subs := h.projectSubs[projectID]
+	members := subs[subKey]
+	if members == 0 && limit > 0 && len(subs) >= limit {
+		return ErrSubscriptionLimit
+	}
+	if maxMembers > 0 && members >= maxMembers && strings.HasPrefix(subKey, groupSubKeyPrefix) {
+		return ErrGroupFull
+	}
+	if subs == nil {
+		subs = make(map[string]int)
+		h.projectSubs[projectID] = subs
+	}
+	subs[subKey]++
+	return nil
+}
+
+// ReleaseSubscription frees a slot reserved by AcquireSubscription.
+func (h *Hub) ReleaseSubscription(projectID, subKey string) {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	subs := h.projectSubs[projectID]
+	if subs[subKey] <= 1 {
+		delete(subs, subKey)
+	} else {
+		subs[subKey]--
+	}
+	if len(subs) == 0 {
+		delete(h.projectSubs, projectID)
+	}
+}
+
+// rebalanceGroup tells the members of the consumer group behind subKey,
+// except from, how many members it has now. Other subscriptions are
+// ignored.
+func (h *Hub) rebalanceGroup(projectID, subKey string, from *Client) {
+	group, ok := strings.CutPrefix(subKey, groupSubKeyPrefix)
+	if !ok {
+		return
+	}
+	h.connMu.Lock()
+	members := h.projectSubs[projectID][subKey]
+	h.connMu.Unlock()
+
+	msg := NewGroupRebalanceMessage(group, members)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client == from || client.projectID != projectID {
+			continue
+		}
+		client.mu.RLock()
+		member := client.subKey == subKey
+		client.mu.RUnlock()
+		if member {
+			client.sendJSON(msg)
+		}
+	}
+}
+
+// SubscriptionCount returns the number of distinct active subscriptions
+// for the project.
+func (h *Hub) SubscriptionCount(projectID string) int {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	return len(h.projectSubs[projectID])
+}
+
+// DrainOrg stops delivering new events to the org's clients and tells them
+// with a "draining" message. Events already delivered can still be acked.
+// Clients connecting while the org is drained start out paused.
+func (h *Hub) DrainOrg(orgID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drainedOrgs[orgID] = true
+	for client := range h.clients {
+		if client.orgID == orgID {
+			client.pause()
+		}
+	}
+}
+
+// ResumeOrg undoes DrainOrg.
+func (h *Hub) ResumeOrg(orgID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.drainedOrgs, orgID)
+	for client := range h.clients {
+		if client.orgID == orgID {
+			client.resume()
+		}
+	}
+}
+
+// Kick closes the API key's connections with "kicked", e.g. once the key
+// is revoked, and returns how many there were.
+func (h *Hub) Kick(apiKeyID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	n := 0
+	for client := range h.clients {
+		if client.apiKeyID == apiKeyID {
+			client.closeWithReason(CloseKicked, "API key revoked")
+			n++
+		}
+	}
+	return n
+}
+
+// ExpireKey moves the auth expiry of the API key's connections to t, e.g.
+// to the end of the old key's grace period once it is rotated, and returns
+// how many there were.
+func (h *Hub) ExpireKey(apiKeyID string, t time.Time) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	n := 0
+	for client := range h.clients {
+		if client.apiKeyID == apiKeyID {
+			client.SetAuthExpiry(t)
+			n++
+		}
+	}
+	return n
+}
+
+// CloseAll closes every connection with "server_draining", for shutdown.
+// Clients are told to reconnect, to another instance or after the restart.
+func (h *Hub) CloseAll() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		client.closeWithReason(CloseServerDraining, "server shutting down, reconnect")
+	}
+}
+
+// closeClient closes client for reason if it's still registered: sending
+// to an unregistered client would hit its closed send channel.
+func (h *Hub) closeClient(client *Client, reason CloseReason, message string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.clients[client] {
+		client.closeWithReason(reason, message)
+	}
+}
+
+// PushDisplayConfigs re-sends display configs to the project's clients
+// subscribed with display_config, after one of its schemas changed.
+func (h *Hub) PushDisplayConfigs(ctx context.Context, projectID string) {
+	h.mu.RLock()
+	var clients []*Client
+	for client := range h.clients {
+		if client.projectID == projectID {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		msg := client.loadDisplayConfigs(ctx)
+		if msg == nil {
+			continue
+		}
+		// Send only while registered: unregistering closes the channel
+		h.mu.RLock()
+		if h.clients[client] {
+			client.sendJSON(msg)
+		}
+		h.mu.RUnlock()
+	}
+}
+
+// SetFanoutGate sets the gate that caps how many receivers each event is
+// delivered to. Call before clients connect.
+func (h *Hub) SetFanoutGate(g *nats.FanoutGate) {
+	h.fanout = g
+}
+
 // ClientCount returns the number of connected clients.
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
@@ -0,0 +1,487 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/gorilla/websocket"
+)
+
+func TestHub_ConnLimit(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	const key = "key_capped"
+	var clients []*Client
+	for i := 0; i < 2; i++ {
+		if !hub.AcquireConn(key, 2) {
+			t.Fatalf("connection %d should be accepted", i+1)
+		}
+		c := NewClient(hub, nil, key, "org_test", "prj_test", nil, nil, "ws_test", 1<<20)
+		hub.Register(c)
+		clients = append(clients, c)
+	}
+
+	if hub.AcquireConn(key, 2) {
+		t.Fatal("3rd connection should be rejected")
+	}
+	if got := hub.ConnCount(key); got != 2 {
+		t.Errorf("expected 2 open connections, got %d", got)
+	}
+
+	// Other keys are tracked independently
+	if !hub.AcquireConn("key_other", 2) {
+		t.Error("a different key should not be affected by the cap")
+	}
+
+	// Closing a connection frees its slot
+	hub.unregister <- clients[0]
+	deadline := time.Now().Add(time.Second)
+	for hub.ConnCount(key) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("slot not released, count %d", hub.ConnCount(key))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !hub.AcquireConn(key, 2) {
+		t.Error("connection should be accepted after one closed")
+	}
+}
+
+func TestHub_ConnLimitUnlimited(t *testing.T) {
+	hub := NewHub()
+	for i := 0; i < 50; i++ {
+		if !hub.AcquireConn("key_unlimited", 0) {
+			t.Fatalf("limit 0 should be unlimited, rejected at %d", i+1)
+		}
+	}
+	// Connections without an API key (dashboard sessions) are not capped
+	if !hub.AcquireConn("", 1) || !hub.AcquireConn("", 1) {
+		t.Error("connections without an API key should not be capped")
+	}
+}
+
+// frameTypes collects the types of frames sent to the client until none
+// arrive for a short while.
+func frameTypes(t *testing.T, c *Client) map[string]int {
+	t.Helper()
+	types := map[string]int{}
+	for {
+		select {
+		case data := <-c.send:
+			var frame map[string]any
+			if err := json.Unmarshal(data, &frame); err != nil {
+				t.Fatalf("invalid frame: %v", err)
+			}
+			types[frame["type"].(string)]++
+		case <-time.After(500 * time.Millisecond):
+			return types
+		}
+	}
+}
+
+func TestHub_DrainOrg(t *testing.T) {
+	consumerMgr, pub := newTestJetStream(t)
+	hub := NewHub()
+	go hub.Run()
+
+	subscribe := func(orgID string) *Client {
+		c := NewClient(hub, nil, "", orgID, "prj_test", nil, nil, "ws_"+orgID, 1<<20)
+		hub.Register(c)
+		t.Cleanup(c.cleanup)
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"auto_ack":true}}`), consumerMgr)
+		if got := frameTypes(t, c); got["subscribed"] != 1 {
+			t.Fatalf("expected subscribed frame, got %v", got)
+		}
+		return c
+	}
+	emit := func(orgID string, n int) {
+		for i := 0; i < n; i++ {
+			event := domain.NewEvent("orders.created", json.RawMessage(`{}`))
+			event.OrgID, event.ProjectID = orgID, "prj_test"
+			if err := pub.Publish(context.Background(), event); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+		}
+	}
+
+	maint := subscribe("org_maint")
+	live := subscribe("org_live")
+
+	hub.DrainOrg("org_maint")
+	emit("org_maint", 3)
+	emit("org_live", 3)
+
+	if got := frameTypes(t, maint); got["draining"] != 1 || got["event"] != 0 {
+		t.Errorf("drained org: expected draining notice and no events, got %v", got)
+	}
+	if got := frameTypes(t, live); got["event"] != 3 || got["draining"] != 0 {
+		t.Errorf("other org: expected 3 events, got %v", got)
+	}
+
+	// Clients connecting during the drain start out paused
+	late := subscribe("org_maint")
+	if got := frameTypes(t, late); got["event"] != 0 {
+		t.Errorf("late client: expected no events while drained, got %v", got)
+	}
+
+	// Resuming delivers what was held back
+	hub.ResumeOrg("org_maint")
+	if got := frameTypes(t, maint); got["resumed"] != 1 || got["event"] != 3 {
+		t.Errorf("resumed org: expected resumed notice and 3 events, got %v", got)
+	}
+}
+
+func TestHub_SubscriptionLimit(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	hub := NewHub()
+	go hub.Run()
+
+	connect := func(clientID string) *Client {
+		c := NewClient(hub, nil, "", "org_test", "prj_test", nil, nil, clientID, 1<<20)
+		c.SetMaxSubscriptions(1)
+		hub.Register(c)
+		t.Cleanup(c.cleanup)
+		return c
+	}
+	subscribe := func(c *Client, msg string) map[string]any {
+		c.handleMessage(context.Background(), []byte(msg), consumerMgr)
+		frames := drainSent(t, c)
+		if len(frames) != 1 {
+			t.Fatalf("expected 1 frame, got %v", frames)
+		}
+		return frames[0]
+	}
+	plain := `{"action":"subscribe","topics":["orders.*"]}`
+
+	first := connect("ws_first")
+	if f := subscribe(first, plain); f["type"] != "subscribed" {
+		t.Fatalf("first subscribe: got %v", f)
+	}
+
+	// The cap is per project, so a second connection is rejected
+	second := connect("ws_second")
+	if f := subscribe(second, plain); f["type"] != "error" || f["code"] != "LIMIT_EXCEEDED" {
+		t.Fatalf("second subscribe: expected LIMIT_EXCEEDED, got %v", f)
+	}
+
+	// Re-subscribing on the same connection reuses its slot
+	if f := subscribe(first, `{"action":"subscribe","topics":["users.*"]}`); f["type"] != "subscribed" {
+		t.Fatalf("re-subscribe: got %v", f)
+	}
+	if n := hub.SubscriptionCount("prj_test"); n != 1 {
+		t.Errorf("expected 1 active subscription, got %d", n)
+	}
+
+	// Unsubscribing frees capacity
+	first.handleMessage(context.Background(), []byte(`{"action":"unsubscribe"}`), consumerMgr)
+	if f := drainSent(t, first); len(f) != 1 || f[0]["type"] != "unsubscribed" {
+		t.Fatalf("unsubscribe: got %v", f)
+	}
+	if f := subscribe(second, plain); f["type"] != "subscribed" {
+		t.Fatalf("subscribe after unsubscribe: got %v", f)
+	}
+
+	// Disconnecting frees capacity too
+	second.cleanup()
+	if f := subscribe(first, plain); f["type"] != "subscribed" {
+		t.Fatalf("subscribe after disconnect: got %v", f)
+	}
+}
+
+func TestHub_SubscriptionLimitGroupSharesSlot(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	hub := NewHub()
+	go hub.Run()
+
+	for _, id := range []string{"ws_a", "ws_b"} {
+		c := NewClient(hub, nil, "", "org_test", "prj_test", nil, nil, id, 1<<20)
+		c.SetMaxSubscriptions(1)
+		hub.Register(c)
+		t.Cleanup(c.cleanup)
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"workers"}}`), consumerMgr)
+		if f := drainSent(t, c); len(f) != 1 || f[0]["type"] != "subscribed" {
+			t.Fatalf("%s: expected subscribed, got %v", id, f)
+		}
+	}
+	if n := hub.SubscriptionCount("prj_test"); n != 1 {
+		t.Errorf("expected group to hold 1 subscription, got %d", n)
+	}
+}
+
+func TestHub_PushDisplayConfigs(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	hub := NewHub()
+	go hub.Run()
+
+	display := `{"template":"v1"}`
+	source := func(ctx context.Context, projectID string, topics []string) ([]DisplayConfig, error) {
+		return fakeDisplayConfigs(display)(ctx, projectID, topics)
+	}
+
+	subscribe := func(id, options string) *Client {
+		c := NewClient(hub, nil, "", "org_test", "prj_test", nil, nil, id, 1<<20)
+		c.SetDisplayConfigSource(source)
+		hub.Register(c)
+		t.Cleanup(c.cleanup)
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":`+options+`}`), consumerMgr)
+		frameTypes(t, c)
+		return c
+	}
+	watching := subscribe("ws_watching", `{"display_config":true}`)
+	plain := subscribe("ws_plain", `{}`)
+
+	display = `{"template":"v2"}`
+	hub.PushDisplayConfigs(context.Background(), "prj_test")
+
+	frames := drainSent(t, watching)
+	if len(frames) != 1 || frames[0]["type"] != "display_config" {
+		t.Fatalf("expected a display_config push, got %v", frames)
+	}
+	configs, _ := frames[0]["configs"].([]any)
+	if len(configs) != 1 || configs[0].(map[string]any)["display"].(map[string]any)["template"] != "v2" {
+		t.Errorf("expected the updated config, got %v", frames[0])
+	}
+	if got := frameTypes(t, plain); len(got) != 0 {
+		t.Errorf("client without display_config: expected no frames, got %v", got)
+	}
+
+	// Other projects are untouched
+	hub.PushDisplayConfigs(context.Background(), "prj_other")
+	if got := frameTypes(t, watching); len(got) != 0 {
+		t.Errorf("other project's change: expected no frames, got %v", got)
+	}
+}
+
+func TestHub_GroupRebalance(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	hub := NewHub()
+	go hub.Run()
+
+	join := func(id, group string) *Client {
+		c := NewClient(hub, nil, "", "org_test", "prj_test", nil, nil, id, 1<<20)
+		hub.Register(c)
+		t.Cleanup(c.cleanup)
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"`+group+`"}}`), consumerMgr)
+		if f := drainSent(t, c); len(f) != 1 || f[0]["type"] != "subscribed" {
+			t.Fatalf("%s: expected only subscribed, got %v", id, f)
+		}
+		return c
+	}
+
+	first := join("ws_first", "workers")
+	other := join("ws_other", "auditors")
+	second := join("ws_second", "workers")
+
+	frames := drainSent(t, first)
+	if len(frames) != 1 || frames[0]["type"] != "group_rebalance" || frames[0]["group"] != "workers" || frames[0]["members"] != float64(2) {
+		t.Fatalf("first member: expected rebalance to 2 members, got %v", frames)
+	}
+	if f := drainSent(t, other); len(f) != 0 {
+		t.Errorf("other group: expected no frames, got %v", f)
+	}
+
+	// The second member leaving rebalances back to one
+	second.handleMessage(context.Background(), []byte(`{"action":"unsubscribe"}`), consumerMgr)
+	frames = drainSent(t, first)
+	if len(frames) != 1 || frames[0]["type"] != "group_rebalance" || frames[0]["members"] != float64(1) {
+		t.Fatalf("first member: expected rebalance to 1 member, got %v", frames)
+	}
+	if f := drainSent(t, second); len(f) != 1 || f[0]["type"] != "unsubscribed" {
+		t.Errorf("leaving member: expected only unsubscribed, got %v", f)
+	}
+}
+
+func TestHub_GroupMaxMembers(t *testing.T) {
+	consumerMgr := newTestConsumerManager(t)
+	hub := NewHub()
+	go hub.Run()
+
+	join := func(id string) (*Client, map[string]any) {
+		c := NewClient(hub, nil, "", "org_test", "prj_test", nil, nil, id, 1<<20)
+		c.SetGroupLimits(2, 0)
+		hub.Register(c)
+		t.Cleanup(c.cleanup)
+		c.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"workers"}}`), consumerMgr)
+		frames := drainSent(t, c)
+		if len(frames) != 1 {
+			t.Fatalf("%s: expected 1 frame, got %v", id, frames)
+		}
+		return c, frames[0]
+	}
+
+	first, _ := join("ws_first")
+	if _, f := join("ws_second"); f["type"] != "subscribed" {
+		t.Fatalf("second member: got %v", f)
+	}
+	third, f := join("ws_third")
+	if f["type"] != "error" || f["code"] != "GROUP_FULL" {
+		t.Fatalf("third member: expected GROUP_FULL, got %v", f)
+	}
+
+	// Other groups and plain subscriptions are unaffected
+	third.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"auditors"}}`), consumerMgr)
+	if f := drainSent(t, third); len(f) != 1 || f[0]["type"] != "subscribed" {
+		t.Fatalf("other group: got %v", f)
+	}
+
+	// A member re-subscribing keeps its place
+	drainSent(t, first)
+	first.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"workers"}}`), consumerMgr)
+	if f := drainSent(t, first); len(f) != 1 || f[0]["type"] != "subscribed" {
+		t.Fatalf("re-subscribe: got %v", f)
+	}
+
+	// Leaving frees a slot
+	first.handleMessage(context.Background(), []byte(`{"action":"unsubscribe"}`), consumerMgr)
+	drainSent(t, first)
+	third.handleMessage(context.Background(), []byte(`{"action":"subscribe","topics":["orders.*"],"options":{"group":"workers"}}`), consumerMgr)
+	if f := drainSent(t, third); len(f) == 0 || f[len(f)-1]["type"] != "subscribed" {
+		t.Fatalf("join after leave: got %v", f)
+	}
+}
+
+// dialTestClient serves one real WebSocket connection as a registered hub
+// client and returns the client side of it.
+func dialTestClient(t *testing.T, hub *Hub, apiKeyID string, setup func(*Client)) (*Client, *websocket.Conn) {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	clients := make(chan *Client, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		c := NewClient(hub, conn, apiKeyID, "org_test", "prj_test", nil, nil, "ws_"+apiKeyID, 1<<20)
+		if setup != nil {
+			setup(c)
+		}
+		hub.Register(c)
+		go c.WritePump()
+		go c.ReadPump(context.Background(), nil)
+		clients <- c
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := <-clients
+	for registered := false; !registered; {
+		hub.mu.RLock()
+		registered = hub.clients[c]
+		hub.mu.RUnlock()
+	}
+	return c, conn
+}
+
+// readClose reads frames until the server closes the connection, returning
+// the "closing" frame (nil if none came) and the close frame.
+func readClose(t *testing.T, conn *websocket.Conn) (map[string]any, *websocket.CloseError) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var closing map[string]any
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				t.Fatalf("expected a close frame, got %v", err)
+			}
+			return closing, closeErr
+		}
+		var frame map[string]any
+		if err := json.Unmarshal(data, &frame); err != nil {
+			t.Fatalf("invalid frame: %v", err)
+		}
+		if frame["type"] == "closing" {
+			closing = frame
+		}
+	}
+}
+
+func TestClient_CloseReasons(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	tests := []struct {
+		name      string
+		reason    CloseReason
+		code      int
+		reconnect bool
+		setup     func(*Client)
+		trigger   func(*Client, *websocket.Conn)
+	}{
+		{
+			name: "kicked", reason: CloseKicked, code: 4003,
+			trigger: func(c *Client, _ *websocket.Conn) {
+				if n := hub.Kick(c.apiKeyID); n != 1 {
+					t.Errorf("kicked %d clients, want 1", n)
+				}
+			},
+		},
+		{
+			name: "key rotated", reason: CloseAuthExpired, code: 4001,
+			trigger: func(c *Client, _ *websocket.Conn) {
+				if n := hub.ExpireKey(c.apiKeyID, time.Now().Add(50*time.Millisecond)); n != 1 {
+					t.Errorf("expired %d clients, want 1", n)
+				}
+			},
+		},
+		{
+			name: "server draining", reason: CloseServerDraining, code: websocket.CloseGoingAway, reconnect: true,
+			trigger: func(*Client, *websocket.Conn) { hub.CloseAll() },
+		},
+		{
+			name: "auth expired", reason: CloseAuthExpired, code: 4001,
+			setup:   func(c *Client) { c.SetAuthExpiry(time.Now().Add(50 * time.Millisecond)) },
+			trigger: func(*Client, *websocket.Conn) {},
+		},
+		{
+			name: "protocol error", reason: CloseProtocolError, code: websocket.CloseUnsupportedData,
+			trigger: func(_ *Client, conn *websocket.Conn) {
+				conn.WriteMessage(websocket.BinaryMessage, []byte{0x01})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, conn := dialTestClient(t, hub, "key_"+strings.ReplaceAll(tt.name, " ", "_"), tt.setup)
+			tt.trigger(c, conn)
+
+			closing, closeErr := readClose(t, conn)
+			if closing == nil || closing["reason"] != string(tt.reason) || closing["code"] != float64(tt.code) || closing["reconnect"] != tt.reconnect {
+				t.Errorf("closing frame = %v, want reason %s, code %d, reconnect %v", closing, tt.reason, tt.code, tt.reconnect)
+			}
+			if closeErr.Code != tt.code || closeErr.Text != string(tt.reason) {
+				t.Errorf("close frame = %d %q, want %d %q", closeErr.Code, closeErr.Text, tt.code, tt.reason)
+			}
+		})
+	}
+
+	t.Run("slow consumer", func(t *testing.T) {
+		// Not writing: the client stops reading, so the buffer fills up
+		c := NewClient(hub, nil, "key_slow", "org_test", "prj_test", nil, nil, "ws_slow", 1<<20)
+		for i := 0; i < sendBufferSize; i++ {
+			c.sendJSON(NewPongMessage())
+		}
+		c.sendJSON(NewPongMessage())
+		if c.closing != CloseSlowConsumer {
+			t.Errorf("closing = %q, want %s", c.closing, CloseSlowConsumer)
+		}
+		if code := CloseSlowConsumer.Code(); code != 4008 || !CloseSlowConsumer.Reconnect() {
+			t.Errorf("slow_consumer: code %d, reconnect %v; want 4008, true", code, CloseSlowConsumer.Reconnect())
+		}
+	})
+}
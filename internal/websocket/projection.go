@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// projectionTimeout bounds a jq projection of a single event so a
+// pathological expression cannot stall delivery.
+const projectionTimeout = 100 * time.Millisecond
+
+// Projection reshapes event data before it is sent to a subscriber. It is
+// either a list of dot-separated field paths or a jq expression.
+type Projection struct {
+	fields [][]string
+	jq     *gojq.Code
+}
+
+// ParseProjection parses the subscribe "project" option: a JSON array of
+// field paths such as ["id","customer.email"], or a jq expression string.
+// An empty or null option returns nil.
+func ParseProjection(raw json.RawMessage) (*Projection, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	switch raw[0] {
+	case '"':
+		var expr string
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return nil, fmt.Errorf("invalid jq expression: %w", err)
+		}
+		if strings.TrimSpace(expr) == "" {
+			return nil, fmt.Errorf("jq expression is empty")
+		}
+		query, err := gojq.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("parse jq expression: %w", err)
+		}
+		code, err := gojq.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("compile jq expression: %w", err)
+		}
+		return &Projection{jq: code}, nil
+
+	case '[':
+		var paths []string
+		if err := json.Unmarshal(raw, &paths); err != nil {
+			return nil, fmt.Errorf("fields must be strings: %w", err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("at least one field required")
+		}
+		p := &Projection{}
+		for _, path := range paths {
+			segments := strings.Split(path, ".")
+			for _, s := range segments {
+				if s == "" {
+					return nil, fmt.Errorf("invalid field path %q", path)
+				}
+			}
+			p.fields = append(p.fields, segments)
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("want a list of field paths or a jq expression")
+}
+
+// Apply returns the projection of data. Data the projection cannot be
+// applied to becomes null, so unselected fields are never sent.
+func (p *Projection) Apply(data json.RawMessage) json.RawMessage {
+	var out any
+	var err error
+	if p.jq != nil {
+		out, err = p.applyJq(data)
+	} else {
+		out, err = p.applyFields(data)
+	}
+	if err != nil {
+		slog.Debug("projection failed", "error", err)
+		return json.RawMessage("null")
+	}
+
+	projected, err := json.Marshal(out)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return projected
+}
+
+// applyFields copies the selected paths into a new object, keeping their
+// nesting. Missing paths are left out.
+func (p *Projection) applyFields(data json.RawMessage) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var input map[string]any
+	if err := dec.Decode(&input); err != nil {
+		return nil, err
+	}
+
+	out := map[string]any{}
+	for _, path := range p.fields {
+		value, ok := lookup(input, path)
+		if !ok {
+			continue
+		}
+		dst := out
+		for _, key := range path[:len(path)-1] {
+			next, ok := dst[key].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				dst[key] = next
+			}
+			dst = next
+		}
+		dst[path[len(path)-1]] = value
+	}
+	return out, nil
+}
+
+func lookup(obj map[string]any, path []string) (any, bool) {
+	var value any = obj
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// applyJq runs the expression and returns its first output.
+func (p *Projection) applyJq(data json.RawMessage) (any, error) {
+	var input any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), projectionTimeout)
+	defer cancel()
+
+	v, ok := p.jq.RunWithContext(ctx, input).Next()
+	if !ok {
+		return nil, nil
+	}
+	if err, isErr := v.(error); isErr {
+		return nil, err
+	}
+	return v, nil
+}
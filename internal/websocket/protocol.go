@@ -3,6 +3,9 @@ package websocket
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/filipexyz/notif/internal/domain"
+	"github.com/gorilla/websocket"
 )
 
 // Client to Server messages
@@ -12,28 +15,126 @@ type ClientMessage struct {
 }
 
 type SubscribeMessage struct {
-	Action  string            `json:"action"`
-	Topics  []string          `json:"topics"`
-	Options SubscribeOptions  `json:"options,omitempty"`
+	Action  string           `json:"action"`
+	Topics  []string         `json:"topics"`
+	Options SubscribeOptions `json:"options,omitempty"`
 }
 
 type SubscribeOptions struct {
 	AutoAck    bool   `json:"auto_ack"`
-	From       string `json:"from,omitempty"` // "latest", "beginning", or timestamp
+	From       string `json:"from,omitempty"`     // "latest", "beginning", timestamp, resume token, or "resume"
+	FromSeq    uint64 `json:"from_seq,omitempty"` // stream sequence to start at, instead of from
 	Group      string `json:"group,omitempty"`
 	MaxRetries int    `json:"max_retries,omitempty"`
 	AckTimeout string `json:"ack_timeout,omitempty"`
+	// EnvelopeVersion pins the event frame shape; 0 = current version.
+	EnvelopeVersion int `json:"envelope_version,omitempty"`
+	// Sample delivers every Nth matching event; SampleRate delivers each
+	// event with the given probability. Skipped events are acked.
+	Sample     int     `json:"sample,omitempty"`
+	SampleRate float64 `json:"sample_rate,omitempty"`
+	// Sequential makes a consumer group deliver one event at a time across
+	// all members, each only after the previous one is acked.
+	Sequential bool `json:"sequential,omitempty"`
+	// Until "caught_up" delivers the events stored at subscribe time, then
+	// sends a "done" frame and closes the connection.
+	Until string `json:"until,omitempty"`
+	// DisplayConfig pushes the display configs of schemas matching the
+	// topics, on subscribe and again whenever one of them changes.
+	DisplayConfig bool `json:"display_config,omitempty"`
+	// Project reshapes the data of each delivered event: a list of field
+	// paths (["id","customer.email"]) or a jq expression string. Unlike
+	// filtering it never drops events; acks and the DLQ see the full event.
+	Project json.RawMessage `json:"project,omitempty"`
+	// Projects subscribes across these project IDs instead of the key's own
+	// project, with project_id on every event frame. Admin keys only, and
+	// only for projects the key is authorized for.
+	Projects []string `json:"projects,omitempty"`
+	// MaxInFlight caps the subscription's unacked events; no more are
+	// delivered until some are acked. 0 keeps the server default.
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+	// LatestPerKey names a data field (dot-separated path) keying the
+	// events: of the events stored at subscribe time, only the latest per
+	// key is delivered, then live events as usual. Implies from beginning.
+	LatestPerKey string `json:"latest_per_key,omitempty"`
+	// ResumeToken, with from "resume", continues a durable resume cursor:
+	// the first time right after the token's event, and on every later
+	// reconnect with a token of the same cursor exactly where the previous
+	// connection left off. Not for groups, which resume on their own.
+	ResumeToken string `json:"resume_token,omitempty"`
 }
 
+// UntilCaughtUp is the only supported SubscribeOptions.Until value.
+const UntilCaughtUp = "caught_up"
+
 type AckMessage struct {
-	Action string `json:"action"`
-	ID     string `json:"id"`
+	Action string   `json:"action"`
+	ID     string   `json:"id,omitempty"`
+	IDs    []string `json:"ids,omitempty"` // Batch form: ack many events in one frame
 }
 
 type NackMessage struct {
-	Action  string `json:"action"`
-	ID      string `json:"id"`
-	RetryIn string `json:"retry_in,omitempty"`
+	Action  string   `json:"action"`
+	ID      string   `json:"id,omitempty"`
+	IDs     []string `json:"ids,omitempty"` // Batch form: nack many events in one frame
+	RetryIn string   `json:"retry_in,omitempty"`
+}
+
+// InProgressMessage resets the ack deadline of in-flight events, for
+// handlers that need longer than the subscription's ack_timeout.
+type InProgressMessage struct {
+	Action string   `json:"action"`
+	ID     string   `json:"id,omitempty"`
+	IDs    []string `json:"ids,omitempty"`
+}
+
+// TermMessage stops redelivery of events without moving them to the DLQ.
+type TermMessage struct {
+	Action string   `json:"action"`
+	ID     string   `json:"id,omitempty"`
+	IDs    []string `json:"ids,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// EmitMessage publishes an event as the connection's identity. Replies
+// ("emitted" or "error") arrive in the order emits were sent.
+type EmitMessage struct {
+	Action      string          `json:"action"`
+	Topic       string          `json:"topic"`
+	Data        json.RawMessage `json:"data"`
+	Attachments []string        `json:"attachments,omitempty"`
+	AckDeadline string          `json:"ack_deadline,omitempty"`
+}
+
+// EventIDs returns the event IDs targeted by the ack, merging the singular
+// and plural forms.
+func (m *AckMessage) EventIDs() []string {
+	return mergeIDs(m.ID, m.IDs)
+}
+
+// EventIDs returns the event IDs targeted by the nack, merging the singular
+// and plural forms.
+func (m *NackMessage) EventIDs() []string {
+	return mergeIDs(m.ID, m.IDs)
+}
+
+// EventIDs returns the event IDs targeted by the in-progress signal,
+// merging the singular and plural forms.
+func (m *InProgressMessage) EventIDs() []string {
+	return mergeIDs(m.ID, m.IDs)
+}
+
+// EventIDs returns the event IDs targeted by the term, merging the singular
+// and plural forms.
+func (m *TermMessage) EventIDs() []string {
+	return mergeIDs(m.ID, m.IDs)
+}
+
+func mergeIDs(id string, ids []string) []string {
+	if id == "" {
+		return ids
+	}
+	return append([]string{id}, ids...)
 }
 
 // Server to Client messages
@@ -43,19 +144,69 @@ type ServerMessage struct {
 }
 
 type EventMessage struct {
-	Type        string          `json:"type"`
-	ID          string          `json:"id"`
-	Topic       string          `json:"topic"`
-	Data        json.RawMessage `json:"data"`
-	Timestamp   time.Time       `json:"timestamp"`
-	Attempt     int             `json:"attempt,omitempty"`
-	MaxAttempts int             `json:"max_attempts,omitempty"`
+	Type            string          `json:"type"`
+	EnvelopeVersion int             `json:"envelope_version"`
+	ID              string          `json:"id"`
+	Topic           string          `json:"topic"`
+	Data            json.RawMessage `json:"data"`
+	Timestamp       time.Time       `json:"timestamp"`
+	Attempt         int             `json:"attempt,omitempty"`
+	MaxAttempts     int             `json:"max_attempts,omitempty"`
+
+	// StreamSeq and ConsumerSeq are the JetStream sequences of this
+	// delivery, for consumers doing their own offset bookkeeping.
+	StreamSeq   uint64 `json:"stream_seq,omitempty"`
+	ConsumerSeq uint64 `json:"consumer_seq,omitempty"`
+
+	// ProjectID is set on cross-project subscriptions only.
+	ProjectID string `json:"project_id,omitempty"`
+
+	Attachments []domain.Attachment `json:"attachments,omitempty"`
+
+	// Traceparent is the W3C trace context of the emit, when it had one.
+	Traceparent string `json:"traceparent,omitempty"`
+
+	// AckDeadline is the event's own ack deadline, when the emitter set
+	// one; it replaces the subscription's ack timeout for this event.
+	AckDeadline string `json:"ack_deadline,omitempty"`
 }
 
 type SubscribedMessage struct {
-	Type       string   `json:"type"`
-	Topics     []string `json:"topics"`
-	ConsumerID string   `json:"consumer_id,omitempty"`
+	Type       string          `json:"type"`
+	Topics     []string        `json:"topics"`
+	ConsumerID string          `json:"consumer_id,omitempty"`
+	Options    *AppliedOptions `json:"options,omitempty"`
+}
+
+// AppliedOptions echoes the subscription options the server actually applied,
+// after defaults and clamping, so clients can detect adjusted requests.
+type AppliedOptions struct {
+	AutoAck    bool   `json:"auto_ack"`
+	From       string `json:"from"`
+	FromSeq    uint64 `json:"from_seq,omitempty"`
+	Group      string `json:"group,omitempty"`
+	MaxRetries int    `json:"max_retries"`
+	AckTimeout string `json:"ack_timeout"`
+
+	EnvelopeVersion int `json:"envelope_version"`
+
+	Sample     int     `json:"sample,omitempty"`
+	SampleRate float64 `json:"sample_rate,omitempty"`
+
+	Sequential bool   `json:"sequential,omitempty"`
+	Until      string `json:"until,omitempty"`
+
+	DisplayConfig bool `json:"display_config,omitempty"`
+
+	Project  json.RawMessage `json:"project,omitempty"`
+	Projects []string        `json:"projects,omitempty"`
+
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+
+	LatestPerKey string `json:"latest_per_key,omitempty"`
+
+	// ResumeToken is the resume cursor's token, for from "resume".
+	ResumeToken string `json:"resume_token,omitempty"`
 }
 
 type ErrorMessage struct {
@@ -68,26 +219,209 @@ type PongMessage struct {
 	Type string `json:"type"`
 }
 
-// NewEventMessage creates an event message from domain event.
+// DrainingMessage tells a client its org is drained for maintenance: no new
+// events arrive until a "resumed" message, but pending events can still be
+// acked or nacked.
+type DrainingMessage struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// UnsubscribedMessage confirms an unsubscribe.
+type UnsubscribedMessage struct {
+	Type string `json:"type"`
+}
+
+// EmittedMessage confirms an emit.
+type EmittedMessage struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Topic     string    `json:"topic"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DoneMessage ends an until=caught_up subscription: every event stored at
+// subscribe time was delivered, and the server closes the connection next.
+type DoneMessage struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// CloseReason says why the server closed a connection. It is sent in a
+// "closing" frame just before the close, and as the close frame's text.
+type CloseReason string
+
+const (
+	// CloseCaughtUp ends an until=caught_up subscription. The "done" frame
+	// stands in for "closing".
+	CloseCaughtUp CloseReason = "caught_up"
+	// CloseAuthExpired: the session the connection was opened with expired.
+	CloseAuthExpired CloseReason = "auth_expired"
+	// CloseKicked: the connection's API key was revoked.
+	CloseKicked CloseReason = "kicked"
+	// CloseServerDraining: the server is shutting down.
+	CloseServerDraining CloseReason = "server_draining"
+	// CloseSlowConsumer: the client read too slowly to keep up with even
+	// control frames.
+	CloseSlowConsumer CloseReason = "slow_consumer"
+	// CloseProtocolError: the client sent a frame the protocol doesn't allow.
+	CloseProtocolError CloseReason = "protocol_error"
+)
+
+// Code returns the WebSocket close code sent with the reason: a standard
+// code where one fits, else one in the application range (4000-4999).
+func (r CloseReason) Code() int {
+	switch r {
+	case CloseCaughtUp:
+		return websocket.CloseNormalClosure
+	case CloseAuthExpired:
+		return 4001
+	case CloseKicked:
+		return 4003
+	case CloseServerDraining:
+		return websocket.CloseGoingAway
+	case CloseSlowConsumer:
+		return 4008
+	case CloseProtocolError:
+		return websocket.CloseUnsupportedData
+	}
+	return websocket.CloseNormalClosure
+}
+
+// Reconnect reports whether a client should reconnect after the close:
+// only when reconnecting with the same credentials can succeed.
+func (r CloseReason) Reconnect() bool {
+	return r == CloseServerDraining || r == CloseSlowConsumer
+}
+
+// ClosingMessage precedes a close by the server.
+type ClosingMessage struct {
+	Type      string      `json:"type"`
+	Reason    CloseReason `json:"reason"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Reconnect bool        `json:"reconnect"`
+}
+
+// DisplayConfigMessage carries the display configs of every schema matching
+// a subscription. Each one replaces the previous set.
+type DisplayConfigMessage struct {
+	Type    string          `json:"type"`
+	Configs []DisplayConfig `json:"configs"`
+}
+
+// DisplayConfig is a schema's x-notif-display config.
+type DisplayConfig struct {
+	Schema       string          `json:"schema"`
+	TopicPattern string          `json:"topic_pattern"`
+	Display      json.RawMessage `json:"display"`
+}
+
+// GroupRebalanceMessage tells consumer group members that another member
+// joined or left, and how many members the group has now.
+type GroupRebalanceMessage struct {
+	Type    string `json:"type"`
+	Group   string `json:"group"`
+	Members int    `json:"members"`
+}
+
+// AckedMessage confirms an ack frame. ResumeToken, passed as "from" on a
+// later subscribe, continues right after the latest event acked here.
+type AckedMessage struct {
+	Type        string   `json:"type"`
+	IDs         []string `json:"ids"`
+	ResumeToken string   `json:"resume_token"`
+}
+
+// ResumedMessage tells a client that delivery has resumed after draining.
+type ResumedMessage struct {
+	Type string `json:"type"`
+}
+
+// NewEventMessage creates an event message in the current envelope version.
 func NewEventMessage(id, topic string, data json.RawMessage, timestamp time.Time, attempt, maxAttempts int) *EventMessage {
 	return &EventMessage{
-		Type:        "event",
-		ID:          id,
-		Topic:       topic,
-		Data:        data,
-		Timestamp:   timestamp,
-		Attempt:     attempt,
-		MaxAttempts: maxAttempts,
+		Type:            "event",
+		EnvelopeVersion: domain.EnvelopeVersion,
+		ID:              id,
+		Topic:           topic,
+		Data:            data,
+		Timestamp:       timestamp,
+		Attempt:         attempt,
+		MaxAttempts:     maxAttempts,
 	}
 }
 
 // NewSubscribedMessage creates a subscribed confirmation.
-func NewSubscribedMessage(topics []string, consumerID string) *SubscribedMessage {
+func NewSubscribedMessage(topics []string, consumerID string, applied *AppliedOptions) *SubscribedMessage {
 	return &SubscribedMessage{
 		Type:       "subscribed",
 		Topics:     topics,
 		ConsumerID: consumerID,
+		Options:    applied,
+	}
+}
+
+// NewDrainingMessage creates a draining notice.
+func NewDrainingMessage() *DrainingMessage {
+	return &DrainingMessage{
+		Type:    "draining",
+		Message: "org is drained for maintenance; delivery will resume",
+	}
+}
+
+// NewUnsubscribedMessage creates an unsubscribe confirmation.
+func NewUnsubscribedMessage() *UnsubscribedMessage {
+	return &UnsubscribedMessage{Type: "unsubscribed"}
+}
+
+// NewEmittedMessage creates an emit confirmation.
+func NewEmittedMessage(resp *domain.EmitResponse) *EmittedMessage {
+	return &EmittedMessage{
+		Type:      "emitted",
+		ID:        resp.ID,
+		Topic:     resp.Topic,
+		CreatedAt: resp.CreatedAt,
+	}
+}
+
+// NewDoneMessage creates a caught-up notice.
+func NewDoneMessage() *DoneMessage {
+	return &DoneMessage{Type: "done", Reason: UntilCaughtUp}
+}
+
+// NewClosingMessage creates the frame sent before closing for reason.
+func NewClosingMessage(reason CloseReason, message string) *ClosingMessage {
+	return &ClosingMessage{
+		Type:      "closing",
+		Reason:    reason,
+		Code:      reason.Code(),
+		Message:   message,
+		Reconnect: reason.Reconnect(),
+	}
+}
+
+// NewDisplayConfigMessage creates a display config push.
+func NewDisplayConfigMessage(configs []DisplayConfig) *DisplayConfigMessage {
+	if configs == nil {
+		configs = []DisplayConfig{}
 	}
+	return &DisplayConfigMessage{Type: "display_config", Configs: configs}
+}
+
+// NewGroupRebalanceMessage creates a group membership notice.
+func NewGroupRebalanceMessage(group string, members int) *GroupRebalanceMessage {
+	return &GroupRebalanceMessage{Type: "group_rebalance", Group: group, Members: members}
+}
+
+// NewResumedMessage creates a resumed notice.
+func NewResumedMessage() *ResumedMessage {
+	return &ResumedMessage{Type: "resumed"}
+}
+
+// NewAckedMessage creates an ack confirmation carrying a resume token.
+func NewAckedMessage(ids []string, resumeToken string) *AckedMessage {
+	return &AckedMessage{Type: "acked", IDs: ids, ResumeToken: resumeToken}
 }
 
 // NewErrorMessage creates an error message.
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// APIKey describes an API key. The secret is never returned by listings.
+type APIKey struct {
+	ID                 string       `json:"id"`
+	KeyPrefix          string       `json:"key_prefix"`
+	FullKey            string       `json:"full_key,omitempty"` // Only returned by APIKeyCreate
+	Name               string       `json:"name,omitempty"`
+	RateLimitPerSecond int          `json:"rate_limit_per_second,omitempty"`
+	MaxConnections     *int         `json:"max_connections,omitempty"`
+	Scopes             []string     `json:"scopes,omitempty"`
+	AuthorizedProjects []string     `json:"authorized_projects,omitempty"`
+	CreatedAt          string       `json:"created_at"`
+	ExpiresAt          *string      `json:"expires_at,omitempty"`
+	LastUsedAt         *string      `json:"last_used_at,omitempty"`
+	RevokedAt          *string      `json:"revoked_at,omitempty"`
+	Usage              *APIKeyUsage `json:"usage,omitempty"`
+}
+
+// APIKeyUsage summarizes recent activity for an API key.
+type APIKeyUsage struct {
+	Events24h int64 `json:"events_24h"`
+}
+
+// CreateAPIKeyRequest is the request to create an API key.
+type CreateAPIKeyRequest struct {
+	Name           string `json:"name,omitempty"`
+	MaxConnections *int   `json:"max_connections,omitempty"`
+	// Scopes limits what the key may do ("emit", "subscribe",
+	// "schemas:write", "webhooks:write", "admin"); empty means full access.
+	Scopes             []string `json:"scopes,omitempty"`
+	AuthorizedProjects []string `json:"authorized_projects,omitempty"`
+	// ExpiresAt makes the key stop working at that time; nil never expires.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyListResponse is the response from listing API keys.
+type APIKeyListResponse struct {
+	APIKeys []APIKey `json:"api_keys"`
+	Count   int      `json:"count"`
+}
+
+// APIKeyList lists the API keys of the current project.
+// Revoked keys are included only when includeRevoked is true.
+func (c *Client) APIKeyList(includeRevoked bool) (*APIKeyListResponse, error) {
+	url := c.server + "/api/v1/api-keys"
+	if includeRevoked {
+		url += "?include_revoked=true"
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, &AuthError{Message: "invalid or missing API key"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to list API keys"
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var result APIKeyListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// APIKeyCreate creates an API key in the current project. The returned
+// key's FullKey is the only time the secret is shown.
+func (c *Client) APIKeyCreate(createReq CreateAPIKeyRequest) (*APIKey, error) {
+	reqBody, _ := json.Marshal(createReq)
+
+	req, err := http.NewRequest("POST", c.server+"/api/v1/api-keys", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, &AuthError{Message: "invalid or missing API key"}
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to create API key"
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var key APIKey
+	if err := json.NewDecoder(resp.Body).Decode(&key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// RotatedAPIKey is the key that replaced a rotated one.
+type RotatedAPIKey struct {
+	APIKey
+	PreviousKeyID        string `json:"previous_key_id"`
+	PreviousKeyExpiresAt string `json:"previous_key_expires_at"`
+}
+
+// APIKeyRotate replaces the key id with a new one carrying the same name,
+// scopes and limits. The old key keeps working for gracePeriod (e.g. "24h";
+// empty uses the server default). The new key's FullKey is the only time
+// its secret is shown.
+func (c *Client) APIKeyRotate(id, gracePeriod string) (*RotatedAPIKey, error) {
+	reqBody, _ := json.Marshal(map[string]string{"grace_period": gracePeriod})
+
+	req, err := http.NewRequest("POST", c.server+"/api/v1/api-keys/"+id+"/rotate", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, &AuthError{Message: "invalid or missing API key"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to rotate API key"
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var key RotatedAPIKey
+	if err := json.NewDecoder(resp.Body).Decode(&key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
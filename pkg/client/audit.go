@@ -10,30 +10,41 @@ import (
 // AuditEntry represents a single audit log entry.
 type AuditEntry struct {
 	ID        int64           `json:"id"`
-	Timestamp string          `json:"timestamp"`
+	CreatedAt string          `json:"created_at"`
 	Actor     string          `json:"actor"`
 	Action    string          `json:"action"`
 	OrgID     string          `json:"org_id,omitempty"`
 	Target    string          `json:"target,omitempty"`
-	Detail    json.RawMessage `json:"detail,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
 	IPAddress string          `json:"ip_address,omitempty"`
+
+	// Deprecated: use CreatedAt. Servers send both until the next release.
+	Timestamp string `json:"timestamp,omitempty"`
+	// Deprecated: use Metadata. Servers send both until the next release.
+	Detail json.RawMessage `json:"detail,omitempty"`
 }
 
 // AuditListResponse is the response from listing audit entries.
 type AuditListResponse struct {
 	Entries []AuditEntry `json:"entries"`
 	Count   int          `json:"count"`
+	// NextCursor is set when more entries may follow; pass it as Before.
+	NextCursor int64 `json:"next_cursor,omitempty"`
 }
 
 // AuditQueryOptions configures audit log queries.
 type AuditQueryOptions struct {
 	Org    string
 	Action string
+	Actor  string
 	Since  string // duration string like "1h", "30m"
+	From   string // RFC 3339 or Unix seconds; overrides Since
+	To     string // RFC 3339 or Unix seconds, exclusive
+	Before int64  // cursor: NextCursor of the previous page
 	Limit  int
 }
 
-// AuditList queries the audit log.
+// AuditList queries the audit log, newest first. It needs an admin key.
 func (c *Client) AuditList(opts AuditQueryOptions) (*AuditListResponse, error) {
 	u, _ := url.Parse(c.server + "/api/v1/audit")
 	q := u.Query()
@@ -44,9 +55,21 @@ func (c *Client) AuditList(opts AuditQueryOptions) (*AuditListResponse, error) {
 	if opts.Action != "" {
 		q.Set("action", opts.Action)
 	}
+	if opts.Actor != "" {
+		q.Set("actor", opts.Actor)
+	}
 	if opts.Since != "" {
 		q.Set("since", opts.Since)
 	}
+	if opts.From != "" {
+		q.Set("from", opts.From)
+	}
+	if opts.To != "" {
+		q.Set("to", opts.To)
+	}
+	if opts.Before > 0 {
+		q.Set("before", strconv.FormatInt(opts.Before, 10))
+	}
 	if opts.Limit > 0 {
 		q.Set("limit", strconv.Itoa(opts.Limit))
 	}
@@ -69,7 +92,14 @@ func (c *Client) AuditList(opts AuditQueryOptions) (*AuditListResponse, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to query audit log"}
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to query audit log"
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
 	}
 
 	var result AuditListResponse
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBatcherClosed is returned by a Batcher's methods after Close.
+var ErrBatcherClosed = errors.New("batcher closed")
+
+// Batcher defaults, for zero BatcherOptions fields.
+const (
+	defaultBatchFlushInterval = time.Second
+	defaultBatchMaxRetries    = 5
+	defaultBatchRetryInitial  = 200 * time.Millisecond
+	defaultBatchRetryMax      = 30 * time.Second
+)
+
+// BatcherOptions configures a Batcher. Zero fields take the defaults.
+type BatcherOptions struct {
+	// MaxBatchSize sends the buffered events once this many are waiting
+	// (default MaxEmitBatchSize).
+	MaxBatchSize int
+	// FlushInterval sends buffered events at least this often (default 1s).
+	FlushInterval time.Duration
+	// MaxBuffered is how many events may wait to be sent; Emit blocks once
+	// it is reached (default 10 batches).
+	MaxBuffered int
+	// MaxRetries bounds the retries of a batch refused with 429 (default
+	// 5). Batches whose events all have idempotency keys are also retried
+	// on 5xx responses and connection errors.
+	MaxRetries int
+	// Backoff paces those retries (default 200ms doubling up to 30s). A
+	// longer Retry-After from the server wins.
+	Backoff Backoff
+	// OnError is called with events that could not be emitted: rejected by
+	// the server, or still failing after MaxRetries.
+	OnError func(events []EmitRequest, err error)
+}
+
+// Batcher buffers events and emits them with EmitBatch, in batches of up
+// to MaxBatchSize or every FlushInterval. When the server answers 429 the
+// batch is retried after a backoff, and new events wait meanwhile: Emit
+// blocks once MaxBuffered are waiting, pushing back on the producer.
+// Events rejected for exceeding an emit rate limit are retried the same
+// way. A Batcher is safe for concurrent use.
+type Batcher struct {
+	client *Client
+	opts   BatcherOptions
+
+	// mu guards closed; Emit holds it for reading while queueing, so Close
+	// knows no event is queued after the final flush.
+	mu      sync.RWMutex
+	closed  bool
+	events  chan EmitRequest
+	flushes chan chan error
+	closing chan struct{}
+	done    chan struct{}
+
+	// ctx bounds sends and retry waits; Close cancels it when its own
+	// context is done first.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewBatcher starts a Batcher emitting through the client. Close it to
+// send the remaining events.
+func (c *Client) NewBatcher(opts BatcherOptions) *Batcher {
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = MaxEmitBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultBatchFlushInterval
+	}
+	if opts.MaxBuffered <= 0 {
+		opts.MaxBuffered = 10 * opts.MaxBatchSize
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultBatchMaxRetries
+	}
+	if opts.Backoff.Initial <= 0 {
+		opts.Backoff.Initial = defaultBatchRetryInitial
+	}
+	if opts.Backoff.Max < opts.Backoff.Initial {
+		opts.Backoff.Max = max(defaultBatchRetryMax, opts.Backoff.Initial)
+	}
+
+	b := &Batcher{
+		client:  c,
+		opts:    opts,
+		events:  make(chan EmitRequest, opts.MaxBuffered),
+		flushes: make(chan chan error),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	go b.run()
+	return b
+}
+
+// Emit queues an event. It blocks while MaxBuffered events are waiting,
+// until ctx is done.
+func (b *Batcher) Emit(ctx context.Context, topic string, data json.RawMessage, opts ...EmitOption) error {
+	req := EmitRequest{Topic: topic, Data: data}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	return b.EmitRequest(ctx, req)
+}
+
+// EmitRequest queues an event from a full request, as Emit does.
+func (b *Batcher) EmitRequest(ctx context.Context, req EmitRequest) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBatcherClosed
+	}
+	select {
+	case b.events <- req:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush sends every event queued so far and waits for the batches to
+// finish. It returns the last error, if some events could not be emitted.
+func (b *Batcher) Flush(ctx context.Context) error {
+	reply := make(chan error, 1)
+	select {
+	case b.flushes <- reply:
+	case <-b.done:
+		return ErrBatcherClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events, sends the queued ones and waits for them
+// until ctx is done. Then sends still in flight or waiting to retry are
+// abandoned, their events passed to OnError, and ctx's error returned.
+func (b *Batcher) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.closing)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		<-b.done
+		return ctx.Err()
+	}
+}
+
+func (b *Batcher) run() {
+	defer close(b.done)
+	defer b.cancel()
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var pending []EmitRequest
+	for {
+		select {
+		case req := <-b.events:
+			pending = append(pending, req)
+			if len(pending) >= b.opts.MaxBatchSize {
+				b.send(pending)
+				pending = nil
+			}
+		case <-ticker.C:
+			b.sendAll(pending)
+			pending = nil
+		case reply := <-b.flushes:
+			reply <- b.sendAll(b.drain(pending))
+			pending = nil
+		case <-b.closing:
+			b.sendAll(b.drain(pending))
+			return
+		}
+	}
+}
+
+// drain appends the events queued so far to pending.
+func (b *Batcher) drain(pending []EmitRequest) []EmitRequest {
+	for {
+		select {
+		case req := <-b.events:
+			pending = append(pending, req)
+		default:
+			return pending
+		}
+	}
+}
+
+// sendAll sends events in batches of up to MaxBatchSize and returns the
+// last error.
+func (b *Batcher) sendAll(events []EmitRequest) error {
+	var lastErr error
+	for len(events) > 0 {
+		n := min(len(events), b.opts.MaxBatchSize)
+		if err := b.send(events[:n]); err != nil {
+			lastErr = err
+		}
+		events = events[n:]
+	}
+	return lastErr
+}
+
+// send emits one batch, retrying what the server refused for now.
+func (b *Batcher) send(events []EmitRequest) error {
+	var lastErr error
+	delay := b.opts.Backoff.Initial
+	for attempt := 0; ; attempt++ {
+		resp, err := b.client.EmitBatch(b.ctx, events)
+		if err == nil {
+			var rejected error
+			events, rejected = b.results(events, resp)
+			if rejected != nil {
+				lastErr = rejected
+			}
+			if len(events) == 0 {
+				return lastErr
+			}
+			err = &APIError{StatusCode: http.StatusTooManyRequests, Message: "emit rate limit exceeded", Code: "RATE_LIMITED"}
+		}
+		if attempt == b.opts.MaxRetries || !retryableBatchError(events, err) {
+			b.fail(events, err)
+			return err
+		}
+
+		wait := b.opts.Backoff.wait(delay)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-b.ctx.Done():
+			timer.Stop()
+			b.fail(events, b.ctx.Err())
+			return b.ctx.Err()
+		}
+		delay = b.opts.Backoff.next(delay)
+	}
+}
+
+// results reports the events the server rejected and returns the ones an
+// emit rate limit refused, to retry, along with the last rejection.
+func (b *Batcher) results(events []EmitRequest, resp *EmitBatchResponse) ([]EmitRequest, error) {
+	var retry []EmitRequest
+	var rejected error
+	for i, result := range resp.Results {
+		if result.Error == "" || i >= len(events) {
+			continue
+		}
+		if result.Code == "RATE_LIMITED" {
+			retry = append(retry, events[i])
+			continue
+		}
+		err := &APIError{Message: result.Error, Code: result.Code}
+		b.fail(events[i:i+1], err)
+		rejected = err
+	}
+	return retry, rejected
+}
+
+func (b *Batcher) fail(events []EmitRequest, err error) {
+	if b.opts.OnError != nil {
+		b.opts.OnError(events, err)
+	}
+}
+
+// retryableBatchError reports whether a batch may succeed if sent again.
+// A 429 refused the whole batch; after a 5xx or a connection error some
+// events may have been published, so like Emit it only retries when every
+// event has an idempotency key.
+func retryableBatchError(events []EmitRequest, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	for _, req := range events {
+		if req.IdempotencyKey == "" {
+			return false
+		}
+	}
+	return retryableEmitError(err)
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchServer records the size of each batch it accepts. refuse is called
+// with the number of each request and may answer it instead, returning true.
+func batchServer(t *testing.T, refuse func(w http.ResponseWriter, request int) bool) (*httptest.Server, func() []int) {
+	t.Helper()
+	var mu sync.Mutex
+	var batches []int
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/emit/batch" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var body struct {
+			Events []EmitRequest `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if refuse != nil && refuse(w, requests) {
+			return
+		}
+		batches = append(batches, len(body.Events))
+		resp := EmitBatchResponse{Emitted: len(body.Events)}
+		for _, e := range body.Events {
+			resp.Results = append(resp.Results, EmitBatchResult{ID: "evt", Topic: e.Topic})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), batches...)
+	}
+}
+
+func TestBatcher_FlushesInBatches(t *testing.T) {
+	server, batches := batchServer(t, nil)
+	c := New("test-api-key", WithServer(server.URL))
+	b := c.NewBatcher(BatcherOptions{MaxBatchSize: 3, FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 7; i++ {
+		if err := b.Emit(ctx, "orders.created", json.RawMessage(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two full batches go out on their own; Flush sends the rest
+	if err := b.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := batches(); len(got) != 3 || got[0] != 3 || got[1] != 3 || got[2] != 1 {
+		t.Errorf("batches = %v, want [3 3 1]", got)
+	}
+
+	b.Emit(ctx, "orders.created", json.RawMessage(`{}`))
+	if err := b.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := batches(); len(got) != 4 || got[3] != 1 {
+		t.Errorf("batches after close = %v, want the last event sent", got)
+	}
+	if err := b.Emit(ctx, "orders.created", json.RawMessage(`{}`)); err != ErrBatcherClosed {
+		t.Errorf("emit after close = %v, want ErrBatcherClosed", err)
+	}
+}
+
+func TestBatcher_FlushInterval(t *testing.T) {
+	server, batches := batchServer(t, nil)
+	c := New("test-api-key", WithServer(server.URL))
+	b := c.NewBatcher(BatcherOptions{FlushInterval: 20 * time.Millisecond})
+	defer b.Close(context.Background())
+
+	b.Emit(context.Background(), "orders.created", json.RawMessage(`{}`))
+	deadline := time.Now().Add(2 * time.Second)
+	for len(batches()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("buffered event was not flushed on the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBatcher_BacksOffOn429(t *testing.T) {
+	var requests atomic.Int32
+	server, batches := batchServer(t, func(w http.ResponseWriter, request int) bool {
+		requests.Store(int32(request))
+		if request > 1 {
+			return false
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "rate limit exceeded"})
+		return true
+	})
+
+	c := New("test-api-key", WithServer(server.URL))
+	const backoff = 50 * time.Millisecond
+	b := c.NewBatcher(BatcherOptions{
+		MaxBatchSize:  2,
+		FlushInterval: time.Hour,
+		Backoff:       Backoff{Initial: backoff, Max: time.Second},
+	})
+	ctx := context.Background()
+
+	start := time.Now()
+	b.Emit(ctx, "orders.created", json.RawMessage(`{}`))
+	b.Emit(ctx, "orders.created", json.RawMessage(`{}`))
+	if err := b.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := batches(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("batches = %v, want the refused batch retried whole", got)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want a 429 then a retry", n)
+	}
+	if elapsed := time.Since(start); elapsed < backoff {
+		t.Errorf("retried after %v, want a backoff of at least %v", elapsed, backoff)
+	}
+}
+
+func TestBatcher_CloseAbandonsBackoff(t *testing.T) {
+	server, _ := batchServer(t, func(w http.ResponseWriter, request int) bool {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]string{"error": "rate limit exceeded"})
+		return true
+	})
+
+	var failed atomic.Int32
+	var failErr error
+	c := New("test-api-key", WithServer(server.URL))
+	b := c.NewBatcher(BatcherOptions{
+		FlushInterval: time.Hour,
+		OnError: func(events []EmitRequest, err error) {
+			failed.Add(int32(len(events)))
+			failErr = err
+		},
+	})
+	b.Emit(context.Background(), "orders.created", json.RawMessage(`{}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := b.Close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("close = %v, want the deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("close took %v, want it to stop waiting out Retry-After", elapsed)
+	}
+	if n := failed.Load(); n != 1 || failErr != context.Canceled {
+		t.Errorf("OnError got %d events (%v), want the abandoned one", n, failErr)
+	}
+}
+
+func TestBatcher_RetriesRateLimitedEvents(t *testing.T) {
+	var mu sync.Mutex
+	var topics [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []EmitRequest `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		first := len(topics) == 0
+		batch := []string{}
+		for _, e := range body.Events {
+			batch = append(batch, e.Topic)
+		}
+		topics = append(topics, batch)
+		mu.Unlock()
+
+		var resp EmitBatchResponse
+		for _, e := range body.Events {
+			switch {
+			case e.Topic == "bad topic":
+				resp.Results = append(resp.Results, EmitBatchResult{Topic: e.Topic, Error: "invalid topic", Code: "INVALID_TOPIC"})
+			case first && e.Topic == "clicks":
+				resp.Results = append(resp.Results, EmitBatchResult{Topic: e.Topic, Error: "emit rate limit exceeded", Code: "RATE_LIMITED"})
+			default:
+				resp.Results = append(resp.Results, EmitBatchResult{ID: "evt", Topic: e.Topic})
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	var failed []string
+	c := New("test-api-key", WithServer(server.URL))
+	b := c.NewBatcher(BatcherOptions{
+		FlushInterval: time.Hour,
+		Backoff:       Backoff{Initial: time.Millisecond, Max: time.Millisecond},
+		OnError: func(events []EmitRequest, err error) {
+			for _, e := range events {
+				failed = append(failed, e.Topic)
+			}
+		},
+	})
+	ctx := context.Background()
+	for _, topic := range []string{"orders", "clicks", "bad topic"} {
+		b.Emit(ctx, topic, json.RawMessage(`{}`))
+	}
+	if err := b.Flush(ctx); err == nil {
+		t.Error("flush = nil error, want the rejected event's error")
+	}
+	b.Close(ctx)
+
+	if len(topics) != 2 || len(topics[1]) != 1 || topics[1][0] != "clicks" {
+		t.Errorf("batches = %v, want only the rate-limited event retried", topics)
+	}
+	if len(failed) != 1 || failed[0] != "bad topic" {
+		t.Errorf("failed = %v, want the rejected event", failed)
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Blob is a file uploaded out-of-band and attached to events by ID.
+type Blob struct {
+	ID          string `json:"id"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Status      string `json:"status"` // "pending" or "uploaded"
+	CreatedAt   string `json:"created_at"`
+
+	// Set by BlobCreate: where and how to upload the content.
+	UploadURL       string `json:"upload_url,omitempty"`
+	UploadMethod    string `json:"upload_method,omitempty"`
+	UploadExpiresAt string `json:"upload_expires_at,omitempty"`
+
+	// Set by BlobGet once the upload has landed.
+	DownloadURL       string `json:"download_url,omitempty"`
+	DownloadExpiresAt string `json:"download_expires_at,omitempty"`
+}
+
+// Attachment is a blob delivered with an event. URL is a presigned download
+// link valid until ExpiresAt.
+type Attachment struct {
+	ID          string    `json:"id"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	URL         string    `json:"url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// BlobCreate registers a blob of at most size bytes and returns its
+// presigned upload URL.
+func (c *Client) BlobCreate(contentType string, size int64) (*Blob, error) {
+	reqBody, _ := json.Marshal(map[string]any{
+		"content_type": contentType,
+		"size":         size,
+	})
+
+	req, err := http.NewRequest("POST", c.server+"/api/v1/blobs", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, &AuthError{Message: "invalid or missing API key"}
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var blob Blob
+	if err := json.NewDecoder(resp.Body).Decode(&blob); err != nil {
+		return nil, err
+	}
+
+	return &blob, nil
+}
+
+// BlobUpload sends content to the blob's presigned upload URL. The URL is
+// self-authenticating, so no API key is sent.
+func (c *Client) BlobUpload(blob *Blob, content io.Reader, size int64) error {
+	method := blob.UploadMethod
+	if method == "" {
+		method = "PUT"
+	}
+	req, err := http.NewRequest(method, blob.UploadURL, content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", blob.ContentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("upload failed: %s", bytes.TrimSpace(body))}
+	}
+
+	return nil
+}
+
+// BlobGet retrieves a blob's metadata, including a download URL once uploaded.
+func (c *Client) BlobGet(id string) (*Blob, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/blobs/%s", c.server, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "blob not found"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to get blob"}
+	}
+
+	var blob Blob
+	if err := json.NewDecoder(resp.Body).Decode(&blob); err != nil {
+		return nil, err
+	}
+
+	return &blob, nil
+}
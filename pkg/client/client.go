@@ -19,6 +19,14 @@ type Client struct {
 	server     string
 	projectID  string // For JWT auth - sent as X-Project-ID header
 	httpClient *http.Client
+
+	reconnectBackoff Backoff
+
+	// compression is set by WithCompression
+	compression bool
+
+	// schemas is set by WithSchemaValidation
+	schemas *schemaCache
 }
 
 // Option configures the client.
@@ -32,6 +40,10 @@ func New(apiKey string, opts ...Option) *Client {
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
+		reconnectBackoff: Backoff{
+			Initial: initialReconnectDelay,
+			Max:     maxReconnectDelay,
+		},
 	}
 
 	for _, opt := range opts {
@@ -71,6 +83,40 @@ func WithProjectID(projectID string) Option {
 	}
 }
 
+// WithReconnectBackoff sets how subscriptions back off between reconnection
+// attempts: starting at initial, doubling up to max, with each wait shortened
+// by a random fraction of up to jitter (0-1). Non-positive durations keep the
+// defaults (1s, 30s).
+func WithReconnectBackoff(initial, max time.Duration, jitter float64) Option {
+	return func(c *Client) {
+		if initial > 0 {
+			c.reconnectBackoff.Initial = initial
+		}
+		if max > 0 {
+			c.reconnectBackoff.Max = max
+		}
+		if c.reconnectBackoff.Max < c.reconnectBackoff.Initial {
+			c.reconnectBackoff.Max = c.reconnectBackoff.Initial
+		}
+		switch {
+		case jitter < 0:
+			jitter = 0
+		case jitter > 1:
+			jitter = 1
+		}
+		c.reconnectBackoff.Jitter = jitter
+	}
+}
+
+// WithCompression offers permessage-deflate when subscriptions connect, so
+// event frames are compressed if the server agrees. Servers without it
+// keep sending plain frames.
+func WithCompression() Option {
+	return func(c *Client) {
+		c.compression = true
+	}
+}
+
 // ServerURL returns the configured server URL.
 func (c *Client) ServerURL() string {
 	return c.server
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -34,15 +35,39 @@ type DLQListResponse struct {
 	Count    int        `json:"count"`
 }
 
+// DLQListOptions filters a DLQ listing. Zero fields match every message.
+type DLQListOptions struct {
+	Topic string
+	Limit int
+	// OlderThan keeps messages that failed at least this long ago.
+	OlderThan     time.Duration
+	MinAttempts   int
+	ConsumerGroup string
+}
+
 // DLQList lists messages in the dead letter queue.
 func (c *Client) DLQList(topic string, limit int) (*DLQListResponse, error) {
+	return c.DLQListWithOptions(DLQListOptions{Topic: topic, Limit: limit})
+}
+
+// DLQListWithOptions lists messages in the dead letter queue that match opts.
+func (c *Client) DLQListWithOptions(opts DLQListOptions) (*DLQListResponse, error) {
 	u, _ := url.Parse(c.server + "/api/v1/dlq")
 	q := u.Query()
-	if topic != "" {
-		q.Set("topic", topic)
+	if opts.Topic != "" {
+		q.Set("topic", opts.Topic)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
 	}
-	if limit > 0 {
-		q.Set("limit", strconv.Itoa(limit))
+	if opts.OlderThan > 0 {
+		q.Set("older_than", opts.OlderThan.String())
+	}
+	if opts.MinAttempts > 0 {
+		q.Set("min_attempts", strconv.Itoa(opts.MinAttempts))
+	}
+	if opts.ConsumerGroup != "" {
+		q.Set("consumer_group", opts.ConsumerGroup)
 	}
 	u.RawQuery = q.Encode()
 
@@ -185,19 +210,82 @@ func (c *Client) DLQReplayAll(topic string) (*DLQReplayAllResponse, error) {
 	return &result, nil
 }
 
+// DLQReplayRequest selects DLQ messages to replay: either Seqs (at most
+// 1000) or every message on Topic.
+type DLQReplayRequest struct {
+	Seqs  []uint64 `json:"seqs,omitempty"`
+	Topic string   `json:"topic,omitempty"`
+}
+
+// DLQReplayResponse counts the outcome of a replay. Skipped messages were
+// already replayed within the server's replay window; NotFound seqs don't
+// exist in the project's DLQ.
+type DLQReplayResponse struct {
+	Replayed int `json:"replayed"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
+	NotFound int `json:"not_found"`
+}
+
+// DLQReplayBatch replays the selected DLQ messages to their original topics
+// and removes them from the DLQ.
+func (c *Client) DLQReplayBatch(replayReq DLQReplayRequest) (*DLQReplayResponse, error) {
+	reqBody, _ := json.Marshal(replayReq)
+
+	req, err := http.NewRequest("POST", c.server+"/api/v1/dlq/replay", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to replay messages"}
+	}
+
+	var result DLQReplayResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // DLQPurgeResponse is the response from purge.
 type DLQPurgeResponse struct {
 	Deleted int `json:"deleted"`
 }
 
+// DLQPurgeOptions selects the DLQ messages to purge. Zero fields match
+// every message.
+type DLQPurgeOptions struct {
+	Topic string
+	// Before keeps messages that failed before this time.
+	Before time.Time
+}
+
 // DLQPurge deletes all messages from the DLQ.
 func (c *Client) DLQPurge(topic string) (*DLQPurgeResponse, error) {
-	u, _ := url.Parse(c.server + "/api/v1/dlq/purge")
-	if topic != "" {
-		q := u.Query()
-		q.Set("topic", topic)
-		u.RawQuery = q.Encode()
+	return c.DLQPurgeWithOptions(DLQPurgeOptions{Topic: topic})
+}
+
+// DLQPurgeWithOptions deletes the DLQ messages that match opts.
+func (c *Client) DLQPurgeWithOptions(opts DLQPurgeOptions) (*DLQPurgeResponse, error) {
+	u, _ := url.Parse(c.server + "/api/v1/dlq")
+	q := u.Query()
+	if opts.Topic != "" {
+		q.Set("topic", opts.Topic)
+	}
+	if !opts.Before.IsZero() {
+		q.Set("before", opts.Before.UTC().Format(time.RFC3339))
 	}
+	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequest("DELETE", u.String(), nil)
 	if err != nil {
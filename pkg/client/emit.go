@@ -2,8 +2,11 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -11,6 +14,15 @@ import (
 type EmitRequest struct {
 	Topic string          `json:"topic"`
 	Data  json.RawMessage `json:"data"`
+	// Attachments lists blob IDs (see BlobCreate) to attach to the event.
+	Attachments []string `json:"attachments,omitempty"`
+	// IdempotencyKey makes retries safe: the server publishes the event once
+	// per key within its dedup window (24h by default) and answers repeats
+	// with the original event. See WithIdempotencyKey.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// AckDeadline, e.g. "15m", gives subscribers this long to ack the event
+	// instead of their subscription's ack timeout. See WithAckDeadline.
+	AckDeadline string `json:"ack_deadline,omitempty"`
 }
 
 // EmitResponse represents the response from emit.
@@ -18,15 +30,84 @@ type EmitResponse struct {
 	ID        string    `json:"id"`
 	Topic     string    `json:"topic"`
 	CreatedAt time.Time `json:"created_at"`
+	// Duplicate is set when the idempotency key was already used: the
+	// event was not published again.
+	Duplicate bool `json:"duplicate,omitempty"`
 }
 
+// EmitOption configures a single Emit call.
+type EmitOption func(*EmitRequest)
+
+// WithIdempotencyKey sends the emit with an Idempotency-Key, so the server
+// deduplicates it, and retries it on connection errors and 5xx responses.
+func WithIdempotencyKey(key string) EmitOption {
+	return func(req *EmitRequest) {
+		req.IdempotencyKey = key
+	}
+}
+
+// WithAckDeadline gives subscribers d to ack the event, overriding their
+// subscription's ack timeout. The server accepts 1s to 1h.
+func WithAckDeadline(d time.Duration) EmitOption {
+	return func(req *EmitRequest) {
+		req.AckDeadline = d.String()
+	}
+}
+
+// maxEmitAttempts bounds the attempts of an emit with an idempotency key.
+const maxEmitAttempts = 3
+
+// emitRetryDelay is the wait before the first retry; it doubles after each.
+var emitRetryDelay = 200 * time.Millisecond
+
 // Emit publishes an event to a topic.
-func (c *Client) Emit(topic string, data json.RawMessage) (*EmitResponse, error) {
+func (c *Client) Emit(topic string, data json.RawMessage, opts ...EmitOption) (*EmitResponse, error) {
 	req := EmitRequest{
 		Topic: topic,
 		Data:  data,
 	}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	return c.EmitWithOptions(req)
+}
+
+// EmitWithOptions publishes an event from a full request. With
+// WithSchemaValidation, data that fails the topic's schema returns a
+// *SchemaValidationError and is not sent. Requests with an IdempotencyKey
+// are retried on connection errors and 5xx responses.
+func (c *Client) EmitWithOptions(req EmitRequest) (*EmitResponse, error) {
+	if c.schemas != nil {
+		if err := c.validateLocally(req.Topic, req.Data); err != nil {
+			return nil, err
+		}
+	}
 
+	if req.IdempotencyKey == "" {
+		return c.emit(req)
+	}
+	delay := emitRetryDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := c.emit(req)
+		if err == nil || attempt == maxEmitAttempts || !retryableEmitError(err) {
+			return resp, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// retryableEmitError reports whether an emit may have failed transiently.
+func retryableEmitError(err error) bool {
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
+
+func (c *Client) emit(req EmitRequest) (*EmitResponse, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -38,6 +119,9 @@ func (c *Client) Emit(topic string, data json.RawMessage) (*EmitResponse, error)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
+	if req.IdempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.IdempotencyKey)
+	}
 	c.setAuthHeaders(httpReq)
 
 	resp, err := c.httpClient.Do(httpReq)
@@ -62,6 +146,7 @@ func (c *Client) Emit(topic string, data json.RawMessage) (*EmitResponse, error)
 		return nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    msg,
+			RetryAfter: retryAfter(resp),
 		}
 	}
 
@@ -72,3 +157,86 @@ func (c *Client) Emit(topic string, data json.RawMessage) (*EmitResponse, error)
 
 	return &emitResp, nil
 }
+
+// retryAfter returns the response's Retry-After hint in seconds, or 0.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// EmitBatchResult is the outcome of one event in a batch. Exactly one of
+// ID and Error is set.
+type EmitBatchResult struct {
+	ID        string     `json:"id,omitempty"`
+	Topic     string     `json:"topic"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Code      string     `json:"code,omitempty"`
+}
+
+// EmitBatchResponse represents the response from a batch emit. Results are
+// in request order.
+type EmitBatchResponse struct {
+	Results []EmitBatchResult `json:"results"`
+	Emitted int               `json:"emitted"`
+	Failed  int               `json:"failed"`
+}
+
+// MaxEmitBatchSize is the most events a server accepts in one EmitBatch
+// by default (its EMIT_BATCH_MAX_EVENTS). The whole request is also capped
+// at EMIT_BATCH_MAX_BYTES (default 4MB).
+const MaxEmitBatchSize = 500
+
+// EmitBatch publishes several events in one request. Events are validated
+// individually; rejected ones are reported in their result rather than
+// failing the call. ctx bounds the request.
+func (c *Client) EmitBatch(ctx context.Context, reqs []EmitRequest) (*EmitBatchResponse, error) {
+	body, err := json.Marshal(map[string]any{"events": reqs})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.server+"/api/v1/emit/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, &AuthError{Message: "invalid or missing API key"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		msg := errResp.Error
+		if msg == "" {
+			msg = "batch emit failed"
+		}
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    msg,
+			RetryAfter: retryAfter(resp),
+		}
+	}
+
+	var batchResp EmitBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batchResp); err != nil {
+		return nil, err
+	}
+
+	return &batchResp, nil
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmit_IdempotencyKeyRetries(t *testing.T) {
+	defer func(d time.Duration) { emitRetryDelay = d }(emitRetryDelay)
+	emitRetryDelay = time.Millisecond
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Idempotency-Key") != "order-1" {
+			t.Errorf("Idempotency-Key = %q", r.Header.Get("Idempotency-Key"))
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(EmitResponse{ID: "evt_1", Topic: "orders.created", Duplicate: true})
+	}))
+	defer server.Close()
+	c := New("test-api-key", WithServer(server.URL))
+
+	resp, err := c.Emit("orders.created", json.RawMessage(`{}`), WithIdempotencyKey("order-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "evt_1" || !resp.Duplicate {
+		t.Errorf("resp = %+v", resp)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("attempts = %d, want 2", n)
+	}
+}
+
+func TestEmit_NoRetryWithoutIdempotencyKey(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	c := New("test-api-key", WithServer(server.URL))
+
+	_, err := c.Emit("orders.created", json.RawMessage(`{}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want a 503 *APIError", err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("attempts = %d, want 1", n)
+	}
+}
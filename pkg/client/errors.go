@@ -3,6 +3,7 @@ package client
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Sentinel errors for connection handling.
@@ -18,10 +19,42 @@ func (e *ReconnectedError) Error() string {
 	return "reconnected"
 }
 
+// Reasons the server closes a subscription's connection, in ClosedError.
+const (
+	CloseReasonCaughtUp       = "caught_up"
+	CloseReasonAuthExpired    = "auth_expired"
+	CloseReasonKicked         = "kicked"
+	CloseReasonServerDraining = "server_draining"
+	CloseReasonSlowConsumer   = "slow_consumer"
+	CloseReasonProtocolError  = "protocol_error"
+)
+
+// ClosedError is sent when the server closes the connection and says why.
+// Reconnect reports whether the subscription reconnects on its own; when
+// false it has stopped, e.g. after its API key was revoked.
+type ClosedError struct {
+	Reason    string
+	Code      int
+	Message   string
+	Reconnect bool
+}
+
+func (e *ClosedError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("closed by server: %s", e.Reason)
+	}
+	return fmt.Sprintf("closed by server: %s: %s", e.Reason, e.Message)
+}
+
 // APIError represents an error from the API.
 type APIError struct {
 	StatusCode int
 	Message    string
+	// Code is the error code of a WebSocket error frame, e.g.
+	// "INVALID_FILTER" or "TOO_MANY_CONSUMERS".
+	Code string
+	// RetryAfter is the server's Retry-After hint, if it sent one.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
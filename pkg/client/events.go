@@ -25,6 +25,9 @@ type StoredEvent struct {
 type EventsListResponse struct {
 	Events []StoredEvent `json:"events"`
 	Count  int           `json:"count"`
+	// NextCursor is set when the page is full; pass it as After to fetch
+	// the next page.
+	NextCursor uint64 `json:"next_cursor,omitempty"`
 }
 
 // EventsQueryOptions configures event queries.
@@ -33,6 +36,8 @@ type EventsQueryOptions struct {
 	From  time.Time
 	To    time.Time
 	Limit int
+	// After resumes listing after this stream sequence (a NextCursor).
+	After uint64
 }
 
 // EventsList queries historical events.
@@ -52,6 +57,9 @@ func (c *Client) EventsList(opts EventsQueryOptions) (*EventsListResponse, error
 	if opts.Limit > 0 {
 		q.Set("limit", strconv.Itoa(opts.Limit))
 	}
+	if opts.After > 0 {
+		q.Set("after", strconv.FormatUint(opts.After, 10))
+	}
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequest("GET", u.String(), nil)
@@ -82,6 +90,89 @@ func (c *Client) EventsList(opts EventsQueryOptions) (*EventsListResponse, error
 	return &result, nil
 }
 
+// EventsSearchResponse is the response from searching events by data.
+// Found events carry no stream sequence.
+type EventsSearchResponse struct {
+	Events []StoredEvent `json:"events"`
+	Count  int           `json:"count"`
+	// NextCursor is set when the page is full; pass it as Before to fetch
+	// the next page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// EventsSearchOptions configures a search by data fields.
+type EventsSearchOptions struct {
+	// Filters are data field predicates, data.<field>=<value>, ANDed together.
+	Filters []string
+	Topic   string
+	From    time.Time
+	To      time.Time
+	Limit   int
+	// Before resumes the search after this event id (a NextCursor).
+	Before string
+}
+
+// EventsSearch finds historical events whose data matches every filter,
+// newest first.
+func (c *Client) EventsSearch(opts EventsSearchOptions) (*EventsSearchResponse, error) {
+	u, _ := url.Parse(c.server + "/api/v1/events")
+	q := u.Query()
+
+	for _, f := range opts.Filters {
+		q.Add("filter", f)
+	}
+	if opts.Topic != "" {
+		q.Set("topic", opts.Topic)
+	}
+	if !opts.From.IsZero() {
+		q.Set("from", opts.From.Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		q.Set("to", opts.To.Format(time.RFC3339))
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Before != "" {
+		q.Set("before", opts.Before)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, &AuthError{Message: "invalid or missing API key"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to search events"
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var result EventsSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // EventsGet retrieves a specific event by sequence number.
 func (c *Client) EventsGet(seq uint64) (*StoredEvent, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/events/%d", c.server, seq), nil)
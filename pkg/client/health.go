@@ -51,3 +51,68 @@ func (c *Client) Ready() error {
 
 	return nil
 }
+
+// ReadyResponse represents the readiness check response.
+type ReadyResponse struct {
+	Status   string `json:"status"` // "ready" or "not_ready"
+	NATS     string `json:"nats"`
+	Database string `json:"database"`
+
+	// Multi-account servers also report their NATS account pool.
+	Accounts         int      `json:"accounts,omitempty"`
+	DisconnectedOrgs []string `json:"disconnected_orgs,omitempty"`
+}
+
+// ReadyStatus returns the server's dependency status. Unlike Ready, a
+// not-ready server is not an error: the response says which dependency is down.
+func (c *Client) ReadyStatus() (*ReadyResponse, error) {
+	resp, err := c.httpClient.Get(c.server + "/ready")
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    "readiness check failed",
+		}
+	}
+
+	var ready ReadyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ready); err != nil {
+		return nil, err
+	}
+
+	return &ready, nil
+}
+
+// VersionInfo describes the server's build.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Version returns the build the server is running.
+func (c *Client) Version() (*VersionInfo, error) {
+	resp, err := c.httpClient.Get(c.server + "/version")
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    "version request failed",
+		}
+	}
+
+	var info VersionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Pipeline is a named transformation applied to a project's events at
+// emit, before they're stored and delivered.
+type Pipeline struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	TopicPattern string    `json:"topic_pattern"`
+	Redact       []string  `json:"redact"`
+	Jq           string    `json:"jq,omitempty"`
+	Position     int       `json:"position"`
+	Enabled      bool      `json:"enabled"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PipelineListResponse is the response from listing pipelines.
+type PipelineListResponse struct {
+	Pipelines []Pipeline `json:"pipelines"`
+	Count     int        `json:"count"`
+}
+
+// CreatePipelineRequest is the request to create a pipeline. Redact lists
+// dotted paths into the event data whose values become "[redacted]"; Jq's
+// output then replaces the data. At least one of them is required.
+type CreatePipelineRequest struct {
+	Name string `json:"name"`
+	// TopicPattern defaults to every topic.
+	TopicPattern string   `json:"topic_pattern,omitempty"`
+	Redact       []string `json:"redact,omitempty"`
+	Jq           string   `json:"jq,omitempty"`
+	// Position defaults to after the existing pipelines.
+	Position *int  `json:"position,omitempty"`
+	Enabled  *bool `json:"enabled,omitempty"`
+}
+
+// UpdatePipelineRequest changes the fields that are set.
+type UpdatePipelineRequest struct {
+	TopicPattern *string  `json:"topic_pattern,omitempty"`
+	Redact       []string `json:"redact,omitempty"`
+	Jq           *string  `json:"jq,omitempty"`
+	Position     *int     `json:"position,omitempty"`
+	Enabled      *bool    `json:"enabled,omitempty"`
+}
+
+// PipelineCreate creates a pipeline.
+func (c *Client) PipelineCreate(createReq CreatePipelineRequest) (*Pipeline, error) {
+	reqBody, _ := json.Marshal(createReq)
+
+	req, err := http.NewRequest("POST", c.server+"/api/v1/pipelines", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var pipeline Pipeline
+	if err := json.NewDecoder(resp.Body).Decode(&pipeline); err != nil {
+		return nil, err
+	}
+
+	return &pipeline, nil
+}
+
+// PipelineList lists the project's pipelines in the order they apply.
+func (c *Client) PipelineList() (*PipelineListResponse, error) {
+	req, err := http.NewRequest("GET", c.server+"/api/v1/pipelines", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to list pipelines"}
+	}
+
+	var result PipelineListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PipelineGet retrieves a pipeline by name.
+func (c *Client) PipelineGet(name string) (*Pipeline, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/pipelines/%s", c.server, url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "pipeline not found"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to get pipeline"}
+	}
+
+	var pipeline Pipeline
+	if err := json.NewDecoder(resp.Body).Decode(&pipeline); err != nil {
+		return nil, err
+	}
+
+	return &pipeline, nil
+}
+
+// PipelineUpdate updates a pipeline.
+func (c *Client) PipelineUpdate(name string, req UpdatePipelineRequest) (*Pipeline, error) {
+	reqBody, _ := json.Marshal(req)
+
+	httpReq, err := http.NewRequest("PUT", fmt.Sprintf("%s/api/v1/pipelines/%s", c.server, url.PathEscape(name)), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var pipeline Pipeline
+	if err := json.NewDecoder(resp.Body).Decode(&pipeline); err != nil {
+		return nil, err
+	}
+
+	return &pipeline, nil
+}
+
+// PipelineDelete deletes a pipeline.
+func (c *Client) PipelineDelete(name string) error {
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/api/v1/pipelines/%s", c.server, url.PathEscape(name)), nil)
+	if err != nil {
+		return err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: "failed to delete pipeline"}
+	}
+
+	return nil
+}
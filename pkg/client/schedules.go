@@ -14,6 +14,10 @@ type ScheduleRequest struct {
 	Data         json.RawMessage `json:"data"`
 	ScheduledFor *time.Time      `json:"scheduled_for,omitempty"`
 	In           string          `json:"in,omitempty"`
+	MaxAttempts  int             `json:"max_attempts,omitempty"` // retries on failure (server default 3)
+	// Cron makes the schedule recurring (five-field expression, UTC).
+	Cron           string `json:"cron,omitempty"`
+	MaxOccurrences int    `json:"max_occurrences,omitempty"` // 0 = unlimited
 }
 
 // ScheduleResponse is the response body for a scheduled event.
@@ -23,9 +27,18 @@ type ScheduleResponse struct {
 	Data         json.RawMessage `json:"data,omitempty"`
 	ScheduledFor time.Time       `json:"scheduled_for"`
 	Status       string          `json:"status,omitempty"`
+	Attempts     int             `json:"attempts,omitempty"`
+	MaxAttempts  int             `json:"max_attempts,omitempty"`
 	Error        *string         `json:"error,omitempty"`
 	CreatedAt    time.Time       `json:"created_at"`
 	ExecutedAt   *time.Time      `json:"executed_at,omitempty"`
+
+	// Recurring schedules only
+	Cron           string     `json:"cron,omitempty"`
+	NextRun        *time.Time `json:"next_run,omitempty"`
+	LastRun        *time.Time `json:"last_run,omitempty"`
+	Occurrences    int        `json:"occurrences,omitempty"`
+	MaxOccurrences int        `json:"max_occurrences,omitempty"`
 }
 
 // SchedulesListResponse is the response body for listing scheduled events.
@@ -42,13 +55,17 @@ type RunScheduleResponse struct {
 
 // Schedule creates a new scheduled event.
 func (c *Client) Schedule(topic string, data json.RawMessage, scheduledFor *time.Time, in string) (*ScheduleResponse, error) {
-	req := ScheduleRequest{
+	return c.CreateSchedule(ScheduleRequest{
 		Topic:        topic,
 		Data:         data,
 		ScheduledFor: scheduledFor,
 		In:           in,
-	}
+	})
+}
 
+// CreateSchedule creates a scheduled event with the full set of options,
+// including recurring cron schedules.
+func (c *Client) CreateSchedule(req ScheduleRequest) (*ScheduleResponse, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -36,6 +36,12 @@ type SchemaVersion struct {
 	IsLatest       bool            `json:"is_latest"`
 	CreatedAt      time.Time       `json:"created_at"`
 	CreatedBy      string          `json:"created_by,omitempty"`
+	// Pinned versions are exempt from the server's version retention.
+	Pinned bool `json:"pinned"`
+	// CompatibilityWarnings, returned by SchemaVersionCreate only, lists how
+	// a version created without a declared compatibility breaks backward
+	// compatibility. Declaring one rejects such versions instead.
+	CompatibilityWarnings []BreakingChange `json:"compatibility_warnings,omitempty"`
 }
 
 // SchemaListResponse is the response from listing schemas.
@@ -65,6 +71,28 @@ type ValidationResult struct {
 	Version string            `json:"version,omitempty"`
 }
 
+// ValidationStats counts validation outcomes.
+type ValidationStats struct {
+	Total        int64 `json:"total"`
+	ValidCount   int64 `json:"valid_count"`
+	InvalidCount int64 `json:"invalid_count"`
+}
+
+// SchemaValidationStats is a schema's emit-time validation outcomes over the
+// last Window (e.g. "24h"), overall and per version.
+type SchemaValidationStats struct {
+	Schema string `json:"schema"`
+	Window string `json:"window"`
+	ValidationStats
+	Versions []VersionValidationStats `json:"versions"`
+}
+
+// VersionValidationStats is the validation outcomes of one schema version.
+type VersionValidationStats struct {
+	Version string `json:"version"`
+	ValidationStats
+}
+
 // CreateSchemaRequest is the request to create a schema.
 type CreateSchemaRequest struct {
 	Name         string   `json:"name"`
@@ -95,6 +123,22 @@ type ValidateDataRequest struct {
 	Data json.RawMessage `json:"data"`
 }
 
+// BreakingChange is one way a new schema version breaks compatibility with
+// the latest, e.g. a field that became required.
+type BreakingChange struct {
+	Field      string `json:"field"`
+	ChangeType string `json:"change_type"`
+	Message    string `json:"message"`
+}
+
+// CompatibilityError is returned by SchemaVersionCreate when the new
+// version violates its compatibility mode.
+type CompatibilityError struct {
+	APIError
+	Compatibility string
+	Changes       []BreakingChange
+}
+
 // SchemaCreate creates a new schema.
 func (c *Client) SchemaCreate(req CreateSchemaRequest) (*Schema, error) {
 	reqBody, _ := json.Marshal(req)
@@ -160,7 +204,17 @@ func (c *Client) SchemaList() (*SchemaListResponse, error) {
 
 // SchemaGet retrieves a schema by name.
 func (c *Client) SchemaGet(name string) (*Schema, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/schemas/%s", c.server, name), nil)
+	return c.schemaGet(fmt.Sprintf("%s/api/v1/schemas/%s", c.server, name))
+}
+
+// SchemaGetBundled retrieves a schema by name with the notif://schemas/
+// refs in its latest version inlined, so the JSON Schema is self-contained.
+func (c *Client) SchemaGetBundled(name string) (*Schema, error) {
+	return c.schemaGet(fmt.Sprintf("%s/api/v1/schemas/%s?bundle=true", c.server, name))
+}
+
+func (c *Client) schemaGet(url string) (*Schema, error) {
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +230,14 @@ func (c *Client) SchemaGet(name string) (*Schema, error) {
 		return nil, &APIError{StatusCode: resp.StatusCode, Message: "schema not found"}
 	}
 
+	if resp.StatusCode == http.StatusConflict {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to get schema"}
 	}
@@ -265,9 +327,18 @@ func (c *Client) SchemaVersionCreate(schemaName string, req CreateSchemaVersionR
 
 	if resp.StatusCode != http.StatusCreated {
 		var errResp struct {
-			Error string `json:"error"`
+			Error           string           `json:"error"`
+			Compatibility   string           `json:"compatibility"`
+			BreakingChanges []BreakingChange `json:"breaking_changes"`
 		}
 		json.NewDecoder(resp.Body).Decode(&errResp)
+		if len(errResp.BreakingChanges) > 0 {
+			return nil, &CompatibilityError{
+				APIError:      APIError{StatusCode: resp.StatusCode, Message: errResp.Error},
+				Compatibility: errResp.Compatibility,
+				Changes:       errResp.BreakingChanges,
+			}
+		}
 		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
 	}
 
@@ -372,6 +443,37 @@ func (c *Client) SchemaValidate(schemaName string, data json.RawMessage) (*Valid
 	return &result, nil
 }
 
+// SchemaStats returns how many emitted events passed and failed validation
+// against a schema recently, per version.
+func (c *Client) SchemaStats(schemaName string) (*SchemaValidationStats, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/schemas/%s/stats", c.server, schemaName), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "schema not found"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to get schema stats"}
+	}
+
+	var stats SchemaValidationStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
 // SchemaForTopic finds the schema that matches a topic.
 func (c *Client) SchemaForTopic(topic string) (*Schema, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/schemas/for-topic/%s", c.server, topic), nil)
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// DLQStats is the response from DLQ stats.
+type DLQStats struct {
+	Total int64 `json:"total"`
+	// ByTopic and ByConsumerGroup break Total down. Messages without a
+	// consumer group are left out of ByConsumerGroup.
+	ByTopic         map[string]int64 `json:"by_topic,omitempty"`
+	ByConsumerGroup map[string]int64 `json:"by_consumer_group,omitempty"`
+}
+
+// WebhooksStats is the response from webhook stats.
+type WebhooksStats struct {
+	Total      int64                `json:"total"`
+	Enabled    int64                `json:"enabled"`
+	Disabled   int64                `json:"disabled"`
+	Deliveries WebhookDeliveryStats `json:"deliveries_24h"`
+}
+
+// WebhookDeliveryStats summarizes webhook deliveries over the last 24h.
+type WebhookDeliveryStats struct {
+	Total       int64   `json:"total"`
+	Success     int64   `json:"success"`
+	Failed      int64   `json:"failed"`
+	Pending     int64   `json:"pending"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// SchedulesStats counts scheduled events by status.
+type SchedulesStats struct {
+	Pending   int64 `json:"pending"`
+	Completed int64 `json:"completed"`
+	Cancelled int64 `json:"cancelled"`
+	Failed    int64 `json:"failed"`
+}
+
+// StreamStats is the storage usage of one JetStream stream.
+type StreamStats struct {
+	Name      string `json:"name"`
+	Messages  uint64 `json:"messages"`
+	Bytes     uint64 `json:"bytes"`
+	Consumers int    `json:"consumers"`
+	MaxBytes  int64  `json:"max_bytes"` // -1 = unlimited
+}
+
+// StorageUsage is the project's storage footprint, for quotas and billing.
+type StorageUsage struct {
+	ProjectID string `json:"project_id"`
+	// Streams are the org's event and DLQ streams, shared by its projects.
+	Streams []StreamStats `json:"streams"`
+	// Messages counts the project's events retained in the events stream.
+	Messages uint64 `json:"messages"`
+	// Events and EventBytes count persisted event records and payload bytes.
+	Events     int64     `json:"events"`
+	EventBytes int64     `json:"event_bytes"`
+	AsOf       time.Time `json:"as_of"`
+}
+
+// DLQStats returns the number of dead-lettered events in the project, by
+// topic and by consumer group. Servers without /dlq/stats fall back to
+// /stats/dlq, which only reports Total.
+func (c *Client) DLQStats() (*DLQStats, error) {
+	var stats DLQStats
+	err := c.getStats("/api/v1/dlq/stats", &stats)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		err = c.getStats("/api/v1/stats/dlq", &stats)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// WebhooksStats returns webhook counts and 24h delivery outcomes.
+func (c *Client) WebhooksStats() (*WebhooksStats, error) {
+	var stats WebhooksStats
+	if err := c.getStats("/api/v1/stats/webhooks", &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// SchedulesStats returns scheduled event counts by status.
+func (c *Client) SchedulesStats() (*SchedulesStats, error) {
+	var stats SchedulesStats
+	if err := c.getStats("/api/v1/stats/schedules", &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// StreamsStats returns storage usage of the server's event and DLQ streams.
+func (c *Client) StreamsStats() ([]StreamStats, error) {
+	var stats struct {
+		Streams []StreamStats `json:"streams"`
+	}
+	if err := c.getStats("/api/v1/stats/streams", &stats); err != nil {
+		return nil, err
+	}
+	return stats.Streams, nil
+}
+
+// StorageUsage returns the project's storage usage. The server caches it
+// for a few seconds; AsOf says when it was read.
+func (c *Client) StorageUsage() (*StorageUsage, error) {
+	var usage StorageUsage
+	if err := c.getStats("/api/v1/usage/storage", &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func (c *Client) getStats(path string, v any) error {
+	req, err := http.NewRequest("GET", c.server+path, nil)
+	if err != nil {
+		return err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return &AuthError{Message: "invalid or missing API key"}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to get stats"
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDLQStats_FallsBackToLegacyPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/stats/dlq" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"total":3}`))
+	}))
+	defer server.Close()
+	c := New("test-api-key", WithServer(server.URL))
+
+	stats, err := c.DLQStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 3 {
+		t.Errorf("Total = %d, want 3", stats.Total)
+	}
+}
@@ -3,6 +3,8 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math/rand"
 	"net/http"
 	"strings"
 	"sync"
@@ -31,37 +33,152 @@ const (
 	maxReconnectDelay = 30 * time.Second
 )
 
+// Backoff configures how long a subscription waits between reconnection
+// attempts: Initial, doubling after each failure up to Max. Jitter (0-1)
+// shortens each wait by a random fraction of up to Jitter, so clients
+// dropped together don't reconnect in lockstep.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	Jitter  float64
+}
+
+// next returns the delay after a failed attempt waited delay.
+func (b Backoff) next(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// wait returns how long to actually wait for delay, after jitter.
+func (b Backoff) wait(delay time.Duration) time.Duration {
+	if b.Jitter <= 0 {
+		return delay
+	}
+	return delay - time.Duration(rand.Float64()*b.Jitter*float64(delay))
+}
+
 // SubscribeOptions configures the subscription.
 type SubscribeOptions struct {
 	AutoAck bool
 	Group   string
-	From    string // "latest", "beginning", or timestamp
+	From    string // "latest", "beginning", timestamp, a ResumeToken, or "resume"
+	FromSeq uint64 // Stream sequence to start at; leave From empty
+
+	// ResumeToken, with From "resume", continues a durable resume cursor
+	// on the server: right after the token's event the first time, and
+	// exactly where the last connection left off when resumed again with
+	// a token of the same cursor. Not for groups.
+	ResumeToken string
+
+	// EnvelopeVersion pins the event envelope shape (0 = server's current).
+	EnvelopeVersion int
+
+	// Sample asks the server to deliver only every Nth matching event;
+	// SampleRate delivers each with the given probability (0 < rate <= 1).
+	// Set at most one; zero delivers everything.
+	Sample     int
+	SampleRate float64
+
+	// Sequential makes the consumer group deliver one event at a time
+	// across all members, each only after the previous one is acked. It
+	// serializes the whole group, whatever the events' keys. Requires Group.
+	Sequential bool
+
+	// DisplayConfig asks the server to push the display configs of schemas
+	// matching the topics on DisplayConfigs, on subscribe and whenever one
+	// of them changes.
+	DisplayConfig bool
+
+	// Project asks the server to send only these fields of each event's
+	// data, as dot-separated paths ("customer.email"). ProjectJq reshapes
+	// the data with a jq expression instead. Set at most one.
+	Project   []string
+	ProjectJq string
+
+	// Projects subscribes across these project IDs instead of the key's own
+	// project; each Event carries its ProjectID. Requires an admin key
+	// authorized for every listed project.
+	Projects []string
+
+	// MaxInFlight caps the unacked events the server delivers to the
+	// subscription at once; 0 keeps the server default. Groups share one
+	// cap, fixed when the group is created.
+	MaxInFlight int
+
+	// LatestPerKey names a data field ("sku", "customer.id"): of the events
+	// stored at subscribe time only the latest per key is delivered, then
+	// live events. Requires From "beginning" (or empty); not for groups.
+	LatestPerKey string
+}
+
+// DisplayConfig is a schema's x-notif-display config, pushed by the server.
+type DisplayConfig struct {
+	Schema       string          `json:"schema"`
+	TopicPattern string          `json:"topic_pattern"`
+	Display      json.RawMessage `json:"display"`
 }
 
 // Event represents a received event.
 type Event struct {
-	ID        string          `json:"id"`
-	Topic     string          `json:"topic"`
-	Data      json.RawMessage `json:"data"`
-	Timestamp time.Time       `json:"timestamp"`
-	Attempt   int             `json:"attempt,omitempty"`
+	EnvelopeVersion int             `json:"envelope_version"`
+	ID              string          `json:"id"`
+	Topic           string          `json:"topic"`
+	Data            json.RawMessage `json:"data"`
+	Timestamp       time.Time       `json:"timestamp"`
+	Attempt         int             `json:"attempt,omitempty"`
+
+	// StreamSeq and ConsumerSeq are the server's stream and consumer
+	// sequences for this delivery, for consumers tracking their own offsets.
+	StreamSeq   uint64 `json:"stream_seq,omitempty"`
+	ConsumerSeq uint64 `json:"consumer_seq,omitempty"`
+
+	// ProjectID is set on cross-project subscriptions only.
+	ProjectID string `json:"project_id,omitempty"`
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+
+	// Traceparent is the W3C trace context the event was emitted with.
+	Traceparent string `json:"traceparent,omitempty"`
+
+	// AckDeadline is the event's own ack deadline, e.g. "15m", set by its
+	// emitter in place of the subscription's ack timeout.
+	AckDeadline string `json:"ack_deadline,omitempty"`
 }
 
 // Subscription represents an active subscription with auto-reconnection.
 type Subscription struct {
-	client  *Client
-	topics  []string
-	opts    SubscribeOptions
-	conn    *websocket.Conn
-	connMu  sync.RWMutex
-	writeMu sync.Mutex // protects all writes to conn (gorilla/websocket is not thread-safe)
-	events  chan *Event
-	errors  chan error
-	done    chan struct{}
-	stopMu  sync.Mutex     // protects stopPumps
+	client    *Client
+	topics    []string
+	opts      SubscribeOptions
+	conn      *websocket.Conn
+	connMu    sync.RWMutex
+	writeMu   sync.Mutex // protects all writes to conn (gorilla/websocket is not thread-safe)
+	events    chan *Event
+	errors    chan error
+	displays  chan []DisplayConfig
+	done      chan struct{}
+	stopMu    sync.Mutex    // protects stopPumps
 	stopPumps chan struct{} // signals current pumps to stop on reconnect
-	closed  bool
-	closeMu sync.Mutex
+	closed    bool
+	closeMu   sync.Mutex
+
+	resumeMu    sync.Mutex
+	resumeToken string // from the latest "subscribed" or "acked" frame
+}
+
+// closedError builds the ClosedError for a "closing" frame.
+func closedError(msg map[string]any) *ClosedError {
+	e := &ClosedError{}
+	e.Reason, _ = msg["reason"].(string)
+	e.Message, _ = msg["message"].(string)
+	e.Reconnect, _ = msg["reconnect"].(bool)
+	if code, ok := msg["code"].(float64); ok {
+		e.Code = int(code)
+	}
+	return e
 }
 
 // Subscribe connects to the WebSocket and subscribes to topics.
@@ -73,9 +190,13 @@ func (c *Client) Subscribe(ctx context.Context, topics []string, opts SubscribeO
 		opts:      opts,
 		events:    make(chan *Event, 100),
 		errors:    make(chan error, 10),
+		displays:  make(chan []DisplayConfig, 10),
 		done:      make(chan struct{}),
 		stopPumps: make(chan struct{}),
 	}
+	if opts.From == "resume" {
+		sub.resumeToken = opts.ResumeToken
+	}
 
 	// Initial connection
 	if err := sub.connect(ctx); err != nil {
@@ -89,26 +210,36 @@ func (c *Client) Subscribe(ctx context.Context, topics []string, opts SubscribeO
 	return sub, nil
 }
 
-func (s *Subscription) connect(ctx context.Context) error {
+// dialWS opens an authenticated WebSocket connection to the server.
+func (c *Client) dialWS(ctx context.Context) (*websocket.Conn, error) {
 	// Convert HTTP URL to WebSocket URL
-	wsURL := strings.Replace(s.client.server, "http://", "ws://", 1)
+	wsURL := strings.Replace(c.server, "http://", "ws://", 1)
 	wsURL = strings.Replace(wsURL, "https://", "wss://", 1)
 	wsURL += "/ws"
-	if s.client.projectID != "" {
-		wsURL += "?project_id=" + s.client.projectID
+	if c.projectID != "" {
+		wsURL += "?project_id=" + c.projectID
 	}
 
 	// Set up headers with auth
 	header := http.Header{}
-	header.Set("Authorization", "Bearer "+s.client.apiKey)
+	header.Set("Authorization", "Bearer "+c.apiKey)
 
 	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
+		HandshakeTimeout:  10 * time.Second,
+		EnableCompression: c.compression,
 	}
 
 	conn, _, err := dialer.DialContext(ctx, wsURL, header)
 	if err != nil {
-		return &ConnectionError{Err: err}
+		return nil, &ConnectionError{Err: err}
+	}
+	return conn, nil
+}
+
+func (s *Subscription) connect(ctx context.Context) error {
+	conn, err := s.client.dialWS(ctx)
+	if err != nil {
+		return err
 	}
 
 	// Configure connection
@@ -123,14 +254,52 @@ func (s *Subscription) connect(ctx context.Context) error {
 	s.connMu.Unlock()
 
 	// Send subscribe message
+	options := map[string]any{
+		"auto_ack": s.opts.AutoAck,
+		"group":    s.opts.Group,
+		"from":     s.opts.From,
+	}
+	// Reconnects continue from the latest resume token; groups resume on
+	// their own, and latest_per_key snapshots start over
+	if token := s.ResumeToken(); token != "" && s.opts.Group == "" && s.opts.LatestPerKey == "" {
+		options["from"] = "resume"
+		options["resume_token"] = token
+	} else if s.opts.FromSeq > 0 {
+		options["from_seq"] = s.opts.FromSeq
+	}
+	if s.opts.EnvelopeVersion > 0 {
+		options["envelope_version"] = s.opts.EnvelopeVersion
+	}
+	if s.opts.Sample > 0 {
+		options["sample"] = s.opts.Sample
+	}
+	if s.opts.SampleRate > 0 {
+		options["sample_rate"] = s.opts.SampleRate
+	}
+	if s.opts.Sequential {
+		options["sequential"] = true
+	}
+	if s.opts.DisplayConfig {
+		options["display_config"] = true
+	}
+	if s.opts.ProjectJq != "" {
+		options["project"] = s.opts.ProjectJq
+	} else if len(s.opts.Project) > 0 {
+		options["project"] = s.opts.Project
+	}
+	if len(s.opts.Projects) > 0 {
+		options["projects"] = s.opts.Projects
+	}
+	if s.opts.MaxInFlight > 0 {
+		options["max_in_flight"] = s.opts.MaxInFlight
+	}
+	if s.opts.LatestPerKey != "" {
+		options["latest_per_key"] = s.opts.LatestPerKey
+	}
 	subscribeMsg := map[string]any{
-		"action": "subscribe",
-		"topics": s.topics,
-		"options": map[string]any{
-			"auto_ack": s.opts.AutoAck,
-			"group":    s.opts.Group,
-			"from":     s.opts.From,
-		},
+		"action":  "subscribe",
+		"topics":  s.topics,
+		"options": options,
 	}
 
 	s.writeMu.Lock()
@@ -144,6 +313,93 @@ func (s *Subscription) connect(ctx context.Context) error {
 	return nil
 }
 
+// Drain returns the events currently stored for topics, oldest first, and
+// closes the connection once the server reports it has caught up. Events
+// published while draining are not included. Delivered events are acked.
+func (c *Client) Drain(ctx context.Context, topics []string) ([]*Event, error) {
+	conn, err := c.dialWS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Unblock the read loop when ctx ends
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	subscribeMsg := map[string]any{
+		"action": "subscribe",
+		"topics": topics,
+		"options": map[string]any{
+			"auto_ack": true,
+			"from":     "beginning",
+			"until":    "caught_up",
+		},
+	}
+	if err := conn.WriteJSON(subscribeMsg); err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+
+	events := []*Event{}
+	for {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, &ConnectionError{Err: err}
+		}
+
+		switch msg["type"] {
+		case "event":
+			events = append(events, parseEvent(msg))
+		case "done":
+			return events, nil
+		case "error":
+			errMsg := "unknown error"
+			if m, ok := msg["message"].(string); ok {
+				errMsg = m
+			}
+			code, _ := msg["code"].(string)
+			return nil, &APIError{Message: errMsg, Code: code}
+		}
+	}
+}
+
+// WaitForEvent subscribes to topics and returns the first new event match
+// accepts, closing the subscription before it returns. A nil match accepts
+// any event. It fails when ctx ends first, the server rejects the
+// subscription or closes it for good, or reconnecting gives up.
+func (c *Client) WaitForEvent(ctx context.Context, topics []string, match func(*Event) bool) (*Event, error) {
+	sub, err := c.Subscribe(ctx, topics, SubscribeOptions{AutoAck: true, From: "latest"})
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case event := <-sub.Events():
+			if match == nil || match(event) {
+				return event, nil
+			}
+		case err := <-sub.Errors():
+			var apiErr *APIError
+			if errors.As(err, &apiErr) || errors.Is(err, ErrMaxReconnectAttempts) {
+				return nil, err
+			}
+			var closed *ClosedError
+			if errors.As(err, &closed) && !closed.Reconnect {
+				// No more events will come
+				return nil, err
+			}
+			// Transient; the subscription reconnects on its own
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
 func (s *Subscription) reconnect() {
 	s.closeMu.Lock()
 	if s.closed {
@@ -158,7 +414,8 @@ func (s *Subscription) reconnect() {
 	s.stopPumps = make(chan struct{})
 	s.stopMu.Unlock()
 
-	delay := initialReconnectDelay
+	backoff := s.client.reconnectBackoff
+	delay := backoff.Initial
 	attempts := 0
 
 	for {
@@ -172,7 +429,7 @@ func (s *Subscription) reconnect() {
 		select {
 		case <-s.done:
 			return
-		case <-time.After(delay):
+		case <-time.After(backoff.wait(delay)):
 		}
 
 		attempts++
@@ -207,10 +464,7 @@ func (s *Subscription) reconnect() {
 		default:
 		}
 
-		delay *= 2
-		if delay > maxReconnectDelay {
-			delay = maxReconnectDelay
-		}
+		delay = backoff.next(delay)
 	}
 }
 
@@ -229,6 +483,9 @@ func (s *Subscription) readPump() {
 		}
 	}()
 
+	// serverClose is why the server is closing the connection, once it says
+	var serverClose *ClosedError
+
 	for {
 		select {
 		case <-s.done:
@@ -248,15 +505,37 @@ func (s *Subscription) readPump() {
 
 		var msg map[string]any
 		if err := conn.ReadJSON(&msg); err != nil {
+			s.closeMu.Lock()
+			closed := s.closed
+			s.closeMu.Unlock()
+
+			// The server said why it closed: reconnect only if that can help
+			var closeErr *websocket.CloseError
+			if serverClose == nil && errors.As(err, &closeErr) {
+				// No room for the "closing" frame; the close frame still says
+				switch closeErr.Text {
+				case CloseReasonAuthExpired, CloseReasonKicked, CloseReasonProtocolError:
+					serverClose = &ClosedError{Reason: closeErr.Text, Code: closeErr.Code}
+				case CloseReasonServerDraining, CloseReasonSlowConsumer:
+					serverClose = &ClosedError{Reason: closeErr.Text, Code: closeErr.Code, Reconnect: true}
+				}
+			}
+			if serverClose != nil && !closed {
+				select {
+				case s.errors <- serverClose:
+				default:
+				}
+				if serverClose.Reconnect {
+					go s.reconnect()
+				}
+				return
+			}
+
 			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 				return
 			}
 
 			// Check if we should reconnect
-			s.closeMu.Lock()
-			closed := s.closed
-			s.closeMu.Unlock()
-
 			if !closed {
 				// Report error
 				select {
@@ -272,19 +551,7 @@ func (s *Subscription) readPump() {
 		msgType, _ := msg["type"].(string)
 		switch msgType {
 		case "event":
-			event := &Event{
-				ID:    msg["id"].(string),
-				Topic: msg["topic"].(string),
-			}
-			if data, ok := msg["data"]; ok {
-				event.Data, _ = json.Marshal(data)
-			}
-			if ts, ok := msg["timestamp"].(string); ok {
-				event.Timestamp, _ = time.Parse(time.RFC3339, ts)
-			}
-			if attempt, ok := msg["attempt"].(float64); ok {
-				event.Attempt = int(attempt)
-			}
+			event := parseEvent(msg)
 
 			select {
 			case s.events <- event:
@@ -293,21 +560,71 @@ func (s *Subscription) readPump() {
 			}
 
 		case "subscribed":
-			// Subscription confirmed, continue
+			// A resume cursor's token, to reconnect with before any ack
+			if opts, ok := msg["options"].(map[string]any); ok {
+				if token, ok := opts["resume_token"].(string); ok && token != "" {
+					s.setResumeToken(token)
+				}
+			}
+
+		case "closing":
+			serverClose = closedError(msg)
+
+		case "acked":
+			if token, ok := msg["resume_token"].(string); ok && token != "" {
+				s.setResumeToken(token)
+			}
+
+		case "display_config":
+			var frame struct {
+				Configs []DisplayConfig `json:"configs"`
+			}
+			if raw, err := json.Marshal(msg); err == nil && json.Unmarshal(raw, &frame) == nil {
+				select {
+				case s.displays <- frame.Configs:
+				default:
+				}
+			}
 
 		case "error":
 			errMsg := "unknown error"
 			if m, ok := msg["message"].(string); ok {
 				errMsg = m
 			}
+			code, _ := msg["code"].(string)
 			select {
-			case s.errors <- &APIError{Message: errMsg}:
+			case s.errors <- &APIError{Message: errMsg, Code: code}:
 			default:
 			}
 		}
 	}
 }
 
+// parseEvent builds an Event from an "event" frame.
+func parseEvent(msg map[string]any) *Event {
+	event := &Event{}
+	event.ID, _ = msg["id"].(string)
+	event.Topic, _ = msg["topic"].(string)
+	if data, ok := msg["data"]; ok {
+		event.Data, _ = json.Marshal(data)
+	}
+	if ts, ok := msg["timestamp"].(string); ok {
+		event.Timestamp, _ = time.Parse(time.RFC3339, ts)
+	}
+	if attempt, ok := msg["attempt"].(float64); ok {
+		event.Attempt = int(attempt)
+	}
+	if seq, ok := msg["stream_seq"].(float64); ok {
+		event.StreamSeq = uint64(seq)
+	}
+	if seq, ok := msg["consumer_seq"].(float64); ok {
+		event.ConsumerSeq = uint64(seq)
+	}
+	event.Traceparent, _ = msg["traceparent"].(string)
+	event.AckDeadline, _ = msg["ack_deadline"].(string)
+	return event
+}
+
 func (s *Subscription) writePump() {
 	ticker := time.NewTicker(pingPeriod)
 	defer ticker.Stop()
@@ -351,6 +668,13 @@ func (s *Subscription) Events() <-chan *Event {
 	return s.events
 }
 
+// DisplayConfigs returns the channel of display config pushes, each the
+// full current set for the subscription. Only used with
+// SubscribeOptions.DisplayConfig.
+func (s *Subscription) DisplayConfigs() <-chan []DisplayConfig {
+	return s.displays
+}
+
 // Errors returns the channel of errors.
 // Errors are non-fatal; the subscription will attempt to reconnect.
 func (s *Subscription) Errors() <-chan error {
@@ -398,6 +722,91 @@ func (s *Subscription) Nack(eventID string, retryIn string) error {
 	})
 }
 
+// AckMany acknowledges multiple events in a single frame.
+func (s *Subscription) AckMany(eventIDs []string) error {
+	s.connMu.RLock()
+	conn := s.conn
+	s.connMu.RUnlock()
+
+	if conn == nil {
+		return &ConnectionError{Err: ErrNotConnected}
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteJSON(map[string]any{
+		"action": "ack",
+		"ids":    eventIDs,
+	})
+}
+
+// NackMany negative-acknowledges multiple events in a single frame.
+func (s *Subscription) NackMany(eventIDs []string, retryIn string) error {
+	s.connMu.RLock()
+	conn := s.conn
+	s.connMu.RUnlock()
+
+	if conn == nil {
+		return &ConnectionError{Err: ErrNotConnected}
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteJSON(map[string]any{
+		"action":   "nack",
+		"ids":      eventIDs,
+		"retry_in": retryIn,
+	})
+}
+
+// InProgress tells the server the event is still being worked on, resetting
+// its ack deadline. Long-running handlers call it periodically to avoid
+// redelivery before they Ack.
+func (s *Subscription) InProgress(eventID string) error {
+	s.connMu.RLock()
+	conn := s.conn
+	s.connMu.RUnlock()
+
+	if conn == nil {
+		return &ConnectionError{Err: ErrNotConnected}
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteJSON(map[string]string{
+		"action": "in_progress",
+		"id":     eventID,
+	})
+}
+
+// Term stops redelivery of an event that can never be processed. Unlike
+// Nack, the event is not retried; reason is recorded with the delivery.
+func (s *Subscription) Term(eventID, reason string) error {
+	s.connMu.RLock()
+	conn := s.conn
+	s.connMu.RUnlock()
+
+	if conn == nil {
+		return &ConnectionError{Err: ErrNotConnected}
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteJSON(map[string]string{
+		"action": "term",
+		"id":     eventID,
+		"reason": reason,
+	})
+}
+
 // Close closes the subscription.
 func (s *Subscription) Close() error {
 	s.closeMu.Lock()
@@ -420,6 +829,23 @@ func (s *Subscription) Close() error {
 	return nil
 }
 
+// ResumeToken returns the token from the server's latest ack confirmation,
+// or of the resume cursor subscribed to, or "" before either. Passing it as
+// ResumeToken with From "resume" on a later Subscribe, even from another
+// process, continues right after the latest acked event. The subscription
+// does so itself when it reconnects.
+func (s *Subscription) ResumeToken() string {
+	s.resumeMu.Lock()
+	defer s.resumeMu.Unlock()
+	return s.resumeToken
+}
+
+func (s *Subscription) setResumeToken(token string) {
+	s.resumeMu.Lock()
+	s.resumeToken = token
+	s.resumeMu.Unlock()
+}
+
 // IsConnected returns true if the subscription is currently connected.
 func (s *Subscription) IsConnected() bool {
 	s.connMu.RLock()
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -483,6 +484,177 @@ func TestSubscribe_ErrorChannel(t *testing.T) {
 	}
 }
 
+func TestSubscribe_ClosedByServer(t *testing.T) {
+	var mu sync.Mutex
+	connects := 0
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		mu.Lock()
+		connects++
+		mu.Unlock()
+
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]any{"type": "subscribed"})
+		conn.WriteJSON(map[string]any{
+			"type":      "closing",
+			"reason":    "kicked",
+			"code":      4003,
+			"message":   "API key revoked",
+			"reconnect": false,
+		})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4003, "kicked"))
+		conn.ReadMessage()
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	sub, err := client.Subscribe(context.Background(), []string{"test-topic"}, SubscribeOptions{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	select {
+	case err := <-sub.Errors():
+		closed, ok := err.(*ClosedError)
+		if !ok {
+			t.Fatalf("Expected ClosedError, got %T: %v", err, err)
+		}
+		if closed.Reason != CloseReasonKicked || closed.Code != 4003 || closed.Reconnect {
+			t.Errorf("Unexpected close: %+v", closed)
+		}
+		if closed.Message != "API key revoked" {
+			t.Errorf("Expected message 'API key revoked', got '%s'", closed.Message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for close")
+	}
+
+	// A kicked subscription must not reconnect
+	time.Sleep(300 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if connects != 1 {
+		t.Errorf("Expected 1 connection, got %d", connects)
+	}
+}
+
+func TestSubscribe_WithCompression(t *testing.T) {
+	compressing := websocket.Upgrader{EnableCompression: true}
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{"offered", []Option{WithCompression()}, true},
+		{"default", nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			offered := make(chan bool, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				offered <- strings.Contains(r.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+				conn, err := compressing.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				var msg map[string]any
+				if err := conn.ReadJSON(&msg); err != nil {
+					return
+				}
+				conn.WriteJSON(map[string]any{"type": "subscribed"})
+				conn.WriteJSON(map[string]any{
+					"type":      "event",
+					"id":        "evt_123",
+					"topic":     "test-topic",
+					"data":      map[string]any{"message": strings.Repeat("hello ", 50)},
+					"timestamp": time.Now().Format(time.RFC3339),
+				})
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}))
+			defer server.Close()
+
+			client := New("test-api-key", append(tc.opts, WithServer(server.URL))...)
+			sub, err := client.Subscribe(context.Background(), []string{"test-topic"}, SubscribeOptions{AutoAck: true})
+			if err != nil {
+				t.Fatalf("Subscribe failed: %v", err)
+			}
+			defer sub.Close()
+
+			if got := <-offered; got != tc.want {
+				t.Errorf("permessage-deflate offered = %v, want %v", got, tc.want)
+			}
+			select {
+			case event := <-sub.Events():
+				if event.ID != "evt_123" {
+					t.Errorf("Expected event evt_123, got %s", event.ID)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timeout waiting for event")
+			}
+		})
+	}
+}
+
+func TestSubscribe_DisplayConfig(t *testing.T) {
+	options := make(chan map[string]any, 1)
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		opts, _ := msg["options"].(map[string]any)
+		options <- opts
+
+		conn.WriteJSON(map[string]any{"type": "subscribed"})
+		conn.WriteJSON(map[string]any{
+			"type": "display_config",
+			"configs": []map[string]any{{
+				"schema":        "order",
+				"topic_pattern": "orders.*",
+				"display":       map[string]any{"template": "{{.data.id}}"},
+			}},
+		})
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	sub, err := client.Subscribe(context.Background(), []string{"orders.*"}, SubscribeOptions{DisplayConfig: true})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	if opts := <-options; opts["display_config"] != true {
+		t.Errorf("expected display_config option, got %v", opts)
+	}
+
+	select {
+	case configs := <-sub.DisplayConfigs():
+		if len(configs) != 1 || configs[0].Schema != "order" || configs[0].TopicPattern != "orders.*" {
+			t.Fatalf("unexpected configs %+v", configs)
+		}
+		if string(configs[0].Display) != `{"template":"{{.data.id}}"}` {
+			t.Errorf("display = %s", configs[0].Display)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for display config")
+	}
+}
+
 func TestSubscribe_MultipleTopics(t *testing.T) {
 	var receivedTopics []string
 	var mu sync.Mutex
@@ -604,3 +776,611 @@ func TestSubscribe_AckNotConnected(t *testing.T) {
 		t.Errorf("Expected ErrNotConnected, got %v", connErr.Err)
 	}
 }
+
+func TestSubscribe_AckMany(t *testing.T) {
+	frames := make(chan map[string]any, 10)
+
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		// Read subscribe message
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		// Send subscribed confirmation
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+
+		// Record every frame after subscribe
+		for {
+			var frame map[string]any
+			if err := conn.ReadJSON(&frame); err != nil {
+				return
+			}
+			frames <- frame
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	ctx := context.Background()
+
+	sub, err := client.Subscribe(ctx, []string{"test-topic"}, SubscribeOptions{AutoAck: false})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	ids := []string{"evt-1", "evt-2", "evt-3"}
+	if err := sub.AckMany(ids); err != nil {
+		t.Fatalf("AckMany failed: %v", err)
+	}
+
+	select {
+	case frame := <-frames:
+		if frame["action"] != "ack" {
+			t.Errorf("Expected action 'ack', got %v", frame["action"])
+		}
+		got, ok := frame["ids"].([]any)
+		if !ok || len(got) != len(ids) {
+			t.Fatalf("Expected %d ids in frame, got %v", len(ids), frame["ids"])
+		}
+		for i, id := range ids {
+			if got[i] != id {
+				t.Errorf("Expected id '%s' at index %d, got '%v'", id, i, got[i])
+			}
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for ack frame")
+	}
+
+	// All ids must travel in a single frame
+	select {
+	case frame := <-frames:
+		t.Errorf("Expected a single frame, got extra: %v", frame)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSubscribe_NackMany(t *testing.T) {
+	frames := make(chan map[string]any, 10)
+
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		// Read subscribe message
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		// Send subscribed confirmation
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+
+		for {
+			var frame map[string]any
+			if err := conn.ReadJSON(&frame); err != nil {
+				return
+			}
+			frames <- frame
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	ctx := context.Background()
+
+	sub, err := client.Subscribe(ctx, []string{"test-topic"}, SubscribeOptions{AutoAck: false})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	if err := sub.NackMany([]string{"evt-1", "evt-2"}, "30s"); err != nil {
+		t.Fatalf("NackMany failed: %v", err)
+	}
+
+	select {
+	case frame := <-frames:
+		if frame["action"] != "nack" {
+			t.Errorf("Expected action 'nack', got %v", frame["action"])
+		}
+		if got, ok := frame["ids"].([]any); !ok || len(got) != 2 {
+			t.Errorf("Expected 2 ids in frame, got %v", frame["ids"])
+		}
+		if frame["retry_in"] != "30s" {
+			t.Errorf("Expected retry_in '30s', got %v", frame["retry_in"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for nack frame")
+	}
+}
+
+func TestSubscribe_InProgressAndTerm(t *testing.T) {
+	frames := make(chan map[string]any, 10)
+
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		// Read subscribe message
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		// Send subscribed confirmation
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+
+		sendEvent := func(attempt int) {
+			conn.WriteJSON(map[string]any{
+				"type":         "event",
+				"id":           "evt-1",
+				"topic":        "test-topic",
+				"data":         map[string]string{},
+				"timestamp":    time.Now().Format(time.RFC3339),
+				"attempt":      attempt,
+				"stream_seq":   42,
+				"consumer_seq": attempt,
+			})
+		}
+		sendEvent(1)
+
+		// Like JetStream: redeliver until the event is acked or terminated
+		attempt := 1
+		for {
+			var frame map[string]any
+			if err := conn.ReadJSON(&frame); err != nil {
+				return
+			}
+			frames <- frame
+			if frame["action"] == "nack" {
+				attempt++
+				sendEvent(attempt)
+			}
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	sub, err := client.Subscribe(context.Background(), []string{"test-topic"}, SubscribeOptions{AutoAck: false})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	var event *Event
+	select {
+	case event = <-sub.Events():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for event")
+	}
+	if event.StreamSeq != 42 || event.ConsumerSeq != 1 {
+		t.Errorf("Expected stream_seq 42 and consumer_seq 1, got %d and %d", event.StreamSeq, event.ConsumerSeq)
+	}
+
+	if err := sub.InProgress(event.ID); err != nil {
+		t.Fatalf("InProgress failed: %v", err)
+	}
+	select {
+	case frame := <-frames:
+		if frame["action"] != "in_progress" || frame["id"] != "evt-1" {
+			t.Errorf("Expected in_progress frame for evt-1, got %v", frame)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for in_progress frame")
+	}
+
+	if err := sub.Term(event.ID, "unprocessable"); err != nil {
+		t.Fatalf("Term failed: %v", err)
+	}
+	select {
+	case frame := <-frames:
+		if frame["action"] != "term" || frame["id"] != "evt-1" || frame["reason"] != "unprocessable" {
+			t.Errorf("Expected term frame for evt-1, got %v", frame)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for term frame")
+	}
+
+	// Terminated events are not redelivered
+	select {
+	case event := <-sub.Events():
+		t.Errorf("Expected no redelivery after term, got %v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSubscribe_ReconnectBackoff(t *testing.T) {
+	var mu sync.Mutex
+	var connectedAt []time.Time
+
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		mu.Lock()
+		connectedAt = append(connectedAt, time.Now())
+		first := len(connectedAt) == 1
+		mu.Unlock()
+
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+
+		if first {
+			conn.Close()
+			return
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL), WithReconnectBackoff(20*time.Millisecond, 50*time.Millisecond, 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub, err := client.Subscribe(ctx, []string{"test-topic"}, SubscribeOptions{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	// The default backoff waits 1s before the first reconnect
+	time.Sleep(500 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(connectedAt) < 2 {
+		t.Fatalf("Expected a reconnect within 500ms, got %d connections", len(connectedAt))
+	}
+}
+
+func TestSubscribe_ReconnectBackoffMax(t *testing.T) {
+	const rejections = 6
+
+	var mu sync.Mutex
+	var attemptsAt []time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attemptsAt = append(attemptsAt, time.Now())
+		n := len(attemptsAt)
+		mu.Unlock()
+
+		// Reject the reconnects after the first connection drops
+		if n > 1 && n <= 1+rejections {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+		if n == 1 {
+			return
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	maxDelay := 40 * time.Millisecond
+	client := New("test-api-key", WithServer(server.URL), WithReconnectBackoff(20*time.Millisecond, maxDelay, 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub, err := client.Subscribe(ctx, []string{"test-topic"}, SubscribeOptions{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	attempts := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(attemptsAt)
+	}
+
+	// Uncapped, the backoff would reach 640ms by the last rejection
+	deadline := time.Now().Add(2 * time.Second)
+	for !sub.IsConnected() || attempts() < 2+rejections {
+		if time.Now().After(deadline) {
+			t.Fatal("Subscription did not reconnect in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 2; i < len(attemptsAt); i++ {
+		if gap := attemptsAt[i].Sub(attemptsAt[i-1]); gap > maxDelay+100*time.Millisecond {
+			t.Errorf("Attempt %d came %v after the previous one, want about %v at most", i, gap, maxDelay)
+		}
+	}
+}
+
+func TestBackoff_Wait(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := b.wait(time.Second); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("wait(1s) with jitter 0.5 = %v, want between 500ms and 1s", got)
+		}
+	}
+	if got := b.next(800 * time.Millisecond); got != time.Second {
+		t.Errorf("next(800ms) = %v, want the 1s cap", got)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	subscribeOptions := make(chan map[string]any, 1)
+
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		options, _ := msg["options"].(map[string]any)
+		subscribeOptions <- options
+
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+		for _, id := range []string{"evt_1", "evt_2"} {
+			conn.WriteJSON(map[string]any{
+				"type":      "event",
+				"id":        id,
+				"topic":     "orders.created",
+				"data":      map[string]any{"id": id},
+				"timestamp": time.Now().Format(time.RFC3339),
+			})
+		}
+		conn.WriteJSON(map[string]string{"type": "done", "reason": "caught_up"})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := client.Drain(ctx, []string{"orders.*"})
+	if err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != "evt_1" || events[1].ID != "evt_2" {
+		t.Fatalf("Expected evt_1 and evt_2, got %+v", events)
+	}
+	if options := <-subscribeOptions; options["until"] != "caught_up" || options["from"] != "beginning" {
+		t.Errorf("Expected until caught_up from beginning, got %v", options)
+	}
+}
+
+func TestDrain_Error(t *testing.T) {
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "error", "code": "INVALID_OPTIONS", "message": "bad until"})
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	_, err := client.Drain(context.Background(), []string{"orders.*"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "bad until" {
+		t.Fatalf("Expected APIError with server message, got %v", err)
+	}
+}
+
+func TestWaitForEvent(t *testing.T) {
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+		for _, status := range []string{"pending", "paid"} {
+			conn.WriteJSON(map[string]any{
+				"type":      "event",
+				"id":        "evt_" + status,
+				"topic":     "orders.updated",
+				"data":      map[string]string{"status": status},
+				"timestamp": time.Now().Format(time.RFC3339),
+			})
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event, err := client.WaitForEvent(ctx, []string{"orders.*"}, func(e *Event) bool {
+		var data map[string]string
+		json.Unmarshal(e.Data, &data)
+		return data["status"] == "paid"
+	})
+	if err != nil {
+		t.Fatalf("WaitForEvent failed: %v", err)
+	}
+	if event.ID != "evt_paid" {
+		t.Errorf("Expected evt_paid, got %s", event.ID)
+	}
+}
+
+func TestWaitForEvent_ContextTimeout(t *testing.T) {
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+		conn.WriteJSON(map[string]any{
+			"type":      "event",
+			"id":        "evt_1",
+			"topic":     "orders.created",
+			"data":      map[string]string{},
+			"timestamp": time.Now().Format(time.RFC3339),
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := client.WaitForEvent(ctx, []string{"orders.*"}, func(*Event) bool { return false })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWaitForEvent_ClosedByServer(t *testing.T) {
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+		conn.WriteJSON(map[string]any{
+			"type":      "closing",
+			"reason":    "kicked",
+			"code":      4003,
+			"reconnect": false,
+		})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4003, "kicked"))
+		conn.ReadMessage()
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.WaitForEvent(ctx, []string{"orders.*"}, nil)
+	var closed *ClosedError
+	if !errors.As(err, &closed) || closed.Reason != CloseReasonKicked {
+		t.Fatalf("Expected a kicked ClosedError, got %v", err)
+	}
+}
+
+func TestSubscribe_ResumeToken(t *testing.T) {
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "subscribed"})
+		for {
+			var ackMsg map[string]any
+			if err := conn.ReadJSON(&ackMsg); err != nil {
+				return
+			}
+			if ackMsg["action"] == "ack" {
+				conn.WriteJSON(map[string]any{"type": "acked", "ids": []string{"evt-1"}, "resume_token": "rt_token"})
+			}
+		}
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL))
+	sub, err := client.Subscribe(context.Background(), []string{"test-topic"}, SubscribeOptions{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	if token := sub.ResumeToken(); token != "" {
+		t.Errorf("expected no token before an ack, got %q", token)
+	}
+	if err := sub.Ack("evt-1"); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sub.ResumeToken() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if token := sub.ResumeToken(); token != "rt_token" {
+		t.Errorf("expected rt_token, got %q", token)
+	}
+}
+
+func TestSubscribe_ReconnectResumes(t *testing.T) {
+	subscribes := make(chan map[string]any, 4)
+	server := mockWSServer(t, func(conn *websocket.Conn) {
+		var msg map[string]any
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		options, _ := msg["options"].(map[string]any)
+		subscribes <- options
+		if options["from"] == "resume" {
+			conn.WriteJSON(map[string]any{"type": "subscribed", "options": map[string]any{"from": "resume", "resume_token": options["resume_token"]}})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+
+		// First connection: one event, acked, then the connection drops
+		conn.WriteJSON(map[string]any{"type": "subscribed"})
+		conn.WriteJSON(map[string]any{"type": "event", "id": "evt-1", "topic": "orders.created", "data": map[string]any{}})
+		var ack map[string]any
+		if err := conn.ReadJSON(&ack); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]any{"type": "acked", "ids": []string{"evt-1"}, "resume_token": "rt_after_evt_1"})
+		time.Sleep(50 * time.Millisecond)
+		conn.Close()
+	})
+	defer server.Close()
+
+	client := New("test-api-key", WithServer(server.URL), WithReconnectBackoff(20*time.Millisecond, 50*time.Millisecond, 0))
+	sub, err := client.Subscribe(context.Background(), []string{"orders.*"}, SubscribeOptions{From: "beginning"})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	defer sub.Close()
+
+	select {
+	case event := <-sub.Events():
+		if err := sub.Ack(event.ID); err != nil {
+			t.Fatalf("Ack failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+
+	var options map[string]any
+	for range 2 {
+		select {
+		case options = <-subscribes:
+		case <-time.After(2 * time.Second):
+			t.Fatal("no resubscribe after the connection dropped")
+		}
+	}
+	if options["from"] != "resume" || options["resume_token"] != "rt_after_evt_1" {
+		t.Errorf("expected the reconnect to resume from the acked token, got %v", options)
+	}
+}
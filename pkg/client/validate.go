@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// DefaultSchemaCacheTTL is how long WithSchemaValidation reuses a topic's
+// schema before fetching it again.
+const DefaultSchemaCacheTTL = 5 * time.Minute
+
+// schemaFetchRetryDelay is how long a failed schema fetch is remembered, so
+// an unreachable registry doesn't cost every Emit a round trip.
+const schemaFetchRetryDelay = 30 * time.Second
+
+// SchemaValidationError is returned by Emit when client-side schema
+// validation rejects the data. The event is not sent.
+type SchemaValidationError struct {
+	Topic   string
+	Schema  string
+	Version string
+	Errors  []ValidationError
+}
+
+func (e *SchemaValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, ve := range e.Errors {
+		msgs[i] = ve.Field + ": " + ve.Message
+	}
+	return fmt.Sprintf("data for %q does not match schema %s@%s: %s", e.Topic, e.Schema, e.Version, strings.Join(msgs, "; "))
+}
+
+// WithSchemaValidation makes Emit validate data against the latest version
+// of the topic's schema before sending it, so invalid events fail with a
+// *SchemaValidationError without a round trip. Schemas are fetched once per
+// topic and kept for ttl (DefaultSchemaCacheTTL if non-positive). Only
+// versions the server would reject invalid data for (strict mode, on_invalid
+// reject) are checked; other events are sent for the server to log or
+// dead-letter as configured. If a schema can't be fetched the event is sent
+// for the server to validate, and the fetch isn't retried for
+// schemaFetchRetryDelay.
+func WithSchemaValidation(ttl time.Duration) Option {
+	return func(c *Client) {
+		if ttl <= 0 {
+			ttl = DefaultSchemaCacheTTL
+		}
+		c.schemas = &schemaCache{ttl: ttl, topics: make(map[string]*topicSchema)}
+	}
+}
+
+// schemaCache holds the compiled schema of each topic emitted to.
+type schemaCache struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	topics map[string]*topicSchema
+}
+
+// topicSchema is a topic's schema, compiled. A nil compiled means the topic
+// has nothing to validate against locally.
+type topicSchema struct {
+	name     string
+	version  string
+	compiled *gojsonschema.Schema
+	expires  time.Time
+}
+
+// validateLocally checks data against the topic's schema.
+func (c *Client) validateLocally(topic string, data json.RawMessage) error {
+	ts := c.topicSchema(topic)
+	if ts.compiled == nil {
+		return nil
+	}
+
+	result, err := ts.compiled.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		// Not JSON the validator can read; let the server decide
+		return nil
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	verr := &SchemaValidationError{Topic: topic, Schema: ts.name, Version: ts.version}
+	for _, re := range result.Errors() {
+		verr.Errors = append(verr.Errors, ValidationError{
+			Field:   re.Field(),
+			Message: re.Description(),
+			Type:    re.Type(),
+		})
+	}
+	return verr
+}
+
+// topicSchema returns the topic's cached schema, fetching it when missing
+// or expired. A failed fetch is cached as nothing to validate against.
+func (c *Client) topicSchema(topic string) *topicSchema {
+	c.schemas.mu.Lock()
+	ts, ok := c.schemas.topics[topic]
+	c.schemas.mu.Unlock()
+	if ok && time.Now().Before(ts.expires) {
+		return ts
+	}
+
+	ts, err := c.fetchTopicSchema(topic)
+	if err != nil {
+		ts = &topicSchema{expires: time.Now().Add(min(schemaFetchRetryDelay, c.schemas.ttl))}
+	} else {
+		ts.expires = time.Now().Add(c.schemas.ttl)
+	}
+
+	c.schemas.mu.Lock()
+	c.schemas.topics[topic] = ts
+	c.schemas.mu.Unlock()
+	return ts
+}
+
+func (c *Client) fetchTopicSchema(topic string) (*topicSchema, error) {
+	ts := &topicSchema{}
+
+	s, err := c.SchemaForTopic(topic)
+	if err != nil {
+		return nil, err
+	}
+	// Only the server's reject policy turns invalid data into an error
+	if s == nil || s.LatestVersion == nil || s.LatestVersion.ValidationMode != "strict" || s.LatestVersion.OnInvalid != "reject" {
+		return ts, nil
+	}
+
+	// notif:// refs only resolve on the server; fetch them inlined
+	if bytes.Contains(s.LatestVersion.Schema, []byte("notif://")) {
+		if s, err = c.SchemaGetBundled(s.Name); err != nil {
+			return nil, err
+		}
+		if s.LatestVersion == nil {
+			return ts, nil
+		}
+	}
+
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(s.LatestVersion.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("compile schema %s: %w", s.Name, err)
+	}
+	ts.name = s.Name
+	ts.version = s.LatestVersion.Version
+	ts.compiled = compiled
+	return ts, nil
+}
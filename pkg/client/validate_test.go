@@ -0,0 +1,132 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func mockSchemaServer(t *testing.T, schemaFetches, emits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/schemas/for-topic/{topic}", func(w http.ResponseWriter, r *http.Request) {
+		schemaFetches.Add(1)
+		onInvalid := "reject"
+		switch r.PathValue("topic") {
+		case "orders.created":
+		case "orders.logged":
+			onInvalid = "log"
+		case "orders.broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(Schema{
+			Name: "order",
+			LatestVersion: &SchemaVersion{
+				Version:        "1.0.0",
+				ValidationMode: "strict",
+				OnInvalid:      onInvalid,
+				Schema:         json.RawMessage(`{"type":"object","required":["id"],"properties":{"id":{"type":"string"}}}`),
+			},
+		})
+	})
+	mux.HandleFunc("POST /api/v1/emit", func(w http.ResponseWriter, r *http.Request) {
+		emits.Add(1)
+		json.NewEncoder(w).Encode(EmitResponse{ID: "evt_1", Topic: "orders.created"})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEmit_SchemaValidation(t *testing.T) {
+	var schemaFetches, emits atomic.Int32
+	server := mockSchemaServer(t, &schemaFetches, &emits)
+	c := New("test-api-key", WithServer(server.URL), WithSchemaValidation(0))
+
+	_, err := c.Emit("orders.created", json.RawMessage(`{"id":42}`))
+	var verr *SchemaValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("invalid data: err = %v, want *SchemaValidationError", err)
+	}
+	if verr.Schema != "order" || verr.Version != "1.0.0" || len(verr.Errors) != 1 || verr.Errors[0].Field != "id" {
+		t.Errorf("validation error = %+v", verr)
+	}
+	if n := emits.Load(); n != 0 {
+		t.Errorf("invalid data sent %d times", n)
+	}
+
+	if _, err := c.Emit("orders.created", json.RawMessage(`{"id":"ord_1"}`)); err != nil {
+		t.Fatalf("valid data: %v", err)
+	}
+	if _, err := c.Emit("users.signup", json.RawMessage(`{"anything":true}`)); err != nil {
+		t.Fatalf("topic without schema: %v", err)
+	}
+	if _, err := c.Emit("users.signup", json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := emits.Load(); n != 3 {
+		t.Errorf("emits = %d, want 3", n)
+	}
+	// One fetch per topic, missing schemas included
+	if n := schemaFetches.Load(); n != 2 {
+		t.Errorf("schema fetches = %d, want 2", n)
+	}
+}
+
+func TestEmit_SchemaValidationOnlyRejectsUnderRejectPolicy(t *testing.T) {
+	var schemaFetches, emits atomic.Int32
+	server := mockSchemaServer(t, &schemaFetches, &emits)
+	c := New("test-api-key", WithServer(server.URL), WithSchemaValidation(0))
+
+	// The server logs invalid data for this schema instead of rejecting it
+	if _, err := c.Emit("orders.logged", json.RawMessage(`{"id":42}`)); err != nil {
+		t.Fatalf("on_invalid log: %v", err)
+	}
+	if n := emits.Load(); n != 1 {
+		t.Errorf("emits = %d, want 1", n)
+	}
+}
+
+func TestEmit_SchemaValidationCachesFetchFailures(t *testing.T) {
+	var schemaFetches, emits atomic.Int32
+	server := mockSchemaServer(t, &schemaFetches, &emits)
+	c := New("test-api-key", WithServer(server.URL), WithSchemaValidation(0))
+
+	for range 3 {
+		if _, err := c.Emit("orders.broken", json.RawMessage(`{"id":42}`)); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+	if n := schemaFetches.Load(); n != 1 {
+		t.Errorf("schema fetches = %d, want 1", n)
+	}
+
+	// The failure is retried after the delay
+	c.schemas.topics["orders.broken"].expires = time.Now().Add(-time.Second)
+	c.Emit("orders.broken", json.RawMessage(`{"id":42}`))
+	if n := schemaFetches.Load(); n != 2 {
+		t.Errorf("schema fetches after expiry = %d, want 2", n)
+	}
+}
+
+func TestEmit_SchemaValidationOff(t *testing.T) {
+	var schemaFetches, emits atomic.Int32
+	server := mockSchemaServer(t, &schemaFetches, &emits)
+	c := New("test-api-key", WithServer(server.URL))
+
+	if _, err := c.Emit("orders.created", json.RawMessage(`{"id":42}`)); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	if schemaFetches.Load() != 0 || emits.Load() != 1 {
+		t.Errorf("fetches = %d, emits = %d, want 0 and 1", schemaFetches.Load(), emits.Load())
+	}
+}
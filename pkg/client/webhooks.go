@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -16,6 +18,42 @@ type Webhook struct {
 	Secret    string   `json:"secret,omitempty"`
 	Enabled   bool     `json:"enabled"`
 	CreatedAt string   `json:"created_at"`
+
+	// RetryBudget bounds how long a failing delivery is retried (e.g. "6h0m0s")
+	// before it moves to the DLQ.
+	RetryBudget string `json:"retry_budget,omitempty"`
+
+	// BodyEncoding is how payloads are serialized ("json" or "form") and
+	// ContentType the header sent with them.
+	ContentType  string `json:"content_type,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+
+	// PayloadMode is "envelope" or "raw" (only the event data as the body,
+	// with id, topic and timestamp in X-Notif-* headers).
+	PayloadMode string `json:"payload_mode,omitempty"`
+
+	// Ordered webhooks receive events one at a time, in emit order.
+	Ordered bool `json:"ordered,omitempty"`
+
+	// Headers are the static headers sent with every delivery; secret
+	// values (e.g. Authorization) read as "[redacted]".
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// TenantField selects the signing secret from the event data; the
+	// mapped tenants are listed in TenantSecrets with secrets redacted.
+	TenantField   string            `json:"tenant_field,omitempty"`
+	TenantSecrets map[string]string `json:"tenant_secrets,omitempty"`
+
+	// RetryPolicy is set when the webhook overrides the default retry
+	// schedule.
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+
+	// FallbackURLs are tried in order when URL fails a delivery attempt.
+	FallbackURLs []string `json:"fallback_urls,omitempty"`
+
+	// PreviousSecretExpiresAt is set after a rotation: until then, deliveries
+	// also carry X-Notif-Signature-Previous signed with the old secret.
+	PreviousSecretExpiresAt string `json:"previous_secret_expires_at,omitempty"`
 }
 
 // WebhookListResponse is the response from listing webhooks.
@@ -43,20 +81,74 @@ type WebhookDelivery struct {
 type WebhookDeliveriesResponse struct {
 	Deliveries []WebhookDelivery `json:"deliveries"`
 	Count      int               `json:"count"`
+	// NextCursor is set when the page is full; pass it as Before to fetch
+	// the next page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// WebhookDeliveriesOptions pages and filters a deliveries listing.
+type WebhookDeliveriesOptions struct {
+	Limit int
+	// Before resumes listing after this delivery ID (a NextCursor).
+	Before string
+	// Status is pending, success or failed.
+	Status string
 }
 
 // CreateWebhookRequest is the request to create a webhook.
 type CreateWebhookRequest struct {
-	URL    string   `json:"url"`
-	Topics []string `json:"topics"`
+	URL         string   `json:"url"`
+	Topics      []string `json:"topics"`
+	RetryBudget string   `json:"retry_budget,omitempty"` // e.g. "6h"; empty uses the server default
+
+	// BodyEncoding is "json" (default) or "form"; ContentType defaults to
+	// the encoding's standard type.
+	ContentType  string `json:"content_type,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+
+	// PayloadMode is "envelope" (default) or "raw".
+	PayloadMode string `json:"payload_mode,omitempty"`
+
+	// Ordered delivers events one at a time in emit order; a failing event
+	// holds back later ones while it is retried.
+	Ordered bool `json:"ordered,omitempty"`
+
+	// Headers are static headers added to every delivery, e.g.
+	// Authorization. X-Notif-* and Content-Type are reserved.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// TenantField is a dot path into the event data (e.g. "tenant"); events
+	// whose tenant is in TenantSecrets are signed with that secret.
+	TenantField   string            `json:"tenant_field,omitempty"`
+	TenantSecrets map[string]string `json:"tenant_secrets,omitempty"`
+
+	// RetryPolicy overrides the default retry count and backoff.
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+
+	// FallbackURLs (at most 3) are tried in order, within the same attempt,
+	// when URL fails.
+	FallbackURLs []string `json:"fallback_urls,omitempty"`
+}
+
+// RetryPolicy is a webhook's retry schedule. MaxRetries (1-20) caps the
+// attempts and Backoff lists the delay before each retry, e.g. "30s"; the
+// last delay repeats. Unset fields keep the server defaults.
+type RetryPolicy struct {
+	MaxRetries int      `json:"max_retries,omitempty"`
+	Backoff    []string `json:"backoff,omitempty"`
 }
 
 // WebhookCreate creates a new webhook.
 func (c *Client) WebhookCreate(url string, topics []string) (*Webhook, error) {
-	reqBody, _ := json.Marshal(CreateWebhookRequest{
+	return c.WebhookCreateWithOptions(CreateWebhookRequest{
 		URL:    url,
 		Topics: topics,
 	})
+}
+
+// WebhookCreateWithOptions creates a new webhook from a full request.
+func (c *Client) WebhookCreateWithOptions(createReq CreateWebhookRequest) (*Webhook, error) {
+	reqBody, _ := json.Marshal(createReq)
 
 	req, err := http.NewRequest("POST", c.server+"/api/v1/webhooks", bytes.NewReader(reqBody))
 	if err != nil {
@@ -152,6 +244,30 @@ type UpdateWebhookRequest struct {
 	URL     string   `json:"url,omitempty"`
 	Topics  []string `json:"topics,omitempty"`
 	Enabled *bool    `json:"enabled,omitempty"`
+
+	RetryBudget string `json:"retry_budget,omitempty"`
+
+	ContentType  string `json:"content_type,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+	PayloadMode  string `json:"payload_mode,omitempty"`
+	Ordered      *bool  `json:"ordered,omitempty"`
+
+	// Headers replaces the static headers when non-nil; an empty map clears
+	// them and "[redacted]" keeps a header's stored value.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// TenantSecrets replaces the tenant map the same way; setting
+	// TenantField to "" turns per-tenant signing off.
+	TenantField   *string           `json:"tenant_field,omitempty"`
+	TenantSecrets map[string]string `json:"tenant_secrets,omitempty"`
+
+	// RetryPolicy replaces the retry policy when non-nil; an empty policy
+	// restores the defaults.
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+
+	// FallbackURLs replaces the fallback URLs when non-nil; an empty list
+	// clears them.
+	FallbackURLs *[]string `json:"fallback_urls,omitempty"`
 }
 
 // WebhookUpdate updates a webhook.
@@ -204,9 +320,64 @@ func (c *Client) WebhookDelete(id string) error {
 	return nil
 }
 
+// WebhookRotateSecret replaces the webhook's signing secret. The old secret
+// keeps verifying for gracePeriod (e.g. "24h"); empty uses the server default.
+func (c *Client) WebhookRotateSecret(id string, gracePeriod string) (*Webhook, error) {
+	reqBody, _ := json.Marshal(map[string]string{"grace_period": gracePeriod})
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/v1/webhooks/%s/rotate-secret", c.server, id), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to rotate secret"
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	var webhook Webhook
+	if err := json.NewDecoder(resp.Body).Decode(&webhook); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
 // WebhookDeliveries lists recent deliveries for a webhook.
 func (c *Client) WebhookDeliveries(id string) (*WebhookDeliveriesResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/webhooks/%s/deliveries", c.server, id), nil)
+	return c.WebhookDeliveriesWithOptions(id, WebhookDeliveriesOptions{})
+}
+
+// WebhookDeliveriesWithOptions lists a webhook's deliveries, newest first.
+func (c *Client) WebhookDeliveriesWithOptions(id string, opts WebhookDeliveriesOptions) (*WebhookDeliveriesResponse, error) {
+	u, _ := url.Parse(fmt.Sprintf("%s/api/v1/webhooks/%s/deliveries", c.server, url.PathEscape(id)))
+	q := u.Query()
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Before != "" {
+		q.Set("before", opts.Before)
+	}
+	if opts.Status != "" {
+		q.Set("status", opts.Status)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +390,14 @@ func (c *Client) WebhookDeliveries(id string) (*WebhookDeliveriesResponse, error
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to get deliveries"}
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to get deliveries"
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
 	}
 
 	var result WebhookDeliveriesResponse
@@ -229,3 +407,32 @@ func (c *Client) WebhookDeliveries(id string) (*WebhookDeliveriesResponse, error
 
 	return &result, nil
 }
+
+// RetryWebhookDelivery re-queues a failed delivery to the webhook, with
+// attempts starting over. The event is not re-emitted.
+func (c *Client) RetryWebhookDelivery(webhookID, deliveryID string) error {
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/v1/webhooks/%s/deliveries/%s/retry", c.server, url.PathEscape(webhookID), url.PathEscape(deliveryID)), nil)
+	if err != nil {
+		return err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "failed to retry delivery"
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+
+	return nil
+}
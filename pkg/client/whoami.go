@@ -0,0 +1,58 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Identity describes who the client is authenticated as.
+type Identity struct {
+	AuthType    string   `json:"auth_type"` // "api_key" or "session"
+	OrgID       string   `json:"org_id"`
+	ProjectID   string   `json:"project_id"`
+	ProjectName string   `json:"project_name,omitempty"`
+	Scopes      []string `json:"scopes"`
+
+	KeyID     string `json:"key_id,omitempty"`
+	KeyPrefix string `json:"key_prefix,omitempty"`
+	KeyName   string `json:"key_name,omitempty"`
+
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Whoami returns the identity behind the client's credentials.
+func (c *Client) Whoami() (*Identity, error) {
+	req, err := http.NewRequest("GET", c.server+"/api/v1/whoami", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error == "" {
+			errResp.Error = "invalid or missing API key"
+		}
+		return nil, &AuthError{Message: errResp.Error}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: "failed to get identity"}
+	}
+
+	var identity Identity
+	if err := json.NewDecoder(resp.Body).Decode(&identity); err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
@@ -2,7 +2,9 @@ package e2e
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -10,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	notifnats "github.com/filipexyz/notif/internal/nats"
 	"github.com/gorilla/websocket"
 )
 
@@ -356,6 +359,82 @@ func TestEmitAndReceive(t *testing.T) {
 			t.Errorf("expected order_id 12345, got %v", data["order_id"])
 		}
 	})
+
+	t.Run("emit over websocket is received by another subscriber", func(t *testing.T) {
+		sub, _, err := websocket.DefaultDialer.Dial(wsURL+"/ws?token="+TestAPIKey, nil)
+		if err != nil {
+			t.Fatalf("failed to connect subscriber: %v", err)
+		}
+		defer sub.Close()
+
+		if err := sub.WriteJSON(map[string]interface{}{
+			"action":  "subscribe",
+			"topics":  []string{"chat.*"},
+			"options": map[string]interface{}{"auto_ack": true},
+		}); err != nil {
+			t.Fatalf("failed to send subscribe: %v", err)
+		}
+		sub.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var subResp map[string]interface{}
+		if err := sub.ReadJSON(&subResp); err != nil || subResp["type"] != "subscribed" {
+			t.Fatalf("expected subscribed, got %v (err %v)", subResp, err)
+		}
+
+		emitter, _, err := websocket.DefaultDialer.Dial(wsURL+"/ws?token="+TestAPIKey, nil)
+		if err != nil {
+			t.Fatalf("failed to connect emitter: %v", err)
+		}
+		defer emitter.Close()
+
+		if err := emitter.WriteJSON(map[string]interface{}{
+			"action": "emit",
+			"topic":  "chat.message",
+			"data":   map[string]interface{}{"text": "hello"},
+		}); err != nil {
+			t.Fatalf("failed to send emit: %v", err)
+		}
+		emitter.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var emitted map[string]interface{}
+		if err := emitter.ReadJSON(&emitted); err != nil {
+			t.Fatalf("failed to read emitted response: %v", err)
+		}
+		if emitted["type"] != "emitted" || emitted["id"] == nil {
+			t.Fatalf("expected emitted frame with id, got %v", emitted)
+		}
+
+		sub.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var eventResp map[string]interface{}
+		if err := sub.ReadJSON(&eventResp); err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		if eventResp["type"] != "event" || eventResp["id"] != emitted["id"] {
+			t.Errorf("expected event %v, got %v", emitted["id"], eventResp)
+		}
+	})
+
+	t.Run("emit over websocket validates the topic", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+"/ws?token="+TestAPIKey, nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		if err := conn.WriteJSON(map[string]interface{}{
+			"action": "emit",
+			"topic":  "orders.*",
+			"data":   map[string]interface{}{},
+		}); err != nil {
+			t.Fatalf("failed to send emit: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var resp map[string]interface{}
+		if err := conn.ReadJSON(&resp); err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		if resp["type"] != "error" || resp["code"] != "INVALID_TOPIC" {
+			t.Errorf("expected INVALID_TOPIC error, got %v", resp)
+		}
+	})
 }
 
 func TestAckNack(t *testing.T) {
@@ -771,6 +850,99 @@ func TestDeadLetterQueue(t *testing.T) {
 		}
 	})
 
+	t.Run("DLQ filters narrow the listing", func(t *testing.T) {
+		seedDLQ(t, env)
+
+		tests := []struct {
+			query string
+			want  []string // event IDs
+		}{
+			{"topic=dlq-filter.a", []string{"dlq-filter-old", "dlq-filter-new"}},
+			{"topic=dlq-filter.a&older_than=1h", []string{"dlq-filter-old"}},
+			{"topic=dlq-filter.a&min_attempts=4", []string{"dlq-filter-new"}},
+			{"consumer_group=dlq-filter-billing", []string{"dlq-filter-old", "dlq-filter-other"}},
+			{"consumer_group=dlq-filter-billing&min_attempts=4", nil},
+		}
+		for _, tt := range tests {
+			req, _ := http.NewRequest("GET", env.ServerURL+"/api/v1/dlq?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+
+			var result struct {
+				Messages []struct {
+					EventID string `json:"event_id"`
+				} `json:"messages"`
+			}
+			json.NewDecoder(resp.Body).Decode(&result)
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("%s: expected status 200, got %d", tt.query, resp.StatusCode)
+			}
+			var got []string
+			for _, m := range result.Messages {
+				if strings.HasPrefix(m.EventID, "dlq-filter-") {
+					got = append(got, m.EventID)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("%s: got %v, want %v", tt.query, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("DLQ rejects invalid filters", func(t *testing.T) {
+		for _, query := range []string{"older_than=soon", "min_attempts=-1"} {
+			req, _ := http.NewRequest("GET", env.ServerURL+"/api/v1/dlq?"+query, nil)
+			req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("DLQ stats count dead-lettered messages", func(t *testing.T) {
+		seedDLQ(t, env)
+
+		req, _ := http.NewRequest("GET", env.ServerURL+"/api/v1/dlq/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+
+		var stats struct {
+			Total           int64            `json:"total"`
+			ByTopic         map[string]int64 `json:"by_topic"`
+			ByConsumerGroup map[string]int64 `json:"by_consumer_group"`
+		}
+		json.NewDecoder(resp.Body).Decode(&stats)
+
+		// Earlier subtests may have dead-lettered more messages
+		if stats.Total < 3 {
+			t.Errorf("expected total >= 3, got %d", stats.Total)
+		}
+		if stats.ByTopic["dlq-filter.a"] < 2 || stats.ByTopic["dlq-filter.b"] < 1 {
+			t.Errorf("unexpected by_topic: %v", stats.ByTopic)
+		}
+		if stats.ByConsumerGroup["dlq-filter-billing"] < 2 || stats.ByConsumerGroup["dlq-filter-email"] < 1 {
+			t.Errorf("unexpected by_consumer_group: %v", stats.ByConsumerGroup)
+		}
+	})
+
 	t.Run("DLQ requires authorization", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", env.ServerURL+"/api/v1/dlq", nil)
 
@@ -786,6 +958,37 @@ func TestDeadLetterQueue(t *testing.T) {
 	})
 }
 
+// seedDLQ dead-letters three messages for the DLQ filter tests, once per
+// test environment.
+func seedDLQ(t *testing.T, env *TestEnv) {
+	t.Helper()
+	ctx := context.Background()
+	reader, err := notifnats.NewDLQReader(env.NATS.JetStream())
+	if err != nil {
+		t.Fatalf("dlq reader: %v", err)
+	}
+	entries, _ := reader.List(ctx, TestOrgID, TestProjectID, "dlq-filter.a", 1)
+	if len(entries) > 0 {
+		return
+	}
+
+	publisher := notifnats.NewDLQPublisher(env.NATS.JetStream())
+	now := time.Now()
+	for _, msg := range []*notifnats.DLQMessage{
+		{ID: "dlq-filter-old", OriginalTopic: "dlq-filter.a", FailedAt: now.Add(-2 * time.Hour), Attempts: 3, ConsumerGroup: "dlq-filter-billing"},
+		{ID: "dlq-filter-new", OriginalTopic: "dlq-filter.a", FailedAt: now, Attempts: 5, ConsumerGroup: "dlq-filter-email"},
+		{ID: "dlq-filter-other", OriginalTopic: "dlq-filter.b", FailedAt: now, Attempts: 1, ConsumerGroup: "dlq-filter-billing"},
+	} {
+		msg.OrgID = TestOrgID
+		msg.ProjectID = TestProjectID
+		msg.Data = json.RawMessage(`{}`)
+		msg.Timestamp = msg.FailedAt
+		if err := publisher.Publish(ctx, msg); err != nil {
+			t.Fatalf("publish to DLQ: %v", err)
+		}
+	}
+}
+
 func TestEventsReplayAPI(t *testing.T) {
 	env := SetupTestEnv(t)
 	defer env.Cleanup(t)
@@ -1370,6 +1573,114 @@ func TestWebhooksCRUD(t *testing.T) {
 		}
 	})
 
+	t.Run("webhook deliveries page and filter by status", func(t *testing.T) {
+		if createdWebhookID == "" {
+			t.Skip("no webhook created")
+		}
+
+		// Seed deliveries, newest last
+		base := time.Now().Add(-time.Hour)
+		for i, status := range []string{"failed", "success", "failed"} {
+			_, err := env.DB.Exec(context.Background(), `
+				INSERT INTO webhook_deliveries (webhook_id, event_id, topic, status, attempt, created_at)
+				VALUES ($1, $2, 'users.created', $3, 1, $4)
+			`, createdWebhookID, fmt.Sprintf("evt_page_%d", i), status, base.Add(time.Duration(i)*time.Minute))
+			if err != nil {
+				t.Fatalf("seed delivery: %v", err)
+			}
+		}
+
+		list := func(query string) (ids []string, cursor string, status int) {
+			req, _ := http.NewRequest("GET", env.ServerURL+"/api/v1/webhooks/"+createdWebhookID+"/deliveries?"+query, nil)
+			req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var result struct {
+				Deliveries []struct {
+					EventID string `json:"event_id"`
+				} `json:"deliveries"`
+				NextCursor string `json:"next_cursor"`
+			}
+			json.NewDecoder(resp.Body).Decode(&result)
+			for _, d := range result.Deliveries {
+				ids = append(ids, d.EventID)
+			}
+			return ids, result.NextCursor, resp.StatusCode
+		}
+
+		ids, _, code := list("status=failed")
+		if code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", code)
+		}
+		if strings.Join(ids, ",") != "evt_page_2,evt_page_0" {
+			t.Errorf("status=failed: got %v", ids)
+		}
+
+		// Walk all three one page at a time
+		var walked []string
+		cursor := ""
+		for page := 0; page < 4; page++ {
+			query := "limit=1"
+			if cursor != "" {
+				query += "&before=" + cursor
+			}
+			ids, next, _ := list(query)
+			walked = append(walked, ids...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if strings.Join(walked, ",") != "evt_page_2,evt_page_1,evt_page_0" {
+			t.Errorf("paging: got %v", walked)
+		}
+
+		if _, _, code := list("status=bogus"); code != http.StatusBadRequest {
+			t.Errorf("invalid status: expected 400, got %d", code)
+		}
+		if _, _, code := list("before=not-a-uuid"); code != http.StatusBadRequest {
+			t.Errorf("invalid cursor: expected 400, got %d", code)
+		}
+	})
+
+	t.Run("retry webhook delivery", func(t *testing.T) {
+		if createdWebhookID == "" {
+			t.Skip("no webhook created")
+		}
+
+		var succeededID string
+		err := env.DB.QueryRow(context.Background(), `
+			INSERT INTO webhook_deliveries (webhook_id, event_id, topic, status, attempt)
+			VALUES ($1, 'evt_retry_done', 'users.created', 'success', 1)
+			RETURNING id::text
+		`, createdWebhookID).Scan(&succeededID)
+		if err != nil {
+			t.Fatalf("seed delivery: %v", err)
+		}
+
+		retry := func(deliveryID string) int {
+			req, _ := http.NewRequest("POST", env.ServerURL+"/api/v1/webhooks/"+createdWebhookID+"/deliveries/"+deliveryID+"/retry", nil)
+			req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		if code := retry(succeededID); code != http.StatusConflict {
+			t.Errorf("retrying a successful delivery: expected 409, got %d", code)
+		}
+		if code := retry("00000000-0000-0000-0000-000000000000"); code != http.StatusNotFound {
+			t.Errorf("retrying an unknown delivery: expected 404, got %d", code)
+		}
+	})
+
 	t.Run("delete webhook", func(t *testing.T) {
 		if createdWebhookID == "" {
 			t.Skip("no webhook created")
@@ -0,0 +1,98 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/filipexyz/notif/internal/config"
+)
+
+// selfHosted runs the server with AUTH_MODE=local, where API keys may manage keys.
+func selfHosted(cfg *config.Config) {
+	cfg.AuthMode = config.AuthModeLocal
+}
+
+func TestAPIKeyList_Metadata(t *testing.T) {
+	env := SetupTestEnv(t, selfHosted)
+	defer env.Cleanup(t)
+
+	// Create a key
+	body, _ := json.Marshal(map[string]string{"name": "ci-deploy"})
+	req, _ := http.NewRequest("POST", env.ServerURL+"/api/v1/api-keys", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("create request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		b, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, b)
+	}
+	var created map[string]any
+	json.NewDecoder(resp.Body).Decode(&created)
+	fullKey, _ := created["full_key"].(string)
+	if fullKey == "" {
+		t.Fatal("expected full_key on create")
+	}
+
+	// List keys
+	req, _ = http.NewRequest("GET", env.ServerURL+"/api/v1/api-keys", nil)
+	req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("list request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, raw)
+	}
+
+	// Secrets must never appear in the listing
+	for _, secret := range []string{fullKey, TestAPIKey, "full_key", "key_hash"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("listing leaks %q: %s", secret, raw)
+		}
+	}
+
+	var result struct {
+		APIKeys []map[string]any `json:"api_keys"`
+		Count   int              `json:"count"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if result.Count != 2 {
+		t.Errorf("expected 2 keys (seeded + created), got %d", result.Count)
+	}
+
+	var found map[string]any
+	for _, k := range result.APIKeys {
+		if k["id"] == created["id"] {
+			found = k
+		}
+	}
+	if found == nil {
+		t.Fatalf("created key %v not in listing: %s", created["id"], raw)
+	}
+	if found["name"] != "ci-deploy" {
+		t.Errorf("expected name ci-deploy, got %v", found["name"])
+	}
+	if found["key_prefix"] != created["key_prefix"] {
+		t.Errorf("expected key_prefix %v, got %v", created["key_prefix"], found["key_prefix"])
+	}
+	if found["created_at"] == nil {
+		t.Error("expected created_at")
+	}
+	if _, ok := found["usage"].(map[string]any); !ok {
+		t.Errorf("expected usage summary, got %v", found["usage"])
+	}
+}
@@ -0,0 +1,199 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/gorilla/websocket"
+)
+
+// blobRequest sends an authenticated JSON request and decodes the response.
+func blobRequest(t *testing.T, method, url string, body any, out any) int {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		r = bytes.NewReader(b)
+	}
+	req, _ := http.NewRequest(method, url, r)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+func TestBlobAttachments(t *testing.T) {
+	blobDir := t.TempDir()
+	env := SetupTestEnv(t, func(cfg *config.Config) {
+		cfg.BlobStore = "local"
+		cfg.BlobLocalDir = blobDir
+		cfg.BlobSigningKey = "test-blob-signing-key"
+		cfg.BlobMaxSize = 1024 * 1024
+	})
+	defer env.Cleanup(t)
+
+	content := []byte(strings.Repeat("log line\n", 100))
+
+	// 1. Request an upload URL
+	var created map[string]any
+	status := blobRequest(t, "POST", env.ServerURL+"/api/v1/blobs", map[string]any{
+		"content_type": "text/plain",
+		"size":         len(content),
+	}, &created)
+	if status != http.StatusCreated {
+		t.Fatalf("create blob: expected 201, got %d (%v)", status, created)
+	}
+	blobID, _ := created["id"].(string)
+	uploadURL, _ := created["upload_url"].(string)
+	if !strings.HasPrefix(blobID, "blob_") || !strings.HasPrefix(uploadURL, env.ServerURL+"/blobs/") {
+		t.Fatalf("unexpected create response: %v", created)
+	}
+	if created["status"] != "pending" {
+		t.Errorf("expected pending status, got %v", created["status"])
+	}
+
+	// Attaching before the upload lands is rejected
+	var errResp map[string]string
+	status = blobRequest(t, "POST", env.ServerURL+"/api/v1/emit", map[string]any{
+		"topic":       "logs.uploaded",
+		"data":        map[string]string{"job": "nightly"},
+		"attachments": []string{blobID},
+	}, &errResp)
+	if status != http.StatusBadRequest {
+		t.Errorf("emit with pending blob: expected 400, got %d (%v)", status, errResp)
+	}
+
+	// 2. Upload out-of-band (no API key: the URL is presigned)
+	req, _ := http.NewRequest("PUT", uploadURL, bytes.NewReader(content))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("upload: expected 200, got %d", resp.StatusCode)
+	}
+
+	// Metadata now reports the upload and a download URL
+	var got map[string]any
+	if status := blobRequest(t, "GET", env.ServerURL+"/api/v1/blobs/"+blobID, nil, &got); status != http.StatusOK {
+		t.Fatalf("get blob: expected 200, got %d", status)
+	}
+	if got["status"] != "uploaded" || got["size"] != float64(len(content)) || got["download_url"] == nil {
+		t.Errorf("unexpected blob metadata: %v", got)
+	}
+
+	// 3. Emit referencing the blob; the subscriber gets a download URL
+	wsURL := strings.Replace(env.ServerURL, "http://", "ws://", 1)
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"/ws?token="+TestAPIKey, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.WriteJSON(map[string]any{
+		"action":  "subscribe",
+		"topics":  []string{"logs.*"},
+		"options": map[string]any{"auto_ack": true},
+	})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var subResp map[string]any
+	if err := conn.ReadJSON(&subResp); err != nil || subResp["type"] != "subscribed" {
+		t.Fatalf("subscribe failed: %v %v", err, subResp)
+	}
+
+	status = blobRequest(t, "POST", env.ServerURL+"/api/v1/emit", map[string]any{
+		"topic":       "logs.uploaded",
+		"data":        map[string]string{"job": "nightly"},
+		"attachments": []string{blobID},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("emit with attachment: expected 200, got %d", status)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ID          string `json:"id"`
+			ContentType string `json:"content_type"`
+			Size        int64  `json:"size"`
+			URL         string `json:"url"`
+		} `json:"attachments"`
+	}
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if len(event.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %+v", event)
+	}
+	att := event.Attachments[0]
+	if att.ID != blobID || att.ContentType != "text/plain" || att.Size != int64(len(content)) {
+		t.Errorf("unexpected attachment: %+v", att)
+	}
+
+	// 4. The download URL serves the uploaded content
+	dl, err := http.Get(att.URL)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	defer dl.Body.Close()
+	body, _ := io.ReadAll(dl.Body)
+	if dl.StatusCode != http.StatusOK || !bytes.Equal(body, content) {
+		t.Errorf("download: status %d, %d bytes", dl.StatusCode, len(body))
+	}
+
+	t.Run("unknown blob", func(t *testing.T) {
+		if status := blobRequest(t, "GET", env.ServerURL+"/api/v1/blobs/blob_missing", nil, nil); status != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", status)
+		}
+		status := blobRequest(t, "POST", env.ServerURL+"/api/v1/emit", map[string]any{
+			"topic":       "logs.uploaded",
+			"data":        map[string]string{},
+			"attachments": []string{"blob_missing"},
+		}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("emit with unknown blob: expected 400, got %d", status)
+		}
+	})
+
+	t.Run("size over limit", func(t *testing.T) {
+		status := blobRequest(t, "POST", env.ServerURL+"/api/v1/blobs", map[string]any{
+			"content_type": "text/plain",
+			"size":         2 * 1024 * 1024,
+		}, nil)
+		if status != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", status)
+		}
+	})
+}
+
+func TestBlobAttachments_Disabled(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup(t)
+
+	if status := blobRequest(t, "POST", env.ServerURL+"/api/v1/blobs", map[string]any{"size": 10}, nil); status != http.StatusNotImplemented {
+		t.Errorf("create blob without a store: expected 501, got %d", status)
+	}
+	status := blobRequest(t, "POST", env.ServerURL+"/api/v1/emit", map[string]any{
+		"topic":       "logs.uploaded",
+		"data":        map[string]string{},
+		"attachments": []string{"blob_x"},
+	}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("emit with attachment without a store: expected 400, got %d", status)
+	}
+}
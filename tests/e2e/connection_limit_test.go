@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/config"
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketConnectionLimit(t *testing.T) {
+	env := SetupTestEnv(t, func(cfg *config.Config) {
+		cfg.WSMaxConnectionsPerKey = 5
+	})
+	defer env.Cleanup(t)
+
+	// The per-key setting overrides the server default
+	if _, err := env.DB.Exec(context.Background(),
+		`UPDATE api_keys SET max_connections = 2 WHERE key_prefix = 'nsh_abcdefghi'`); err != nil {
+		t.Fatalf("set max_connections: %v", err)
+	}
+
+	wsURL := strings.Replace(env.ServerURL, "http://", "ws://", 1) + "/ws?token=" + TestAPIKey
+
+	conn1, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("connection 1: %v", err)
+	}
+	defer conn1.Close()
+	conn2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("connection 2: %v", err)
+	}
+	defer conn2.Close()
+
+	// 3rd connection exceeds the cap
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("expected 3rd connection to be rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %v", resp)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var errResp map[string]string
+	json.Unmarshal(body, &errResp)
+	if !strings.Contains(errResp["error"], "connection limit reached") {
+		t.Errorf("expected clear limit error, got %s", body)
+	}
+
+	// Closing one frees a slot (release happens when the server notices)
+	conn1.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn3, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err == nil {
+			conn3.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("slot was not freed after closing a connection: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -284,6 +284,113 @@ func TestEventDeliveryTracking(t *testing.T) {
 		}
 	})
 
+	t.Run("nack then ack records redelivery count", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+"/ws?token="+TestAPIKey, nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		subscribeMsg := map[string]interface{}{
+			"action": "subscribe",
+			"topics": []string{"redelivery-track.*"},
+			"options": map[string]interface{}{
+				"auto_ack":    false,
+				"max_retries": 3,
+			},
+		}
+		if err := conn.WriteJSON(subscribeMsg); err != nil {
+			t.Fatalf("failed to send subscribe: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var subResp map[string]interface{}
+		conn.ReadJSON(&subResp)
+
+		payload := `{"topic": "redelivery-track.test", "data": {"retry": true}}`
+		req, _ := http.NewRequest("POST", env.ServerURL+"/api/v1/emit", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("emit request failed: %v", err)
+		}
+		var emitResult map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&emitResult)
+		resp.Body.Close()
+		eventID := emitResult["id"].(string)
+
+		// First attempt: nack it
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var first map[string]interface{}
+		if err := conn.ReadJSON(&first); err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		conn.WriteJSON(map[string]interface{}{
+			"action":   "nack",
+			"id":       eventID,
+			"retry_in": "100ms",
+		})
+
+		// Redelivery: ack it
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var second map[string]interface{}
+		if err := conn.ReadJSON(&second); err != nil {
+			t.Fatalf("failed to read redelivered event: %v", err)
+		}
+		if second["id"] != eventID {
+			t.Fatalf("expected redelivery of %s, got %v", eventID, second["id"])
+		}
+		conn.WriteJSON(map[string]string{"action": "ack", "id": eventID})
+
+		time.Sleep(200 * time.Millisecond)
+
+		deliveriesReq, _ := http.NewRequest("GET", env.ServerURL+"/api/v1/events/"+eventID+"/deliveries", nil)
+		deliveriesReq.Header.Set("Authorization", "Bearer "+TestAPIKey)
+		deliveriesResp, err := http.DefaultClient.Do(deliveriesReq)
+		if err != nil {
+			t.Fatalf("failed to get deliveries: %v", err)
+		}
+		var deliveriesResult map[string]interface{}
+		json.NewDecoder(deliveriesResp.Body).Decode(&deliveriesResult)
+		deliveriesResp.Body.Close()
+
+		var acked map[string]interface{}
+		for _, d := range deliveriesResult["deliveries"].([]interface{}) {
+			if delivery := d.(map[string]interface{}); delivery["status"] == "acked" {
+				acked = delivery
+			}
+		}
+		if acked == nil {
+			t.Fatalf("expected an acked delivery, got %v", deliveriesResult["deliveries"])
+		}
+		if acked["redeliveries"] != float64(1) {
+			t.Errorf("expected redeliveries 1, got %v", acked["redeliveries"])
+		}
+		if acked["first_attempt_at"] == nil {
+			t.Error("expected first_attempt_at to be set")
+		}
+
+		statsReq, _ := http.NewRequest("GET", env.ServerURL+"/api/v1/stats/events", nil)
+		statsReq.Header.Set("Authorization", "Bearer "+TestAPIKey)
+		statsResp, err := http.DefaultClient.Do(statsReq)
+		if err != nil {
+			t.Fatalf("failed to get event stats: %v", err)
+		}
+		var stats struct {
+			Redeliveries struct {
+				Redelivered    int64   `json:"redelivered"`
+				RedeliveryRate float64 `json:"redelivery_rate"`
+			} `json:"redeliveries_24h"`
+		}
+		json.NewDecoder(statsResp.Body).Decode(&stats)
+		statsResp.Body.Close()
+
+		if stats.Redeliveries.Redelivered < 1 || stats.Redeliveries.RedeliveryRate <= 0 {
+			t.Errorf("expected redeliveries in event stats, got %+v", stats.Redeliveries)
+		}
+	})
+
 	t.Run("deliveries API returns receiver_type", func(t *testing.T) {
 		// Connect WebSocket
 		conn, _, err := websocket.DefaultDialer.Dial(wsURL+"/ws?token="+TestAPIKey, nil)
@@ -0,0 +1,86 @@
+package e2e
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/internal/cli/doctor"
+	"github.com/filipexyz/notif/pkg/client"
+	"github.com/gorilla/websocket"
+)
+
+func TestDoctorServer(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup(t)
+
+	c := client.New(TestAPIKey, client.WithServer(env.ServerURL))
+
+	t.Run("healthy server", func(t *testing.T) {
+		report := doctor.Server(c)
+		if !report.Healthy() {
+			t.Fatalf("expected healthy report, got %+v", report.Checks)
+		}
+		if dlq := report.Check("DLQ"); dlq == nil || dlq.Status != doctor.StatusOK {
+			t.Errorf("expected empty DLQ, got %+v", dlq)
+		}
+		if report.Check("Storage NOTIF_EVENTS") == nil {
+			t.Errorf("expected events stream storage check, got %+v", report.Checks)
+		}
+	})
+
+	t.Run("surfaces DLQ depth", func(t *testing.T) {
+		wsURL := strings.Replace(env.ServerURL, "http://", "ws://", 1)
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+"/ws?token="+TestAPIKey, nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		// One attempt only, so a nack dead-letters the event
+		conn.WriteJSON(map[string]any{
+			"action":  "subscribe",
+			"topics":  []string{"doctor.*"},
+			"options": map[string]any{"auto_ack": false, "max_retries": 1},
+		})
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var subResp map[string]any
+		if err := conn.ReadJSON(&subResp); err != nil || subResp["type"] != "subscribed" {
+			t.Fatalf("subscribe failed: %v %v", err, subResp)
+		}
+
+		req, _ := http.NewRequest("POST", env.ServerURL+"/api/v1/emit",
+			bytes.NewReader([]byte(`{"topic": "doctor.fail", "data": {}}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+TestAPIKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("emit failed: %v", err)
+		}
+		resp.Body.Close()
+
+		var event map[string]any
+		if err := conn.ReadJSON(&event); err != nil || event["type"] != "event" {
+			t.Fatalf("expected event, got %v %v", err, event)
+		}
+		conn.WriteJSON(map[string]any{"action": "nack", "id": event["id"]})
+
+		var dlq *doctor.Check
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			dlq = doctor.Server(c).Check("DLQ")
+			if dlq != nil && dlq.Status == doctor.StatusWarn {
+				break
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+		if dlq == nil || dlq.Status != doctor.StatusWarn {
+			t.Fatalf("expected DLQ warning, got %+v", dlq)
+		}
+		if !strings.HasPrefix(dlq.Detail, "1 dead-lettered") {
+			t.Errorf("expected DLQ depth 1, got %q", dlq.Detail)
+		}
+	})
+}
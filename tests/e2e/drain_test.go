@@ -0,0 +1,52 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+func TestDrain(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup(t)
+
+	c := client.New(TestAPIKey, client.WithServer(env.ServerURL))
+
+	var emitted []string
+	for i := 0; i < 3; i++ {
+		resp, err := c.Emit("drain-test.item", json.RawMessage(fmt.Sprintf(`{"n":%d}`, i)))
+		if err != nil {
+			t.Fatalf("emit failed: %v", err)
+		}
+		emitted = append(emitted, resp.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := c.Drain(ctx, []string{"drain-test.*"})
+	if err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if len(events) != len(emitted) {
+		t.Fatalf("expected %d events, got %d", len(emitted), len(events))
+	}
+	for i, id := range emitted {
+		if events[i].ID != id {
+			t.Errorf("event %d: expected %s, got %s", i, id, events[i].ID)
+		}
+	}
+
+	// A topic with nothing stored drains to an empty slice
+	events, err = c.Drain(ctx, []string{"drain-test-empty.*"})
+	if err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no events, got %d", len(events))
+	}
+}
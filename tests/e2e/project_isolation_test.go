@@ -507,6 +507,59 @@ func TestCrossProjectAccessDenied(t *testing.T) {
 		}
 	})
 
+	t.Run("cannot list other project's webhook deliveries", func(t *testing.T) {
+		// Create webhook in Project A
+		webhookPayload := `{"url": "https://example.com/deliveries-test", "topics": ["deliveries.test"]}`
+		req, _ := http.NewRequest("POST", env.ServerURL+"/api/v1/webhooks", strings.NewReader(webhookPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+TestAPIKeyA)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("create webhook failed: %v", err)
+		}
+
+		var webhook struct {
+			ID string `json:"id"`
+		}
+		json.NewDecoder(resp.Body).Decode(&webhook)
+		resp.Body.Close()
+
+		// Try to list Project A's deliveries with Project B's key
+		req, _ = http.NewRequest("GET", env.ServerURL+"/api/v1/webhooks/"+webhook.ID+"/deliveries", nil)
+		req.Header.Set("Authorization", "Bearer "+TestAPIKeyB)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("list deliveries failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("listing other project's webhook deliveries should return 404, got %d", resp.StatusCode)
+		}
+
+		// Retrying one of Project A's deliveries with Project B's key
+		var deliveryID string
+		err = env.DB.QueryRow(context.Background(), `
+			INSERT INTO webhook_deliveries (webhook_id, event_id, topic, status, attempt)
+			VALUES ($1, 'evt_cross_retry', 'deliveries.test', 'failed', 5)
+			RETURNING id::text
+		`, webhook.ID).Scan(&deliveryID)
+		if err != nil {
+			t.Fatalf("seed delivery: %v", err)
+		}
+		req, _ = http.NewRequest("POST", env.ServerURL+"/api/v1/webhooks/"+webhook.ID+"/deliveries/"+deliveryID+"/retry", nil)
+		req.Header.Set("Authorization", "Bearer "+TestAPIKeyB)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("retry delivery failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("retrying other project's webhook delivery should return 404, got %d", resp.StatusCode)
+		}
+	})
+
 	t.Run("cannot delete other project's webhook", func(t *testing.T) {
 		// Create webhook in Project A
 		webhookPayload := `{"url": "https://example.com/delete-test", "topics": ["delete.test"]}`
@@ -583,3 +636,92 @@ func TestCrossProjectAccessDenied(t *testing.T) {
 		}
 	})
 }
+
+// TestAdminKeyA is an admin key of project A also authorized for project B.
+const TestAdminKeyA = "nsh_projectAadmin234567890abcdef"
+
+// TestCrossProjectSubscription verifies that an admin key can subscribe
+// across the projects it's authorized for, and a normal key cannot.
+func TestCrossProjectSubscription(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup(t)
+
+	setupProjectIsolationTest(t, env)
+
+	hash := sha256.Sum256([]byte(TestAdminKeyA))
+	_, err := env.DB.Exec(context.Background(), `
+		INSERT INTO api_keys (key_hash, key_prefix, name, org_id, project_id, scopes, authorized_projects)
+		VALUES ($1, $2, $3, $4, $5, '{admin}', $6)
+		ON CONFLICT (key_hash) DO NOTHING
+	`, hex.EncodeToString(hash[:]), TestAdminKeyA[:16], "Project A Admin Key", IsolationTestOrg, TestProjectA_ID, []string{TestProjectB_ID})
+	if err != nil {
+		t.Fatalf("failed to create admin key: %v", err)
+	}
+
+	wsURL := strings.Replace(env.ServerURL, "http://", "ws://", 1) + "/ws?token="
+	subscribe := fmt.Sprintf(`{"action": "subscribe", "topics": ["aggregate.*"], "options": {"auto_ack": true, "projects": [%q, %q]}}`, TestProjectA_ID, TestProjectB_ID)
+
+	t.Run("admin key receives events from both projects", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+TestAdminKeyA, nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(subscribe)); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var subscribed map[string]interface{}
+		if err := conn.ReadJSON(&subscribed); err != nil || subscribed["type"] != "subscribed" {
+			t.Fatalf("expected subscribed, got %v (err %v)", subscribed, err)
+		}
+
+		for _, key := range []string{TestAPIKeyA, TestAPIKeyB} {
+			req, _ := http.NewRequest("POST", env.ServerURL+"/api/v1/emit", strings.NewReader(`{"topic": "aggregate.test", "data": {}}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+key)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("emit failed: %v", err)
+			}
+			resp.Body.Close()
+		}
+
+		projects := make(map[string]bool)
+		for len(projects) < 2 {
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			var frame map[string]interface{}
+			if err := conn.ReadJSON(&frame); err != nil {
+				t.Fatalf("expected events from both projects, got %v: %v", projects, err)
+			}
+			if frame["type"] == "event" {
+				projectID, _ := frame["project_id"].(string)
+				projects[projectID] = true
+			}
+		}
+		if !projects[TestProjectA_ID] || !projects[TestProjectB_ID] {
+			t.Errorf("expected events from both projects, got %v", projects)
+		}
+	})
+
+	t.Run("normal key cannot subscribe across projects", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+TestAPIKeyA, nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(subscribe)); err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var frame map[string]interface{}
+		if err := conn.ReadJSON(&frame); err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		if frame["type"] != "error" || frame["code"] != "FORBIDDEN" {
+			t.Errorf("expected FORBIDDEN error, got %v", frame)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+func TestSchemaValidationStats(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup(t)
+
+	c := client.New(TestAPIKey, client.WithServer(env.ServerURL))
+
+	if _, err := c.SchemaCreate(client.CreateSchemaRequest{Name: "order-placed", TopicPattern: "orders.placed"}); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	// Strict, but invalid events are only logged, so every emit goes through
+	if _, err := c.SchemaVersionCreate("order-placed", client.CreateSchemaVersionRequest{
+		Version:        "1.0.0",
+		Schema:         json.RawMessage(`{"type":"object","required":["id"]}`),
+		ValidationMode: "strict",
+		OnInvalid:      "log",
+	}); err != nil {
+		t.Fatalf("create version: %v", err)
+	}
+
+	for _, data := range []string{`{"id":"1"}`, `{}`, `{"id":"2"}`, `{"amount":3}`, `{"id":"4"}`} {
+		if _, err := c.Emit("orders.placed", json.RawMessage(data)); err != nil {
+			t.Fatalf("emit %s: %v", data, err)
+		}
+	}
+
+	// Validations are recorded in the background
+	var stats *client.SchemaValidationStats
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var err error
+		stats, err = c.SchemaStats("order-placed")
+		if err != nil {
+			t.Fatalf("schema stats: %v", err)
+		}
+		if stats.Total >= 5 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if stats.Total != 5 || stats.ValidCount != 3 || stats.InvalidCount != 2 {
+		t.Errorf("expected 5 total, 3 valid, 2 invalid, got %+v", stats.ValidationStats)
+	}
+	if len(stats.Versions) != 1 || stats.Versions[0].Version != "1.0.0" || stats.Versions[0].InvalidCount != 2 {
+		t.Errorf("expected per-version counts for 1.0.0, got %+v", stats.Versions)
+	}
+
+	if _, err := c.SchemaStats("missing"); err == nil {
+		t.Error("expected an error for an unknown schema")
+	}
+}
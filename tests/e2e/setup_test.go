@@ -39,8 +39,9 @@ type TestEnv struct {
 	cancel    context.CancelFunc
 }
 
-// SetupTestEnv creates a complete test environment with containers
-func SetupTestEnv(t *testing.T) *TestEnv {
+// SetupTestEnv creates a complete test environment with containers.
+// Options can adjust the server config before it starts.
+func SetupTestEnv(t *testing.T, opts ...func(*config.Config)) *TestEnv {
 	t.Helper()
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 
@@ -135,6 +136,9 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 		LogFormat:       "text",
 		MaxPayloadSize:  262144, // 256KB
 	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
 
 	srv := server.New(cfg, db, nc)
 
@@ -0,0 +1,54 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+func TestStorageUsage(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup(t)
+
+	c := client.New(TestAPIKey, client.WithServer(env.ServerURL))
+
+	before, err := c.StorageUsage()
+	if err != nil {
+		t.Fatalf("storage usage failed: %v", err)
+	}
+	if before.ProjectID != TestProjectID {
+		t.Errorf("expected project %s, got %s", TestProjectID, before.ProjectID)
+	}
+	if len(before.Streams) != 2 {
+		t.Errorf("expected events and DLQ streams, got %+v", before.Streams)
+	}
+
+	for i := 0; i < 3; i++ {
+		data := json.RawMessage(fmt.Sprintf(`{"n":%d,"pad":"0123456789"}`, i))
+		if _, err := c.Emit("usage.test", data); err != nil {
+			t.Fatalf("emit failed: %v", err)
+		}
+	}
+
+	// Usage is cached briefly, so poll until the new figures show up
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		after, err := c.StorageUsage()
+		if err != nil {
+			t.Fatalf("storage usage failed: %v", err)
+		}
+		if after.Messages >= before.Messages+3 && after.Events >= before.Events+3 && after.EventBytes > before.EventBytes {
+			if after.Streams[0].Bytes <= before.Streams[0].Bytes {
+				t.Errorf("expected events stream bytes to grow, got %d -> %d", before.Streams[0].Bytes, after.Streams[0].Bytes)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("storage usage did not grow after emitting: before %+v, after %+v", before, after)
+		}
+		time.Sleep(time.Second)
+	}
+}
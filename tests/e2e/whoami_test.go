@@ -0,0 +1,50 @@
+package e2e
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/filipexyz/notif/pkg/client"
+)
+
+func TestWhoami(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer env.Cleanup(t)
+
+	t.Run("reflects the key's project and scopes", func(t *testing.T) {
+		c := client.New(TestAPIKey, client.WithServer(env.ServerURL))
+		id, err := c.Whoami()
+		if err != nil {
+			t.Fatalf("whoami failed: %v", err)
+		}
+
+		if id.AuthType != "api_key" {
+			t.Errorf("expected auth_type api_key, got %q", id.AuthType)
+		}
+		if id.OrgID != TestOrgID || id.ProjectID != TestProjectID {
+			t.Errorf("expected %s/%s, got %s/%s", TestOrgID, TestProjectID, id.OrgID, id.ProjectID)
+		}
+		if id.ProjectName != "Default" {
+			t.Errorf("expected project name Default, got %q", id.ProjectName)
+		}
+		if id.KeyName != "E2E Test Key" || id.KeyPrefix != "nsh_abcdefghi" || id.KeyID == "" {
+			t.Errorf("unexpected key identity: %+v", id)
+		}
+		if len(id.Scopes) != 1 || id.Scopes[0] != "*" {
+			t.Errorf("expected full-access scopes, got %v", id.Scopes)
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		c := client.New("nsh_zzzzzzzzzzzzzzzzzzzzzzzzzzzz", client.WithServer(env.ServerURL))
+		_, err := c.Whoami()
+
+		var authErr *client.AuthError
+		if !errors.As(err, &authErr) {
+			t.Fatalf("expected AuthError, got %v", err)
+		}
+		if authErr.Message != "invalid api key" {
+			t.Errorf("expected server's reason, got %q", authErr.Message)
+		}
+	})
+}